@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	jsonpatch "github.com/evanphx/json-patch"
+	analyticsConf "github.com/prebid/prebid-server/analytics/config"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/metrics"
@@ -58,6 +59,15 @@ func GetAccount(ctx context.Context, cfg *config.Configuration, fetcher stored_r
 		if len(account.ID) == 0 {
 			account.ID = accountID
 		}
+		// The stored accounts override the privacy, targeting, cache and analytics settings of the host, which are
+		// validated at startup only
+		accountErrs := append(account.Validate(), analyticsConf.ValidateAccountOptions(account)...)
+		if len(accountErrs) > 0 {
+			for _, err := range accountErrs {
+				errs = append(errs, fmt.Errorf("The config of the account %s is invalid: %v", accountID, err))
+			}
+			return nil, errs
+		}
 	}
 	if account.Disabled {
 		errs = append(errs, &errortypes.BlacklistedAcct{
@@ -14,8 +14,11 @@ import (
 )
 
 var mockAccountData = map[string]json.RawMessage{
-	"valid_acct":    json.RawMessage(`{"disabled":false}`),
-	"disabled_acct": json.RawMessage(`{"disabled":true}`),
+	"valid_acct":     json.RawMessage(`{"disabled":false}`),
+	"disabled_acct":  json.RawMessage(`{"disabled":true}`),
+	"invalid_acct":   json.RawMessage(`{"gdpr":{"purpose2":{"enforce_purpose":"yes"}},"privacy":{"allowactivities":{"syncUser":{"rules":[{"condition":{"gpc":"true"}}]}}},"targeting":{"prefix":"a-b"},"cache":{"max_ttl":{"video":-1}}}`),
+	"analytics_acct": json.RawMessage(`{"analytics":{"modules":{"pubstack":{"options":{"scopeid":""}}}}}`),
+	"override_acct":  json.RawMessage(`{"gdpr":{"purpose2":{"enforce_purpose":"basic"},"integration_enabled":{"dooh":false}},"gpp":{"enabled":false}}`),
 }
 
 type mockAccountFetcher struct {
@@ -92,3 +95,54 @@ func TestGetAccount(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAccountPrivacyOverride(t *testing.T) {
+	trueValue, falseValue, basicEnforcement := true, false, config.TCF2BasicEnforcement
+	cfg := &config.Configuration{
+		AccountDefaults: config.Account{
+			GDPR: config.AccountGDPR{Enabled: &trueValue},
+			GPP:  config.AccountGPP{IntegrationEnabled: config.AccountIntegration{App: &trueValue}},
+		},
+	}
+	assert.NoError(t, cfg.MarshalAccountDefaults())
+
+	account, errors := GetAccount(context.Background(), cfg, &mockAccountFetcher{}, "override_acct")
+
+	assert.Empty(t, errors)
+	assert.Equal(t, config.AccountGDPR{
+		Enabled:            &trueValue,
+		IntegrationEnabled: config.AccountIntegration{DOOH: &falseValue},
+		Purpose2:           config.AccountGDPRPurpose{EnforcePurpose: &basicEnforcement},
+	}, account.GDPR)
+	assert.Equal(t, config.AccountGPP{
+		Enabled:            &falseValue,
+		IntegrationEnabled: config.AccountIntegration{App: &trueValue},
+	}, account.GPP)
+}
+
+func TestGetAccountInvalidConfig(t *testing.T) {
+	cfg := &config.Configuration{}
+	assert.NoError(t, cfg.MarshalAccountDefaults())
+
+	account, errors := GetAccount(context.Background(), cfg, &mockAccountFetcher{}, "invalid_acct")
+
+	assert.Nil(t, account)
+	assert.Equal(t, []error{
+		fmt.Errorf("The config of the account invalid_acct is invalid: privacy.allowactivities.syncUser.rules[0].condition.gpc must be either 0 or 1. Got true"),
+		fmt.Errorf("The config of the account invalid_acct is invalid: gdpr.purpose2.enforce_purpose must be full, basic or no. Got yes"),
+		fmt.Errorf("The config of the account invalid_acct is invalid: targeting.prefix must only have letters, digits and underscores. Got a-b"),
+		fmt.Errorf("The config of the account invalid_acct is invalid: cache.max_ttl.video must be >= 0. Got -1"),
+	}, errors)
+}
+
+func TestGetAccountInvalidAnalyticsOptions(t *testing.T) {
+	cfg := &config.Configuration{}
+	assert.NoError(t, cfg.MarshalAccountDefaults())
+
+	account, errors := GetAccount(context.Background(), cfg, &mockAccountFetcher{}, "analytics_acct")
+
+	assert.Nil(t, account)
+	if assert.Len(t, errors, 1) {
+		assert.Contains(t, errors[0].Error(), "The config of the account analytics_acct is invalid: analytics.modules.pubstack.options are invalid: scopeid")
+	}
+}
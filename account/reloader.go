@@ -0,0 +1,193 @@
+package account
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Reloader is an AccountFetcher which keeps the accounts it has fetched in memory, and reloads them from its backend
+// every time it is run. The accounts are validated against a JSON schema whenever they change, and keep serving
+// their last valid version if their new one is invalid.
+type Reloader struct {
+	fetcher stored_requests.AccountFetcher
+	schema  *gojsonschema.Schema
+	timeout time.Duration
+	now     func() time.Time
+
+	mutex    sync.RWMutex
+	accounts map[string]*loadedAccount
+}
+
+// loadedAccount holds the last valid version of an account, and the errors of the last load which failed
+type loadedAccount struct {
+	json   json.RawMessage
+	status AccountStatus
+}
+
+// AccountStatus describes the version of an account loaded by the Reloader
+type AccountStatus struct {
+	ID string `json:"id"`
+	// Version counts the valid versions of the account loaded so far, starting at 1. It is 0 until one is loaded.
+	Version int `json:"version"`
+	// Hash is the SHA-256 of the JSON of the version
+	Hash     string     `json:"hash,omitempty"`
+	LoadedAt *time.Time `json:"loaded_at,omitempty"`
+	// Errors are the validation or fetch errors of the last reload, which didn't replace the version
+	Errors   []string   `json:"errors,omitempty"`
+	FailedAt *time.Time `json:"failed_at,omitempty"`
+}
+
+// NewReloader returns a Reloader of the accounts of the fetcher, validated against the JSON schema of the schema file
+func NewReloader(fetcher stored_requests.AccountFetcher, cfg config.AccountReload) (*Reloader, error) {
+	schemaJSON, err := ioutil.ReadFile(cfg.SchemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the account schema %s: %v", cfg.SchemaFile, err)
+	}
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schemaJSON))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load the account schema %s: %v", cfg.SchemaFile, err)
+	}
+	return &Reloader{
+		fetcher:  fetcher,
+		schema:   schema,
+		timeout:  cfg.TimeoutDuration(),
+		now:      time.Now,
+		accounts: make(map[string]*loadedAccount),
+	}, nil
+}
+
+// FetchAccount returns the last valid version of the account. It is loaded from the backend the first time only.
+func (r *Reloader) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
+	r.mutex.RLock()
+	account, ok := r.accounts[accountID]
+	r.mutex.RUnlock()
+	if ok && account.json != nil {
+		return account.json, nil
+	}
+
+	if errs := r.load(ctx, accountID); len(errs) > 0 {
+		return nil, errs
+	}
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if account, ok := r.accounts[accountID]; ok && account.json != nil {
+		return account.json, nil
+	}
+	return nil, []error{stored_requests.NotFoundError{ID: accountID, DataType: "Account"}}
+}
+
+// Run reloads all the accounts loaded so far. It is meant to be run by a TickerTask.
+func (r *Reloader) Run() error {
+	r.mutex.RLock()
+	accountIDs := make([]string, 0, len(r.accounts))
+	for accountID := range r.accounts {
+		accountIDs = append(accountIDs, accountID)
+	}
+	r.mutex.RUnlock()
+
+	for _, accountID := range accountIDs {
+		ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+		if errs := r.load(ctx, accountID); len(errs) > 0 {
+			glog.Warningf("Failed to reload the account %s, keeping its version loaded before: %v", accountID, errs)
+		}
+		cancel()
+	}
+	return nil
+}
+
+// Statuses returns the status of every account loaded so far, sorted by account id
+func (r *Reloader) Statuses() []AccountStatus {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	statuses := make([]AccountStatus, 0, len(r.accounts))
+	for _, account := range r.accounts {
+		statuses = append(statuses, account.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ID < statuses[j].ID
+	})
+	return statuses
+}
+
+// load fetches the account from the backend and validates it. An account which is no longer found is dropped, while
+// an account failing to fetch or to validate keeps its last valid version, and its errors are recorded.
+func (r *Reloader) load(ctx context.Context, accountID string) []error {
+	accountJSON, errs := r.fetcher.FetchAccount(ctx, accountID)
+	if len(errs) == 0 && accountJSON == nil {
+		errs = []error{stored_requests.NotFoundError{ID: accountID, DataType: "Account"}}
+	}
+	if len(errs) == 1 {
+		if _, ok := errs[0].(stored_requests.NotFoundError); ok {
+			r.mutex.Lock()
+			delete(r.accounts, accountID)
+			r.mutex.Unlock()
+			return nil
+		}
+	}
+	if len(errs) == 0 {
+		errs = r.validate(accountID, accountJSON)
+	}
+
+	now := r.now()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	account, ok := r.accounts[accountID]
+	if !ok {
+		account = &loadedAccount{status: AccountStatus{ID: accountID}}
+		r.accounts[accountID] = account
+	}
+
+	if len(errs) > 0 {
+		account.status.Errors = make([]string, 0, len(errs))
+		for _, err := range errs {
+			account.status.Errors = append(account.status.Errors, err.Error())
+		}
+		account.status.FailedAt = &now
+		return errs
+	}
+
+	hashBytes := sha256.Sum256(accountJSON)
+	hash := hex.EncodeToString(hashBytes[:])
+	if hash != account.status.Hash {
+		account.json = accountJSON
+		account.status.Version++
+		account.status.Hash = hash
+		account.status.LoadedAt = &now
+	}
+	account.status.Errors = nil
+	account.status.FailedAt = nil
+	return nil
+}
+
+// validate checks the account against the schema, and that it can be read as an account config
+func (r *Reloader) validate(accountID string, accountJSON json.RawMessage) []error {
+	result, err := r.schema.Validate(gojsonschema.NewBytesLoader(accountJSON))
+	if err != nil {
+		return []error{fmt.Errorf("The config of the account %s is invalid: %v", accountID, err)}
+	}
+	var errs []error
+	for _, resultErr := range result.Errors() {
+		errs = append(errs, fmt.Errorf("The config of the account %s is invalid: %s", accountID, resultErr.String()))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	var account config.Account
+	if err := json.Unmarshal(accountJSON, &account); err != nil {
+		return []error{fmt.Errorf("The config of the account %s is invalid: %v", accountID, err)}
+	}
+	return nil
+}
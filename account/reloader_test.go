@@ -0,0 +1,133 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockReloadedAccountFetcher struct {
+	accounts map[string]json.RawMessage
+	errs     map[string]error
+	calls    int
+}
+
+func (af *mockReloadedAccountFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
+	af.calls++
+	if err, ok := af.errs[accountID]; ok {
+		return nil, []error{err}
+	}
+	if account, ok := af.accounts[accountID]; ok {
+		return account, nil
+	}
+	return nil, []error{stored_requests.NotFoundError{ID: accountID, DataType: "Account"}}
+}
+
+func newTestReloader(t *testing.T, fetcher stored_requests.AccountFetcher) *Reloader {
+	t.Helper()
+	reloader, err := NewReloader(fetcher, config.AccountReload{Timeout: 1000, SchemaFile: "../static/account-schema.json"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	loadTime := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	reloader.now = func() time.Time {
+		return loadTime
+	}
+	return reloader
+}
+
+func TestReloaderFetchAccount(t *testing.T) {
+	fetcher := &mockReloadedAccountFetcher{accounts: map[string]json.RawMessage{
+		"valid":   json.RawMessage(`{"disabled":false,"events_enabled":true}`),
+		"invalid": json.RawMessage(`{"disabled":"no"}`),
+	}}
+	reloader := newTestReloader(t, fetcher)
+
+	account, errs := reloader.FetchAccount(context.Background(), "valid")
+	assert.Empty(t, errs)
+	assert.Equal(t, json.RawMessage(`{"disabled":false,"events_enabled":true}`), account)
+
+	_, errs = reloader.FetchAccount(context.Background(), "valid")
+	assert.Empty(t, errs)
+	assert.Equal(t, 1, fetcher.calls, "The loaded accounts should not be fetched again")
+
+	account, errs = reloader.FetchAccount(context.Background(), "invalid")
+	assert.Nil(t, account)
+	assert.Equal(t, []error{errors.New("The config of the account invalid is invalid: disabled: Invalid type. Expected: boolean, given: string")}, errs)
+
+	account, errs = reloader.FetchAccount(context.Background(), "unknown")
+	assert.Nil(t, account)
+	assert.Equal(t, []error{stored_requests.NotFoundError{ID: "unknown", DataType: "Account"}}, errs)
+
+	loadTime := reloader.now()
+	assert.Equal(t, []AccountStatus{
+		{ID: "invalid", Errors: []string{"The config of the account invalid is invalid: disabled: Invalid type. Expected: boolean, given: string"}, FailedAt: &loadTime},
+		{ID: "valid", Version: 1, Hash: "f1259f532dbc2d7f2aba414e7faa9a862b7eab99875b552843119bcf06da02c7", LoadedAt: &loadTime},
+	}, reloader.Statuses())
+}
+
+func TestReloaderRun(t *testing.T) {
+	fetcher := &mockReloadedAccountFetcher{accounts: map[string]json.RawMessage{
+		"changed":   json.RawMessage(`{"debug_allow":false}`),
+		"invalid":   json.RawMessage(`{"debug_allow":false}`),
+		"failing":   json.RawMessage(`{"debug_allow":false}`),
+		"deleted":   json.RawMessage(`{"debug_allow":false}`),
+		"unchanged": json.RawMessage(`{"debug_allow":false}`),
+	}}
+	reloader := newTestReloader(t, fetcher)
+	for accountID := range fetcher.accounts {
+		_, errs := reloader.FetchAccount(context.Background(), accountID)
+		assert.Empty(t, errs, accountID)
+	}
+
+	fetcher.accounts["changed"] = json.RawMessage(`{"debug_allow":true}`)
+	fetcher.accounts["invalid"] = json.RawMessage(`{"validations":{"adm_presence":"drop"}}`)
+	fetcher.errs = map[string]error{"failing": errors.New("backend down")}
+	delete(fetcher.accounts, "deleted")
+	assert.NoError(t, reloader.Run())
+
+	statuses := make(map[string]AccountStatus)
+	for _, status := range reloader.Statuses() {
+		statuses[status.ID] = status
+	}
+	assert.Len(t, statuses, 4, "The deleted account should be dropped")
+
+	assert.Equal(t, 2, statuses["changed"].Version)
+	assert.Empty(t, statuses["changed"].Errors)
+	account, _ := reloader.FetchAccount(context.Background(), "changed")
+	assert.Equal(t, json.RawMessage(`{"debug_allow":true}`), account)
+
+	assert.Equal(t, 1, statuses["invalid"].Version)
+	assert.Len(t, statuses["invalid"].Errors, 1)
+	account, _ = reloader.FetchAccount(context.Background(), "invalid")
+	assert.Equal(t, json.RawMessage(`{"debug_allow":false}`), account, "The last valid version should be kept")
+
+	assert.Equal(t, 1, statuses["failing"].Version)
+	assert.Equal(t, []string{"backend down"}, statuses["failing"].Errors)
+	account, _ = reloader.FetchAccount(context.Background(), "failing")
+	assert.Equal(t, json.RawMessage(`{"debug_allow":false}`), account, "The last valid version should be kept")
+
+	assert.Equal(t, 1, statuses["unchanged"].Version)
+
+	// The accounts which validate again clear their errors
+	fetcher.accounts["invalid"] = json.RawMessage(`{"validations":{"adm_presence":"enforce"}}`)
+	assert.NoError(t, reloader.Run())
+	for _, status := range reloader.Statuses() {
+		if status.ID == "invalid" {
+			assert.Equal(t, 2, status.Version)
+			assert.Empty(t, status.Errors)
+			assert.Nil(t, status.FailedAt)
+		}
+	}
+}
+
+func TestNewReloaderInvalidSchema(t *testing.T) {
+	_, err := NewReloader(&mockReloadedAccountFetcher{}, config.AccountReload{SchemaFile: "missing-schema.json"})
+	assert.Error(t, err)
+}
@@ -59,9 +59,13 @@ type TimeoutBidder interface {
 // Currency declaration is not mandatory but helps to detect an eventual currency mismatch issue.
 // From the bid response, the bidder accepts a list of valid currencies for the bid.
 // The currency is the same across all bids.
+//
+// Seat is optional. Bidders which respond on behalf of an alternate bidder code can set it to have their bids
+// returned under that seat instead of the one configured for the bidder.
 type BidderResponse struct {
 	Currency string
 	Bids     []*TypedBid
+	Seat     string
 }
 
 // NewBidderResponseWithBidsCapacity create a new BidderResponse initialising the bids array capacity and the default currency value
@@ -0,0 +1,311 @@
+package genericortb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/macros"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// adapter serves the bidders onboarded through config, whose behavior is entirely set by their generic_ortb config
+type adapter struct {
+	endpoint      *template.Template
+	macros        config.GenericORTBMacros
+	fieldMappings []config.GenericORTBFieldMapping
+}
+
+// Builder builds a new instance of the generic OpenRTB adapter for the given bidder with the given config.
+func Builder(bidderName openrtb_ext.BidderName, config config.Adapter) (adapters.Bidder, error) {
+	if config.GenericORTB == nil {
+		return nil, fmt.Errorf("the generic_ortb config of %s is missing", bidderName)
+	}
+
+	endpoint, err := template.New("endpointTemplate").Parse(config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse endpoint url template: %v", err)
+	}
+
+	bidder := &adapter{
+		endpoint:      endpoint,
+		macros:        config.GenericORTB.EndpointMacros,
+		fieldMappings: config.GenericORTB.FieldMappings,
+	}
+	return bidder, nil
+}
+
+// impsByEndpoint are the imps sent to an endpoint, along with the params of the first of them which set the request
+// level fields of the bidder request
+type impsByEndpoint struct {
+	endpoint string
+	imps     []openrtb2.Imp
+	params   map[string]json.RawMessage
+}
+
+func (a *adapter) MakeRequests(request *openrtb2.BidRequest, reqInfo *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
+	var errs []error
+	var requestsImps []*impsByEndpoint
+	requestsImpsByEndpoint := make(map[string]*impsByEndpoint)
+
+	for _, imp := range request.Imp {
+		params, err := getImpParams(imp)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		endpoint, err := a.resolveEndpoint(imp.ID, params)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, mapping := range a.fieldMappings {
+			if mapping.Field == config.GenericORTBFieldImpTagID {
+				if value, ok := getParam(params, mapping.Param); ok {
+					imp.TagID = value
+				}
+			}
+		}
+
+		requestImps, ok := requestsImpsByEndpoint[endpoint]
+		if !ok {
+			requestImps = &impsByEndpoint{endpoint: endpoint, params: params}
+			requestsImpsByEndpoint[endpoint] = requestImps
+			requestsImps = append(requestsImps, requestImps)
+		}
+		requestImps.imps = append(requestImps.imps, imp)
+	}
+
+	requests := make([]*adapters.RequestData, 0, len(requestsImps))
+	for _, requestImps := range requestsImps {
+		bidderRequest := a.makeBidderRequest(request, requestImps)
+
+		body, err := json.Marshal(bidderRequest)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		headers := http.Header{}
+		headers.Add("Content-Type", "application/json;charset=utf-8")
+		headers.Add("Accept", "application/json")
+		headers.Add("x-openrtb-version", "2.5")
+
+		requests = append(requests, &adapters.RequestData{
+			Method:  http.MethodPost,
+			Uri:     requestImps.endpoint,
+			Body:    body,
+			Headers: headers,
+		})
+	}
+	return requests, errs
+}
+
+func getImpParams(imp openrtb2.Imp) (map[string]json.RawMessage, error) {
+	var bidderExt adapters.ExtImpBidder
+	if err := json.Unmarshal(imp.Ext, &bidderExt); err != nil {
+		return nil, &errortypes.BadInput{
+			Message: fmt.Sprintf("imp %s: unable to read imp.ext: %v", imp.ID, err),
+		}
+	}
+
+	var params map[string]json.RawMessage
+	if err := json.Unmarshal(bidderExt.Bidder, &params); err != nil {
+		return nil, &errortypes.BadInput{
+			Message: fmt.Sprintf("imp %s: unable to read imp.ext.bidder: %v", imp.ID, err),
+		}
+	}
+	return params, nil
+}
+
+// getParam returns a param as a string. Numbers and booleans are taken as is, which allows them in endpoints and in
+// string fields of the bidder request.
+func getParam(params map[string]json.RawMessage, name string) (string, bool) {
+	rawValue, ok := params[name]
+	if !ok {
+		return "", false
+	}
+
+	var value string
+	if err := json.Unmarshal(rawValue, &value); err == nil {
+		return value, true
+	}
+	var other interface{}
+	if err := json.Unmarshal(rawValue, &other); err != nil || other == nil {
+		return "", false
+	}
+	switch other.(type) {
+	case float64, bool:
+		return string(rawValue), true
+	}
+	return "", false
+}
+
+// resolveEndpoint resolves the endpoint of an imp. The params mapped to macros are required, since a macro resolved
+// to an empty string would send the imp to the wrong endpoint.
+func (a *adapter) resolveEndpoint(impID string, params map[string]json.RawMessage) (string, error) {
+	var endpointParams macros.EndpointTemplateParams
+	for _, macro := range []struct {
+		param string
+		value *string
+	}{
+		{a.macros.Host, &endpointParams.Host},
+		{a.macros.PublisherID, &endpointParams.PublisherID},
+		{a.macros.ZoneID, &endpointParams.ZoneID},
+		{a.macros.SourceId, &endpointParams.SourceId},
+		{a.macros.AccountID, &endpointParams.AccountID},
+		{a.macros.AdUnit, &endpointParams.AdUnit},
+	} {
+		if macro.param == "" {
+			continue
+		}
+		value, ok := getParam(params, macro.param)
+		if !ok || value == "" {
+			return "", &errortypes.BadInput{
+				Message: fmt.Sprintf("imp %s: the %s param of imp.ext.bidder is required", impID, macro.param),
+			}
+		}
+		*macro.value = value
+	}
+
+	endpoint, err := macros.ResolveMacros(a.endpoint, endpointParams)
+	if err != nil {
+		return "", &errortypes.BadInput{
+			Message: fmt.Sprintf("imp %s: unable to resolve the endpoint: %v", impID, err),
+		}
+	}
+	return endpoint, nil
+}
+
+// makeBidderRequest copies the request fields mapped to params before setting them, since the request is shared
+// with the other bidders
+func (a *adapter) makeBidderRequest(request *openrtb2.BidRequest, requestImps *impsByEndpoint) *openrtb2.BidRequest {
+	bidderRequest := *request
+	bidderRequest.Imp = requestImps.imps
+
+	if bidderRequest.Site != nil {
+		siteCopy := *bidderRequest.Site
+		bidderRequest.Site = &siteCopy
+	}
+	if bidderRequest.App != nil {
+		appCopy := *bidderRequest.App
+		bidderRequest.App = &appCopy
+	}
+
+	for _, mapping := range a.fieldMappings {
+		value, ok := getParam(requestImps.params, mapping.Param)
+		if !ok {
+			continue
+		}
+
+		switch mapping.Field {
+		case config.GenericORTBFieldSiteID:
+			if bidderRequest.Site != nil {
+				bidderRequest.Site.ID = value
+			}
+		case config.GenericORTBFieldAppID:
+			if bidderRequest.App != nil {
+				bidderRequest.App.ID = value
+			}
+		case config.GenericORTBFieldPublisherID:
+			if bidderRequest.Site != nil {
+				bidderRequest.Site.Publisher = publisherWithID(bidderRequest.Site.Publisher, value)
+			}
+			if bidderRequest.App != nil {
+				bidderRequest.App.Publisher = publisherWithID(bidderRequest.App.Publisher, value)
+			}
+		}
+	}
+	return &bidderRequest
+}
+
+func publisherWithID(publisher *openrtb2.Publisher, id string) *openrtb2.Publisher {
+	var publisherCopy openrtb2.Publisher
+	if publisher != nil {
+		publisherCopy = *publisher
+	}
+	publisherCopy.ID = id
+	return &publisherCopy
+}
+
+func (a *adapter) MakeBids(internalRequest *openrtb2.BidRequest, externalRequest *adapters.RequestData, response *adapters.ResponseData) (*adapters.BidderResponse, []error) {
+	if response.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+	if response.StatusCode == http.StatusBadRequest {
+		return nil, []error{&errortypes.BadInput{
+			Message: fmt.Sprintf("Unexpected status code: %d. Run with request.debug = 1 for more info", response.StatusCode),
+		}}
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, []error{&errortypes.BadServerResponse{
+			Message: fmt.Sprintf("Unexpected status code: %d. Run with request.debug = 1 for more info", response.StatusCode),
+		}}
+	}
+
+	var bidResp openrtb2.BidResponse
+	if err := json.Unmarshal(response.Body, &bidResp); err != nil {
+		return nil, []error{&errortypes.BadServerResponse{
+			Message: fmt.Sprintf("Bad server response: %v", err),
+		}}
+	}
+
+	var errs []error
+	bidResponse := adapters.NewBidderResponseWithBidsCapacity(len(internalRequest.Imp))
+	if bidResp.Cur != "" {
+		bidResponse.Currency = bidResp.Cur
+	}
+	for _, seatBid := range bidResp.SeatBid {
+		for i := range seatBid.Bid {
+			bidType, err := getBidType(seatBid.Bid[i], internalRequest.Imp)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			bidResponse.Bids = append(bidResponse.Bids, &adapters.TypedBid{
+				Bid:     &seatBid.Bid[i],
+				BidType: bidType,
+			})
+		}
+	}
+	return bidResponse, errs
+}
+
+// getBidType reads the media type of a bid from bid.ext.prebid.type, falling back on the media type of its imp. The
+// imp is expected to hold a single media type in the latter case.
+func getBidType(bid openrtb2.Bid, imps []openrtb2.Imp) (openrtb_ext.BidType, error) {
+	if bid.Ext != nil {
+		var bidExt openrtb_ext.ExtBid
+		if err := json.Unmarshal(bid.Ext, &bidExt); err == nil && bidExt.Prebid != nil {
+			if bidType, err := openrtb_ext.ParseBidType(string(bidExt.Prebid.Type)); err == nil {
+				return bidType, nil
+			}
+		}
+	}
+
+	for _, imp := range imps {
+		if imp.ID != bid.ImpID {
+			continue
+		}
+		switch {
+		case imp.Banner != nil:
+			return openrtb_ext.BidTypeBanner, nil
+		case imp.Video != nil:
+			return openrtb_ext.BidTypeVideo, nil
+		case imp.Native != nil:
+			return openrtb_ext.BidTypeNative, nil
+		case imp.Audio != nil:
+			return openrtb_ext.BidTypeAudio, nil
+		}
+	}
+	return "", &errortypes.BadServerResponse{
+		Message: fmt.Sprintf("Failed to find the media type of the bid for imp %s", bid.ImpID),
+	}
+}
@@ -0,0 +1,50 @@
+package genericortb
+
+import (
+	"testing"
+
+	"github.com/prebid/prebid-server/adapters/adapterstest"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJsonSamples(t *testing.T) {
+	bidder, buildErr := Builder(openrtb_ext.BidderName("somegenericbidder"), config.Adapter{
+		Endpoint: "http://{{.Host}}/bid?zone={{.ZoneID}}",
+		GenericORTB: &config.GenericORTB{
+			MediaTypes: []openrtb_ext.BidType{openrtb_ext.BidTypeBanner, openrtb_ext.BidTypeVideo},
+			EndpointMacros: config.GenericORTBMacros{
+				Host:   "host",
+				ZoneID: "zoneId",
+			},
+			FieldMappings: []config.GenericORTBFieldMapping{
+				{Param: "tagId", Field: config.GenericORTBFieldImpTagID},
+				{Param: "publisherId", Field: config.GenericORTBFieldPublisherID},
+			},
+		},
+	})
+
+	if buildErr != nil {
+		t.Fatalf("Builder returned unexpected error %v", buildErr)
+	}
+
+	adapterstest.RunJSONBidderTest(t, "genericortbtest", bidder)
+}
+
+func TestEndpointTemplateMalformed(t *testing.T) {
+	_, buildErr := Builder(openrtb_ext.BidderName("somegenericbidder"), config.Adapter{
+		Endpoint:    "{{Malformed}}",
+		GenericORTB: &config.GenericORTB{},
+	})
+
+	assert.Error(t, buildErr)
+}
+
+func TestBuilderWithoutGenericORTBConfig(t *testing.T) {
+	_, buildErr := Builder(openrtb_ext.BidderName("somegenericbidder"), config.Adapter{
+		Endpoint: "http://somebidder.com/bid",
+	})
+
+	assert.EqualError(t, buildErr, "the generic_ortb config of somegenericbidder is missing")
+}
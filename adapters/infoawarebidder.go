@@ -6,6 +6,7 @@ import (
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
@@ -13,7 +14,7 @@ import (
 // media types defined in the static/bidder-info/{bidder}.yaml file.
 //
 // It adjusts incoming requests in the following ways:
-//   1. If App or Site traffic is not supported by the info file, then requests from
+//   1. If App, Site or DOOH traffic is not supported by the info file, then requests from
 //      those sources will be rejected before the delegate is called.
 //   2. If a given MediaType is not supported for the platform, then it will be set
 //      to nil before the request is forwarded to the delegate.
@@ -35,13 +36,21 @@ func BuildInfoAwareBidder(bidder Bidder, info config.BidderInfo) Bidder {
 func (i *InfoAwareBidder) MakeRequests(request *openrtb2.BidRequest, reqInfo *ExtraRequestInfo) ([]*RequestData, []error) {
 	var allowedMediaTypes parsedSupports
 
-	if request.Site != nil {
+	// The requests of the dooh channel are shaped as site or app requests, but only go to the dooh bidders
+	isDOOH := reqInfo != nil && reqInfo.PbsEntryPoint == metrics.ReqTypeORTB2DOOH
+	if isDOOH {
+		if !i.info.dooh.enabled {
+			return nil, []error{&errortypes.BadInput{Message: "this bidder does not support dooh requests"}}
+		}
+		allowedMediaTypes = i.info.dooh
+	}
+	if request.Site != nil && !isDOOH {
 		if !i.info.site.enabled {
 			return nil, []error{&errortypes.BadInput{Message: "this bidder does not support site requests"}}
 		}
 		allowedMediaTypes = i.info.site
 	}
-	if request.App != nil {
+	if request.App != nil && !isDOOH {
 		if !i.info.app.enabled {
 			return nil, []error{&errortypes.BadInput{Message: "this bidder does not support app requests"}}
 		}
@@ -136,6 +145,7 @@ func filterImps(imps []openrtb2.Imp, numToFilter int) ([]openrtb2.Imp, []error)
 type parsedBidderInfo struct {
 	app  parsedSupports
 	site parsedSupports
+	dooh parsedSupports
 }
 
 type parsedSupports struct {
@@ -156,5 +166,9 @@ func parseBidderInfo(info config.BidderInfo) parsedBidderInfo {
 		parsedInfo.site.enabled = true
 		parsedInfo.site.banner, parsedInfo.site.video, parsedInfo.site.audio, parsedInfo.site.native = parseAllowedTypes(info.Capabilities.Site.MediaTypes)
 	}
+	if info.Capabilities != nil && info.Capabilities.DOOH != nil {
+		parsedInfo.dooh.enabled = true
+		parsedInfo.dooh.banner, parsedInfo.dooh.video, parsedInfo.dooh.audio, parsedInfo.dooh.native = parseAllowedTypes(info.Capabilities.DOOH.MediaTypes)
+	}
 	return parsedInfo
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/prebid/prebid-server/adapters"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/stretchr/testify/assert"
 )
@@ -56,6 +57,53 @@ func TestSiteNotSupported(t *testing.T) {
 	assert.Len(t, bids, 0)
 }
 
+func TestDOOHNotSupported(t *testing.T) {
+	bidder := &mockBidder{}
+	info := config.BidderInfo{
+		Capabilities: &config.CapabilitiesInfo{
+			Site: &config.PlatformInfo{
+				MediaTypes: []openrtb_ext.BidType{openrtb_ext.BidTypeBanner},
+			},
+		},
+	}
+	constrained := adapters.BuildInfoAwareBidder(bidder, info)
+	bids, errs := constrained.MakeRequests(&openrtb2.BidRequest{
+		Imp:  []openrtb2.Imp{{ID: "imp-1", Banner: &openrtb2.Banner{}}},
+		Site: &openrtb2.Site{},
+	}, &adapters.ExtraRequestInfo{PbsEntryPoint: metrics.ReqTypeORTB2DOOH})
+	if !assert.Len(t, errs, 1) {
+		return
+	}
+	assert.EqualError(t, errs[0], "this bidder does not support dooh requests")
+	assert.IsType(t, &errortypes.BadInput{}, errs[0])
+	assert.Len(t, bids, 0)
+}
+
+func TestDOOHSupported(t *testing.T) {
+	bidder := &mockBidder{}
+	info := config.BidderInfo{
+		Capabilities: &config.CapabilitiesInfo{
+			DOOH: &config.PlatformInfo{
+				MediaTypes: []openrtb_ext.BidType{openrtb_ext.BidTypeBanner},
+			},
+		},
+	}
+	constrained := adapters.BuildInfoAwareBidder(bidder, info)
+	bids, errs := constrained.MakeRequests(&openrtb2.BidRequest{
+		Imp: []openrtb2.Imp{
+			{ID: "imp-1", Banner: &openrtb2.Banner{}},
+			{ID: "imp-2", Video: &openrtb2.Video{}},
+		},
+		Site: &openrtb2.Site{},
+	}, &adapters.ExtraRequestInfo{PbsEntryPoint: metrics.ReqTypeORTB2DOOH})
+
+	assert.Equal(t, []error{
+		&errortypes.BadInput{Message: "request.imp[1] uses video, but this bidder doesn't support it"},
+		&errortypes.BadInput{Message: "request.imp[1] has no supported MediaTypes. It will be ignored"},
+	}, errs)
+	assert.Len(t, bids, 1)
+}
+
 func TestImpFiltering(t *testing.T) {
 	bidder := &mockBidder{}
 	info := config.BidderInfo{
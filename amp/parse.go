@@ -11,15 +11,18 @@ import (
 
 // Params defines the paramters of an AMP request.
 type Params struct {
-	Account         string
-	CanonicalURL    string
-	Consent         string
-	Debug           bool
-	Origin          string
-	Size            Size
-	Slot            string
-	StoredRequestID string
-	Timeout         *uint64
+	Account           string
+	AdditionalConsent string
+	CanonicalURL      string
+	Consent           string
+	Debug             bool
+	GPP               string
+	GPPSectionIDs     []int8
+	Origin            string
+	Size              Size
+	Slot              string
+	StoredRequestID   string
+	Timeout           *uint64
 }
 
 // Size defines size information of an AMP request.
@@ -41,11 +44,14 @@ func ParseParams(httpRequest *http.Request) (Params, error) {
 	}
 
 	params := Params{
-		Account:      query.Get("account"),
-		CanonicalURL: query.Get("curl"),
-		Consent:      chooseConsent(query.Get("consent_string"), query.Get("gdpr_consent")),
-		Debug:        query.Get("debug") == "1",
-		Origin:       query.Get("__amp_source_origin"),
+		Account:           query.Get("account"),
+		AdditionalConsent: query.Get("addtl_consent"),
+		CanonicalURL:      query.Get("curl"),
+		Consent:           chooseConsent(query.Get("consent_string"), query.Get("gdpr_consent")),
+		Debug:             query.Get("debug") == "1",
+		GPP:               query.Get("gpp"),
+		GPPSectionIDs:     parseSectionIDs(query.Get("gpp_sid")),
+		Origin:            query.Get("__amp_source_origin"),
 		Size: Size{
 			Height:         parseInt(query.Get("h")),
 			Multisize:      parseMultisize(query.Get("ms")),
@@ -99,6 +105,25 @@ func parseMultisize(multisize string) []openrtb2.Format {
 	return sizes
 }
 
+// parseSectionIDs parses the comma separated ids of the GPP sections which apply to the request. The whole list is
+// dropped if any id is invalid, since a partial list would misstate which laws apply.
+func parseSectionIDs(sectionIDs string) []int8 {
+	if sectionIDs == "" {
+		return nil
+	}
+
+	idStrings := strings.Split(sectionIDs, ",")
+	ids := make([]int8, 0, len(idStrings))
+	for _, idString := range idStrings {
+		id, err := strconv.ParseInt(strings.TrimSpace(idString), 10, 8)
+		if err != nil || id < 0 {
+			return nil
+		}
+		ids = append(ids, int8(id))
+	}
+	return ids
+}
+
 func chooseConsent(consent, gdprConsent string) string {
 	if len(consent) > 0 {
 		return consent
@@ -25,16 +25,19 @@ func TestParseParams(t *testing.T) {
 		{
 			description: "All Fields",
 			query: "tag_id=anyTagID&account=anyAccount&curl=anyCurl&consent_string=anyConsent&debug=1&__amp_source_origin=anyOrigin" +
-				"&slot=anySlot&timeout=42&h=1&w=2&oh=3&ow=4&ms=10x11,12x13",
+				"&slot=anySlot&timeout=42&h=1&w=2&oh=3&ow=4&ms=10x11,12x13&gpp=anyGPP&gpp_sid=2,6&addtl_consent=anyAdditionalConsent",
 			expectedParams: Params{
-				Account:         "anyAccount",
-				CanonicalURL:    "anyCurl",
-				Consent:         "anyConsent",
-				Debug:           true,
-				Origin:          "anyOrigin",
-				Slot:            "anySlot",
-				StoredRequestID: "anyTagID",
-				Timeout:         &expectedTimeout,
+				Account:           "anyAccount",
+				AdditionalConsent: "anyAdditionalConsent",
+				CanonicalURL:      "anyCurl",
+				Consent:           "anyConsent",
+				Debug:             true,
+				GPP:               "anyGPP",
+				GPPSectionIDs:     []int8{2, 6},
+				Origin:            "anyOrigin",
+				Slot:              "anySlot",
+				StoredRequestID:   "anyTagID",
+				Timeout:           &expectedTimeout,
 				Size: Size{
 					Height:         1,
 					OverrideHeight: 3,
@@ -48,7 +51,7 @@ func TestParseParams(t *testing.T) {
 		},
 		{
 			description:    "Integer Values Ignored If Invalid",
-			query:          "tag_id=anyTagID&h=invalid&w=invalid&oh=invalid&ow=invalid&ms=invalid",
+			query:          "tag_id=anyTagID&h=invalid&w=invalid&oh=invalid&ow=invalid&ms=invalid&gpp_sid=2,invalid",
 			expectedParams: Params{StoredRequestID: "anyTagID"},
 		},
 		{
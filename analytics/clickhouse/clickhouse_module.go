@@ -0,0 +1,145 @@
+package clickhouse
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+)
+
+// OptionsSchema is the JSON schema of the options the accounts give to the module, which takes none
+const OptionsSchema = `{"type": "object", "additionalProperties": false}`
+
+// ClickHouseModule inserts the auction, amp, video, setuid and cookie sync events into ClickHouse, as the flattened
+// rows of the auctions, bids, setuids and cookie_syncs tables. The queued rows are inserted at the shutdown.
+type ClickHouseModule struct {
+	auctions    *inserter
+	bids        *inserter
+	setUIDs     *inserter
+	cookieSyncs *inserter
+	sigTermCh   chan os.Signal
+	now         func() time.Time
+}
+
+func NewClickHouseModule(client *http.Client, cfg config.ClickHouseAnalytics) (analytics.PBSAnalyticsModule, error) {
+	glog.Infof("[clickhouse] Initializing module endpoint=%s database=%s", cfg.Endpoint, cfg.Database)
+
+	m := newModule(client, cfg)
+	signal.Notify(m.sigTermCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-m.sigTermCh
+		m.close()
+	}()
+
+	glog.Info("[clickhouse] ClickHouse analytics configured and ready")
+	return m, nil
+}
+
+func newModule(client *http.Client, cfg config.ClickHouseAnalytics) *ClickHouseModule {
+	return &ClickHouseModule{
+		auctions:    newInserter(cfg, client, auctionsTable),
+		bids:        newInserter(cfg, client, bidsTable),
+		setUIDs:     newInserter(cfg, client, setUIDsTable),
+		cookieSyncs: newInserter(cfg, client, cookieSyncsTable),
+		sigTermCh:   make(chan os.Signal, 1),
+		now:         time.Now,
+	}
+}
+
+func (m *ClickHouseModule) LogAuctionObject(ao *analytics.AuctionObject) {
+	m.logAuction(auctionEvent{
+		endpoint:  auctionEndpoint,
+		status:    ao.Status,
+		errors:    ao.Errors,
+		request:   ao.Request,
+		response:  ao.Response,
+		account:   ao.Account,
+		startTime: ao.StartTime,
+	})
+}
+
+func (m *ClickHouseModule) LogVideoObject(vo *analytics.VideoObject) {
+	m.logAuction(auctionEvent{
+		endpoint:  videoEndpoint,
+		status:    vo.Status,
+		errors:    vo.Errors,
+		request:   vo.Request,
+		response:  vo.Response,
+		account:   vo.Account,
+		startTime: vo.StartTime,
+	})
+}
+
+func (m *ClickHouseModule) LogAmpObject(ao *analytics.AmpObject) {
+	m.logAuction(auctionEvent{
+		endpoint:  ampEndpoint,
+		status:    ao.Status,
+		errors:    ao.Errors,
+		request:   ao.Request,
+		response:  ao.AuctionResponse,
+		account:   ao.Account,
+		origin:    ao.Origin,
+		startTime: ao.StartTime,
+	})
+}
+
+func (m *ClickHouseModule) LogSetUIDObject(so *analytics.SetUIDObject) {
+	m.insert(m.setUIDs, setUIDRow{
+		Timestamp: formatTimestamp(m.now()),
+		Status:    so.Status,
+		Bidder:    so.Bidder,
+		Success:   so.Success,
+		Errors:    errorStrings(so.Errors),
+	})
+}
+
+func (m *ClickHouseModule) LogCookieSyncObject(cso *analytics.CookieSyncObject) {
+	row := cookieSyncRow{
+		Timestamp:       formatTimestamp(m.now()),
+		Status:          cso.Status,
+		Bidders:         make([]string, 0, len(cso.BidderStatus)),
+		NoCookieBidders: []string{},
+		Errors:          errorStrings(cso.Errors),
+	}
+	for _, bidder := range cso.BidderStatus {
+		row.Bidders = append(row.Bidders, bidder.BidderCode)
+		if bidder.NoCookie {
+			row.NoCookieBidders = append(row.NoCookieBidders, bidder.BidderCode)
+		}
+	}
+	m.insert(m.cookieSyncs, row)
+}
+
+func (m *ClickHouseModule) LogNotificationEventObject(ne *analytics.NotificationEvent) {
+}
+
+func (m *ClickHouseModule) logAuction(event auctionEvent) {
+	auction, bids := newAuctionRows(event, m.now())
+	m.insert(m.auctions, auction)
+	for _, bid := range bids {
+		m.insert(m.bids, bid)
+	}
+}
+
+func (m *ClickHouseModule) insert(table *inserter, row interface{}) {
+	encoded, err := json.Marshal(row)
+	if err != nil {
+		glog.Warningf("[clickhouse] Cannot serialize a row of the %s table: %v", table.table, err)
+		return
+	}
+	table.insert(encoded)
+}
+
+// close inserts the queued rows of all the tables
+func (m *ClickHouseModule) close() {
+	m.auctions.close()
+	m.bids.close()
+	m.setUIDs.close()
+	m.cookieSyncs.close()
+}
@@ -0,0 +1,179 @@
+package clickhouse
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClickHouse struct {
+	server *httptest.Server
+
+	mutex   sync.Mutex
+	inserts map[string][][]map[string]interface{}
+}
+
+func newFakeClickHouse(t *testing.T) *fakeClickHouse {
+	fake := &fakeClickHouse{inserts: make(map[string][][]map[string]interface{})}
+	fake.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "pbs", r.Header.Get("X-ClickHouse-User"))
+		assert.Equal(t, "secret", r.Header.Get("X-ClickHouse-Key"))
+		assert.Equal(t, "1", r.URL.Query().Get("async_insert"))
+
+		body, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		var rows []map[string]interface{}
+		scanner := bufio.NewScanner(body)
+		for scanner.Scan() {
+			var row map[string]interface{}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &row))
+			rows = append(rows, row)
+		}
+
+		query := r.URL.Query().Get("query")
+		fake.mutex.Lock()
+		fake.inserts[query] = append(fake.inserts[query], rows)
+		fake.mutex.Unlock()
+	}))
+	t.Cleanup(fake.server.Close)
+	return fake
+}
+
+func (f *fakeClickHouse) inserted(table string) [][]map[string]interface{} {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.inserts["INSERT INTO prebid."+table+" FORMAT JSONEachRow"]
+}
+
+func newTestModule(fake *fakeClickHouse, batchSize int) *ClickHouseModule {
+	module := newModule(fake.server.Client(), config.ClickHouseAnalytics{
+		Enabled:         true,
+		Endpoint:        fake.server.URL + "/",
+		Database:        "prebid",
+		Username:        "pbs",
+		Password:        "secret",
+		BatchSize:       batchSize,
+		FlushIntervalMs: 60000,
+		TimeoutMs:       1000,
+		BufferSize:      10,
+	})
+	module.now = func() time.Time { return time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC) }
+	return module
+}
+
+func TestLogAuctionObject(t *testing.T) {
+	fake := newFakeClickHouse(t)
+	module := newTestModule(fake, 10)
+
+	module.LogAuctionObject(&analytics.AuctionObject{
+		Status:  http.StatusOK,
+		Request: &openrtb2.BidRequest{ID: "req", Imp: []openrtb2.Imp{{ID: "imp"}}},
+		Response: &openrtb2.BidResponse{
+			Cur: "USD",
+			SeatBid: []openrtb2.SeatBid{{
+				Seat: "appnexus",
+				Bid:  []openrtb2.Bid{{ID: "bid", ImpID: "imp", Price: 1.5}},
+			}},
+		},
+		Account:   &config.Account{ID: "acct"},
+		StartTime: time.Date(2021, 6, 1, 11, 59, 59, 750000000, time.UTC),
+	})
+	module.LogAmpObject(&analytics.AmpObject{Status: http.StatusBadRequest, Errors: []error{errors.New("invalid")}, Origin: "https://publisher.com"})
+	module.LogVideoObject(&analytics.VideoObject{Status: http.StatusOK})
+	module.close()
+
+	auctions := fake.inserted(auctionsTable)
+	require.Len(t, auctions, 1, "The auctions should be inserted in a single batch")
+	require.Len(t, auctions[0], 3)
+	assert.Equal(t, "2021-06-01 11:59:59.750", auctions[0][0]["timestamp"])
+	assert.Equal(t, "auction", auctions[0][0]["endpoint"])
+	assert.Equal(t, "acct", auctions[0][0]["account_id"])
+	assert.Equal(t, float64(250), auctions[0][0]["duration_ms"])
+	assert.Equal(t, float64(1), auctions[0][0]["bid_count"])
+	assert.Equal(t, []interface{}{"appnexus"}, auctions[0][0]["seats"])
+	assert.Equal(t, "amp", auctions[0][1]["endpoint"])
+	assert.Equal(t, "2021-06-01 12:00:00.000", auctions[0][1]["timestamp"], "The time of the event should be used without a start time")
+	assert.Equal(t, "https://publisher.com", auctions[0][1]["origin"])
+	assert.Equal(t, []interface{}{"invalid"}, auctions[0][1]["errors"])
+	assert.Equal(t, "video", auctions[0][2]["endpoint"])
+
+	bids := fake.inserted(bidsTable)
+	require.Len(t, bids, 1)
+	require.Len(t, bids[0], 1)
+	assert.Equal(t, "req", bids[0][0]["request_id"])
+	assert.Equal(t, "appnexus", bids[0][0]["seat"])
+	assert.Equal(t, 1.5, bids[0][0]["price"])
+}
+
+func TestLogSetUIDAndCookieSyncObjects(t *testing.T) {
+	fake := newFakeClickHouse(t)
+	module := newTestModule(fake, 1)
+
+	module.LogSetUIDObject(&analytics.SetUIDObject{Status: http.StatusOK, Bidder: "appnexus", UID: "uid", Success: true})
+	module.LogSetUIDObject(&analytics.SetUIDObject{Status: http.StatusBadRequest, Bidder: "rubicon"})
+	module.LogCookieSyncObject(&analytics.CookieSyncObject{
+		Status: http.StatusOK,
+		BidderStatus: []*analytics.CookieSyncBidder{
+			{BidderCode: "appnexus", NoCookie: true},
+			{BidderCode: "rubicon"},
+		},
+	})
+	module.LogNotificationEventObject(&analytics.NotificationEvent{})
+	module.close()
+
+	setUIDs := fake.inserted(setUIDsTable)
+	require.Len(t, setUIDs, 2, "Each full batch should be inserted")
+	assert.Equal(t, map[string]interface{}{
+		"timestamp": "2021-06-01 12:00:00.000",
+		"status":    float64(http.StatusOK),
+		"bidder":    "appnexus",
+		"success":   true,
+		"errors":    []interface{}{},
+	}, setUIDs[0][0])
+	assert.Equal(t, "rubicon", setUIDs[1][0]["bidder"])
+
+	cookieSyncs := fake.inserted(cookieSyncsTable)
+	require.Len(t, cookieSyncs, 1)
+	assert.Equal(t, []interface{}{"appnexus", "rubicon"}, cookieSyncs[0][0]["bidders"])
+	assert.Equal(t, []interface{}{"appnexus"}, cookieSyncs[0][0]["no_cookie_bidders"])
+
+	assert.Empty(t, fake.inserted(auctionsTable))
+}
+
+func TestInsertDropsWhenFull(t *testing.T) {
+	table := &inserter{
+		table: auctionsTable,
+		rows:  make(chan []byte, 1),
+	}
+
+	table.insert([]byte(`{}`))
+	table.insert([]byte(`{}`))
+	table.insert([]byte(`{}`))
+
+	assert.Len(t, table.rows, 1)
+	assert.Equal(t, uint64(2), table.dropped, "The rows past the size of the queue should be dropped and counted")
+}
+
+func TestInsertAfterClose(t *testing.T) {
+	fake := newFakeClickHouse(t)
+	module := newTestModule(fake, 10)
+
+	module.close()
+	module.close()
+	module.LogSetUIDObject(&analytics.SetUIDObject{Bidder: "appnexus"})
+
+	assert.Empty(t, fake.inserted(setUIDsTable))
+}
@@ -0,0 +1,163 @@
+package clickhouse
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/config"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// inserter inserts the rows of a table in batches, through the HTTP interface of ClickHouse. A batch is inserted
+// once full, and the rows batched so far are inserted at every flush interval. The batches are
+// inserted one at a time, so the rows are queued while ClickHouse is slow and dropped once the queue is full.
+type inserter struct {
+	// dropped counts the rows dropped since the last insert. It comes first, to be 64 bit aligned for atomic access.
+	dropped uint64
+
+	client        *http.Client
+	url           string
+	username      string
+	password      string
+	table         string
+	batchSize     int
+	flushInterval time.Duration
+	timeout       time.Duration
+	rows          chan []byte
+	done          chan struct{}
+
+	closeMutex sync.RWMutex
+	closed     bool
+}
+
+func newInserter(cfg config.ClickHouseAnalytics, client *http.Client, table string) *inserter {
+	// The inserts are async, so that ClickHouse merges the batches of all the instances before writing them, and
+	// wait for the rows to be written, so that the failed inserts are logged.
+	query := url.Values{}
+	query.Set("query", fmt.Sprintf("INSERT INTO %s.%s FORMAT JSONEachRow", cfg.Database, table))
+	query.Set("async_insert", "1")
+	query.Set("wait_for_async_insert", "1")
+
+	i := &inserter{
+		client:        client,
+		url:           strings.TrimSuffix(cfg.Endpoint, "/") + "/?" + query.Encode(),
+		username:      cfg.Username,
+		password:      cfg.Password,
+		table:         table,
+		batchSize:     cfg.BatchSize,
+		flushInterval: time.Duration(cfg.FlushIntervalMs) * time.Millisecond,
+		timeout:       time.Duration(cfg.TimeoutMs) * time.Millisecond,
+		rows:          make(chan []byte, cfg.BufferSize),
+		done:          make(chan struct{}),
+	}
+	go i.run()
+	return i
+}
+
+// insert queues a JSON encoded row to be inserted. The row is dropped if the queue is full.
+func (i *inserter) insert(row []byte) {
+	i.closeMutex.RLock()
+	defer i.closeMutex.RUnlock()
+
+	if i.closed {
+		return
+	}
+	select {
+	case i.rows <- row:
+	default:
+		atomic.AddUint64(&i.dropped, 1)
+	}
+}
+
+func (i *inserter) run() {
+	defer close(i.done)
+	ticker := time.NewTicker(i.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, i.batchSize)
+	for {
+		select {
+		case row, ok := <-i.rows:
+			if !ok {
+				i.post(batch)
+				return
+			}
+			batch = append(batch, row)
+			if len(batch) < i.batchSize {
+				continue
+			}
+		case <-ticker.C:
+		}
+		i.post(batch)
+		batch = batch[:0]
+	}
+}
+
+func (i *inserter) post(batch [][]byte) {
+	if dropped := atomic.SwapUint64(&i.dropped, 0); dropped > 0 {
+		glog.Warningf("[clickhouse] The queue of the %s table is full, %d rows were dropped", i.table, dropped)
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	for _, row := range batch {
+		gz.Write(row)
+		gz.Write([]byte{'\n'})
+	}
+	if err := gz.Close(); err != nil {
+		glog.Errorf("[clickhouse] Failed to compress %d rows of the %s table: %v", len(batch), i.table, err)
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, i.url, &body)
+	if err != nil {
+		glog.Errorf("[clickhouse] Failed to insert %d rows into the %s table: %v", len(batch), i.table, err)
+		return
+	}
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("X-ClickHouse-User", i.username)
+	if i.password != "" {
+		req.Header.Set("X-ClickHouse-Key", i.password)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), i.timeout)
+	defer cancel()
+	resp, err := ctxhttp.Do(ctx, i.client, req)
+	if err != nil {
+		glog.Errorf("[clickhouse] Failed to insert %d rows into the %s table: %v", len(batch), i.table, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		glog.Errorf("[clickhouse] Failed to insert %d rows into the %s table: ClickHouse responded with status %d: %s", len(batch), i.table, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+}
+
+// close inserts the queued rows before stopping
+func (i *inserter) close() {
+	i.closeMutex.Lock()
+	if i.closed {
+		i.closeMutex.Unlock()
+		return
+	}
+	i.closed = true
+	close(i.rows)
+	i.closeMutex.Unlock()
+
+	<-i.done
+}
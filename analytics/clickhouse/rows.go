@@ -0,0 +1,233 @@
+package clickhouse
+
+import (
+	"time"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+)
+
+// The tables the rows are inserted into, as created by the schema in the README
+const (
+	auctionsTable    = "auctions"
+	bidsTable        = "bids"
+	setUIDsTable     = "setuids"
+	cookieSyncsTable = "cookie_syncs"
+)
+
+// The endpoints the rows of the auctions and bids tables come from
+const (
+	auctionEndpoint = "auction"
+	ampEndpoint     = "amp"
+	videoEndpoint   = "video"
+)
+
+const timestampFormat = "2006-01-02 15:04:05.000"
+
+// auctionRow is a row of the auctions table, flattening an auction of any of the endpoints
+type auctionRow struct {
+	Timestamp  string   `json:"timestamp"`
+	Endpoint   string   `json:"endpoint"`
+	Status     int      `json:"status"`
+	DurationMs int64    `json:"duration_ms"`
+	RequestID  string   `json:"request_id"`
+	AccountID  string   `json:"account_id"`
+	SiteDomain string   `json:"site_domain"`
+	AppBundle  string   `json:"app_bundle"`
+	DeviceType int      `json:"device_type"`
+	DeviceOS   string   `json:"device_os"`
+	Country    string   `json:"country"`
+	ImpCount   int      `json:"imp_count"`
+	MediaTypes []string `json:"media_types"`
+	Test       bool     `json:"test"`
+	TMax       int64    `json:"tmax"`
+	BidCount   int      `json:"bid_count"`
+	Seats      []string `json:"seats"`
+	MaxPrice   float64  `json:"max_price"`
+	Currency   string   `json:"currency"`
+	Origin     string   `json:"origin"`
+	Errors     []string `json:"errors"`
+}
+
+// bidRow is a row of the bids table, with a bid of the response of an auction
+type bidRow struct {
+	Timestamp  string   `json:"timestamp"`
+	Endpoint   string   `json:"endpoint"`
+	RequestID  string   `json:"request_id"`
+	AccountID  string   `json:"account_id"`
+	Seat       string   `json:"seat"`
+	ImpID      string   `json:"imp_id"`
+	BidID      string   `json:"bid_id"`
+	Price      float64  `json:"price"`
+	Currency   string   `json:"currency"`
+	DealID     string   `json:"deal_id"`
+	CreativeID string   `json:"creative_id"`
+	ADomain    []string `json:"adomain"`
+	Width      int64    `json:"width"`
+	Height     int64    `json:"height"`
+}
+
+// setUIDRow is a row of the setuids table
+type setUIDRow struct {
+	Timestamp string   `json:"timestamp"`
+	Status    int      `json:"status"`
+	Bidder    string   `json:"bidder"`
+	Success   bool     `json:"success"`
+	Errors    []string `json:"errors"`
+}
+
+// cookieSyncRow is a row of the cookie_syncs table
+type cookieSyncRow struct {
+	Timestamp       string   `json:"timestamp"`
+	Status          int      `json:"status"`
+	Bidders         []string `json:"bidders"`
+	NoCookieBidders []string `json:"no_cookie_bidders"`
+	Errors          []string `json:"errors"`
+}
+
+type auctionEvent struct {
+	endpoint  string
+	status    int
+	errors    []error
+	request   *openrtb2.BidRequest
+	response  *openrtb2.BidResponse
+	account   *config.Account
+	origin    string
+	startTime time.Time
+}
+
+// newAuctionRows flattens the auction into its row of the auctions table and the rows of its bids
+func newAuctionRows(event auctionEvent, now time.Time) (auctionRow, []bidRow) {
+	at := now
+	if !event.startTime.IsZero() {
+		at = event.startTime
+	}
+	row := auctionRow{
+		Timestamp:  formatTimestamp(at),
+		Endpoint:   event.endpoint,
+		Status:     event.status,
+		AccountID:  accountID(event.request, event.account),
+		MediaTypes: []string{},
+		Seats:      []string{},
+		Origin:     event.origin,
+		Errors:     errorStrings(event.errors),
+	}
+	if !event.startTime.IsZero() {
+		row.DurationMs = now.Sub(event.startTime).Milliseconds()
+	}
+
+	if req := event.request; req != nil {
+		row.RequestID = req.ID
+		row.ImpCount = len(req.Imp)
+		row.MediaTypes = mediaTypes(req.Imp)
+		row.Test = req.Test == 1
+		row.TMax = req.TMax
+		if req.Site != nil {
+			row.SiteDomain = req.Site.Domain
+		}
+		if req.App != nil {
+			row.AppBundle = req.App.Bundle
+		}
+		if req.Device != nil {
+			row.DeviceType = int(req.Device.DeviceType)
+			row.DeviceOS = req.Device.OS
+			if req.Device.Geo != nil {
+				row.Country = req.Device.Geo.Country
+			}
+		}
+	}
+
+	resp := event.response
+	if resp == nil {
+		return row, []bidRow{}
+	}
+	row.Currency = resp.Cur
+	bids := make([]bidRow, 0)
+	for _, seatBid := range resp.SeatBid {
+		if len(seatBid.Bid) > 0 {
+			row.Seats = append(row.Seats, seatBid.Seat)
+		}
+		for _, bid := range seatBid.Bid {
+			row.BidCount++
+			if bid.Price > row.MaxPrice {
+				row.MaxPrice = bid.Price
+			}
+			adomain := bid.ADomain
+			if adomain == nil {
+				adomain = []string{}
+			}
+			bids = append(bids, bidRow{
+				Timestamp:  row.Timestamp,
+				Endpoint:   row.Endpoint,
+				RequestID:  row.RequestID,
+				AccountID:  row.AccountID,
+				Seat:       seatBid.Seat,
+				ImpID:      bid.ImpID,
+				BidID:      bid.ID,
+				Price:      bid.Price,
+				Currency:   resp.Cur,
+				DealID:     bid.DealID,
+				CreativeID: bid.CrID,
+				ADomain:    adomain,
+				Width:      bid.W,
+				Height:     bid.H,
+			})
+		}
+	}
+	return row, bids
+}
+
+// accountID is the id of the account of the auction, or the publisher of the request if the account isn't known
+func accountID(req *openrtb2.BidRequest, account *config.Account) string {
+	if account != nil && account.ID != "" {
+		return account.ID
+	}
+	if req == nil {
+		return ""
+	}
+	if req.Site != nil && req.Site.Publisher != nil {
+		return req.Site.Publisher.ID
+	}
+	if req.App != nil && req.App.Publisher != nil {
+		return req.App.Publisher.ID
+	}
+	return ""
+}
+
+// mediaTypes lists the media types requested by any of the imps
+func mediaTypes(imps []openrtb2.Imp) []string {
+	var banner, video, audio, native bool
+	for _, imp := range imps {
+		banner = banner || imp.Banner != nil
+		video = video || imp.Video != nil
+		audio = audio || imp.Audio != nil
+		native = native || imp.Native != nil
+	}
+
+	types := make([]string, 0, 4)
+	if banner {
+		types = append(types, "banner")
+	}
+	if video {
+		types = append(types, "video")
+	}
+	if audio {
+		types = append(types, "audio")
+	}
+	if native {
+		types = append(types, "native")
+	}
+	return types
+}
+
+func errorStrings(errs []error) []string {
+	strs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		strs = append(strs, err.Error())
+	}
+	return strs
+}
+
+func formatTimestamp(at time.Time) string {
+	return at.UTC().Format(timestampFormat)
+}
@@ -0,0 +1,147 @@
+package clickhouse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAuctionRows(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	event := auctionEvent{
+		endpoint: auctionEndpoint,
+		status:   200,
+		request: &openrtb2.BidRequest{
+			ID:     "req",
+			Imp:    []openrtb2.Imp{{ID: "imp1", Banner: &openrtb2.Banner{}}, {ID: "imp2", Video: &openrtb2.Video{}, Banner: &openrtb2.Banner{}}},
+			Site:   &openrtb2.Site{Domain: "publisher.com", Publisher: &openrtb2.Publisher{ID: "pub"}},
+			Device: &openrtb2.Device{DeviceType: 2, OS: "iOS", Geo: &openrtb2.Geo{Country: "USA"}},
+			Test:   1,
+			TMax:   500,
+		},
+		response: &openrtb2.BidResponse{
+			Cur: "EUR",
+			SeatBid: []openrtb2.SeatBid{
+				{Seat: "appnexus", Bid: []openrtb2.Bid{
+					{ID: "bid1", ImpID: "imp1", Price: 1.2, CrID: "cr1", ADomain: []string{"advertiser.com"}, W: 300, H: 250},
+					{ID: "bid2", ImpID: "imp2", Price: 3.4, DealID: "deal"},
+				}},
+				{Seat: "rubicon"},
+			},
+		},
+		startTime: now.Add(-120 * time.Millisecond),
+	}
+
+	auction, bids := newAuctionRows(event, now)
+
+	assert.Equal(t, auctionRow{
+		Timestamp:  "2021-06-01 11:59:59.880",
+		Endpoint:   auctionEndpoint,
+		Status:     200,
+		DurationMs: 120,
+		RequestID:  "req",
+		AccountID:  "pub",
+		SiteDomain: "publisher.com",
+		DeviceType: 2,
+		DeviceOS:   "iOS",
+		Country:    "USA",
+		ImpCount:   2,
+		MediaTypes: []string{"banner", "video"},
+		Test:       true,
+		TMax:       500,
+		BidCount:   2,
+		Seats:      []string{"appnexus"},
+		MaxPrice:   3.4,
+		Currency:   "EUR",
+		Errors:     []string{},
+	}, auction)
+	assert.Equal(t, []bidRow{
+		{
+			Timestamp:  "2021-06-01 11:59:59.880",
+			Endpoint:   auctionEndpoint,
+			RequestID:  "req",
+			AccountID:  "pub",
+			Seat:       "appnexus",
+			ImpID:      "imp1",
+			BidID:      "bid1",
+			Price:      1.2,
+			Currency:   "EUR",
+			CreativeID: "cr1",
+			ADomain:    []string{"advertiser.com"},
+			Width:      300,
+			Height:     250,
+		},
+		{
+			Timestamp: "2021-06-01 11:59:59.880",
+			Endpoint:  auctionEndpoint,
+			RequestID: "req",
+			AccountID: "pub",
+			Seat:      "appnexus",
+			ImpID:     "imp2",
+			BidID:     "bid2",
+			Price:     3.4,
+			Currency:  "EUR",
+			DealID:    "deal",
+			ADomain:   []string{},
+		},
+	}, bids)
+}
+
+func TestNewAuctionRowsWithoutRequest(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	auction, bids := newAuctionRows(auctionEvent{endpoint: ampEndpoint, status: 400}, now)
+
+	assert.Equal(t, auctionRow{
+		Timestamp:  "2021-06-01 12:00:00.000",
+		Endpoint:   ampEndpoint,
+		Status:     400,
+		MediaTypes: []string{},
+		Seats:      []string{},
+		Errors:     []string{},
+	}, auction)
+	assert.Empty(t, bids)
+}
+
+func TestAccountID(t *testing.T) {
+	testCases := []struct {
+		description  string
+		givenRequest *openrtb2.BidRequest
+		givenAccount *config.Account
+		expectedID   string
+	}{
+		{
+			description:  "Account",
+			givenRequest: &openrtb2.BidRequest{Site: &openrtb2.Site{Publisher: &openrtb2.Publisher{ID: "pub"}}},
+			givenAccount: &config.Account{ID: "acct"},
+			expectedID:   "acct",
+		},
+		{
+			description:  "Site publisher",
+			givenRequest: &openrtb2.BidRequest{Site: &openrtb2.Site{Publisher: &openrtb2.Publisher{ID: "pub"}}},
+			givenAccount: &config.Account{},
+			expectedID:   "pub",
+		},
+		{
+			description:  "App publisher",
+			givenRequest: &openrtb2.BidRequest{App: &openrtb2.App{Publisher: &openrtb2.Publisher{ID: "app-pub"}}},
+			expectedID:   "app-pub",
+		},
+		{
+			description:  "No publisher",
+			givenRequest: &openrtb2.BidRequest{Site: &openrtb2.Site{}},
+			expectedID:   "",
+		},
+		{
+			description: "No request",
+			expectedID:  "",
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedID, accountID(test.givenRequest, test.givenAccount), test.description)
+	}
+}
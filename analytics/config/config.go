@@ -3,18 +3,28 @@ package config
 import (
 	"github.com/golang/glog"
 	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/analytics/clickhouse"
 	"github.com/prebid/prebid-server/analytics/clients"
 	"github.com/prebid/prebid-server/analytics/filesystem"
 	"github.com/prebid/prebid-server/analytics/pubstack"
+	"github.com/prebid/prebid-server/analytics/requestlog"
 	"github.com/prebid/prebid-server/config"
 )
 
+// The names of the analytics modules, under which the accounts configure them
+const (
+	fileModuleName       = "file"
+	pubstackModuleName   = "pubstack"
+	clickHouseModuleName = "clickhouse"
+	requestLogModuleName = "request_log"
+)
+
 //Modules that need to be logged to need to be initialized here
 func NewPBSAnalytics(analytics *config.Analytics) analytics.PBSAnalyticsModule {
-	modules := make(enabledAnalytics, 0)
+	enabled := newEnabledAnalytics(*analytics)
 	if len(analytics.File.Filename) > 0 {
 		if mod, err := filesystem.NewFileLogger(analytics.File.Filename); err == nil {
-			modules = append(modules, mod)
+			enabled.modules[fileModuleName] = mod
 		} else {
 			glog.Fatalf("Could not initialize FileLogger for file %v :%v", analytics.File.Filename, err)
 		}
@@ -29,49 +39,106 @@ func NewPBSAnalytics(analytics *config.Analytics) analytics.PBSAnalyticsModule {
 			analytics.Pubstack.Buffers.BufferSize,
 			analytics.Pubstack.Buffers.Timeout)
 		if err == nil {
-			modules = append(modules, pubstackModule)
+			enabled.modules[pubstackModuleName] = pubstackModule
 		} else {
 			glog.Errorf("Could not initialize PubstackModule: %v", err)
 		}
 	}
-	return modules
+	if analytics.ClickHouse.Enabled {
+		clickHouseModule, err := clickhouse.NewClickHouseModule(clients.GetDefaultHttpInstance(), analytics.ClickHouse)
+		if err == nil {
+			enabled.modules[clickHouseModuleName] = clickHouseModule
+		} else {
+			glog.Errorf("Could not initialize ClickHouseModule: %v", err)
+		}
+	}
+	if analytics.RequestLog.Enabled {
+		if mod, err := requestlog.NewRequestLogger(analytics.RequestLog); err == nil {
+			enabled.modules[requestLogModuleName] = mod
+		} else {
+			glog.Fatalf("Could not initialize RequestLogger for file %v :%v", analytics.RequestLog.File, err)
+		}
+	}
+	return enabled
 }
 
-//Collection of all the correctly configured analytics modules - implements the PBSAnalyticsModule interface
-type enabledAnalytics []analytics.PBSAnalyticsModule
+//Collection of all the correctly configured analytics modules by name - implements the PBSAnalyticsModule interface
+type enabledAnalytics struct {
+	modules map[string]analytics.PBSAnalyticsModule
+	cfg     config.Analytics
+}
+
+func newEnabledAnalytics(cfg config.Analytics) enabledAnalytics {
+	return enabledAnalytics{modules: make(map[string]analytics.PBSAnalyticsModule), cfg: cfg}
+}
 
 func (ea enabledAnalytics) LogAuctionObject(ao *analytics.AuctionObject) {
-	for _, module := range ea {
+	ao = scrubAuctionObject(ao)
+	requestOptions := requestAnalyticsOptions(ao.Request)
+	for name, module := range ea.modules {
+		if !moduleEnabled(name, ao.Account) {
+			continue
+		}
+		if options := moduleOptions(name, ao.Account, requestOptions, &ea.cfg); options != nil {
+			moduleObject := *ao
+			moduleObject.Options = options
+			module.LogAuctionObject(&moduleObject)
+			continue
+		}
 		module.LogAuctionObject(ao)
 	}
 }
 
 func (ea enabledAnalytics) LogVideoObject(vo *analytics.VideoObject) {
-	for _, module := range ea {
+	vo = scrubVideoObject(vo)
+	requestOptions := requestAnalyticsOptions(vo.Request)
+	for name, module := range ea.modules {
+		if !moduleEnabled(name, vo.Account) {
+			continue
+		}
+		if options := moduleOptions(name, vo.Account, requestOptions, &ea.cfg); options != nil {
+			moduleObject := *vo
+			moduleObject.Options = options
+			module.LogVideoObject(&moduleObject)
+			continue
+		}
 		module.LogVideoObject(vo)
 	}
 }
 
 func (ea enabledAnalytics) LogCookieSyncObject(cso *analytics.CookieSyncObject) {
-	for _, module := range ea {
+	for _, module := range ea.modules {
 		module.LogCookieSyncObject(cso)
 	}
 }
 
 func (ea enabledAnalytics) LogSetUIDObject(so *analytics.SetUIDObject) {
-	for _, module := range ea {
+	for _, module := range ea.modules {
 		module.LogSetUIDObject(so)
 	}
 }
 
 func (ea enabledAnalytics) LogAmpObject(ao *analytics.AmpObject) {
-	for _, module := range ea {
+	ao = scrubAmpObject(ao)
+	requestOptions := requestAnalyticsOptions(ao.Request)
+	for name, module := range ea.modules {
+		if !moduleEnabled(name, ao.Account) {
+			continue
+		}
+		if options := moduleOptions(name, ao.Account, requestOptions, &ea.cfg); options != nil {
+			moduleObject := *ao
+			moduleObject.Options = options
+			module.LogAmpObject(&moduleObject)
+			continue
+		}
 		module.LogAmpObject(ao)
 	}
 }
 
 func (ea enabledAnalytics) LogNotificationEventObject(ne *analytics.NotificationEvent) {
-	for _, module := range ea {
-		module.LogNotificationEventObject(ne)
+	for name, module := range ea.modules {
+		if moduleEnabled(name, ne.Account) {
+			module.LogNotificationEventObject(ne)
+		}
 	}
 }
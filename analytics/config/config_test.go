@@ -76,8 +76,8 @@ func (m *sampleModule) LogAmpObject(ao *analytics.AmpObject) { *m.count++ }
 func (m *sampleModule) LogNotificationEventObject(ne *analytics.NotificationEvent) { *m.count++ }
 
 func initAnalytics(count *int) analytics.PBSAnalyticsModule {
-	modules := make(enabledAnalytics, 0)
-	modules = append(modules, &sampleModule{count})
+	modules := newEnabledAnalytics(config.Analytics{})
+	modules.modules["sampleModule"] = &sampleModule{count}
 	return &modules
 }
 
@@ -85,7 +85,7 @@ func TestNewPBSAnalytics(t *testing.T) {
 	pbsAnalytics := NewPBSAnalytics(&config.Analytics{})
 	instance := pbsAnalytics.(enabledAnalytics)
 
-	assert.Equal(t, len(instance), 0)
+	assert.Equal(t, len(instance.modules), 0)
 }
 
 func TestNewPBSAnalytics_FileLogger(t *testing.T) {
@@ -98,7 +98,7 @@ func TestNewPBSAnalytics_FileLogger(t *testing.T) {
 	mod := NewPBSAnalytics(&config.Analytics{File: config.FileLogs{Filename: TEST_DIR + "/test"}})
 	switch modType := mod.(type) {
 	case enabledAnalytics:
-		if len(enabledAnalytics(modType)) != 1 {
+		if len(modType.modules) != 1 {
 			t.Fatalf("Failed to add analytics module")
 		}
 	default:
@@ -108,7 +108,7 @@ func TestNewPBSAnalytics_FileLogger(t *testing.T) {
 	pbsAnalytics := NewPBSAnalytics(&config.Analytics{File: config.FileLogs{Filename: TEST_DIR + "/test"}})
 	instance := pbsAnalytics.(enabledAnalytics)
 
-	assert.Equal(t, len(instance), 1)
+	assert.Equal(t, len(instance.modules), 1)
 }
 
 func TestNewPBSAnalytics_Pubstack(t *testing.T) {
@@ -128,7 +128,7 @@ func TestNewPBSAnalytics_Pubstack(t *testing.T) {
 	})
 	instanceWithoutError := pbsAnalyticsWithoutError.(enabledAnalytics)
 
-	assert.Equal(t, len(instanceWithoutError), 1)
+	assert.Equal(t, len(instanceWithoutError.modules), 1)
 
 	pbsAnalyticsWithError := NewPBSAnalytics(&config.Analytics{
 		Pubstack: config.Pubstack{
@@ -136,5 +136,5 @@ func TestNewPBSAnalytics_Pubstack(t *testing.T) {
 		},
 	})
 	instanceWithError := pbsAnalyticsWithError.(enabledAnalytics)
-	assert.Equal(t, len(instanceWithError), 0)
+	assert.Equal(t, len(instanceWithError.modules), 0)
 }
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/analytics/clickhouse"
+	"github.com/prebid/prebid-server/analytics/filesystem"
+	"github.com/prebid/prebid-server/analytics/pubstack"
+	"github.com/prebid/prebid-server/analytics/requestlog"
+	"github.com/prebid/prebid-server/config"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// optionsSchemas are the JSON schemas of the options of the modules, by module name. The options of the modules
+// without a schema aren't validated.
+var optionsSchemas = mustLoadOptionsSchemas(map[string]string{
+	fileModuleName:       filesystem.OptionsSchema,
+	pubstackModuleName:   pubstack.OptionsSchema,
+	clickHouseModuleName: clickhouse.OptionsSchema,
+	requestLogModuleName: requestlog.OptionsSchema,
+})
+
+func mustLoadOptionsSchemas(schemas map[string]string) map[string]*gojsonschema.Schema {
+	loaded := make(map[string]*gojsonschema.Schema, len(schemas))
+	for name, schema := range schemas {
+		loadedSchema, err := gojsonschema.NewSchema(gojsonschema.NewStringLoader(schema))
+		if err != nil {
+			panic(fmt.Sprintf("Failed to load the options schema of the %s analytics module: %v", name, err))
+		}
+		loaded[name] = loadedSchema
+	}
+	return loaded
+}
+
+// hostModules are the modules of the host operating the server, which receive the events of all the accounts
+// whatever their analytics.modules
+var hostModules = map[string]bool{requestLogModuleName: true}
+
+// moduleEnabled tells if the module receives the events of the account. The events without an account are received
+// by all the modules.
+func moduleEnabled(name string, account *config.Account) bool {
+	return account == nil || hostModules[name] || account.Analytics.ModuleEnabled(name)
+}
+
+// requestAnalyticsOptions returns the request.ext.prebid.analytics options of the modules, by module name
+func requestAnalyticsOptions(req *openrtb2.BidRequest) map[string]json.RawMessage {
+	if req == nil || len(req.Ext) == 0 {
+		return nil
+	}
+	var ext struct {
+		Prebid struct {
+			Analytics map[string]json.RawMessage `json:"analytics"`
+		} `json:"prebid"`
+	}
+	if err := json.Unmarshal(req.Ext, &ext); err != nil {
+		return nil
+	}
+	return ext.Prebid.Analytics
+}
+
+// moduleOptions returns the options of the module for an event: the options of the account, with the request options
+// the host lets the requests override merged over them. The account options are validated when the account is
+// loaded, and the request options failing the schema of the module are left out.
+func moduleOptions(name string, account *config.Account, requestOptions map[string]json.RawMessage, cfg *config.Analytics) json.RawMessage {
+	var options json.RawMessage
+	if account != nil && len(account.Analytics.Modules[name].Options) > 0 {
+		accountOptions, err := json.Marshal(account.Analytics.Modules[name].Options)
+		if err != nil {
+			return nil
+		}
+		options = accountOptions
+	}
+
+	overrides := overridableOptions(name, requestOptions[name], cfg)
+	if overrides == nil {
+		return options
+	}
+	merged := overrides
+	if options != nil {
+		var err error
+		if merged, err = jsonpatch.MergePatch(options, overrides); err != nil {
+			return options
+		}
+	}
+	if validateOptions(name, merged) != nil {
+		return options
+	}
+	return merged
+}
+
+// overridableOptions returns the request options of the module which the host lets the requests override
+func overridableOptions(name string, requestOptions json.RawMessage, cfg *config.Analytics) json.RawMessage {
+	if len(requestOptions) == 0 {
+		return nil
+	}
+	var options map[string]json.RawMessage
+	if err := json.Unmarshal(requestOptions, &options); err != nil {
+		return nil
+	}
+	for option := range options {
+		if !cfg.RequestOverridable(name, option) {
+			delete(options, option)
+		}
+	}
+	if len(options) == 0 {
+		return nil
+	}
+	overrides, err := json.Marshal(options)
+	if err != nil {
+		return nil
+	}
+	return overrides
+}
+
+// ValidateAccountOptions validates the options the account gives to the analytics modules against their schemas
+func ValidateAccountOptions(account *config.Account) []error {
+	names := make([]string, 0, len(account.Analytics.Modules))
+	for name := range account.Analytics.Modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		module := account.Analytics.Modules[name]
+		if len(module.Options) == 0 {
+			continue
+		}
+		options, err := json.Marshal(module.Options)
+		if err == nil {
+			err = validateOptions(name, options)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("analytics.modules.%s.options are invalid: %v", name, err))
+		}
+	}
+	return errs
+}
+
+func validateOptions(name string, options json.RawMessage) error {
+	schema, ok := optionsSchemas[name]
+	if !ok {
+		return nil
+	}
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(options))
+	if err != nil {
+		return err
+	}
+	if !result.Valid() {
+		descriptions := make([]string, 0, len(result.Errors()))
+		for _, resultErr := range result.Errors() {
+			descriptions = append(descriptions, resultErr.String())
+		}
+		return errors.New(strings.Join(descriptions, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+)
+
+func TestLogAuctionObjectModules(t *testing.T) {
+	disabled := false
+	account := &config.Account{ID: "acct", Analytics: config.AccountAnalytics{Modules: map[string]config.AccountAnalyticsModule{
+		pubstackModuleName:   {Options: map[string]interface{}{"scopeid": "account-scope"}},
+		clickHouseModuleName: {Enabled: &disabled},
+		requestLogModuleName: {Enabled: &disabled},
+	}}}
+	pubstackModule := &capturingModule{}
+	clickHouseModule := &capturingModule{}
+	fileModule := &capturingModule{}
+	requestLogModule := &capturingModule{}
+	ea := enabledAnalytics{modules: map[string]analytics.PBSAnalyticsModule{
+		pubstackModuleName:   pubstackModule,
+		clickHouseModuleName: clickHouseModule,
+		fileModuleName:       fileModule,
+		requestLogModuleName: requestLogModule,
+	}}
+
+	ao := &analytics.AuctionObject{Request: &openrtb2.BidRequest{ID: "req"}, Account: account}
+	ea.LogAuctionObject(ao)
+
+	require.NotNil(t, pubstackModule.auction)
+	assert.JSONEq(t, `{"scopeid":"account-scope"}`, string(pubstackModule.auction.Options))
+	assert.Nil(t, clickHouseModule.auction, "The module disabled by the account shouldn't receive the event")
+	assert.NotNil(t, requestLogModule.auction, "The request log of the host cannot be disabled by the account")
+	require.NotNil(t, fileModule.auction)
+	assert.Nil(t, fileModule.auction.Options)
+	assert.Nil(t, ao.Options, "The object of the auction should be left as it is")
+}
+
+func TestLogAmpObjectModulesWithoutAccount(t *testing.T) {
+	module := &capturingModule{}
+
+	enabledAnalytics{modules: map[string]analytics.PBSAnalyticsModule{clickHouseModuleName: module}}.LogAmpObject(&analytics.AmpObject{})
+
+	assert.NotNil(t, module.amp, "The events without an account should be received by all the modules")
+}
+
+func TestModuleOptions(t *testing.T) {
+	customAccount := &config.Account{Analytics: config.AccountAnalytics{Modules: map[string]config.AccountAnalyticsModule{"custom": {Options: map[string]interface{}{"a": 1, "b": 2}}}}}
+	hostConfig := &config.Analytics{RequestOptions: map[string][]string{"custom": {"b"}, fileModuleName: {"path"}, pubstackModuleName: {"scopeid"}}}
+
+	testCases := []struct {
+		description     string
+		givenModule     string
+		givenAccount    *config.Account
+		givenRequestExt json.RawMessage
+		expectedOptions json.RawMessage
+	}{
+		{
+			description:     "No options",
+			givenModule:     pubstackModuleName,
+			givenAccount:    &config.Account{},
+			expectedOptions: nil,
+		},
+		{
+			description:     "Account options",
+			givenModule:     "custom",
+			givenAccount:    customAccount,
+			expectedOptions: json.RawMessage(`{"a":1,"b":2}`),
+		},
+		{
+			description:     "Overridable request options merged over the account ones",
+			givenModule:     "custom",
+			givenAccount:    customAccount,
+			givenRequestExt: json.RawMessage(`{"prebid":{"analytics":{"custom":{"a":5,"b":3},"other":{"c":4}}}}`),
+			expectedOptions: json.RawMessage(`{"a":1,"b":3}`),
+		},
+		{
+			description:     "Overridable request options without account options",
+			givenModule:     "custom",
+			givenRequestExt: json.RawMessage(`{"prebid":{"analytics":{"custom":{"b":3}}}}`),
+			expectedOptions: json.RawMessage(`{"b":3}`),
+		},
+		{
+			description:     "Request scope",
+			givenModule:     pubstackModuleName,
+			givenAccount:    &config.Account{Analytics: config.AccountAnalytics{Modules: map[string]config.AccountAnalyticsModule{pubstackModuleName: {Options: map[string]interface{}{"scopeid": "account-scope"}}}}},
+			givenRequestExt: json.RawMessage(`{"prebid":{"analytics":{"pubstack":{"scopeid":"request-scope"}}}}`),
+			expectedOptions: json.RawMessage(`{"scopeid":"account-scope"}`),
+		},
+		{
+			description:     "Request options failing the schema of the module",
+			givenModule:     fileModuleName,
+			givenRequestExt: json.RawMessage(`{"prebid":{"analytics":{"file":{"path":"/tmp"}}}}`),
+			expectedOptions: nil,
+		},
+		{
+			description:     "Malformed request ext",
+			givenModule:     "custom",
+			givenAccount:    customAccount,
+			givenRequestExt: json.RawMessage(`{"prebid":{"analytics":"custom"}}`),
+			expectedOptions: json.RawMessage(`{"a":1,"b":2}`),
+		},
+	}
+
+	for _, test := range testCases {
+		requestOptions := requestAnalyticsOptions(&openrtb2.BidRequest{Ext: test.givenRequestExt})
+		options := moduleOptions(test.givenModule, test.givenAccount, requestOptions, hostConfig)
+
+		if test.expectedOptions == nil {
+			assert.Nil(t, options, test.description)
+		} else {
+			assert.JSONEq(t, string(test.expectedOptions), string(options), test.description)
+		}
+	}
+}
+
+func TestValidateAccountOptions(t *testing.T) {
+	account := &config.Account{Analytics: config.AccountAnalytics{Modules: map[string]config.AccountAnalyticsModule{
+		pubstackModuleName:   {Options: map[string]interface{}{"scopeid": 1}},
+		clickHouseModuleName: {},
+		"custom":             {Options: map[string]interface{}{"a": 1}},
+	}}}
+
+	errs := ValidateAccountOptions(account)
+
+	if assert.Len(t, errs, 1) {
+		assert.Contains(t, errs[0].Error(), "analytics.modules.pubstack.options are invalid: scopeid")
+	}
+	assert.Empty(t, ValidateAccountOptions(&config.Account{}))
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/privacy"
+)
+
+// The events are scrubbed as the analytics.privacy of their account tells before the modules receive them, so that
+// the modules don't have to handle the privacy of the accounts themselves. The objects of the events are copied
+// rather than modified, as they share the requests with the auctions.
+
+func scrubAuctionObject(ao *analytics.AuctionObject) *analytics.AuctionObject {
+	if !hasAnalyticsPrivacy(ao.Account) {
+		return ao
+	}
+	scrubbed := *ao
+	scrubbed.Request = scrubRequest(ao.Request, ao.Account.Analytics.Privacy)
+	return &scrubbed
+}
+
+func scrubAmpObject(ao *analytics.AmpObject) *analytics.AmpObject {
+	if !hasAnalyticsPrivacy(ao.Account) {
+		return ao
+	}
+	scrubbed := *ao
+	scrubbed.Request = scrubRequest(ao.Request, ao.Account.Analytics.Privacy)
+	return &scrubbed
+}
+
+func scrubVideoObject(vo *analytics.VideoObject) *analytics.VideoObject {
+	if !hasAnalyticsPrivacy(vo.Account) {
+		return vo
+	}
+	scrubbed := *vo
+	scrubbed.Request = scrubRequest(vo.Request, vo.Account.Analytics.Privacy)
+	scrubbed.VideoRequest = scrubVideoRequest(vo.VideoRequest, vo.Account.Analytics.Privacy)
+	return &scrubbed
+}
+
+func hasAnalyticsPrivacy(account *config.Account) bool {
+	return account != nil && account.Analytics.Privacy != config.AnalyticsPrivacy{}
+}
+
+func scrubRequest(req *openrtb2.BidRequest, p config.AnalyticsPrivacy) *openrtb2.BidRequest {
+	if req == nil {
+		return nil
+	}
+	scrubbed := *req
+	privacy.ApplyScrubProfile(&scrubbed, p.ScrubProfile())
+	if p.HashPage {
+		scrubbed.Site = hashSitePage(req.Site)
+	}
+	return &scrubbed
+}
+
+func scrubVideoRequest(req *openrtb_ext.BidRequestVideo, p config.AnalyticsPrivacy) *openrtb_ext.BidRequestVideo {
+	if req == nil {
+		return nil
+	}
+	scrubbed := *req
+	request := openrtb2.BidRequest{Device: &scrubbed.Device, User: req.User}
+	privacy.ApplyScrubProfile(&request, p.ScrubProfile())
+	scrubbed.Device = *request.Device
+	scrubbed.User = request.User
+	if p.HashPage {
+		scrubbed.Site = hashSitePage(req.Site)
+	}
+	return &scrubbed
+}
+
+// hashSitePage returns a copy of the site with the SHA-256 hashes of its page and ref URLs, in hex
+func hashSitePage(site *openrtb2.Site) *openrtb2.Site {
+	if site == nil {
+		return nil
+	}
+	hashed := *site
+	hashed.Page = hashURL(site.Page)
+	hashed.Ref = hashURL(site.Ref)
+	return &hashed
+}
+
+func hashURL(url string) string {
+	if url == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(hash[:])
+}
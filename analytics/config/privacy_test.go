@@ -0,0 +1,113 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+type capturingModule struct {
+	sampleModule
+	auction *analytics.AuctionObject
+	amp     *analytics.AmpObject
+	video   *analytics.VideoObject
+}
+
+func (m *capturingModule) LogAuctionObject(ao *analytics.AuctionObject) { m.auction = ao }
+
+func (m *capturingModule) LogAmpObject(ao *analytics.AmpObject) { m.amp = ao }
+
+func (m *capturingModule) LogVideoObject(vo *analytics.VideoObject) { m.video = vo }
+
+func newPrivacyTestRequest() *openrtb2.BidRequest {
+	return &openrtb2.BidRequest{
+		ID:     "req",
+		Site:   &openrtb2.Site{Page: "https://publisher.com/article", Domain: "publisher.com"},
+		Device: &openrtb2.Device{IP: "1.2.3.4", IFA: "ifa"},
+		User:   &openrtb2.User{ID: "id", BuyerUID: "buyer"},
+	}
+}
+
+func TestScrubAuctionObject(t *testing.T) {
+	testCases := []struct {
+		description     string
+		givenAccount    *config.Account
+		expectedRequest *openrtb2.BidRequest
+	}{
+		{
+			description:     "No account",
+			givenAccount:    nil,
+			expectedRequest: newPrivacyTestRequest(),
+		},
+		{
+			description:     "Nothing to scrub",
+			givenAccount:    &config.Account{ID: "acct"},
+			expectedRequest: newPrivacyTestRequest(),
+		},
+		{
+			description: "User ids, device ids and reduced geo",
+			givenAccount: &config.Account{ID: "acct", Analytics: config.AccountAnalytics{Privacy: config.AnalyticsPrivacy{
+				User:      config.ScrubUserIDs,
+				DeviceIDs: true,
+				Geo:       config.ScrubGeoReduced,
+			}}},
+			expectedRequest: &openrtb2.BidRequest{
+				ID:     "req",
+				Site:   &openrtb2.Site{Page: "https://publisher.com/article", Domain: "publisher.com"},
+				Device: &openrtb2.Device{IP: "1.2.3.0"},
+				User:   &openrtb2.User{},
+			},
+		},
+		{
+			description:  "Hashed page",
+			givenAccount: &config.Account{ID: "acct", Analytics: config.AccountAnalytics{Privacy: config.AnalyticsPrivacy{HashPage: true}}},
+			expectedRequest: &openrtb2.BidRequest{
+				ID:     "req",
+				Site:   &openrtb2.Site{Page: "af0213c9a24264d25f88192219836d63acfe237789fab3e788c2109d2d6b0cc9", Domain: "publisher.com"},
+				Device: &openrtb2.Device{IP: "1.2.3.4", IFA: "ifa"},
+				User:   &openrtb2.User{ID: "id", BuyerUID: "buyer"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		module := &capturingModule{}
+		ao := &analytics.AuctionObject{Request: newPrivacyTestRequest(), Account: test.givenAccount}
+
+		enabledAnalytics{modules: map[string]analytics.PBSAnalyticsModule{"capturing": module}}.LogAuctionObject(ao)
+
+		assert.Equal(t, test.expectedRequest, module.auction.Request, test.description)
+		assert.Equal(t, newPrivacyTestRequest(), ao.Request, test.description+":the request of the auction should be left as it is")
+	}
+}
+
+func TestScrubAmpAndVideoObjects(t *testing.T) {
+	account := &config.Account{ID: "acct", Analytics: config.AccountAnalytics{Privacy: config.AnalyticsPrivacy{User: config.ScrubUserIDs, HashPage: true}}}
+	module := &capturingModule{}
+
+	enabledAnalytics{modules: map[string]analytics.PBSAnalyticsModule{"capturing": module}}.LogAmpObject(&analytics.AmpObject{Request: newPrivacyTestRequest(), Account: account})
+	enabledAnalytics{modules: map[string]analytics.PBSAnalyticsModule{"capturing": module}}.LogVideoObject(&analytics.VideoObject{
+		Request: newPrivacyTestRequest(),
+		VideoRequest: &openrtb_ext.BidRequestVideo{
+			Site:   &openrtb2.Site{Page: "https://publisher.com/article"},
+			User:   &openrtb2.User{BuyerUID: "buyer"},
+			Device: openrtb2.Device{IP: "1.2.3.4"},
+		},
+		Account: account,
+	})
+
+	hashedPage := "af0213c9a24264d25f88192219836d63acfe237789fab3e788c2109d2d6b0cc9"
+	assert.Equal(t, &openrtb2.User{}, module.amp.Request.User)
+	assert.Equal(t, hashedPage, module.amp.Request.Site.Page)
+	assert.Equal(t, &openrtb2.User{}, module.video.Request.User)
+	assert.Equal(t, &openrtb_ext.BidRequestVideo{
+		Site:   &openrtb2.Site{Page: hashedPage},
+		User:   &openrtb2.User{},
+		Device: openrtb2.Device{IP: "1.2.3.4"},
+	}, module.video.VideoRequest)
+}
@@ -1,6 +1,7 @@
 package analytics
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
@@ -33,6 +34,13 @@ type AuctionObject struct {
 	Response  *openrtb2.BidResponse
 	Account   *config.Account
 	StartTime time.Time
+	// TrafficShaping is the assignment of the request to the bidder experiments of the account
+	TrafficShaping openrtb_ext.ExtTrafficShaping
+	// SeatNonBid are the bids dropped by the auction, by seat
+	SeatNonBid []openrtb_ext.SeatNonBid
+	// Options are the options of the account, and the overridable ones of request.ext.prebid.analytics, for the
+	// module receiving the object
+	Options json.RawMessage `json:"-"`
 }
 
 //Loggable object of a transaction at /openrtb2/amp endpoint
@@ -43,7 +51,13 @@ type AmpObject struct {
 	AuctionResponse    *openrtb2.BidResponse
 	AmpTargetingValues map[string]string
 	Origin             string
+	Account            *config.Account
 	StartTime          time.Time
+	// SeatNonBid are the bids dropped by the auction, by seat
+	SeatNonBid []openrtb_ext.SeatNonBid
+	// Options are the options of the account, and the overridable ones of request.ext.prebid.analytics, for the
+	// module receiving the object
+	Options json.RawMessage `json:"-"`
 }
 
 //Loggable object of a transaction at /openrtb2/video endpoint
@@ -54,7 +68,13 @@ type VideoObject struct {
 	Response      *openrtb2.BidResponse
 	VideoRequest  *openrtb_ext.BidRequestVideo
 	VideoResponse *openrtb_ext.BidResponseVideo
+	Account       *config.Account
 	StartTime     time.Time
+	// SeatNonBid are the bids dropped by the auction, by seat
+	SeatNonBid []openrtb_ext.SeatNonBid
+	// Options are the options of the account, and the overridable ones of request.ext.prebid.analytics, for the
+	// module receiving the object
+	Options json.RawMessage `json:"-"`
 }
 
 //Loggable object of a transaction at /setuid
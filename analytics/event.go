@@ -5,8 +5,9 @@ type EventType string
 
 // Possible values of events Prebid Server can receive for an ad.
 const (
-	Win EventType = "win"
-	Imp EventType = "imp"
+	Win   EventType = "win"
+	Imp   EventType = "imp"
+	Click EventType = "click"
 )
 
 // ResponseFormat enumerates the values of a Prebid Server event.
@@ -20,6 +20,9 @@ const (
 	NOTIFICATION_EVENT RequestType = "/event"
 )
 
+// OptionsSchema is the JSON schema of the options the accounts give to the module, which takes none
+const OptionsSchema = `{"type": "object", "additionalProperties": false}`
+
 //Module that can perform transactional logging
 type FileLogger struct {
 	Logger *glog.Logger
@@ -1,6 +1,7 @@
 package pubstack
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/prebid/prebid-server/analytics/pubstack/eventchannel"
 	"net/http"
@@ -32,6 +33,16 @@ const (
 	video      = "video"
 )
 
+// OptionsSchema is the JSON schema of the options the accounts give to the module. The scopeid sends the events of
+// the account under a scope of its own, rather than the scope of the host.
+const OptionsSchema = `{
+  "type": "object",
+  "properties": {
+    "scopeid": { "type": "string", "minLength": 1 }
+  },
+  "additionalProperties": false
+}`
+
 type bufferConfig struct {
 	timeout time.Duration
 	count   int64
@@ -116,7 +127,7 @@ func (p *PubstackModule) LogAuctionObject(ao *analytics.AuctionObject) {
 	}
 
 	// serialize event
-	payload, err := helpers.JsonifyAuctionObject(ao, p.scope)
+	payload, err := helpers.JsonifyAuctionObject(ao, p.scopeOf(ao.Options))
 	if err != nil {
 		glog.Warning("[pubstack] Cannot serialize auction")
 		return
@@ -137,7 +148,7 @@ func (p *PubstackModule) LogVideoObject(vo *analytics.VideoObject) {
 	}
 
 	// serialize event
-	payload, err := helpers.JsonifyVideoObject(vo, p.scope)
+	payload, err := helpers.JsonifyVideoObject(vo, p.scopeOf(vo.Options))
 	if err != nil {
 		glog.Warning("[pubstack] Cannot serialize video")
 		return
@@ -192,7 +203,7 @@ func (p *PubstackModule) LogAmpObject(ao *analytics.AmpObject) {
 	}
 
 	// serialize event
-	payload, err := helpers.JsonifyAmpObject(ao, p.scope)
+	payload, err := helpers.JsonifyAmpObject(ao, p.scopeOf(ao.Options))
 	if err != nil {
 		glog.Warning("[pubstack] Cannot serialize video")
 		return
@@ -270,6 +281,20 @@ func (p *PubstackModule) closeAllEventChannels() {
 	}
 }
 
+// scopeOf returns the scope of the options of the account, or the scope of the host
+func (p *PubstackModule) scopeOf(options json.RawMessage) string {
+	if len(options) == 0 {
+		return p.scope
+	}
+	var accountOptions struct {
+		ScopeID string `json:"scopeid"`
+	}
+	if err := json.Unmarshal(options, &accountOptions); err != nil || accountOptions.ScopeID == "" {
+		return p.scope
+	}
+	return accountOptions.ScopeID
+}
+
 func (p *PubstackModule) isFeatureEnable(feature string) bool {
 	val, ok := p.cfg.Features[feature]
 	return ok && val
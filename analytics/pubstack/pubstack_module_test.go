@@ -206,6 +206,15 @@ func TestPubstackModuleSuccess(t *testing.T) {
 	}
 }
 
+func TestScopeOf(t *testing.T) {
+	module := &PubstackModule{scope: "host-scope"}
+
+	assert.Equal(t, "host-scope", module.scopeOf(nil), "No options")
+	assert.Equal(t, "host-scope", module.scopeOf(json.RawMessage(`{}`)), "No scopeid")
+	assert.Equal(t, "host-scope", module.scopeOf(json.RawMessage(`{"scopeid":1}`)), "Invalid scopeid")
+	assert.Equal(t, "account-scope", module.scopeOf(json.RawMessage(`{"scopeid":"account-scope"}`)))
+}
+
 func assertChanNone(t *testing.T, c <-chan int, msgAndArgs ...interface{}) bool {
 	select {
 	case <-c:
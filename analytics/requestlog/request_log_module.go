@@ -0,0 +1,260 @@
+package requestlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/golang/glog"
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// OptionsSchema is the JSON schema of the options the accounts give to the module, which takes none
+const OptionsSchema = `{"type": "object", "additionalProperties": false}`
+
+// The endpoints of the requests logged
+const (
+	auctionEndpoint = "/openrtb2/auction"
+	ampEndpoint     = "/openrtb2/amp"
+	videoEndpoint   = "/openrtb2/video"
+)
+
+const timeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// bufferSize is the size of the buffer of the lines, which are written once it is full or at the flush interval
+const bufferSize = 64 * 1024
+
+// RequestLogger writes a JSON line per sampled auction, amp and video request, with the fields of the config, so that
+// the log can be shipped by any pipeline reading stdout or files. The lines are buffered, and written every flush
+// interval and at the shutdown.
+type RequestLogger struct {
+	writer        *bufio.Writer
+	samplePercent float64
+	fields        map[string]bool
+	now           func() time.Time
+	random        func() float64
+	sigTermCh     chan os.Signal
+
+	mutex sync.Mutex
+}
+
+func NewRequestLogger(cfg config.RequestLog) (analytics.PBSAnalyticsModule, error) {
+	var writer io.Writer = os.Stdout
+	if cfg.File != "" {
+		file, err := os.OpenFile(cfg.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, err
+		}
+		writer = file
+	}
+
+	l := newRequestLogger(cfg, writer)
+	signal.Notify(l.sigTermCh, os.Interrupt, syscall.SIGTERM)
+	go l.run(time.Duration(cfg.FlushIntervalMs) * time.Millisecond)
+	return l, nil
+}
+
+func newRequestLogger(cfg config.RequestLog, writer io.Writer) *RequestLogger {
+	fieldNames := cfg.Fields
+	if len(fieldNames) == 0 {
+		fieldNames = config.RequestLogFields()
+	}
+	fields := make(map[string]bool, len(fieldNames))
+	for _, field := range fieldNames {
+		fields[field] = true
+	}
+
+	return &RequestLogger{
+		writer:        bufio.NewWriterSize(writer, bufferSize),
+		samplePercent: cfg.SamplePercent,
+		fields:        fields,
+		now:           time.Now,
+		random:        rand.Float64,
+		sigTermCh:     make(chan os.Signal, 1),
+	}
+}
+
+// run writes the buffered lines every flush interval, and a last time at the shutdown
+func (l *RequestLogger) run(flushInterval time.Duration) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.flush()
+		case <-l.sigTermCh:
+			l.flush()
+			return
+		}
+	}
+}
+
+func (l *RequestLogger) flush() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if err := l.writer.Flush(); err != nil {
+		glog.Errorf("[request_log] Failed to write the buffered lines: %v", err)
+	}
+}
+
+// entry is a line of the log. The selected fields are pointers or slices, left out when not selected.
+type entry struct {
+	Time           string                    `json:"time"`
+	Endpoint       string                    `json:"endpoint"`
+	Status         int                       `json:"status"`
+	RequestID      string                    `json:"request_id,omitempty"`
+	Account        *string                   `json:"account,omitempty"`
+	Bidders        []string                  `json:"bidders,omitempty"`
+	TMax           *int64                    `json:"tmax,omitempty"`
+	NBR            *openrtb2.NoBidReasonCode `json:"nbr,omitempty"`
+	ResponseTimeMs *int64                    `json:"response_time_ms,omitempty"`
+	BidCount       *int                      `json:"bid_count,omitempty"`
+	BidCounts      map[string]int            `json:"bid_counts,omitempty"`
+	Errors         []string                  `json:"errors,omitempty"`
+}
+
+func (l *RequestLogger) LogAuctionObject(ao *analytics.AuctionObject) {
+	l.log(auctionEndpoint, ao.Status, ao.Errors, ao.Request, ao.Response, ao.Account, ao.StartTime)
+}
+
+func (l *RequestLogger) LogVideoObject(vo *analytics.VideoObject) {
+	l.log(videoEndpoint, vo.Status, vo.Errors, vo.Request, vo.Response, vo.Account, vo.StartTime)
+}
+
+func (l *RequestLogger) LogAmpObject(ao *analytics.AmpObject) {
+	l.log(ampEndpoint, ao.Status, ao.Errors, ao.Request, ao.AuctionResponse, ao.Account, ao.StartTime)
+}
+
+func (l *RequestLogger) LogCookieSyncObject(cso *analytics.CookieSyncObject) {
+}
+
+func (l *RequestLogger) LogSetUIDObject(so *analytics.SetUIDObject) {
+}
+
+func (l *RequestLogger) LogNotificationEventObject(ne *analytics.NotificationEvent) {
+}
+
+func (l *RequestLogger) log(endpoint string, status int, errs []error, req *openrtb2.BidRequest, resp *openrtb2.BidResponse, account *config.Account, startTime time.Time) {
+	if l.random()*100 >= l.samplePercent {
+		return
+	}
+
+	now := l.now()
+	e := entry{
+		Time:     now.UTC().Format(timeFormat),
+		Endpoint: endpoint,
+		Status:   status,
+	}
+	if req != nil {
+		e.RequestID = req.ID
+	}
+	if l.fields[config.RequestLogFieldAccount] {
+		accountID := accountID(req, account)
+		e.Account = &accountID
+	}
+	if l.fields[config.RequestLogFieldBidders] && req != nil {
+		e.Bidders = bidders(req.Imp)
+	}
+	if l.fields[config.RequestLogFieldTMax] && req != nil {
+		e.TMax = &req.TMax
+	}
+	if l.fields[config.RequestLogFieldNBR] && resp != nil {
+		e.NBR = resp.NBR
+	}
+	if l.fields[config.RequestLogFieldResponseTime] && !startTime.IsZero() {
+		responseTimeMs := now.Sub(startTime).Milliseconds()
+		e.ResponseTimeMs = &responseTimeMs
+	}
+	if l.fields[config.RequestLogFieldBidCounts] {
+		bidCount, bidCounts := countBids(resp)
+		e.BidCount = &bidCount
+		e.BidCounts = bidCounts
+	}
+	if l.fields[config.RequestLogFieldErrors] && len(errs) > 0 {
+		e.Errors = make([]string, 0, len(errs))
+		for _, err := range errs {
+			e.Errors = append(e.Errors, err.Error())
+		}
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		glog.Warningf("[request_log] Cannot serialize the log of the request %s: %v", e.RequestID, err)
+		return
+	}
+	line = append(line, '\n')
+
+	// The errors of the writer are sticky, and logged at the next flush
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.writer.Write(line)
+}
+
+// accountID is the id of the account of the request, or its publisher if the account isn't known
+func accountID(req *openrtb2.BidRequest, account *config.Account) string {
+	if account != nil && account.ID != "" {
+		return account.ID
+	}
+	if req == nil {
+		return ""
+	}
+	if req.Site != nil && req.Site.Publisher != nil {
+		return req.Site.Publisher.ID
+	}
+	if req.App != nil && req.App.Publisher != nil {
+		return req.App.Publisher.ID
+	}
+	return ""
+}
+
+// bidders returns the sorted bidders of the imps, from both imp.ext.BIDDER and imp.ext.prebid.bidder.BIDDER
+func bidders(imps []openrtb2.Imp) []string {
+	seen := make(map[string]struct{})
+	for _, imp := range imps {
+		jsonparser.ObjectEach(imp.Ext, func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
+			if bidder := string(key); !openrtb_ext.IsBidderNameReserved(bidder) {
+				seen[bidder] = struct{}{}
+			}
+			return nil
+		})
+		jsonparser.ObjectEach(imp.Ext, func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
+			seen[string(key)] = struct{}{}
+			return nil
+		}, openrtb_ext.PrebidExtKey, "bidder")
+	}
+
+	names := make([]string, 0, len(seen))
+	for bidder := range seen {
+		names = append(names, bidder)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// countBids returns the number of bids of the response, in total and by seat
+func countBids(resp *openrtb2.BidResponse) (int, map[string]int) {
+	if resp == nil {
+		return 0, nil
+	}
+	total := 0
+	bySeat := make(map[string]int, len(resp.SeatBid))
+	for _, seatBid := range resp.SeatBid {
+		if len(seatBid.Bid) > 0 {
+			bySeat[seatBid.Seat] += len(seatBid.Bid)
+			total += len(seatBid.Bid)
+		}
+	}
+	return total, bySeat
+}
@@ -0,0 +1,152 @@
+package requestlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/prebid/prebid-server/analytics"
+	"github.com/prebid/prebid-server/config"
+)
+
+var testTime = time.Date(2021, 6, 1, 12, 30, 0, 0, time.UTC)
+
+// newTestLogger returns a logger writing to the buffer, which has the lines once the logger is flushed
+func newTestLogger(cfg config.RequestLog, random float64) (*RequestLogger, *bytes.Buffer) {
+	buffer := &bytes.Buffer{}
+	logger := newRequestLogger(cfg, buffer)
+	logger.now = func() time.Time { return testTime }
+	logger.random = func() float64 { return random }
+	return logger, buffer
+}
+
+func newTestAuctionObject() *analytics.AuctionObject {
+	noBidReason := openrtb2.NoBidReasonCode(2)
+	return &analytics.AuctionObject{
+		Status: 200,
+		Errors: []error{errors.New("bidder timed out")},
+		Request: &openrtb2.BidRequest{
+			ID:   "req",
+			TMax: 500,
+			Site: &openrtb2.Site{Publisher: &openrtb2.Publisher{ID: "pub"}},
+			Imp: []openrtb2.Imp{
+				{ID: "imp1", Ext: json.RawMessage(`{"appnexus":{},"prebid":{"bidder":{"rubicon":{}}}}`)},
+				{ID: "imp2", Ext: json.RawMessage(`{"context":{},"appnexus":{}}`)},
+			},
+		},
+		Response: &openrtb2.BidResponse{
+			ID:  "req",
+			NBR: &noBidReason,
+			SeatBid: []openrtb2.SeatBid{
+				{Seat: "appnexus", Bid: []openrtb2.Bid{{ID: "bid1"}, {ID: "bid2"}}},
+				{Seat: "rubicon", Bid: []openrtb2.Bid{{ID: "bid3"}}},
+				{Seat: "empty"},
+			},
+		},
+		Account:   &config.Account{ID: "acct"},
+		StartTime: testTime.Add(-120 * time.Millisecond),
+	}
+}
+
+func TestLogAuctionObject(t *testing.T) {
+	testCases := []struct {
+		description  string
+		givenFields  []string
+		expectedLine string
+	}{
+		{
+			description:  "All the fields",
+			givenFields:  []string{},
+			expectedLine: `{"time":"2021-06-01T12:30:00.000Z","endpoint":"/openrtb2/auction","status":200,"request_id":"req","account":"acct","bidders":["appnexus","rubicon"],"tmax":500,"nbr":2,"response_time_ms":120,"bid_count":3,"bid_counts":{"appnexus":2,"rubicon":1},"errors":["bidder timed out"]}` + "\n",
+		},
+		{
+			description:  "Selected fields",
+			givenFields:  []string{config.RequestLogFieldAccount, config.RequestLogFieldTMax},
+			expectedLine: `{"time":"2021-06-01T12:30:00.000Z","endpoint":"/openrtb2/auction","status":200,"request_id":"req","account":"acct","tmax":500}` + "\n",
+		},
+	}
+
+	for _, test := range testCases {
+		logger, buffer := newTestLogger(config.RequestLog{SamplePercent: 100, Fields: test.givenFields}, 0.5)
+
+		logger.LogAuctionObject(newTestAuctionObject())
+		logger.flush()
+
+		assert.Equal(t, test.expectedLine, buffer.String(), test.description)
+	}
+}
+
+func TestLogAmpAndVideoObjects(t *testing.T) {
+	logger, buffer := newTestLogger(config.RequestLog{SamplePercent: 100, Fields: []string{config.RequestLogFieldAccount}}, 0)
+	request := &openrtb2.BidRequest{ID: "req", App: &openrtb2.App{Publisher: &openrtb2.Publisher{ID: "pub"}}}
+
+	logger.LogAmpObject(&analytics.AmpObject{Status: 200, Request: request})
+	logger.LogVideoObject(&analytics.VideoObject{Status: 400, Request: request, Account: &config.Account{ID: "acct"}})
+	logger.flush()
+
+	assert.Equal(t, `{"time":"2021-06-01T12:30:00.000Z","endpoint":"/openrtb2/amp","status":200,"request_id":"req","account":"pub"}`+"\n"+
+		`{"time":"2021-06-01T12:30:00.000Z","endpoint":"/openrtb2/video","status":400,"request_id":"req","account":"acct"}`+"\n", buffer.String())
+}
+
+func TestLogWithoutRequest(t *testing.T) {
+	logger, buffer := newTestLogger(config.RequestLog{SamplePercent: 100}, 0)
+
+	logger.LogAuctionObject(&analytics.AuctionObject{Status: 400, Errors: []error{errors.New("bad request")}})
+	logger.flush()
+
+	assert.Equal(t, `{"time":"2021-06-01T12:30:00.000Z","endpoint":"/openrtb2/auction","status":400,"account":"","bid_count":0,"errors":["bad request"]}`+"\n", buffer.String())
+}
+
+func TestSampling(t *testing.T) {
+	testCases := []struct {
+		description   string
+		givenPercent  float64
+		givenRandom   float64
+		expectedLines int
+	}{
+		{description: "Sampled in", givenPercent: 10, givenRandom: 0.05, expectedLines: 1},
+		{description: "Sampled out", givenPercent: 10, givenRandom: 0.1, expectedLines: 0},
+		{description: "Nothing sampled", givenPercent: 0, givenRandom: 0, expectedLines: 0},
+		{description: "Everything sampled", givenPercent: 100, givenRandom: 0.999, expectedLines: 1},
+	}
+
+	for _, test := range testCases {
+		logger, buffer := newTestLogger(config.RequestLog{SamplePercent: test.givenPercent}, test.givenRandom)
+
+		logger.LogAuctionObject(newTestAuctionObject())
+		logger.flush()
+
+		assert.Equal(t, test.expectedLines, bytes.Count(buffer.Bytes(), []byte("\n")), test.description)
+	}
+}
+
+func TestBufferedWrites(t *testing.T) {
+	logger, buffer := newTestLogger(config.RequestLog{SamplePercent: 100}, 0)
+
+	logger.LogAuctionObject(newTestAuctionObject())
+	assert.Empty(t, buffer.String(), "the line is buffered until the flush")
+
+	logger.flush()
+	assert.Equal(t, 1, bytes.Count(buffer.Bytes(), []byte("\n")), "the line is written by the flush")
+}
+
+func TestRunFlushesAtShutdown(t *testing.T) {
+	logger, buffer := newTestLogger(config.RequestLog{SamplePercent: 100}, 0)
+	done := make(chan struct{})
+	go func() {
+		logger.run(time.Hour)
+		close(done)
+	}()
+
+	logger.LogAuctionObject(newTestAuctionObject())
+	logger.sigTermCh <- os.Interrupt
+	<-done
+
+	assert.Equal(t, 1, bytes.Count(buffer.Bytes(), []byte("\n")), "the buffered line is written at the shutdown")
+}
@@ -0,0 +1,120 @@
+package biddercapture
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// Call is a captured bidder call, with the raw request sent to the bidder and the raw response received from it
+type Call struct {
+	Timestamp      time.Time           `json:"timestamp"`
+	RequestID      string              `json:"request_id"`
+	Account        string              `json:"account"`
+	Bidder         string              `json:"bidder"`
+	Method         string              `json:"method"`
+	Uri            string              `json:"uri"`
+	RequestHeaders map[string][]string `json:"request_headers,omitempty"`
+	RequestBody    string              `json:"request_body"`
+	Status         int                 `json:"status,omitempty"`
+	ResponseBody   string              `json:"response_body,omitempty"`
+	Error          string              `json:"error,omitempty"`
+}
+
+// Capturer writes the captured bidder calls to a sink in the background. A nil Capturer samples no calls, so that
+// callers don't need to check if the capture is enabled.
+type Capturer struct {
+	sink  Sink
+	calls chan Call
+	done  chan struct{}
+
+	closeMutex sync.RWMutex
+	closed     bool
+}
+
+// NewCapturer returns the capturer of the config, which is nil when the capture is disabled
+func NewCapturer(cfg config.BidderCapture, client *http.Client) (*Capturer, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	sink, err := NewSink(cfg, client)
+	if err != nil {
+		return nil, err
+	}
+	return newCapturer(sink, cfg.BufferSize), nil
+}
+
+func newCapturer(sink Sink, bufferSize int) *Capturer {
+	capturer := &Capturer{
+		sink:  sink,
+		calls: make(chan Call, bufferSize),
+		done:  make(chan struct{}),
+	}
+	go capturer.run()
+	return capturer
+}
+
+// Sampled tells if a call of the bidder is to be captured for the account. The calls of the bidder are sampled at
+// its own percentage if the account sets one, or else at the percentage of the account.
+func (c *Capturer) Sampled(cfg config.AccountBidderCapture, bidder openrtb_ext.BidderName) bool {
+	if c == nil || !cfg.Enabled {
+		return false
+	}
+
+	percent, ok := cfg.Bidders[string(bidder)]
+	if !ok {
+		percent = cfg.SamplePercent
+	}
+	return percent > 0 && rand.Float64()*100 < percent
+}
+
+// Capture queues a call to be written to the sink. The call is dropped if the queue is full.
+func (c *Capturer) Capture(call Call) {
+	c.closeMutex.RLock()
+	defer c.closeMutex.RUnlock()
+
+	if c.closed {
+		return
+	}
+	select {
+	case c.calls <- call:
+	default:
+		glog.Warningf("Bidder capture queue is full, the call of %s for request %s was dropped", call.Bidder, call.RequestID)
+	}
+}
+
+func (c *Capturer) run() {
+	defer close(c.done)
+	for call := range c.calls {
+		if err := c.sink.Write(call); err != nil {
+			glog.Errorf("Failed to write the captured call of %s for request %s: %v", call.Bidder, call.RequestID, err)
+		}
+	}
+}
+
+// Shutdown writes the queued calls to the sink before closing it
+func (c *Capturer) Shutdown() {
+	if c == nil {
+		return
+	}
+
+	c.closeMutex.Lock()
+	if c.closed {
+		c.closeMutex.Unlock()
+		return
+	}
+	c.closed = true
+	close(c.calls)
+	c.closeMutex.Unlock()
+
+	<-c.done
+	if err := c.sink.Close(); err != nil {
+		glog.Errorf("Failed to close the bidder capture sink: %v", err)
+	}
+}
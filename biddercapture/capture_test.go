@@ -0,0 +1,91 @@
+package biddercapture
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSink struct {
+	calls  []Call
+	err    error
+	closed bool
+}
+
+func (s *mockSink) Write(call Call) error {
+	s.calls = append(s.calls, call)
+	return s.err
+}
+
+func (s *mockSink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestNewCapturerDisabled(t *testing.T) {
+	capturer, err := NewCapturer(config.BidderCapture{Enabled: false, Sink: "unknown"}, nil)
+
+	assert.NoError(t, err)
+	assert.Nil(t, capturer)
+	assert.False(t, capturer.Sampled(config.AccountBidderCapture{Enabled: true, SamplePercent: 100}, openrtb_ext.BidderAppnexus))
+	capturer.Shutdown()
+}
+
+func TestNewCapturerInvalidSink(t *testing.T) {
+	_, err := NewCapturer(config.BidderCapture{Enabled: true, Sink: "unknown", BufferSize: 1}, nil)
+
+	assert.EqualError(t, err, `unknown bidder capture sink "unknown"`)
+}
+
+func TestSampled(t *testing.T) {
+	testCases := []struct {
+		description     string
+		givenConfig     config.AccountBidderCapture
+		expectedSampled bool
+	}{
+		{
+			description:     "Account capture disabled",
+			givenConfig:     config.AccountBidderCapture{SamplePercent: 100},
+			expectedSampled: false,
+		},
+		{
+			description:     "Account percentage",
+			givenConfig:     config.AccountBidderCapture{Enabled: true, SamplePercent: 100},
+			expectedSampled: true,
+		},
+		{
+			description:     "Bidder percentage over the account one",
+			givenConfig:     config.AccountBidderCapture{Enabled: true, SamplePercent: 100, Bidders: map[string]float64{"appnexus": 0}},
+			expectedSampled: false,
+		},
+		{
+			description:     "Account percentage for the bidders not listed",
+			givenConfig:     config.AccountBidderCapture{Enabled: true, Bidders: map[string]float64{"rubicon": 100}},
+			expectedSampled: false,
+		},
+	}
+
+	capturer := newCapturer(&mockSink{}, 1)
+	defer capturer.Shutdown()
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedSampled, capturer.Sampled(test.givenConfig, openrtb_ext.BidderAppnexus), test.description)
+	}
+}
+
+func TestCaptureAndShutdown(t *testing.T) {
+	sink := &mockSink{err: errors.New("sink error")}
+	capturer := newCapturer(sink, 10)
+
+	capturer.Capture(Call{RequestID: "req1", Bidder: "appnexus"})
+	capturer.Capture(Call{RequestID: "req2", Bidder: "appnexus"})
+	capturer.Shutdown()
+	capturer.Capture(Call{RequestID: "req3", Bidder: "appnexus"})
+	capturer.Shutdown()
+
+	assert.Equal(t, []Call{{RequestID: "req1", Bidder: "appnexus"}, {RequestID: "req2", Bidder: "appnexus"}}, sink.calls, "the queued calls are written on shutdown, even if the sink fails")
+	assert.True(t, sink.closed)
+}
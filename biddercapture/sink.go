@@ -0,0 +1,95 @@
+package biddercapture
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// Sink is where the captured calls are written. The calls are written one at a time, so a Sink doesn't need to be
+// safe for concurrent use.
+type Sink interface {
+	Write(call Call) error
+	Close() error
+}
+
+// NewSink returns the sink of the config
+func NewSink(cfg config.BidderCapture, client *http.Client) (Sink, error) {
+	switch cfg.Sink {
+	case config.BidderCaptureSinkFile:
+		return newFileSink(cfg.Filename)
+	case config.BidderCaptureSinkHTTP:
+		return &httpSink{
+			client:   client,
+			endpoint: cfg.Endpoint,
+			timeout:  time.Duration(cfg.TimeoutMs) * time.Millisecond,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown bidder capture sink %q", cfg.Sink)
+}
+
+// fileSink appends the calls to a file, one JSON object per line
+type fileSink struct {
+	file    *os.File
+	encoder *json.Encoder
+}
+
+func newFileSink(filename string) (*fileSink, error) {
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the bidder capture file: %v", err)
+	}
+	return &fileSink{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+func (s *fileSink) Write(call Call) error {
+	return s.encoder.Encode(call)
+}
+
+func (s *fileSink) Close() error {
+	return s.file.Close()
+}
+
+// httpSink posts each call as a JSON object to an endpoint
+type httpSink struct {
+	client   *http.Client
+	endpoint string
+	timeout  time.Duration
+}
+
+func (s *httpSink) Write(call Call) error {
+	body, err := json.Marshal(call)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	resp, err := ctxhttp.Do(ctx, s.client, req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("the bidder capture endpoint responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *httpSink) Close() error {
+	return nil
+}
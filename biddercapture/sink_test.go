@@ -0,0 +1,70 @@
+package biddercapture
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "biddercapture")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "capture.log")
+
+	sink, err := NewSink(config.BidderCapture{Sink: config.BidderCaptureSinkFile, Filename: filename}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, sink.Write(Call{RequestID: "req1", Bidder: "appnexus", RequestBody: `{"id":"req1"}`}))
+	assert.NoError(t, sink.Write(Call{RequestID: "req2", Bidder: "rubicon", Status: 204}))
+	assert.NoError(t, sink.Close())
+
+	content, err := ioutil.ReadFile(filename)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if assert.Len(t, lines, 2) {
+		var call Call
+		assert.NoError(t, json.Unmarshal([]byte(lines[1]), &call))
+		assert.Equal(t, "rubicon", call.Bidder)
+		assert.Equal(t, 204, call.Status)
+	}
+}
+
+func TestFileSinkOpenError(t *testing.T) {
+	_, err := NewSink(config.BidderCapture{Sink: config.BidderCaptureSinkFile, Filename: "/does/not/exist/capture.log"}, nil)
+
+	assert.Error(t, err)
+}
+
+func TestHTTPSink(t *testing.T) {
+	var received Call
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(config.BidderCapture{Sink: config.BidderCaptureSinkHTTP, Endpoint: server.URL, TimeoutMs: 1000}, server.Client())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, sink.Write(Call{RequestID: "req1", Bidder: "appnexus"}))
+	assert.Equal(t, "req1", received.RequestID)
+
+	status = http.StatusInternalServerError
+	assert.EqualError(t, sink.Write(Call{RequestID: "req2"}), "the bidder capture endpoint responded with status 500")
+}
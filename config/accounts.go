@@ -1,5 +1,14 @@
 package config
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
 // IntegrationType enumerates the values of integrations Prebid Server can configure for an account
 type IntegrationType string
 
@@ -9,6 +18,8 @@ const (
 	IntegrationTypeApp   IntegrationType = "app"
 	IntegrationTypeVideo IntegrationType = "video"
 	IntegrationTypeWeb   IntegrationType = "web"
+	// IntegrationTypeDOOH is the digital out-of-home channel, of the requests whose ext.prebid.channel.name is dooh
+	IntegrationTypeDOOH IntegrationType = "dooh"
 )
 
 // Account represents a publisher account configuration
@@ -19,7 +30,436 @@ type Account struct {
 	EventsEnabled bool        `mapstructure:"events_enabled" json:"events_enabled"`
 	CCPA          AccountCCPA `mapstructure:"ccpa" json:"ccpa"`
 	GDPR          AccountGDPR `mapstructure:"gdpr" json:"gdpr"`
+	GPP           AccountGPP  `mapstructure:"gpp" json:"gpp"`
 	DebugAllow    bool        `mapstructure:"debug_allow" json:"debug_allow"`
+	DealsOnly     bool        `mapstructure:"deals_only" json:"deals_only"`
+	// EventsExternalURL is the host the event urls of the account point to, over the one of the server
+	EventsExternalURL string `mapstructure:"events_external_url" json:"events_external_url,omitempty"`
+	// PreferDeals makes deal bids win the auction over open market bids, ranked by their deal priority
+	PreferDeals bool `mapstructure:"prefer_deals" json:"prefer_deals"`
+	// DebugToken, if set, restricts debug output to requests sending the same token in the x-pbs-debug-token header
+	DebugToken string `mapstructure:"debug_token" json:"debug_token,omitempty"`
+	// GenerateTIDs fills in the source.tid and imp.ext.tid transaction ids when the request doesn't have them
+	GenerateTIDs bool `mapstructure:"generate_tids" json:"generate_tids"`
+	// PriceFloors controls the enforcement of imp.bidfloor
+	PriceFloors AccountPriceFloors `mapstructure:"price_floors" json:"price_floors"`
+	// Aliases defines bidder aliases for all requests of the account, in the same format as request.ext.prebid.aliases
+	Aliases map[string]string `mapstructure:"aliases" json:"aliases,omitempty"`
+	// AliasOverrides changes how the aliases are called for all requests of the account, like request.ext.prebid.aliasoverrides
+	AliasOverrides map[string]AccountAliasOverride `mapstructure:"alias_overrides" json:"alias_overrides,omitempty"`
+	// Validations controls the checks run on the bids received for the account
+	Validations AccountValidations `mapstructure:"validations" json:"validations"`
+	// TrafficShaping sends only a share of the requests of the account to some bidders, to run bidder experiments
+	TrafficShaping AccountTrafficShaping `mapstructure:"traffic_shaping" json:"traffic_shaping"`
+	// BidderCapture samples the bidder calls of the account captured to the bidder_capture sink of the host
+	BidderCapture AccountBidderCapture `mapstructure:"bidder_capture" json:"bidder_capture"`
+	// Privacy controls which components of Prebid Server may carry out the privacy sensitive activities
+	Privacy AccountPrivacy `mapstructure:"privacy" json:"privacy"`
+	// UserID sends the first party ids minted by the host to the bidders of the account
+	UserID AccountUserID `mapstructure:"user_id" json:"user_id"`
+	// CookieSync overrides the limits and the cooperative syncing of user_sync for the /cookie_sync requests of the account
+	CookieSync AccountCookieSync `mapstructure:"cookie_sync" json:"cookie_sync"`
+	// DefaultRequest is merged under every auction request of the account, like the default request of the host,
+	// which it wins over. The request and its stored request win over it.
+	DefaultRequest map[string]interface{} `mapstructure:"default_request" json:"default_request,omitempty"`
+	// StoredRequestMacros are the values of the custom {{name}} macros in the stored requests and imps of the account
+	StoredRequestMacros map[string]string `mapstructure:"stored_request_macros" json:"stored_request_macros,omitempty"`
+	// Analytics scrubs the personal data from the events of the account before the analytics modules receive them
+	Analytics AccountAnalytics `mapstructure:"analytics" json:"analytics"`
+	// DOOH enables the auction requests of the dooh channel for the account
+	DOOH AccountDOOH `mapstructure:"dooh" json:"dooh"`
+	// PreferredMediaType is the media type kept in the multi-format imps sent to the bidders which don't support them,
+	// by bidder. The request.ext.prebid.biddercontrols of the request win over it.
+	PreferredMediaType map[string]openrtb_ext.BidType `mapstructure:"preferred_media_type" json:"preferred_media_type,omitempty"`
+	// Targeting customizes the targeting keys of the account, for the ad servers limiting their names
+	Targeting AccountTargeting `mapstructure:"targeting" json:"targeting"`
+	// Cache controls the caching of the bids of the account in prebid cache, past the default TTLs of CacheTTL
+	Cache AccountCache `mapstructure:"cache" json:"cache"`
+}
+
+// validatePreferredMediaType checks that the preferred media types are media types of the imps
+func (a *Account) validatePreferredMediaType(field string, errs []error) []error {
+	bidders := make([]string, 0, len(a.PreferredMediaType))
+	for bidder := range a.PreferredMediaType {
+		bidders = append(bidders, bidder)
+	}
+	sort.Strings(bidders)
+
+	for _, bidder := range bidders {
+		switch mediaType := a.PreferredMediaType[bidder]; mediaType {
+		case openrtb_ext.BidTypeBanner, openrtb_ext.BidTypeVideo, openrtb_ext.BidTypeAudio, openrtb_ext.BidTypeNative:
+		default:
+			errs = append(errs, fmt.Errorf("%spreferred_media_type.%s must be one of banner, video, audio or native. Got %s", field, bidder, mediaType))
+		}
+	}
+	return errs
+}
+
+// AccountDOOH represents the account-specific handling of the digital out-of-home requests
+type AccountDOOH struct {
+	Enabled bool `mapstructure:"enabled" json:"enabled"`
+}
+
+// MinTargetingKeyLength is the shortest max_key_length of the targeting keys, which keeps a few characters of the
+// truncated keys ahead of their hash
+const MinTargetingKeyLength = 10
+
+// AccountTargeting represents the account-specific targeting keys. The keys are named as usual when left empty.
+type AccountTargeting struct {
+	// Prefix replaces the hb prefix of the keys
+	Prefix string `mapstructure:"prefix" json:"prefix,omitempty"`
+	// MaxKeyLength truncates the longer keys, ending them with a hash of the full key so that they stay unique. The
+	// bidder keys are truncated to 20 characters, without the hash, when 0.
+	MaxKeyLength int `mapstructure:"max_key_length" json:"max_key_length,omitempty"`
+	// IncludeFormat, IncludeDeals and IncludeCache override the hb_format key of request.ext.prebid.targeting.includeformat,
+	// and remove the hb_deal keys and the hb_cache_id, hb_uuid, hb_cache_host and hb_cache_path keys when false
+	IncludeFormat *bool `mapstructure:"include_format" json:"include_format,omitempty"`
+	IncludeDeals  *bool `mapstructure:"include_deals" json:"include_deals,omitempty"`
+	IncludeCache  *bool `mapstructure:"include_cache" json:"include_cache,omitempty"`
+}
+
+func (t *AccountTargeting) validate(field string, errs []error) []error {
+	for _, c := range t.Prefix {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			errs = append(errs, fmt.Errorf("%stargeting.prefix must only have letters, digits and underscores. Got %s", field, t.Prefix))
+			break
+		}
+	}
+	if t.MaxKeyLength != 0 && t.MaxKeyLength < MinTargetingKeyLength {
+		errs = append(errs, fmt.Errorf("%stargeting.max_key_length must be 0 or at least %d. Got %d", field, MinTargetingKeyLength, t.MaxKeyLength))
+	}
+	return errs
+}
+
+// WinnerKey returns the key of the winning bids
+func (t *AccountTargeting) WinnerKey(key openrtb_ext.TargetingKey) string {
+	return openrtb_ext.TruncateKey(string(key.WithPrefix(t.Prefix)), t.MaxKeyLength)
+}
+
+// BidderKey returns the key of the bids of the bidder, truncated to defaultMaxLength when the account doesn't set one
+func (t *AccountTargeting) BidderKey(key openrtb_ext.TargetingKey, bidder openrtb_ext.BidderName, defaultMaxLength int) string {
+	key = key.WithPrefix(t.Prefix)
+	if t.MaxKeyLength == 0 {
+		return key.BidderKey(bidder, defaultMaxLength)
+	}
+	return openrtb_ext.TruncateKey(key.BidderKey(bidder, 0), t.MaxKeyLength)
+}
+
+// AccountCache represents the account-specific caching of the bids
+type AccountCache struct {
+	// MaxTTL caps the TTLs of the cached bids by media type, including the ones of the imps and bids. The TTLs of a
+	// media type aren't capped when 0.
+	MaxTTL DefaultTTLs `mapstructure:"max_ttl" json:"max_ttl"`
+	// VASTXMLOnly caches only the VAST XML of the video bids, leaving out their JSON when the request caches both
+	VASTXMLOnly bool `mapstructure:"vastxml_only" json:"vastxml_only"`
+	// Host and Path override the hb_cache_host and hb_cache_path targeting keys, for the accounts reaching the cache
+	// through their own domain
+	Host string `mapstructure:"host" json:"host,omitempty"`
+	Path string `mapstructure:"path" json:"path,omitempty"`
+}
+
+func (c *AccountCache) validate(field string, errs []error) []error {
+	for _, ttl := range []struct {
+		mediaType string
+		ttl       int
+	}{
+		{"banner", c.MaxTTL.Banner},
+		{"video", c.MaxTTL.Video},
+		{"native", c.MaxTTL.Native},
+		{"audio", c.MaxTTL.Audio},
+	} {
+		if ttl.ttl < 0 {
+			errs = append(errs, fmt.Errorf("%scache.max_ttl.%s must be >= 0. Got %d", field, ttl.mediaType, ttl.ttl))
+		}
+	}
+	return errs
+}
+
+// AccountAnalytics represents the account-specific handling of the events received by the analytics modules
+type AccountAnalytics struct {
+	Privacy AnalyticsPrivacy `mapstructure:"privacy" json:"privacy"`
+	// Modules enables or disables the analytics modules of the host for the account and gives them their options, by
+	// module name. The modules left out stay enabled, without options. The request_log of the host cannot be disabled.
+	Modules map[string]AccountAnalyticsModule `mapstructure:"modules" json:"modules,omitempty"`
+}
+
+// AccountAnalyticsModule represents the account-specific config of an analytics module. The options are validated
+// against the schema of the module when the account is loaded, and the request.ext.prebid.analytics options which
+// the host lists in analytics.request_options are merged over them.
+type AccountAnalyticsModule struct {
+	Enabled *bool                  `mapstructure:"enabled" json:"enabled,omitempty"`
+	Options map[string]interface{} `mapstructure:"options" json:"options,omitempty"`
+}
+
+// ModuleEnabled tells if the analytics module of the name receives the events of the account
+func (a *AccountAnalytics) ModuleEnabled(name string) bool {
+	module, ok := a.Modules[name]
+	return !ok || module.Enabled == nil || *module.Enabled
+}
+
+// AnalyticsPrivacy tells what is scrubbed from the requests of the events before all the analytics modules receive
+// them. User, DeviceIDs and Geo scrub the user and device like in the scrub profiles, and HashPage replaces the
+// site.page and site.ref URLs with their SHA-256 hashes. The empty fields scrub nothing.
+type AnalyticsPrivacy struct {
+	User      string `mapstructure:"user" json:"user,omitempty"`
+	DeviceIDs bool   `mapstructure:"device_ids" json:"device_ids,omitempty"`
+	Geo       string `mapstructure:"geo" json:"geo,omitempty"`
+	HashPage  bool   `mapstructure:"hash_page" json:"hash_page,omitempty"`
+}
+
+// ScrubProfile returns the scrubbing of the user and device as a scrub profile
+func (p *AnalyticsPrivacy) ScrubProfile() ScrubProfile {
+	return ScrubProfile{User: p.User, DeviceIDs: p.DeviceIDs, Geo: p.Geo}
+}
+
+func (a *AccountAnalytics) validate(field string, errs []error) []error {
+	switch a.Privacy.User {
+	case "", ScrubUserNone, ScrubUserEIDs, ScrubUserIDs, ScrubUserIDsAndDemographics:
+	default:
+		errs = append(errs, fmt.Errorf("%sanalytics.privacy.user must be one of %s, %s, %s or %s. Got %s", field, ScrubUserNone, ScrubUserEIDs, ScrubUserIDs, ScrubUserIDsAndDemographics, a.Privacy.User))
+	}
+	switch a.Privacy.Geo {
+	case "", ScrubGeoNone, ScrubGeoReduced, ScrubGeoFull:
+	default:
+		errs = append(errs, fmt.Errorf("%sanalytics.privacy.geo must be one of %s, %s or %s. Got %s", field, ScrubGeoNone, ScrubGeoReduced, ScrubGeoFull, a.Privacy.Geo))
+	}
+	return errs
+}
+
+// AccountCookieSync represents the account-specific cookie syncs. The fields left unset keep the host's value.
+type AccountCookieSync struct {
+	DefaultLimit    *int  `mapstructure:"default_limit" json:"default_limit,omitempty"`
+	MaxLimit        *int  `mapstructure:"max_limit" json:"max_limit,omitempty"`
+	DefaultCoopSync *bool `mapstructure:"default_coop_sync" json:"default_coop_sync,omitempty"`
+	// PriorityGroups are the bidders synced first in the cooperative syncs, ahead of the priority groups of the host
+	PriorityGroups [][]string `mapstructure:"priority_groups" json:"priority_groups,omitempty"`
+}
+
+func (c *AccountCookieSync) validate(errs []error) []error {
+	if c.DefaultLimit != nil && *c.DefaultLimit < 0 {
+		errs = append(errs, fmt.Errorf("account_defaults.cookie_sync.default_limit must be >= 0. Got %d", *c.DefaultLimit))
+	}
+	if c.MaxLimit != nil && *c.MaxLimit < 0 {
+		errs = append(errs, fmt.Errorf("account_defaults.cookie_sync.max_limit must be >= 0. Got %d", *c.MaxLimit))
+	}
+	return errs
+}
+
+// AccountUserID represents the account-specific first party ids. The ids are sent to the Bidders only, or to all the
+// bidders when empty.
+type AccountUserID struct {
+	Enabled bool     `mapstructure:"enabled" json:"enabled"`
+	Bidders []string `mapstructure:"bidders" json:"bidders,omitempty"`
+}
+
+// AccountAliasOverride represents the account-specific overrides of an alias
+type AccountAliasOverride struct {
+	Endpoint    string                 `mapstructure:"endpoint" json:"endpoint,omitempty"`
+	GVLVendorID uint16                 `mapstructure:"gvl_vendor_id" json:"gvl_vendor_id,omitempty"`
+	Params      map[string]interface{} `mapstructure:"params" json:"params,omitempty"`
+}
+
+// AccountTrafficShaping represents the account-specific bidder experiments
+type AccountTrafficShaping struct {
+	Enabled bool `mapstructure:"enabled" json:"enabled"`
+	// Experiment names the experiment. Changing it splits the traffic of the bidders anew.
+	Experiment string `mapstructure:"experiment" json:"experiment"`
+	// Bidders maps the shaped bidders to the percentage of the requests sent to them
+	Bidders map[string]float64 `mapstructure:"bidders" json:"bidders,omitempty"`
+}
+
+func (t *AccountTrafficShaping) validate(errs []error) []error {
+	bidders := make([]string, 0, len(t.Bidders))
+	for bidder := range t.Bidders {
+		bidders = append(bidders, bidder)
+	}
+	sort.Strings(bidders)
+
+	for _, bidder := range bidders {
+		if percent := t.Bidders[bidder]; percent < 0 || percent > 100 {
+			errs = append(errs, fmt.Errorf("account_defaults.traffic_shaping.bidders.%s must be a percentage between 0 and 100. Got %g", bidder, percent))
+		}
+	}
+	return errs
+}
+
+// AccountBidderCapture represents the account-specific sampling of the captured bidder calls. The calls of a bidder
+// are captured at its percentage in Bidders, falling back on SamplePercent for the bidders not listed.
+type AccountBidderCapture struct {
+	Enabled       bool               `mapstructure:"enabled" json:"enabled"`
+	SamplePercent float64            `mapstructure:"sample_percent" json:"sample_percent"`
+	Bidders       map[string]float64 `mapstructure:"bidders" json:"bidders,omitempty"`
+}
+
+func (c *AccountBidderCapture) validate(errs []error) []error {
+	if c.SamplePercent < 0 || c.SamplePercent > 100 {
+		errs = append(errs, fmt.Errorf("account_defaults.bidder_capture.sample_percent must be a percentage between 0 and 100. Got %g", c.SamplePercent))
+	}
+
+	bidders := make([]string, 0, len(c.Bidders))
+	for bidder := range c.Bidders {
+		bidders = append(bidders, bidder)
+	}
+	sort.Strings(bidders)
+
+	for _, bidder := range bidders {
+		if percent := c.Bidders[bidder]; percent < 0 || percent > 100 {
+			errs = append(errs, fmt.Errorf("account_defaults.bidder_capture.bidders.%s must be a percentage between 0 and 100. Got %g", bidder, percent))
+		}
+	}
+	return errs
+}
+
+// AccountPrivacy represents the account-specific privacy controls
+type AccountPrivacy struct {
+	AllowActivities AllowActivities `mapstructure:"allowactivities" json:"allowactivities"`
+}
+
+// AllowActivities holds the rules of each activity the account controls
+type AllowActivities struct {
+	// SyncUser controls which bidders may sync their users through /cookie_sync
+	SyncUser Activity `mapstructure:"syncUser" json:"syncUser"`
+	// FetchBids controls which bidders are called by the auctions
+	FetchBids Activity `mapstructure:"fetchBids" json:"fetchBids"`
+	// EnrichUserFPD controls which bidders are sent the user first party data of their ext.prebid.bidderconfig
+	EnrichUserFPD Activity `mapstructure:"enrichUfpd" json:"enrichUfpd"`
+	// TransmitUserFPD controls which bidders are sent the ids and demographics of the user and device
+	TransmitUserFPD Activity `mapstructure:"transmitUfpd" json:"transmitUfpd"`
+	// TransmitPreciseGeo controls which bidders are sent the unrounded geo and the full IP addresses
+	TransmitPreciseGeo Activity `mapstructure:"transmitPreciseGeo" json:"transmitPreciseGeo"`
+	// TransmitEids controls which bidders are sent user.ext.eids
+	TransmitEids Activity `mapstructure:"transmitEids" json:"transmitEids"`
+}
+
+// Activity is allowed by the first of its rules whose condition the component and the request match, or else by
+// Default. A nil Default allows the activity.
+type Activity struct {
+	Default *bool          `mapstructure:"default" json:"default,omitempty"`
+	Rules   []ActivityRule `mapstructure:"rules" json:"rules,omitempty"`
+}
+
+// ActivityRule allows or denies an activity to the components and to the requests matching its condition
+type ActivityRule struct {
+	Condition ActivityCondition `mapstructure:"condition" json:"condition"`
+	Allow     bool              `mapstructure:"allow" json:"allow"`
+}
+
+// ActivityCondition is matched by the components and the requests matching all of its fields. An empty field matches
+// everything.
+type ActivityCondition struct {
+	// ComponentType lists the types of the components, which are bidder, analytics, rtd or general
+	ComponentType []string `mapstructure:"componentType" json:"componentType,omitempty"`
+	// ComponentName lists the names of the components, such as the bidder names
+	ComponentName []string `mapstructure:"componentName" json:"componentName,omitempty"`
+	// Geo lists the countries of device.geo, as ISO-3166-1-alpha-3 codes optionally followed by a dot and the region
+	Geo []string `mapstructure:"geo" json:"geo,omitempty"`
+	// GPC is the Global Privacy Control signal of the request, 1 or 0
+	GPC string `mapstructure:"gpc" json:"gpc,omitempty"`
+}
+
+// Possible values of the component types of the activity conditions
+const (
+	ComponentTypeBidder    = "bidder"
+	ComponentTypeAnalytics = "analytics"
+	ComponentTypeRTD       = "rtd"
+	ComponentTypeGeneral   = "general"
+)
+
+func (p *AccountPrivacy) validate(field string, errs []error) []error {
+	activities := []struct {
+		name     string
+		activity Activity
+	}{
+		{"syncUser", p.AllowActivities.SyncUser},
+		{"fetchBids", p.AllowActivities.FetchBids},
+		{"enrichUfpd", p.AllowActivities.EnrichUserFPD},
+		{"transmitUfpd", p.AllowActivities.TransmitUserFPD},
+		{"transmitPreciseGeo", p.AllowActivities.TransmitPreciseGeo},
+		{"transmitEids", p.AllowActivities.TransmitEids},
+	}
+	for _, a := range activities {
+		for i, rule := range a.activity.Rules {
+			for _, componentType := range rule.Condition.ComponentType {
+				switch componentType {
+				case ComponentTypeBidder, ComponentTypeAnalytics, ComponentTypeRTD, ComponentTypeGeneral:
+				default:
+					errs = append(errs, fmt.Errorf("%sprivacy.allowactivities.%s.rules[%d].condition.componentType must be one of %s, %s, %s or %s. Got %s", field, a.name, i, ComponentTypeBidder, ComponentTypeAnalytics, ComponentTypeRTD, ComponentTypeGeneral, componentType))
+				}
+			}
+			if gpc := rule.Condition.GPC; gpc != "" && gpc != "0" && gpc != "1" {
+				errs = append(errs, fmt.Errorf("%sprivacy.allowactivities.%s.rules[%d].condition.gpc must be either 0 or 1. Got %s", field, a.name, i, gpc))
+			}
+		}
+	}
+	return errs
+}
+
+// ValidationMode controls what happens to the bids failing a validation
+type ValidationMode string
+
+// Possible values of bid validation modes
+const (
+	// ValidationSkip doesn't run the validation. It is also the behavior of an empty mode.
+	ValidationSkip ValidationMode = "skip"
+	// ValidationWarn keeps the failing bids, with a warning
+	ValidationWarn ValidationMode = "warn"
+	// ValidationEnforce drops the failing bids
+	ValidationEnforce ValidationMode = "enforce"
+)
+
+// AccountValidations represents the account-specific bid validations
+type AccountValidations struct {
+	// BannerCreativeSize checks that banner bids are sized like one of the formats of their imp
+	BannerCreativeSize ValidationMode `mapstructure:"banner_creative_size" json:"banner_creative_size"`
+	// SecureMarkup checks that the bids of secure imps don't load insecure resources
+	SecureMarkup ValidationMode `mapstructure:"secure_markup" json:"secure_markup"`
+	// AdmPresence checks that bids have either an adm or a nurl to fetch it from
+	AdmPresence ValidationMode `mapstructure:"adm_presence" json:"adm_presence"`
+	// NativeAssets checks that native bids are native markup whose assets are assets of their imp, with all the
+	// required ones
+	NativeAssets ValidationMode `mapstructure:"native_assets" json:"native_assets"`
+}
+
+func (v *AccountValidations) validate(errs []error) []error {
+	modes := []struct {
+		field string
+		mode  ValidationMode
+	}{
+		{"banner_creative_size", v.BannerCreativeSize},
+		{"secure_markup", v.SecureMarkup},
+		{"adm_presence", v.AdmPresence},
+		{"native_assets", v.NativeAssets},
+	}
+	for _, m := range modes {
+		if m.mode != "" && m.mode != ValidationSkip && m.mode != ValidationWarn && m.mode != ValidationEnforce {
+			errs = append(errs, fmt.Errorf("account_defaults.validations.%s must be one of %s, %s or %s. Got %s", m.field, ValidationSkip, ValidationWarn, ValidationEnforce, m.mode))
+		}
+	}
+	return errs
+}
+
+// AccountPriceFloors represents account-specific price floor enforcement
+type AccountPriceFloors struct {
+	// EnforceFloors rejects bids priced below imp.bidfloor, once converted from imp.bidfloorcur to the bid currency
+	EnforceFloors bool `mapstructure:"enforce_floors" json:"enforce_floors"`
+	// AdjustmentFactors multiply the floors enforced for the given bidders
+	AdjustmentFactors map[string]float64 `mapstructure:"adjustment_factors" json:"adjustment_factors,omitempty"`
+	// Fetch loads the floors of the account from a publisher hosted JSON file
+	Fetch AccountFloorsFetch `mapstructure:"fetch" json:"fetch"`
+}
+
+// AccountFloorsFetch represents the location and refresh policy of the dynamic floors of an account
+type AccountFloorsFetch struct {
+	Enabled bool   `mapstructure:"enabled" json:"enabled"`
+	URL     string `mapstructure:"url" json:"url"`
+	// Timeout bounds each fetch of the floors file
+	Timeout int `mapstructure:"timeout_ms" json:"timeout_ms"`
+	// MaxFileSizeKB rejects floors files larger than this size
+	MaxFileSizeKB int `mapstructure:"max_file_size_kb" json:"max_file_size_kb"`
+	// Period is the minimum time between two fetches of the floors file
+	Period int `mapstructure:"period_sec" json:"period_sec"`
+	// MaxAge drops the fetched floors if they couldn't be refreshed for this long. 0 keeps them forever.
+	MaxAge int `mapstructure:"max_age_sec" json:"max_age_sec"`
 }
 
 // AccountCCPA represents account-specific CCPA configuration
@@ -42,6 +482,58 @@ type AccountGDPR struct {
 	Enabled                 *bool              `mapstructure:"enabled" json:"enabled,omitempty"`
 	IntegrationEnabled      AccountIntegration `mapstructure:"integration_enabled" json:"integration_enabled"`
 	BasicEnforcementVendors []string           `mapstructure:"basic_enforcement_vendors" json:"basic_enforcement_vendors"`
+	// Purpose1 to Purpose10 and SpecialFeature1 override the enforcement of the host's gdpr.tcf2 config
+	Purpose1        AccountGDPRPurpose        `mapstructure:"purpose1" json:"purpose1"`
+	Purpose2        AccountGDPRPurpose        `mapstructure:"purpose2" json:"purpose2"`
+	Purpose3        AccountGDPRPurpose        `mapstructure:"purpose3" json:"purpose3"`
+	Purpose4        AccountGDPRPurpose        `mapstructure:"purpose4" json:"purpose4"`
+	Purpose5        AccountGDPRPurpose        `mapstructure:"purpose5" json:"purpose5"`
+	Purpose6        AccountGDPRPurpose        `mapstructure:"purpose6" json:"purpose6"`
+	Purpose7        AccountGDPRPurpose        `mapstructure:"purpose7" json:"purpose7"`
+	Purpose8        AccountGDPRPurpose        `mapstructure:"purpose8" json:"purpose8"`
+	Purpose9        AccountGDPRPurpose        `mapstructure:"purpose9" json:"purpose9"`
+	Purpose10       AccountGDPRPurpose        `mapstructure:"purpose10" json:"purpose10"`
+	SpecialFeature1 AccountGDPRSpecialFeature `mapstructure:"special_feature1" json:"special_feature1"`
+}
+
+// AccountGDPRPurpose represents the account-specific enforcement of a TCF2 purpose. The fields left unset keep the
+// host's value.
+type AccountGDPRPurpose struct {
+	EnforcePurpose   *string                  `mapstructure:"enforce_purpose" json:"enforce_purpose,omitempty"`
+	EnforceVendors   *bool                    `mapstructure:"enforce_vendors" json:"enforce_vendors,omitempty"`
+	VendorExceptions []openrtb_ext.BidderName `mapstructure:"vendor_exceptions" json:"vendor_exceptions,omitempty"`
+}
+
+// AccountGDPRSpecialFeature represents the account-specific enforcement of the TCF2 special feature 1, the use of
+// precise geolocation data
+type AccountGDPRSpecialFeature struct {
+	Enforce          *bool                    `mapstructure:"enforce" json:"enforce,omitempty"`
+	VendorExceptions []openrtb_ext.BidderName `mapstructure:"vendor_exceptions" json:"vendor_exceptions,omitempty"`
+}
+
+// PurposeConfigs returns the account-specific configs of the purposes 1 to 10, in order
+func (a *AccountGDPR) PurposeConfigs() []AccountGDPRPurpose {
+	return []AccountGDPRPurpose{
+		a.Purpose1,
+		a.Purpose2,
+		a.Purpose3,
+		a.Purpose4,
+		a.Purpose5,
+		a.Purpose6,
+		a.Purpose7,
+		a.Purpose8,
+		a.Purpose9,
+		a.Purpose10,
+	}
+}
+
+func (a *AccountGDPR) validate(field string, errs []error) []error {
+	for i, purpose := range a.PurposeConfigs() {
+		if purpose.EnforcePurpose != nil && !ValidTCF2Enforcement(*purpose.EnforcePurpose) {
+			errs = append(errs, fmt.Errorf("%sgdpr.purpose%d.enforce_purpose must be full, basic or no. Got %s", field, i+1, *purpose.EnforcePurpose))
+		}
+	}
+	return errs
 }
 
 // EnabledForIntegrationType indicates whether GDPR is turned on at the account level for the specified integration type
@@ -54,12 +546,54 @@ func (a *AccountGDPR) EnabledForIntegrationType(integrationType IntegrationType)
 	return a.Enabled
 }
 
-// AccountIntegration indicates whether a particular privacy policy (GDPR, CCPA) is enabled for each integration type
+// AccountGPP represents account-specific GPP configuration. A disabled GPP ignores regs.ext.gpp, so that GDPR and CCPA
+// are read from their own fields of the request only.
+type AccountGPP struct {
+	Enabled            *bool              `mapstructure:"enabled" json:"enabled,omitempty"`
+	IntegrationEnabled AccountIntegration `mapstructure:"integration_enabled" json:"integration_enabled"`
+}
+
+// EnabledForIntegrationType indicates whether GPP is turned on at the account level for the specified integration type
+// by using the integration type setting if defined or the general GPP setting if defined; otherwise it returns nil
+func (a *AccountGPP) EnabledForIntegrationType(integrationType IntegrationType) *bool {
+	if integrationEnabled := a.IntegrationEnabled.GetByIntegrationType(integrationType); integrationEnabled != nil {
+		return integrationEnabled
+	}
+	return a.Enabled
+}
+
+// Validate checks the privacy, targeting and cache settings of an account, which override the ones of the host. It is
+// called when the account is loaded, as the stored accounts aren't part of the host config.
+func (a *Account) Validate() []error {
+	return a.validate("", nil)
+}
+
+// validate checks the privacy, targeting and cache settings, reporting the errors under the field prefix
+func (a *Account) validate(field string, errs []error) []error {
+	errs = a.Privacy.validate(field, errs)
+	errs = a.GDPR.validate(field, errs)
+	errs = a.Analytics.validate(field, errs)
+	errs = validateEventsURL(field+"events_external_url", a.EventsExternalURL, errs)
+	errs = a.Targeting.validate(field, errs)
+	errs = a.Cache.validate(field, errs)
+	return errs
+}
+
+// EventsURL returns the host of the event urls of the account, given the one of the server
+func (a *Account) EventsURL(hostURL string) string {
+	if a.EventsExternalURL != "" {
+		return a.EventsExternalURL
+	}
+	return hostURL
+}
+
+// AccountIntegration indicates whether a particular privacy policy (GDPR, CCPA, GPP) is enabled for each integration type
 type AccountIntegration struct {
 	AMP   *bool `mapstructure:"amp" json:"amp,omitempty"`
 	App   *bool `mapstructure:"app" json:"app,omitempty"`
 	Video *bool `mapstructure:"video" json:"video,omitempty"`
 	Web   *bool `mapstructure:"web" json:"web,omitempty"`
+	DOOH  *bool `mapstructure:"dooh" json:"dooh,omitempty"`
 }
 
 // GetByIntegrationType looks up the account integration enabled setting for the specified integration type
@@ -75,7 +609,47 @@ func (a *AccountIntegration) GetByIntegrationType(integrationType IntegrationTyp
 		integrationEnabled = a.Video
 	case IntegrationTypeWeb:
 		integrationEnabled = a.Web
+	case IntegrationTypeDOOH:
+		integrationEnabled = a.DOOH
 	}
 
 	return integrationEnabled
 }
+
+// AccountReload configures the periodic reload of the stored accounts. The accounts are validated against the JSON
+// schema of the schema file when they are loaded, and keep their last valid version if their new one is not.
+type AccountReload struct {
+	Enabled bool `mapstructure:"enabled"`
+	// RefreshRate is the interval at which the accounts loaded so far are fetched again from the accounts backend
+	RefreshRate int    `mapstructure:"refresh_rate_seconds"`
+	Timeout     int    `mapstructure:"timeout_ms"`
+	SchemaFile  string `mapstructure:"schema_file"`
+}
+
+func (cfg AccountReload) RefreshRateDuration() time.Duration {
+	return time.Duration(cfg.RefreshRate) * time.Second
+}
+
+func (cfg AccountReload) TimeoutDuration() time.Duration {
+	return time.Duration(cfg.Timeout) * time.Millisecond
+}
+
+func (cfg *AccountReload) validate(accountsCacheType string, errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.RefreshRate <= 0 {
+		errs = append(errs, fmt.Errorf("account_reload.refresh_rate_seconds must be positive. Got %d", cfg.RefreshRate))
+	}
+	if cfg.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("account_reload.timeout_ms must be positive. Got %d", cfg.Timeout))
+	}
+	if cfg.SchemaFile == "" {
+		errs = append(errs, errors.New("account_reload.schema_file is required"))
+	}
+	// The reloaded accounts are kept in memory, and reloading them from a cache would not get their new versions
+	if accountsCacheType != "" && accountsCacheType != "none" {
+		errs = append(errs, fmt.Errorf("account_reload requires accounts.in_memory_cache.type to be none. Got %s", accountsCacheType))
+	}
+	return errs
+}
@@ -142,6 +142,53 @@ func TestAccountCCPAEnabledForIntegrationType(t *testing.T) {
 	}
 }
 
+func TestAccountGPPEnabledForIntegrationType(t *testing.T) {
+	trueValue, falseValue := true, false
+
+	tests := []struct {
+		description         string
+		giveIntegrationType IntegrationType
+		giveGPPEnabled      *bool
+		giveAppGPPEnabled   *bool
+		wantEnabled         *bool
+	}{
+		{
+			description:         "GPP App integration disabled, general GPP enabled",
+			giveIntegrationType: IntegrationTypeApp,
+			giveGPPEnabled:      &trueValue,
+			giveAppGPPEnabled:   &falseValue,
+			wantEnabled:         &falseValue,
+		},
+		{
+			description:         "GPP App integration unspecified, general GPP disabled",
+			giveIntegrationType: IntegrationTypeApp,
+			giveGPPEnabled:      &falseValue,
+			wantEnabled:         &falseValue,
+		},
+		{
+			description:         "GPP Web integration unspecified, general GPP unspecified",
+			giveIntegrationType: IntegrationTypeWeb,
+			giveAppGPPEnabled:   &falseValue,
+			wantEnabled:         nil,
+		},
+	}
+
+	for _, tt := range tests {
+		account := Account{
+			GPP: AccountGPP{
+				Enabled: tt.giveGPPEnabled,
+				IntegrationEnabled: AccountIntegration{
+					App: tt.giveAppGPPEnabled,
+				},
+			},
+		}
+
+		enabled := account.GPP.EnabledForIntegrationType(tt.giveIntegrationType)
+
+		assert.Equal(t, tt.wantEnabled, enabled, tt.description)
+	}
+}
+
 func TestAccountIntegrationGetByIntegrationType(t *testing.T) {
 	trueValue, falseValue := true, false
 
@@ -151,6 +198,7 @@ func TestAccountIntegrationGetByIntegrationType(t *testing.T) {
 		giveAppEnabled      *bool
 		giveVideoEnabled    *bool
 		giveWebEnabled      *bool
+		giveDOOHEnabled     *bool
 		giveIntegrationType IntegrationType
 		wantEnabled         *bool
 	}{
@@ -222,6 +270,18 @@ func TestAccountIntegrationGetByIntegrationType(t *testing.T) {
 			giveIntegrationType: IntegrationTypeWeb,
 			wantEnabled:         &trueValue,
 		},
+		{
+			description:         "DOOH integration setting unspecified, returns nil",
+			giveWebEnabled:      &trueValue,
+			giveIntegrationType: IntegrationTypeDOOH,
+			wantEnabled:         nil,
+		},
+		{
+			description:         "DOOH integration disabled, returns false",
+			giveDOOHEnabled:     &falseValue,
+			giveIntegrationType: IntegrationTypeDOOH,
+			wantEnabled:         &falseValue,
+		},
 	}
 
 	for _, tt := range tests {
@@ -230,6 +290,7 @@ func TestAccountIntegrationGetByIntegrationType(t *testing.T) {
 			App:   tt.giveAppEnabled,
 			Video: tt.giveVideoEnabled,
 			Web:   tt.giveWebEnabled,
+			DOOH:  tt.giveDOOHEnabled,
 		}
 
 		result := accountIntegration.GetByIntegrationType(tt.giveIntegrationType)
@@ -6,6 +6,7 @@ import (
 
 	validator "github.com/asaskevich/govalidator"
 	"github.com/prebid/prebid-server/macros"
+	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
 type Adapter struct {
@@ -23,6 +24,17 @@ type Adapter struct {
 	// needed for Facebook
 	PlatformID string `mapstructure:"platform_id"`
 	AppSecret  string `mapstructure:"app_secret"`
+
+	Retry          AdapterRetry          `mapstructure:"retry"`
+	CircuitBreaker AdapterCircuitBreaker `mapstructure:"circuit_breaker"`
+
+	// Seat is the seatbid.seat used for this bidder's bids when the adapter doesn't set one itself.
+	// Defaults to the bidder code.
+	Seat string `mapstructure:"seat"`
+
+	// GenericORTB onboards a bidder without an adapter of its own. The bidder is served by the generic OpenRTB
+	// adapter, and its bidder info is built from this config rather than read from static/bidder-info.
+	GenericORTB *GenericORTB `mapstructure:"generic_ortb"`
 }
 
 type AdapterXAPI struct {
@@ -31,11 +43,139 @@ type AdapterXAPI struct {
 	Tracker  string `mapstructure:"tracker"`
 }
 
+// AdapterRetry configures a single retry of idempotent (GET and HEAD) bidder calls which are answered
+// with one of the listed 5xx status codes. Before retrying, the exchange waits a jittered backoff of between
+// half and the full BackoffMs. The retry is skipped if the backoff would not fit in the remaining auction time.
+type AdapterRetry struct {
+	Enabled     bool  `mapstructure:"enabled"`
+	StatusCodes []int `mapstructure:"status_codes"`
+	BackoffMs   int   `mapstructure:"backoff_ms"`
+	// ConnectionErrors retries bidder calls of any method whose connection was refused or reset before a response
+	// was read, whether or not status code retries are enabled. These calls use the same backoff.
+	ConnectionErrors bool `mapstructure:"connection_errors"`
+}
+
+// AdapterCircuitBreaker stops calling a bidder for CooldownMs once at least FailureRatePercent of its last
+// WindowSize calls failed. After the cooldown, the calls of a single auction are let through to probe whether
+// the bidder has recovered, closing the breaker if they succeed and opening it again if they fail.
+type AdapterCircuitBreaker struct {
+	Enabled            bool `mapstructure:"enabled"`
+	WindowSize         int  `mapstructure:"window_size"`
+	FailureRatePercent int  `mapstructure:"failure_rate_percent"`
+	CooldownMs         int  `mapstructure:"cooldown_ms"`
+}
+
+// GenericORTB configures a bidder served by the generic OpenRTB adapter. The bidder is sent the auction request with
+// its imps grouped by the endpoint resolved for them, and answers with a plain OpenRTB bid response.
+type GenericORTB struct {
+	GVLVendorID uint16 `mapstructure:"gvl_vendor_id"`
+	// MediaTypes are the media types the bidder supports, on both app and site
+	MediaTypes []openrtb_ext.BidType `mapstructure:"media_types"`
+	// EndpointMacros names the imp.ext.bidder params the macros of the endpoint are resolved from
+	EndpointMacros GenericORTBMacros `mapstructure:"endpoint_macros"`
+	// FieldMappings copy imp.ext.bidder params into fields of the bidder request
+	FieldMappings []GenericORTBFieldMapping `mapstructure:"field_mappings"`
+}
+
+// GenericORTBMacros maps each endpoint macro to the imp.ext.bidder param resolving it. Unmapped macros resolve to an
+// empty string.
+type GenericORTBMacros struct {
+	Host        string `mapstructure:"host"`
+	PublisherID string `mapstructure:"publisher_id"`
+	ZoneID      string `mapstructure:"zone_id"`
+	SourceId    string `mapstructure:"source_id"`
+	AccountID   string `mapstructure:"account_id"`
+	AdUnit      string `mapstructure:"ad_unit"`
+}
+
+// GenericORTBFieldMapping copies the Param of imp.ext.bidder into the Field of the bidder request
+type GenericORTBFieldMapping struct {
+	Param string `mapstructure:"param"`
+	Field string `mapstructure:"field"`
+}
+
+// The bidder request fields imp.ext.bidder params can be copied into
+const (
+	GenericORTBFieldImpTagID    = "imp.tagid"
+	GenericORTBFieldSiteID      = "site.id"
+	GenericORTBFieldAppID       = "app.id"
+	GenericORTBFieldPublisherID = "publisher.id"
+)
+
 // validateAdapters validates adapter's endpoint and user sync URL
 func validateAdapters(adapterMap map[string]Adapter, errs []error) []error {
 	for adapterName, adapter := range adapterMap {
 		if !adapter.Disabled {
 			errs = validateAdapterEndpoint(adapter.Endpoint, adapterName, errs)
+			errs = validateAdapterRetry(adapter.Retry, adapterName, errs)
+			errs = validateAdapterCircuitBreaker(adapter.CircuitBreaker, adapterName, errs)
+			errs = validateGenericORTB(adapter.GenericORTB, adapterName, errs)
+		}
+	}
+	return errs
+}
+
+// validateAdapterRetry makes sure that retries are only configured for server errors and with a sane backoff
+func validateAdapterRetry(retry AdapterRetry, adapterName string, errs []error) []error {
+	if !retry.Enabled && !retry.ConnectionErrors {
+		return errs
+	}
+	if retry.Enabled && len(retry.StatusCodes) == 0 {
+		errs = append(errs, fmt.Errorf("adapters.%s.retry.status_codes must not be empty when retries are enabled", adapterName))
+	}
+	for _, statusCode := range retry.StatusCodes {
+		if statusCode < 500 || statusCode > 599 {
+			errs = append(errs, fmt.Errorf("adapters.%s.retry.status_codes contains %d, only 5xx status codes can be retried", adapterName, statusCode))
+		}
+	}
+	if retry.BackoffMs < 0 {
+		errs = append(errs, fmt.Errorf("adapters.%s.retry.backoff_ms must not be negative. Got %d", adapterName, retry.BackoffMs))
+	}
+	return errs
+}
+
+// validateAdapterCircuitBreaker makes sure that the circuit breaker thresholds are usable
+func validateAdapterCircuitBreaker(breaker AdapterCircuitBreaker, adapterName string, errs []error) []error {
+	if !breaker.Enabled {
+		return errs
+	}
+	if breaker.WindowSize <= 0 {
+		errs = append(errs, fmt.Errorf("adapters.%s.circuit_breaker.window_size must be positive. Got %d", adapterName, breaker.WindowSize))
+	}
+	if breaker.FailureRatePercent <= 0 || breaker.FailureRatePercent > 100 {
+		errs = append(errs, fmt.Errorf("adapters.%s.circuit_breaker.failure_rate_percent must be between 1 and 100. Got %d", adapterName, breaker.FailureRatePercent))
+	}
+	if breaker.CooldownMs <= 0 {
+		errs = append(errs, fmt.Errorf("adapters.%s.circuit_breaker.cooldown_ms must be positive. Got %d", adapterName, breaker.CooldownMs))
+	}
+	return errs
+}
+
+// validateGenericORTB makes sure that a bidder onboarded through config doesn't take the name of another bidder and
+// that its media types and field mappings are supported
+func validateGenericORTB(genericORTB *GenericORTB, adapterName string, errs []error) []error {
+	if genericORTB == nil {
+		return errs
+	}
+	if _, isCoreBidder := openrtb_ext.NormalizeBidderName(adapterName); isCoreBidder || openrtb_ext.IsBidderNameReserved(adapterName) {
+		errs = append(errs, fmt.Errorf("adapters.%s.generic_ortb can't be set, %s is the name of a core or reserved bidder", adapterName, adapterName))
+	}
+	if len(genericORTB.MediaTypes) == 0 {
+		errs = append(errs, fmt.Errorf("adapters.%s.generic_ortb.media_types must not be empty", adapterName))
+	}
+	for _, mediaType := range genericORTB.MediaTypes {
+		if _, err := openrtb_ext.ParseBidType(string(mediaType)); err != nil {
+			errs = append(errs, fmt.Errorf("adapters.%s.generic_ortb.media_types contains %s, which isn't a media type", adapterName, mediaType))
+		}
+	}
+	for _, mapping := range genericORTB.FieldMappings {
+		if mapping.Param == "" {
+			errs = append(errs, fmt.Errorf("adapters.%s.generic_ortb.field_mappings must set the param copied into %s", adapterName, mapping.Field))
+		}
+		switch mapping.Field {
+		case GenericORTBFieldImpTagID, GenericORTBFieldSiteID, GenericORTBFieldAppID, GenericORTBFieldPublisherID:
+		default:
+			errs = append(errs, fmt.Errorf("adapters.%s.generic_ortb.field_mappings can't copy params into %s", adapterName, mapping.Field))
 		}
 	}
 	return errs
@@ -21,6 +21,16 @@ type BidderInfo struct {
 	Debug                   *DebugInfo        `yaml:"debug"`
 	GVLVendorID             uint16            `yaml:"gvlVendorID"`
 	Syncer                  *Syncer           `yaml:"userSync"`
+	Compression             *CompressionInfo  `yaml:"compression"`
+	HTTPClient              *HTTPClientInfo   `yaml:"httpClient"`
+	// MultiFormatSupported set to false sends the bidder only the preferred media type of the multi-format imps
+	MultiFormatSupported *bool `yaml:"multiFormatSupported"`
+}
+
+// SupportsMultiFormat tells if the bidder accepts the imps with several media types, which it does unless its info says
+// otherwise
+func (info BidderInfo) SupportsMultiFormat() bool {
+	return info.MultiFormatSupported == nil || *info.MultiFormatSupported
 }
 
 // MaintainerInfo specifies the support email address for a bidder.
@@ -32,6 +42,9 @@ type MaintainerInfo struct {
 type CapabilitiesInfo struct {
 	App  *PlatformInfo `yaml:"app"`
 	Site *PlatformInfo `yaml:"site"`
+	// DOOH lists the media types the bidder supports for the requests of the dooh channel, which it doesn't get
+	// otherwise
+	DOOH *PlatformInfo `yaml:"dooh"`
 }
 
 // PlatformInfo specifies the supported media types for a bidder.
@@ -39,6 +52,25 @@ type PlatformInfo struct {
 	MediaTypes []openrtb_ext.BidType `yaml:"mediaTypes"`
 }
 
+// CompressionInfo specifies the gzip support of a bidder endpoint.
+type CompressionInfo struct {
+	// GZIPRequests compresses the bodies of the requests sent to the bidder
+	GZIPRequests bool `yaml:"gzipRequests"`
+	// GZIPResponses asks the bidder for gzip compressed responses
+	GZIPResponses bool `yaml:"gzipResponses"`
+}
+
+// HTTPClientInfo tunes the HTTP client used to call a bidder. Unset values keep the settings of the http_client config.
+type HTTPClientInfo struct {
+	MaxIdleConns        int `yaml:"maxIdleConns"`
+	MaxIdleConnsPerHost int `yaml:"maxIdleConnsPerHost"`
+	IdleConnTimeout     int `yaml:"idleConnTimeoutSeconds"`
+	// EnableHTTP2 attempts HTTP/2 with the bidder, which the TLS config of the shared client otherwise turns off
+	EnableHTTP2 bool `yaml:"enableHttp2"`
+	// TLSSessionCacheSize is the number of TLS sessions kept for resumption with the bidder
+	TLSSessionCacheSize int `yaml:"tlsSessionCacheSize"`
+}
+
 // DebugInfo specifies the supported debug options for a bidder.
 type DebugInfo struct {
 	Allow bool `yaml:"allow"`
@@ -70,6 +102,12 @@ type Syncer struct {
 
 	// SupportCORS identifies if CORS is supported for the user syncing endpoints.
 	SupportCORS *bool `yaml:"supportCors" mapstructure:"support_cors"`
+
+	// ChainRedirectURL is an endpoint on the bidder server the user is redirected to by the /setuid
+	// endpoint once the uid is stored, for bidders whose sync flow continues after Prebid Server.
+	// The {{.UID}}, {{.GDPR}}, {{.GDPRConsent}} and {{.USPrivacy}} macros are resolved with the
+	// url escaped values of the /setuid call.
+	ChainRedirectURL string `yaml:"chainRedirectUrl" mapstructure:"chain_redirect_url"`
 }
 
 // Override returns a new Syncer object where values in the original are replaced by non-empty/non-default
@@ -113,6 +151,10 @@ func (s *Syncer) Override(original *Syncer) *Syncer {
 		copy.SupportCORS = s.SupportCORS
 	}
 
+	if s.ChainRedirectURL != "" {
+		copy.ChainRedirectURL = s.ChainRedirectURL
+	}
+
 	return &copy
 }
 
@@ -216,6 +258,13 @@ func loadBidderInfo(r infoReader, adapterConfigs map[string]Adapter, bidders []s
 	infos := BidderInfos{}
 
 	for _, bidder := range bidders {
+		if adapterConfig, ok := adapterConfigs[strings.ToLower(bidder)]; ok && adapterConfig.GenericORTB != nil {
+			info := genericORTBBidderInfo(adapterConfig.GenericORTB)
+			info.Enabled = !adapterConfig.Disabled
+			infos[bidder] = info
+			continue
+		}
+
 		data, err := r.Read(bidder)
 		if err != nil {
 			return nil, err
@@ -233,6 +282,17 @@ func loadBidderInfo(r infoReader, adapterConfigs map[string]Adapter, bidders []s
 	return infos, nil
 }
 
+// genericORTBBidderInfo builds the bidder info of a bidder onboarded through config, which has no bidder info file
+func genericORTBBidderInfo(genericORTB *GenericORTB) BidderInfo {
+	return BidderInfo{
+		Capabilities: &CapabilitiesInfo{
+			App:  &PlatformInfo{MediaTypes: genericORTB.MediaTypes},
+			Site: &PlatformInfo{MediaTypes: genericORTB.MediaTypes},
+		},
+		GVLVendorID: genericORTB.GVLVendorID,
+	}
+}
+
 func isEnabledByConfig(adapterConfigs map[string]Adapter, bidderName string) bool {
 	a, ok := adapterConfigs[strings.ToLower(bidderName)]
 	return ok && !a.Disabled
@@ -60,6 +60,17 @@ func TestLoadBidderInfoFromDisk(t *testing.T) {
 				},
 				SupportCORS: &trueValue,
 			},
+			Compression: &CompressionInfo{
+				GZIPRequests:  true,
+				GZIPResponses: true,
+			},
+			HTTPClient: &HTTPClientInfo{
+				MaxIdleConns:        200,
+				MaxIdleConnsPerHost: 50,
+				IdleConnTimeout:     30,
+				EnableHTTP2:         true,
+				TLSSessionCacheSize: 128,
+			},
 		},
 	}
 	assert.Equal(t, expected, infos)
@@ -132,6 +143,24 @@ func TestLoadBidderInfo(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "Generic ORTB - Built From Config",
+			givenConfigs: map[string]Adapter{strings.ToLower(bidder): {GenericORTB: &GenericORTB{
+				GVLVendorID: 42,
+				MediaTypes:  []openrtb_ext.BidType{openrtb_ext.BidTypeBanner},
+			}}},
+			givenError: errors.New("generic bidders have no bidder info file"),
+			expectedInfo: map[string]BidderInfo{
+				bidder: {
+					Enabled: true,
+					Capabilities: &CapabilitiesInfo{
+						App:  &PlatformInfo{MediaTypes: []openrtb_ext.BidType{openrtb_ext.BidTypeBanner}},
+						Site: &PlatformInfo{MediaTypes: []openrtb_ext.BidType{openrtb_ext.BidTypeBanner}},
+					},
+					GVLVendorID: 42,
+				},
+			},
+		},
 		{
 			description:   "Read Error",
 			givenConfigs:  map[string]Adapter{strings.ToLower(bidder): {}},
@@ -232,6 +261,12 @@ func TestSyncerOverride(t *testing.T) {
 			givenOverride: &Syncer{SupportCORS: &falseValue},
 			expected:      &Syncer{SupportCORS: &falseValue},
 		},
+		{
+			description:   "Override ChainRedirectURL",
+			givenOriginal: &Syncer{ChainRedirectURL: "original"},
+			givenOverride: &Syncer{ChainRedirectURL: "override"},
+			expected:      &Syncer{ChainRedirectURL: "override"},
+		},
 		{
 			description:   "Override Partial - Other Fields Untouched",
 			givenOriginal: &Syncer{Key: "originalKey", Default: "originalDefault"},
@@ -53,6 +53,9 @@ type Configuration struct {
 	GDPR                 GDPR               `mapstructure:"gdpr"`
 	CCPA                 CCPA               `mapstructure:"ccpa"`
 	LMT                  LMT                `mapstructure:"lmt"`
+	LGPD                 LGPD               `mapstructure:"lgpd"`
+	GPP                  GPP                `mapstructure:"gpp"`
+	ScrubProfiles        ScrubProfiles      `mapstructure:"scrub_profiles"`
 	CurrencyConverter    CurrencyConverter  `mapstructure:"currency_converter"`
 	DefReqConfig         DefReqConfig       `mapstructure:"default_request"`
 
@@ -71,6 +74,8 @@ type Configuration struct {
 	AccountDefaults Account `mapstructure:"account_defaults"`
 	// accountDefaultsJSON is the internal serialized form of AccountDefaults used for json merge
 	accountDefaultsJSON json.RawMessage
+	// AccountReload reloads the stored accounts periodically, keeping the last version which passed validation
+	AccountReload AccountReload `mapstructure:"account_reload"`
 	// Local private file containing SSL certificates
 	PemCertsFile string `mapstructure:"certificates_file"`
 	// Custom headers to handle request timeouts from queueing infrastructure
@@ -85,6 +90,266 @@ type Configuration struct {
 	GenerateBidID bool `mapstructure:"generate_bid_id"`
 	// GenerateRequestID overrides the bidrequest.id in an AMP Request or an App Stored Request with a generated UUID if set to true. The default is false.
 	GenerateRequestID bool `mapstructure:"generate_request_id"`
+	// AuctionResponseCompression configures gzip compression of the /openrtb2/auction responses
+	AuctionResponseCompression ResponseCompression `mapstructure:"auction_response_compression"`
+	// TmaxAdjustments shortens the time given to the bidders so that the auction response meets the request tmax
+	TmaxAdjustments TmaxAdjustments `mapstructure:"tmax_adjustments"`
+	// AllowAliasEndpointOverrides lets the requests and the accounts call aliases at another endpoint than their core bidder
+	AllowAliasEndpointOverrides bool `mapstructure:"allow_alias_endpoint_overrides"`
+	// BidderCapture writes the calls of the bidders sampled by the accounts to a sink, to reproduce adapter bugs
+	BidderCapture BidderCapture `mapstructure:"bidder_capture"`
+	// FirstPartyData limits what the ext.prebid.bidderconfig of the requests may set in the bidder requests
+	FirstPartyData FirstPartyData `mapstructure:"first_party_data"`
+	// GeoLocation locates the devices of the requests without device.geo.country from their IP address
+	GeoLocation GeoLocation `mapstructure:"geolocation"`
+	// UserID mints the first party ids of the users, sent to the bidders of the accounts enabling them
+	UserID UserID `mapstructure:"user_id"`
+	// Tracing exports OpenTelemetry spans of the requests, to see where the time of the slow requests is spent
+	Tracing Tracing `mapstructure:"tracing"`
+}
+
+// ResponseCompression configures gzip compression of an endpoint's responses. Responses smaller than
+// MinSizeBytes are sent uncompressed, since compressing them costs more CPU than it saves in bandwidth.
+type ResponseCompression struct {
+	Enabled      bool `mapstructure:"enabled"`
+	MinSizeBytes int  `mapstructure:"min_size_bytes"`
+}
+
+func (cfg *ResponseCompression) validate(errs []error) []error {
+	if cfg.MinSizeBytes < 0 {
+		errs = append(errs, fmt.Errorf("auction_response_compression.min_size_bytes must be >= 0. Got %d", cfg.MinSizeBytes))
+	}
+	return errs
+}
+
+// TmaxAdjustments computes the deadline of the bidder calls from the time left in the auction. Once
+// PBSResponsePreparationDurationMs is set aside for preparing the auction response, the bidders get
+// BidderTmaxPercent of the remaining time. The tmax sent to them is BidderNetworkLatencyBufferMs shorter than
+// their deadline, to leave room for the network round trip. Bidders left with a tmax under
+// BidderResponseDurationMinMs aren't called at all.
+type TmaxAdjustments struct {
+	Enabled                          bool `mapstructure:"enabled"`
+	BidderTmaxPercent                int  `mapstructure:"bidder_tmax_percent"`
+	BidderNetworkLatencyBufferMs     int  `mapstructure:"bidder_network_latency_buffer_ms"`
+	PBSResponsePreparationDurationMs int  `mapstructure:"pbs_response_preparation_duration_ms"`
+	BidderResponseDurationMinMs      int  `mapstructure:"bidder_response_duration_min_ms"`
+}
+
+func (cfg *TmaxAdjustments) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.BidderTmaxPercent <= 0 || cfg.BidderTmaxPercent > 100 {
+		errs = append(errs, fmt.Errorf("tmax_adjustments.bidder_tmax_percent must be between 1 and 100. Got %d", cfg.BidderTmaxPercent))
+	}
+	if cfg.BidderNetworkLatencyBufferMs < 0 {
+		errs = append(errs, fmt.Errorf("tmax_adjustments.bidder_network_latency_buffer_ms must be >= 0. Got %d", cfg.BidderNetworkLatencyBufferMs))
+	}
+	if cfg.PBSResponsePreparationDurationMs < 0 {
+		errs = append(errs, fmt.Errorf("tmax_adjustments.pbs_response_preparation_duration_ms must be >= 0. Got %d", cfg.PBSResponsePreparationDurationMs))
+	}
+	if cfg.BidderResponseDurationMinMs < 0 {
+		errs = append(errs, fmt.Errorf("tmax_adjustments.bidder_response_duration_min_ms must be >= 0. Got %d", cfg.BidderResponseDurationMinMs))
+	}
+	return errs
+}
+
+// BidderCapture configures where the captured bidder calls are written. Which calls are captured is set by account,
+// through account_defaults.bidder_capture or the config of each account. The calls are queued up to BufferSize and
+// dropped past it, so that a slow sink never holds the auctions back.
+type BidderCapture struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Sink is either "file", which appends the calls as JSON lines to Filename, or "http", which posts them to Endpoint
+	Sink       string `mapstructure:"sink"`
+	Filename   string `mapstructure:"filename"`
+	Endpoint   string `mapstructure:"endpoint"`
+	TimeoutMs  int    `mapstructure:"timeout_ms"`
+	BufferSize int    `mapstructure:"buffer_size"`
+}
+
+const (
+	BidderCaptureSinkFile = "file"
+	BidderCaptureSinkHTTP = "http"
+)
+
+func (cfg *BidderCapture) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	switch cfg.Sink {
+	case BidderCaptureSinkFile:
+		if cfg.Filename == "" {
+			errs = append(errs, errors.New("bidder_capture.filename is required with the file sink"))
+		}
+	case BidderCaptureSinkHTTP:
+		if cfg.Endpoint == "" {
+			errs = append(errs, errors.New("bidder_capture.endpoint is required with the http sink"))
+		}
+		if cfg.TimeoutMs <= 0 {
+			errs = append(errs, fmt.Errorf("bidder_capture.timeout_ms must be > 0. Got %d", cfg.TimeoutMs))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("bidder_capture.sink must be either %s or %s. Got %q", BidderCaptureSinkFile, BidderCaptureSinkHTTP, cfg.Sink))
+	}
+	if cfg.BufferSize <= 0 {
+		errs = append(errs, fmt.Errorf("bidder_capture.buffer_size must be > 0. Got %d", cfg.BufferSize))
+	}
+	return errs
+}
+
+// GeoLocation configures the lookup of the country and region of the devices from their IP address, for the requests
+// without a device.geo.country. The location only decides which privacy regulations apply to the request, such as
+// GDPR or the US state laws, and isn't sent to the bidders.
+type GeoLocation struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Type is either "maxmind", which reads a MaxMind DB file, or "http", which calls a geolocation service
+	Type    string             `mapstructure:"type"`
+	MaxMind GeoLocationMaxMind `mapstructure:"maxmind"`
+	HTTP    GeoLocationHTTP    `mapstructure:"http"`
+}
+
+// GeoLocationMaxMind configures the MaxMind DB file, such as GeoIP2 or GeoLite2 City. The file is reloaded every
+// RefreshIntervalSeconds if it changed, or never if 0.
+type GeoLocationMaxMind struct {
+	DatabasePath           string `mapstructure:"database_path"`
+	RefreshIntervalSeconds int    `mapstructure:"refresh_interval_seconds"`
+}
+
+// GeoLocationHTTP configures the geolocation service, which is called as GET Endpoint?ip=<ip>
+type GeoLocationHTTP struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	TimeoutMs int    `mapstructure:"timeout_ms"`
+}
+
+const (
+	GeoLocationTypeMaxMind = "maxmind"
+	GeoLocationTypeHTTP    = "http"
+)
+
+func (cfg *GeoLocation) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	switch cfg.Type {
+	case GeoLocationTypeMaxMind:
+		if cfg.MaxMind.DatabasePath == "" {
+			errs = append(errs, errors.New("geolocation.maxmind.database_path is required with the maxmind type"))
+		}
+		if cfg.MaxMind.RefreshIntervalSeconds < 0 {
+			errs = append(errs, fmt.Errorf("geolocation.maxmind.refresh_interval_seconds must be >= 0. Got %d", cfg.MaxMind.RefreshIntervalSeconds))
+		}
+	case GeoLocationTypeHTTP:
+		if cfg.HTTP.Endpoint == "" {
+			errs = append(errs, errors.New("geolocation.http.endpoint is required with the http type"))
+		}
+		if cfg.HTTP.TimeoutMs <= 0 {
+			errs = append(errs, fmt.Errorf("geolocation.http.timeout_ms must be > 0. Got %d", cfg.HTTP.TimeoutMs))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("geolocation.type must be either %s or %s. Got %q", GeoLocationTypeMaxMind, GeoLocationTypeHTTP, cfg.Type))
+	}
+	return errs
+}
+
+// UserID configures the first party ids Prebid Server mints for the users of the web requests. The id is persisted in
+// the CookieName cookie of the host for TTLDays, signed with the HMAC-SHA256 of the SigningKey. Each account is sent an
+// id of its own derived from it in user.ext.eids, under the Source.
+type UserID struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	CookieName string `mapstructure:"cookie_name"`
+	TTLDays    int    `mapstructure:"ttl_days"`
+	SigningKey string `mapstructure:"signing_key"`
+	Source     string `mapstructure:"source"`
+}
+
+// TTLDuration returns the time the cookie of the ids lasts
+func (cfg *UserID) TTLDuration() time.Duration {
+	return time.Duration(cfg.TTLDays) * time.Hour * 24
+}
+
+func (cfg *UserID) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.CookieName == "" {
+		errs = append(errs, errors.New("user_id.cookie_name is required"))
+	}
+	if cfg.TTLDays <= 0 {
+		errs = append(errs, fmt.Errorf("user_id.ttl_days must be > 0. Got %d", cfg.TTLDays))
+	}
+	if cfg.SigningKey == "" {
+		errs = append(errs, errors.New("user_id.signing_key is required"))
+	}
+	if cfg.Source == "" {
+		errs = append(errs, errors.New("user_id.source is required"))
+	}
+	return errs
+}
+
+// Tracing configures the OpenTelemetry spans of the requests, posted in batches of up to BatchSize spans to the
+// OTLP/HTTP Endpoint of a collector. SamplePercent of the requests are traced, including those continuing the trace
+// of the caller, unless HonorCallerSampling is set to trace exactly the requests the caller sampled in the W3C
+// traceparent header it sent. The trace context is read from and sent to the bidders in each one of the
+// PropagationHeaders, in the traceparent format. The spans are queued up to BufferSize and dropped past it, and
+// the dropped spans are logged once per flush interval.
+type Tracing struct {
+	Enabled             bool     `mapstructure:"enabled"`
+	Endpoint            string   `mapstructure:"endpoint"`
+	ServiceName         string   `mapstructure:"service_name"`
+	SamplePercent       float64  `mapstructure:"sample_percent"`
+	HonorCallerSampling bool     `mapstructure:"honor_caller_sampling"`
+	PropagationHeaders  []string `mapstructure:"propagation_headers"`
+	BatchSize           int      `mapstructure:"batch_size"`
+	FlushIntervalMs     int      `mapstructure:"flush_interval_ms"`
+	TimeoutMs           int      `mapstructure:"timeout_ms"`
+	BufferSize          int      `mapstructure:"buffer_size"`
+}
+
+func (cfg *Tracing) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.Endpoint == "" {
+		errs = append(errs, errors.New("tracing.endpoint is required"))
+	}
+	if cfg.SamplePercent < 0 || cfg.SamplePercent > 100 {
+		errs = append(errs, fmt.Errorf("tracing.sample_percent must be between 0 and 100. Got %g", cfg.SamplePercent))
+	}
+	if cfg.BatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("tracing.batch_size must be > 0. Got %d", cfg.BatchSize))
+	}
+	if cfg.FlushIntervalMs <= 0 {
+		errs = append(errs, fmt.Errorf("tracing.flush_interval_ms must be > 0. Got %d", cfg.FlushIntervalMs))
+	}
+	if cfg.TimeoutMs <= 0 {
+		errs = append(errs, fmt.Errorf("tracing.timeout_ms must be > 0. Got %d", cfg.TimeoutMs))
+	}
+	if cfg.BufferSize <= 0 {
+		errs = append(errs, fmt.Errorf("tracing.buffer_size must be > 0. Got %d", cfg.BufferSize))
+	}
+	return errs
+}
+
+// FirstPartyData configures how the ext.prebid.bidderconfig of a request is merged into the site, app and user of
+// its bidders. The bidder configs may only set the listed attributes of each object, the others are dropped with a
+// warning. Conflict decides which one of the request and the bidder config wins when both set an attribute.
+type FirstPartyData struct {
+	SiteAttributes []string `mapstructure:"site_attributes"`
+	AppAttributes  []string `mapstructure:"app_attributes"`
+	UserAttributes []string `mapstructure:"user_attributes"`
+	Conflict       string   `mapstructure:"conflict"`
+}
+
+const (
+	FirstPartyDataConflictBidder  = "bidder"
+	FirstPartyDataConflictRequest = "request"
+)
+
+func (cfg *FirstPartyData) validate(errs []error) []error {
+	if cfg.Conflict != FirstPartyDataConflictBidder && cfg.Conflict != FirstPartyDataConflictRequest {
+		errs = append(errs, fmt.Errorf("first_party_data.conflict must be either %s or %s. Got %q", FirstPartyDataConflictBidder, FirstPartyDataConflictRequest, cfg.Conflict))
+	}
+	return errs
 }
 
 const MIN_COOKIE_SIZE_BYTES = 500
@@ -109,10 +374,31 @@ func (cfg *Configuration) validate(v *viper.Viper) []error {
 		errs = append(errs, fmt.Errorf("cfg.max_request_size must be >= 0. Got %d", cfg.MaxRequestSize))
 	}
 	errs = cfg.GDPR.validate(v, errs)
+	errs = cfg.ScrubProfiles.validate(errs)
 	errs = cfg.CurrencyConverter.validate(errs)
 	errs = validateAdapters(cfg.Adapters, errs)
 	errs = cfg.Debug.validate(errs)
 	errs = cfg.ExtCacheURL.validate(errs)
+	errs = cfg.CacheURL.Embedded.validate(errs)
+	errs = cfg.CacheURL.AsyncWrites.validate(errs)
+	errs = cfg.AuctionResponseCompression.validate(errs)
+	errs = cfg.TmaxAdjustments.validate(errs)
+	errs = cfg.BidderCapture.validate(errs)
+	errs = cfg.FirstPartyData.validate(errs)
+	errs = cfg.GeoLocation.validate(errs)
+	errs = cfg.UserID.validate(errs)
+	errs = cfg.Tracing.validate(errs)
+	errs = cfg.Analytics.validate(errs)
+	errs = cfg.UserSync.validate(errs)
+	errs = cfg.HostCookie.validate(errs)
+	errs = cfg.Event.validate(errs)
+	errs = cfg.AccountDefaults.Validations.validate(errs)
+	errs = cfg.AccountDefaults.TrafficShaping.validate(errs)
+	errs = cfg.AccountDefaults.BidderCapture.validate(errs)
+	errs = cfg.AccountDefaults.CookieSync.validate(errs)
+	errs = cfg.AccountDefaults.validate("account_defaults.", errs)
+	errs = cfg.AccountDefaults.validatePreferredMediaType("account_defaults.", errs)
+	errs = cfg.AccountReload.validate(cfg.Accounts.InMemoryCache.Type, errs)
 	if cfg.AccountDefaults.Disabled {
 		glog.Warning(`With account_defaults.disabled=true, host-defined accounts must exist and have "disabled":false. All other requests will be rejected.`)
 	}
@@ -188,9 +474,12 @@ func (cfg *AuctionTimeouts) LimitAuctionTimeout(requested time.Duration) time.Du
 
 // Privacy is a grouping of privacy related configs to assist in dependency injection.
 type Privacy struct {
-	CCPA CCPA
-	GDPR GDPR
-	LMT  LMT
+	CCPA          CCPA
+	GDPR          GDPR
+	LMT           LMT
+	LGPD          LGPD
+	GPP           GPP
+	ScrubProfiles ScrubProfiles
 }
 
 type GDPR struct {
@@ -222,12 +511,33 @@ func (cfg *GDPR) validate(v *viper.Viper, errs []error) []error {
 	if cfg.HostVendorID == 0 {
 		glog.Warning("gdpr.host_vendor_id was not specified. Host company GDPR checks will be skipped.")
 	}
+	for i, purpose := range cfg.TCF2.purposeConfigs() {
+		if purpose.EnforcePurpose != "" && !ValidTCF2Enforcement(purpose.EnforcePurpose) {
+			errs = append(errs, fmt.Errorf("gdpr.tcf2.purpose%d.enforce_purpose must be full, basic or no. Got %s", i+1, purpose.EnforcePurpose))
+		}
+	}
 	if cfg.AMPException == true {
 		errs = append(errs, fmt.Errorf("gdpr.amp_exception has been discontinued and must be removed from your config. If you need to disable GDPR for AMP, you may do so per-account (gdpr.integration_enabled.amp) or at the host level for the default account (account_defaults.gdpr.integration_enabled.amp)"))
 	}
 	return errs
 }
 
+// purposeConfigs returns the configs of the purposes 1 to 10, in order
+func (t *TCF2) purposeConfigs() []*TCF2Purpose {
+	return []*TCF2Purpose{
+		&t.Purpose1,
+		&t.Purpose2,
+		&t.Purpose3,
+		&t.Purpose4,
+		&t.Purpose5,
+		&t.Purpose6,
+		&t.Purpose7,
+		&t.Purpose8,
+		&t.Purpose9,
+		&t.Purpose10,
+	}
+}
+
 type GDPRTimeouts struct {
 	InitVendorlistFetch   int `mapstructure:"init_vendorlist_fetches"`
 	ActiveVendorlistFetch int `mapstructure:"active_vendorlist_fetch"`
@@ -241,7 +551,9 @@ func (t *GDPRTimeouts) ActiveTimeout() time.Duration {
 	return time.Duration(t.ActiveVendorlistFetch) * time.Millisecond
 }
 
-// TCF2 defines the TCF2 specific configurations for GDPR
+// TCF2 defines the TCF2 specific configurations for GDPR. SpecialPurpose1 configures the enforcement of the special
+// feature 1, the use of precise geolocation data; it keeps the name it got before special purposes and special
+// features were told apart.
 type TCF2 struct {
 	Enabled             bool                    `mapstructure:"enabled"`
 	Purpose1            TCF2Purpose             `mapstructure:"purpose1"`
@@ -260,13 +572,34 @@ type TCF2 struct {
 
 // Making a purpose struct so purpose specific details can be added later.
 type TCF2Purpose struct {
-	Enabled        bool `mapstructure:"enabled"`
-	EnforceVendors bool `mapstructure:"enforce_vendors"`
+	Enabled bool `mapstructure:"enabled"`
+	// EnforcePurpose is the enforcement mode of the purpose: full, basic or no
+	EnforcePurpose string `mapstructure:"enforce_purpose"`
+	EnforceVendors bool   `mapstructure:"enforce_vendors"`
 	// Array of vendor exceptions that is used to create the hash table VendorExceptionMap so vendor names can be instantly accessed
 	VendorExceptions   []openrtb_ext.BidderName `mapstructure:"vendor_exceptions"`
 	VendorExceptionMap map[openrtb_ext.BidderName]struct{}
 }
 
+// The enforcement modes of a TCF2 purpose. The full enforcement checks the legal basis of the purpose, and the vendor
+// consent or legitimate interest of the bidders against their declarations in the Global Vendor List. The basic
+// enforcement leaves out the Global Vendor List, and the purposes which aren't enforced are always allowed. An empty
+// mode is the full enforcement.
+const (
+	TCF2FullEnforcement  = "full"
+	TCF2BasicEnforcement = "basic"
+	TCF2NoEnforcement    = "no"
+)
+
+// ValidTCF2Enforcement tells if the enforcement mode is one of full, basic or no
+func ValidTCF2Enforcement(enforcement string) bool {
+	switch enforcement {
+	case TCF2FullEnforcement, TCF2BasicEnforcement, TCF2NoEnforcement:
+		return true
+	}
+	return false
+}
+
 type TCF2PurposeOneTreatment struct {
 	Enabled       bool `mapstructure:"enabled"`
 	AccessAllowed bool `mapstructure:"access_allowed"`
@@ -280,21 +613,142 @@ type LMT struct {
 	Enforce bool `mapstructure:"enforce"`
 }
 
+// GPP configures the reading of the GDPR and CCPA signals from the GPP string of regs.ext.gpp. When disabled, they
+// are read from their own fields of the request only.
+type GPP struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// LGPD configures the enforcement of the Brazilian General Data Protection Law. It applies to the requests whose
+// device.geo.country is one of the countries.
+type LGPD struct {
+	Enforce      bool     `mapstructure:"enforce"`
+	Countries    []string `mapstructure:"countries"`
+	CountriesMap map[string]struct{}
+}
+
+// ScrubProfiles are the scrubbing profiles of the privacy regulations removing personal data from the bidder requests
+// as a whole, rather than by the consent of the users
+type ScrubProfiles struct {
+	COPPA ScrubProfile `mapstructure:"coppa"`
+	LGPD  ScrubProfile `mapstructure:"lgpd"`
+}
+
+// ScrubProfile tells what a privacy regulation scrubs from the bidder requests. The empty fields scrub nothing, while an
+// empty profile scrubs like the default profile of its regulation.
+type ScrubProfile struct {
+	// User is one of none, eids, ids or ids_and_demographics. The ids are user.id, user.buyeruid and the EIDs, and the
+	// demographics are user.yob and user.gender.
+	User string `mapstructure:"user"`
+	// DeviceIDs removes the hardware and device IDs of device
+	DeviceIDs bool `mapstructure:"device_ids"`
+	// Geo is one of none, reduced or full. The reduced one rounds the geo coordinates and truncates the lowest 8 bits of
+	// the IPv4 and 16 bits of the IPv6 addresses, while the full one removes the geo and truncates 32 bits of IPv6.
+	Geo string `mapstructure:"geo"`
+}
+
+// The scrubbing of the users and geo of the scrub profiles
+const (
+	ScrubUserNone               = "none"
+	ScrubUserEIDs               = "eids"
+	ScrubUserIDs                = "ids"
+	ScrubUserIDsAndDemographics = "ids_and_demographics"
+	ScrubGeoNone                = "none"
+	ScrubGeoReduced             = "reduced"
+	ScrubGeoFull                = "full"
+)
+
+func (p *ScrubProfiles) validate(errs []error) []error {
+	for _, profile := range []struct {
+		name    string
+		profile ScrubProfile
+	}{
+		{"coppa", p.COPPA},
+		{"lgpd", p.LGPD},
+	} {
+		switch profile.profile.User {
+		case "", ScrubUserNone, ScrubUserEIDs, ScrubUserIDs, ScrubUserIDsAndDemographics:
+		default:
+			errs = append(errs, fmt.Errorf("scrub_profiles.%s.user must be one of %s, %s, %s or %s. Got %s", profile.name, ScrubUserNone, ScrubUserEIDs, ScrubUserIDs, ScrubUserIDsAndDemographics, profile.profile.User))
+		}
+		switch profile.profile.Geo {
+		case "", ScrubGeoNone, ScrubGeoReduced, ScrubGeoFull:
+		default:
+			errs = append(errs, fmt.Errorf("scrub_profiles.%s.geo must be one of %s, %s or %s. Got %s", profile.name, ScrubGeoNone, ScrubGeoReduced, ScrubGeoFull, profile.profile.Geo))
+		}
+	}
+	return errs
+}
+
 type Analytics struct {
-	File     FileLogs `mapstructure:"file"`
-	Pubstack Pubstack `mapstructure:"pubstack"`
+	File       FileLogs            `mapstructure:"file"`
+	Pubstack   Pubstack            `mapstructure:"pubstack"`
+	ClickHouse ClickHouseAnalytics `mapstructure:"clickhouse"`
+	RequestLog RequestLog          `mapstructure:"request_log"`
+	// RequestOptions lists the options of the modules, by module name, which the requests may override with their
+	// ext.prebid.analytics. The other options of the requests are ignored.
+	RequestOptions map[string][]string `mapstructure:"request_options"`
+}
+
+// reservedAnalyticsOptions are the options routing the events of an account to its own scope or endpoint, which only
+// the accounts may set
+var reservedAnalyticsOptions = map[string]bool{"scopeid": true, "endpoint": true}
+
+func (cfg *Analytics) validate(errs []error) []error {
+	errs = cfg.ClickHouse.validate(errs)
+	errs = cfg.RequestLog.validate(errs)
+	for module, options := range cfg.RequestOptions {
+		for _, option := range options {
+			if reservedAnalyticsOptions[option] {
+				errs = append(errs, fmt.Errorf("analytics.request_options.%s cannot include %s, which only the accounts may set", module, option))
+			}
+		}
+	}
+	return errs
+}
+
+// RequestOverridable tells if the requests may override the option of the module
+func (cfg *Analytics) RequestOverridable(module string, option string) bool {
+	if reservedAnalyticsOptions[option] {
+		return false
+	}
+	for _, overridable := range cfg.RequestOptions[module] {
+		if overridable == option {
+			return true
+		}
+	}
+	return false
 }
 
 type CurrencyConverter struct {
 	FetchURL             string `mapstructure:"fetch_url"`
 	FetchIntervalSeconds int    `mapstructure:"fetch_interval_seconds"`
 	StaleRatesSeconds    int    `mapstructure:"stale_rates_seconds"`
+	// FallbackSources are fetched in order when the fetch url fails. The rates are fetched from the fetch url again
+	// on every interval, so that it is used again once it recovers.
+	FallbackSources []CurrencyRatesSource `mapstructure:"fallback_sources"`
+}
+
+// CurrencyRatesSource is a url or a file the currency rates are fetched from. The rates fetched from the source are
+// dropped for the constant rates once they have not been updated for StaleRatesSeconds, unless it is 0.
+type CurrencyRatesSource struct {
+	URL               string `mapstructure:"url"`
+	File              string `mapstructure:"file"`
+	StaleRatesSeconds int    `mapstructure:"stale_rates_seconds"`
 }
 
 func (cfg *CurrencyConverter) validate(errs []error) []error {
 	if cfg.FetchIntervalSeconds < 0 {
 		errs = append(errs, fmt.Errorf("currency_converter.fetch_interval_seconds must be in the range [0, %d]. Got %d", 0xffff, cfg.FetchIntervalSeconds))
 	}
+	for i, source := range cfg.FallbackSources {
+		if (source.URL == "") == (source.File == "") {
+			errs = append(errs, fmt.Errorf("currency_converter.fallback_sources[%d] must set exactly one of url and file", i))
+		}
+		if source.StaleRatesSeconds < 0 {
+			errs = append(errs, fmt.Errorf("currency_converter.fallback_sources[%d].stale_rates_seconds must be >= 0. Got %d", i, source.StaleRatesSeconds))
+		}
+	}
 	return errs
 }
 
@@ -317,6 +771,121 @@ type PubstackBuffer struct {
 	Timeout    string `mapstructure:"timeout"`
 }
 
+// ClickHouseAnalytics configures the analytics module inserting the auction, amp, video, setuid and cookie sync
+// events into the tables of Database, through the HTTP interface of the ClickHouse server at Endpoint. The rows of
+// each table are inserted in batches of up to BatchSize rows, or every FlushIntervalMs, as async inserts. The rows
+// are queued up to BufferSize per table and dropped past it.
+type ClickHouseAnalytics struct {
+	Enabled         bool   `mapstructure:"enabled"`
+	Endpoint        string `mapstructure:"endpoint"`
+	Database        string `mapstructure:"database"`
+	Username        string `mapstructure:"username"`
+	Password        string `mapstructure:"password"`
+	BatchSize       int    `mapstructure:"batch_size"`
+	FlushIntervalMs int    `mapstructure:"flush_interval_ms"`
+	TimeoutMs       int    `mapstructure:"timeout_ms"`
+	BufferSize      int    `mapstructure:"buffer_size"`
+}
+
+func (cfg *ClickHouseAnalytics) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.Endpoint == "" {
+		errs = append(errs, errors.New("analytics.clickhouse.endpoint is required"))
+	}
+	if cfg.Database == "" {
+		errs = append(errs, errors.New("analytics.clickhouse.database is required"))
+	}
+	if cfg.BatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("analytics.clickhouse.batch_size must be > 0. Got %d", cfg.BatchSize))
+	}
+	if cfg.FlushIntervalMs <= 0 {
+		errs = append(errs, fmt.Errorf("analytics.clickhouse.flush_interval_ms must be > 0. Got %d", cfg.FlushIntervalMs))
+	}
+	if cfg.TimeoutMs <= 0 {
+		errs = append(errs, fmt.Errorf("analytics.clickhouse.timeout_ms must be > 0. Got %d", cfg.TimeoutMs))
+	}
+	if cfg.BufferSize <= 0 {
+		errs = append(errs, fmt.Errorf("analytics.clickhouse.buffer_size must be > 0. Got %d", cfg.BufferSize))
+	}
+	return errs
+}
+
+// RequestLog configures the analytics module writing a JSON line per auction, amp and video request, to stdout or to
+// the File when set. SamplePercent of the requests are logged, with the time, endpoint, status and id of the request
+// and the Fields among account, bidders, tmax, nbr, response_time, bid_counts and errors, or all of them when empty.
+// The lines are buffered, and written every FlushIntervalMs.
+type RequestLog struct {
+	Enabled         bool     `mapstructure:"enabled"`
+	File            string   `mapstructure:"file"`
+	SamplePercent   float64  `mapstructure:"sample_percent"`
+	Fields          []string `mapstructure:"fields"`
+	FlushIntervalMs int      `mapstructure:"flush_interval_ms"`
+}
+
+// The fields of the request log
+const (
+	RequestLogFieldAccount      = "account"
+	RequestLogFieldBidders      = "bidders"
+	RequestLogFieldTMax         = "tmax"
+	RequestLogFieldNBR          = "nbr"
+	RequestLogFieldResponseTime = "response_time"
+	RequestLogFieldBidCounts    = "bid_counts"
+	RequestLogFieldErrors       = "errors"
+)
+
+// RequestLogFields returns all the fields of the request log
+func RequestLogFields() []string {
+	return []string{
+		RequestLogFieldAccount,
+		RequestLogFieldBidders,
+		RequestLogFieldTMax,
+		RequestLogFieldNBR,
+		RequestLogFieldResponseTime,
+		RequestLogFieldBidCounts,
+		RequestLogFieldErrors,
+	}
+}
+
+// LogsAllErrors tells if the request log has the errors of every request, in which case the endpoints don't log them
+func (cfg *RequestLog) LogsAllErrors() bool {
+	if !cfg.Enabled || cfg.SamplePercent < 100 {
+		return false
+	}
+	if len(cfg.Fields) == 0 {
+		return true
+	}
+	for _, field := range cfg.Fields {
+		if field == RequestLogFieldErrors {
+			return true
+		}
+	}
+	return false
+}
+
+func (cfg *RequestLog) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.SamplePercent < 0 || cfg.SamplePercent > 100 {
+		errs = append(errs, fmt.Errorf("analytics.request_log.sample_percent must be between 0 and 100. Got %g", cfg.SamplePercent))
+	}
+	if cfg.FlushIntervalMs <= 0 {
+		errs = append(errs, fmt.Errorf("analytics.request_log.flush_interval_ms must be > 0. Got %d", cfg.FlushIntervalMs))
+	}
+	for _, field := range cfg.Fields {
+		known := false
+		for _, knownField := range RequestLogFields() {
+			known = known || field == knownField
+		}
+		if !known {
+			errs = append(errs, fmt.Errorf("analytics.request_log.fields must be among %s. Got %s", strings.Join(RequestLogFields(), ", "), field))
+		}
+	}
+	return errs
+}
+
 type VTrack struct {
 	TimeoutMS          int64 `mapstructure:"timeout_ms"`
 	AllowUnknownBidder bool  `mapstructure:"allow_unknown_bidder"`
@@ -325,6 +894,81 @@ type VTrack struct {
 
 type Event struct {
 	TimeoutMS int64 `mapstructure:"timeout_ms"`
+	// ExternalURL is the host the event urls of the responses point to, such as an external event tracking host. The
+	// external_url of the server is used when empty.
+	ExternalURL string `mapstructure:"external_url"`
+	// WinNotifications fires the notification urls of the bids of some bidders on their win events
+	WinNotifications WinNotifications `mapstructure:"win_notifications"`
+}
+
+func (cfg *Event) validate(errs []error) []error {
+	errs = validateEventsURL("event.external_url", cfg.ExternalURL, errs)
+	return cfg.WinNotifications.validate(errs)
+}
+
+// WinNotifications fires the burl, or else the nurl, of the bids of the bidders server side when the win events of the
+// bids are received, for the bidders which can't rely on the notifications fired by the clients, like in apps. The urls
+// are removed from the responses, and kept in memory until the win events, so the win events have to reach the server
+// which ran the auction.
+type WinNotifications struct {
+	Enabled bool     `mapstructure:"enabled"`
+	Bidders []string `mapstructure:"bidders"`
+	// TTLSeconds is how long the urls of a bid are kept for its win event
+	TTLSeconds int `mapstructure:"ttl_seconds"`
+	// SizeBytes is the memory the urls are kept in, the oldest being dropped once it is full
+	SizeBytes int `mapstructure:"size_bytes"`
+	// TimeoutMs is the timeout of each attempt at firing a url
+	TimeoutMs int `mapstructure:"timeout_ms"`
+	// MaxRetries is the number of times a url is fired again, after RetryDelayMs, when a bidder fails to answer it
+	MaxRetries   int `mapstructure:"max_retries"`
+	RetryDelayMs int `mapstructure:"retry_delay_ms"`
+}
+
+func (cfg *WinNotifications) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if len(cfg.Bidders) == 0 {
+		errs = append(errs, errors.New("event.win_notifications.bidders must list at least one bidder"))
+	}
+	if cfg.TTLSeconds <= 0 {
+		errs = append(errs, fmt.Errorf("event.win_notifications.ttl_seconds must be > 0. Got %d", cfg.TTLSeconds))
+	}
+	if cfg.SizeBytes <= 0 {
+		errs = append(errs, fmt.Errorf("event.win_notifications.size_bytes must be > 0. Got %d", cfg.SizeBytes))
+	}
+	if cfg.TimeoutMs <= 0 {
+		errs = append(errs, fmt.Errorf("event.win_notifications.timeout_ms must be > 0. Got %d", cfg.TimeoutMs))
+	}
+	if cfg.MaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("event.win_notifications.max_retries must be >= 0. Got %d", cfg.MaxRetries))
+	}
+	if cfg.RetryDelayMs < 0 {
+		errs = append(errs, fmt.Errorf("event.win_notifications.retry_delay_ms must be >= 0. Got %d", cfg.RetryDelayMs))
+	}
+	return errs
+}
+
+// validateEventsURL checks that the host of the event urls is an absolute http or https url without a trailing slash
+func validateEventsURL(field string, eventsURL string, errs []error) []error {
+	if eventsURL == "" {
+		return errs
+	}
+	if u, err := url.Parse(eventsURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return append(errs, fmt.Errorf("%s must be an http or https url. Got %s", field, eventsURL))
+	}
+	if strings.HasSuffix(eventsURL, "/") {
+		return append(errs, fmt.Errorf("%s must not end with a path separator. Got %s", field, eventsURL))
+	}
+	return errs
+}
+
+// EventsURL returns the host of the event urls of the responses
+func (cfg *Configuration) EventsURL() string {
+	if cfg.Event.ExternalURL != "" {
+		return cfg.Event.ExternalURL
+	}
+	return cfg.ExternalURL
 }
 
 type HostCookie struct {
@@ -334,15 +978,62 @@ type HostCookie struct {
 	OptOutURL          string `mapstructure:"opt_out_url"`
 	OptInURL           string `mapstructure:"opt_in_url"`
 	MaxCookieSizeBytes int    `mapstructure:"max_cookie_size_bytes"`
-	OptOutCookie       Cookie `mapstructure:"optout_cookie"`
+	// MaxCookies is the number of cookies the uids are split across, named uids, uids2 and so on, once they exceed the
+	// MaxCookieSizeBytes of a single one. Both 0 and 1 keep them in the uids cookie.
+	MaxCookies int `mapstructure:"max_cookies"`
+	// MaxUIDs caps the number of uids kept across the cookies, with 0 for no cap
+	MaxUIDs int `mapstructure:"max_uids"`
+	// PrioritySyncers lists the keys of the syncers whose uids are stored first and evicted last, in order. The uids
+	// of the other syncers are evicted from the oldest sync.
+	PrioritySyncers []string `mapstructure:"priority_syncers"`
+	OptOutCookie    Cookie   `mapstructure:"optout_cookie"`
 	// Cookie timeout in days
-	TTL int64 `mapstructure:"ttl_days"`
+	TTL     int64             `mapstructure:"ttl_days"`
+	Signing HostCookieSigning `mapstructure:"signing"`
 }
 
 func (cfg *HostCookie) TTLDuration() time.Duration {
 	return time.Duration(cfg.TTL) * time.Hour * 24
 }
 
+func (cfg *HostCookie) validate(errs []error) []error {
+	if cfg.MaxCookies < 0 {
+		errs = append(errs, fmt.Errorf("host_cookie.max_cookies must be >= 0. Got %d", cfg.MaxCookies))
+	}
+	if cfg.MaxUIDs < 0 {
+		errs = append(errs, fmt.Errorf("host_cookie.max_uids must be >= 0. Got %d", cfg.MaxUIDs))
+	}
+	return cfg.Signing.validate(errs)
+}
+
+// HostCookieSigning configures the HMAC-SHA256 signature of the uids cookie, whose payload is also encrypted with
+// AES-256-GCM when Encrypt is set. The first of the Keys signs the cookies written, and all of them verify the cookies
+// read, so that a key is rotated by prepending its successor and dropping it once its cookies have expired. The
+// unsigned cookies of the legacy format are still read while AcceptLegacy is set, by default, so that turning the
+// signing on keeps the existing cookies. Once they have been rewritten signed, AcceptLegacy may be turned off to
+// reject them like the tampered ones.
+type HostCookieSigning struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	Keys         []string `mapstructure:"keys"`
+	Encrypt      bool     `mapstructure:"encrypt"`
+	AcceptLegacy bool     `mapstructure:"accept_legacy"`
+}
+
+func (cfg *HostCookieSigning) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if len(cfg.Keys) == 0 {
+		errs = append(errs, errors.New("host_cookie.signing.keys must not be empty when the signing is enabled"))
+	}
+	for i, key := range cfg.Keys {
+		if key == "" {
+			errs = append(errs, fmt.Errorf("host_cookie.signing.keys[%d] must not be empty", i))
+		}
+	}
+	return errs
+}
+
 type RequestTimeoutHeaders struct {
 	RequestTimeInQueue    string `mapstructure:"request_time_in_queue"`
 	RequestTimeoutInQueue string `mapstructure:"request_timeout_in_queue"`
@@ -351,6 +1042,7 @@ type RequestTimeoutHeaders struct {
 type Metrics struct {
 	Influxdb   InfluxMetrics     `mapstructure:"influxdb"`
 	Prometheus PrometheusMetrics `mapstructure:"prometheus"`
+	StatsD     StatsDMetrics     `mapstructure:"statsd"`
 	Disabled   DisabledMetrics   `mapstructure:"disabled_metrics"`
 }
 
@@ -365,10 +1057,15 @@ type DisabledMetrics struct {
 
 	// True if we don't want to collect the per adapter GDPR request blocked metric
 	AdapterGDPRRequestBlocked bool `mapstructure:"adapter_gdpr_request_blocked"`
+
+	// True if we don't want to collect the per alias request metrics. The alias names are set by the requests, so
+	// every new name adds a metric, and they are disabled by default.
+	AdapterAliasDetails bool `mapstructure:"adapter_alias_details"`
 }
 
 func (cfg *Metrics) validate(errs []error) []error {
-	return cfg.Prometheus.validate(errs)
+	errs = cfg.Prometheus.validate(errs)
+	return cfg.StatsD.validate(errs)
 }
 
 type InfluxMetrics struct {
@@ -379,17 +1076,69 @@ type InfluxMetrics struct {
 	MetricSendInterval int    `mapstructure:"metric_send_interval"`
 }
 
+// StatsDMetrics sends the metrics to the DogStatsD agent at Address, like the Datadog agent, when it is set. The
+// metric names start with Prefix, and the metrics get the Tags, as in "env:prod", on top of their own. They are sent
+// over UDP in packets of up to MaxPacketSize bytes, once full or every FlushIntervalMs. The metrics are queued up to
+// BufferSize and dropped past it.
+type StatsDMetrics struct {
+	Address         string   `mapstructure:"address"`
+	Prefix          string   `mapstructure:"prefix"`
+	Tags            []string `mapstructure:"tags"`
+	FlushIntervalMs int      `mapstructure:"flush_interval_ms"`
+	BufferSize      int      `mapstructure:"buffer_size"`
+	MaxPacketSize   int      `mapstructure:"max_packet_size"`
+}
+
+func (cfg *StatsDMetrics) validate(errs []error) []error {
+	if cfg.Address == "" {
+		return errs
+	}
+	if cfg.FlushIntervalMs <= 0 {
+		errs = append(errs, fmt.Errorf("metrics.statsd.flush_interval_ms must be > 0. Got %d", cfg.FlushIntervalMs))
+	}
+	if cfg.BufferSize <= 0 {
+		errs = append(errs, fmt.Errorf("metrics.statsd.buffer_size must be > 0. Got %d", cfg.BufferSize))
+	}
+	if cfg.MaxPacketSize <= 0 {
+		errs = append(errs, fmt.Errorf("metrics.statsd.max_packet_size must be > 0. Got %d", cfg.MaxPacketSize))
+	}
+	return errs
+}
+
 type PrometheusMetrics struct {
 	Port             int    `mapstructure:"port"`
 	Namespace        string `mapstructure:"namespace"`
 	Subsystem        string `mapstructure:"subsystem"`
 	TimeoutMillisRaw int    `mapstructure:"timeout_ms"`
+	// TimeBuckets are the bucket boundaries, in seconds, of the timing histograms, which have built-in boundaries up to
+	// 1 second if they aren't set. The adapter request timings use AdapterTimeBuckets instead if they are set, since the
+	// bidders usually answer slower than the other timed operations.
+	TimeBuckets        []float64 `mapstructure:"time_buckets"`
+	AdapterTimeBuckets []float64 `mapstructure:"adapter_time_buckets"`
+	// MaxAdapterAccounts caps the number of accounts the adapter request timings are also labeled with, so
+	// that hosts with many accounts don't blow up the registry. The accounts seen once the cap is reached are
+	// labeled "other". The timings aren't labeled by account at all when it is 0.
+	MaxAdapterAccounts int `mapstructure:"max_adapter_accounts"`
 }
 
 func (cfg *PrometheusMetrics) validate(errs []error) []error {
 	if cfg.Port > 0 && cfg.TimeoutMillisRaw <= 0 {
 		errs = append(errs, fmt.Errorf("metrics.prometheus.timeout_ms must be positive if metrics.prometheus.port is defined. Got timeout=%d and port=%d", cfg.TimeoutMillisRaw, cfg.Port))
 	}
+	errs = validateBuckets("metrics.prometheus.time_buckets", cfg.TimeBuckets, errs)
+	errs = validateBuckets("metrics.prometheus.adapter_time_buckets", cfg.AdapterTimeBuckets, errs)
+	if cfg.MaxAdapterAccounts < 0 {
+		errs = append(errs, fmt.Errorf("metrics.prometheus.max_adapter_accounts must be >= 0. Got %d", cfg.MaxAdapterAccounts))
+	}
+	return errs
+}
+
+func validateBuckets(name string, buckets []float64, errs []error) []error {
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			return append(errs, fmt.Errorf("%s must be in increasing order. Got %v", name, buckets))
+		}
+	}
 	return errs
 }
 
@@ -429,6 +1178,77 @@ type Cache struct {
 	ExpectedTimeMillis int `mapstructure:"expected_millis"`
 
 	DefaultTTLs DefaultTTLs `mapstructure:"default_ttl_seconds"`
+
+	// Embedded serves /cache from the memory of Prebid Server, in place of a Prebid Cache at the url above
+	Embedded EmbeddedCache `mapstructure:"embedded"`
+
+	// AsyncWrites stores the values in Prebid Cache after the auctions respond, in place of the auctions waiting for it
+	AsyncWrites AsyncCacheWrites `mapstructure:"async_writes"`
+}
+
+// EmbeddedCache configures the in-memory cache of Prebid Server, for the small hosts without a Prebid Cache. The
+// values are evicted once their TTL expires, or the least recently used ones once the cache is full.
+type EmbeddedCache struct {
+	Enabled   bool `mapstructure:"enabled"`
+	SizeBytes int  `mapstructure:"size_bytes"`
+	// DefaultTTLSeconds is the TTL of the values stored without one, and MaxTTLSeconds caps the TTLs when not 0
+	DefaultTTLSeconds int `mapstructure:"default_ttl_seconds"`
+	MaxTTLSeconds     int `mapstructure:"max_ttl_seconds"`
+	// AllowSettingKeys lets the writes to /cache choose the keys of their values, like the option of Prebid Cache.
+	// Otherwise their keys are ignored, and the values are stored under new uuids.
+	AllowSettingKeys bool `mapstructure:"allow_setting_keys"`
+	// PublicWrites serves the writes to /cache on the public port. Otherwise they are only served on the admin port,
+	// since /cache has no authentication, and the values are only read from the public port.
+	PublicWrites bool `mapstructure:"public_writes"`
+}
+
+func (cfg *EmbeddedCache) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.SizeBytes <= 0 {
+		errs = append(errs, fmt.Errorf("cache.embedded.size_bytes must be > 0. Got %d", cfg.SizeBytes))
+	}
+	if cfg.DefaultTTLSeconds <= 0 {
+		errs = append(errs, fmt.Errorf("cache.embedded.default_ttl_seconds must be > 0. Got %d", cfg.DefaultTTLSeconds))
+	}
+	if cfg.MaxTTLSeconds < 0 {
+		errs = append(errs, fmt.Errorf("cache.embedded.max_ttl_seconds must be >= 0. Got %d", cfg.MaxTTLSeconds))
+	} else if cfg.MaxTTLSeconds > 0 && cfg.MaxTTLSeconds < cfg.DefaultTTLSeconds {
+		errs = append(errs, fmt.Errorf("cache.embedded.max_ttl_seconds must be 0 or at least cache.embedded.default_ttl_seconds. Got %d", cfg.MaxTTLSeconds))
+	}
+	return errs
+}
+
+// AsyncCacheWrites configures the writes of the values to Prebid Cache in the background. Prebid Server generates the
+// keys of the values up front, so Prebid Cache must allow the keys to be set. The values are written in batches, once
+// a batch is full and at every flush interval for the values batched so far. The values are dropped when the buffer
+// is full. As the auctions don't wait for the writes, cache.expected_millis may be lowered.
+type AsyncCacheWrites struct {
+	Enabled         bool `mapstructure:"enabled"`
+	BatchSize       int  `mapstructure:"batch_size"`
+	FlushIntervalMs int  `mapstructure:"flush_interval_ms"`
+	TimeoutMs       int  `mapstructure:"timeout_ms"`
+	BufferSize      int  `mapstructure:"buffer_size"`
+}
+
+func (cfg *AsyncCacheWrites) validate(errs []error) []error {
+	if !cfg.Enabled {
+		return errs
+	}
+	if cfg.BatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("cache.async_writes.batch_size must be > 0. Got %d", cfg.BatchSize))
+	}
+	if cfg.FlushIntervalMs <= 0 {
+		errs = append(errs, fmt.Errorf("cache.async_writes.flush_interval_ms must be > 0. Got %d", cfg.FlushIntervalMs))
+	}
+	if cfg.TimeoutMs <= 0 {
+		errs = append(errs, fmt.Errorf("cache.async_writes.timeout_ms must be > 0. Got %d", cfg.TimeoutMs))
+	}
+	if cfg.BufferSize <= 0 {
+		errs = append(errs, fmt.Errorf("cache.async_writes.buffer_size must be > 0. Got %d", cfg.BufferSize))
+	}
+	return errs
 }
 
 // Default TTLs to use to cache bids for different types of imps.
@@ -516,21 +1336,14 @@ func New(v *viper.Viper) (*Configuration, error) {
 		c.GDPR.EEACountriesMap[v] = s
 	}
 
+	c.LGPD.CountriesMap = make(map[string]struct{}, len(c.LGPD.Countries))
+	for _, v := range c.LGPD.Countries {
+		c.LGPD.CountriesMap[strings.ToUpper(v)] = s
+	}
+
 	// To look for a purpose's vendor exceptions in O(1) time, for each purpose we fill this hash table located in the
 	// VendorExceptions field of the GDPR.TCF2.PurposeX struct defined in this file
-	purposeConfigs := []*TCF2Purpose{
-		&c.GDPR.TCF2.Purpose1,
-		&c.GDPR.TCF2.Purpose2,
-		&c.GDPR.TCF2.Purpose3,
-		&c.GDPR.TCF2.Purpose4,
-		&c.GDPR.TCF2.Purpose5,
-		&c.GDPR.TCF2.Purpose6,
-		&c.GDPR.TCF2.Purpose7,
-		&c.GDPR.TCF2.Purpose8,
-		&c.GDPR.TCF2.Purpose9,
-		&c.GDPR.TCF2.Purpose10,
-		&c.GDPR.TCF2.SpecialPurpose1,
-	}
+	purposeConfigs := append(c.GDPR.TCF2.purposeConfigs(), &c.GDPR.TCF2.SpecialPurpose1)
 	for c := 0; c < len(purposeConfigs); c++ {
 		purposeConfigs[c].VendorExceptionMap = make(map[openrtb_ext.BidderName]struct{})
 
@@ -622,6 +1435,17 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("cache.default_ttl_seconds.video", 0)
 	v.SetDefault("cache.default_ttl_seconds.native", 0)
 	v.SetDefault("cache.default_ttl_seconds.audio", 0)
+	v.SetDefault("cache.embedded.enabled", false)
+	v.SetDefault("cache.embedded.size_bytes", 64*1024*1024)
+	v.SetDefault("cache.embedded.default_ttl_seconds", 300)
+	v.SetDefault("cache.embedded.max_ttl_seconds", 3600)
+	v.SetDefault("cache.embedded.allow_setting_keys", false)
+	v.SetDefault("cache.embedded.public_writes", false)
+	v.SetDefault("cache.async_writes.enabled", false)
+	v.SetDefault("cache.async_writes.batch_size", 50)
+	v.SetDefault("cache.async_writes.flush_interval_ms", 50)
+	v.SetDefault("cache.async_writes.timeout_ms", 1000)
+	v.SetDefault("cache.async_writes.buffer_size", 10000)
 	v.SetDefault("external_cache.scheme", "")
 	v.SetDefault("external_cache.host", "")
 	v.SetDefault("external_cache.path", "")
@@ -635,6 +1459,13 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("host_cookie.value", "")
 	v.SetDefault("host_cookie.ttl_days", 90)
 	v.SetDefault("host_cookie.max_cookie_size_bytes", 0)
+	v.SetDefault("host_cookie.max_cookies", 1)
+	v.SetDefault("host_cookie.max_uids", 0)
+	v.SetDefault("host_cookie.priority_syncers", []string{})
+	v.SetDefault("host_cookie.signing.enabled", false)
+	v.SetDefault("host_cookie.signing.keys", []string{})
+	v.SetDefault("host_cookie.signing.encrypt", false)
+	v.SetDefault("host_cookie.signing.accept_legacy", true)
 	v.SetDefault("http_client.max_connections_per_host", 0) // unlimited
 	v.SetDefault("http_client.max_idle_connections", 400)
 	v.SetDefault("http_client.max_idle_connections_per_host", 10)
@@ -647,6 +1478,7 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("metrics.disabled_metrics.account_adapter_details", false)
 	v.SetDefault("metrics.disabled_metrics.adapter_connections_metrics", true)
 	v.SetDefault("metrics.disabled_metrics.adapter_gdpr_request_blocked", false)
+	v.SetDefault("metrics.disabled_metrics.adapter_alias_details", true)
 	v.SetDefault("metrics.influxdb.host", "")
 	v.SetDefault("metrics.influxdb.database", "")
 	v.SetDefault("metrics.influxdb.username", "")
@@ -656,6 +1488,15 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("metrics.prometheus.namespace", "")
 	v.SetDefault("metrics.prometheus.subsystem", "")
 	v.SetDefault("metrics.prometheus.timeout_ms", 10000)
+	v.SetDefault("metrics.prometheus.time_buckets", []float64{})
+	v.SetDefault("metrics.prometheus.adapter_time_buckets", []float64{})
+	v.SetDefault("metrics.prometheus.max_adapter_accounts", 0)
+	v.SetDefault("metrics.statsd.address", "")
+	v.SetDefault("metrics.statsd.prefix", "prebid.")
+	v.SetDefault("metrics.statsd.tags", []string{})
+	v.SetDefault("metrics.statsd.flush_interval_ms", 1000)
+	v.SetDefault("metrics.statsd.buffer_size", 10000)
+	v.SetDefault("metrics.statsd.max_packet_size", 1432)
 	v.SetDefault("datacache.type", "dummy")
 	v.SetDefault("datacache.filename", "")
 	v.SetDefault("datacache.cache_size", 0)
@@ -673,6 +1514,7 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("stored_requests.postgres.connection.password", "")
 	v.SetDefault("stored_requests.postgres.fetcher.query", "")
 	v.SetDefault("stored_requests.postgres.fetcher.amp_query", "")
+	v.SetDefault("stored_requests.postgres.fetcher.response_query", "")
 	v.SetDefault("stored_requests.postgres.initialize_caches.timeout_ms", 0)
 	v.SetDefault("stored_requests.postgres.initialize_caches.query", "")
 	v.SetDefault("stored_requests.postgres.initialize_caches.amp_query", "")
@@ -680,6 +1522,9 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("stored_requests.postgres.poll_for_updates.timeout_ms", 0)
 	v.SetDefault("stored_requests.postgres.poll_for_updates.query", "")
 	v.SetDefault("stored_requests.postgres.poll_for_updates.amp_query", "")
+	v.SetDefault("stored_requests.postgres.listen_for_updates.channel", "")
+	v.SetDefault("stored_requests.postgres.listen_for_updates.min_reconnect_interval_ms", 1000)
+	v.SetDefault("stored_requests.postgres.listen_for_updates.max_reconnect_interval_ms", 60000)
 	v.SetDefault("stored_requests.http.endpoint", "")
 	v.SetDefault("stored_requests.http.amp_endpoint", "")
 	v.SetDefault("stored_requests.in_memory_cache.type", "none")
@@ -691,6 +1536,23 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("stored_requests.http_events.amp_endpoint", "")
 	v.SetDefault("stored_requests.http_events.refresh_rate_seconds", 0)
 	v.SetDefault("stored_requests.http_events.timeout_ms", 0)
+	v.SetDefault("stored_requests.object_storage.provider", "s3")
+	v.SetDefault("stored_requests.object_storage.endpoint", "")
+	v.SetDefault("stored_requests.object_storage.region", "")
+	v.SetDefault("stored_requests.object_storage.bucket", "")
+	v.SetDefault("stored_requests.object_storage.prefix", "")
+	v.SetDefault("stored_requests.object_storage.amp_prefix", "")
+	v.SetDefault("stored_requests.object_storage.access_key_id", "")
+	v.SetDefault("stored_requests.object_storage.secret_access_key", "")
+	v.SetDefault("stored_requests.object_storage.refresh_rate_seconds", 0)
+	v.SetDefault("stored_requests.object_storage.timeout_ms", 1000)
+	v.SetDefault("stored_requests.redis.address", "")
+	v.SetDefault("stored_requests.redis.password", "")
+	v.SetDefault("stored_requests.redis.db", 0)
+	v.SetDefault("stored_requests.redis.key_prefix", "pbs:")
+	v.SetDefault("stored_requests.redis.ttl_seconds", 300)
+	v.SetDefault("stored_requests.redis.timeout_ms", 50)
+	v.SetDefault("stored_requests.redis.pool_size", 16)
 	// stored_video is short for stored_video_requests.
 	// PBS is not in the business of storing video content beyond the normal prebid cache system.
 	v.SetDefault("stored_video_req.filesystem.enabled", false)
@@ -706,6 +1568,9 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("stored_video_req.postgres.poll_for_updates.refresh_rate_seconds", 0)
 	v.SetDefault("stored_video_req.postgres.poll_for_updates.timeout_ms", 0)
 	v.SetDefault("stored_video_req.postgres.poll_for_updates.query", "")
+	v.SetDefault("stored_video_req.postgres.listen_for_updates.channel", "")
+	v.SetDefault("stored_video_req.postgres.listen_for_updates.min_reconnect_interval_ms", 1000)
+	v.SetDefault("stored_video_req.postgres.listen_for_updates.max_reconnect_interval_ms", 60000)
 	v.SetDefault("stored_video_req.http.endpoint", "")
 	v.SetDefault("stored_video_req.in_memory_cache.type", "none")
 	v.SetDefault("stored_video_req.in_memory_cache.ttl_seconds", 0)
@@ -716,20 +1581,62 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("stored_video_req.http_events.endpoint", "")
 	v.SetDefault("stored_video_req.http_events.refresh_rate_seconds", 0)
 	v.SetDefault("stored_video_req.http_events.timeout_ms", 0)
+	v.SetDefault("stored_video_req.object_storage.provider", "s3")
+	v.SetDefault("stored_video_req.object_storage.endpoint", "")
+	v.SetDefault("stored_video_req.object_storage.region", "")
+	v.SetDefault("stored_video_req.object_storage.bucket", "")
+	v.SetDefault("stored_video_req.object_storage.prefix", "")
+	v.SetDefault("stored_video_req.object_storage.access_key_id", "")
+	v.SetDefault("stored_video_req.object_storage.secret_access_key", "")
+	v.SetDefault("stored_video_req.object_storage.refresh_rate_seconds", 0)
+	v.SetDefault("stored_video_req.object_storage.timeout_ms", 1000)
+	v.SetDefault("stored_video_req.redis.address", "")
+	v.SetDefault("stored_video_req.redis.password", "")
+	v.SetDefault("stored_video_req.redis.db", 0)
+	v.SetDefault("stored_video_req.redis.key_prefix", "pbs:")
+	v.SetDefault("stored_video_req.redis.ttl_seconds", 300)
+	v.SetDefault("stored_video_req.redis.timeout_ms", 50)
+	v.SetDefault("stored_video_req.redis.pool_size", 16)
 
 	v.SetDefault("vtrack.timeout_ms", 2000)
 	v.SetDefault("vtrack.allow_unknown_bidder", true)
 	v.SetDefault("vtrack.enabled", true)
 
 	v.SetDefault("event.timeout_ms", 1000)
+	v.SetDefault("event.external_url", "")
+	v.SetDefault("event.win_notifications.enabled", false)
+	v.SetDefault("event.win_notifications.bidders", []string{})
+	v.SetDefault("event.win_notifications.ttl_seconds", 3600)
+	v.SetDefault("event.win_notifications.size_bytes", 10*1024*1024)
+	v.SetDefault("event.win_notifications.timeout_ms", 1000)
+	v.SetDefault("event.win_notifications.max_retries", 2)
+	v.SetDefault("event.win_notifications.retry_delay_ms", 500)
 
 	v.SetDefault("accounts.filesystem.enabled", false)
 	v.SetDefault("accounts.filesystem.directorypath", "./stored_requests/data/by_id")
 	v.SetDefault("accounts.in_memory_cache.type", "none")
+	v.SetDefault("accounts.object_storage.provider", "s3")
+	v.SetDefault("accounts.object_storage.endpoint", "")
+	v.SetDefault("accounts.object_storage.region", "")
+	v.SetDefault("accounts.object_storage.bucket", "")
+	v.SetDefault("accounts.object_storage.prefix", "")
+	v.SetDefault("accounts.object_storage.access_key_id", "")
+	v.SetDefault("accounts.object_storage.secret_access_key", "")
+	v.SetDefault("accounts.object_storage.refresh_rate_seconds", 0)
+	v.SetDefault("accounts.object_storage.timeout_ms", 1000)
+	v.SetDefault("accounts.redis.address", "")
+	v.SetDefault("accounts.redis.password", "")
+	v.SetDefault("accounts.redis.db", 0)
+	v.SetDefault("accounts.redis.key_prefix", "pbs:")
+	v.SetDefault("accounts.redis.ttl_seconds", 300)
+	v.SetDefault("accounts.redis.timeout_ms", 50)
+	v.SetDefault("accounts.redis.pool_size", 16)
 
 	// some adapters append the user id to the end of the redirect url instead of using
 	// macro substitution. it is important for the uid to be the last query parameter.
 	v.SetDefault("user_sync.redirect_url", "{{.ExternalURL}}/setuid?bidder={{.SyncerKey}}&gdpr={{.GDPR}}&gdpr_consent={{.GDPRConsent}}&f={{.SyncType}}&uid={{.UserMacro}}")
+	v.SetDefault("user_sync.default_limit", 0)
+	v.SetDefault("user_sync.max_limit", 0)
 
 	for _, bidder := range openrtb_ext.CoreBidderNames() {
 		setBidderDefaults(v, strings.ToLower(string(bidder)))
@@ -885,6 +1792,20 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("analytics.pubstack.buffers.size", "2MB")
 	v.SetDefault("analytics.pubstack.buffers.count", 100)
 	v.SetDefault("analytics.pubstack.buffers.timeout", "900s")
+	v.SetDefault("analytics.clickhouse.enabled", false)
+	v.SetDefault("analytics.clickhouse.endpoint", "")
+	v.SetDefault("analytics.clickhouse.database", "prebid")
+	v.SetDefault("analytics.clickhouse.username", "default")
+	v.SetDefault("analytics.clickhouse.password", "")
+	v.SetDefault("analytics.clickhouse.batch_size", 1000)
+	v.SetDefault("analytics.clickhouse.flush_interval_ms", 5000)
+	v.SetDefault("analytics.clickhouse.timeout_ms", 10000)
+	v.SetDefault("analytics.clickhouse.buffer_size", 10000)
+	v.SetDefault("analytics.request_log.enabled", false)
+	v.SetDefault("analytics.request_log.file", "")
+	v.SetDefault("analytics.request_log.sample_percent", 100)
+	v.SetDefault("analytics.request_log.fields", []string{})
+	v.SetDefault("analytics.request_log.flush_interval_ms", 1000)
 	v.SetDefault("amp_timeout_adjustment_ms", 0)
 	v.BindEnv("gdpr.default_value")
 	v.SetDefault("gdpr.enabled", true)
@@ -903,6 +1824,16 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("gdpr.tcf2.purpose8.enabled", true)
 	v.SetDefault("gdpr.tcf2.purpose9.enabled", true)
 	v.SetDefault("gdpr.tcf2.purpose10.enabled", true)
+	v.SetDefault("gdpr.tcf2.purpose1.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose2.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose3.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose4.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose5.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose6.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose7.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose8.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose9.enforce_purpose", TCF2FullEnforcement)
+	v.SetDefault("gdpr.tcf2.purpose10.enforce_purpose", TCF2FullEnforcement)
 	v.SetDefault("gdpr.tcf2.purpose1.enforce_vendors", true)
 	v.SetDefault("gdpr.tcf2.purpose2.enforce_vendors", true)
 	v.SetDefault("gdpr.tcf2.purpose3.enforce_vendors", true)
@@ -932,6 +1863,15 @@ func SetupViper(v *viper.Viper, filename string) {
 		"SVK", "SVN", "ESP", "SWE", "GBR"})
 	v.SetDefault("ccpa.enforce", false)
 	v.SetDefault("lmt.enforce", true)
+	v.SetDefault("lgpd.enforce", false)
+	v.SetDefault("lgpd.countries", []string{"BRA"})
+	v.SetDefault("gpp.enabled", true)
+	v.SetDefault("scrub_profiles.coppa.user", ScrubUserIDsAndDemographics)
+	v.SetDefault("scrub_profiles.coppa.device_ids", true)
+	v.SetDefault("scrub_profiles.coppa.geo", ScrubGeoFull)
+	v.SetDefault("scrub_profiles.lgpd.user", ScrubUserIDs)
+	v.SetDefault("scrub_profiles.lgpd.device_ids", true)
+	v.SetDefault("scrub_profiles.lgpd.geo", ScrubGeoReduced)
 	v.SetDefault("currency_converter.fetch_url", "https://cdn.jsdelivr.net/gh/prebid/currency-file@1/latest.json")
 	v.SetDefault("currency_converter.fetch_interval_seconds", 1800) // fetch currency rates every 30 minutes
 	v.SetDefault("currency_converter.stale_rates_seconds", 0)
@@ -941,12 +1881,75 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("blacklisted_apps", []string{""})
 	v.SetDefault("blacklisted_accts", []string{""})
 	v.SetDefault("account_required", false)
+	v.SetDefault("account_reload.enabled", false)
+	v.SetDefault("account_reload.refresh_rate_seconds", 60)
+	v.SetDefault("account_reload.timeout_ms", 1000)
+	v.SetDefault("account_reload.schema_file", "static/account-schema.json")
 	v.SetDefault("account_defaults.disabled", false)
 	v.SetDefault("account_defaults.debug_allow", true)
+	v.SetDefault("account_defaults.deals_only", false)
+	v.SetDefault("account_defaults.prefer_deals", false)
+	v.SetDefault("account_defaults.debug_token", "")
+	v.SetDefault("account_defaults.generate_tids", false)
+	v.SetDefault("account_defaults.price_floors.enforce_floors", false)
+	v.SetDefault("account_defaults.price_floors.fetch.enabled", false)
+	v.SetDefault("account_defaults.price_floors.fetch.url", "")
+	v.SetDefault("account_defaults.price_floors.fetch.timeout_ms", 3000)
+	v.SetDefault("account_defaults.price_floors.fetch.max_file_size_kb", 100)
+	v.SetDefault("account_defaults.price_floors.fetch.period_sec", 300)
+	v.SetDefault("account_defaults.price_floors.fetch.max_age_sec", 86400)
+	v.SetDefault("account_defaults.validations.banner_creative_size", ValidationSkip)
+	v.SetDefault("account_defaults.validations.secure_markup", ValidationSkip)
+	v.SetDefault("account_defaults.validations.native_assets", ValidationSkip)
+	v.SetDefault("account_defaults.validations.adm_presence", ValidationSkip)
+	v.SetDefault("account_defaults.traffic_shaping.enabled", false)
+	v.SetDefault("account_defaults.traffic_shaping.experiment", "")
+	v.SetDefault("account_defaults.bidder_capture.enabled", false)
+	v.SetDefault("account_defaults.bidder_capture.sample_percent", 0)
+	v.SetDefault("account_defaults.dooh.enabled", true)
 	v.SetDefault("certificates_file", "")
 	v.SetDefault("auto_gen_source_tid", true)
 	v.SetDefault("generate_bid_id", false)
+	v.SetDefault("allow_alias_endpoint_overrides", false)
 	v.SetDefault("generate_request_id", false)
+	v.SetDefault("auction_response_compression.enabled", false)
+	v.SetDefault("auction_response_compression.min_size_bytes", 1400)
+	v.SetDefault("tmax_adjustments.enabled", false)
+	v.SetDefault("tmax_adjustments.bidder_tmax_percent", 100)
+	v.SetDefault("tmax_adjustments.bidder_network_latency_buffer_ms", 0)
+	v.SetDefault("tmax_adjustments.pbs_response_preparation_duration_ms", 0)
+	v.SetDefault("tmax_adjustments.bidder_response_duration_min_ms", 0)
+	v.SetDefault("bidder_capture.enabled", false)
+	v.SetDefault("bidder_capture.sink", BidderCaptureSinkFile)
+	v.SetDefault("bidder_capture.filename", "")
+	v.SetDefault("bidder_capture.endpoint", "")
+	v.SetDefault("bidder_capture.timeout_ms", 1000)
+	v.SetDefault("bidder_capture.buffer_size", 1000)
+	v.SetDefault("first_party_data.site_attributes", []string{"name", "domain", "cat", "sectioncat", "pagecat", "page", "ref", "search", "keywords", "content", "ext"})
+	v.SetDefault("first_party_data.app_attributes", []string{"name", "bundle", "domain", "storeurl", "cat", "sectioncat", "pagecat", "ver", "keywords", "content", "ext"})
+	v.SetDefault("first_party_data.user_attributes", []string{"yob", "gender", "keywords", "data", "ext"})
+	v.SetDefault("first_party_data.conflict", FirstPartyDataConflictBidder)
+	v.SetDefault("geolocation.enabled", false)
+	v.SetDefault("geolocation.type", GeoLocationTypeMaxMind)
+	v.SetDefault("geolocation.maxmind.database_path", "")
+	v.SetDefault("geolocation.maxmind.refresh_interval_seconds", 86400)
+	v.SetDefault("geolocation.http.endpoint", "")
+	v.SetDefault("geolocation.http.timeout_ms", 20)
+	v.SetDefault("user_id.enabled", false)
+	v.SetDefault("user_id.cookie_name", "pbs_fpid")
+	v.SetDefault("user_id.ttl_days", 365)
+	v.SetDefault("user_id.signing_key", "")
+	v.SetDefault("user_id.source", "")
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.endpoint", "")
+	v.SetDefault("tracing.service_name", "prebid-server")
+	v.SetDefault("tracing.sample_percent", 1)
+	v.SetDefault("tracing.honor_caller_sampling", false)
+	v.SetDefault("tracing.propagation_headers", []string{"traceparent"})
+	v.SetDefault("tracing.batch_size", 512)
+	v.SetDefault("tracing.flush_interval_ms", 5000)
+	v.SetDefault("tracing.timeout_ms", 10000)
+	v.SetDefault("tracing.buffer_size", 2048)
 
 	v.SetDefault("request_timeout_headers.request_time_in_queue", "")
 	v.SetDefault("request_timeout_headers.request_timeout_in_queue", "")
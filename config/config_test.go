@@ -130,6 +130,13 @@ func TestDefaults(t *testing.T) {
 	cmpInts(t, "max_request_size", int(cfg.MaxRequestSize), 1024*256)
 	cmpInts(t, "host_cookie.ttl_days", int(cfg.HostCookie.TTL), 90)
 	cmpInts(t, "host_cookie.max_cookie_size_bytes", cfg.HostCookie.MaxCookieSizeBytes, 0)
+	cmpInts(t, "host_cookie.max_cookies", cfg.HostCookie.MaxCookies, 1)
+	cmpInts(t, "host_cookie.max_uids", cfg.HostCookie.MaxUIDs, 0)
+	cmpInts(t, "host_cookie.priority_syncers", len(cfg.HostCookie.PrioritySyncers), 0)
+	cmpBools(t, "host_cookie.signing.enabled", cfg.HostCookie.Signing.Enabled, false)
+	cmpInts(t, "host_cookie.signing.keys", len(cfg.HostCookie.Signing.Keys), 0)
+	cmpBools(t, "host_cookie.signing.encrypt", cfg.HostCookie.Signing.Encrypt, false)
+	cmpBools(t, "host_cookie.signing.accept_legacy", cfg.HostCookie.Signing.AcceptLegacy, true)
 	cmpStrings(t, "datacache.type", cfg.DataCache.Type, "dummy")
 	cmpStrings(t, "adapters.pubmatic.endpoint", cfg.Adapters[string(openrtb_ext.BidderPubmatic)].Endpoint, "https://hbopenbid.pubmatic.com/translator?source=prebid-server")
 	cmpInts(t, "currency_converter.fetch_interval_seconds", cfg.CurrencyConverter.FetchIntervalSeconds, 1800)
@@ -139,71 +146,171 @@ func TestDefaults(t *testing.T) {
 	cmpBools(t, "account_adapter_details", cfg.Metrics.Disabled.AccountAdapterDetails, false)
 	cmpBools(t, "adapter_connections_metrics", cfg.Metrics.Disabled.AdapterConnectionMetrics, true)
 	cmpBools(t, "adapter_gdpr_request_blocked", cfg.Metrics.Disabled.AdapterGDPRRequestBlocked, false)
+	cmpBools(t, "adapter_alias_details", cfg.Metrics.Disabled.AdapterAliasDetails, true)
+	assert.Empty(t, cfg.Metrics.Prometheus.TimeBuckets, "metrics.prometheus.time_buckets")
+	cmpInts(t, "metrics.prometheus.max_adapter_accounts", cfg.Metrics.Prometheus.MaxAdapterAccounts, 0)
+	cmpStrings(t, "metrics.statsd.address", cfg.Metrics.StatsD.Address, "")
+	cmpStrings(t, "metrics.statsd.prefix", cfg.Metrics.StatsD.Prefix, "prebid.")
+	cmpInts(t, "metrics.statsd.flush_interval_ms", cfg.Metrics.StatsD.FlushIntervalMs, 1000)
+	cmpInts(t, "metrics.statsd.buffer_size", cfg.Metrics.StatsD.BufferSize, 10000)
+	cmpInts(t, "metrics.statsd.max_packet_size", cfg.Metrics.StatsD.MaxPacketSize, 1432)
 	cmpStrings(t, "certificates_file", cfg.PemCertsFile, "")
 	cmpBools(t, "stored_requests.filesystem.enabled", false, cfg.StoredRequests.Files.Enabled)
 	cmpStrings(t, "stored_requests.filesystem.directorypath", "./stored_requests/data/by_id", cfg.StoredRequests.Files.Path)
+	cmpStrings(t, "stored_requests.object_storage.provider", "s3", cfg.StoredRequests.ObjectStorage.Provider)
+	cmpStrings(t, "stored_requests.object_storage.bucket", "", cfg.StoredRequests.ObjectStorage.Bucket)
+	cmpInts(t, "stored_requests.object_storage.timeout_ms", 1000, cfg.StoredRequests.ObjectStorage.Timeout)
+	cmpStrings(t, "accounts.object_storage.provider", "s3", cfg.Accounts.ObjectStorage.Provider)
+	cmpStrings(t, "stored_requests.redis.address", "", cfg.StoredRequests.Redis.Address)
+	cmpStrings(t, "stored_requests.redis.key_prefix", "pbs:", cfg.StoredRequests.Redis.KeyPrefix)
+	cmpInts(t, "stored_requests.redis.ttl_seconds", 300, cfg.StoredRequests.Redis.TTL)
+	cmpInts(t, "stored_requests.redis.timeout_ms", 50, cfg.StoredRequests.Redis.Timeout)
+	cmpInts(t, "stored_requests.redis.pool_size", 16, cfg.StoredRequests.Redis.PoolSize)
+	cmpInts(t, "accounts.redis.ttl_seconds", 300, cfg.Accounts.Redis.TTL)
+	cmpBools(t, "account_reload.enabled", false, cfg.AccountReload.Enabled)
+	cmpInts(t, "account_reload.refresh_rate_seconds", 60, cfg.AccountReload.RefreshRate)
+	cmpInts(t, "account_reload.timeout_ms", 1000, cfg.AccountReload.Timeout)
+	cmpStrings(t, "account_reload.schema_file", "static/account-schema.json", cfg.AccountReload.SchemaFile)
 	cmpBools(t, "auto_gen_source_tid", cfg.AutoGenSourceTID, true)
 	cmpBools(t, "generate_bid_id", cfg.GenerateBidID, false)
+	cmpBools(t, "allow_alias_endpoint_overrides", cfg.AllowAliasEndpointOverrides, false)
+	cmpBools(t, "auction_response_compression.enabled", cfg.AuctionResponseCompression.Enabled, false)
+	cmpInts(t, "auction_response_compression.min_size_bytes", cfg.AuctionResponseCompression.MinSizeBytes, 1400)
+	cmpBools(t, "tmax_adjustments.enabled", cfg.TmaxAdjustments.Enabled, false)
+	cmpInts(t, "tmax_adjustments.bidder_tmax_percent", cfg.TmaxAdjustments.BidderTmaxPercent, 100)
+	cmpBools(t, "bidder_capture.enabled", cfg.BidderCapture.Enabled, false)
+	cmpStrings(t, "bidder_capture.sink", cfg.BidderCapture.Sink, "file")
+	cmpInts(t, "bidder_capture.timeout_ms", cfg.BidderCapture.TimeoutMs, 1000)
+	cmpInts(t, "bidder_capture.buffer_size", cfg.BidderCapture.BufferSize, 1000)
+	cmpBools(t, "account_defaults.bidder_capture.enabled", cfg.AccountDefaults.BidderCapture.Enabled, false)
+	cmpBools(t, "account_defaults.dooh.enabled", cfg.AccountDefaults.DOOH.Enabled, true)
+	cmpBools(t, "cache.embedded.enabled", cfg.CacheURL.Embedded.Enabled, false)
+	cmpInts(t, "cache.embedded.size_bytes", cfg.CacheURL.Embedded.SizeBytes, 64*1024*1024)
+	cmpInts(t, "cache.embedded.default_ttl_seconds", cfg.CacheURL.Embedded.DefaultTTLSeconds, 300)
+	cmpInts(t, "cache.embedded.max_ttl_seconds", cfg.CacheURL.Embedded.MaxTTLSeconds, 3600)
+	cmpBools(t, "cache.embedded.allow_setting_keys", cfg.CacheURL.Embedded.AllowSettingKeys, false)
+	cmpBools(t, "cache.embedded.public_writes", cfg.CacheURL.Embedded.PublicWrites, false)
+	cmpBools(t, "cache.async_writes.enabled", cfg.CacheURL.AsyncWrites.Enabled, false)
+	cmpInts(t, "cache.async_writes.batch_size", cfg.CacheURL.AsyncWrites.BatchSize, 50)
+	cmpInts(t, "cache.async_writes.flush_interval_ms", cfg.CacheURL.AsyncWrites.FlushIntervalMs, 50)
+	cmpInts(t, "cache.async_writes.timeout_ms", cfg.CacheURL.AsyncWrites.TimeoutMs, 1000)
+	cmpInts(t, "cache.async_writes.buffer_size", cfg.CacheURL.AsyncWrites.BufferSize, 10000)
+	cmpBools(t, "geolocation.enabled", cfg.GeoLocation.Enabled, false)
+	cmpStrings(t, "geolocation.type", cfg.GeoLocation.Type, "maxmind")
+	cmpInts(t, "geolocation.maxmind.refresh_interval_seconds", cfg.GeoLocation.MaxMind.RefreshIntervalSeconds, 86400)
+	cmpInts(t, "geolocation.http.timeout_ms", cfg.GeoLocation.HTTP.TimeoutMs, 20)
+	cmpBools(t, "user_id.enabled", cfg.UserID.Enabled, false)
+	cmpStrings(t, "user_id.cookie_name", cfg.UserID.CookieName, "pbs_fpid")
+	cmpInts(t, "user_id.ttl_days", cfg.UserID.TTLDays, 365)
+	cmpBools(t, "tracing.enabled", cfg.Tracing.Enabled, false)
+	cmpStrings(t, "tracing.service_name", cfg.Tracing.ServiceName, "prebid-server")
+	assert.Equal(t, float64(1), cfg.Tracing.SamplePercent, "tracing.sample_percent")
+	cmpBools(t, "tracing.honor_caller_sampling", cfg.Tracing.HonorCallerSampling, false)
+	assert.Equal(t, []string{"traceparent"}, cfg.Tracing.PropagationHeaders, "tracing.propagation_headers")
+	cmpInts(t, "tracing.batch_size", cfg.Tracing.BatchSize, 512)
+	cmpInts(t, "tracing.flush_interval_ms", cfg.Tracing.FlushIntervalMs, 5000)
+	cmpInts(t, "tracing.timeout_ms", cfg.Tracing.TimeoutMs, 10000)
+	cmpInts(t, "tracing.buffer_size", cfg.Tracing.BufferSize, 2048)
+	cmpBools(t, "analytics.clickhouse.enabled", cfg.Analytics.ClickHouse.Enabled, false)
+	cmpStrings(t, "analytics.clickhouse.database", cfg.Analytics.ClickHouse.Database, "prebid")
+	cmpStrings(t, "analytics.clickhouse.username", cfg.Analytics.ClickHouse.Username, "default")
+	cmpInts(t, "analytics.clickhouse.batch_size", cfg.Analytics.ClickHouse.BatchSize, 1000)
+	cmpInts(t, "analytics.clickhouse.flush_interval_ms", cfg.Analytics.ClickHouse.FlushIntervalMs, 5000)
+	cmpInts(t, "analytics.clickhouse.timeout_ms", cfg.Analytics.ClickHouse.TimeoutMs, 10000)
+	cmpInts(t, "analytics.clickhouse.buffer_size", cfg.Analytics.ClickHouse.BufferSize, 10000)
+	cmpBools(t, "analytics.request_log.enabled", cfg.Analytics.RequestLog.Enabled, false)
+	assert.Equal(t, float64(100), cfg.Analytics.RequestLog.SamplePercent, "analytics.request_log.sample_percent")
+	assert.Empty(t, cfg.Analytics.RequestLog.Fields, "analytics.request_log.fields")
+	assert.Equal(t, 1000, cfg.Analytics.RequestLog.FlushIntervalMs, "analytics.request_log.flush_interval_ms")
+	cmpStrings(t, "event.external_url", cfg.Event.ExternalURL, "")
+	cmpBools(t, "event.win_notifications.enabled", cfg.Event.WinNotifications.Enabled, false)
+	assert.Empty(t, cfg.Event.WinNotifications.Bidders, "event.win_notifications.bidders")
+	cmpInts(t, "event.win_notifications.ttl_seconds", cfg.Event.WinNotifications.TTLSeconds, 3600)
+	cmpInts(t, "event.win_notifications.size_bytes", cfg.Event.WinNotifications.SizeBytes, 10*1024*1024)
+	cmpInts(t, "event.win_notifications.timeout_ms", cfg.Event.WinNotifications.TimeoutMs, 1000)
+	cmpInts(t, "event.win_notifications.max_retries", cfg.Event.WinNotifications.MaxRetries, 2)
+	cmpInts(t, "event.win_notifications.retry_delay_ms", cfg.Event.WinNotifications.RetryDelayMs, 500)
+	cmpInts(t, "user_sync.default_limit", cfg.UserSync.DefaultLimit, 0)
+	cmpInts(t, "user_sync.max_limit", cfg.UserSync.MaxLimit, 0)
+	cmpStrings(t, "first_party_data.conflict", cfg.FirstPartyData.Conflict, "bidder")
+	assert.Equal(t, []string{"yob", "gender", "keywords", "data", "ext"}, cfg.FirstPartyData.UserAttributes, "first_party_data.user_attributes")
+	cmpStrings(t, "account_defaults.validations.secure_markup", string(cfg.AccountDefaults.Validations.SecureMarkup), "skip")
+	cmpStrings(t, "account_defaults.validations.native_assets", string(cfg.AccountDefaults.Validations.NativeAssets), "skip")
+	cmpBools(t, "lgpd.enforce", cfg.LGPD.Enforce, false)
+	cmpBools(t, "gpp.enabled", cfg.GPP.Enabled, true)
+	assert.Equal(t, map[string]struct{}{"BRA": {}}, cfg.LGPD.CountriesMap, "lgpd.countries")
+	assert.Equal(t, ScrubProfile{User: "ids_and_demographics", DeviceIDs: true, Geo: "full"}, cfg.ScrubProfiles.COPPA, "scrub_profiles.coppa")
+	assert.Equal(t, ScrubProfile{User: "ids", DeviceIDs: true, Geo: "reduced"}, cfg.ScrubProfiles.LGPD, "scrub_profiles.lgpd")
 
 	//Assert purpose VendorExceptionMap hash tables were built correctly
 	expectedTCF2 := TCF2{
 		Enabled: true,
 		Purpose1: TCF2Purpose{
 			Enabled:            true,
+			EnforcePurpose:     "full",
 			EnforceVendors:     true,
 			VendorExceptions:   []openrtb_ext.BidderName{},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{},
 		},
 		Purpose2: TCF2Purpose{
 			Enabled:            true,
+			EnforcePurpose:     "full",
 			EnforceVendors:     true,
 			VendorExceptions:   []openrtb_ext.BidderName{},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{},
 		},
 		Purpose3: TCF2Purpose{
 			Enabled:            true,
+			EnforcePurpose:     "full",
 			EnforceVendors:     true,
 			VendorExceptions:   []openrtb_ext.BidderName{},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{},
 		},
 		Purpose4: TCF2Purpose{
 			Enabled:            true,
+			EnforcePurpose:     "full",
 			EnforceVendors:     true,
 			VendorExceptions:   []openrtb_ext.BidderName{},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{},
 		},
 		Purpose5: TCF2Purpose{
 			Enabled:            true,
+			EnforcePurpose:     "full",
 			EnforceVendors:     true,
 			VendorExceptions:   []openrtb_ext.BidderName{},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{},
 		},
 		Purpose6: TCF2Purpose{
 			Enabled:            true,
+			EnforcePurpose:     "full",
 			EnforceVendors:     true,
 			VendorExceptions:   []openrtb_ext.BidderName{},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{},
 		},
 		Purpose7: TCF2Purpose{
 			Enabled:            true,
+			EnforcePurpose:     "full",
 			EnforceVendors:     true,
 			VendorExceptions:   []openrtb_ext.BidderName{},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{},
 		},
 		Purpose8: TCF2Purpose{
 			Enabled:            true,
+			EnforcePurpose:     "full",
 			EnforceVendors:     true,
 			VendorExceptions:   []openrtb_ext.BidderName{},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{},
 		},
 		Purpose9: TCF2Purpose{
 			Enabled:            true,
+			EnforcePurpose:     "full",
 			EnforceVendors:     true,
 			VendorExceptions:   []openrtb_ext.BidderName{},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{},
 		},
 		Purpose10: TCF2Purpose{
 			Enabled:            true,
+			EnforcePurpose:     "full",
 			EnforceVendors:     true,
 			VendorExceptions:   []openrtb_ext.BidderName{},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{},
@@ -239,9 +346,11 @@ gdpr:
       enforce_vendors: false
       vendor_exceptions: ["foo2"]
     purpose3:
+      enforce_purpose: basic
       enforce_vendors: false
       vendor_exceptions: ["foo3"]
     purpose4:
+      enforce_purpose: "no"
       enforce_vendors: false
       vendor_exceptions: ["foo4"]
     purpose5:
@@ -268,6 +377,8 @@ ccpa:
   enforce: true
 lmt:
   enforce: true
+gpp:
+  enabled: false
 host_cookie:
   cookie_name: userid
   family: prebid
@@ -275,6 +386,14 @@ host_cookie:
   opt_out_url: http://prebid.org/optout
   opt_in_url: http://prebid.org/optin
   max_cookie_size_bytes: 32768
+  max_cookies: 3
+  max_uids: 40
+  priority_syncers: ["adnxs", "rubicon"]
+  signing:
+    enabled: true
+    keys: ["key2", "key1"]
+    encrypt: true
+    accept_legacy: false
 external_url: http://prebid-server.prebid.org/
 host: prebid-server.prebid.org
 port: 1234
@@ -303,6 +422,10 @@ http_client_cache:
 currency_converter:
   fetch_url: https://currency.prebid.org
   fetch_interval_seconds: 1800
+  fallback_sources:
+    - url: https://currency-backup.prebid.org
+      stale_rates_seconds: 86400
+    - file: static/currency/rates.json
 recaptcha_secret: asdfasdfasdfasdf
 metrics:
   influxdb:
@@ -311,10 +434,18 @@ metrics:
     username: admin
     password: admin1324
     metric_send_interval: 30
+  prometheus:
+    time_buckets: [0.01, 0.1, 1]
+    adapter_time_buckets: [0.1, 0.5, 1, 2]
+    max_adapter_accounts: 50
+  statsd:
+    address: localhost:8125
+    tags: ["env:prod"]
   disabled_metrics:
     account_adapter_details: true
     adapter_connections_metrics: true
     adapter_gdpr_request_blocked: true
+    adapter_alias_details: false
 datacache:
   type: postgres
   filename: /usr/db/db.db
@@ -353,6 +484,40 @@ request_validation:
     ipv4_private_networks: ["1.1.1.0/24"]
     ipv6_private_networks: ["1111::/16", "2222::/16"]
 generate_bid_id: true
+allow_alias_endpoint_overrides: true
+auction_response_compression:
+    enabled: true
+    min_size_bytes: 2048
+tmax_adjustments:
+    enabled: true
+    bidder_tmax_percent: 90
+    bidder_network_latency_buffer_ms: 20
+    pbs_response_preparation_duration_ms: 50
+    bidder_response_duration_min_ms: 100
+bidder_capture:
+    enabled: true
+    sink: http
+    endpoint: http://capture.prebid.org
+    timeout_ms: 500
+    buffer_size: 200
+first_party_data:
+    site_attributes: ["page", "ext"]
+    conflict: request
+geolocation:
+    enabled: true
+    type: http
+    http:
+        endpoint: http://geo.prebid.org/lookup
+        timeout_ms: 10
+user_id:
+    enabled: true
+    cookie_name: fpid
+    ttl_days: 30
+    signing_key: secret
+    source: prebid.org
+user_sync:
+    default_limit: 4
+    max_limit: 8
 `)
 
 var adapterExtraInfoConfig = []byte(`
@@ -417,6 +582,13 @@ func TestFullConfig(t *testing.T) {
 	cmpStrings(t, "cookie family", cfg.HostCookie.Family, "prebid")
 	cmpStrings(t, "opt out", cfg.HostCookie.OptOutURL, "http://prebid.org/optout")
 	cmpStrings(t, "opt in", cfg.HostCookie.OptInURL, "http://prebid.org/optin")
+	cmpInts(t, "host_cookie.max_cookies", cfg.HostCookie.MaxCookies, 3)
+	cmpInts(t, "host_cookie.max_uids", cfg.HostCookie.MaxUIDs, 40)
+	assert.Equal(t, []string{"adnxs", "rubicon"}, cfg.HostCookie.PrioritySyncers, "host_cookie.priority_syncers")
+	cmpBools(t, "host_cookie.signing.enabled", cfg.HostCookie.Signing.Enabled, true)
+	assert.Equal(t, []string{"key2", "key1"}, cfg.HostCookie.Signing.Keys, "host_cookie.signing.keys")
+	cmpBools(t, "host_cookie.signing.encrypt", cfg.HostCookie.Signing.Encrypt, true)
+	cmpBools(t, "host_cookie.signing.accept_legacy", cfg.HostCookie.Signing.AcceptLegacy, false)
 	cmpStrings(t, "external url", cfg.ExternalURL, "http://prebid-server.prebid.org/")
 	cmpStrings(t, "host", cfg.Host, "prebid-server.prebid.org")
 	cmpInts(t, "port", cfg.Port, 1234)
@@ -450,6 +622,7 @@ func TestFullConfig(t *testing.T) {
 
 	cmpBools(t, "ccpa.enforce", cfg.CCPA.Enforce, true)
 	cmpBools(t, "lmt.enforce", cfg.LMT.Enforce, true)
+	cmpBools(t, "gpp.enabled", cfg.GPP.Enabled, false)
 
 	//Assert the NonStandardPublishers was correctly unmarshalled
 	cmpStrings(t, "blacklisted_apps", cfg.BlacklistedApps[0], "spamAppID")
@@ -465,60 +638,70 @@ func TestFullConfig(t *testing.T) {
 		Enabled: true,
 		Purpose1: TCF2Purpose{
 			Enabled:            true, // true by default
+			EnforcePurpose:     "full",
 			EnforceVendors:     false,
 			VendorExceptions:   []openrtb_ext.BidderName{openrtb_ext.BidderName("foo1a"), openrtb_ext.BidderName("foo1b")},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{openrtb_ext.BidderName("foo1a"): {}, openrtb_ext.BidderName("foo1b"): {}},
 		},
 		Purpose2: TCF2Purpose{
 			Enabled:            false,
+			EnforcePurpose:     "full",
 			EnforceVendors:     false,
 			VendorExceptions:   []openrtb_ext.BidderName{openrtb_ext.BidderName("foo2")},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{openrtb_ext.BidderName("foo2"): {}},
 		},
 		Purpose3: TCF2Purpose{
 			Enabled:            true, // true by default
+			EnforcePurpose:     "basic",
 			EnforceVendors:     false,
 			VendorExceptions:   []openrtb_ext.BidderName{openrtb_ext.BidderName("foo3")},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{openrtb_ext.BidderName("foo3"): {}},
 		},
 		Purpose4: TCF2Purpose{
 			Enabled:            true, // true by default
+			EnforcePurpose:     "no",
 			EnforceVendors:     false,
 			VendorExceptions:   []openrtb_ext.BidderName{openrtb_ext.BidderName("foo4")},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{openrtb_ext.BidderName("foo4"): {}},
 		},
 		Purpose5: TCF2Purpose{
 			Enabled:            true, // true by default
+			EnforcePurpose:     "full",
 			EnforceVendors:     false,
 			VendorExceptions:   []openrtb_ext.BidderName{openrtb_ext.BidderName("foo5")},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{openrtb_ext.BidderName("foo5"): {}},
 		},
 		Purpose6: TCF2Purpose{
 			Enabled:            true, // true by default
+			EnforcePurpose:     "full",
 			EnforceVendors:     false,
 			VendorExceptions:   []openrtb_ext.BidderName{openrtb_ext.BidderName("foo6")},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{openrtb_ext.BidderName("foo6"): {}},
 		},
 		Purpose7: TCF2Purpose{
 			Enabled:            true, // true by default
+			EnforcePurpose:     "full",
 			EnforceVendors:     false,
 			VendorExceptions:   []openrtb_ext.BidderName{openrtb_ext.BidderName("foo7")},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{openrtb_ext.BidderName("foo7"): {}},
 		},
 		Purpose8: TCF2Purpose{
 			Enabled:            true, // true by default
+			EnforcePurpose:     "full",
 			EnforceVendors:     false,
 			VendorExceptions:   []openrtb_ext.BidderName{openrtb_ext.BidderName("foo8")},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{openrtb_ext.BidderName("foo8"): {}},
 		},
 		Purpose9: TCF2Purpose{
 			Enabled:            true, // true by default
+			EnforcePurpose:     "full",
 			EnforceVendors:     false,
 			VendorExceptions:   []openrtb_ext.BidderName{openrtb_ext.BidderName("foo9")},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{openrtb_ext.BidderName("foo9"): {}},
 		},
 		Purpose10: TCF2Purpose{
 			Enabled:            true, // true by default
+			EnforcePurpose:     "full",
 			EnforceVendors:     false,
 			VendorExceptions:   []openrtb_ext.BidderName{openrtb_ext.BidderName("foo10")},
 			VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{openrtb_ext.BidderName("foo10"): {}},
@@ -537,12 +720,21 @@ func TestFullConfig(t *testing.T) {
 
 	cmpStrings(t, "currency_converter.fetch_url", cfg.CurrencyConverter.FetchURL, "https://currency.prebid.org")
 	cmpInts(t, "currency_converter.fetch_interval_seconds", cfg.CurrencyConverter.FetchIntervalSeconds, 1800)
+	assert.Equal(t, []CurrencyRatesSource{
+		{URL: "https://currency-backup.prebid.org", StaleRatesSeconds: 86400},
+		{File: "static/currency/rates.json"},
+	}, cfg.CurrencyConverter.FallbackSources, "currency_converter.fallback_sources")
 	cmpStrings(t, "recaptcha_secret", cfg.RecaptchaSecret, "asdfasdfasdfasdf")
 	cmpStrings(t, "metrics.influxdb.host", cfg.Metrics.Influxdb.Host, "upstream:8232")
 	cmpStrings(t, "metrics.influxdb.database", cfg.Metrics.Influxdb.Database, "metricsdb")
 	cmpStrings(t, "metrics.influxdb.username", cfg.Metrics.Influxdb.Username, "admin")
 	cmpStrings(t, "metrics.influxdb.password", cfg.Metrics.Influxdb.Password, "admin1324")
 	cmpInts(t, "metrics.influxdb.metric_send_interval", cfg.Metrics.Influxdb.MetricSendInterval, 30)
+	assert.Equal(t, []float64{0.01, 0.1, 1}, cfg.Metrics.Prometheus.TimeBuckets, "metrics.prometheus.time_buckets")
+	assert.Equal(t, []float64{0.1, 0.5, 1, 2}, cfg.Metrics.Prometheus.AdapterTimeBuckets, "metrics.prometheus.adapter_time_buckets")
+	cmpInts(t, "metrics.prometheus.max_adapter_accounts", cfg.Metrics.Prometheus.MaxAdapterAccounts, 50)
+	cmpStrings(t, "metrics.statsd.address", cfg.Metrics.StatsD.Address, "localhost:8125")
+	assert.Equal(t, []string{"env:prod"}, cfg.Metrics.StatsD.Tags, "metrics.statsd.tags")
 	cmpStrings(t, "datacache.type", cfg.DataCache.Type, "postgres")
 	cmpStrings(t, "datacache.filename", cfg.DataCache.Filename, "/usr/db/db.db")
 	cmpInts(t, "datacache.cache_size", cfg.DataCache.CacheSize, 10000000)
@@ -571,11 +763,38 @@ func TestFullConfig(t *testing.T) {
 	cmpBools(t, "account_adapter_details", cfg.Metrics.Disabled.AccountAdapterDetails, true)
 	cmpBools(t, "adapter_connections_metrics", cfg.Metrics.Disabled.AdapterConnectionMetrics, true)
 	cmpBools(t, "adapter_gdpr_request_blocked", cfg.Metrics.Disabled.AdapterGDPRRequestBlocked, true)
+	cmpBools(t, "adapter_alias_details", cfg.Metrics.Disabled.AdapterAliasDetails, false)
 	cmpStrings(t, "certificates_file", cfg.PemCertsFile, "/etc/ssl/cert.pem")
 	cmpStrings(t, "request_validation.ipv4_private_networks", cfg.RequestValidation.IPv4PrivateNetworks[0], "1.1.1.0/24")
 	cmpStrings(t, "request_validation.ipv6_private_networks", cfg.RequestValidation.IPv6PrivateNetworks[0], "1111::/16")
 	cmpStrings(t, "request_validation.ipv6_private_networks", cfg.RequestValidation.IPv6PrivateNetworks[1], "2222::/16")
 	cmpBools(t, "generate_bid_id", cfg.GenerateBidID, true)
+	cmpBools(t, "allow_alias_endpoint_overrides", cfg.AllowAliasEndpointOverrides, true)
+	cmpBools(t, "auction_response_compression.enabled", cfg.AuctionResponseCompression.Enabled, true)
+	cmpInts(t, "auction_response_compression.min_size_bytes", cfg.AuctionResponseCompression.MinSizeBytes, 2048)
+	cmpBools(t, "tmax_adjustments.enabled", cfg.TmaxAdjustments.Enabled, true)
+	cmpInts(t, "tmax_adjustments.bidder_tmax_percent", cfg.TmaxAdjustments.BidderTmaxPercent, 90)
+	cmpInts(t, "tmax_adjustments.bidder_network_latency_buffer_ms", cfg.TmaxAdjustments.BidderNetworkLatencyBufferMs, 20)
+	cmpInts(t, "tmax_adjustments.pbs_response_preparation_duration_ms", cfg.TmaxAdjustments.PBSResponsePreparationDurationMs, 50)
+	cmpInts(t, "tmax_adjustments.bidder_response_duration_min_ms", cfg.TmaxAdjustments.BidderResponseDurationMinMs, 100)
+	cmpBools(t, "bidder_capture.enabled", cfg.BidderCapture.Enabled, true)
+	cmpStrings(t, "bidder_capture.sink", cfg.BidderCapture.Sink, "http")
+	cmpStrings(t, "bidder_capture.endpoint", cfg.BidderCapture.Endpoint, "http://capture.prebid.org")
+	cmpInts(t, "bidder_capture.timeout_ms", cfg.BidderCapture.TimeoutMs, 500)
+	cmpInts(t, "bidder_capture.buffer_size", cfg.BidderCapture.BufferSize, 200)
+	cmpStrings(t, "first_party_data.conflict", cfg.FirstPartyData.Conflict, "request")
+	assert.Equal(t, []string{"page", "ext"}, cfg.FirstPartyData.SiteAttributes, "first_party_data.site_attributes")
+	cmpBools(t, "geolocation.enabled", cfg.GeoLocation.Enabled, true)
+	cmpStrings(t, "geolocation.type", cfg.GeoLocation.Type, "http")
+	cmpStrings(t, "geolocation.http.endpoint", cfg.GeoLocation.HTTP.Endpoint, "http://geo.prebid.org/lookup")
+	cmpInts(t, "geolocation.http.timeout_ms", cfg.GeoLocation.HTTP.TimeoutMs, 10)
+	cmpBools(t, "user_id.enabled", cfg.UserID.Enabled, true)
+	cmpStrings(t, "user_id.cookie_name", cfg.UserID.CookieName, "fpid")
+	cmpInts(t, "user_id.ttl_days", cfg.UserID.TTLDays, 30)
+	cmpStrings(t, "user_id.signing_key", cfg.UserID.SigningKey, "secret")
+	cmpStrings(t, "user_id.source", cfg.UserID.Source, "prebid.org")
+	cmpInts(t, "user_sync.default_limit", cfg.UserSync.DefaultLimit, 4)
+	cmpInts(t, "user_sync.max_limit", cfg.UserSync.MaxLimit, 8)
 	cmpStrings(t, "debug.override_token", cfg.Debug.OverrideToken, "")
 }
 
@@ -633,6 +852,7 @@ func TestValidateConfig(t *testing.T) {
 			Files:         FileFetcherConfig{Enabled: true},
 			InMemoryCache: InMemoryCache{Type: "none"},
 		},
+		FirstPartyData: FirstPartyData{Conflict: FirstPartyDataConflictBidder},
 	}
 
 	v := viper.New()
@@ -754,12 +974,939 @@ func TestInvalidAdapterEndpointConfig(t *testing.T) {
 	}
 }
 
+func TestValidateAdapterRetry(t *testing.T) {
+	testCases := []struct {
+		description  string
+		retry        AdapterRetry
+		expectedErrs []error
+	}{
+		{
+			description:  "Disabled",
+			retry:        AdapterRetry{Enabled: false, StatusCodes: []int{404}, BackoffMs: -1},
+			expectedErrs: nil,
+		},
+		{
+			description:  "Valid",
+			retry:        AdapterRetry{Enabled: true, StatusCodes: []int{502, 503}, BackoffMs: 10},
+			expectedErrs: nil,
+		},
+		{
+			description:  "No status codes",
+			retry:        AdapterRetry{Enabled: true, BackoffMs: 10},
+			expectedErrs: []error{errors.New("adapters.appnexus.retry.status_codes must not be empty when retries are enabled")},
+		},
+		{
+			description:  "Non 5xx status code",
+			retry:        AdapterRetry{Enabled: true, StatusCodes: []int{503, 404}, BackoffMs: 10},
+			expectedErrs: []error{errors.New("adapters.appnexus.retry.status_codes contains 404, only 5xx status codes can be retried")},
+		},
+		{
+			description:  "Negative backoff",
+			retry:        AdapterRetry{Enabled: true, StatusCodes: []int{503}, BackoffMs: -1},
+			expectedErrs: []error{errors.New("adapters.appnexus.retry.backoff_ms must not be negative. Got -1")},
+		},
+		{
+			description: "Connection error retries only",
+			retry:       AdapterRetry{ConnectionErrors: true, BackoffMs: 10},
+		},
+		{
+			description:  "Connection error retries with a negative backoff",
+			retry:        AdapterRetry{ConnectionErrors: true, BackoffMs: -1},
+			expectedErrs: []error{errors.New("adapters.appnexus.retry.backoff_ms must not be negative. Got -1")},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := validateAdapterRetry(test.retry, "appnexus", nil)
+		assert.Equal(t, test.expectedErrs, errs, test.description)
+	}
+}
+
+func TestValidateAdapterCircuitBreaker(t *testing.T) {
+	testCases := []struct {
+		description  string
+		breaker      AdapterCircuitBreaker
+		expectedErrs []error
+	}{
+		{
+			description:  "Disabled",
+			breaker:      AdapterCircuitBreaker{Enabled: false},
+			expectedErrs: nil,
+		},
+		{
+			description:  "Valid",
+			breaker:      AdapterCircuitBreaker{Enabled: true, WindowSize: 20, FailureRatePercent: 50, CooldownMs: 30000},
+			expectedErrs: nil,
+		},
+		{
+			description: "Invalid",
+			breaker:     AdapterCircuitBreaker{Enabled: true, WindowSize: 0, FailureRatePercent: 101, CooldownMs: -1},
+			expectedErrs: []error{
+				errors.New("adapters.appnexus.circuit_breaker.window_size must be positive. Got 0"),
+				errors.New("adapters.appnexus.circuit_breaker.failure_rate_percent must be between 1 and 100. Got 101"),
+				errors.New("adapters.appnexus.circuit_breaker.cooldown_ms must be positive. Got -1"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := validateAdapterCircuitBreaker(test.breaker, "appnexus", nil)
+		assert.Equal(t, test.expectedErrs, errs, test.description)
+	}
+}
+
+func TestGenericORTBConfig(t *testing.T) {
+	v := viper.New()
+	SetupViper(v, "")
+	v.Set("gdpr.default_value", "0")
+	v.SetConfigType("yaml")
+	v.ReadConfig(bytes.NewBuffer([]byte(`
+adapters:
+  somegenericbidder:
+    endpoint: "http://{{.Host}}/bid?zone={{.ZoneID}}"
+    generic_ortb:
+      gvl_vendor_id: 42
+      media_types: ["banner", "video"]
+      endpoint_macros:
+        host: host
+        zone_id: zoneId
+      field_mappings:
+        - param: tagId
+          field: imp.tagid
+`)))
+	cfg, err := New(v)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &GenericORTB{
+		GVLVendorID:    42,
+		MediaTypes:     []openrtb_ext.BidType{openrtb_ext.BidTypeBanner, openrtb_ext.BidTypeVideo},
+		EndpointMacros: GenericORTBMacros{Host: "host", ZoneID: "zoneId"},
+		FieldMappings:  []GenericORTBFieldMapping{{Param: "tagId", Field: GenericORTBFieldImpTagID}},
+	}, cfg.Adapters["somegenericbidder"].GenericORTB)
+	assert.Nil(t, cfg.Adapters["appnexus"].GenericORTB)
+}
+
+func TestValidateGenericORTB(t *testing.T) {
+	testCases := []struct {
+		description  string
+		bidder       string
+		genericORTB  *GenericORTB
+		expectedErrs []error
+	}{
+		{
+			description:  "Not set",
+			bidder:       "appnexus",
+			expectedErrs: nil,
+		},
+		{
+			description: "Valid",
+			bidder:      "somegenericbidder",
+			genericORTB: &GenericORTB{
+				MediaTypes:    []openrtb_ext.BidType{openrtb_ext.BidTypeBanner},
+				FieldMappings: []GenericORTBFieldMapping{{Param: "tagId", Field: GenericORTBFieldImpTagID}},
+			},
+			expectedErrs: nil,
+		},
+		{
+			description: "Core bidder",
+			bidder:      "appnexus",
+			genericORTB: &GenericORTB{MediaTypes: []openrtb_ext.BidType{openrtb_ext.BidTypeBanner}},
+			expectedErrs: []error{
+				errors.New("adapters.appnexus.generic_ortb can't be set, appnexus is the name of a core or reserved bidder"),
+			},
+		},
+		{
+			description: "Invalid media types and field mappings",
+			bidder:      "somegenericbidder",
+			genericORTB: &GenericORTB{
+				MediaTypes:    []openrtb_ext.BidType{"banner", "other"},
+				FieldMappings: []GenericORTBFieldMapping{{Field: GenericORTBFieldSiteID}, {Param: "tagId", Field: "imp.id"}},
+			},
+			expectedErrs: []error{
+				errors.New("adapters.somegenericbidder.generic_ortb.media_types contains other, which isn't a media type"),
+				errors.New("adapters.somegenericbidder.generic_ortb.field_mappings must set the param copied into site.id"),
+				errors.New("adapters.somegenericbidder.generic_ortb.field_mappings can't copy params into imp.id"),
+			},
+		},
+		{
+			description: "No media types",
+			bidder:      "somegenericbidder",
+			genericORTB: &GenericORTB{},
+			expectedErrs: []error{
+				errors.New("adapters.somegenericbidder.generic_ortb.media_types must not be empty"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := validateGenericORTB(test.genericORTB, test.bidder, nil)
+		assert.Equal(t, test.expectedErrs, errs, test.description)
+	}
+}
+
 func TestNegativeRequestSize(t *testing.T) {
 	cfg, v := newDefaultConfig(t)
 	cfg.MaxRequestSize = -1
 	assertOneError(t, cfg.validate(v), "cfg.max_request_size must be >= 0. Got -1")
 }
 
+func TestNegativeResponseCompressionMinSize(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.AuctionResponseCompression.MinSizeBytes = -1
+	assertOneError(t, cfg.validate(v), "auction_response_compression.min_size_bytes must be >= 0. Got -1")
+}
+
+func TestInvalidTmaxAdjustmentsPercent(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.TmaxAdjustments.Enabled = true
+	cfg.TmaxAdjustments.BidderTmaxPercent = 0
+	assertOneError(t, cfg.validate(v), "tmax_adjustments.bidder_tmax_percent must be between 1 and 100. Got 0")
+}
+
+func TestNegativeTmaxAdjustmentsBuffer(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.TmaxAdjustments.Enabled = true
+	cfg.TmaxAdjustments.BidderNetworkLatencyBufferMs = -1
+	assertOneError(t, cfg.validate(v), "tmax_adjustments.bidder_network_latency_buffer_ms must be >= 0. Got -1")
+}
+
+func TestInvalidAccountValidationMode(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.AccountDefaults.Validations.AdmPresence = "drop"
+	assertOneError(t, cfg.validate(v), "account_defaults.validations.adm_presence must be one of skip, warn or enforce. Got drop")
+}
+
+func TestInvalidAccountTrafficShapingPercent(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.AccountDefaults.TrafficShaping.Bidders = map[string]float64{"appnexus": 50, "rubicon": 150}
+	assertOneError(t, cfg.validate(v), "account_defaults.traffic_shaping.bidders.rubicon must be a percentage between 0 and 100. Got 150")
+}
+
+func TestInvalidAccountBidderCapturePercent(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.AccountDefaults.BidderCapture.Bidders = map[string]float64{"appnexus": 50, "rubicon": -1}
+	assertOneError(t, cfg.validate(v), "account_defaults.bidder_capture.bidders.rubicon must be a percentage between 0 and 100. Got -1")
+}
+
+func TestInvalidAccountReloadRefreshRate(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.AccountReload.Enabled = true
+	cfg.AccountReload.RefreshRate = 0
+	assertOneError(t, cfg.validate(v), "account_reload.refresh_rate_seconds must be positive. Got 0")
+}
+
+func TestInvalidAccountReloadCache(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.AccountReload.Enabled = true
+	cfg.Accounts.InMemoryCache = InMemoryCache{Type: "lru", Size: 1000}
+	assertOneError(t, cfg.validate(v), "account_reload requires accounts.in_memory_cache.type to be none. Got lru")
+}
+
+func TestInvalidRedisTTL(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.StoredRequests.Redis.Address = "localhost:6379"
+	cfg.StoredRequests.Redis.TTL = 0
+	assertOneError(t, cfg.validate(v), "stored_requests.redis.ttl_seconds must be positive. Got 0")
+}
+
+func TestInvalidRedisCategories(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.CategoryMapping.Redis.Address = "localhost:6379"
+	assertOneError(t, cfg.validate(v), "categories.redis: caching categories in redis not available")
+}
+
+func TestInvalidAccountPrivacyComponentType(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.AccountDefaults.Privacy.AllowActivities.FetchBids.Rules = []ActivityRule{
+		{Condition: ActivityCondition{ComponentType: []string{ComponentTypeBidder}}},
+		{Condition: ActivityCondition{ComponentType: []string{"adapter"}}},
+	}
+	assertOneError(t, cfg.validate(v), "account_defaults.privacy.allowactivities.fetchBids.rules[1].condition.componentType must be one of bidder, analytics, rtd or general. Got adapter")
+}
+
+func TestInvalidAccountPrivacyGPC(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.AccountDefaults.Privacy.AllowActivities.TransmitEids.Rules = []ActivityRule{{Condition: ActivityCondition{GPC: "true"}}}
+	assertOneError(t, cfg.validate(v), "account_defaults.privacy.allowactivities.transmitEids.rules[0].condition.gpc must be either 0 or 1. Got true")
+}
+
+func TestInvalidAccountAnalyticsPrivacy(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.AccountDefaults.Analytics.Privacy.User = "all"
+	cfg.AccountDefaults.Analytics.Privacy.Geo = "precise"
+
+	errs := cfg.validate(v)
+	assert.Equal(t, []error{
+		errors.New("account_defaults.analytics.privacy.user must be one of none, eids, ids or ids_and_demographics. Got all"),
+		errors.New("account_defaults.analytics.privacy.geo must be one of none, reduced or full. Got precise"),
+	}, errs)
+}
+
+func TestInvalidTCF2EnforcePurpose(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.GDPR.TCF2.Purpose3.EnforcePurpose = "strict"
+	assertOneError(t, cfg.validate(v), "gdpr.tcf2.purpose3.enforce_purpose must be full, basic or no. Got strict")
+}
+
+func TestInvalidAccountGDPREnforcePurpose(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	enforcePurpose := "yes"
+	cfg.AccountDefaults.GDPR.Purpose7.EnforcePurpose = &enforcePurpose
+	assertOneError(t, cfg.validate(v), "account_defaults.gdpr.purpose7.enforce_purpose must be full, basic or no. Got yes")
+}
+
+func TestInvalidScrubProfiles(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.ScrubProfiles.COPPA.User = "all"
+	cfg.ScrubProfiles.LGPD.Geo = "precise"
+
+	errs := cfg.validate(v)
+	assert.Equal(t, []error{
+		errors.New("scrub_profiles.coppa.user must be one of none, eids, ids or ids_and_demographics. Got all"),
+		errors.New("scrub_profiles.lgpd.geo must be one of none, reduced or full. Got precise"),
+	}, errs)
+}
+
+func TestValidateBidderCapture(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenConfig    BidderCapture
+		expectedErrors []error
+	}{
+		{
+			description: "Disabled",
+			givenConfig: BidderCapture{Sink: "s3"},
+		},
+		{
+			description: "Valid file sink",
+			givenConfig: BidderCapture{Enabled: true, Sink: BidderCaptureSinkFile, Filename: "capture.log", BufferSize: 10},
+		},
+		{
+			description: "File sink without filename",
+			givenConfig: BidderCapture{Enabled: true, Sink: BidderCaptureSinkFile, BufferSize: 10},
+			expectedErrors: []error{
+				errors.New("bidder_capture.filename is required with the file sink"),
+			},
+		},
+		{
+			description: "Http sink without endpoint and timeout",
+			givenConfig: BidderCapture{Enabled: true, Sink: BidderCaptureSinkHTTP, BufferSize: 10},
+			expectedErrors: []error{
+				errors.New("bidder_capture.endpoint is required with the http sink"),
+				errors.New("bidder_capture.timeout_ms must be > 0. Got 0"),
+			},
+		},
+		{
+			description: "Unknown sink without buffer",
+			givenConfig: BidderCapture{Enabled: true, Sink: "s3"},
+			expectedErrors: []error{
+				errors.New(`bidder_capture.sink must be either file or http. Got "s3"`),
+				errors.New("bidder_capture.buffer_size must be > 0. Got 0"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.givenConfig.validate(nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestValidatePreferredMediaType(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenConfig    Account
+		expectedErrors []error
+	}{
+		{
+			description: "None",
+			givenConfig: Account{},
+		},
+		{
+			description: "Valid",
+			givenConfig: Account{PreferredMediaType: map[string]openrtb_ext.BidType{"appnexus": openrtb_ext.BidTypeVideo, "rubicon": openrtb_ext.BidTypeBanner}},
+		},
+		{
+			description: "Invalid",
+			givenConfig: Account{PreferredMediaType: map[string]openrtb_ext.BidType{"appnexus": "display", "rubicon": openrtb_ext.BidTypeBanner, "ix": ""}},
+			expectedErrors: []error{
+				errors.New("account_defaults.preferred_media_type.appnexus must be one of banner, video, audio or native. Got display"),
+				errors.New("account_defaults.preferred_media_type.ix must be one of banner, video, audio or native. Got "),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.givenConfig.validatePreferredMediaType("account_defaults.", nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestValidateAccountTargeting(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenConfig    AccountTargeting
+		expectedErrors []error
+	}{
+		{
+			description: "None",
+			givenConfig: AccountTargeting{},
+		},
+		{
+			description: "Valid",
+			givenConfig: AccountTargeting{Prefix: "pbs_1", MaxKeyLength: 10},
+		},
+		{
+			description: "Invalid",
+			givenConfig: AccountTargeting{Prefix: "pbs-1", MaxKeyLength: 9},
+			expectedErrors: []error{
+				errors.New("account_defaults.targeting.prefix must only have letters, digits and underscores. Got pbs-1"),
+				errors.New("account_defaults.targeting.max_key_length must be 0 or at least 10. Got 9"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.givenConfig.validate("account_defaults.", nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestValidateAccountCache(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenConfig    AccountCache
+		expectedErrors []error
+	}{
+		{
+			description: "None",
+			givenConfig: AccountCache{},
+		},
+		{
+			description: "Valid",
+			givenConfig: AccountCache{MaxTTL: DefaultTTLs{Banner: 300, Video: 1800}},
+		},
+		{
+			description: "Invalid",
+			givenConfig: AccountCache{MaxTTL: DefaultTTLs{Video: -1, Audio: -2}},
+			expectedErrors: []error{
+				errors.New("account_defaults.cache.max_ttl.video must be >= 0. Got -1"),
+				errors.New("account_defaults.cache.max_ttl.audio must be >= 0. Got -2"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.givenConfig.validate("account_defaults.", nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestValidateEmbeddedCache(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenConfig    EmbeddedCache
+		expectedErrors []error
+	}{
+		{
+			description: "Disabled",
+			givenConfig: EmbeddedCache{SizeBytes: -1},
+		},
+		{
+			description: "Valid",
+			givenConfig: EmbeddedCache{Enabled: true, SizeBytes: 1024 * 1024, DefaultTTLSeconds: 300, MaxTTLSeconds: 3600},
+		},
+		{
+			description: "Valid without max TTL",
+			givenConfig: EmbeddedCache{Enabled: true, SizeBytes: 1024 * 1024, DefaultTTLSeconds: 300},
+		},
+		{
+			description: "Invalid size and TTLs",
+			givenConfig: EmbeddedCache{Enabled: true, MaxTTLSeconds: -1},
+			expectedErrors: []error{
+				errors.New("cache.embedded.size_bytes must be > 0. Got 0"),
+				errors.New("cache.embedded.default_ttl_seconds must be > 0. Got 0"),
+				errors.New("cache.embedded.max_ttl_seconds must be >= 0. Got -1"),
+			},
+		},
+		{
+			description: "Max TTL below the default TTL",
+			givenConfig: EmbeddedCache{Enabled: true, SizeBytes: 1024 * 1024, DefaultTTLSeconds: 300, MaxTTLSeconds: 60},
+			expectedErrors: []error{
+				errors.New("cache.embedded.max_ttl_seconds must be 0 or at least cache.embedded.default_ttl_seconds. Got 60"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.givenConfig.validate(nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestValidateAsyncCacheWrites(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenConfig    AsyncCacheWrites
+		expectedErrors []error
+	}{
+		{
+			description: "Disabled",
+			givenConfig: AsyncCacheWrites{BatchSize: -1},
+		},
+		{
+			description: "Valid",
+			givenConfig: AsyncCacheWrites{Enabled: true, BatchSize: 50, FlushIntervalMs: 50, TimeoutMs: 1000, BufferSize: 10000},
+		},
+		{
+			description: "Invalid",
+			givenConfig: AsyncCacheWrites{Enabled: true, BatchSize: -1},
+			expectedErrors: []error{
+				errors.New("cache.async_writes.batch_size must be > 0. Got -1"),
+				errors.New("cache.async_writes.flush_interval_ms must be > 0. Got 0"),
+				errors.New("cache.async_writes.timeout_ms must be > 0. Got 0"),
+				errors.New("cache.async_writes.buffer_size must be > 0. Got 0"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.givenConfig.validate(nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestValidateGeoLocation(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenConfig    GeoLocation
+		expectedErrors []error
+	}{
+		{
+			description: "Disabled",
+			givenConfig: GeoLocation{Type: "ip2location"},
+		},
+		{
+			description: "Valid maxmind type",
+			givenConfig: GeoLocation{Enabled: true, Type: GeoLocationTypeMaxMind, MaxMind: GeoLocationMaxMind{DatabasePath: "GeoLite2-City.mmdb"}},
+		},
+		{
+			description: "Maxmind type without database path",
+			givenConfig: GeoLocation{Enabled: true, Type: GeoLocationTypeMaxMind, MaxMind: GeoLocationMaxMind{RefreshIntervalSeconds: -1}},
+			expectedErrors: []error{
+				errors.New("geolocation.maxmind.database_path is required with the maxmind type"),
+				errors.New("geolocation.maxmind.refresh_interval_seconds must be >= 0. Got -1"),
+			},
+		},
+		{
+			description: "Http type without endpoint and timeout",
+			givenConfig: GeoLocation{Enabled: true, Type: GeoLocationTypeHTTP},
+			expectedErrors: []error{
+				errors.New("geolocation.http.endpoint is required with the http type"),
+				errors.New("geolocation.http.timeout_ms must be > 0. Got 0"),
+			},
+		},
+		{
+			description: "Unknown type",
+			givenConfig: GeoLocation{Enabled: true, Type: "ip2location"},
+			expectedErrors: []error{
+				errors.New(`geolocation.type must be either maxmind or http. Got "ip2location"`),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.givenConfig.validate(nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestValidateUserID(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenConfig    UserID
+		expectedErrors []error
+	}{
+		{
+			description: "Disabled",
+			givenConfig: UserID{},
+		},
+		{
+			description: "Valid",
+			givenConfig: UserID{Enabled: true, CookieName: "fpid", TTLDays: 1, SigningKey: "secret", Source: "prebid.org"},
+		},
+		{
+			description: "Empty",
+			givenConfig: UserID{Enabled: true},
+			expectedErrors: []error{
+				errors.New("user_id.cookie_name is required"),
+				errors.New("user_id.ttl_days must be > 0. Got 0"),
+				errors.New("user_id.signing_key is required"),
+				errors.New("user_id.source is required"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.givenConfig.validate(nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestValidateTracing(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenConfig    Tracing
+		expectedErrors []error
+	}{
+		{
+			description: "Disabled",
+			givenConfig: Tracing{},
+		},
+		{
+			description: "Valid",
+			givenConfig: Tracing{Enabled: true, Endpoint: "http://collector:4318/v1/traces", SamplePercent: 100, BatchSize: 1, FlushIntervalMs: 1, TimeoutMs: 1, BufferSize: 1},
+		},
+		{
+			description: "Empty",
+			givenConfig: Tracing{Enabled: true, SamplePercent: 101},
+			expectedErrors: []error{
+				errors.New("tracing.endpoint is required"),
+				errors.New("tracing.sample_percent must be between 0 and 100. Got 101"),
+				errors.New("tracing.batch_size must be > 0. Got 0"),
+				errors.New("tracing.flush_interval_ms must be > 0. Got 0"),
+				errors.New("tracing.timeout_ms must be > 0. Got 0"),
+				errors.New("tracing.buffer_size must be > 0. Got 0"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.givenConfig.validate(nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestValidateClickHouseAnalytics(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenConfig    ClickHouseAnalytics
+		expectedErrors []error
+	}{
+		{
+			description: "Disabled",
+			givenConfig: ClickHouseAnalytics{},
+		},
+		{
+			description: "Valid",
+			givenConfig: ClickHouseAnalytics{Enabled: true, Endpoint: "http://clickhouse:8123", Database: "prebid", BatchSize: 1, FlushIntervalMs: 1, TimeoutMs: 1, BufferSize: 1},
+		},
+		{
+			description: "Empty",
+			givenConfig: ClickHouseAnalytics{Enabled: true},
+			expectedErrors: []error{
+				errors.New("analytics.clickhouse.endpoint is required"),
+				errors.New("analytics.clickhouse.database is required"),
+				errors.New("analytics.clickhouse.batch_size must be > 0. Got 0"),
+				errors.New("analytics.clickhouse.flush_interval_ms must be > 0. Got 0"),
+				errors.New("analytics.clickhouse.timeout_ms must be > 0. Got 0"),
+				errors.New("analytics.clickhouse.buffer_size must be > 0. Got 0"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.givenConfig.validate(nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestValidateRequestLog(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenConfig    RequestLog
+		expectedErrors []error
+	}{
+		{
+			description: "Disabled",
+			givenConfig: RequestLog{SamplePercent: -1},
+		},
+		{
+			description: "Valid",
+			givenConfig: RequestLog{Enabled: true, SamplePercent: 10, Fields: []string{"account", "nbr"}, FlushIntervalMs: 1000},
+		},
+		{
+			description: "Invalid",
+			givenConfig: RequestLog{Enabled: true, SamplePercent: 101, Fields: []string{"account", "ip"}},
+			expectedErrors: []error{
+				errors.New("analytics.request_log.sample_percent must be between 0 and 100. Got 101"),
+				errors.New("analytics.request_log.flush_interval_ms must be > 0. Got 0"),
+				errors.New("analytics.request_log.fields must be among account, bidders, tmax, nbr, response_time, bid_counts, errors. Got ip"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.givenConfig.validate(nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestRequestLogLogsAllErrors(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenConfig    RequestLog
+		expectedResult bool
+	}{
+		{
+			description:    "Disabled",
+			givenConfig:    RequestLog{SamplePercent: 100},
+			expectedResult: false,
+		},
+		{
+			description:    "Sampled",
+			givenConfig:    RequestLog{Enabled: true, SamplePercent: 50},
+			expectedResult: false,
+		},
+		{
+			description:    "All the fields",
+			givenConfig:    RequestLog{Enabled: true, SamplePercent: 100},
+			expectedResult: true,
+		},
+		{
+			description:    "Errors selected",
+			givenConfig:    RequestLog{Enabled: true, SamplePercent: 100, Fields: []string{"account", "errors"}},
+			expectedResult: true,
+		},
+		{
+			description:    "Errors not selected",
+			givenConfig:    RequestLog{Enabled: true, SamplePercent: 100, Fields: []string{"account"}},
+			expectedResult: false,
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedResult, test.givenConfig.LogsAllErrors(), test.description)
+	}
+}
+
+func TestValidateAnalyticsRequestOptions(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenConfig    Analytics
+		expectedErrors []error
+	}{
+		{
+			description: "None",
+			givenConfig: Analytics{},
+		},
+		{
+			description: "Valid",
+			givenConfig: Analytics{RequestOptions: map[string][]string{"custom": {"sample"}}},
+		},
+		{
+			description: "Reserved",
+			givenConfig: Analytics{RequestOptions: map[string][]string{"pubstack": {"scopeid"}}},
+			expectedErrors: []error{
+				errors.New("analytics.request_options.pubstack cannot include scopeid, which only the accounts may set"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.givenConfig.validate(nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestAnalyticsRequestOverridable(t *testing.T) {
+	cfg := Analytics{RequestOptions: map[string][]string{"custom": {"sample", "endpoint"}}}
+
+	assert.True(t, cfg.RequestOverridable("custom", "sample"))
+	assert.False(t, cfg.RequestOverridable("custom", "other"), "the options left out aren't overridable")
+	assert.False(t, cfg.RequestOverridable("custom", "endpoint"), "the reserved options aren't overridable")
+	assert.False(t, cfg.RequestOverridable("pubstack", "sample"), "the options of the other modules aren't overridable")
+}
+
+func TestValidateEvent(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenConfig    Event
+		expectedErrors []error
+	}{
+		{
+			description: "No external url",
+			givenConfig: Event{},
+		},
+		{
+			description: "Valid",
+			givenConfig: Event{ExternalURL: "https://events.publisher.com/pbs"},
+		},
+		{
+			description: "Relative",
+			givenConfig: Event{ExternalURL: "events.publisher.com"},
+			expectedErrors: []error{
+				errors.New("event.external_url must be an http or https url. Got events.publisher.com"),
+			},
+		},
+		{
+			description: "Trailing slash",
+			givenConfig: Event{ExternalURL: "https://events.publisher.com/"},
+			expectedErrors: []error{
+				errors.New("event.external_url must not end with a path separator. Got https://events.publisher.com/"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.givenConfig.validate(nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestValidateWinNotifications(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenConfig    WinNotifications
+		expectedErrors []error
+	}{
+		{
+			description: "Disabled",
+			givenConfig: WinNotifications{},
+		},
+		{
+			description: "Valid",
+			givenConfig: WinNotifications{Enabled: true, Bidders: []string{"appnexus"}, TTLSeconds: 1, SizeBytes: 1, TimeoutMs: 1},
+		},
+		{
+			description: "Invalid",
+			givenConfig: WinNotifications{Enabled: true, MaxRetries: -1, RetryDelayMs: -1},
+			expectedErrors: []error{
+				errors.New("event.win_notifications.bidders must list at least one bidder"),
+				errors.New("event.win_notifications.ttl_seconds must be > 0. Got 0"),
+				errors.New("event.win_notifications.size_bytes must be > 0. Got 0"),
+				errors.New("event.win_notifications.timeout_ms must be > 0. Got 0"),
+				errors.New("event.win_notifications.max_retries must be >= 0. Got -1"),
+				errors.New("event.win_notifications.retry_delay_ms must be >= 0. Got -1"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		errs := test.givenConfig.validate(nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
+func TestInvalidAccountEventsExternalURL(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.AccountDefaults.EventsExternalURL = "ftp://events.publisher.com"
+	assertOneError(t, cfg.validate(v), "account_defaults.events_external_url must be an http or https url. Got ftp://events.publisher.com")
+}
+
+func TestEventsURL(t *testing.T) {
+	cfg := &Configuration{ExternalURL: "http://prebid.publisher.com"}
+	assert.Equal(t, "http://prebid.publisher.com", cfg.EventsURL(), "server")
+	cfg.Event.ExternalURL = "http://events.publisher.com"
+	assert.Equal(t, "http://events.publisher.com", cfg.EventsURL(), "event.external_url")
+
+	account := &Account{}
+	assert.Equal(t, "http://events.publisher.com", account.EventsURL(cfg.EventsURL()), "account without events_external_url")
+	account.EventsExternalURL = "http://account.events.com"
+	assert.Equal(t, "http://account.events.com", account.EventsURL(cfg.EventsURL()), "account events_external_url")
+}
+
+func TestInvalidUserSyncLimits(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.UserSync.DefaultLimit = -1
+	maxLimit := -2
+	cfg.AccountDefaults.CookieSync.MaxLimit = &maxLimit
+
+	errs := cfg.validate(v)
+	assert.Equal(t, []error{
+		errors.New("user_sync.default_limit must be >= 0. Got -1"),
+		errors.New("account_defaults.cookie_sync.max_limit must be >= 0. Got -2"),
+	}, errs)
+}
+
+func TestInvalidHostCookieSharding(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.HostCookie.MaxCookies = -1
+	cfg.HostCookie.MaxUIDs = -1
+
+	errs := cfg.validate(v)
+	assert.Equal(t, []error{
+		errors.New("host_cookie.max_cookies must be >= 0. Got -1"),
+		errors.New("host_cookie.max_uids must be >= 0. Got -1"),
+	}, errs)
+}
+
+func TestInvalidHostCookieSigning(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenSigning   HostCookieSigning
+		expectedErrors []error
+	}{
+		{
+			description:  "Disabled",
+			givenSigning: HostCookieSigning{},
+		},
+		{
+			description:  "Valid",
+			givenSigning: HostCookieSigning{Enabled: true, Keys: []string{"key2", "key1"}},
+		},
+		{
+			description:    "No keys",
+			givenSigning:   HostCookieSigning{Enabled: true},
+			expectedErrors: []error{errors.New("host_cookie.signing.keys must not be empty when the signing is enabled")},
+		},
+		{
+			description:    "Empty key",
+			givenSigning:   HostCookieSigning{Enabled: true, Keys: []string{"key2", ""}},
+			expectedErrors: []error{errors.New("host_cookie.signing.keys[1] must not be empty")},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedErrors, test.givenSigning.validate(nil), test.description)
+	}
+}
+
+func TestInvalidFirstPartyDataConflict(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.FirstPartyData.Conflict = "global"
+	assertOneError(t, cfg.validate(v), `first_party_data.conflict must be either bidder or request. Got "global"`)
+}
+
+func TestInvalidPrometheusBuckets(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.Metrics.Prometheus.TimeBuckets = []float64{0.1, 0.5, 0.5}
+	cfg.Metrics.Prometheus.AdapterTimeBuckets = []float64{1, 0.5}
+	cfg.Metrics.Prometheus.MaxAdapterAccounts = -1
+
+	errs := cfg.validate(v)
+	assert.Equal(t, []error{
+		errors.New("metrics.prometheus.time_buckets must be in increasing order. Got [0.1 0.5 0.5]"),
+		errors.New("metrics.prometheus.adapter_time_buckets must be in increasing order. Got [1 0.5]"),
+		errors.New("metrics.prometheus.max_adapter_accounts must be >= 0. Got -1"),
+	}, errs)
+}
+
+func TestInvalidStatsD(t *testing.T) {
+	cfg, v := newDefaultConfig(t)
+	cfg.Metrics.StatsD.Address = "localhost:8125"
+	cfg.Metrics.StatsD.FlushIntervalMs = 0
+	cfg.Metrics.StatsD.BufferSize = -1
+	cfg.Metrics.StatsD.MaxPacketSize = 0
+
+	errs := cfg.validate(v)
+	assert.Equal(t, []error{
+		errors.New("metrics.statsd.flush_interval_ms must be > 0. Got 0"),
+		errors.New("metrics.statsd.buffer_size must be > 0. Got -1"),
+		errors.New("metrics.statsd.max_packet_size must be > 0. Got 0"),
+	}, errs)
+}
+
 func TestNegativePrometheusTimeout(t *testing.T) {
 	cfg, v := newDefaultConfig(t)
 	cfg.Metrics.Prometheus.Port = 8001
@@ -840,6 +1987,40 @@ func TestOverflowedCurrencyConverterFetchInterval(t *testing.T) {
 	assert.NotNil(t, err, "cfg.currency_converter.fetch_interval_seconds prevent values over %d, but it doesn't", 0xffff)
 }
 
+func TestValidateCurrencyConverterFallbackSources(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenSources   []CurrencyRatesSource
+		expectedErrors []error
+	}{
+		{
+			description: "Valid",
+			givenSources: []CurrencyRatesSource{
+				{URL: "https://currency.prebid.org/latest.json", StaleRatesSeconds: 86400},
+				{File: "static/currency/rates.json"},
+			},
+		},
+		{
+			description: "Invalid",
+			givenSources: []CurrencyRatesSource{
+				{StaleRatesSeconds: -1},
+				{URL: "https://currency.prebid.org/latest.json", File: "static/currency/rates.json"},
+			},
+			expectedErrors: []error{
+				errors.New("currency_converter.fallback_sources[0] must set exactly one of url and file"),
+				errors.New("currency_converter.fallback_sources[0].stale_rates_seconds must be >= 0. Got -1"),
+				errors.New("currency_converter.fallback_sources[1] must set exactly one of url and file"),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		cfg := CurrencyConverter{FallbackSources: test.givenSources}
+		errs := cfg.validate(nil)
+		assert.Equal(t, test.expectedErrors, errs, test.description)
+	}
+}
+
 func TestLimitTimeout(t *testing.T) {
 	doTimeoutTest(t, 10, 15, 10, 0)
 	doTimeoutTest(t, 10, 0, 10, 0)
@@ -71,6 +71,14 @@ type StoredRequests struct {
 	// HTTPEvents configures an instance of stored_requests/events/http/http.go.
 	// If non-nil, the server will use those endpoints to populate and update the cache.
 	HTTPEvents HTTPEventsConfig `mapstructure:"http_events"`
+	// ObjectStorage configures an instance of stored_requests/backends/objectstore_fetcher/fetcher.go, and of
+	// stored_requests/events/objectstore/objectstore.go if its refresh rate is set.
+	// If the bucket is set, Stored Requests will be fetched from the objects stored there.
+	ObjectStorage ObjectStorageConfig `mapstructure:"object_storage"`
+	// Redis configures an instance of stored_requests/caches/redis/cache.go.
+	// If the address is set, Stored Requests will be saved in Redis, which is shared by all the instances
+	// between their in-memory caches and the backends.
+	Redis RedisCacheConfig `mapstructure:"redis"`
 }
 
 // HTTPEventsConfig configures stored_requests/events/http/http.go
@@ -111,6 +119,121 @@ type HTTPFetcherConfig struct {
 	AmpEndpoint string `mapstructure:"amp_endpoint"`
 }
 
+// ObjectStorageConfig configures stored_requests/backends/objectstore_fetcher/fetcher.go and
+// stored_requests/events/objectstore/objectstore.go
+type ObjectStorageConfig struct {
+	// Provider is the object storage service hosting the bucket, either "s3" or "gcs".
+	Provider string `mapstructure:"provider"`
+	// Endpoint overrides the default endpoint of the provider, e.g. for S3 compatible servers.
+	Endpoint string `mapstructure:"endpoint"`
+	// Region is the region of the bucket, which the requests are signed for.
+	Region string `mapstructure:"region"`
+	Bucket string `mapstructure:"bucket"`
+	// Prefix is the key prefix of the stored_requests, stored_imps, stored_responses and accounts directories.
+	Prefix    string `mapstructure:"prefix"`
+	AmpPrefix string `mapstructure:"amp_prefix"`
+	// AccessKeyID and SecretAccessKey are the HMAC credentials the requests are signed with.
+	// The requests are not signed if they are empty, which only works for public buckets.
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	// RefreshRate is the interval at which the bucket is listed for changes to update the cache with.
+	// The cache is not populated from the bucket if it is 0, and is never refreshed if it is negative.
+	RefreshRate int64 `mapstructure:"refresh_rate_seconds"`
+	Timeout     int   `mapstructure:"timeout_ms"`
+}
+
+func (cfg ObjectStorageConfig) TimeoutDuration() time.Duration {
+	return time.Duration(cfg.Timeout) * time.Millisecond
+}
+
+func (cfg ObjectStorageConfig) RefreshRateDuration() time.Duration {
+	return time.Duration(cfg.RefreshRate) * time.Second
+}
+
+// BucketEndpoint returns the url of the bucket, which the keys of its objects are appended to
+func (cfg ObjectStorageConfig) BucketEndpoint() string {
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		switch cfg.Provider {
+		case "s3":
+			endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+		case "gcs":
+			endpoint = "https://storage.googleapis.com"
+		}
+	}
+	return strings.TrimSuffix(endpoint, "/") + "/" + cfg.Bucket
+}
+
+// SigningRegion returns the region the requests are signed for. GCS accepts "auto" for every bucket.
+func (cfg ObjectStorageConfig) SigningRegion() string {
+	if cfg.Region == "" && cfg.Provider == "gcs" {
+		return "auto"
+	}
+	return cfg.Region
+}
+
+func (cfg *ObjectStorageConfig) validate(section string, errs []error) []error {
+	if cfg.Bucket == "" {
+		return errs
+	}
+	if cfg.Provider != "s3" && cfg.Provider != "gcs" {
+		errs = append(errs, fmt.Errorf("%s.object_storage.provider must be s3 or gcs. Got %s", section, cfg.Provider))
+	}
+	if cfg.Provider == "s3" && cfg.Region == "" {
+		errs = append(errs, fmt.Errorf("%s.object_storage.region is required for the s3 provider", section))
+	}
+	if (cfg.AccessKeyID == "") != (cfg.SecretAccessKey == "") {
+		errs = append(errs, fmt.Errorf("%s.object_storage: access_key_id and secret_access_key must be set together", section))
+	}
+	if cfg.RefreshRate != 0 && cfg.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("%s.object_storage.timeout_ms must be positive if refresh_rate_seconds is set. Got %d", section, cfg.Timeout))
+	}
+	return errs
+}
+
+// RedisCacheConfig configures stored_requests/caches/redis/cache.go
+type RedisCacheConfig struct {
+	// Address is the host:port of the Redis server. The Redis cache is disabled if it is empty.
+	Address  string `mapstructure:"address"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+	// KeyPrefix is prepended to the keys, which are then made of the config section, the data type and the id,
+	// e.g. pbs:stored_requests:imp:{id}
+	KeyPrefix string `mapstructure:"key_prefix"`
+	// TTL is the time after which the keys expire since they were saved.
+	TTL int `mapstructure:"ttl_seconds"`
+	// Timeout bounds the time of each round trip to Redis, after which the lookups are treated as misses.
+	Timeout  int `mapstructure:"timeout_ms"`
+	PoolSize int `mapstructure:"pool_size"`
+}
+
+func (cfg RedisCacheConfig) TTLDuration() time.Duration {
+	return time.Duration(cfg.TTL) * time.Second
+}
+
+func (cfg RedisCacheConfig) TimeoutDuration() time.Duration {
+	return time.Duration(cfg.Timeout) * time.Millisecond
+}
+
+func (cfg *RedisCacheConfig) validate(section string, errs []error) []error {
+	if cfg.Address == "" {
+		return errs
+	}
+	if cfg.DB < 0 {
+		errs = append(errs, fmt.Errorf("%s.redis.db must be >= 0. Got %d", section, cfg.DB))
+	}
+	if cfg.TTL <= 0 {
+		errs = append(errs, fmt.Errorf("%s.redis.ttl_seconds must be positive. Got %d", section, cfg.TTL))
+	}
+	if cfg.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("%s.redis.timeout_ms must be positive. Got %d", section, cfg.Timeout))
+	}
+	if cfg.PoolSize <= 0 {
+		errs = append(errs, fmt.Errorf("%s.redis.pool_size must be positive. Got %d", section, cfg.PoolSize))
+	}
+	return errs
+}
+
 // Migrate combined stored_requests+amp configuration to separate simple config sections
 func resolvedStoredRequestsConfig(cfg *Configuration) {
 	sr := &cfg.StoredRequests
@@ -126,6 +249,10 @@ func resolvedStoredRequestsConfig(cfg *Configuration) {
 	amp.HTTP.Endpoint = sr.HTTP.AmpEndpoint
 	amp.CacheEvents.Endpoint = "/storedrequests/amp"
 	amp.HTTPEvents.Endpoint = sr.HTTPEvents.AmpEndpoint
+	amp.ObjectStorage.Prefix = sr.ObjectStorage.AmpPrefix
+	if sr.ObjectStorage.AmpPrefix == "" {
+		amp.ObjectStorage.Bucket = ""
+	}
 
 	// Set data types for each section
 	cfg.StoredRequests.dataType = RequestDataType
@@ -145,8 +272,16 @@ func (cfg *StoredRequests) validate(errs []error) []error {
 
 	// Categories do not use cache so none of the following checks apply
 	if cfg.DataType() == CategoryDataType {
+		if cfg.ObjectStorage.Bucket != "" {
+			errs = append(errs, fmt.Errorf("%s.object_storage: retrieving categories via object storage not available, use categories.filesystem", cfg.Section()))
+		}
+		if cfg.Redis.Address != "" {
+			errs = append(errs, fmt.Errorf("%s.redis: caching categories in redis not available", cfg.Section()))
+		}
 		return errs
 	}
+	errs = cfg.ObjectStorage.validate(cfg.Section(), errs)
+	errs = cfg.Redis.validate(cfg.Section(), errs)
 
 	if cfg.InMemoryCache.Type == "none" {
 		if cfg.CacheEvents.Enabled {
@@ -157,6 +292,10 @@ func (cfg *StoredRequests) validate(errs []error) []error {
 			errs = append(errs, fmt.Errorf("%s: http_events.refresh_rate_seconds must be 0 if in_memory_cache=none", cfg.Section()))
 		}
 
+		if cfg.ObjectStorage.RefreshRate != 0 {
+			errs = append(errs, fmt.Errorf("%s: object_storage.refresh_rate_seconds must be 0 if in_memory_cache=none", cfg.Section()))
+		}
+
 		if cfg.Postgres.PollUpdates.Query != "" {
 			errs = append(errs, fmt.Errorf("%s: postgres.poll_for_updates.query must be empty if in_memory_cache=none", cfg.Section()))
 		}
@@ -175,6 +314,7 @@ type PostgresConfig struct {
 	FetcherQueries      PostgresFetcherQueries   `mapstructure:"fetcher"`
 	CacheInitialization PostgresCacheInitializer `mapstructure:"initialize_caches"`
 	PollUpdates         PostgresUpdatePolling    `mapstructure:"poll_for_updates"`
+	ListenUpdates       PostgresUpdateListening  `mapstructure:"listen_for_updates"`
 }
 
 func (cfg *PostgresConfig) validate(dataType DataType, errs []error) []error {
@@ -184,6 +324,7 @@ func (cfg *PostgresConfig) validate(dataType DataType, errs []error) []error {
 
 	errs = cfg.CacheInitialization.validate(dataType, errs)
 	errs = cfg.PollUpdates.validate(dataType, errs)
+	errs = cfg.ListenUpdates.validate(dataType, cfg.PollUpdates.Query, errs)
 	return errs
 }
 
@@ -261,6 +402,13 @@ type PostgresFetcherQueries struct {
 
 	// AmpQueryTemplate is the same as QueryTemplate, but used in the `/openrtb2/amp` endpoint.
 	AmpQueryTemplate string `mapstructure:"amp_query"`
+
+	// ResponseQueryTemplate is the Postgres Query which fetches the Stored Responses, if any. It selects the id and
+	// the data of each, for example:
+	//   SELECT id, responseData
+	//     FROM stored_responses
+	//     WHERE id in %ID_LIST%
+	ResponseQueryTemplate string `mapstructure:"response_query"`
 }
 
 type PostgresCacheInitializer struct {
@@ -336,12 +484,56 @@ func (cfg *PostgresUpdatePolling) validate(dataType DataType, errs []error) []er
 	return errs
 }
 
+// PostgresUpdateListening configures the LISTEN on a channel the database notifies of the changes of the Stored
+// Requests, typically from a trigger running pg_notify on their tables. Each notification runs the poll_for_updates
+// query right away, so the caches pick up the changes within seconds instead of at the next refresh. The polling
+// keeps running, which catches up on the notifications lost while the connection was down.
+type PostgresUpdateListening struct {
+	// Channel is the name of the notified channel. The payload of the notifications is ignored.
+	Channel string `mapstructure:"channel"`
+
+	// MinReconnectInterval and MaxReconnectInterval bound the backoff between the attempts to reconnect after the
+	// listening connection was lost.
+	MinReconnectInterval int `mapstructure:"min_reconnect_interval_ms"`
+	MaxReconnectInterval int `mapstructure:"max_reconnect_interval_ms"`
+}
+
+func (cfg *PostgresUpdateListening) validate(dataType DataType, pollQuery string, errs []error) []error {
+	section := dataType.Section()
+	if cfg.Channel == "" {
+		return errs
+	}
+
+	if pollQuery == "" {
+		errs = append(errs, fmt.Errorf("%s: postgres.listen_for_updates.channel requires a postgres.poll_for_updates.query", section))
+	}
+
+	if cfg.MinReconnectInterval <= 0 {
+		errs = append(errs, fmt.Errorf("%s: postgres.listen_for_updates.min_reconnect_interval_ms must be > 0", section))
+	}
+
+	if cfg.MaxReconnectInterval < cfg.MinReconnectInterval {
+		errs = append(errs, fmt.Errorf("%s: postgres.listen_for_updates.max_reconnect_interval_ms must be >= min_reconnect_interval_ms", section))
+	}
+	return errs
+}
+
 // MakeQuery builds a query which can fetch numReqs Stored Requests and numImps Stored Imps.
 // See the docs on PostgresConfig.QueryTemplate for a description of how it works.
 func (cfg *PostgresFetcherQueries) MakeQuery(numReqs int, numImps int) (query string) {
 	return resolve(cfg.QueryTemplate, numReqs, numImps)
 }
 
+// MakeResponseQuery builds a query which can fetch numIDs Stored Responses. It is empty if no
+// ResponseQueryTemplate is configured.
+func (cfg *PostgresFetcherQueries) MakeResponseQuery(numIDs int) (query string) {
+	if cfg.ResponseQueryTemplate == "" {
+		return ""
+	}
+	numIDs = ensureNonNegative("Response", numIDs)
+	return strings.Replace(cfg.ResponseQueryTemplate, "%ID_LIST%", makeIdList(0, numIDs), -1)
+}
+
 func resolve(template string, numReqs int, numImps int) (query string) {
 	numReqs = ensureNonNegative("Request", numReqs)
 	numImps = ensureNonNegative("Imp", numImps)
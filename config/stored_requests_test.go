@@ -41,6 +41,15 @@ func TestQueryMakerNegative(t *testing.T) {
 	assertStringsEqual(t, query, expected)
 }
 
+func TestResponseQueryMaker(t *testing.T) {
+	cfg := PostgresFetcherQueries{ResponseQueryTemplate: "SELECT id, responseData FROM stored_responses WHERE id in %ID_LIST%"}
+	assertStringsEqual(t, cfg.MakeResponseQuery(2), "SELECT id, responseData FROM stored_responses WHERE id in ($1, $2)")
+	assertStringsEqual(t, cfg.MakeResponseQuery(-1), "SELECT id, responseData FROM stored_responses WHERE id in (NULL)")
+
+	cfg = PostgresFetcherQueries{}
+	assertStringsEqual(t, cfg.MakeResponseQuery(2), "")
+}
+
 func TestPostgressConnString(t *testing.T) {
 	db := "TestDB"
 	host := "somehost.com"
@@ -166,6 +175,9 @@ func TestPostgresConfigValidation(t *testing.T) {
 		cacheUpdateQuery       string
 		cacheUpdateRefreshRate int
 		cacheUpdateTimeout     int
+		listenChannel          string
+		listenMinReconnect     int
+		listenMaxReconnect     int
 		existingErrors         []error
 		wantErrorCount         int
 	}{
@@ -228,6 +240,46 @@ func TestPostgresConfigValidation(t *testing.T) {
 			cacheUpdateTimeout:     1,
 			wantErrorCount:         1,
 		},
+		{
+			description:            "Valid listen channel with valid reconnect intervals",
+			connectionStr:          "some-connection-string",
+			cacheUpdateQuery:       "SELECT * FROM table WHERE $1",
+			cacheUpdateRefreshRate: 1,
+			cacheUpdateTimeout:     1,
+			listenChannel:          "stored_requests",
+			listenMinReconnect:     1,
+			listenMaxReconnect:     1,
+		},
+		{
+			description:        "Invalid listen channel without cache update query",
+			connectionStr:      "some-connection-string",
+			listenChannel:      "stored_requests",
+			listenMinReconnect: 1,
+			listenMaxReconnect: 1,
+			wantErrorCount:     1,
+		},
+		{
+			description:            "Invalid listen channel with zero min reconnect interval",
+			connectionStr:          "some-connection-string",
+			cacheUpdateQuery:       "SELECT * FROM table WHERE $1",
+			cacheUpdateRefreshRate: 1,
+			cacheUpdateTimeout:     1,
+			listenChannel:          "stored_requests",
+			listenMinReconnect:     0,
+			listenMaxReconnect:     1,
+			wantErrorCount:         1,
+		},
+		{
+			description:            "Invalid listen channel with max reconnect interval below min",
+			connectionStr:          "some-connection-string",
+			cacheUpdateQuery:       "SELECT * FROM table WHERE $1",
+			cacheUpdateRefreshRate: 1,
+			cacheUpdateTimeout:     1,
+			listenChannel:          "stored_requests",
+			listenMinReconnect:     2,
+			listenMaxReconnect:     1,
+			wantErrorCount:         1,
+		},
 		{
 			description:      "Multiple errors: valid queries missing timeouts and refresh rates plus existing error",
 			connectionStr:    "some-connection-string",
@@ -252,6 +304,11 @@ func TestPostgresConfigValidation(t *testing.T) {
 				RefreshRate: tt.cacheUpdateRefreshRate,
 				Timeout:     tt.cacheUpdateTimeout,
 			},
+			ListenUpdates: PostgresUpdateListening{
+				Channel:              tt.listenChannel,
+				MinReconnectInterval: tt.listenMinReconnect,
+				MaxReconnectInterval: tt.listenMaxReconnect,
+			},
 		}
 
 		errs := pgConfig.validate(RequestDataType, tt.existingErrors)
@@ -259,6 +316,99 @@ func TestPostgresConfigValidation(t *testing.T) {
 	}
 }
 
+func TestObjectStorageConfigValidation(t *testing.T) {
+	tests := []struct {
+		description    string
+		dataType       DataType
+		cacheType      string
+		objectStorage  ObjectStorageConfig
+		wantErrorCount int
+	}{
+		{
+			description: "No bucket",
+			dataType:    RequestDataType,
+			cacheType:   "none",
+		},
+		{
+			description:   "Valid s3 bucket",
+			dataType:      RequestDataType,
+			cacheType:     "none",
+			objectStorage: ObjectStorageConfig{Provider: "s3", Region: "us-east-1", Bucket: "bucket", AccessKeyID: "key", SecretAccessKey: "secret"},
+		},
+		{
+			description:   "Valid gcs bucket with refresh",
+			dataType:      AccountDataType,
+			cacheType:     "lru",
+			objectStorage: ObjectStorageConfig{Provider: "gcs", Bucket: "bucket", RefreshRate: 60, Timeout: 1000},
+		},
+		{
+			description:    "Invalid provider",
+			dataType:       RequestDataType,
+			cacheType:      "none",
+			objectStorage:  ObjectStorageConfig{Provider: "azure", Bucket: "bucket"},
+			wantErrorCount: 1,
+		},
+		{
+			description:    "Invalid s3 bucket without region",
+			dataType:       RequestDataType,
+			cacheType:      "none",
+			objectStorage:  ObjectStorageConfig{Provider: "s3", Bucket: "bucket"},
+			wantErrorCount: 1,
+		},
+		{
+			description:    "Invalid access key without secret",
+			dataType:       RequestDataType,
+			cacheType:      "none",
+			objectStorage:  ObjectStorageConfig{Provider: "gcs", Bucket: "bucket", AccessKeyID: "key"},
+			wantErrorCount: 1,
+		},
+		{
+			description:    "Invalid refresh without timeout",
+			dataType:       RequestDataType,
+			cacheType:      "lru",
+			objectStorage:  ObjectStorageConfig{Provider: "gcs", Bucket: "bucket", RefreshRate: 60},
+			wantErrorCount: 1,
+		},
+		{
+			description:    "Invalid refresh without cache",
+			dataType:       RequestDataType,
+			cacheType:      "none",
+			objectStorage:  ObjectStorageConfig{Provider: "gcs", Bucket: "bucket", RefreshRate: 60, Timeout: 1000},
+			wantErrorCount: 1,
+		},
+		{
+			description:    "Invalid bucket for categories",
+			dataType:       CategoryDataType,
+			objectStorage:  ObjectStorageConfig{Provider: "gcs", Bucket: "bucket"},
+			wantErrorCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		cfg := StoredRequests{
+			dataType:      tt.dataType,
+			ObjectStorage: tt.objectStorage,
+		}
+		cfg.InMemoryCache.Type = tt.cacheType
+		if tt.cacheType == "lru" {
+			cfg.InMemoryCache.Size = 1000
+			cfg.InMemoryCache.RequestCacheSize = 1000
+			cfg.InMemoryCache.ImpCacheSize = 1000
+		}
+
+		errs := cfg.validate(nil)
+		assert.Equal(t, tt.wantErrorCount, len(errs), "%s: %v", tt.description, errs)
+	}
+}
+
+func TestObjectStorageBucketEndpoint(t *testing.T) {
+	assert.Equal(t, "https://s3.eu-west-1.amazonaws.com/bucket", ObjectStorageConfig{Provider: "s3", Region: "eu-west-1", Bucket: "bucket"}.BucketEndpoint())
+	assert.Equal(t, "https://storage.googleapis.com/bucket", ObjectStorageConfig{Provider: "gcs", Bucket: "bucket"}.BucketEndpoint())
+	assert.Equal(t, "http://localhost:9000/bucket", ObjectStorageConfig{Provider: "s3", Endpoint: "http://localhost:9000/", Bucket: "bucket"}.BucketEndpoint())
+	assert.Equal(t, "auto", ObjectStorageConfig{Provider: "gcs"}.SigningRegion())
+	assert.Equal(t, "us-east-1", ObjectStorageConfig{Provider: "s3", Region: "us-east-1"}.SigningRegion())
+}
+
 func assertErrsExist(t *testing.T, err []error) {
 	t.Helper()
 	if len(err) == 0 {
@@ -337,6 +487,10 @@ func TestResolveConfig(t *testing.T) {
 			HTTPEvents: HTTPEventsConfig{
 				AmpEndpoint: "amp-http-events-endpoint",
 			},
+			ObjectStorage: ObjectStorageConfig{
+				Bucket:    "bucket",
+				AmpPrefix: "amp-prefix",
+			},
 		},
 	}
 
@@ -345,6 +499,7 @@ func TestResolveConfig(t *testing.T) {
 	cfg.StoredRequests.Postgres.PollUpdates.Query = "auc-poll-query"
 	cfg.StoredRequests.HTTP.Endpoint = "auc-http-fetcher-endpoint"
 	cfg.StoredRequests.HTTPEvents.Endpoint = "auc-http-events-endpoint"
+	cfg.StoredRequests.ObjectStorage.Prefix = "auc-prefix"
 
 	resolvedStoredRequestsConfig(cfg)
 	auc := &cfg.StoredRequests
@@ -360,4 +515,22 @@ func TestResolveConfig(t *testing.T) {
 	assertStringsEqual(t, amp.HTTP.Endpoint, cfg.StoredRequests.HTTP.AmpEndpoint)
 	assertStringsEqual(t, amp.HTTPEvents.Endpoint, cfg.StoredRequests.HTTPEvents.AmpEndpoint)
 	assertStringsEqual(t, amp.CacheEvents.Endpoint, "/storedrequests/amp")
+	assertStringsEqual(t, amp.ObjectStorage.Prefix, cfg.StoredRequests.ObjectStorage.AmpPrefix)
+	assertStringsEqual(t, amp.ObjectStorage.Bucket, "bucket")
+}
+
+func TestResolveConfigWithoutAmpPrefix(t *testing.T) {
+	cfg := &Configuration{
+		StoredRequests: StoredRequests{
+			ObjectStorage: ObjectStorageConfig{
+				Bucket: "bucket",
+				Prefix: "auc-prefix",
+			},
+		},
+	}
+
+	resolvedStoredRequestsConfig(cfg)
+
+	assertStringsEqual(t, cfg.StoredRequests.ObjectStorage.Bucket, "bucket")
+	assertStringsEqual(t, cfg.StoredRequestsAMP.ObjectStorage.Bucket, "")
 }
@@ -1,10 +1,26 @@
 package config
 
+import "fmt"
+
 // UserSync specifies the static global user sync configuration.
 type UserSync struct {
 	Cooperative UserSyncCooperative `mapstructure:"coop_sync"`
 	ExternalURL string              `mapstructure:"external_url"`
 	RedirectURL string              `mapstructure:"redirect_url"`
+	// DefaultLimit is the number of syncs returned to the /cookie_sync requests without a limit. 0 returns them all.
+	DefaultLimit int `mapstructure:"default_limit"`
+	// MaxLimit caps the number of syncs returned to each /cookie_sync request. 0 doesn't cap them.
+	MaxLimit int `mapstructure:"max_limit"`
+}
+
+func (cfg *UserSync) validate(errs []error) []error {
+	if cfg.DefaultLimit < 0 {
+		errs = append(errs, fmt.Errorf("user_sync.default_limit must be >= 0. Got %d", cfg.DefaultLimit))
+	}
+	if cfg.MaxLimit < 0 {
+		errs = append(errs, fmt.Errorf("user_sync.max_limit must be >= 0. Got %d", cfg.MaxLimit))
+	}
+	return errs
 }
 
 // UserSyncCooperative specifies the static global default cooperative cookie sync
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -15,13 +16,37 @@ import (
 
 // RateConverter holds the currencies conversion rates dictionary
 type RateConverter struct {
-	httpClient          httpClient
-	staleRatesThreshold time.Duration
-	syncSourceURL       string
-	rates               atomic.Value // Should only hold Rates struct
-	lastUpdated         atomic.Value // Should only hold time.Time
-	constantRates       Conversions
-	time                timeutil.Time
+	httpClient    httpClient
+	sources       []RateSource
+	rates         atomic.Value // Should only hold Rates struct
+	lastUpdated   atomic.Value // Should only hold time.Time
+	constantRates Conversions
+	time          timeutil.Time
+
+	// The fetches of the sources, which the info of the converter exposes
+	sourcesMutex  sync.RWMutex
+	activeSource  int
+	sourceFetches []rateSourceFetch
+}
+
+// RateSource is a URL or a file the currencies rates are fetched from. The rates fetched from the source are stale
+// once they have not been updated for StaleRatesThreshold, unless it is 0.
+type RateSource struct {
+	URL                 string
+	File                string
+	StaleRatesThreshold time.Duration
+}
+
+func (s RateSource) name() string {
+	if s.File != "" {
+		return s.File
+	}
+	return s.URL
+}
+
+type rateSourceFetch struct {
+	lastFetched time.Time
+	lastError   error
 }
 
 // NewRateConverter returns a new RateConverter
@@ -30,68 +55,114 @@ func NewRateConverter(
 	syncSourceURL string,
 	staleRatesThreshold time.Duration,
 ) *RateConverter {
+	return NewRateConverterWithSources(httpClient, []RateSource{{URL: syncSourceURL, StaleRatesThreshold: staleRatesThreshold}})
+}
+
+// NewRateConverterWithSources returns a new RateConverter fetching the rates from the first source which succeeds,
+// in order. The first source is tried again on every update, so it is used again once it recovers.
+func NewRateConverterWithSources(httpClient httpClient, sources []RateSource) *RateConverter {
 	return &RateConverter{
-		httpClient:          httpClient,
-		staleRatesThreshold: staleRatesThreshold,
-		syncSourceURL:       syncSourceURL,
-		rates:               atomic.Value{},
-		lastUpdated:         atomic.Value{},
-		constantRates:       NewConstantRates(),
-		time:                &timeutil.RealTime{},
+		httpClient:    httpClient,
+		sources:       sources,
+		rates:         atomic.Value{},
+		lastUpdated:   atomic.Value{},
+		constantRates: NewConstantRates(),
+		time:          &timeutil.RealTime{},
+		activeSource:  -1,
+		sourceFetches: make([]rateSourceFetch, len(sources)),
 	}
 }
 
-// fetch allows to retrieve the currencies rates from the syncSourceURL provided
-func (rc *RateConverter) fetch() (*Rates, error) {
-	request, err := http.NewRequest("GET", rc.syncSourceURL, nil)
+// fetch allows to retrieve the currencies rates from the source provided
+func (rc *RateConverter) fetch(source RateSource) (*Rates, error) {
+	var bytesJSON []byte
+	var err error
+	if source.File != "" {
+		bytesJSON, err = ioutil.ReadFile(source.File)
+	} else {
+		bytesJSON, err = rc.fetchURL(source.URL)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	response, err := rc.httpClient.Do(request)
+	updatedRates := &Rates{}
+	err = json.Unmarshal(bytesJSON, updatedRates)
 	if err != nil {
 		return nil, err
 	}
 
-	if response.StatusCode >= 400 {
-		message := fmt.Sprintf("The currency rates request failed with status code %d", response.StatusCode)
-		return nil, &errortypes.BadServerResponse{Message: message}
-	}
-
-	defer response.Body.Close()
+	return updatedRates, err
+}
 
-	bytesJSON, err := ioutil.ReadAll(response.Body)
+func (rc *RateConverter) fetchURL(url string) ([]byte, error) {
+	request, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	updatedRates := &Rates{}
-	err = json.Unmarshal(bytesJSON, updatedRates)
+	response, err := rc.httpClient.Do(request)
 	if err != nil {
 		return nil, err
 	}
 
-	return updatedRates, err
+	if response.StatusCode >= 400 {
+		message := fmt.Sprintf("The currency rates request failed with status code %d", response.StatusCode)
+		return nil, &errortypes.BadServerResponse{Message: message}
+	}
+
+	defer response.Body.Close()
+
+	return ioutil.ReadAll(response.Body)
 }
 
-// Update updates the internal currencies rates from remote sources
+// Update updates the internal currencies rates from the first source which succeeds
 func (rc *RateConverter) update() error {
-	rates, err := rc.fetch()
-	if err == nil {
+	errs := make([]error, 0, len(rc.sources))
+	for i, source := range rc.sources {
+		rates, err := rc.fetch(source)
+		rc.recordFetch(i, err)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if i > 0 {
+			glog.Warningf("Error updating conversion rates from %s, falling back to %s: %v", rc.sources[0].name(), source.name(), errs[0])
+		}
 		rc.rates.Store(rates)
 		rc.lastUpdated.Store(rc.time.Now())
+		return nil
+	}
+
+	var err error
+	if len(errs) == 1 {
+		err = errs[0]
 	} else {
-		if rc.checkStaleRates() {
-			rc.clearRates()
-			glog.Errorf("Error updating conversion rates, falling back to constant rates: %v", err)
-		} else {
-			glog.Errorf("Error updating conversion rates: %v", err)
-		}
+		err = errortypes.NewAggregateError("Error updating conversion rates from every source", errs)
+	}
+	if rc.checkStaleRates() {
+		rc.clearRates()
+		glog.Errorf("Error updating conversion rates, falling back to constant rates: %v", err)
+	} else {
+		glog.Errorf("Error updating conversion rates: %v", err)
 	}
 
 	return err
 }
 
+// recordFetch records the result of a fetch of the source, which becomes the active source when it succeeds
+func (rc *RateConverter) recordFetch(source int, err error) {
+	rc.sourcesMutex.Lock()
+	defer rc.sourcesMutex.Unlock()
+
+	rc.sourceFetches[source].lastError = err
+	if err == nil {
+		rc.sourceFetches[source].lastFetched = rc.time.Now()
+		rc.activeSource = source
+	}
+}
+
 func (rc *RateConverter) Run() error {
 	return rc.update()
 }
@@ -118,35 +189,89 @@ func (rc *RateConverter) Rates() Conversions {
 func (rc *RateConverter) clearRates() {
 	// atomic.Value field rates must be of type *Rates so we cast nil to that type
 	rc.rates.Store((*Rates)(nil))
+
+	rc.sourcesMutex.Lock()
+	rc.activeSource = -1
+	rc.sourcesMutex.Unlock()
 }
 
-// checkStaleRates checks if loaded third party conversion rates are stale
+// checkStaleRates checks if loaded third party conversion rates are stale, by the threshold of the source they
+// were fetched from
 func (rc *RateConverter) checkStaleRates() bool {
-	if rc.staleRatesThreshold <= 0 {
+	rc.sourcesMutex.RLock()
+	activeSource := rc.activeSource
+	rc.sourcesMutex.RUnlock()
+	if activeSource < 0 {
+		return false
+	}
+
+	staleRatesThreshold := rc.sources[activeSource].StaleRatesThreshold
+	if staleRatesThreshold <= 0 {
 		return false
 	}
 
 	currentTime := rc.time.Now().UTC()
 	if lastUpdated := rc.lastUpdated.Load(); lastUpdated != nil {
 		delta := currentTime.Sub(lastUpdated.(time.Time).UTC())
-		if delta.Seconds() > rc.staleRatesThreshold.Seconds() {
+		if delta.Seconds() > staleRatesThreshold.Seconds() {
 			return true
 		}
 	}
 	return false
 }
 
-// GetInfo returns setup information about the converter
+// GetInfo returns setup information about the converter. The source is the one of the current rates, or the first
+// one before the rates are fetched, and the additional info has the fetches of every source.
 func (rc *RateConverter) GetInfo() ConverterInfo {
 	var rates *map[string]map[string]float64
 	rates = rc.Rates().GetRates()
+
+	rc.sourcesMutex.RLock()
+	defer rc.sourcesMutex.RUnlock()
+
+	var source string
+	if rc.activeSource >= 0 {
+		source = rc.sources[rc.activeSource].name()
+	} else if len(rc.sources) > 0 && rc.rates.Load() == nil {
+		source = rc.sources[0].name()
+	}
+
+	sourcesInfo := make([]RateSourceInfo, len(rc.sources))
+	for i, fetch := range rc.sourceFetches {
+		sourcesInfo[i] = RateSourceInfo{
+			Source: rc.sources[i].name(),
+			Active: i == rc.activeSource,
+		}
+		if !fetch.lastFetched.IsZero() {
+			lastFetched := fetch.lastFetched
+			sourcesInfo[i].LastFetched = &lastFetched
+		}
+		if fetch.lastError != nil {
+			sourcesInfo[i].LastError = fetch.lastError.Error()
+		}
+	}
+
 	return converterInfo{
-		source:      rc.syncSourceURL,
-		lastUpdated: rc.LastUpdated(),
-		rates:       rates,
+		source:         source,
+		lastUpdated:    rc.LastUpdated(),
+		rates:          rates,
+		additionalInfo: RateSourcesInfo{Sources: sourcesInfo},
 	}
 }
 
+// RateSourcesInfo is the additional info of a RateConverter, with the fetches of its sources
+type RateSourcesInfo struct {
+	Sources []RateSourceInfo `json:"sources"`
+}
+
+// RateSourceInfo tells if the current rates were fetched from the source, and when it was last fetched
+type RateSourceInfo struct {
+	Source      string     `json:"source"`
+	Active      bool       `json:"active"`
+	LastFetched *time.Time `json:"lastFetched,omitempty"`
+	LastError   string     `json:"lastError,omitempty"`
+}
+
 type httpClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"sync"
 	"testing"
@@ -293,6 +294,106 @@ func TestRatesAreNeverConsideredStale(t *testing.T) {
 	assert.Equal(t, initialFakeTime, currencyConverter.LastUpdated(), "LastUpdated should be set")
 }
 
+func TestRateSourcesFailover(t *testing.T) {
+	primaryStatus := http.StatusInternalServerError
+	primaryServer := httptest.NewServer(http.HandlerFunc(
+		func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(primaryStatus)
+			rw.Write([]byte(`{"conversions":{"USD":{"EUR":0.9}}}`))
+		}),
+	)
+	defer primaryServer.Close()
+
+	file, err := ioutil.TempFile("", "currency-rates-*.json")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.Remove(file.Name())
+	file.Write(getMockRates())
+	file.Close()
+
+	fakeTime := &FakeTime{time: time.Date(2018, time.September, 12, 30, 0, 0, 0, time.UTC)}
+	currencyConverter := NewRateConverterWithSources(&http.Client{}, []RateSource{
+		{URL: primaryServer.URL, StaleRatesThreshold: 30 * time.Second},
+		{File: file.Name()},
+	})
+	currencyConverter.time = fakeTime
+
+	// Before the first update, the info has the first source
+	assert.Equal(t, primaryServer.URL, currencyConverter.GetInfo().Source())
+
+	// The primary source fails, so the rates are fetched from the file
+	assert.Nil(t, currencyConverter.Run())
+	rate, err := currencyConverter.Rates().GetRate("USD", "GBP")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.77208, rate)
+
+	info := currencyConverter.GetInfo()
+	assert.Equal(t, file.Name(), info.Source())
+	fetchedTime := fakeTime.time
+	assert.Equal(t, RateSourcesInfo{Sources: []RateSourceInfo{
+		{Source: primaryServer.URL, LastError: "The currency rates request failed with status code 500"},
+		{Source: file.Name(), Active: true, LastFetched: &fetchedTime},
+	}}, info.AdditionalInfo())
+
+	// The primary source recovers, so the rates are fetched from it again
+	primaryStatus = http.StatusOK
+	fakeTime.time = fakeTime.time.Add(time.Minute)
+	assert.Nil(t, currencyConverter.Run())
+	rate, err = currencyConverter.Rates().GetRate("USD", "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.9, rate)
+
+	info = currencyConverter.GetInfo()
+	assert.Equal(t, primaryServer.URL, info.Source())
+	assert.Equal(t, fakeTime.time, info.LastUpdated())
+	assert.Equal(t, RateSourcesInfo{Sources: []RateSourceInfo{
+		{Source: primaryServer.URL, Active: true, LastFetched: &fakeTime.time},
+		{Source: file.Name(), LastFetched: &fetchedTime},
+	}}, info.AdditionalInfo())
+}
+
+func TestRateSourcesStaleness(t *testing.T) {
+	secondaryCalls := 0
+	failingServer := httptest.NewServer(http.HandlerFunc(
+		func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusNotFound)
+		}),
+	)
+	defer failingServer.Close()
+	secondaryServer := httptest.NewServer(http.HandlerFunc(
+		func(rw http.ResponseWriter, req *http.Request) {
+			secondaryCalls++
+			if secondaryCalls > 1 {
+				rw.WriteHeader(http.StatusNotFound)
+				return
+			}
+			rw.Write(getMockRates())
+		}),
+	)
+	defer secondaryServer.Close()
+
+	fakeTime := &FakeTime{time: time.Date(2018, time.September, 12, 30, 0, 0, 0, time.UTC)}
+	currencyConverter := NewRateConverterWithSources(&http.Client{}, []RateSource{
+		{URL: failingServer.URL, StaleRatesThreshold: time.Hour},
+		{URL: secondaryServer.URL, StaleRatesThreshold: 30 * time.Second},
+	})
+	currencyConverter.time = fakeTime
+
+	assert.Nil(t, currencyConverter.Run())
+	assert.IsType(t, &Rates{}, currencyConverter.Rates())
+
+	// Every source fails, and the rates of the secondary source go stale after its own threshold
+	fakeTime.time = fakeTime.time.Add(29 * time.Second)
+	assert.NotNil(t, currencyConverter.Run())
+	assert.IsType(t, &Rates{}, currencyConverter.Rates(), "Rates should not be stale yet")
+
+	fakeTime.time = fakeTime.time.Add(2 * time.Second)
+	assert.NotNil(t, currencyConverter.Run())
+	assert.Equal(t, &ConstantRates{}, currencyConverter.Rates(), "Rates should return constant rates")
+	assert.Equal(t, "", currencyConverter.GetInfo().Source(), "No source should be active with the constant rates")
+}
+
 func TestRace(t *testing.T) {
 	// This test is checking that no race conditions appear in rate converter.
 	// It simulate multiple clients (in different goroutines) asking for updates
@@ -0,0 +1,40 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/account"
+)
+
+// accountVersionsInfo holds the versions of the accounts loaded by the account reloader.
+type accountVersionsInfo struct {
+	Active   bool                    `json:"active"`
+	Accounts []account.AccountStatus `json:"accounts,omitempty"`
+}
+
+type accountStatusProvider interface {
+	Statuses() []account.AccountStatus
+}
+
+// NewAccountVersionsEndpoint returns the versions of the accounts loaded so far, and the errors of the last reload of
+// the accounts which failed to reload.
+func NewAccountVersionsEndpoint(provider accountStatusProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		info := accountVersionsInfo{Active: provider != nil}
+		if provider != nil {
+			info.Accounts = provider.Statuses()
+		}
+
+		jsonOutput, err := json.Marshal(info)
+		if err != nil {
+			glog.Errorf("/accounts/versions Critical error when trying to marshal accountVersionsInfo: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(jsonOutput)
+	}
+}
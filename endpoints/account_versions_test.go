@@ -0,0 +1,59 @@
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/account"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockAccountStatusProvider struct {
+	statuses []account.AccountStatus
+}
+
+func (p *mockAccountStatusProvider) Statuses() []account.AccountStatus {
+	return p.statuses
+}
+
+func TestAccountVersionsEndpoint(t *testing.T) {
+	loadedAt := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	failedAt := loadedAt.Add(time.Minute)
+	testCases := []struct {
+		description  string
+		provider     accountStatusProvider
+		expectedBody string
+	}{
+		{
+			description:  "Account reload disabled",
+			provider:     nil,
+			expectedBody: `{"active":false}`,
+		},
+		{
+			description: "Accounts loaded",
+			provider: &mockAccountStatusProvider{statuses: []account.AccountStatus{
+				{ID: "account-1", Version: 2, Hash: "hash-1", LoadedAt: &loadedAt},
+				{ID: "account-2", Version: 1, Hash: "hash-2", LoadedAt: &loadedAt, Errors: []string{"invalid"}, FailedAt: &failedAt},
+			}},
+			expectedBody: `{
+				"active": true,
+				"accounts": [
+					{"id": "account-1", "version": 2, "hash": "hash-1", "loaded_at": "2026-01-01T00:00:00Z"},
+					{"id": "account-2", "version": 1, "hash": "hash-2", "loaded_at": "2026-01-01T00:00:00Z", "errors": ["invalid"], "failed_at": "2026-01-01T00:01:00Z"}
+				]
+			}`,
+		},
+	}
+
+	for _, test := range testCases {
+		handler := NewAccountVersionsEndpoint(test.provider)
+		w := httptest.NewRecorder()
+
+		handler(w, httptest.NewRequest(http.MethodGet, "/accounts/versions", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code, test.description)
+		assert.JSONEq(t, test.expectedBody, w.Body.String(), test.description)
+	}
+}
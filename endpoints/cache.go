@@ -0,0 +1,99 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/config"
+	pbc "github.com/prebid/prebid-server/prebid_cache_client"
+)
+
+type cachePutRequest struct {
+	Puts []pbc.Cacheable `json:"puts"`
+}
+
+type cachePutResponse struct {
+	Responses []cachePutResponseObject `json:"responses"`
+}
+
+type cachePutResponseObject struct {
+	UUID string `json:"uuid"`
+}
+
+// NewCachePutEndpoint implements the POST and PUT /cache endpoint of the embedded cache, which stores the values in
+// the format of Prebid Cache. The keys of the values are ignored unless the config allows setting them.
+func NewCachePutEndpoint(cache *pbc.EmbeddedCache, cfg config.EmbeddedCache, maxRequestSize int64) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		body := r.Body
+		if maxRequestSize > 0 {
+			body = http.MaxBytesReader(w, r.Body, maxRequestSize)
+		}
+		requestJSON, err := ioutil.ReadAll(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "Failed to read the request body: %v", err)
+			return
+		}
+
+		var request cachePutRequest
+		if err := json.Unmarshal(requestJSON, &request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "Failed to parse the request body: %v", err)
+			return
+		}
+		for i, put := range request.Puts {
+			if put.Type != pbc.TypeJSON && put.Type != pbc.TypeXML {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, "puts[%d].type must be one of json or xml. Got %s", i, put.Type)
+				return
+			}
+			if len(put.Data) == 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, "puts[%d].value is required", i)
+				return
+			}
+			if !cfg.AllowSettingKeys {
+				request.Puts[i].Key = ""
+			}
+		}
+
+		uuids := cache.Put(request.Puts)
+		response := cachePutResponse{Responses: make([]cachePutResponseObject, len(uuids))}
+		for i, uuid := range uuids {
+			response.Responses[i].UUID = uuid
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// NewCacheGetEndpoint implements the GET /cache?uuid=UUID endpoint of the embedded cache, which serves the stored
+// values with their content type
+func NewCacheGetEndpoint(cache *pbc.EmbeddedCache) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		uuid := r.URL.Query().Get("uuid")
+		if uuid == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Missing required parameter uuid"))
+			return
+		}
+
+		payloadType, data, found := cache.Get(uuid)
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, "No content stored for uuid=%s", uuid)
+			return
+		}
+
+		if payloadType == pbc.TypeXML {
+			w.Header().Set("Content-Type", "application/xml")
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		w.Write(data)
+	}
+}
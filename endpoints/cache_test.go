@@ -0,0 +1,134 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prebid/prebid-server/config"
+	pbc "github.com/prebid/prebid-server/prebid_cache_client"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEmbeddedCache() *pbc.EmbeddedCache {
+	return pbc.NewEmbeddedCache(config.EmbeddedCache{Enabled: true, SizeBytes: 1024 * 1024, DefaultTTLSeconds: 300})
+}
+
+func TestCachePutEndpoint(t *testing.T) {
+	testCases := []struct {
+		description      string
+		givenBody        string
+		givenConfig      config.EmbeddedCache
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			description:      "Valid puts",
+			givenBody:        `{"puts":[{"type":"json","value":{"id":"bid1"},"key":"key1"},{"type":"xml","value":"<VAST></VAST>","key":"key2"}]}`,
+			givenConfig:      config.EmbeddedCache{AllowSettingKeys: true},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"responses":[{"uuid":"key1"},{"uuid":"key2"}]}` + "\n",
+		},
+		{
+			description:      "Malformed body",
+			givenBody:        `{"puts":`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: "Failed to parse the request body: unexpected end of JSON input",
+		},
+		{
+			description:      "Invalid type",
+			givenBody:        `{"puts":[{"type":"html","value":"<div></div>"}]}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: "puts[0].type must be one of json or xml. Got html",
+		},
+		{
+			description:      "Missing value",
+			givenBody:        `{"puts":[{"type":"json"}]}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: "puts[0].value is required",
+		},
+	}
+
+	for _, test := range testCases {
+		endpoint := NewCachePutEndpoint(newTestEmbeddedCache(), test.givenConfig, 0)
+		recorder := httptest.NewRecorder()
+
+		endpoint(recorder, httptest.NewRequest("POST", "/cache", strings.NewReader(test.givenBody)), nil)
+
+		assert.Equal(t, test.expectedStatus, recorder.Code, test.description)
+		assert.Equal(t, test.expectedResponse, recorder.Body.String(), test.description)
+	}
+}
+
+func TestCachePutEndpointIgnoresKeys(t *testing.T) {
+	cache := newTestEmbeddedCache()
+	recorder := httptest.NewRecorder()
+
+	NewCachePutEndpoint(cache, config.EmbeddedCache{}, 0)(recorder, httptest.NewRequest("POST", "/cache",
+		strings.NewReader(`{"puts":[{"type":"json","value":{"id":"bid1"},"key":"key1"}]}`)), nil)
+
+	var response cachePutResponse
+	if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response)) && assert.Len(t, response.Responses, 1) {
+		assert.NotEqual(t, "key1", response.Responses[0].UUID, "the keys are not set unless allowed")
+		_, data, found := cache.Get(response.Responses[0].UUID)
+		assert.True(t, found)
+		assert.Equal(t, `{"id":"bid1"}`, string(data))
+	}
+	_, _, found := cache.Get("key1")
+	assert.False(t, found)
+}
+
+func TestCacheGetEndpoint(t *testing.T) {
+	cache := newTestEmbeddedCache()
+	NewCachePutEndpoint(cache, config.EmbeddedCache{AllowSettingKeys: true}, 0)(httptest.NewRecorder(), httptest.NewRequest("POST", "/cache",
+		strings.NewReader(`{"puts":[{"type":"json","value":{"id":"bid1"},"key":"key1"},{"type":"xml","value":"<VAST></VAST>","key":"key2"}]}`)), nil)
+
+	testCases := []struct {
+		description         string
+		givenURL            string
+		expectedStatus      int
+		expectedContentType string
+		expectedResponse    string
+	}{
+		{
+			description:         "JSON value",
+			givenURL:            "/cache?uuid=key1",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedResponse:    `{"id":"bid1"}`,
+		},
+		{
+			description:         "XML value",
+			givenURL:            "/cache?uuid=key2",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/xml",
+			expectedResponse:    `<VAST></VAST>`,
+		},
+		{
+			description:      "Unknown uuid",
+			givenURL:         "/cache?uuid=key3",
+			expectedStatus:   http.StatusNotFound,
+			expectedResponse: "No content stored for uuid=key3",
+		},
+		{
+			description:      "Missing uuid",
+			givenURL:         "/cache",
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: "Missing required parameter uuid",
+		},
+	}
+
+	for _, test := range testCases {
+		recorder := httptest.NewRecorder()
+
+		NewCacheGetEndpoint(cache)(recorder, httptest.NewRequest("GET", test.givenURL, nil), nil)
+
+		assert.Equal(t, test.expectedStatus, recorder.Code, test.description)
+		assert.Equal(t, test.expectedResponse, recorder.Body.String(), test.description)
+		if test.expectedContentType != "" {
+			assert.Equal(t, test.expectedContentType, recorder.Header().Get("Content-Type"), test.description)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
+	accountService "github.com/prebid/prebid-server/account"
 	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/gdpr"
@@ -19,6 +20,7 @@ import (
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/privacy/ccpa"
 	gdprPrivacy "github.com/prebid/prebid-server/privacy/gdpr"
+	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/usersync"
 )
 
@@ -38,6 +40,7 @@ func NewCookieSyncEndpoint(
 	gdprPermissions gdpr.Permissions,
 	metrics metrics.MetricsEngine,
 	pbsAnalytics analytics.PBSAnalyticsModule,
+	accountsFetcher stored_requests.AccountFetcher,
 	bidders map[string]openrtb_ext.BidderName) HTTPRouterHandler {
 
 	bidderHashSet := make(map[string]struct{}, len(bidders))
@@ -55,8 +58,10 @@ func NewCookieSyncEndpoint(
 			ccpaEnforce:     config.CCPA.Enforce,
 			bidderHashSet:   bidderHashSet,
 		},
-		metrics:      metrics,
-		pbsAnalytics: pbsAnalytics,
+		metrics:         metrics,
+		pbsAnalytics:    pbsAnalytics,
+		pbsConfig:       config,
+		accountsFetcher: accountsFetcher,
 	}
 }
 
@@ -67,6 +72,9 @@ type cookieSyncEndpoint struct {
 	privacyConfig    usersyncPrivacyConfig
 	metrics          metrics.MetricsEngine
 	pbsAnalytics     analytics.PBSAnalyticsModule
+	// pbsConfig and accountsFetcher load the account of the request, whose activity controls may block the syncs
+	pbsConfig       *config.Configuration
+	accountsFetcher stored_requests.AccountFetcher
 }
 
 func (c *cookieSyncEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -106,6 +114,12 @@ func (c *cookieSyncEndpoint) parseRequest(r *http.Request) (usersync.Request, pr
 		return usersync.Request{}, privacy.Policies{}, fmt.Errorf("JSON parsing failed: %s", err.Error())
 	}
 
+	// The GPC signal of the browser stands for an opt out of sale when the request has no us_privacy string
+	gpcSignal := parseGPCHeader(r)
+	if gpcSignal == "1" && request.USPrivacy == "" {
+		request.USPrivacy = ccpa.GPCConsent
+	}
+
 	var gdprString string
 	if request.GDPR != nil {
 		gdprString = strconv.Itoa(*request.GDPR)
@@ -151,24 +165,90 @@ func (c *cookieSyncEndpoint) parseRequest(r *http.Request) (usersync.Request, pr
 		return usersync.Request{}, privacy.Policies{}, err
 	}
 
+	account, err := getAccount(c.pbsConfig, c.accountsFetcher, request.Account)
+	if err != nil {
+		return usersync.Request{}, privacy.Policies{}, err
+	}
+	activityControl := privacy.NewActivityControl(account.Privacy)
+
 	rx := usersync.Request{
-		Bidders: request.Bidders,
-		Cooperative: usersync.Cooperative{
-			Enabled:        (request.CooperativeSync != nil && *request.CooperativeSync) || (request.CooperativeSync == nil && c.config.Cooperative.EnabledByDefault),
-			PriorityGroups: c.config.Cooperative.PriorityGroups,
-		},
-		Limit: request.Limit,
+		Bidders:     request.Bidders,
+		Cooperative: c.cooperative(request.CooperativeSync, account.CookieSync),
+		Limit:       c.limit(request.Limit, account.CookieSync),
 		Privacy: usersyncPrivacy{
 			gdprPermissions:  c.privacyConfig.gdprPermissions,
 			gdprSignal:       gdprSignal,
 			gdprConsent:      request.GDPRConsent,
 			ccpaParsedPolicy: ccpaParsedPolicy,
+			activityControl:  activityControl,
+			activityRequest:  privacy.ActivityRequest{GPC: gpcSignal},
 		},
 		SyncTypeFilter: syncTypeFilter,
 	}
 	return rx, privacyPolicies, nil
 }
 
+// getAccount loads the account of the request, which is account_defaults for the requests without an account
+func getAccount(pbsConfig *config.Configuration, accountsFetcher stored_requests.AccountFetcher, accountID string) (*config.Account, error) {
+	if accountID == "" {
+		return &pbsConfig.AccountDefaults, nil
+	}
+
+	account, errs := accountService.GetAccount(context.Background(), pbsConfig, accountsFetcher, accountID)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return account, nil
+}
+
+// cooperative returns the cooperative syncing of the request, which is enabled by the coopSync of the request or else
+// by default for the account. The priority groups of the account are synced ahead of the ones of the host, which fill
+// the remaining slots.
+func (c *cookieSyncEndpoint) cooperative(coopSync *bool, accountConfig config.AccountCookieSync) usersync.Cooperative {
+	enabled := c.config.Cooperative.EnabledByDefault
+	if accountConfig.DefaultCoopSync != nil {
+		enabled = *accountConfig.DefaultCoopSync
+	}
+	if coopSync != nil {
+		enabled = *coopSync
+	}
+
+	priorityGroups := c.config.Cooperative.PriorityGroups
+	if len(accountConfig.PriorityGroups) > 0 {
+		priorityGroups = append(append([][]string{}, accountConfig.PriorityGroups...), priorityGroups...)
+	}
+	return usersync.Cooperative{Enabled: enabled, PriorityGroups: priorityGroups}
+}
+
+// limit returns the number of syncs of the request, which is its own limit or else the default one, capped by the
+// max limit. The limits of the account override the ones of the host.
+func (c *cookieSyncEndpoint) limit(requestLimit int, accountConfig config.AccountCookieSync) int {
+	defaultLimit, maxLimit := c.config.DefaultLimit, c.config.MaxLimit
+	if accountConfig.DefaultLimit != nil {
+		defaultLimit = *accountConfig.DefaultLimit
+	}
+	if accountConfig.MaxLimit != nil {
+		maxLimit = *accountConfig.MaxLimit
+	}
+
+	limit := requestLimit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if maxLimit > 0 && (limit <= 0 || limit > maxLimit) {
+		limit = maxLimit
+	}
+	return limit
+}
+
+// parseGPCHeader reads the Global Privacy Control signal of the browser, which cookie syncs can't carry in regs.ext
+func parseGPCHeader(r *http.Request) string {
+	if r.Header.Get("Sec-GPC") == "1" {
+		return "1"
+	}
+	return ""
+}
+
 func parseTypeFilter(request *cookieSyncRequestFilterSettings) (usersync.SyncTypeFilter, error) {
 	syncTypeFilter := usersync.SyncTypeFilter{
 		IFrame:   cookieSyncBidderFilterAllowAll,
@@ -246,6 +326,8 @@ func (c *cookieSyncEndpoint) writeBidderMetrics(biddersEvaluated []usersync.Bidd
 			c.metrics.RecordSyncerRequest(bidder.SyncerKey, metrics.SyncerCookieSyncPrivacyBlocked)
 		case usersync.StatusBlockedByCCPA:
 			c.metrics.RecordSyncerRequest(bidder.SyncerKey, metrics.SyncerCookieSyncPrivacyBlocked)
+		case usersync.StatusBlockedByPrivacy:
+			c.metrics.RecordSyncerRequest(bidder.SyncerKey, metrics.SyncerCookieSyncPrivacyBlocked)
 		case usersync.StatusAlreadySynced:
 			c.metrics.RecordSyncerRequest(bidder.SyncerKey, metrics.SyncerCookieSyncAlreadySynced)
 		case usersync.StatusTypeNotSupported:
@@ -319,6 +401,7 @@ type cookieSyncRequest struct {
 	Limit           int                              `json:"limit"`
 	CooperativeSync *bool                            `json:"coopSync"`
 	FilterSettings  *cookieSyncRequestFilterSettings `json:"filterSettings"`
+	Account         string                           `json:"account"`
 }
 
 type cookieSyncRequestFilterSettings struct {
@@ -360,6 +443,8 @@ type usersyncPrivacy struct {
 	gdprSignal       gdpr.Signal
 	gdprConsent      string
 	ccpaParsedPolicy ccpa.ParsedPolicy
+	activityControl  privacy.ActivityControl
+	activityRequest  privacy.ActivityRequest
 }
 
 func (p usersyncPrivacy) GDPRAllowsHostCookie() bool {
@@ -376,3 +461,8 @@ func (p usersyncPrivacy) CCPAAllowsBidderSync(bidder string) bool {
 	enforce := p.ccpaParsedPolicy.CanEnforce() && p.ccpaParsedPolicy.ShouldEnforce(bidder)
 	return !enforce
 }
+
+func (p usersyncPrivacy) ActivityAllowsUserSync(bidder string) bool {
+	component := privacy.Component{Type: config.ComponentTypeBidder, Name: bidder}
+	return p.activityControl.Allow(privacy.ActivitySyncUser, component, p.activityRequest)
+}
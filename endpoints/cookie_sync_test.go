@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
@@ -18,6 +19,7 @@ import (
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/privacy/ccpa"
 	gdprPrivacy "github.com/prebid/prebid-server/privacy/gdpr"
+	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/usersync"
 
 	"github.com/stretchr/testify/assert"
@@ -34,20 +36,23 @@ func TestNewCookieSyncEndpoint(t *testing.T) {
 		configCCPAEnforce = true
 		metrics           = metrics.MetricsEngineMock{}
 		analytics         = MockAnalytics{}
+		accountsFetcher   = FakeAccountsFetcher{}
 		bidders           = map[string]openrtb_ext.BidderName{"bidderA": openrtb_ext.BidderName("bidderA"), "bidderB": openrtb_ext.BidderName("bidderB")}
-	)
-
-	endpoint := NewCookieSyncEndpoint(
-		syncersByBidder,
-		&config.Configuration{
+		pbsConfig         = &config.Configuration{
 			UserSync:   configUserSync,
 			HostCookie: configHostCookie,
 			GDPR:       configGDPR,
 			CCPA:       config.CCPA{Enforce: configCCPAEnforce},
-		},
+		}
+	)
+
+	endpoint := NewCookieSyncEndpoint(
+		syncersByBidder,
+		pbsConfig,
 		&gdprPerms,
 		&metrics,
 		&analytics,
+		&accountsFetcher,
 		bidders,
 	)
 
@@ -61,8 +66,10 @@ func TestNewCookieSyncEndpoint(t *testing.T) {
 			ccpaEnforce:     configCCPAEnforce,
 			bidderHashSet:   map[string]struct{}{"bidderA": {}, "bidderB": {}},
 		},
-		metrics:      &metrics,
-		pbsAnalytics: &analytics,
+		metrics:         &metrics,
+		pbsAnalytics:    &analytics,
+		pbsConfig:       pbsConfig,
+		accountsFetcher: &accountsFetcher,
 	}
 
 	assert.Equal(t, expected, endpoint)
@@ -249,6 +256,7 @@ func TestCookieSyncHandle(t *testing.T) {
 			},
 			metrics:      &mockMetrics,
 			pbsAnalytics: &mockAnalytics,
+			pbsConfig:    &config.Configuration{},
 		}
 		endpoint.Handle(writer, request, nil)
 
@@ -658,6 +666,7 @@ func TestCookieSyncParseRequest(t *testing.T) {
 				gdprConfig:  test.givenGDPRConfig,
 				ccpaEnforce: test.givenCCPAEnabled,
 			},
+			pbsConfig: &config.Configuration{},
 		}
 		request, privacyPolicies, err := endpoint.parseRequest(httpRequest)
 
@@ -673,6 +682,225 @@ func TestCookieSyncParseRequest(t *testing.T) {
 	}
 }
 
+func TestCookieSyncParseRequestActivityControl(t *testing.T) {
+	falseValue := false
+	defaultsPrivacy := config.AccountPrivacy{AllowActivities: config.AllowActivities{
+		SyncUser: config.Activity{Default: &falseValue},
+	}}
+	// The rules of the account are merged into account_defaults, which keeps its default
+	accountPrivacy := config.AccountPrivacy{AllowActivities: config.AllowActivities{
+		SyncUser: config.Activity{Default: &falseValue, Rules: []config.ActivityRule{{Condition: config.ActivityCondition{ComponentName: []string{"a"}}}}},
+	}}
+
+	testCases := []struct {
+		description             string
+		givenBody               string
+		givenGPCHeader          string
+		expectedError           string
+		expectedActivityControl privacy.ActivityControl
+		expectedActivityRequest privacy.ActivityRequest
+		expectedUSPrivacy       string
+	}{
+		{
+			description:             "No account",
+			givenBody:               `{"gdpr":0}`,
+			expectedActivityControl: privacy.NewActivityControl(defaultsPrivacy),
+		},
+		{
+			description:             "Account with activity controls and the GPC header",
+			givenBody:               `{"gdpr":0,"account":"activities"}`,
+			givenGPCHeader:          "1",
+			expectedActivityControl: privacy.NewActivityControl(accountPrivacy),
+			expectedActivityRequest: privacy.ActivityRequest{GPC: "1"},
+			expectedUSPrivacy:       "1-Y-",
+		},
+		{
+			description:             "GPC header with a us_privacy string",
+			givenBody:               `{"gdpr":0,"us_privacy":"1NNN"}`,
+			givenGPCHeader:          "1",
+			expectedActivityControl: privacy.NewActivityControl(defaultsPrivacy),
+			expectedActivityRequest: privacy.ActivityRequest{GPC: "1"},
+			expectedUSPrivacy:       "1NNN",
+		},
+		{
+			description:             "Unknown account",
+			givenBody:               `{"gdpr":0,"account":"unknown"}`,
+			expectedActivityControl: privacy.NewActivityControl(defaultsPrivacy),
+		},
+		{
+			description:   "Blacklisted account",
+			givenBody:     `{"gdpr":0,"account":"blacklisted"}`,
+			expectedError: "Prebid-server has disabled Account ID: blacklisted, please reach out to the prebid server host.",
+		},
+	}
+
+	for _, test := range testCases {
+		httpRequest := httptest.NewRequest("POST", "/cookiesync", strings.NewReader(test.givenBody))
+		if test.givenGPCHeader != "" {
+			httpRequest.Header.Set("Sec-GPC", test.givenGPCHeader)
+		}
+
+		pbsConfig := &config.Configuration{
+			AccountDefaults:    config.Account{Privacy: defaultsPrivacy},
+			BlacklistedAcctMap: map[string]bool{"blacklisted": true},
+		}
+		assert.NoError(t, pbsConfig.MarshalAccountDefaults())
+
+		endpoint := cookieSyncEndpoint{
+			privacyConfig: usersyncPrivacyConfig{gdprConfig: config.GDPR{Enabled: true, DefaultValue: "0"}},
+			pbsConfig:     pbsConfig,
+			accountsFetcher: FakeAccountsFetcher{AccountData: map[string]json.RawMessage{
+				"activities": json.RawMessage(`{"privacy":{"allowactivities":{"syncUser":{"rules":[{"condition":{"componentName":["a"]},"allow":false}]}}}}`),
+			}},
+		}
+		request, privacyPolicies, err := endpoint.parseRequest(httpRequest)
+
+		if test.expectedError != "" {
+			assert.EqualError(t, err, test.expectedError, test.description+":err")
+			continue
+		}
+		if assert.NoError(t, err, test.description+":err") {
+			privacy := request.Privacy.(usersyncPrivacy)
+			assert.Equal(t, test.expectedActivityControl, privacy.activityControl, test.description+":activity_control")
+			assert.Equal(t, test.expectedActivityRequest, privacy.activityRequest, test.description+":activity_request")
+			assert.Equal(t, test.expectedUSPrivacy, privacyPolicies.CCPA.Consent, test.description+":us_privacy")
+		}
+	}
+}
+
+func TestCookieSyncParseRequestAccountCookieSync(t *testing.T) {
+	httpRequest := httptest.NewRequest("POST", "/cookiesync", strings.NewReader(`{"gdpr":0,"account":"coop"}`))
+
+	pbsConfig := &config.Configuration{}
+	assert.NoError(t, pbsConfig.MarshalAccountDefaults())
+
+	endpoint := cookieSyncEndpoint{
+		config: config.UserSync{
+			Cooperative: config.UserSyncCooperative{PriorityGroups: [][]string{{"a"}}},
+			MaxLimit:    8,
+		},
+		privacyConfig: usersyncPrivacyConfig{gdprConfig: config.GDPR{Enabled: true, DefaultValue: "0"}},
+		pbsConfig:     pbsConfig,
+		accountsFetcher: FakeAccountsFetcher{AccountData: map[string]json.RawMessage{
+			"coop": json.RawMessage(`{"cookie_sync":{"default_limit":4,"default_coop_sync":true,"priority_groups":[["b","c"]]}}`),
+		}},
+	}
+	request, _, err := endpoint.parseRequest(httpRequest)
+
+	assert.NoError(t, err)
+	assert.Equal(t, usersync.Cooperative{Enabled: true, PriorityGroups: [][]string{{"b", "c"}, {"a"}}}, request.Cooperative)
+	assert.Equal(t, 4, request.Limit)
+}
+
+func TestCookieSyncCooperative(t *testing.T) {
+	trueValue, falseValue := true, false
+	hostConfig := config.UserSync{Cooperative: config.UserSyncCooperative{EnabledByDefault: true, PriorityGroups: [][]string{{"a", "b"}, {"c"}}}}
+
+	testCases := []struct {
+		description         string
+		givenCoopSync       *bool
+		givenAccountConfig  config.AccountCookieSync
+		expectedCooperative usersync.Cooperative
+	}{
+		{
+			description:         "Host defaults",
+			expectedCooperative: usersync.Cooperative{Enabled: true, PriorityGroups: [][]string{{"a", "b"}, {"c"}}},
+		},
+		{
+			description:         "Disabled by default for the account",
+			givenAccountConfig:  config.AccountCookieSync{DefaultCoopSync: &falseValue},
+			expectedCooperative: usersync.Cooperative{Enabled: false, PriorityGroups: [][]string{{"a", "b"}, {"c"}}},
+		},
+		{
+			description:         "Enabled by the request",
+			givenCoopSync:       &trueValue,
+			givenAccountConfig:  config.AccountCookieSync{DefaultCoopSync: &falseValue},
+			expectedCooperative: usersync.Cooperative{Enabled: true, PriorityGroups: [][]string{{"a", "b"}, {"c"}}},
+		},
+		{
+			description:         "Priority groups of the account ahead of the host ones",
+			givenAccountConfig:  config.AccountCookieSync{PriorityGroups: [][]string{{"d"}}},
+			expectedCooperative: usersync.Cooperative{Enabled: true, PriorityGroups: [][]string{{"d"}, {"a", "b"}, {"c"}}},
+		},
+	}
+
+	for _, test := range testCases {
+		endpoint := cookieSyncEndpoint{config: hostConfig}
+
+		cooperative := endpoint.cooperative(test.givenCoopSync, test.givenAccountConfig)
+
+		assert.Equal(t, test.expectedCooperative, cooperative, test.description)
+	}
+	assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, hostConfig.Cooperative.PriorityGroups, "host priority groups unchanged")
+}
+
+func TestCookieSyncLimit(t *testing.T) {
+	zero, two, five := 0, 2, 5
+
+	testCases := []struct {
+		description        string
+		givenHostConfig    config.UserSync
+		givenRequestLimit  int
+		givenAccountConfig config.AccountCookieSync
+		expectedLimit      int
+	}{
+		{
+			description:   "No limit",
+			expectedLimit: 0,
+		},
+		{
+			description:       "Request limit",
+			givenHostConfig:   config.UserSync{DefaultLimit: 2},
+			givenRequestLimit: 4,
+			expectedLimit:     4,
+		},
+		{
+			description:     "Host default limit",
+			givenHostConfig: config.UserSync{DefaultLimit: 2},
+			expectedLimit:   2,
+		},
+		{
+			description:        "Account default limit",
+			givenHostConfig:    config.UserSync{DefaultLimit: 2},
+			givenAccountConfig: config.AccountCookieSync{DefaultLimit: &five},
+			expectedLimit:      5,
+		},
+		{
+			description:       "Request limit capped by the host",
+			givenHostConfig:   config.UserSync{MaxLimit: 3},
+			givenRequestLimit: 4,
+			expectedLimit:     3,
+		},
+		{
+			description:     "No limit capped by the host",
+			givenHostConfig: config.UserSync{MaxLimit: 3},
+			expectedLimit:   3,
+		},
+		{
+			description:        "Request limit capped by the account",
+			givenHostConfig:    config.UserSync{MaxLimit: 3},
+			givenRequestLimit:  4,
+			givenAccountConfig: config.AccountCookieSync{MaxLimit: &two},
+			expectedLimit:      2,
+		},
+		{
+			description:        "Host cap lifted by the account",
+			givenHostConfig:    config.UserSync{MaxLimit: 3},
+			givenRequestLimit:  4,
+			givenAccountConfig: config.AccountCookieSync{MaxLimit: &zero},
+			expectedLimit:      4,
+		},
+	}
+
+	for _, test := range testCases {
+		endpoint := cookieSyncEndpoint{config: test.givenHostConfig}
+
+		limit := endpoint.limit(test.givenRequestLimit, test.givenAccountConfig)
+
+		assert.Equal(t, test.expectedLimit, limit, test.description)
+	}
+}
+
 func TestParseTypeFilter(t *testing.T) {
 	testCases := []struct {
 		description    string
@@ -882,6 +1110,13 @@ func TestCookieSyncWriteBidderMetrics(t *testing.T) {
 				m.On("RecordSyncerRequest", "aSyncer", metrics.SyncerCookieSyncPrivacyBlocked).Once()
 			},
 		},
+		{
+			description: "One - Blocked By Activity Controls",
+			given:       []usersync.BidderEvaluation{{Bidder: "a", SyncerKey: "aSyncer", Status: usersync.StatusBlockedByPrivacy}},
+			setExpectations: func(m *metrics.MetricsEngineMock) {
+				m.On("RecordSyncerRequest", "aSyncer", metrics.SyncerCookieSyncPrivacyBlocked).Once()
+			},
+		},
 		{
 			description: "One - Already Synced",
 			given:       []usersync.BidderEvaluation{{Bidder: "a", SyncerKey: "aSyncer", Status: usersync.StatusAlreadySynced}},
@@ -1242,6 +1477,53 @@ func TestUsersyncPrivacyCCPAAllowsBidderSync(t *testing.T) {
 	}
 }
 
+func TestUsersyncPrivacyActivityAllowsUserSync(t *testing.T) {
+	activityControl := privacy.NewActivityControl(config.AccountPrivacy{AllowActivities: config.AllowActivities{
+		SyncUser: config.Activity{Rules: []config.ActivityRule{{Condition: config.ActivityCondition{ComponentName: []string{"foo"}, GPC: "1"}}}},
+	}})
+
+	testCases := []struct {
+		description  string
+		givenBidder  string
+		givenRequest privacy.ActivityRequest
+		expected     bool
+	}{
+		{
+			description:  "Not Allowed - Matching Rule",
+			givenBidder:  "foo",
+			givenRequest: privacy.ActivityRequest{GPC: "1"},
+			expected:     false,
+		},
+		{
+			description: "Allowed - No GPC",
+			givenBidder: "foo",
+			expected:    true,
+		},
+		{
+			description:  "Allowed - Another Bidder",
+			givenBidder:  "bar",
+			givenRequest: privacy.ActivityRequest{GPC: "1"},
+			expected:     true,
+		},
+	}
+
+	for _, test := range testCases {
+		privacy := usersyncPrivacy{activityControl: activityControl, activityRequest: test.givenRequest}
+		assert.Equal(t, test.expected, privacy.ActivityAllowsUserSync(test.givenBidder), test.description)
+	}
+}
+
+type FakeAccountsFetcher struct {
+	AccountData map[string]json.RawMessage
+}
+
+func (f FakeAccountsFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
+	if account, ok := f.AccountData[accountID]; ok {
+		return account, nil
+	}
+	return nil, []error{stored_requests.NotFoundError{ID: accountID, DataType: "Account"}}
+}
+
 type FakeChooser struct {
 	Result usersync.Result
 }
@@ -1274,6 +1556,11 @@ func (m *MockSyncer) GetSync(syncTypes []usersync.SyncType, privacyPolicies priv
 	return args.Get(0).(usersync.Sync), args.Error(1)
 }
 
+func (m *MockSyncer) GetChainRedirect(uid string, privacyPolicies privacy.Policies) (string, error) {
+	args := m.Called(uid, privacyPolicies)
+	return args.String(0), args.Error(1)
+}
+
 type MockAnalytics struct {
 	mock.Mock
 }
@@ -55,11 +55,12 @@ func newCurrencyRatesInfo(rateConverter rateConverter, fetchingInterval time.Dur
 	return currencyRatesInfo
 }
 
-// NewCurrencyRatesEndpoint returns current currency rates applied by the PBS server.
+// NewCurrencyRatesEndpoint returns current currency rates applied by the PBS server, with the source they were
+// fetched from.
 func NewCurrencyRatesEndpoint(rateConverter rateConverter, fetchingInterval time.Duration) http.HandlerFunc {
-	currencyRateInfo := newCurrencyRatesInfo(rateConverter, fetchingInterval)
-
 	return func(w http.ResponseWriter, _ *http.Request) {
+		// The info is read on every request, as the rates and their source change with the updates
+		currencyRateInfo := newCurrencyRatesInfo(rateConverter, fetchingInterval)
 		jsonOutput, err := json.Marshal(currencyRateInfo)
 		if err != nil {
 			glog.Errorf("/currency/rates Critical error when trying to marshal currencyRateInfo: %v", err)
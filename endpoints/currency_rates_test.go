@@ -106,6 +106,24 @@ func TestCurrencyRatesEndpoint(t *testing.T) {
 	}
 }
 
+func TestCurrencyRatesEndpointReadsUpdates(t *testing.T) {
+	converter := newRateConverterMock("https://sync.test.com", time.Time{}, nil)
+	handler := NewCurrencyRatesEndpoint(&converter, time.Duration(0))
+
+	// The rates are fetched from another source after the endpoint was created
+	converter.syncSourceURL = "https://backup.test.com"
+	converter.lastUpdated = time.Date(2019, 3, 2, 12, 54, 56, 0, time.UTC)
+	w := httptest.NewRecorder()
+	handler(w, nil)
+
+	assert.JSONEq(t, `{
+		"active": true,
+		"source": "https://backup.test.com",
+		"fetchingIntervalNs": 0,
+		"lastUpdated": "2019-03-02T12:54:56Z"
+	}`, w.Body.String())
+}
+
 type conversionMock struct {
 	rates *map[string]map[string]float64
 }
@@ -134,7 +134,7 @@ func event(cfg *config.Configuration, fetcher stored_requests.AccountFetcher) st
 		r    *http.Request
 	}{
 		name: "event",
-		h:    NewEventEndpoint(cfg, fetcher, nil),
+		h:    NewEventEndpoint(cfg, fetcher, nil, nil),
 		r:    httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=b&x=1&a=testacc", strings.NewReader("")),
 	}
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/util/httputil"
+	"github.com/prebid/prebid-server/winnotification"
 )
 
 const (
@@ -37,14 +38,16 @@ type eventEndpoint struct {
 	Analytics     analytics.PBSAnalyticsModule
 	Cfg           *config.Configuration
 	TrackingPixel *httputil.Pixel
+	WinNotifier   *winnotification.Notifier
 }
 
-func NewEventEndpoint(cfg *config.Configuration, accounts stored_requests.AccountFetcher, analytics analytics.PBSAnalyticsModule) httprouter.Handle {
+func NewEventEndpoint(cfg *config.Configuration, accounts stored_requests.AccountFetcher, analytics analytics.PBSAnalyticsModule, winNotifier *winnotification.Notifier) httprouter.Handle {
 	ee := &eventEndpoint{
 		Accounts:      accounts,
 		Analytics:     analytics,
 		Cfg:           cfg,
 		TrackingPixel: &httputil.Pixel1x1PNG,
+		WinNotifier:   winNotifier,
 	}
 
 	return ee.Handle
@@ -112,6 +115,11 @@ func (e *eventEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ httprou
 		Account: account,
 	})
 
+	// fire the win notification the auction kept for the bidder
+	if eventRequest.Type == analytics.Win {
+		e.WinNotifier.Notify(eventRequest.AccountID, eventRequest.Bidder, eventRequest.BidID)
+	}
+
 	// Add tracking pixel if format == image
 	if eventRequest.Format == analytics.Image {
 		w.WriteHeader(http.StatusOK)
@@ -126,7 +134,7 @@ func (e *eventEndpoint) Handle(w http.ResponseWriter, r *http.Request, _ httprou
 
 // EventRequestToUrl converts an analytics.EventRequest to an URL
 func EventRequestToUrl(externalUrl string, request *analytics.EventRequest) string {
-	s := fmt.Sprintf(TemplateUrl, externalUrl, request.Type, request.BidID, request.AccountID)
+	s := fmt.Sprintf(TemplateUrl, externalUrl, request.Type, url.QueryEscape(request.BidID), url.QueryEscape(request.AccountID))
 
 	return s + optionalParameters(request)
 }
@@ -249,6 +257,9 @@ func readType(er *analytics.EventRequest, httpRequest *http.Request) error {
 	case string(analytics.Win):
 		er.Type = analytics.Win
 		return nil
+	case string(analytics.Click):
+		er.Type = analytics.Click
+		return nil
 	default:
 		return &errortypes.BadInput{Message: fmt.Sprintf("unknown type: '%s'", t)}
 	}
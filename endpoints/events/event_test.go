@@ -7,6 +7,7 @@ import (
 	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/winnotification"
 	"github.com/stretchr/testify/assert"
 	"io/ioutil"
 	"net/http"
@@ -123,7 +124,7 @@ func TestShouldReturnBadRequestWhenTypeIsMissing(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?b=test", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule)
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -159,7 +160,7 @@ func TestShouldReturnBadRequestWhenTypeIsInvalid(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=test&b=t", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccounts, mockAnalyticsModule)
+	e := NewEventEndpoint(cfg, mockAccounts, mockAnalyticsModule, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -195,7 +196,7 @@ func TestShouldReturnBadRequestWhenBidIdIsMissing(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=win", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule)
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -231,7 +232,7 @@ func TestShouldReturnBadRequestWhenTimestampIsInvalid(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=q", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule)
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -267,7 +268,7 @@ func TestShouldReturnUnauthorizedWhenAccountIsMissing(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule)
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -303,7 +304,7 @@ func TestShouldReturnBadRequestWhenFormatValueIsInvalid(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=q", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule)
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -339,7 +340,7 @@ func TestShouldReturnBadRequestWhenAnalyticsValueIsInvalid(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=b&x=4", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule)
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -378,7 +379,7 @@ func TestShouldNotPassEventToAnalyticsReporterWhenAccountNotFound(t *testing.T)
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=b&x=1&a=testacc", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule)
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -416,7 +417,7 @@ func TestShouldNotPassEventToAnalyticsReporterWhenAccountEventNotEnabled(t *test
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=b&x=1&a=events_disabled", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule)
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -454,7 +455,7 @@ func TestShouldPassEventToAnalyticsReporterWhenAccountEventEnabled(t *testing.T)
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=b&x=1&a=events_enabled", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule)
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -464,6 +465,46 @@ func TestShouldPassEventToAnalyticsReporterWhenAccountEventEnabled(t *testing.T)
 	assert.Equal(t, true, mockAnalyticsModule.Invoked)
 }
 
+func TestShouldFireWinNotificationWhenAccountEventEnabled(t *testing.T) {
+
+	// mock bidder
+	fired := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fired <- r.URL.Path
+	}))
+	defer server.Close()
+
+	// mock win notifier
+	notifier := winnotification.NewNotifier(config.WinNotifications{
+		Enabled:    true,
+		Bidders:    []string{"appnexus"},
+		TTLSeconds: 60,
+		SizeBytes:  512 * 1024,
+		TimeoutMs:  1000,
+	}, server.Client())
+	notifier.Save("events_enabled", "appnexus", "test", server.URL+"/burl")
+
+	// mock config
+	cfg := &config.Configuration{
+		AccountDefaults: config.Account{},
+	}
+	cfg.MarshalAccountDefaults()
+
+	e := NewEventEndpoint(cfg, &mockAccountsFetcher{}, &eventsMockAnalyticsModule{}, notifier)
+
+	// execute an imp event, then a win event
+	for _, eventType := range []string{"imp", "win"} {
+		req := httptest.NewRequest("GET", "/event?t="+eventType+"&b=test&a=events_enabled&bidder=appnexus", strings.NewReader(""))
+		recorder := httptest.NewRecorder()
+		e(recorder, req, nil)
+		assert.Equal(t, 204, recorder.Result().StatusCode)
+	}
+
+	// validate
+	assert.Equal(t, "/burl", <-fired)
+	assert.False(t, notifier.Notify("events_enabled", "appnexus", "test"), "Expected the win notification to be fired once")
+}
+
 func TestShouldNotPassEventToAnalyticsReporterWhenAnalyticsValueIsZero(t *testing.T) {
 
 	// mock AccountsFetcher
@@ -488,7 +529,7 @@ func TestShouldNotPassEventToAnalyticsReporterWhenAnalyticsValueIsZero(t *testin
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=b&x=0&a=events_enabled", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule)
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -522,7 +563,7 @@ func TestShouldRespondWithPixelAndContentTypeWhenRequestFormatIsImage(t *testing
 	req := httptest.NewRequest("GET", "/event?t=win&b=test&ts=1234&f=i&x=1&a=events_enabled", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule)
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -563,7 +604,7 @@ func TestShouldRespondWithNoContentWhenRequestFormatIsNotDefined(t *testing.T) {
 	req := httptest.NewRequest("GET", "/event?t=imp&b=test&ts=1234&x=1&a=events_enabled", strings.NewReader(reqData))
 	recorder := httptest.NewRecorder()
 
-	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule)
+	e := NewEventEndpoint(cfg, mockAccountsFetcher, mockAnalyticsModule, nil)
 
 	// execute
 	e(recorder, req, nil)
@@ -607,6 +648,15 @@ func TestShouldParseEventCorrectly(t *testing.T) {
 				Analytics: analytics.Enabled,
 			},
 		},
+		"click": {
+			req: httptest.NewRequest("GET", "/event?t=click&b=bidId&f=b&a=accountId", strings.NewReader("")),
+			expected: &analytics.EventRequest{
+				Type:      analytics.Click,
+				BidID:     "bidId",
+				Format:    analytics.Blank,
+				Analytics: analytics.Enabled,
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -652,6 +702,15 @@ func TestEventRequestToUrl(t *testing.T) {
 			},
 			want: "http://localhost:8000/event?t=win&b=bidid&a=accountId&bidder=bidder&f=i&ts=1234567&x=0",
 		},
+		"escaped": {
+			er: &analytics.EventRequest{
+				Type:      analytics.Click,
+				BidID:     "bid id&1",
+				AccountID: "account/1",
+				Format:    analytics.Blank,
+			},
+			want: "http://localhost:8000/event?t=click&b=bid+id%261&a=account%2F1&f=b",
+		},
 	}
 
 	for name, test := range tests {
@@ -24,6 +24,16 @@ const (
 	AccountParameter   = "a"
 	ImpressionCloseTag = "</Impression>"
 	ImpressionOpenTag  = "<Impression>"
+
+	ClickTrackingCloseTag = "</ClickTracking>"
+	ClickTrackingOpenTag  = "<ClickTracking>"
+	CreativesOpenTag      = "<Creatives>"
+	InLineCloseTag        = "</InLine>"
+	LinearCloseTag        = "</Linear>"
+	MediaFilesOpenTag     = "<MediaFiles>"
+	VideoClicksCloseTag   = "</VideoClicks>"
+	VideoClicksOpenTag    = "<VideoClicks>"
+	WrapperCloseTag       = "</Wrapper>"
 )
 
 type vtrackEndpoint struct {
@@ -142,6 +152,21 @@ func GetVastUrlTracking(externalUrl string, bidid string, bidder string, account
 	return EventRequestToUrl(externalUrl, eventReq)
 }
 
+// GetVastClickTracking creates a vast url tracking the clicks
+func GetVastClickTracking(externalUrl string, bidid string, bidder string, accountId string, timestamp int64) string {
+
+	eventReq := &analytics.EventRequest{
+		Type:      analytics.Click,
+		BidID:     bidid,
+		AccountID: accountId,
+		Bidder:    bidder,
+		Timestamp: timestamp,
+		Format:    analytics.Blank,
+	}
+
+	return EventRequestToUrl(externalUrl, eventReq)
+}
+
 // ParseVTrackRequest parses a BidCacheRequest from an HTTP Request
 func ParseVTrackRequest(httpRequest *http.Request, maxRequestSize int64) (req *BidCacheRequest, err error) {
 	req = &BidCacheRequest{}
@@ -193,7 +218,7 @@ func ParseVTrackRequest(httpRequest *http.Request, maxRequestSize int64) (req *B
 func (v *vtrackEndpoint) handleVTrackRequest(ctx context.Context, req *BidCacheRequest, account *config.Account) (*BidCacheResponse, []error) {
 	biddersAllowingVastUpdate := getBiddersAllowingVastUpdate(req, &v.BidderInfos, v.Cfg.VTrack.AllowUnknownBidder)
 	// cache data
-	r, errs := v.cachePutObjects(ctx, req, biddersAllowingVastUpdate, account.ID)
+	r, errs := v.cachePutObjects(ctx, req, biddersAllowingVastUpdate, account.ID, account.EventsURL(v.Cfg.EventsURL()))
 
 	// handle pbs caching errors
 	if len(errs) != 0 {
@@ -216,7 +241,7 @@ func (v *vtrackEndpoint) handleVTrackRequest(ctx context.Context, req *BidCacheR
 }
 
 // cachePutObjects caches BidCacheRequest data
-func (v *vtrackEndpoint) cachePutObjects(ctx context.Context, req *BidCacheRequest, biddersAllowingVastUpdate map[string]struct{}, accountId string) ([]string, []error) {
+func (v *vtrackEndpoint) cachePutObjects(ctx context.Context, req *BidCacheRequest, biddersAllowingVastUpdate map[string]struct{}, accountId string, eventsURL string) ([]string, []error) {
 	var cacheables []prebid_cache_client.Cacheable
 
 	for _, c := range req.Puts {
@@ -229,7 +254,7 @@ func (v *vtrackEndpoint) cachePutObjects(ctx context.Context, req *BidCacheReque
 		}
 
 		if _, ok := biddersAllowingVastUpdate[c.Bidder]; ok && nc.Data != nil {
-			nc.Data = ModifyVastXmlJSON(v.Cfg.ExternalURL, nc.Data, c.BidID, c.Bidder, accountId, c.Timestamp)
+			nc.Data = ModifyVastXmlJSON(eventsURL, nc.Data, c.BidID, c.Bidder, accountId, c.Timestamp)
 		}
 
 		cacheables = append(cacheables, *nc)
@@ -268,17 +293,37 @@ func getAccountId(httpRequest *http.Request) string {
 	return httpRequest.URL.Query().Get(AccountParameter)
 }
 
-// ModifyVastXmlString rewrites and returns the string vastXML and a flag indicating if it was modified
+// ModifyVastXmlString rewrites and returns the string vastXML and a flag indicating if it was modified. The impression
+// event is added to the first Impression element, or to the InLine or Wrapper of the ad when it has none, and the click
+// event to the VideoClicks of the Linear creatives.
 func ModifyVastXmlString(externalUrl, vast, bidid, bidder, accountID string, timestamp int64) (string, bool) {
+	impressionUrl := "<![CDATA[" + GetVastUrlTracking(externalUrl, bidid, bidder, accountID, timestamp) + "]]>"
+	vast, impressionAdded := addImpressionTracking(vast, impressionUrl)
+
+	clickUrl := "<![CDATA[" + GetVastClickTracking(externalUrl, bidid, bidder, accountID, timestamp) + "]]>"
+	vast, clickAdded := addClickTracking(vast, clickUrl)
+
+	return vast, impressionAdded || clickAdded
+}
+
+// addImpressionTracking adds the impression url to the first Impression element of the VAST
+func addImpressionTracking(vast, impressionUrl string) (string, bool) {
 	ci := strings.Index(vast, ImpressionCloseTag)
 
-	// no impression tag - pass it as it is
+	// no impression tag - the impression goes before the creatives of the ad, or at the end of it
 	if ci == -1 {
+		impression := ImpressionOpenTag + impressionUrl + ImpressionCloseTag
+		if i := strings.Index(vast, CreativesOpenTag); i != -1 {
+			return vast[:i] + impression + vast[i:], true
+		}
+		for _, closeTag := range []string{InLineCloseTag, WrapperCloseTag} {
+			if i := strings.Index(vast, closeTag); i != -1 {
+				return vast[:i] + impression + vast[i:], true
+			}
+		}
 		return vast, false
 	}
 
-	vastUrlTracking := GetVastUrlTracking(externalUrl, bidid, bidder, accountID, timestamp)
-	impressionUrl := "<![CDATA[" + vastUrlTracking + "]]>"
 	oi := strings.Index(vast, ImpressionOpenTag)
 
 	if ci-oi == len(ImpressionOpenTag) {
@@ -288,6 +333,37 @@ func ModifyVastXmlString(externalUrl, vast, bidid, bidder, accountID string, tim
 	return strings.Replace(vast, ImpressionCloseTag, ImpressionCloseTag+ImpressionOpenTag+impressionUrl+ImpressionCloseTag, 1), true
 }
 
+// addClickTracking adds the click url as a ClickTracking element of each Linear creative of the VAST, creating their
+// VideoClicks when they have none
+func addClickTracking(vast, clickUrl string) (string, bool) {
+	clickTracking := ClickTrackingOpenTag + clickUrl + ClickTrackingCloseTag
+
+	var modified strings.Builder
+	added := false
+	for {
+		end := strings.Index(vast, LinearCloseTag)
+		if end == -1 {
+			modified.WriteString(vast)
+			break
+		}
+		linear := vast[:end]
+		vast = vast[end:]
+
+		if i := strings.Index(linear, VideoClicksCloseTag); i != -1 {
+			linear = linear[:i] + clickTracking + linear[i:]
+		} else if i := strings.Index(linear, MediaFilesOpenTag); i != -1 {
+			linear = linear[:i] + VideoClicksOpenTag + clickTracking + VideoClicksCloseTag + linear[i:]
+		} else {
+			linear += VideoClicksOpenTag + clickTracking + VideoClicksCloseTag
+		}
+		modified.WriteString(linear)
+		modified.WriteString(LinearCloseTag)
+		vast = vast[len(LinearCloseTag):]
+		added = true
+	}
+	return modified.String(), added
+}
+
 // ModifyVastXmlJSON modifies BidCacheRequest element Vast XML data
 func ModifyVastXmlJSON(externalUrl string, data json.RawMessage, bidid, bidder, accountId string, timestamp int64) json.RawMessage {
 	var vast string
@@ -637,6 +637,73 @@ func TestVastUrlShouldReturnExpectedUrl(t *testing.T) {
 	assert.Equal(t, "http://external-url/event?t=imp&b=bidId&a=accountId&bidder=bidder&f=b&ts=1000", url, "Invalid vast url")
 }
 
+func TestVastClickUrlShouldReturnExpectedUrl(t *testing.T) {
+	url := GetVastClickTracking("http://external-url", "bidId", "bidder", "accountId", 1000)
+	assert.Equal(t, "http://external-url/event?t=click&b=bidId&a=accountId&bidder=bidder&f=b&ts=1000", url, "Invalid vast url")
+}
+
+func TestModifyVastXmlString(t *testing.T) {
+	impression := "<![CDATA[http://external-url/event?t=imp&b=bidId&a=accountId&bidder=bidder&f=b&ts=1000]]>"
+	click := "<ClickTracking><![CDATA[http://external-url/event?t=click&b=bidId&a=accountId&bidder=bidder&f=b&ts=1000]]></ClickTracking>"
+
+	testCases := []struct {
+		description      string
+		givenVast        string
+		expectedVast     string
+		expectedModified bool
+	}{
+		{
+			description:      "Impression with content",
+			givenVast:        vastXmlWithImpressionWithContent,
+			expectedVast:     "<VAST version=\"3.0\"><Ad><Wrapper><AdSystem>prebid.org wrapper</AdSystem><VASTAdTagURI><![CDATA[adm2]]></VASTAdTagURI><Impression>content</Impression><Impression>" + impression + "</Impression><Creatives></Creatives></Wrapper></Ad></VAST>",
+			expectedModified: true,
+		},
+		{
+			description:      "Empty impression",
+			givenVast:        vastXmlWithImpressionWithoutContent,
+			expectedVast:     "<VAST version=\"3.0\"><Ad><Wrapper><AdSystem>prebid.org wrapper</AdSystem><VASTAdTagURI><![CDATA[adm2]]></VASTAdTagURI><Impression>" + impression + "</Impression><Creatives></Creatives></Wrapper></Ad></VAST>",
+			expectedModified: true,
+		},
+		{
+			description:      "No impression",
+			givenVast:        vastXmlWithoutImpression,
+			expectedVast:     "<VAST version=\"3.0\"><Ad><Wrapper><AdSystem>prebid.org wrapper</AdSystem><VASTAdTagURI><![CDATA[adm2]]></VASTAdTagURI><Impression>" + impression + "</Impression><Creatives></Creatives></Wrapper></Ad></VAST>",
+			expectedModified: true,
+		},
+		{
+			description:      "No impression nor creatives",
+			givenVast:        "<VAST version=\"3.0\"><Ad><InLine><AdSystem>adserver</AdSystem></InLine></Ad></VAST>",
+			expectedVast:     "<VAST version=\"3.0\"><Ad><InLine><AdSystem>adserver</AdSystem><Impression>" + impression + "</Impression></InLine></Ad></VAST>",
+			expectedModified: true,
+		},
+		{
+			description:      "Linear with video clicks",
+			givenVast:        "<VAST version=\"3.0\"><Ad><InLine><Impression></Impression><Creatives><Creative><Linear><VideoClicks><ClickThrough>http://click.com</ClickThrough></VideoClicks><MediaFiles></MediaFiles></Linear></Creative></Creatives></InLine></Ad></VAST>",
+			expectedVast:     "<VAST version=\"3.0\"><Ad><InLine><Impression>" + impression + "</Impression><Creatives><Creative><Linear><VideoClicks><ClickThrough>http://click.com</ClickThrough>" + click + "</VideoClicks><MediaFiles></MediaFiles></Linear></Creative></Creatives></InLine></Ad></VAST>",
+			expectedModified: true,
+		},
+		{
+			description:      "Linears without video clicks",
+			givenVast:        "<VAST version=\"3.0\"><Ad><InLine><Impression></Impression><Creatives><Creative><Linear><Duration>00:00:15</Duration><MediaFiles></MediaFiles></Linear></Creative><Creative><Linear><TrackingEvents></TrackingEvents></Linear></Creative></Creatives></InLine></Ad></VAST>",
+			expectedVast:     "<VAST version=\"3.0\"><Ad><InLine><Impression>" + impression + "</Impression><Creatives><Creative><Linear><Duration>00:00:15</Duration><VideoClicks>" + click + "</VideoClicks><MediaFiles></MediaFiles></Linear></Creative><Creative><Linear><TrackingEvents></TrackingEvents><VideoClicks>" + click + "</VideoClicks></Linear></Creative></Creatives></InLine></Ad></VAST>",
+			expectedModified: true,
+		},
+		{
+			description:      "Not a VAST ad",
+			givenVast:        "<VAST version=\"3.0\"></VAST>",
+			expectedVast:     "<VAST version=\"3.0\"></VAST>",
+			expectedModified: false,
+		},
+	}
+
+	for _, test := range testCases {
+		vast, modified := ModifyVastXmlString("http://external-url", test.givenVast, "bidId", "bidder", "accountId", 1000)
+
+		assert.Equal(t, test.expectedVast, vast, test.description)
+		assert.Equal(t, test.expectedModified, modified, test.description)
+	}
+}
+
 func getValidVTrackRequestBody(withImpression bool, withContent bool) (string, error) {
 	d, e := getVTrackRequestData(withImpression, withContent)
 
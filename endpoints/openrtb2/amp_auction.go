@@ -11,23 +11,25 @@ import (
 	"strings"
 	"time"
 
-	accountService "github.com/prebid/prebid-server/account"
 	"github.com/prebid/prebid-server/amp"
 	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/gdpr"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/privacy/ccpa"
-	"github.com/prebid/prebid-server/privacy/gdpr"
+	gdprPrivacy "github.com/prebid/prebid-server/privacy/gdpr"
+	"github.com/prebid/prebid-server/privacy/gpp"
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/usersync"
 	"github.com/prebid/prebid-server/util/iputil"
 
 	"github.com/buger/jsonparser"
+	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
@@ -57,6 +59,7 @@ func NewAmpEndpoint(
 	disabledBidders map[string]string,
 	defReqJSON []byte,
 	bidderMap map[string]openrtb_ext.BidderName,
+	gdprPerms gdpr.Permissions,
 ) (httprouter.Handle, error) {
 
 	if ex == nil || validator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil {
@@ -86,7 +89,8 @@ func NewAmpEndpoint(
 		bidderMap,
 		nil,
 		nil,
-		ipValidator}).AmpAuction), nil
+		ipValidator,
+		gdprPerms}).AmpAuction), nil
 
 }
 
@@ -133,7 +137,8 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	w.Header().Set("AMP-Access-Control-Allow-Source-Origin", origin)
 	w.Header().Set("Access-Control-Expose-Headers", "AMP-Access-Control-Allow-Source-Origin")
 
-	req, errL := deps.parseAmpRequest(r)
+	accounts := deps.newAccountLookups()
+	req, errL := deps.parseAmpRequest(r, accounts)
 	ao.Errors = append(ao.Errors, errL...)
 
 	if errortypes.ContainsFatalError(errL) {
@@ -164,7 +169,13 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	}
 	labels.PubID = getAccountID(req.Site.Publisher)
 	// Look up account now that we have resolved the pubID value
-	account, acctIDErrs := accountService.GetAccount(ctx, deps.cfg, deps.accounts, labels.PubID)
+	account, acctIDErrs := accounts.getAccount(ctx, labels.PubID)
+	// AMP only delivers cached ads, whose winning bids are found by their cache keys
+	if len(acctIDErrs) == 0 && account.Targeting.IncludeCache != nil && !*account.Targeting.IncludeCache {
+		acctIDErrs = append(acctIDErrs, &errortypes.BadInput{
+			Message: fmt.Sprintf("The account %s leaves the cache keys out of the targeting, which AMP needs to find the winning bids", account.ID),
+		})
+	}
 	if len(acctIDErrs) > 0 {
 		errL = append(errL, acctIDErrs...)
 		httpStatus := http.StatusBadRequest
@@ -185,6 +196,20 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 		ao.Errors = append(ao.Errors, acctIDErrs...)
 		return
 	}
+	applyDebugToken(account, r)
+	ao.Account = account
+
+	reqWrapper := &openrtb_ext.RequestWrapper{BidRequest: req}
+	if err := deps.setUserID(ctx, w, r, reqWrapper, account, labels.RType, usersyncs); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "Critical error while running the auction: %v", err)
+		if !deps.cfg.Analytics.RequestLog.LogsAllErrors() {
+			glog.Errorf("/openrtb2/amp Critical error: %v", err)
+		}
+		ao.Status = http.StatusInternalServerError
+		ao.Errors = append(ao.Errors, err)
+		return
+	}
 
 	secGPC := r.Header.Get("Sec-GPC")
 
@@ -196,6 +221,7 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 		StartTime:                  start,
 		LegacyLabels:               labels,
 		GlobalPrivacyControlHeader: secGPC,
+		SeatNonBid:                 &ao.SeatNonBid,
 	}
 
 	response, err := deps.ex.HoldAuction(ctx, auctionRequest, nil)
@@ -204,7 +230,9 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, "Critical error while running the auction: %v", err)
-		glog.Errorf("/openrtb2/amp Critical error: %v", err)
+		if !deps.cfg.Analytics.RequestLog.LogsAllErrors() {
+			glog.Errorf("/openrtb2/amp Critical error: %v", err)
+		}
 		ao.Status = http.StatusInternalServerError
 		ao.Errors = append(ao.Errors, err)
 		return
@@ -213,7 +241,7 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 	// Need to extract the targeting parameters from the response, as those are all that
 	// go in the AMP response
 	targets := map[string]string{}
-	byteCache := []byte("\"hb_cache_id")
+	byteCache := []byte("\"" + account.Targeting.WinnerKey(openrtb_ext.HbCacheKey))
 	for _, seatBids := range response.SeatBid {
 		for _, bid := range seatBids.Bid {
 			if bytes.Contains(bid.Ext, byteCache) {
@@ -227,7 +255,9 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 				if err != nil {
 					w.WriteHeader(http.StatusInternalServerError)
 					fmt.Fprintf(w, "Critical error while unpacking AMP targets: %v", err)
-					glog.Errorf("/openrtb2/amp Critical error unpacking targets: %v", err)
+					if !deps.cfg.Analytics.RequestLog.LogsAllErrors() {
+						glog.Errorf("/openrtb2/amp Critical error unpacking targets: %v", err)
+					}
 					ao.Errors = append(ao.Errors, fmt.Errorf("Critical error while unpacking AMP targets: %v", err))
 					ao.Status = http.StatusInternalServerError
 					return
@@ -272,7 +302,9 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 		if extResponse.Debug != nil {
 			ampResponse.Debug = extResponse.Debug
 		} else {
-			glog.Errorf("Test set on request but debug not present in response: %v", err)
+			if !deps.cfg.Analytics.RequestLog.LogsAllErrors() {
+				glog.Errorf("Test set on request but debug not present in response: %v", err)
+			}
 			ao.Errors = append(ao.Errors, fmt.Errorf("Test set on request but debug not present in response: %v", err))
 		}
 	}
@@ -296,13 +328,22 @@ func (deps *endpointDeps) AmpAuction(w http.ResponseWriter, r *http.Request, _ h
 // possible, it will return errors with messages that suggest improvements.
 //
 // If the errors list has at least one element, then no guarantees are made about the returned request.
-func (deps *endpointDeps) parseAmpRequest(httpRequest *http.Request) (req *openrtb2.BidRequest, errs []error) {
+func (deps *endpointDeps) parseAmpRequest(httpRequest *http.Request, accounts *accountLookups) (req *openrtb2.BidRequest, errs []error) {
 	// Load the stored request for the AMP ID.
 	req, e := deps.loadRequestJSONForAmp(httpRequest)
 	if errs = append(errs, e...); errortypes.ContainsFatalError(errs) {
 		return
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(storedRequestTimeoutMillis)*time.Millisecond)
+	defer cancel()
+
+	// Apply the default request of the account under the stored request, now that the account param is applied to it
+	if req, e = mergeAccountDefaultAmpRequest(ctx, req, accounts); len(e) > 0 {
+		errs = append(errs, e...)
+		return
+	}
+
 	// Populate any "missing" OpenRTB fields with info from other sources, (e.g. HTTP request headers).
 	deps.setFieldsImplicitly(httpRequest, req)
 
@@ -314,11 +355,52 @@ func (deps *endpointDeps) parseAmpRequest(httpRequest *http.Request) (req *openr
 
 	// At this point, we should have a valid request that definitely has Targeting and Cache turned on
 
-	e = deps.validateRequest(&openrtb_ext.RequestWrapper{BidRequest: req})
+	reqWrapper := &openrtb_ext.RequestWrapper{BidRequest: req}
+	if err := processInterstitials(reqWrapper); err != nil {
+		errs = append(errs, err)
+		return
+	}
+
+	e = mergeAccountAliases(ctx, reqWrapper, accounts)
+	if errs = append(errs, e...); errortypes.ContainsFatalError(errs) {
+		return
+	}
+
+	e = deps.validateRequest(reqWrapper)
 	errs = append(errs, e...)
+
+	// The account aliases are written back to request.ext, for the exchange to call them
+	if err := reqWrapper.RebuildRequest(); err != nil {
+		errs = append(errs, err)
+	}
 	return
 }
 
+// mergeAccountDefaultAmpRequest returns the AMP request with the default request of its account under it
+func mergeAccountDefaultAmpRequest(ctx context.Context, req *openrtb2.BidRequest, accounts *accountLookups) (*openrtb2.BidRequest, []error) {
+	accountDefReqJSON, err := accountDefaultRequest(ctx, getAccountID(req.Site.Publisher), accounts)
+	if err != nil {
+		return nil, []error{err}
+	}
+	if accountDefReqJSON == nil {
+		return req, nil
+	}
+
+	requestJSON, err := json.Marshal(req)
+	if err != nil {
+		return nil, []error{err}
+	}
+	defaultedJSON, err := jsonpatch.MergePatch(accountDefReqJSON, requestJSON)
+	if err != nil {
+		return nil, []error{err}
+	}
+	defaultedReq := &openrtb2.BidRequest{}
+	if err := json.Unmarshal(defaultedJSON, defaultedReq); err != nil {
+		return nil, []error{err}
+	}
+	return defaultedReq, nil
+}
+
 // Load the stored OpenRTB request for an incoming AMP request, or return the errors found.
 func (deps *endpointDeps) loadRequestJSONForAmp(httpRequest *http.Request) (req *openrtb2.BidRequest, errs []error) {
 	req = &openrtb2.BidRequest{}
@@ -424,19 +506,31 @@ func (deps *endpointDeps) overrideWithParams(ampParams amp.Params, req *openrtb2
 		req.Imp[0].TagID = ampParams.Slot
 	}
 
+	var errs []error
+	gppWriter, gppWriterErr := readGPPPolicy(ampParams.GPP, ampParams.GPPSectionIDs)
+	if gppWriterErr != nil {
+		errs = append(errs, gppWriterErr)
+	}
+	if err := gppWriter.Write(req); err != nil {
+		return append(errs, err)
+	}
+	if err := (gdprPrivacy.AdditionalConsentWriter{Consent: ampParams.AdditionalConsent}).Write(req); err != nil {
+		return append(errs, err)
+	}
+
 	policyWriter, policyWriterErr := readPolicy(ampParams.Consent)
 	if policyWriterErr != nil {
-		return []error{policyWriterErr}
+		return append(errs, policyWriterErr)
 	}
 	if err := policyWriter.Write(req); err != nil {
-		return []error{err}
+		return append(errs, err)
 	}
 
 	if ampParams.Timeout != nil {
 		req.TMax = int64(*ampParams.Timeout) - deps.cfg.AMPTimeoutAdjustment
 	}
 
-	return nil
+	return errs
 }
 
 func makeFormatReplacement(size amp.Size) []openrtb2.Format {
@@ -498,7 +592,9 @@ func defaultRequestExt(req *openrtb2.BidRequest) (errs []error) {
 			// Fixes #452
 			IncludeWinners:    true,
 			IncludeBidderKeys: true,
-			PriceGranularity:  openrtb_ext.PriceGranularityFromString("med"),
+			// hb_format lets the AMP page pick the renderer of the winning bid
+			IncludeFormat:    true,
+			PriceGranularity: openrtb_ext.PriceGranularityFromString("med"),
 		}
 	}
 	if extRequest.Prebid.Cache == nil {
@@ -539,8 +635,8 @@ func readPolicy(consent string) (privacy.PolicyWriter, error) {
 		return privacy.NilPolicyWriter{}, nil
 	}
 
-	if gdpr.ValidateConsent(consent) {
-		return gdpr.ConsentWriter{consent}, nil
+	if gdprPrivacy.ValidateConsent(consent) {
+		return gdprPrivacy.ConsentWriter{consent}, nil
 	}
 
 	if ccpa.ValidateConsent(consent) {
@@ -553,6 +649,27 @@ func readPolicy(consent string) (privacy.PolicyWriter, error) {
 	}
 }
 
+// readGPPPolicy returns the writer of the gpp and gpp_sid params. A GPP string whose header can't be read is left out
+// with a warning, while one with unreadable sections is kept, as its other sections still apply.
+func readGPPPolicy(consent string, sectionIDs []int8) (privacy.PolicyWriter, error) {
+	writer := gpp.ConsentWriter{}
+	for _, id := range sectionIDs {
+		writer.SectionIDs = append(writer.SectionIDs, gpp.SectionID(id))
+	}
+	if len(consent) == 0 {
+		return writer, nil
+	}
+
+	if parsed, errs := gpp.Parse(consent); len(parsed.SectionIDs) == 0 && len(errs) > 0 {
+		return writer, &errortypes.Warning{
+			Message:     fmt.Sprintf("GPP string '%s' is not valid: %v", consent, errs[0]),
+			WarningCode: errortypes.InvalidPrivacyConsentWarningCode,
+		}
+	}
+	writer.Consent = consent
+	return writer, nil
+}
+
 // Sets the effective publisher ID for amp request
 func setEffectiveAmpPubID(req *openrtb2.BidRequest, account string) {
 	var pub *openrtb2.Publisher
@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/buger/jsonparser"
 	"github.com/julienschmidt/httprouter"
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/prebid/prebid-server/analytics"
@@ -50,6 +51,7 @@ func TestGoodAmpRequests(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 
 	for requestID := range goodRequests {
@@ -104,6 +106,7 @@ func TestAMPPageInfo(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 	request := httptest.NewRequest("GET", fmt.Sprintf("/openrtb2/auction/amp?tag_id=1&curl=%s", url.QueryEscape(page)), nil)
 	recorder := httptest.NewRecorder()
@@ -202,6 +205,7 @@ func TestGDPRConsent(t *testing.T) {
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -355,6 +359,7 @@ func TestCCPAConsent(t *testing.T) {
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -390,6 +395,79 @@ func TestCCPAConsent(t *testing.T) {
 	}
 }
 
+func TestGPPAndAdditionalConsent(t *testing.T) {
+	gppString := "DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"
+	testCases := []struct {
+		description      string
+		query            string
+		expectedRegsExt  string
+		expectedUserExt  string
+		expectedWarnings map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage
+	}{
+		{
+			description:     "GPP string, sections and additional consent",
+			query:           "&gpp=" + gppString + "&gpp_sid=2&addtl_consent=1~7.12",
+			expectedRegsExt: `{"gpp":"` + gppString + `","gpp_sid":[2]}`,
+			expectedUserExt: `{"ConsentedProvidersSettings":{"consented_providers":"1~7.12"}}`,
+		},
+		{
+			description:     "Invalid GPP string",
+			query:           "&gpp=invalid&gpp_sid=2",
+			expectedRegsExt: `{"gpp_sid":[2]}`,
+			expectedWarnings: map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage{
+				openrtb_ext.BidderReservedGeneral: {{
+					Code:    10001,
+					Message: "GPP string 'invalid' is not valid: the header is invalid: the type must be 3. Got 34",
+				}},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		bid, err := getTestBidRequest(true, nil, true, nil)
+		if err != nil {
+			t.Fatalf("Failed to marshal the complete openrtb2.BidRequest object %v", err)
+		}
+
+		mockExchange := &mockAmpExchange{}
+		endpoint, _ := NewAmpEndpoint(
+			fakeUUIDGenerator{},
+			mockExchange,
+			newParamsValidator(t),
+			&mockAmpStoredReqFetcher{map[string]json.RawMessage{"1": json.RawMessage(bid)}},
+			empty_fetcher.EmptyFetcher{},
+			&config.Configuration{MaxRequestSize: maxSize},
+			&metricsConfig.DummyMetricsEngine{},
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+			map[string]string{},
+			[]byte{},
+			openrtb_ext.BuildBidderMap(),
+			nil,
+		)
+
+		request := httptest.NewRequest("GET", "/openrtb2/auction/amp?tag_id=1"+test.query, nil)
+		responseRecorder := httptest.NewRecorder()
+		endpoint(responseRecorder, request, nil)
+
+		var response AmpResponse
+		if err := json.Unmarshal(responseRecorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Error unmarshalling response: %s", err.Error())
+		}
+
+		result := mockExchange.lastRequest
+		if !assert.NotNil(t, result, test.description+":lastRequest") {
+			continue
+		}
+		if assert.NotNil(t, result.Regs, test.description+":lastRequest.Regs") {
+			assert.JSONEq(t, test.expectedRegsExt, string(result.Regs.Ext), test.description)
+		}
+		if test.expectedUserExt != "" && assert.NotNil(t, result.User, test.description+":lastRequest.User") {
+			assert.JSONEq(t, test.expectedUserExt, string(result.User.Ext), test.description)
+		}
+		assert.Equal(t, test.expectedWarnings, response.Warnings, test.description)
+	}
+}
+
 func TestConsentWarnings(t *testing.T) {
 	type inputTest struct {
 		regs              *openrtb_ext.ExtRegs
@@ -466,6 +544,7 @@ func TestConsentWarnings(t *testing.T) {
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -559,6 +638,7 @@ func TestNewAndLegacyConsentBothProvided(t *testing.T) {
 			map[string]string{},
 			[]byte{},
 			openrtb_ext.BuildBidderMap(),
+			nil,
 		)
 
 		// Invoke Endpoint
@@ -611,6 +691,7 @@ func TestAMPSiteExt(t *testing.T) {
 		nil,
 		nil,
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 	request, err := http.NewRequest("GET", "/openrtb2/auction/amp?tag_id=1", nil)
 	if !assert.NoError(t, err) {
@@ -628,6 +709,42 @@ func TestAMPSiteExt(t *testing.T) {
 	assert.JSONEq(t, `{"amp":1}`, string(exchange.lastRequest.Site.Ext))
 }
 
+func TestAMPInterstitial(t *testing.T) {
+	stored := map[string]json.RawMessage{
+		"1": json.RawMessage(`{
+			"id": "some-request-id",
+			"site": {"page": "prebid.org"},
+			"device": {"w": 320, "h": 640, "ext": {"prebid": {"interstitial": {"minwidthperc": 60, "minheightperc": 60}}}},
+			"imp": [{"id": "my-imp-id", "instl": 1, "banner": {"format": [{"w": 300, "h": 600}]}, "ext": {"appnexus": {"placementId": 12883451}}}]
+		}`),
+	}
+	exchange := &mockAmpExchange{}
+	endpoint, _ := NewAmpEndpoint(
+		fakeUUIDGenerator{},
+		exchange,
+		newParamsValidator(t),
+		&mockAmpStoredReqFetcher{stored},
+		empty_fetcher.EmptyFetcher{},
+		&config.Configuration{MaxRequestSize: maxSize},
+		&metricsConfig.DummyMetricsEngine{},
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		nil,
+		nil,
+		openrtb_ext.BuildBidderMap(),
+		nil,
+	)
+	request := httptest.NewRequest("GET", "/openrtb2/auction/amp?tag_id=1", nil)
+	recorder := httptest.NewRecorder()
+	endpoint(recorder, request, nil)
+
+	if !assert.NotNil(t, exchange.lastRequest, "Endpoint responded with %d: %s", recorder.Code, recorder.Body.String()) {
+		return
+	}
+	formats := exchange.lastRequest.Imp[0].Banner.Format
+	assert.Len(t, formats, 10)
+	assert.Equal(t, openrtb2.Format{W: 300, H: 600}, formats[0])
+}
+
 // TestBadRequests makes sure we return 400's on bad requests.
 func TestAmpBadRequests(t *testing.T) {
 	files := fetchFiles(t, "sample-requests/invalid-whole")
@@ -648,6 +765,7 @@ func TestAmpBadRequests(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 	for requestID := range badRequests {
 		request := httptest.NewRequest("GET", fmt.Sprintf("/openrtb2/auction/amp?tag_id=%s", requestID), nil)
@@ -679,6 +797,7 @@ func TestAmpDebug(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 
 	for requestID := range requests {
@@ -710,6 +829,176 @@ func TestAmpDebug(t *testing.T) {
 	}
 }
 
+func TestAmpAccountWithoutCacheKeys(t *testing.T) {
+	includeCache := false
+	requests := map[string]json.RawMessage{
+		"1": json.RawMessage(validRequest(t, "site.json")),
+	}
+
+	endpoint, _ := NewAmpEndpoint(
+		fakeUUIDGenerator{},
+		&mockAmpExchange{},
+		newParamsValidator(t),
+		&mockAmpStoredReqFetcher{requests},
+		empty_fetcher.EmptyFetcher{},
+		&config.Configuration{
+			MaxRequestSize:  maxSize,
+			AccountDefaults: config.Account{Targeting: config.AccountTargeting{IncludeCache: &includeCache}},
+		},
+		&metricsConfig.DummyMetricsEngine{},
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		map[string]string{},
+		[]byte{},
+		openrtb_ext.BuildBidderMap(),
+		nil,
+	)
+	request := httptest.NewRequest("GET", "/openrtb2/auction/amp?tag_id=1", nil)
+	recorder := httptest.NewRecorder()
+
+	endpoint(recorder, request, nil)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, "Invalid request format: The account unknown leaves the cache keys out of the targeting, which AMP needs to find the winning bids\n", recorder.Body.String())
+}
+
+func TestAmpAccountDefaultRequest(t *testing.T) {
+	requests := map[string]json.RawMessage{
+		"1": json.RawMessage(validRequest(t, "site.json")),
+	}
+	accounts := map[string]json.RawMessage{
+		"default_acct": json.RawMessage(`{"default_request":{"tmax":800,"bcat":["IAB25"],"site":{"page":"default.com","name":"default"}}}`),
+	}
+
+	cfg := &config.Configuration{MaxRequestSize: maxSize}
+	cfg.MarshalAccountDefaults()
+
+	ex := &mockAmpExchange{}
+	endpoint, _ := NewAmpEndpoint(
+		fakeUUIDGenerator{},
+		ex,
+		newParamsValidator(t),
+		&mockAmpStoredReqFetcher{requests},
+		&macroAccountFetcher{accounts: accounts},
+		cfg,
+		&metricsConfig.DummyMetricsEngine{},
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		map[string]string{},
+		[]byte{},
+		openrtb_ext.BuildBidderMap(),
+		nil,
+	)
+	request := httptest.NewRequest("GET", "/openrtb2/auction/amp?tag_id=1&account=default_acct", nil)
+	recorder := httptest.NewRecorder()
+
+	endpoint(recorder, request, nil)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	if assert.NotNil(t, ex.lastRequest, "The request should reach the exchange") {
+		assert.Equal(t, int64(800), ex.lastRequest.TMax, "The account default request should fill in the missing fields")
+		assert.Equal(t, []string{"IAB25"}, ex.lastRequest.BCat)
+		assert.Equal(t, "test.somepage.com", ex.lastRequest.Site.Page, "The stored request should win over the account default request")
+		assert.Equal(t, "default", ex.lastRequest.Site.Name)
+		assert.Equal(t, "default_acct", ex.lastRequest.Site.Publisher.ID)
+	}
+}
+
+func TestAmpAccountAliases(t *testing.T) {
+	requests := map[string]json.RawMessage{
+		"1": json.RawMessage(`{"id":"some-request-id","site":{"page":"prebid.org"},"imp":[{"id":"some-imp-id","banner":{"format":[{"w":300,"h":250}]},"ext":{"appnexusAlias":{"placementId":12345}}}]}`),
+	}
+	accounts := map[string]json.RawMessage{
+		"alias_acct": json.RawMessage(`{"aliases":{"appnexusAlias":"appnexus"}}`),
+	}
+	cfg := &config.Configuration{MaxRequestSize: maxSize}
+	cfg.MarshalAccountDefaults()
+
+	ex := &mockAmpExchange{}
+	endpoint, _ := NewAmpEndpoint(
+		fakeUUIDGenerator{},
+		ex,
+		newParamsValidator(t),
+		&mockAmpStoredReqFetcher{requests},
+		&macroAccountFetcher{accounts: accounts},
+		cfg,
+		&metricsConfig.DummyMetricsEngine{},
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		map[string]string{},
+		[]byte{},
+		openrtb_ext.BuildBidderMap(),
+		nil,
+	)
+	request := httptest.NewRequest("GET", "/openrtb2/auction/amp?tag_id=1&account=alias_acct", nil)
+	recorder := httptest.NewRecorder()
+
+	endpoint(recorder, request, nil)
+
+	assert.Equal(t, http.StatusOK, recorder.Code, "The account alias should be a known bidder")
+	if assert.NotNil(t, ex.lastRequest, "The request should reach the exchange") {
+		aliases, _, _, err := jsonparser.Get(ex.lastRequest.Ext, "prebid", "aliases")
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"appnexusAlias":"appnexus"}`, string(aliases), "The account aliases should be sent to the exchange")
+	}
+}
+
+func TestAmpAccountDebugToken(t *testing.T) {
+	testCases := []struct {
+		description        string
+		givenToken         string
+		expectedDebugAllow bool
+	}{
+		{
+			description:        "Correct token",
+			givenToken:         "secret-token",
+			expectedDebugAllow: true,
+		},
+		{
+			description:        "Wrong token",
+			givenToken:         "wrong-token",
+			expectedDebugAllow: false,
+		},
+		{
+			description:        "Missing token",
+			givenToken:         "",
+			expectedDebugAllow: false,
+		},
+	}
+
+	requests := map[string]json.RawMessage{
+		"1": json.RawMessage(validRequest(t, "site.json")),
+	}
+
+	for _, test := range testCases {
+		ex := &mockAmpExchange{}
+		endpoint, _ := NewAmpEndpoint(
+			fakeUUIDGenerator{},
+			ex,
+			newParamsValidator(t),
+			&mockAmpStoredReqFetcher{requests},
+			empty_fetcher.EmptyFetcher{},
+			&config.Configuration{
+				MaxRequestSize:  maxSize,
+				AccountDefaults: config.Account{DebugAllow: true, DebugToken: "secret-token"},
+			},
+			&metricsConfig.DummyMetricsEngine{},
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+			map[string]string{},
+			[]byte{},
+			openrtb_ext.BuildBidderMap(),
+			nil,
+		)
+		request := httptest.NewRequest("GET", "/openrtb2/auction/amp?tag_id=1", nil)
+		if test.givenToken != "" {
+			request.Header.Set(exchange.DebugTokenHeader, test.givenToken)
+		}
+		recorder := httptest.NewRecorder()
+
+		endpoint(recorder, request, nil)
+
+		assert.Equal(t, http.StatusOK, recorder.Code, test.description+":status")
+		assert.Equal(t, test.expectedDebugAllow, ex.lastAccount.DebugAllow, test.description+":debug_allow")
+	}
+}
+
 // Prevents #452
 func TestAmpTargetingDefaults(t *testing.T) {
 	req := &openrtb2.BidRequest{}
@@ -730,6 +1019,9 @@ func TestAmpTargetingDefaults(t *testing.T) {
 	if !extRequest.Prebid.Targeting.IncludeBidderKeys {
 		t.Error("AMP defaults should set request.ext.targeting.includebidderkeys to true")
 	}
+	if !extRequest.Prebid.Targeting.IncludeFormat {
+		t.Error("AMP defaults should set request.ext.targeting.includeformat to true")
+	}
 	if !reflect.DeepEqual(extRequest.Prebid.Targeting.PriceGranularity, openrtb_ext.PriceGranularityFromString("med")) {
 		t.Error("AMP defaults should set request.ext.targeting.pricegranularity to medium")
 	}
@@ -752,6 +1044,7 @@ func TestQueryParamOverrides(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 
 	requestID := "1"
@@ -905,6 +1198,7 @@ func (s formatOverrideSpec) execute(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 
 	url := fmt.Sprintf("/openrtb2/auction/amp?tag_id=1&debug=1&w=%d&h=%d&ow=%d&oh=%d&ms=%s&account=%s", s.width, s.height, s.overrideWidth, s.overrideHeight, s.multisize, s.account)
@@ -943,8 +1237,13 @@ func (cf *mockAmpStoredReqFetcher) FetchRequests(ctx context.Context, requestIDs
 	return cf.data, nil, nil
 }
 
+func (cf *mockAmpStoredReqFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	return nil, nil
+}
+
 type mockAmpExchange struct {
 	lastRequest *openrtb2.BidRequest
+	lastAccount config.Account
 }
 
 var expectedErrorsFromHoldAuction map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage = map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage{
@@ -958,6 +1257,7 @@ var expectedErrorsFromHoldAuction map[openrtb_ext.BidderName][]openrtb_ext.ExtBi
 
 func (m *mockAmpExchange) HoldAuction(ctx context.Context, r exchange.AuctionRequest, debugLog *exchange.DebugLog) (*openrtb2.BidResponse, error) {
 	m.lastRequest = r.BidRequest
+	m.lastAccount = r.Account
 
 	response := &openrtb2.BidResponse{
 		SeatBid: []openrtb2.SeatBid{{
@@ -1235,7 +1535,7 @@ func TestBuildAmpObject(t *testing.T) {
 					},
 					AT:   1,
 					TMax: 500,
-					Ext:  json.RawMessage(`{"prebid":{"cache":{"bids":{"returnCreative":null},"vastxml":null},"targeting":{"pricegranularity":{"precision":2,"ranges":[{"min":0,"max":20,"increment":0.1}]},"includewinners":true,"includebidderkeys":true,"includebrandcategory":null,"includeformat":false,"durationrangesec":null,"preferdeals":false}}}`),
+					Ext:  json.RawMessage(`{"prebid":{"cache":{"bids":{"returnCreative":null},"vastxml":null},"targeting":{"pricegranularity":{"precision":2,"ranges":[{"min":0,"max":20,"increment":0.1}]},"includewinners":true,"includebidderkeys":true,"includebrandcategory":null,"includeformat":true,"durationrangesec":null,"preferdeals":false}}}`),
 				},
 				AuctionResponse: &openrtb2.BidResponse{
 					SeatBid: []openrtb2.SeatBid{{
@@ -1345,6 +1645,7 @@ func AmpObjectTestSetup(t *testing.T, inTagId string, inStoredRequest json.RawMe
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 	return &actualAmpObject, endpoint
 }
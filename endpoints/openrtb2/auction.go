@@ -10,7 +10,9 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/buger/jsonparser"
@@ -26,13 +28,18 @@ import (
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/macros"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
 	"github.com/prebid/prebid-server/privacy/ccpa"
+	"github.com/prebid/prebid-server/privacy/gpp"
 	"github.com/prebid/prebid-server/privacy/lmt"
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
+	"github.com/prebid/prebid-server/tracing"
+	"github.com/prebid/prebid-server/trafficshaping"
 	"github.com/prebid/prebid-server/usersync"
 	"github.com/prebid/prebid-server/util/httputil"
 	"github.com/prebid/prebid-server/util/iputil"
@@ -61,6 +68,7 @@ func NewEndpoint(
 	disabledBidders map[string]string,
 	defReqJSON []byte,
 	bidderMap map[string]openrtb_ext.BidderName,
+	gdprPerms gdpr.Permissions,
 ) (httprouter.Handle, error) {
 	if ex == nil || validator == nil || requestsById == nil || accounts == nil || cfg == nil || met == nil {
 		return nil, errors.New("NewEndpoint requires non-nil arguments.")
@@ -89,7 +97,8 @@ func NewEndpoint(
 		bidderMap,
 		nil,
 		nil,
-		ipValidator}).Auction), nil
+		ipValidator,
+		gdprPerms}).Auction), nil
 }
 
 type endpointDeps struct {
@@ -109,6 +118,7 @@ type endpointDeps struct {
 	cache                     prebid_cache_client.Client
 	debugLogRegexp            *regexp.Regexp
 	privateNetworkIPValidator iputil.IPValidator
+	gdprPerms                 gdpr.Permissions
 }
 
 func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -139,14 +149,16 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		deps.analytics.LogAuctionObject(&ao)
 	}()
 
-	req, impExtInfoMap, errL := deps.parseRequest(r)
+	accounts := deps.newAccountLookups()
+	req, impExtInfoMap, errL := deps.parseRequest(r, accounts)
 
 	if errortypes.ContainsFatalError(errL) && writeError(errL, w, &labels) {
 		return
 	}
 	warnings := errortypes.WarningOnly(errL)
 
-	ctx := context.Background()
+	// The auction isn't cancelled with the HTTP request, but is part of its trace
+	ctx := tracing.ContextWithSpan(context.Background(), tracing.SpanFromContext(r.Context()))
 
 	timeout := deps.cfg.AuctionTimeouts.LimitAuctionTimeout(time.Duration(req.TMax) * time.Millisecond)
 	if timeout > 0 {
@@ -155,6 +167,13 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		defer cancel()
 	}
 
+	storedAuctionResponses, storedBidResponses, storedResponseErrs := deps.processStoredResponses(ctx, req)
+	if len(storedResponseErrs) > 0 {
+		errL = append(errL, storedResponseErrs...)
+		writeError(errL, w, &labels)
+		return
+	}
+
 	usersyncs := usersync.ParseCookieFromRequest(r, &(deps.cfg.HostCookie))
 	if req.App != nil {
 		labels.Source = metrics.DemandApp
@@ -169,15 +188,31 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		}
 		labels.PubID = getAccountID(req.Site.Publisher)
 	}
+	// The requests of the dooh channel are counted apart from the web and app ones
+	if isDOOHRequest(req) {
+		labels.RType = metrics.ReqTypeORTB2DOOH
+	}
+
+	tracing.SpanFromContext(ctx).SetAttribute("prebid.account", labels.PubID)
 
 	// Look up account now that we have resolved the pubID value
-	account, acctIDErrs := accountService.GetAccount(ctx, deps.cfg, deps.accounts, labels.PubID)
+	account, acctIDErrs := accounts.getAccount(ctx, labels.PubID)
 	if len(acctIDErrs) > 0 {
 		errL = append(errL, acctIDErrs...)
 		writeError(errL, w, &labels)
 		return
 	}
 
+	if labels.RType == metrics.ReqTypeORTB2DOOH && !account.DOOH.Enabled {
+		errL = append(errL, &errortypes.BlacklistedAcct{
+			Message: fmt.Sprintf("Prebid-server has disabled the dooh requests of Account ID: %s, please reach out to the prebid server host.", account.ID),
+		})
+		writeError(errL, w, &labels)
+		return
+	}
+
+	applyDebugToken(account, r)
+
 	// rebuild/resync the request in the request wrapper.
 	if err := req.RebuildRequest(); err != nil {
 		errL = append(errL, err)
@@ -185,6 +220,12 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		return
 	}
 
+	if err := deps.setUserID(ctx, w, r, req, account, labels.RType, usersyncs); err != nil {
+		errL = append(errL, err)
+		writeError(errL, w, &labels)
+		return
+	}
+
 	secGPC := r.Header.Get("Sec-GPC")
 
 	auctionRequest := exchange.AuctionRequest{
@@ -197,17 +238,23 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 		Warnings:                   warnings,
 		GlobalPrivacyControlHeader: secGPC,
 		ImpExtInfoMap:              impExtInfoMap,
+		StoredAuctionResponses:     storedAuctionResponses,
+		StoredBidResponses:         storedBidResponses,
+		SeatNonBid:                 &ao.SeatNonBid,
 	}
 
 	response, err := deps.ex.HoldAuction(ctx, auctionRequest, nil)
 	ao.Request = req.BidRequest
 	ao.Response = response
 	ao.Account = account
+	ao.TrafficShaping = trafficshaping.Assign(req.ID, account.TrafficShaping)
 	if err != nil {
 		labels.RequestStatus = metrics.RequestStatusErr
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprintf(w, "Critical error while running the auction: %v", err)
-		glog.Errorf("/openrtb2/auction Critical error: %v", err)
+		if !deps.cfg.Analytics.RequestLog.LogsAllErrors() {
+			glog.Errorf("/openrtb2/auction Critical error: %v", err)
+		}
 		ao.Status = http.StatusInternalServerError
 		ao.Errors = append(ao.Errors, err)
 		return
@@ -229,6 +276,14 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 	}
 }
 
+// applyDebugToken disallows the debug output of the accounts protecting it with a token, unless the request sends
+// the matching token
+func applyDebugToken(account *config.Account, r *http.Request) {
+	if account.DebugToken != "" && r.Header.Get(exchange.DebugTokenHeader) != account.DebugToken {
+		account.DebugAllow = false
+	}
+}
+
 // parseRequest turns the HTTP request into an OpenRTB request. This is guaranteed to return:
 //
 //   - A context which times out appropriately, given the request.
@@ -239,7 +294,7 @@ func (deps *endpointDeps) Auction(w http.ResponseWriter, r *http.Request, _ http
 // possible, it will return errors with messages that suggest improvements.
 //
 // If the errors list has at least one element, then no guarantees are made about the returned request.
-func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb_ext.RequestWrapper, impExtInfoMap map[string]exchange.ImpExtInfo, errs []error) {
+func (deps *endpointDeps) parseRequest(httpRequest *http.Request, accounts *accountLookups) (req *openrtb_ext.RequestWrapper, impExtInfoMap map[string]exchange.ImpExtInfo, errs []error) {
 	req = &openrtb_ext.RequestWrapper{}
 	req.BidRequest = &openrtb2.BidRequest{}
 	errs = nil
@@ -263,7 +318,7 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb_
 	}
 
 	timeout := parseTimeout(requestJson, time.Duration(storedRequestTimeoutMillis)*time.Millisecond)
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(tracing.ContextWithSpan(context.Background(), tracing.SpanFromContext(httpRequest.Context())), timeout)
 	defer cancel()
 
 	impInfo, errs := parseImpInfo(requestJson)
@@ -272,7 +327,12 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb_
 	}
 
 	// Fetch the Stored Request data and merge it into the HTTP request.
-	if requestJson, impExtInfoMap, errs = deps.processStoredRequests(ctx, requestJson, impInfo); len(errs) > 0 {
+	if requestJson, impExtInfoMap, errs = deps.processStoredRequests(ctx, requestJson, impInfo, accounts); len(errs) > 0 {
+		return
+	}
+
+	if requestJson, err = downconvertOpenRTB26(requestJson); err != nil {
+		errs = []error{err}
 		return
 	}
 
@@ -281,6 +341,14 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb_
 		return
 	}
 
+	// Client hints are more accurate than the User-Agent header of the caller, so run this before filling in device.ua from it.
+	if errL := normalizeUserAgent(requestJson, req.BidRequest); len(errL) > 0 {
+		errs = append(errs, errL...)
+		if errortypes.ContainsFatalError(errL) {
+			return
+		}
+	}
+
 	// Populate any "missing" OpenRTB fields with info from other sources, (e.g. HTTP request headers).
 	deps.setFieldsImplicitly(httpRequest, req.BidRequest)
 
@@ -291,6 +359,13 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb_
 
 	lmt.ModifyForIOS(req.BidRequest)
 
+	if errL := mergeAccountAliases(ctx, req, accounts); len(errL) > 0 {
+		errs = append(errs, errL...)
+		if errortypes.ContainsFatalError(errL) {
+			return
+		}
+	}
+
 	errL := deps.validateRequest(req)
 	if len(errL) > 0 {
 		errs = append(errs, errL...)
@@ -299,6 +374,143 @@ func (deps *endpointDeps) parseRequest(httpRequest *http.Request) (req *openrtb_
 	return
 }
 
+// accountLookups are the accounts looked up while processing a request, by account id. The stored requests, the
+// account default request, the account aliases and the auction all need the account, which is only looked up once.
+type accountLookups struct {
+	cfg      *config.Configuration
+	fetcher  stored_requests.AccountFetcher
+	accounts map[string]*config.Account
+}
+
+func (deps *endpointDeps) newAccountLookups() *accountLookups {
+	return &accountLookups{cfg: deps.cfg, fetcher: deps.accounts, accounts: make(map[string]*config.Account)}
+}
+
+// getAccount returns the account of the id, looking it up the first time. The failed lookups aren't kept, so that
+// the auction looks the account up again and reports the errors.
+func (l *accountLookups) getAccount(ctx context.Context, accountID string) (*config.Account, []error) {
+	if account, ok := l.accounts[accountID]; ok {
+		return account, nil
+	}
+	account, errs := accountService.GetAccount(ctx, l.cfg, l.fetcher, accountID)
+	if len(errs) == 0 {
+		l.accounts[accountID] = account
+	}
+	return account, errs
+}
+
+// mergeAccountAliases adds the bidder aliases defined in the account config to request.ext.prebid.aliases, so they are
+// validated and resolved exactly like aliases sent with the request. Request aliases win over conflicting account aliases.
+// The account alias overrides are added to request.ext.prebid.aliasoverrides the same way.
+func mergeAccountAliases(ctx context.Context, req *openrtb_ext.RequestWrapper, accounts *accountLookups) []error {
+	var pubID string
+	if req.App != nil {
+		pubID = getAccountID(req.App.Publisher)
+	} else if req.Site != nil {
+		pubID = getAccountID(req.Site.Publisher)
+	} else {
+		return nil
+	}
+
+	// Account lookup errors are reported when the auction fetches the account once the request is validated.
+	account, acctErrs := accounts.getAccount(ctx, pubID)
+	if len(acctErrs) > 0 || (len(account.Aliases) == 0 && len(account.AliasOverrides) == 0) {
+		return nil
+	}
+
+	reqExt, err := req.GetRequestExt()
+	if err != nil {
+		return []error{fmt.Errorf("request.ext is invalid: %v", err)}
+	}
+	reqPrebid := reqExt.GetPrebid()
+	if reqPrebid == nil {
+		reqPrebid = &openrtb_ext.ExtRequestPrebid{}
+	}
+
+	var warnings []error
+	aliases := make(map[string]string, len(account.Aliases)+len(reqPrebid.Aliases))
+	for alias, coreBidder := range account.Aliases {
+		aliases[alias] = coreBidder
+	}
+	for alias, coreBidder := range reqPrebid.Aliases {
+		if accountBidder, ok := aliases[alias]; ok && accountBidder != coreBidder {
+			warnings = append(warnings, &errortypes.Warning{Message: fmt.Sprintf("request.ext.prebid.aliases.%s overrides the account alias to %s with %s", alias, accountBidder, coreBidder)})
+		}
+		aliases[alias] = coreBidder
+	}
+
+	aliasOverrides, err := mergeAccountAliasOverrides(account.AliasOverrides, reqPrebid.AliasOverrides)
+	if err != nil {
+		return []error{err}
+	}
+
+	reqPrebid.Aliases = aliases
+	reqPrebid.AliasOverrides = aliasOverrides
+	reqExt.SetPrebid(reqPrebid)
+	return warnings
+}
+
+// accountDefaultRequest returns the default_request of the account as JSON, or nil if it has none
+func accountDefaultRequest(ctx context.Context, accountID string, accounts *accountLookups) (json.RawMessage, error) {
+	// Account lookup errors are reported when the auction fetches the account once the request is validated.
+	account, acctErrs := accounts.getAccount(ctx, accountID)
+	if len(acctErrs) > 0 || len(account.DefaultRequest) == 0 {
+		return nil, nil
+	}
+
+	defReqJSON, err := json.Marshal(account.DefaultRequest)
+	if err != nil {
+		return nil, fmt.Errorf("the default_request of the account %s is invalid: %v", account.ID, err)
+	}
+	return defReqJSON, nil
+}
+
+// mergeAccountDefaultRequest applies the default request of the account of the request under it, so the request
+// wins over it. The request is returned as it is if the account has no default request.
+func mergeAccountDefaultRequest(ctx context.Context, requestJson []byte, accounts *accountLookups) ([]byte, error) {
+	accountDefReqJSON, err := accountDefaultRequest(ctx, requestAccountID(requestJson), accounts)
+	if err != nil || accountDefReqJSON == nil {
+		return requestJson, err
+	}
+
+	defaultedRequest, err := jsonpatch.MergePatch(accountDefReqJSON, requestJson)
+	if err != nil {
+		if hasErr, Err := getJsonSyntaxError(requestJson); hasErr {
+			err = fmt.Errorf("Invalid JSON in Incoming Request: %s", Err)
+		}
+		return nil, err
+	}
+	return defaultedRequest, nil
+}
+
+// mergeAccountAliasOverrides adds the account alias overrides to the request ones. The request overrides of an alias
+// replace its account overrides as a whole.
+func mergeAccountAliasOverrides(accountOverrides map[string]config.AccountAliasOverride, reqOverrides map[string]*openrtb_ext.ExtAliasOverride) (map[string]*openrtb_ext.ExtAliasOverride, error) {
+	if len(accountOverrides) == 0 {
+		return reqOverrides, nil
+	}
+
+	overrides := make(map[string]*openrtb_ext.ExtAliasOverride, len(accountOverrides)+len(reqOverrides))
+	for alias, accountOverride := range accountOverrides {
+		override := &openrtb_ext.ExtAliasOverride{
+			Endpoint:    accountOverride.Endpoint,
+			GVLVendorID: accountOverride.GVLVendorID,
+		}
+		if len(accountOverride.Params) > 0 {
+			params, err := json.Marshal(accountOverride.Params)
+			if err != nil {
+				return nil, fmt.Errorf("the alias_overrides.%s.params of the account are invalid: %v", alias, err)
+			}
+			override.Params = params
+		}
+		overrides[alias] = override
+	}
+	for alias, reqOverride := range reqOverrides {
+		overrides[alias] = reqOverride
+	}
+	return overrides, nil
+}
+
 // parseTimeout returns parses tmax from the requestJson, or returns the default if it doesn't exist.
 //
 // requestJson should be the content of the POST body.
@@ -342,6 +554,7 @@ func (deps *endpointDeps) validateRequest(req *openrtb_ext.RequestWrapper) []err
 	}
 
 	var aliases map[string]string
+	var aliasOverrides map[string]*openrtb_ext.ExtAliasOverride
 	reqExt, err := req.GetRequestExt()
 	if err != nil {
 		return []error{fmt.Errorf("request.ext is invalid: %v", err)}
@@ -356,10 +569,19 @@ func (deps *endpointDeps) validateRequest(req *openrtb_ext.RequestWrapper) []err
 			return []error{err}
 		}
 
+		aliasOverrides = reqPrebid.AliasOverrides
+		if err := deps.validateAliasOverrides(aliasOverrides, aliases); err != nil {
+			return []error{err}
+		}
+
 		if err := deps.validateBidAdjustmentFactors(reqPrebid.BidAdjustmentFactors, aliases); err != nil {
 			return []error{err}
 		}
 
+		if err := deps.validateBidAdjustments(reqPrebid.BidAdjustments, aliases); err != nil {
+			return []error{err}
+		}
+
 		if err := validateSChains(reqPrebid.SChains); err != nil {
 			return []error{err}
 		}
@@ -368,9 +590,19 @@ func (deps *endpointDeps) validateRequest(req *openrtb_ext.RequestWrapper) []err
 			return []error{err}
 		}
 
+		if err := deps.validateFirstPartyData(reqPrebid, aliases); err != nil {
+			return []error{err}
+		}
+
 		if err := validateCustomRates(reqPrebid.CurrencyConversions); err != nil {
 			return []error{err}
 		}
+
+		if err := validateBidderControls(reqPrebid.BidderControls); err != nil {
+			return []error{err}
+		}
+
+		errL = append(errL, validatePriceGranularityGaps(reqPrebid.Targeting, reqPrebid.BidderControls)...)
 	}
 
 	if (req.Site == nil && req.App == nil) || (req.Site != nil && req.App != nil) {
@@ -393,6 +625,12 @@ func (deps *endpointDeps) validateRequest(req *openrtb_ext.RequestWrapper) []err
 		return append(errL, err)
 	}
 
+	gppWarnings, err := validateGPP(req)
+	if err != nil {
+		return append(errL, err)
+	}
+	errL = append(errL, gppWarnings...)
+
 	if err := validateDevice(req.Device); err != nil {
 		return append(errL, err)
 	}
@@ -421,6 +659,9 @@ func (deps *endpointDeps) validateRequest(req *openrtb_ext.RequestWrapper) []err
 			errL = append(errL, fmt.Errorf(`request.imp[%d].id and request.imp[%d].id are both "%s". Imp IDs must be unique.`, firstIndex, index, imp.ID))
 		}
 		impIDs[imp.ID] = index
+		if err := applyAliasParams(imp, aliasOverrides, index); err != nil {
+			return append(errL, err)
+		}
 		errs := deps.validateImp(imp, aliases, index)
 		if len(errs) > 0 {
 			errL = append(errL, errs...)
@@ -447,20 +688,90 @@ func validateAndFillSourceTID(req *openrtb2.BidRequest) error {
 	return nil
 }
 
-func (deps *endpointDeps) validateBidAdjustmentFactors(adjustmentFactors map[string]float64, aliases map[string]string) error {
+func (deps *endpointDeps) validateBidAdjustmentFactors(adjustmentFactors *openrtb_ext.ExtBidAdjustmentFactors, aliases map[string]string) error {
+	if adjustmentFactors == nil {
+		return nil
+	}
+	if err := deps.validateBidderAdjustmentFactors("request.ext.prebid.bidadjustmentfactors", adjustmentFactors.Bidders, aliases); err != nil {
+		return err
+	}
+	for mediaType, bidderFactors := range adjustmentFactors.MediaTypes {
+		if _, err := openrtb_ext.ParseBidType(string(mediaType)); err != nil {
+			return fmt.Errorf("request.ext.prebid.bidadjustmentfactors.mediatypes.%s is not a known media type", mediaType)
+		}
+		if err := deps.validateBidderAdjustmentFactors("request.ext.prebid.bidadjustmentfactors.mediatypes."+string(mediaType), bidderFactors, aliases); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (deps *endpointDeps) validateBidderAdjustmentFactors(path string, adjustmentFactors map[string]float64, aliases map[string]string) error {
 	for bidderToAdjust, adjustmentFactor := range adjustmentFactors {
 		if adjustmentFactor <= 0 {
-			return fmt.Errorf("request.ext.prebid.bidadjustmentfactors.%s must be a positive number. Got %f", bidderToAdjust, adjustmentFactor)
+			return fmt.Errorf("%s.%s must be a positive number. Got %f", path, bidderToAdjust, adjustmentFactor)
+		}
+		if !deps.isBidderOrAlias(bidderToAdjust, aliases) {
+			return fmt.Errorf("%s.%s is not a known bidder or alias", path, bidderToAdjust)
+		}
+	}
+	return nil
+}
+
+func (deps *endpointDeps) validateBidAdjustments(bidAdjustments *openrtb_ext.ExtRequestPrebidBidAdjustments, aliases map[string]string) error {
+	if bidAdjustments == nil {
+		return nil
+	}
+	for mediaType, bidders := range bidAdjustments.MediaType {
+		if _, err := openrtb_ext.ParseBidType(mediaType); err != nil && mediaType != openrtb_ext.BidAdjustmentWildcard {
+			return fmt.Errorf("request.ext.prebid.bidadjustments.mediatype.%s is not a known media type", mediaType)
+		}
+		for bidder, deals := range bidders {
+			if bidder != openrtb_ext.BidAdjustmentWildcard && !deps.isBidderOrAlias(bidder, aliases) {
+				return fmt.Errorf("request.ext.prebid.bidadjustments.mediatype.%s.%s is not a known bidder or alias", mediaType, bidder)
+			}
+			for dealID, adjustments := range deals {
+				for i, adjustment := range adjustments {
+					path := fmt.Sprintf("request.ext.prebid.bidadjustments.mediatype.%s.%s.%s[%d]", mediaType, bidder, dealID, i)
+					if err := validateBidAdjustment(adjustment); err != nil {
+						return fmt.Errorf("%s %v", path, err)
+					}
+				}
+			}
 		}
-		if _, isBidder := deps.bidderMap[bidderToAdjust]; !isBidder {
-			if _, isAlias := aliases[bidderToAdjust]; !isAlias {
-				return fmt.Errorf("request.ext.prebid.bidadjustmentfactors.%s is not a known bidder or alias", bidderToAdjust)
+	}
+	return nil
+}
+
+func validateBidAdjustment(adjustment openrtb_ext.ExtBidAdjustment) error {
+	switch adjustment.AdjType {
+	case openrtb_ext.BidAdjustmentTypeMultiplier:
+		if adjustment.Value <= 0 {
+			return fmt.Errorf("must have a positive value. Got %f", adjustment.Value)
+		}
+	case openrtb_ext.BidAdjustmentTypeCPM, openrtb_ext.BidAdjustmentTypeStatic:
+		if adjustment.Value < 0 {
+			return fmt.Errorf("must not have a negative value. Got %f", adjustment.Value)
+		}
+		if adjustment.Currency != "" {
+			if _, err := currency.ParseISO(adjustment.Currency); err != nil {
+				return fmt.Errorf("has an invalid currency %s", adjustment.Currency)
 			}
 		}
+	default:
+		return fmt.Errorf("has an unknown adjtype %s. Must be one of %s, %s or %s", adjustment.AdjType, openrtb_ext.BidAdjustmentTypeMultiplier, openrtb_ext.BidAdjustmentTypeCPM, openrtb_ext.BidAdjustmentTypeStatic)
 	}
 	return nil
 }
 
+func (deps *endpointDeps) isBidderOrAlias(bidder string, aliases map[string]string) bool {
+	if _, isBidder := deps.bidderMap[bidder]; isBidder {
+		return true
+	}
+	_, isAlias := aliases[bidder]
+	return isAlias
+}
+
 func validateSChains(sChains []*openrtb_ext.ExtRequestPrebidSChain) error {
 	_, err := exchange.BidderToPrebidSChains(sChains)
 	return err
@@ -468,7 +779,8 @@ func validateSChains(sChains []*openrtb_ext.ExtRequestPrebidSChain) error {
 
 // validateCustomRates throws a bad input error if any of the 3-digit currency codes found in
 // the bidRequest.ext.prebid.currency field is invalid, malfomed or does not represent any actual
-// currency. No error is thrown if bidRequest.ext.prebid.currency is invalid or empty.
+// currency, or if any of its rates isn't a positive number. No error is thrown if
+// bidRequest.ext.prebid.currency is invalid or empty.
 func validateCustomRates(bidReqCurrencyRates *openrtb_ext.ExtRequestCurrency) error {
 	if bidReqCurrencyRates == nil {
 		return nil
@@ -476,20 +788,78 @@ func validateCustomRates(bidReqCurrencyRates *openrtb_ext.ExtRequestCurrency) er
 
 	for fromCurrency, rates := range bidReqCurrencyRates.ConversionRates {
 		// Check if fromCurrency is a valid 3-letter currency code
-		if _, err := currency.ParseISO(fromCurrency); err != nil {
-			return &errortypes.BadInput{Message: fmt.Sprintf("currency code %s is not recognized or malformed", fromCurrency)}
+		if err := validateCustomRateCurrency(fromCurrency); err != nil {
+			return err
 		}
 
-		// Check if currencies mapped to fromCurrency are valid 3-letter currency codes
-		for toCurrency := range rates {
-			if _, err := currency.ParseISO(toCurrency); err != nil {
-				return &errortypes.BadInput{Message: fmt.Sprintf("currency code %s is not recognized or malformed", toCurrency)}
+		// Check if currencies mapped to fromCurrency are valid 3-letter currency codes with a usable rate
+		for toCurrency, rate := range rates {
+			if err := validateCustomRateCurrency(toCurrency); err != nil {
+				return err
+			}
+			if rate <= 0 {
+				return &errortypes.BadInput{Message: fmt.Sprintf("currency rate from %s to %s must be > 0. Got %g", fromCurrency, toCurrency, rate)}
+			}
+			if fromCurrency == toCurrency && rate != 1 {
+				return &errortypes.BadInput{Message: fmt.Sprintf("currency rate from %s to %s must be 1. Got %g", fromCurrency, toCurrency, rate)}
 			}
 		}
 	}
 	return nil
 }
 
+// validateCustomRateCurrency checks that a currency code of the custom rates is the upper case code of a currency,
+// as the rates are looked up by it
+func validateCustomRateCurrency(code string) error {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return &errortypes.BadInput{Message: fmt.Sprintf("currency code %s is not recognized or malformed", code)}
+	}
+	if unit.String() != code {
+		return &errortypes.BadInput{Message: fmt.Sprintf("currency code %s must be upper case", code)}
+	}
+	return nil
+}
+
+// validatePriceGranularityGaps warns about custom price granularity ranges which leave a gap after the previous
+// range, in the price granularities of the request, of its media types and of its bidders. Overlapping and unordered
+// ranges are already rejected when the request ext is parsed.
+func validatePriceGranularityGaps(targeting *openrtb_ext.ExtRequestTargeting, bidderControls map[string]openrtb_ext.ExtBidderControl) []error {
+	if targeting == nil {
+		return nil
+	}
+
+	errL := priceGranularityGaps("request.ext.prebid.targeting.pricegranularity", targeting.PriceGranularity)
+	for _, bidType := range openrtb_ext.BidTypes() {
+		if priceGranularity := targeting.MediaTypePriceGranularity.ForBidType(bidType); priceGranularity != nil {
+			errL = append(errL, priceGranularityGaps("request.ext.prebid.targeting.mediatypepricegranularity."+string(bidType), *priceGranularity)...)
+		}
+	}
+
+	bidders := make([]string, 0, len(bidderControls))
+	for bidder := range bidderControls {
+		bidders = append(bidders, bidder)
+	}
+	sort.Strings(bidders)
+	for _, bidder := range bidders {
+		if priceGranularity := bidderControls[bidder].PriceGranularity; priceGranularity != nil {
+			errL = append(errL, priceGranularityGaps("request.ext.prebid.biddercontrols."+bidder+".pricegranularity", *priceGranularity)...)
+		}
+	}
+	return errL
+}
+
+func priceGranularityGaps(field string, priceGranularity openrtb_ext.PriceGranularity) []error {
+	var errL []error
+	for _, i := range priceGranularity.Gaps() {
+		errL = append(errL, &errortypes.Warning{
+			Message:     fmt.Sprintf("%s.ranges[%d] doesn't start where the previous range ends. Bids priced within the gap won't get a price bucket.", field, i),
+			WarningCode: errortypes.PriceGranularityGapWarningCode,
+		})
+	}
+	return errL
+}
+
 func (deps *endpointDeps) validateEidPermissions(prebid *openrtb_ext.ExtRequestPrebidData, aliases map[string]string) error {
 	if prebid == nil {
 		return nil
@@ -518,6 +888,26 @@ func (deps *endpointDeps) validateEidPermissions(prebid *openrtb_ext.ExtRequestP
 	return nil
 }
 
+func (deps *endpointDeps) validateFirstPartyData(prebid *openrtb_ext.ExtRequestPrebid, aliases map[string]string) error {
+	if prebid.Data != nil && len(prebid.Data.Bidders) > 0 {
+		if err := validateBidders(prebid.Data.Bidders, deps.bidderMap, aliases); err != nil {
+			return fmt.Errorf(`request.ext.prebid.data.bidders contains %v`, err)
+		}
+	}
+
+	for i, bidderConfig := range prebid.BidderConfigs {
+		if len(bidderConfig.Bidders) == 0 {
+			return fmt.Errorf(`request.ext.prebid.bidderconfig[%d] missing or empty required field: "bidders"`, i)
+		}
+
+		if err := validateBidders(bidderConfig.Bidders, deps.bidderMap, aliases); err != nil {
+			return fmt.Errorf(`request.ext.prebid.bidderconfig[%d] contains %v`, i, err)
+		}
+	}
+
+	return nil
+}
+
 func validateBidders(bidders []string, knownBidders map[string]openrtb_ext.BidderName, knownAliases map[string]string) error {
 	for _, bidder := range bidders {
 		if bidder == "*" {
@@ -797,6 +1187,10 @@ func validateNativeAsset(asset nativeRequests.Asset, impIndex int, assetIndex in
 	assetErr := "request.imp[%d].native.request.assets[%d] must define exactly one of {title, img, video, data}"
 	foundType := false
 
+	if asset.Required != 0 && asset.Required != 1 {
+		return fmt.Errorf("request.imp[%d].native.request.assets[%d].required must be 0 or 1", impIndex, assetIndex)
+	}
+
 	if asset.Title != nil {
 		foundType = true
 		if err := validateNativeAssetTitle(asset.Title, impIndex, assetIndex); err != nil {
@@ -886,6 +1280,9 @@ func validateNativeAssetImage(img *nativeRequests.Image, impIndex int, assetInde
 	if img.HMin < 0 {
 		return fmt.Errorf("request.imp[%d].native.request.assets[%d].img.hmin must be a positive integer", impIndex, assetIndex)
 	}
+	if img.Type != 0 && (img.Type < native1.ImageAssetTypeIcon || (img.Type > native1.ImageAssetTypeMain && img.Type < openrtb_ext.NativeExchangeSpecificLowerBound)) {
+		return fmt.Errorf("request.imp[%d].native.request.assets[%d].img.type is invalid. See section 7.5: https://iabtechlab.com/wp-content/uploads/2016/07/OpenRTB-Native-Ads-Specification-Final-1.2.pdf#page=41", impIndex, assetIndex)
+	}
 	return nil
 }
 
@@ -996,8 +1393,8 @@ func (deps *endpointDeps) validateImpExt(imp *openrtb2.Imp, aliases map[string]s
 
 	// Prefer bidder params from request.imp.ext.prebid.bidder.BIDDER over request.imp.ext.BIDDER
 	// to avoid confusion beteween prebid specific adapter config and other ext protocols.
+	var extPrebid openrtb_ext.ExtImpPrebid
 	if extPrebidJSON, ok := bidderExts[openrtb_ext.PrebidExtKey]; ok {
-		var extPrebid openrtb_ext.ExtImpPrebid
 		if err := json.Unmarshal(extPrebidJSON, &extPrebid); err == nil && extPrebid.Bidder != nil {
 			for bidder, ext := range extPrebid.Bidder {
 				if ext == nil {
@@ -1010,6 +1407,7 @@ func (deps *endpointDeps) validateImpExt(imp *openrtb2.Imp, aliases map[string]s
 
 	/* Process all the bidder exts in the request */
 	disabledBidders := []string{}
+	invalidBidders := []string{}
 	otherExtElements := 0
 	for bidder, ext := range bidderExts {
 		if isBidderToValidate(bidder) {
@@ -1018,8 +1416,13 @@ func (deps *endpointDeps) validateImpExt(imp *openrtb2.Imp, aliases map[string]s
 				coreBidder = tmp
 			}
 			if bidderName, isValid := deps.bidderMap[coreBidder]; isValid {
+				// Bidders with invalid params are dropped from the imp rather than called with params they will reject.
 				if err := deps.paramsValidator.Validate(bidderName, ext); err != nil {
-					return []error{fmt.Errorf("request.imp[%d].ext.%s failed validation.\n%v", impIndex, coreBidder, err)}
+					errL = append(errL, &errortypes.Warning{
+						Message:     fmt.Sprintf("request.imp[%d].ext.%s failed validation and was removed from the imp.\n%v", impIndex, bidder, err),
+						WarningCode: errortypes.InvalidBidderParamsWarningCode,
+					})
+					invalidBidders = append(invalidBidders, bidder)
 				}
 			} else {
 				if msg, isDisabled := deps.disabledBidders[bidder]; isDisabled {
@@ -1034,11 +1437,18 @@ func (deps *endpointDeps) validateImpExt(imp *openrtb2.Imp, aliases map[string]s
 		}
 	}
 
-	// defer deleting disabled bidders so we don't disrupt the loop
-	if len(disabledBidders) > 0 {
+	// defer deleting disabled and invalid bidders so we don't disrupt the loop
+	if len(disabledBidders) > 0 || len(invalidBidders) > 0 {
 		for _, bidder := range disabledBidders {
 			delete(bidderExts, bidder)
 		}
+		// Invalid bidders are removed from request.imp.ext.prebid.bidder as well, so the exchange won't call them
+		for _, bidder := range invalidBidders {
+			delete(bidderExts, bidder)
+			if extPrebidJSON, ok := bidderExts[openrtb_ext.PrebidExtKey]; ok {
+				bidderExts[openrtb_ext.PrebidExtKey] = jsonparser.Delete(extPrebidJSON, "bidder", bidder)
+			}
+		}
 		extJSON, err := json.Marshal(bidderExts)
 		if err != nil {
 			return []error{err}
@@ -1046,7 +1456,8 @@ func (deps *endpointDeps) validateImpExt(imp *openrtb2.Imp, aliases map[string]s
 		imp.Ext = extJSON
 	}
 
-	if len(bidderExts)-otherExtElements == 0 {
+	// The bids of an imp with a stored auction response all come from it, so it doesn't need any bidder
+	if len(bidderExts)-otherExtElements == 0 && extPrebid.StoredAuctionResponse == nil {
 		errL = append(errL, fmt.Errorf("request.imp[%d].ext must contain at least one bidder", impIndex))
 	}
 
@@ -1064,6 +1475,8 @@ func isBidderToValidate(bidder string) bool {
 		return false
 	case openrtb_ext.BidderReservedSKAdN:
 		return false
+	case openrtb_ext.BidderReservedTID:
+		return false
 	default:
 		return true
 	}
@@ -1093,6 +1506,67 @@ func (deps *endpointDeps) validateAliases(aliases map[string]string) error {
 	return nil
 }
 
+// validateAliasOverrides checks the overrides apply to aliases of the request. The endpoints are called with the
+// macros of the core bidder, so only their scheme is checked.
+func (deps *endpointDeps) validateAliasOverrides(overrides map[string]*openrtb_ext.ExtAliasOverride, aliases map[string]string) error {
+	for alias, override := range overrides {
+		if override == nil {
+			continue
+		}
+
+		if _, isAlias := aliases[alias]; !isAlias {
+			return fmt.Errorf("request.ext.prebid.aliasoverrides.%s doesn't refer to an alias of request.ext.prebid.aliases", alias)
+		}
+
+		if override.Endpoint != "" {
+			if !deps.cfg.AllowAliasEndpointOverrides {
+				return fmt.Errorf("request.ext.prebid.aliasoverrides.%s.endpoint is not allowed by the host", alias)
+			}
+			if !strings.HasPrefix(override.Endpoint, "http://") && !strings.HasPrefix(override.Endpoint, "https://") {
+				return fmt.Errorf("request.ext.prebid.aliasoverrides.%s.endpoint must be an http or https url", alias)
+			}
+		}
+
+		if len(override.Params) > 0 {
+			var params map[string]json.RawMessage
+			if err := json.Unmarshal(override.Params, &params); err != nil {
+				return fmt.Errorf("request.ext.prebid.aliasoverrides.%s.params must be an object", alias)
+			}
+		}
+	}
+	return nil
+}
+
+// applyAliasParams sets the default params of the aliases on the imps calling them, before the params are validated
+// against the schema of the core bidder. The params of the imp take precedence over the defaults.
+func applyAliasParams(imp *openrtb2.Imp, overrides map[string]*openrtb_ext.ExtAliasOverride, impIndex int) error {
+	for alias, override := range overrides {
+		if override == nil || len(override.Params) == 0 {
+			continue
+		}
+
+		path := []string{openrtb_ext.PrebidExtKey, "bidder", alias}
+		params, dataType, _, err := jsonparser.Get(imp.Ext, path...)
+		if dataType == jsonparser.NotExist {
+			path = []string{alias}
+			params, dataType, _, err = jsonparser.Get(imp.Ext, path...)
+		}
+		// imps not calling the alias are left as is, and malformed params are reported by the imp validation
+		if err != nil || dataType != jsonparser.Object {
+			continue
+		}
+
+		mergedParams, err := jsonpatch.MergePatch(override.Params, params)
+		if err != nil {
+			return fmt.Errorf("request.imp[%d].ext.%s could not be merged with request.ext.prebid.aliasoverrides.%s.params: %v", impIndex, alias, alias, err)
+		}
+		if imp.Ext, err = jsonparser.Set(imp.Ext, mergedParams, path...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (deps *endpointDeps) validateSite(req *openrtb_ext.RequestWrapper) error {
 	if req.Site == nil {
 		return nil
@@ -1203,6 +1677,58 @@ func validateRegs(req *openrtb_ext.RequestWrapper) error {
 	return nil
 }
 
+// validateGPP warns about the parts of the GPP string which can't be read, and about the legacy privacy signals which
+// disagree with it, since the GPP signals take precedence over them.
+func validateGPP(req *openrtb_ext.RequestWrapper) ([]error, error) {
+	gppPolicy, err := gpp.ReadFromRequest(req.BidRequest)
+	if err != nil {
+		return nil, err
+	}
+	gppParsedPolicy, parseErrs := gppPolicy.Parse()
+
+	var warnings []error
+	addWarning := func(message string) {
+		warnings = append(warnings, &errortypes.Warning{
+			Message:     message,
+			WarningCode: errortypes.InvalidPrivacyConsentWarningCode})
+	}
+	for _, err := range parseErrs {
+		addWarning(fmt.Sprintf("The invalid parts of request.regs.ext.gpp will be ignored. (%v)", err))
+	}
+	if gppParsedPolicy.GPP.SectionIDs != nil {
+		for _, id := range gppPolicy.SectionIDs {
+			if _, ok := gppParsedPolicy.GPP.Sections[id]; !ok {
+				addWarning(fmt.Sprintf("request.regs.ext.gpp_sid lists the section %d, which request.regs.ext.gpp doesn't hold.", id))
+			}
+		}
+	}
+
+	regsExt, err := req.GetRegExt()
+	if err != nil {
+		return nil, err
+	}
+	if applies, signaled := gppParsedPolicy.GDPRSignal(); signaled {
+		if gdprJSON, hasGDPR := regsExt.GetExt()["gdpr"]; hasGDPR && (string(gdprJSON) == "1") != applies {
+			addWarning("request.regs.ext.gdpr conflicts with request.regs.ext.gpp_sid, which will be used instead.")
+		}
+	}
+	if tcfConsent, ok := gppParsedPolicy.TCFConsent(); ok {
+		userExt, err := req.GetUserExt()
+		if err != nil {
+			return nil, err
+		}
+		if consent := userExt.GetConsent(); consent != nil && *consent != "" && *consent != tcfConsent {
+			addWarning("request.user.ext.consent conflicts with the TCF EU v2 section of request.regs.ext.gpp, which will be used instead.")
+		}
+	}
+	if usPrivacy, ok := gppParsedPolicy.USPrivacy(); ok {
+		if legacyUSPrivacy := regsExt.GetUSPrivacy(); legacyUSPrivacy != "" && legacyUSPrivacy != usPrivacy {
+			addWarning("request.regs.ext.us_privacy conflicts with request.regs.ext.gpp, which will be used instead.")
+		}
+	}
+	return warnings, nil
+}
+
 func validateDevice(device *openrtb2.Device) error {
 	if device == nil {
 		return nil
@@ -1326,7 +1852,7 @@ func getJsonSyntaxError(testJSON []byte) (bool, string) {
 	return false, ""
 }
 
-func (deps *endpointDeps) processStoredRequests(ctx context.Context, requestJson []byte, impInfo []ImpExtPrebidData) ([]byte, map[string]exchange.ImpExtInfo, []error) {
+func (deps *endpointDeps) processStoredRequests(ctx context.Context, requestJson []byte, impInfo []ImpExtPrebidData, accounts *accountLookups) ([]byte, map[string]exchange.ImpExtInfo, []error) {
 	// Parse the Stored Request IDs from the BidRequest and Imps.
 	storedBidRequestId, hasStoredBidRequest, err := getStoredRequestId(requestJson)
 	if err != nil {
@@ -1351,11 +1877,26 @@ func (deps *endpointDeps) processStoredRequests(ctx context.Context, requestJson
 		}
 	}
 
-	storedRequests, storedImps, errs := deps.storedReqFetcher.FetchRequests(ctx, storedReqIds, impStoredReqIds)
+	fetchCtx, fetchSpan := tracing.StartSpan(ctx, "stored_requests.fetch", tracing.SpanKindInternal)
+	fetchSpan.SetAttribute("prebid.stored_requests", len(storedReqIds))
+	fetchSpan.SetAttribute("prebid.stored_imps", len(impStoredReqIds))
+	storedRequests, storedImps, errs := deps.storedReqFetcher.FetchRequests(fetchCtx, storedReqIds, impStoredReqIds)
+	if len(errs) != 0 {
+		fetchSpan.SetError(errs[0])
+	}
+	fetchSpan.End()
 	if len(errs) != 0 {
 		return nil, nil, errs
 	}
 
+	// Resolve the macros of the Stored Request data from the HTTP request. The fetched maps may not be written to.
+	macroValues := storedRequestMacroValues(ctx, requestJson, storedRequests[storedBidRequestId], storedImps, accounts)
+	if macroValues != nil && hasStoredBidRequest {
+		storedRequests = map[string]json.RawMessage{
+			storedBidRequestId: macros.ReplaceStoredRequestMacros(storedRequests[storedBidRequestId], macroValues),
+		}
+	}
+
 	// Apply the Stored BidRequest, if it exists
 	resolvedRequest := requestJson
 
@@ -1388,6 +1929,12 @@ func (deps *endpointDeps) processStoredRequests(ctx context.Context, requestJson
 		}
 	}
 
+	// Apply the default request of the account under the request, so it wins over the default request of the host
+	resolvedRequest, err = mergeAccountDefaultRequest(ctx, resolvedRequest, accounts)
+	if err != nil {
+		return nil, nil, []error{err}
+	}
+
 	// Apply default aliases, if they are provided
 	if deps.defaultRequest {
 		aliasedRequest, err := jsonpatch.MergePatch(deps.defReqJSON, resolvedRequest)
@@ -1413,14 +1960,18 @@ func (deps *endpointDeps) processStoredRequests(ctx context.Context, requestJson
 	resolvedImps := make([]json.RawMessage, 0, len(impInfo))
 	for i, impData := range impInfo {
 		if impData.ImpExtPrebid.StoredRequest != nil && len(impData.ImpExtPrebid.StoredRequest.ID) > 0 {
-			resolvedImp, err := jsonpatch.MergePatch(storedImps[impData.ImpExtPrebid.StoredRequest.ID], impData.Imp)
+			storedImp := storedImps[impData.ImpExtPrebid.StoredRequest.ID]
+			if macroValues != nil {
+				storedImp = macros.ReplaceStoredRequestMacros(storedImp, impMacroValues(macroValues, impData.Imp))
+			}
+			resolvedImp, err := jsonpatch.MergePatch(storedImp, impData.Imp)
 
 			if err != nil {
 				hasErr, errMessage := getJsonSyntaxError(impData.Imp)
 				if hasErr {
 					err = fmt.Errorf("Invalid JSON in Imp[%d] of Incoming Request: %s", i, errMessage)
 				} else {
-					hasErr, errMessage = getJsonSyntaxError(storedImps[impData.ImpExtPrebid.StoredRequest.ID])
+					hasErr, errMessage = getJsonSyntaxError(storedImp)
 					if hasErr {
 						err = fmt.Errorf("imp.ext.prebid.storedrequest.id %s: Stored Imp has Invalid JSON: %s", impData.ImpExtPrebid.StoredRequest.ID, errMessage)
 					}
@@ -1438,7 +1989,7 @@ func (deps *endpointDeps) processStoredRequests(ctx context.Context, requestJson
 			if impData.ImpExtPrebid.Options != nil {
 				echoVideoAttributes = impData.ImpExtPrebid.Options.EchoVideoAttrs
 			}
-			impExtInfoMap[impId] = exchange.ImpExtInfo{EchoVideoAttrs: echoVideoAttributes, StoredImp: storedImps[impData.ImpExtPrebid.StoredRequest.ID]}
+			impExtInfoMap[impId] = exchange.ImpExtInfo{EchoVideoAttrs: echoVideoAttributes, StoredImp: storedImp}
 
 		} else {
 			resolvedImps = append(resolvedImps, impData.Imp)
@@ -1585,6 +2136,28 @@ func writeError(errs []error, w http.ResponseWriter, labels *metrics.Labels) boo
 	return rc
 }
 
+// validateBidderControls checks that the preferred media types of the bidders are media types of the imps
+func validateBidderControls(bidderControls map[string]openrtb_ext.ExtBidderControl) error {
+	for bidder, control := range bidderControls {
+		switch control.PreferredMediaType {
+		case "", openrtb_ext.BidTypeBanner, openrtb_ext.BidTypeVideo, openrtb_ext.BidTypeAudio, openrtb_ext.BidTypeNative:
+		default:
+			return fmt.Errorf("request.ext.prebid.biddercontrols.%s.prefmtype must be one of banner, video, audio or native. Got %s", bidder, control.PreferredMediaType)
+		}
+	}
+	return nil
+}
+
+// isDOOHRequest tells if the request is in the dooh channel, where the dooh object of the OpenRTB 2.6 requests puts them
+func isDOOHRequest(req *openrtb_ext.RequestWrapper) bool {
+	reqExt, err := req.GetRequestExt()
+	if err != nil {
+		return false
+	}
+	prebid := reqExt.GetPrebid()
+	return prebid != nil && prebid.Channel != nil && prebid.Channel.Name == string(config.IntegrationTypeDOOH)
+}
+
 // Returns the account ID for the request
 func getAccountID(pub *openrtb2.Publisher) string {
 	if pub != nil {
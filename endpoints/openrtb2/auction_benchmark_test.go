@@ -84,6 +84,10 @@ func BenchmarkOpenrtbEndpoint(b *testing.B) {
 		gdpr.AlwaysAllow{},
 		currency.NewRateConverter(&http.Client{}, "", time.Duration(0)),
 		empty_fetcher.EmptyFetcher{},
+		nil,
+		nil,
+		nil,
+		nil,
 	)
 
 	endpoint, _ := NewEndpoint(
@@ -98,6 +102,7 @@ func BenchmarkOpenrtbEndpoint(b *testing.B) {
 		map[string]string{},
 		[]byte{},
 		nil,
+		nil,
 	)
 
 	b.ResetTimer()
@@ -440,7 +440,8 @@ func TestExplicitUserId(t *testing.T) {
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 		map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(),
+		nil)
 
 	endpoint(httptest.NewRecorder(), request, nil)
 
@@ -482,7 +483,8 @@ func doRequest(t *testing.T, test testCase) (int, string) {
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 		disabledBidders,
 		[]byte(test.Config.AliasJSON),
-		bidderMap)
+		bidderMap,
+		nil)
 
 	request := httptest.NewRequest("POST", "/openrtb2/auction", bytes.NewReader(test.BidRequest))
 	recorder := httptest.NewRecorder()
@@ -546,7 +548,8 @@ func doBadAliasRequest(t *testing.T, filename string, expectMsg string) {
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 		disabledBidders,
 		aliasJSON,
-		bidderMap)
+		bidderMap,
+		nil)
 
 	request := httptest.NewRequest("POST", "/openrtb2/auction", bytes.NewReader(testBidRequest))
 	recorder := httptest.NewRecorder()
@@ -596,7 +599,8 @@ func TestNilExchange(t *testing.T) {
 		&metricsConfig.DummyMetricsEngine{},
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}), map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(),
+		nil)
 
 	if err == nil {
 		t.Errorf("NewEndpoint should return an error when given a nil Exchange.")
@@ -618,7 +622,8 @@ func TestNilValidator(t *testing.T) {
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 		map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(),
+		nil)
 
 	if err == nil {
 		t.Errorf("NewEndpoint should return an error when given a nil BidderParamValidator.")
@@ -640,7 +645,8 @@ func TestExchangeError(t *testing.T) {
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 		map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(),
+		nil)
 
 	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
 	recorder := httptest.NewRecorder()
@@ -763,7 +769,8 @@ func TestImplicitIPsEndToEnd(t *testing.T) {
 			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 			map[string]string{},
 			[]byte{},
-			openrtb_ext.BuildBidderMap())
+			openrtb_ext.BuildBidderMap(),
+			nil)
 
 		httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, test.reqJSONFile)))
 		httpReq.Header.Set("X-Forwarded-For", test.xForwardedForHeader)
@@ -958,7 +965,8 @@ func TestImplicitDNTEndToEnd(t *testing.T) {
 			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 			map[string]string{},
 			[]byte{},
-			openrtb_ext.BuildBidderMap())
+			openrtb_ext.BuildBidderMap(),
+			nil)
 
 		httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, test.reqJSONFile)))
 		httpReq.Header.Set("DNT", test.dntHeader)
@@ -1118,6 +1126,7 @@ func TestStoredRequests(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
 	}
 
 	testStoreVideoAttr := []bool{true, true, false, false}
@@ -1125,7 +1134,7 @@ func TestStoredRequests(t *testing.T) {
 	for i, requestData := range testStoredRequests {
 		impInfo, errs := parseImpInfo([]byte(requestData))
 		assert.Len(t, errs, 0, "No errors should be returned")
-		newRequest, impExtInfoMap, errList := deps.processStoredRequests(context.Background(), json.RawMessage(requestData), impInfo)
+		newRequest, impExtInfoMap, errList := deps.processStoredRequests(context.Background(), json.RawMessage(requestData), impInfo, deps.newAccountLookups())
 		if len(errList) != 0 {
 			for _, err := range errList {
 				if err != nil {
@@ -1149,6 +1158,92 @@ func TestStoredRequests(t *testing.T) {
 	}
 }
 
+func TestAccountDefaultRequest(t *testing.T) {
+	accounts := map[string]json.RawMessage{
+		"default_acct": json.RawMessage(`{"default_request":{"tmax":800,"cur":["EUR"],"regs":{"ext":{"gdpr":1}},"ext":{"prebid":{"targeting":{"pricegranularity":"high","includewinners":true}}}}}`),
+		"plain_acct":   json.RawMessage(`{}`),
+	}
+
+	testCases := []struct {
+		description     string
+		givenRequest    string
+		expectedRequest string
+	}{
+		{
+			description:     "Account default request merged under the request",
+			givenRequest:    `{"id":"req","site":{"publisher":{"id":"default_acct"}},"ext":{"prebid":{"targeting":{"pricegranularity":"low"}}},"imp":[{"id":"imp-1"}]}`,
+			expectedRequest: `{"id":"req","tmax":800,"cur":["EUR"],"regs":{"ext":{"gdpr":1}},"at":2,"site":{"publisher":{"id":"default_acct"}},"ext":{"prebid":{"targeting":{"pricegranularity":"low","includewinners":true}}},"imp":[{"id":"imp-1"}]}`,
+		},
+		{
+			description:     "Account without default request",
+			givenRequest:    `{"id":"req","site":{"publisher":{"id":"plain_acct"}},"imp":[{"id":"imp-1"}]}`,
+			expectedRequest: `{"id":"req","tmax":100,"at":2,"site":{"publisher":{"id":"plain_acct"}},"imp":[{"id":"imp-1"}]}`,
+		},
+		{
+			description:     "Unknown account",
+			givenRequest:    `{"id":"req","site":{"publisher":{"id":"unknown_acct"}},"imp":[{"id":"imp-1"}]}`,
+			expectedRequest: `{"id":"req","tmax":100,"at":2,"site":{"publisher":{"id":"unknown_acct"}},"imp":[{"id":"imp-1"}]}`,
+		},
+	}
+
+	cfg := &config.Configuration{}
+	cfg.MarshalAccountDefaults()
+	hostDefReqJSON := []byte(`{"tmax":100,"at":2}`)
+	deps := &endpointDeps{
+		storedReqFetcher: &macroStoredReqFetcher{},
+		accounts:         &macroAccountFetcher{accounts: accounts},
+		cfg:              cfg,
+		defaultRequest:   true,
+		defReqJSON:       hostDefReqJSON,
+	}
+
+	for _, test := range testCases {
+		impInfo, errs := parseImpInfo([]byte(test.givenRequest))
+		assert.Empty(t, errs, test.description+":parse")
+
+		resolvedRequest, _, errs := deps.processStoredRequests(context.Background(), []byte(test.givenRequest), impInfo, deps.newAccountLookups())
+
+		assert.Empty(t, errs, test.description+":errs")
+		assert.JSONEq(t, test.expectedRequest, string(resolvedRequest), test.description+":request")
+	}
+}
+
+func TestAccountLookups(t *testing.T) {
+	cfg := &config.Configuration{AccountRequired: true, AccountDefaults: config.Account{Disabled: true}}
+	cfg.MarshalAccountDefaults()
+	fetcher := &countingAccountFetcher{accounts: map[string]json.RawMessage{"acct": json.RawMessage(`{"disabled":false}`)}}
+	accounts := (&endpointDeps{cfg: cfg, accounts: fetcher}).newAccountLookups()
+
+	first, errs := accounts.getAccount(context.Background(), "acct")
+	assert.Empty(t, errs)
+	second, errs := accounts.getAccount(context.Background(), "acct")
+	assert.Empty(t, errs)
+	assert.Same(t, first, second, "the account is looked up once")
+
+	_, errs = accounts.getAccount(context.Background(), "missing_acct")
+	assert.NotEmpty(t, errs)
+	_, errs = accounts.getAccount(context.Background(), "missing_acct")
+	assert.NotEmpty(t, errs)
+
+	assert.Equal(t, map[string]int{"acct": 1, "missing_acct": 2}, fetcher.fetches, "the failed lookups aren't kept")
+}
+
+type countingAccountFetcher struct {
+	accounts map[string]json.RawMessage
+	fetches  map[string]int
+}
+
+func (f *countingAccountFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
+	if f.fetches == nil {
+		f.fetches = make(map[string]int)
+	}
+	f.fetches[accountID]++
+	if account, ok := f.accounts[accountID]; ok {
+		return account, nil
+	}
+	return nil, []error{stored_requests.NotFoundError{ID: accountID, DataType: "Account"}}
+}
+
 func TestStoredRequestGenerateUuid(t *testing.T) {
 	uuid := "foo"
 
@@ -1169,6 +1264,7 @@ func TestStoredRequestGenerateUuid(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
 	}
 
 	req := &openrtb2.BidRequest{}
@@ -1209,7 +1305,7 @@ func TestStoredRequestGenerateUuid(t *testing.T) {
 		deps.cfg.GenerateRequestID = test.givenGenerateRequestID
 		impInfo, errs := parseImpInfo([]byte(test.givenRawData))
 		assert.Empty(t, errs, test.description)
-		newRequest, _, errList := deps.processStoredRequests(context.Background(), json.RawMessage(test.givenRawData), impInfo)
+		newRequest, _, errList := deps.processStoredRequests(context.Background(), json.RawMessage(test.givenRawData), impInfo, deps.newAccountLookups())
 		assert.Empty(t, errList, test.description)
 
 		if err := json.Unmarshal(newRequest, req); err != nil {
@@ -1239,6 +1335,7 @@ func TestOversizedRequest(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
 	}
 
 	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
@@ -1275,6 +1372,7 @@ func TestRequestSizeEdgeCase(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
 	}
 
 	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
@@ -1305,6 +1403,7 @@ func TestNoEncoding(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
 	recorder := httptest.NewRecorder()
@@ -1381,6 +1480,7 @@ func TestContentType(t *testing.T) {
 		map[string]string{},
 		[]byte{},
 		openrtb_ext.BuildBidderMap(),
+		nil,
 	)
 	request := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "site.json")))
 	recorder := httptest.NewRecorder()
@@ -1474,6 +1574,39 @@ func TestValidateCustomRates(t *testing.T) {
 			},
 			outCurrencyError: &errortypes.BadInput{Message: "currency code FOO is not recognized or malformed"},
 		},
+		{
+			desc: "Lower case currency code, expect bad input error",
+			inBidReqCurrencies: &openrtb_ext.ExtRequestCurrency{
+				ConversionRates: map[string]map[string]float64{
+					"usd": {
+						"MXN": 0.05,
+					},
+				},
+			},
+			outCurrencyError: &errortypes.BadInput{Message: "currency code usd must be upper case"},
+		},
+		{
+			desc: "Rate which isn't positive, expect bad input error",
+			inBidReqCurrencies: &openrtb_ext.ExtRequestCurrency{
+				ConversionRates: map[string]map[string]float64{
+					"USD": {
+						"MXN": 0,
+					},
+				},
+			},
+			outCurrencyError: &errortypes.BadInput{Message: "currency rate from USD to MXN must be > 0. Got 0"},
+		},
+		{
+			desc: "Rate from a currency to itself other than 1, expect bad input error",
+			inBidReqCurrencies: &openrtb_ext.ExtRequestCurrency{
+				ConversionRates: map[string]map[string]float64{
+					"USD": {
+						"USD": 1.2,
+					},
+				},
+			},
+			outCurrencyError: &errortypes.BadInput{Message: "currency rate from USD to USD must be 1. Got 1.2"},
+		},
 		{
 			desc: "All 3-digit currency codes exist, expect no error",
 			inBidReqCurrencies: &openrtb_ext.ExtRequestCurrency{
@@ -1498,6 +1631,95 @@ func TestValidateCustomRates(t *testing.T) {
 	}
 }
 
+func TestValidatePriceGranularityGaps(t *testing.T) {
+	gapRanges := []openrtb_ext.GranularityRange{{Min: 0, Max: 5, Increment: 0.1}, {Min: 6, Max: 10, Increment: 0.5}}
+
+	testCases := []struct {
+		description      string
+		targeting        *openrtb_ext.ExtRequestTargeting
+		bidderControls   map[string]openrtb_ext.ExtBidderControl
+		expectedWarnings []error
+	}{
+		{
+			description: "No targeting",
+		},
+		{
+			description: "Contiguous ranges",
+			targeting: &openrtb_ext.ExtRequestTargeting{PriceGranularity: openrtb_ext.PriceGranularity{
+				Precision: 2,
+				Ranges:    []openrtb_ext.GranularityRange{{Min: 0, Max: 5, Increment: 0.1}, {Min: 5, Max: 10, Increment: 0.5}},
+			}},
+		},
+		{
+			description: "Ranges with a gap",
+			targeting: &openrtb_ext.ExtRequestTargeting{PriceGranularity: openrtb_ext.PriceGranularity{
+				Precision: 2,
+				Ranges:    []openrtb_ext.GranularityRange{{Min: 0, Max: 5, Increment: 0.1}, {Min: 6, Max: 10, Increment: 0.5}},
+			}},
+			expectedWarnings: []error{&errortypes.Warning{
+				Message:     "request.ext.prebid.targeting.pricegranularity.ranges[1] doesn't start where the previous range ends. Bids priced within the gap won't get a price bucket.",
+				WarningCode: errortypes.PriceGranularityGapWarningCode,
+			}},
+		},
+		{
+			description: "Media type and bidder ranges with a gap",
+			targeting: &openrtb_ext.ExtRequestTargeting{
+				PriceGranularity:          openrtb_ext.PriceGranularityFromString("med"),
+				MediaTypePriceGranularity: &openrtb_ext.MediaTypePriceGranularity{Video: &openrtb_ext.PriceGranularity{Precision: 2, Ranges: gapRanges}},
+			},
+			bidderControls: map[string]openrtb_ext.ExtBidderControl{
+				"rubicon":  {PriceGranularity: &openrtb_ext.PriceGranularity{Precision: 2, Ranges: gapRanges}},
+				"appnexus": {PreferredMediaType: openrtb_ext.BidTypeBanner},
+			},
+			expectedWarnings: []error{
+				&errortypes.Warning{
+					Message:     "request.ext.prebid.targeting.mediatypepricegranularity.video.ranges[1] doesn't start where the previous range ends. Bids priced within the gap won't get a price bucket.",
+					WarningCode: errortypes.PriceGranularityGapWarningCode,
+				},
+				&errortypes.Warning{
+					Message:     "request.ext.prebid.biddercontrols.rubicon.pricegranularity.ranges[1] doesn't start where the previous range ends. Bids priced within the gap won't get a price bucket.",
+					WarningCode: errortypes.PriceGranularityGapWarningCode,
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		warnings := validatePriceGranularityGaps(test.targeting, test.bidderControls)
+		assert.Equal(t, test.expectedWarnings, warnings, test.description)
+	}
+}
+
+func TestValidateBidderControls(t *testing.T) {
+	testCases := []struct {
+		description    string
+		bidderControls map[string]openrtb_ext.ExtBidderControl
+		expectedError  string
+	}{
+		{
+			description: "No bidder controls",
+		},
+		{
+			description:    "Valid preferred media types",
+			bidderControls: map[string]openrtb_ext.ExtBidderControl{"appnexus": {PreferredMediaType: openrtb_ext.BidTypeVideo}, "rubicon": {}},
+		},
+		{
+			description:    "Invalid preferred media type",
+			bidderControls: map[string]openrtb_ext.ExtBidderControl{"appnexus": {PreferredMediaType: "display"}},
+			expectedError:  "request.ext.prebid.biddercontrols.appnexus.prefmtype must be one of banner, video, audio or native. Got display",
+		},
+	}
+
+	for _, test := range testCases {
+		err := validateBidderControls(test.bidderControls)
+		if test.expectedError == "" {
+			assert.NoError(t, err, test.description)
+		} else {
+			assert.EqualError(t, err, test.expectedError, test.description)
+		}
+	}
+}
+
 func TestValidateImpExt(t *testing.T) {
 	type testCase struct {
 		description    string
@@ -1616,6 +1838,17 @@ func TestValidateImpExt(t *testing.T) {
 				},
 			},
 		},
+		{
+			"Stored auction response",
+			[]testCase{
+				{
+					description:    "Stored Auction Response without bidders",
+					impExt:         json.RawMessage(`{"prebid":{"storedauctionresponse":{"id":"stored-auction-response"}}}`),
+					expectedImpExt: `{"prebid":{"storedauctionresponse":{"id":"stored-auction-response"}}}`,
+					expectedErrs:   []error{},
+				},
+			},
+		},
 		{
 			"Valid bidder tests",
 			[]testCase{
@@ -1687,6 +1920,41 @@ func TestValidateImpExt(t *testing.T) {
 				},
 			},
 		},
+		{
+			"Invalid bidder params tests",
+			[]testCase{
+				{
+					description:    "Invalid Bidder only",
+					impExt:         json.RawMessage(`{"appnexus":"invalidParams"}`),
+					expectedImpExt: `{}`,
+					expectedErrs: []error{
+						&errortypes.Warning{
+							Message:     "request.imp[0].ext.appnexus failed validation and was removed from the imp.\n(root): Invalid type. Expected: object, given: string",
+							WarningCode: errortypes.InvalidBidderParamsWarningCode,
+						},
+						errors.New("request.imp[0].ext must contain at least one bidder"),
+					},
+				},
+				{
+					description:    "Invalid Bidder + Valid Bidder",
+					impExt:         json.RawMessage(`{"appnexus":"invalidParams","rubicon":{"accountId":1,"siteId":2,"zoneId":3}}`),
+					expectedImpExt: `{"rubicon":{"accountId":1,"siteId":2,"zoneId":3}}`,
+					expectedErrs: []error{&errortypes.Warning{
+						Message:     "request.imp[0].ext.appnexus failed validation and was removed from the imp.\n(root): Invalid type. Expected: object, given: string",
+						WarningCode: errortypes.InvalidBidderParamsWarningCode,
+					}},
+				},
+				{
+					description:    "Invalid Prebid Ext Bidder + Valid Prebid Ext Bidder",
+					impExt:         json.RawMessage(`{"prebid":{"bidder":{"appnexus":"invalidParams","rubicon":{"accountId":1,"siteId":2,"zoneId":3}}}}`),
+					expectedImpExt: `{"prebid":{"bidder":{"rubicon":{"accountId":1,"siteId":2,"zoneId":3}}},"rubicon":{"accountId":1,"siteId":2,"zoneId":3}}`,
+					expectedErrs: []error{&errortypes.Warning{
+						Message:     "request.imp[0].ext.appnexus failed validation and was removed from the imp.\n(root): Invalid type. Expected: object, given: string",
+						WarningCode: errortypes.InvalidBidderParamsWarningCode,
+					}},
+				},
+			},
+		},
 	}
 
 	deps := &endpointDeps{
@@ -1706,6 +1974,7 @@ func TestValidateImpExt(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
 	}
 
 	for _, group := range testGroups {
@@ -1753,6 +2022,7 @@ func TestCurrencyTrunc(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
 	}
 
 	ui := int64(1)
@@ -1798,6 +2068,7 @@ func TestCCPAInvalid(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
 	}
 
 	ui := int64(1)
@@ -1829,6 +2100,84 @@ func TestCCPAInvalid(t *testing.T) {
 	assert.ElementsMatch(t, errL, []error{&expectedWarning})
 }
 
+func TestValidateGPP(t *testing.T) {
+	tcf2Consent := "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"
+
+	testCases := []struct {
+		description      string
+		givenRegsExt     json.RawMessage
+		givenUserExt     json.RawMessage
+		expectedWarnings []string
+		expectedError    bool
+	}{
+		{
+			description: "No GPP",
+		},
+		{
+			description:  "Consistent signals",
+			givenRegsExt: json.RawMessage(`{"gdpr":1,"us_privacy":"1YNN","gpp":"DBACNY~` + tcf2Consent + `~1YNN","gpp_sid":[2,6]}`),
+			givenUserExt: json.RawMessage(`{"consent":"` + tcf2Consent + `"}`),
+		},
+		{
+			description:  "Invalid section",
+			givenRegsExt: json.RawMessage(`{"gpp":"DBACNY~` + tcf2Consent + `~invalid"}`),
+			expectedWarnings: []string{
+				"The invalid parts of request.regs.ext.gpp will be ignored. (section 6 is invalid: it must be a valid us_privacy string)",
+			},
+		},
+		{
+			description:  "Invalid header",
+			givenRegsExt: json.RawMessage(`{"gpp":"invalid","gpp_sid":[2]}`),
+			expectedWarnings: []string{
+				"The invalid parts of request.regs.ext.gpp will be ignored. (the header is invalid: the type must be 3. Got 34)",
+			},
+		},
+		{
+			description:  "Section id missing from the GPP string",
+			givenRegsExt: json.RawMessage(`{"gpp":"DBABTA~1YNN","gpp_sid":[6,7]}`),
+			expectedWarnings: []string{
+				"request.regs.ext.gpp_sid lists the section 7, which request.regs.ext.gpp doesn't hold.",
+			},
+		},
+		{
+			description:  "Conflicting legacy signals",
+			givenRegsExt: json.RawMessage(`{"gdpr":0,"us_privacy":"1NNN","gpp":"DBACNY~` + tcf2Consent + `~1YNN","gpp_sid":[2,6]}`),
+			givenUserExt: json.RawMessage(`{"consent":"BOS2bx5OS2bx5ABABBAAABoAAAAAFA"}`),
+			expectedWarnings: []string{
+				"request.regs.ext.gdpr conflicts with request.regs.ext.gpp_sid, which will be used instead.",
+				"request.user.ext.consent conflicts with the TCF EU v2 section of request.regs.ext.gpp, which will be used instead.",
+				"request.regs.ext.us_privacy conflicts with request.regs.ext.gpp, which will be used instead.",
+			},
+		},
+		{
+			description:   "Invalid section ids",
+			givenRegsExt:  json.RawMessage(`{"gpp_sid":"2"}`),
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		req := &openrtb2.BidRequest{
+			Regs: &openrtb2.Regs{Ext: test.givenRegsExt},
+			User: &openrtb2.User{Ext: test.givenUserExt},
+		}
+
+		warnings, err := validateGPP(&openrtb_ext.RequestWrapper{BidRequest: req})
+
+		if test.expectedError {
+			assert.Error(t, err, test.description)
+			continue
+		}
+		assert.NoError(t, err, test.description)
+
+		var expectedWarnings []error
+		for _, message := range test.expectedWarnings {
+			expectedWarnings = append(expectedWarnings, &errortypes.Warning{Message: message, WarningCode: errortypes.InvalidPrivacyConsentWarningCode})
+		}
+		assert.Equal(t, expectedWarnings, warnings, test.description)
+	}
+}
+
 func TestNoSaleInvalid(t *testing.T) {
 	deps := &endpointDeps{
 		fakeUUIDGenerator{},
@@ -1847,6 +2196,7 @@ func TestNoSaleInvalid(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
 	}
 
 	ui := int64(1)
@@ -1899,6 +2249,7 @@ func TestValidateSourceTID(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
 	}
 
 	ui := int64(1)
@@ -1941,6 +2292,7 @@ func TestSChainInvalid(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
 	}
 
 	ui := int64(1)
@@ -2161,6 +2513,7 @@ func TestEidPermissionsInvalid(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
 	}
 
 	ui := int64(1)
@@ -2286,6 +2639,64 @@ func TestValidateEidPermissions(t *testing.T) {
 	}
 }
 
+func TestValidateFirstPartyData(t *testing.T) {
+	knownBidders := map[string]openrtb_ext.BidderName{"a": openrtb_ext.BidderName("a")}
+	knownAliases := map[string]string{"b": "b"}
+
+	testCases := []struct {
+		description   string
+		prebid        openrtb_ext.ExtRequestPrebid
+		expectedError error
+	}{
+		{
+			description: "Valid - None",
+			prebid:      openrtb_ext.ExtRequestPrebid{Data: &openrtb_ext.ExtRequestPrebidData{}},
+		},
+		{
+			description: "Valid - Data bidders and bidder configs",
+			prebid: openrtb_ext.ExtRequestPrebid{
+				Data: &openrtb_ext.ExtRequestPrebidData{Bidders: []string{"a", "b"}},
+				BidderConfigs: []openrtb_ext.BidderConfig{
+					{Bidders: []string{"a"}},
+					{Bidders: []string{"*"}},
+				},
+			},
+		},
+		{
+			description: "Invalid - Invalid data bidders",
+			prebid: openrtb_ext.ExtRequestPrebid{
+				Data: &openrtb_ext.ExtRequestPrebidData{Bidders: []string{"a", "z"}},
+			},
+			expectedError: errors.New(`request.ext.prebid.data.bidders contains unrecognized bidder "z"`),
+		},
+		{
+			description: "Invalid - Missing bidder config bidders",
+			prebid: openrtb_ext.ExtRequestPrebid{
+				BidderConfigs: []openrtb_ext.BidderConfig{
+					{Bidders: []string{"a"}},
+					{},
+				},
+			},
+			expectedError: errors.New(`request.ext.prebid.bidderconfig[1] missing or empty required field: "bidders"`),
+		},
+		{
+			description: "Invalid - Invalid bidder config bidders",
+			prebid: openrtb_ext.ExtRequestPrebid{
+				BidderConfigs: []openrtb_ext.BidderConfig{
+					{Bidders: []string{"*", "a"}},
+				},
+			},
+			expectedError: errors.New(`request.ext.prebid.bidderconfig[0] contains bidder wildcard "*" mixed with specific bidders`),
+		},
+	}
+
+	endpoint := &endpointDeps{bidderMap: knownBidders}
+	for _, test := range testCases {
+		result := endpoint.validateFirstPartyData(&test.prebid, knownAliases)
+		assert.Equal(t, test.expectedError, result, test.description)
+	}
+}
+
 func TestValidateBidders(t *testing.T) {
 	testCases := []struct {
 		description   string
@@ -2407,7 +2818,8 @@ func TestIOS14EndToEnd(t *testing.T) {
 		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
 		map[string]string{},
 		[]byte{},
-		openrtb_ext.BuildBidderMap())
+		openrtb_ext.BuildBidderMap(),
+		nil)
 
 	httpReq := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(validRequest(t, "app-ios140-no-ifa.json")))
 
@@ -2422,6 +2834,45 @@ func TestIOS14EndToEnd(t *testing.T) {
 	assert.Equal(t, &lmtOne, result.Device.Lmt)
 }
 
+func TestAuctionInvalidBidderParams(t *testing.T) {
+	reqBody := `{"id":"some-request-id","site":{"page":"prebid.org"},"imp":[{"id":"some-imp-id","banner":{"format":[{"w":300,"h":250}]},` +
+		`"ext":{"appnexus":"invalidParams","rubicon":{"accountId":1,"siteId":2,"zoneId":3}}}]}`
+	deps := &endpointDeps{
+		fakeUUIDGenerator{},
+		&warningsCheckExchange{},
+		newParamsValidator(t),
+		&mockStoredReqFetcher{},
+		empty_fetcher.EmptyFetcher{},
+		empty_fetcher.EmptyFetcher{},
+		&config.Configuration{MaxRequestSize: int64(len(reqBody))},
+		&metricsConfig.DummyMetricsEngine{},
+		analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+		map[string]string{},
+		false,
+		[]byte{},
+		openrtb_ext.BuildBidderMap(),
+		nil,
+		nil,
+		hardcodedResponseIPValidator{response: true},
+		nil,
+	}
+
+	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
+	recorder := httptest.NewRecorder()
+
+	deps.Auction(recorder, req, nil)
+
+	assert.Equal(t, http.StatusOK, recorder.Code, "The request should go on with the valid bidder")
+	auctionRequest := deps.ex.(*warningsCheckExchange).auctionRequest
+	if assert.NotNil(t, auctionRequest.BidRequest, "The valid bidder should be called") {
+		assert.JSONEq(t, `{"rubicon":{"accountId":1,"siteId":2,"zoneId":3}}`, string(auctionRequest.BidRequest.Imp[0].Ext))
+	}
+	assert.Equal(t, []error{&errortypes.Warning{
+		Message:     "request.imp[0].ext.appnexus failed validation and was removed from the imp.\n(root): Invalid type. Expected: object, given: string",
+		WarningCode: errortypes.InvalidBidderParamsWarningCode,
+	}}, auctionRequest.Warnings)
+}
+
 func TestAuctionWarnings(t *testing.T) {
 	reqBody := validRequest(t, "us-privacy-invalid.json")
 	deps := &endpointDeps{
@@ -2441,6 +2892,7 @@ func TestAuctionWarnings(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
 	}
 
 	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
@@ -2462,6 +2914,152 @@ func TestAuctionWarnings(t *testing.T) {
 	assert.Equal(t, errortypes.InvalidPrivacyConsentWarningCode, actualWarning.WarningCode, "Warning code is incorrect")
 }
 
+func TestAuctionAccountDebugToken(t *testing.T) {
+	testCases := []struct {
+		description        string
+		givenToken         string
+		expectedDebugAllow bool
+	}{
+		{
+			description:        "Correct token",
+			givenToken:         "secret-token",
+			expectedDebugAllow: true,
+		},
+		{
+			description:        "Wrong token",
+			givenToken:         "wrong-token",
+			expectedDebugAllow: false,
+		},
+		{
+			description:        "Missing token",
+			givenToken:         "",
+			expectedDebugAllow: false,
+		},
+	}
+
+	reqBody := `{"id":"some-request-id","test":1,"site":{"page":"prebid.org","publisher":{"id":"debug_token_acct"}},` +
+		`"imp":[{"id":"some-imp-id","banner":{"format":[{"w":300,"h":250}]},"ext":{"appnexus":{"placementId":12345}}}]}`
+
+	for _, test := range testCases {
+		cfg := &config.Configuration{MaxRequestSize: int64(len(reqBody))}
+		cfg.MarshalAccountDefaults()
+
+		deps := &endpointDeps{
+			fakeUUIDGenerator{},
+			&warningsCheckExchange{},
+			newParamsValidator(t),
+			&mockStoredReqFetcher{},
+			empty_fetcher.EmptyFetcher{},
+			&mockAccountFetcher{},
+			cfg,
+			&metricsConfig.DummyMetricsEngine{},
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+			map[string]string{},
+			false,
+			[]byte{},
+			openrtb_ext.BuildBidderMap(),
+			nil,
+			nil,
+			hardcodedResponseIPValidator{response: true},
+			nil,
+		}
+
+		req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
+		if test.givenToken != "" {
+			req.Header.Set(exchange.DebugTokenHeader, test.givenToken)
+		}
+		recorder := httptest.NewRecorder()
+
+		deps.Auction(recorder, req, nil)
+
+		assert.Equal(t, http.StatusOK, recorder.Code, test.description+":status")
+		assert.Equal(t, test.expectedDebugAllow, deps.ex.(*warningsCheckExchange).auctionRequest.Account.DebugAllow, test.description+":debug_allow")
+	}
+}
+
+func TestAuctionDOOH(t *testing.T) {
+	testCases := []struct {
+		description         string
+		givenRequest        string
+		expectedStatus      int
+		expectedRequestType metrics.RequestType
+		expectedSiteID      string
+		expectedSiteExt     string
+	}{
+		{
+			description:         "DOOH object",
+			givenRequest:        `{"id":"req","dooh":{"id":"screen-1","venuetype":["transit"],"publisher":{"id":"valid_acct"}},`,
+			expectedStatus:      http.StatusOK,
+			expectedRequestType: metrics.ReqTypeORTB2DOOH,
+			expectedSiteID:      "screen-1",
+			expectedSiteExt:     `{"amp":0,"venuetype":["transit"]}`,
+		},
+		{
+			description:         "Site of the dooh channel",
+			givenRequest:        `{"id":"req","site":{"id":"screen-1","publisher":{"id":"valid_acct"}},"ext":{"prebid":{"channel":{"name":"dooh"}}},`,
+			expectedStatus:      http.StatusOK,
+			expectedRequestType: metrics.ReqTypeORTB2DOOH,
+			expectedSiteID:      "screen-1",
+			expectedSiteExt:     `{"amp":0}`,
+		},
+		{
+			description:         "Site",
+			givenRequest:        `{"id":"req","site":{"id":"site-1","publisher":{"id":"valid_acct"}},`,
+			expectedStatus:      http.StatusOK,
+			expectedRequestType: metrics.ReqTypeORTB2Web,
+			expectedSiteID:      "site-1",
+			expectedSiteExt:     `{"amp":0}`,
+		},
+		{
+			description:    "DOOH disabled for the account",
+			givenRequest:   `{"id":"req","dooh":{"id":"screen-1","publisher":{"id":"dooh_disabled_acct"}},`,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, test := range testCases {
+		reqBody := test.givenRequest + `"imp":[{"id":"some-imp-id","banner":{"format":[{"w":300,"h":250}]},"ext":{"appnexus":{"placementId":12345}}}]}`
+		cfg := &config.Configuration{MaxRequestSize: int64(len(reqBody))}
+		cfg.AccountDefaults.DOOH.Enabled = true
+		cfg.MarshalAccountDefaults()
+
+		ex := &warningsCheckExchange{}
+		deps := &endpointDeps{
+			fakeUUIDGenerator{},
+			ex,
+			newParamsValidator(t),
+			&mockStoredReqFetcher{},
+			empty_fetcher.EmptyFetcher{},
+			&mockAccountFetcher{},
+			cfg,
+			&metricsConfig.DummyMetricsEngine{},
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+			map[string]string{},
+			false,
+			[]byte{},
+			openrtb_ext.BuildBidderMap(),
+			nil,
+			nil,
+			hardcodedResponseIPValidator{response: true},
+			nil,
+		}
+
+		req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
+		recorder := httptest.NewRecorder()
+
+		deps.Auction(recorder, req, nil)
+
+		assert.Equal(t, test.expectedStatus, recorder.Code, test.description+":status")
+		if test.expectedStatus != http.StatusOK {
+			assert.Contains(t, recorder.Body.String(), "Prebid-server has disabled the dooh requests of Account ID: dooh_disabled_acct", test.description+":body")
+			continue
+		}
+		assert.Equal(t, test.expectedRequestType, ex.auctionRequest.RequestType, test.description+":request type")
+		assert.Equal(t, test.expectedSiteID, ex.auctionRequest.BidRequest.Site.ID, test.description+":site.id")
+		assert.JSONEq(t, test.expectedSiteExt, string(ex.auctionRequest.BidRequest.Site.Ext), test.description+":site.ext")
+	}
+}
+
 func TestParseRequestParseImpInfoError(t *testing.T) {
 	reqBody := validRequest(t, "imp-info-invalid.json")
 	deps := &endpointDeps{
@@ -2481,11 +3079,12 @@ func TestParseRequestParseImpInfoError(t *testing.T) {
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
 	}
 
 	req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
 
-	resReq, impExtInfoMap, errL := deps.parseRequest(req)
+	resReq, impExtInfoMap, errL := deps.parseRequest(req, deps.newAccountLookups())
 
 	assert.Nil(t, resReq, "Result request should be nil due to incorrect imp")
 	assert.Nil(t, impExtInfoMap, "Impression info map should be nil due to incorrect imp")
@@ -2493,6 +3092,246 @@ func TestParseRequestParseImpInfoError(t *testing.T) {
 	assert.Contains(t, errL[0].Error(), "echovideoattrs of type bool", "Incorrect error message")
 }
 
+func TestParseRequestAccountAliases(t *testing.T) {
+	testCases := []struct {
+		description      string
+		givenRequestExt  string
+		givenImpExt      string
+		expectedAliases  map[string]string
+		expectedWarnings []error
+	}{
+		{
+			description:      "Account alias used without request aliases",
+			givenRequestExt:  `{}`,
+			givenImpExt:      `{"appnexusAlias":{"placementId":12345}}`,
+			expectedAliases:  map[string]string{"appnexusAlias": "appnexus"},
+			expectedWarnings: nil,
+		},
+		{
+			description:     "Request alias overrides conflicting account alias",
+			givenRequestExt: `{"prebid":{"aliases":{"appnexusAlias":"rubicon"}}}`,
+			givenImpExt:     `{"appnexusAlias":{"accountId":1,"siteId":2,"zoneId":3}}`,
+			expectedAliases: map[string]string{"appnexusAlias": "rubicon"},
+			expectedWarnings: []error{&errortypes.Warning{
+				Message: "request.ext.prebid.aliases.appnexusAlias overrides the account alias to appnexus with rubicon",
+			}},
+		},
+	}
+
+	for _, test := range testCases {
+		reqBody := `{"id":"some-request-id","site":{"page":"prebid.org","publisher":{"id":"alias_acct"}},` +
+			`"imp":[{"id":"some-imp-id","banner":{"format":[{"w":300,"h":250}]},"ext":` + test.givenImpExt + `}],` +
+			`"ext":` + test.givenRequestExt + `}`
+
+		cfg := &config.Configuration{MaxRequestSize: int64(len(reqBody))}
+		cfg.MarshalAccountDefaults()
+
+		deps := &endpointDeps{
+			fakeUUIDGenerator{},
+			&warningsCheckExchange{},
+			newParamsValidator(t),
+			&mockStoredReqFetcher{},
+			empty_fetcher.EmptyFetcher{},
+			&mockAccountFetcher{},
+			cfg,
+			&metricsConfig.DummyMetricsEngine{},
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+			map[string]string{},
+			false,
+			[]byte{},
+			openrtb_ext.BuildBidderMap(),
+			nil,
+			nil,
+			hardcodedResponseIPValidator{response: true},
+			nil,
+		}
+
+		req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
+
+		resReq, _, errL := deps.parseRequest(req, deps.newAccountLookups())
+
+		assert.Equal(t, test.expectedWarnings, errL, test.description+":errors")
+		if assert.NotNil(t, resReq, test.description+":request") {
+			reqExt, err := resReq.GetRequestExt()
+			assert.NoError(t, err, test.description+":ext")
+			if assert.NotNil(t, reqExt.GetPrebid(), test.description+":ext.prebid") {
+				assert.Equal(t, test.expectedAliases, reqExt.GetPrebid().Aliases, test.description+":aliases")
+			}
+		}
+	}
+}
+
+func TestParseRequestAccountAliasOverrides(t *testing.T) {
+	testCases := []struct {
+		description            string
+		givenRequestExt        string
+		givenImpExt            string
+		expectedImpExt         string
+		expectedAliasOverrides map[string]*openrtb_ext.ExtAliasOverride
+	}{
+		{
+			description:     "Account alias override params set on the imp",
+			givenRequestExt: `{}`,
+			givenImpExt:     `{"appnexusAlias":{"keywords":[{"key":"genre"}]}}`,
+			expectedImpExt:  `{"appnexusAlias":{"keywords":[{"key":"genre"}],"placementId":12345}}`,
+			expectedAliasOverrides: map[string]*openrtb_ext.ExtAliasOverride{
+				"appnexusAlias": {GVLVendorID: 42, Params: json.RawMessage(`{"placementId":12345}`)},
+			},
+		},
+		{
+			description:     "Request alias overrides replace the account ones",
+			givenRequestExt: `{"prebid":{"aliasoverrides":{"appnexusAlias":{"params":{"placementId":67890}}}}}`,
+			givenImpExt:     `{"prebid":{"bidder":{"appnexusAlias":{}}}}`,
+			expectedImpExt:  `{"prebid":{"bidder":{"appnexusAlias":{"placementId":67890}}}}`,
+			expectedAliasOverrides: map[string]*openrtb_ext.ExtAliasOverride{
+				"appnexusAlias": {Params: json.RawMessage(`{"placementId":67890}`)},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		reqBody := `{"id":"some-request-id","site":{"page":"prebid.org","publisher":{"id":"alias_override_acct"}},` +
+			`"imp":[{"id":"some-imp-id","banner":{"format":[{"w":300,"h":250}]},"ext":` + test.givenImpExt + `}],` +
+			`"ext":` + test.givenRequestExt + `}`
+
+		cfg := &config.Configuration{MaxRequestSize: int64(len(reqBody))}
+		cfg.MarshalAccountDefaults()
+
+		deps := &endpointDeps{
+			fakeUUIDGenerator{},
+			&warningsCheckExchange{},
+			newParamsValidator(t),
+			&mockStoredReqFetcher{},
+			empty_fetcher.EmptyFetcher{},
+			&mockAccountFetcher{},
+			cfg,
+			&metricsConfig.DummyMetricsEngine{},
+			analyticsConf.NewPBSAnalytics(&config.Analytics{}),
+			map[string]string{},
+			false,
+			[]byte{},
+			openrtb_ext.BuildBidderMap(),
+			nil,
+			nil,
+			hardcodedResponseIPValidator{response: true},
+			nil,
+		}
+
+		req := httptest.NewRequest("POST", "/openrtb2/auction", strings.NewReader(reqBody))
+
+		resReq, _, errL := deps.parseRequest(req, deps.newAccountLookups())
+
+		assert.Empty(t, errL, test.description+":errors")
+		if assert.NotNil(t, resReq, test.description+":request") {
+			assert.JSONEq(t, test.expectedImpExt, string(resReq.Imp[0].Ext), test.description+":imp.ext")
+			reqExt, err := resReq.GetRequestExt()
+			assert.NoError(t, err, test.description+":ext")
+			if assert.NotNil(t, reqExt.GetPrebid(), test.description+":ext.prebid") {
+				assert.Equal(t, test.expectedAliasOverrides, reqExt.GetPrebid().AliasOverrides, test.description+":aliasoverrides")
+			}
+		}
+	}
+}
+
+func TestValidateAliasOverrides(t *testing.T) {
+	aliases := map[string]string{"appnexusAlias": "appnexus"}
+
+	testCases := []struct {
+		description         string
+		givenOverrides      map[string]*openrtb_ext.ExtAliasOverride
+		givenAllowEndpoints bool
+		expectedError       error
+	}{
+		{
+			description: "Valid overrides",
+			givenOverrides: map[string]*openrtb_ext.ExtAliasOverride{
+				"appnexusAlias": {Endpoint: "https://alias.com/{{.Host}}", GVLVendorID: 42, Params: json.RawMessage(`{"placementId":12345}`)},
+			},
+			givenAllowEndpoints: true,
+		},
+		{
+			description: "Override of an unknown alias",
+			givenOverrides: map[string]*openrtb_ext.ExtAliasOverride{
+				"rubiconAlias": {GVLVendorID: 42},
+			},
+			expectedError: errors.New("request.ext.prebid.aliasoverrides.rubiconAlias doesn't refer to an alias of request.ext.prebid.aliases"),
+		},
+		{
+			description: "Endpoint not allowed by the host",
+			givenOverrides: map[string]*openrtb_ext.ExtAliasOverride{
+				"appnexusAlias": {Endpoint: "https://alias.com"},
+			},
+			expectedError: errors.New("request.ext.prebid.aliasoverrides.appnexusAlias.endpoint is not allowed by the host"),
+		},
+		{
+			description: "Endpoint not an http url",
+			givenOverrides: map[string]*openrtb_ext.ExtAliasOverride{
+				"appnexusAlias": {Endpoint: "ftp://alias.com"},
+			},
+			givenAllowEndpoints: true,
+			expectedError:       errors.New("request.ext.prebid.aliasoverrides.appnexusAlias.endpoint must be an http or https url"),
+		},
+		{
+			description: "Params not an object",
+			givenOverrides: map[string]*openrtb_ext.ExtAliasOverride{
+				"appnexusAlias": {Params: json.RawMessage(`[12345]`)},
+			},
+			expectedError: errors.New("request.ext.prebid.aliasoverrides.appnexusAlias.params must be an object"),
+		},
+	}
+
+	for _, test := range testCases {
+		deps := &endpointDeps{cfg: &config.Configuration{AllowAliasEndpointOverrides: test.givenAllowEndpoints}}
+
+		err := deps.validateAliasOverrides(test.givenOverrides, aliases)
+
+		assert.Equal(t, test.expectedError, err, test.description)
+	}
+}
+
+func TestApplyAliasParams(t *testing.T) {
+	overrides := map[string]*openrtb_ext.ExtAliasOverride{
+		"appnexusAlias": {Params: json.RawMessage(`{"placementId":12345,"member":"alias"}`)},
+		"rubiconAlias":  {GVLVendorID: 42},
+	}
+
+	testCases := []struct {
+		description    string
+		givenImpExt    string
+		expectedImpExt string
+	}{
+		{
+			description:    "Params of imp.ext merged over the defaults",
+			givenImpExt:    `{"appnexusAlias":{"placementId":67890}}`,
+			expectedImpExt: `{"appnexusAlias":{"placementId":67890,"member":"alias"}}`,
+		},
+		{
+			description:    "Params of imp.ext.prebid.bidder merged over the defaults",
+			givenImpExt:    `{"prebid":{"bidder":{"appnexusAlias":{"member":"imp"}}}}`,
+			expectedImpExt: `{"prebid":{"bidder":{"appnexusAlias":{"placementId":12345,"member":"imp"}}}}`,
+		},
+		{
+			description:    "Imp not calling the alias",
+			givenImpExt:    `{"appnexus":{"placementId":67890}}`,
+			expectedImpExt: `{"appnexus":{"placementId":67890}}`,
+		},
+		{
+			description:    "Malformed params left for the imp validation",
+			givenImpExt:    `{"appnexusAlias":"placementId"}`,
+			expectedImpExt: `{"appnexusAlias":"placementId"}`,
+		},
+	}
+
+	for _, test := range testCases {
+		imp := &openrtb2.Imp{Ext: json.RawMessage(test.givenImpExt)}
+
+		err := applyAliasParams(imp, overrides, 0)
+
+		assert.NoError(t, err, test.description)
+		assert.JSONEq(t, test.expectedImpExt, string(imp.Ext), test.description)
+	}
+}
+
 func TestValidateNativeContextTypes(t *testing.T) {
 	impIndex := 4
 
@@ -2912,6 +3751,52 @@ func TestValidateNativeAssetData(t *testing.T) {
 	}
 }
 
+func TestValidateNativeAssetImage(t *testing.T) {
+	impIndex := 4
+	assetIndex := 8
+
+	testCases := []struct {
+		description   string
+		givenImage    nativeRequests.Image
+		expectedError string
+	}{
+		{
+			description:   "Valid",
+			givenImage:    nativeRequests.Image{Type: 3, W: 300, H: 250},
+			expectedError: "",
+		},
+		{
+			description:   "Not Specified",
+			givenImage:    nativeRequests.Image{},
+			expectedError: "",
+		},
+		{
+			description:   "Exchange Specific - Boundary",
+			givenImage:    nativeRequests.Image{Type: 500},
+			expectedError: "",
+		},
+		{
+			description:   "Just Above Range",
+			givenImage:    nativeRequests.Image{Type: 4}, // Range is currently 1-3
+			expectedError: "request.imp[4].native.request.assets[8].img.type is invalid. See section 7.5: https://iabtechlab.com/wp-content/uploads/2016/07/OpenRTB-Native-Ads-Specification-Final-1.2.pdf#page=41",
+		},
+		{
+			description:   "Negative Width",
+			givenImage:    nativeRequests.Image{W: -1},
+			expectedError: "request.imp[4].native.request.assets[8].img.w must be a positive integer",
+		},
+	}
+
+	for _, test := range testCases {
+		err := validateNativeAssetImage(&test.givenImage, impIndex, assetIndex)
+		if test.expectedError == "" {
+			assert.NoError(t, err, test.description)
+		} else {
+			assert.EqualError(t, err, test.expectedError, test.description)
+		}
+	}
+}
+
 // warningsCheckExchange is a well-behaved exchange which stores all incoming warnings.
 type warningsCheckExchange struct {
 	auctionRequest exchange.AuctionRequest
@@ -3644,6 +4529,13 @@ var testStoredRequestsUuid = []string{
 	}`,
 }
 
+var testStoredResponseData = map[string]json.RawMessage{
+	"auction-resp": json.RawMessage(`[{"seat":"appnexus","bid":[{"id":"bid-1","price":1.5}]}]`),
+	"bid-resp":     json.RawMessage(`[{"bid":[{"id":"bid-2","price":2.5}]}]`),
+	"no-seat-resp": json.RawMessage(`[{"bid":[{"id":"bid-3","price":3.5}]}]`),
+	"invalid-resp": json.RawMessage(`{"seat":"appnexus"}`),
+}
+
 type mockStoredReqFetcher struct {
 }
 
@@ -3651,8 +4543,21 @@ func (cf mockStoredReqFetcher) FetchRequests(ctx context.Context, requestIDs []s
 	return testStoredRequestData, testStoredImpData, nil
 }
 
+func (cf mockStoredReqFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	for _, id := range ids {
+		if _, ok := testStoredResponseData[id]; !ok {
+			errs = append(errs, stored_requests.NotFoundError{ID: id, DataType: "Response"})
+		}
+	}
+	return testStoredResponseData, errs
+}
+
 var mockAccountData = map[string]json.RawMessage{
-	"valid_acct": json.RawMessage(`{"disabled":false}`),
+	"valid_acct":          json.RawMessage(`{"disabled":false}`),
+	"alias_acct":          json.RawMessage(`{"disabled":false,"aliases":{"appnexusAlias":"appnexus"}}`),
+	"alias_override_acct": json.RawMessage(`{"disabled":false,"aliases":{"appnexusAlias":"appnexus"},"alias_overrides":{"appnexusAlias":{"gvl_vendor_id":42,"params":{"placementId":12345}}}}`),
+	"debug_token_acct":    json.RawMessage(`{"disabled":false,"debug_allow":true,"debug_token":"secret-token"}`),
+	"dooh_disabled_acct":  json.RawMessage(`{"disabled":false,"dooh":{"enabled":false}}`),
 }
 
 type mockAccountFetcher struct {
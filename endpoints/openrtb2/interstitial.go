@@ -9,13 +9,14 @@ import (
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
+// processInterstitials expands the formats of the interstitial banner imps to the sizes the device ext allows, when
+// the device ext asks for it with device.ext.prebid.interstitial
 func processInterstitials(req *openrtb_ext.RequestWrapper) error {
-	unmarshalled := true
+	var prebid *openrtb_ext.ExtDevicePrebid
 	for i := range req.Imp {
 		if req.Imp[i].Instl == 1 {
-			var prebid *openrtb_ext.ExtDevicePrebid
-			if unmarshalled {
-				if req.Device.Ext == nil {
+			if prebid == nil {
+				if req.Device == nil || req.Device.Ext == nil {
 					// No special interstitial support requested, so bail as there is nothing to do
 					return nil
 				}
@@ -24,7 +25,7 @@ func processInterstitials(req *openrtb_ext.RequestWrapper) error {
 					return err
 				}
 				prebid = deviceExt.GetPrebid()
-				if prebid.Interstitial == nil {
+				if prebid == nil || prebid.Interstitial == nil {
 					// No special interstitial support requested, so bail as there is nothing to do
 					return nil
 				}
@@ -83,3 +83,28 @@ func TestInterstitial(t *testing.T) {
 	assert.Equal(t, targetFormat, myRequest.Imp[0].Banner.Format)
 
 }
+
+func TestInterstitialWithoutInterstitialSupport(t *testing.T) {
+	format := []openrtb2.Format{{W: 300, H: 600}}
+	testCases := []struct {
+		description string
+		givenDevice *openrtb2.Device
+	}{
+		{description: "No device", givenDevice: nil},
+		{description: "No device ext", givenDevice: &openrtb2.Device{W: 320, H: 640}},
+		{description: "No prebid device ext", givenDevice: &openrtb2.Device{W: 320, H: 640, Ext: json.RawMessage(`{"atts":1}`)}},
+		{description: "No interstitial device ext", givenDevice: &openrtb2.Device{W: 320, H: 640, Ext: json.RawMessage(`{"prebid":{}}`)}},
+	}
+
+	for _, test := range testCases {
+		req := &openrtb2.BidRequest{
+			Imp:    []openrtb2.Imp{{ID: "my-imp-id", Instl: 1, Banner: &openrtb2.Banner{Format: format}}},
+			Device: test.givenDevice,
+		}
+
+		err := processInterstitials(&openrtb_ext.RequestWrapper{BidRequest: req})
+
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, format, req.Imp[0].Banner.Format, test.description)
+	}
+}
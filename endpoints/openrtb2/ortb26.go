@@ -0,0 +1,142 @@
+package openrtb2
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/buger/jsonparser"
+)
+
+// ortb26Fields maps OpenRTB 2.6 request fields to the ext location Prebid uses for them in OpenRTB 2.5.
+var ortb26Fields = []struct {
+	from []string
+	to   []string
+}{
+	{from: []string{"regs", "gdpr"}, to: []string{"regs", "ext", "gdpr"}},
+	{from: []string{"regs", "us_privacy"}, to: []string{"regs", "ext", "us_privacy"}},
+	{from: []string{"user", "consent"}, to: []string{"user", "ext", "consent"}},
+	{from: []string{"user", "eids"}, to: []string{"user", "ext", "eids"}},
+	{from: []string{"source", "schain"}, to: []string{"source", "ext", "schain"}},
+}
+
+// ortb26ImpFields is the same as ortb26Fields, relative to each imp.
+var ortb26ImpFields = []struct {
+	from []string
+	to   []string
+}{
+	{from: []string{"rwdd"}, to: []string{"ext", "prebid", "is_rewarded_inventory"}},
+	{from: []string{"video", "plcmt"}, to: []string{"video", "ext", "plcmt"}},
+	{from: []string{"video", "podid"}, to: []string{"video", "ext", "podid"}},
+	{from: []string{"video", "podseq"}, to: []string{"video", "ext", "podseq"}},
+	{from: []string{"video", "poddur"}, to: []string{"video", "ext", "poddur"}},
+	{from: []string{"video", "slotinpod"}, to: []string{"video", "ext", "slotinpod"}},
+	{from: []string{"video", "mincpmpersec"}, to: []string{"video", "ext", "mincpmpersec"}},
+	{from: []string{"video", "rqddurs"}, to: []string{"video", "ext", "rqddurs"}},
+	{from: []string{"video", "maxseq"}, to: []string{"video", "ext", "maxseq"}},
+}
+
+// downconvertOpenRTB26 copies the OpenRTB 2.6 fields of a request to their OpenRTB 2.5 ext locations. The OpenRTB 2.5
+// models used by this server would otherwise silently drop them, so neither the privacy enforcement nor the bidders
+// would see them. A value already present at the 2.5 location wins.
+//
+// All the bidders receive the downconverted 2.5 requests, and the responses stay 2.5: sending native 2.6 requests to
+// the bidders declaring 2.6 support, and the 2.6 fields of the bids, wait for the 2.6 models.
+func downconvertOpenRTB26(requestJson []byte) ([]byte, error) {
+	requestJson, err := downconvertDOOH(requestJson)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range ortb26Fields {
+		if requestJson, err = copyField(requestJson, field.from, field.to); err != nil {
+			return nil, err
+		}
+	}
+
+	impCount := 0
+	jsonparser.ArrayEach(requestJson, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		impCount++
+	}, "imp")
+
+	for i := 0; i < impCount; i++ {
+		impPath := fmt.Sprintf("[%d]", i)
+		for _, field := range ortb26ImpFields {
+			from := append([]string{"imp", impPath}, field.from...)
+			to := append([]string{"imp", impPath}, field.to...)
+			if requestJson, err = copyField(requestJson, from, to); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return requestJson, nil
+}
+
+// downconvertDOOH copies the OpenRTB 2.6 dooh object of a request to request.site, with its venue type in
+// request.site.ext, and puts the request in the dooh channel. The dooh requests can't have a site or an app.
+func downconvertDOOH(requestJson []byte) ([]byte, error) {
+	_, dataType, _, err := jsonparser.Get(requestJson, "dooh")
+	if err != nil || dataType == jsonparser.NotExist || dataType == jsonparser.Null {
+		return requestJson, nil
+	}
+	if dataType != jsonparser.Object {
+		return nil, errors.New("request.dooh is invalid: must be an object")
+	}
+	for _, field := range []string{"site", "app"} {
+		if _, fieldType, _, _ := jsonparser.Get(requestJson, field); fieldType != jsonparser.NotExist && fieldType != jsonparser.Null {
+			return nil, errors.New("request.site, request.app or request.dooh must be defined, but only one of them")
+		}
+	}
+
+	requestJson = jsonparser.Delete(requestJson, "site")
+	if requestJson, err = copyField(requestJson, []string{"dooh"}, []string{"site"}); err != nil {
+		return nil, err
+	}
+	for _, field := range []string{"venuetype", "venuetypetax"} {
+		if requestJson, err = copyField(requestJson, []string{"dooh", field}, []string{"site", "ext", field}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range [][]string{{"ext"}, {"ext", "prebid"}, {"ext", "prebid", "channel"}} {
+		if _, parentType, _, _ := jsonparser.Get(requestJson, path...); parentType != jsonparser.NotExist && parentType != jsonparser.Object {
+			return nil, fmt.Errorf("request.%s is invalid: must be an object", formatPath(path))
+		}
+	}
+	if requestJson, err = jsonparser.Set(requestJson, []byte(`"dooh"`), "ext", "prebid", "channel", "name"); err != nil {
+		return nil, fmt.Errorf("request.ext.prebid.channel is invalid: %v", err)
+	}
+	return requestJson, nil
+}
+
+// copyField copies the value at the from path to the to path, unless the to path is already set.
+func copyField(requestJson []byte, from, to []string) ([]byte, error) {
+	value, dataType, _, err := jsonparser.Get(requestJson, from...)
+	if err != nil || dataType == jsonparser.NotExist || dataType == jsonparser.Null {
+		return requestJson, nil
+	}
+	if _, existingType, _, _ := jsonparser.Get(requestJson, to...); existingType != jsonparser.NotExist {
+		return requestJson, nil
+	}
+
+	for i := 1; i < len(to); i++ {
+		if _, parentType, _, _ := jsonparser.Get(requestJson, to[:i]...); parentType != jsonparser.NotExist && parentType != jsonparser.Object && parentType != jsonparser.Array {
+			return nil, fmt.Errorf("request.%s is invalid: must be an object", formatPath(to[:i]))
+		}
+	}
+
+	if dataType == jsonparser.String {
+		// jsonparser.Get strips the quotes of strings, but keeps them escaped
+		value = append(append([]byte(`"`), value...), '"')
+	}
+
+	requestJson, err = jsonparser.Set(requestJson, value, to...)
+	if err != nil {
+		return nil, fmt.Errorf("request.%s is invalid: %v", formatPath(to[:len(to)-1]), err)
+	}
+	return requestJson, nil
+}
+
+func formatPath(path []string) string {
+	return strings.Replace(strings.Join(path, "."), ".[", "[", -1)
+}
@@ -0,0 +1,85 @@
+package openrtb2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownconvertOpenRTB26(t *testing.T) {
+	testCases := []struct {
+		description   string
+		requestJson   string
+		expectedJson  string
+		expectedError string
+	}{
+		{
+			description:  "OpenRTB 2.5 request is left as is",
+			requestJson:  `{"id":"1","imp":[{"id":"1","ext":{"prebid":{"is_rewarded_inventory":1}}}],"regs":{"ext":{"gdpr":1}}}`,
+			expectedJson: `{"id":"1","imp":[{"id":"1","ext":{"prebid":{"is_rewarded_inventory":1}}}],"regs":{"ext":{"gdpr":1}}}`,
+		},
+		{
+			description:  "Privacy fields",
+			requestJson:  `{"regs":{"gdpr":1,"us_privacy":"1YNN"},"user":{"consent":"BOONs","eids":[{"source":"src","uids":[{"id":"id"}]}]}}`,
+			expectedJson: `{"regs":{"gdpr":1,"us_privacy":"1YNN","ext":{"gdpr":1,"us_privacy":"1YNN"}},"user":{"consent":"BOONs","eids":[{"source":"src","uids":[{"id":"id"}]}],"ext":{"consent":"BOONs","eids":[{"source":"src","uids":[{"id":"id"}]}]}}}`,
+		},
+		{
+			description:  "Supply chain",
+			requestJson:  `{"source":{"tid":"tid","schain":{"complete":1,"nodes":[],"ver":"1.0"}}}`,
+			expectedJson: `{"source":{"tid":"tid","schain":{"complete":1,"nodes":[],"ver":"1.0"},"ext":{"schain":{"complete":1,"nodes":[],"ver":"1.0"}}}}`,
+		},
+		{
+			description:  "2.5 ext location wins",
+			requestJson:  `{"regs":{"gdpr":1,"ext":{"gdpr":0}}}`,
+			expectedJson: `{"regs":{"gdpr":1,"ext":{"gdpr":0}}}`,
+		},
+		{
+			description:  "Imp fields",
+			requestJson:  `{"imp":[{"id":"1","rwdd":1,"ext":{"appnexus":{}}},{"id":"2","video":{"mimes":["video/mp4"],"plcmt":1,"podid":"pod","poddur":60,"mincpmpersec":0.5}}]}`,
+			expectedJson: `{"imp":[{"id":"1","rwdd":1,"ext":{"appnexus":{},"prebid":{"is_rewarded_inventory":1}}},{"id":"2","video":{"mimes":["video/mp4"],"plcmt":1,"podid":"pod","poddur":60,"mincpmpersec":0.5,"ext":{"plcmt":1,"podid":"pod","poddur":60,"mincpmpersec":0.5}}}]}`,
+		},
+		{
+			description:  "DOOH",
+			requestJson:  `{"dooh":{"id":"screen","venuetype":["transit"],"venuetypetax":1,"publisher":{"id":"pub"}},"ext":{"prebid":{"channel":{"name":"web","version":"1.0"}}}}`,
+			expectedJson: `{"dooh":{"id":"screen","venuetype":["transit"],"venuetypetax":1,"publisher":{"id":"pub"}},"ext":{"prebid":{"channel":{"name":"dooh","version":"1.0"}}},"site":{"id":"screen","venuetype":["transit"],"venuetypetax":1,"publisher":{"id":"pub"},"ext":{"venuetype":["transit"],"venuetypetax":1}}}`,
+		},
+		{
+			description:  "DOOH without ext",
+			requestJson:  `{"dooh":{"id":"screen"},"site":null}`,
+			expectedJson: `{"dooh":{"id":"screen"},"site":{"id":"screen"},"ext":{"prebid":{"channel":{"name":"dooh"}}}}`,
+		},
+		{
+			description:   "DOOH and site",
+			requestJson:   `{"dooh":{"id":"screen"},"site":{"page":"https://example.com"}}`,
+			expectedError: "request.site, request.app or request.dooh must be defined, but only one of them",
+		},
+		{
+			description:   "DOOH and app",
+			requestJson:   `{"dooh":{"id":"screen"},"app":{"bundle":"com.example"}}`,
+			expectedError: "request.site, request.app or request.dooh must be defined, but only one of them",
+		},
+		{
+			description:   "DOOH which isn't an object",
+			requestJson:   `{"dooh":"screen"}`,
+			expectedError: "request.dooh is invalid: must be an object",
+		},
+		{
+			description:   "Ext which isn't an object",
+			requestJson:   `{"imp":[{"id":"1","video":{"plcmt":1,"ext":"bad"}}]}`,
+			expectedError: "request.imp[0].video.ext is invalid: must be an object",
+		},
+	}
+
+	for _, test := range testCases {
+		result, err := downconvertOpenRTB26([]byte(test.requestJson))
+
+		if test.expectedError != "" {
+			if assert.Error(t, err, test.description) {
+				assert.Contains(t, err.Error(), test.expectedError, test.description)
+			}
+		} else {
+			assert.NoError(t, err, test.description)
+			assert.JSONEq(t, test.expectedJson, string(result), test.description)
+		}
+	}
+}
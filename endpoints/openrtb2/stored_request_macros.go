@@ -0,0 +1,116 @@
+package openrtb2
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/buger/jsonparser"
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/macros"
+	"github.com/prebid/prebid-server/metrics"
+)
+
+// storedRequestMacroPaths are the fields of the request which the built-in macros are resolved from, in the order
+// they are looked up. The OpenRTB 2.6 locations come second, since the request isn't downconverted yet.
+var storedRequestMacroPaths = map[string][][]string{
+	"page":         {{"site", "page"}},
+	"domain":       {{"site", "domain"}},
+	"bundle":       {{"app", "bundle"}},
+	"gdpr":         {{"regs", "ext", "gdpr"}, {"regs", "gdpr"}},
+	"gdpr_consent": {{"user", "ext", "consent"}, {"user", "consent"}},
+	"us_privacy":   {{"regs", "ext", "us_privacy"}, {"regs", "us_privacy"}},
+}
+
+// storedRequestMacroValues returns the values of the {{name}} macros of the Stored Request data, or nil if the data
+// has no macros. The built-in macros are resolved from the HTTP request merged onto the Stored BidRequest, and the
+// account ones from the stored_request_macros of the account. The built-in macros win over account ones of the same
+// name.
+func storedRequestMacroValues(ctx context.Context, requestJson []byte, storedBidRequest json.RawMessage, storedImps map[string]json.RawMessage, accounts *accountLookups) map[string]string {
+	hasMacros := macros.HasStoredRequestMacros(storedBidRequest)
+	for _, storedImp := range storedImps {
+		hasMacros = hasMacros || macros.HasStoredRequestMacros(storedImp)
+	}
+	if !hasMacros {
+		return nil
+	}
+
+	// Errors merging the requests are reported once the Stored BidRequest is applied
+	request := requestJson
+	if len(storedBidRequest) > 0 {
+		if mergedRequest, err := jsonpatch.MergePatch(storedBidRequest, requestJson); err == nil {
+			request = mergedRequest
+		}
+	}
+
+	values := make(map[string]string)
+	accountID := requestAccountID(request)
+	// Account lookup errors are reported when the auction fetches the account once the request is validated.
+	if account, errs := accounts.getAccount(ctx, accountID); len(errs) == 0 {
+		for name, value := range account.StoredRequestMacros {
+			values[name] = value
+		}
+	}
+	values["account_id"] = ""
+	if accountID != metrics.PublisherUnknown {
+		values["account_id"] = accountID
+	}
+	for name, paths := range storedRequestMacroPaths {
+		values[name] = ""
+		for _, path := range paths {
+			if value, ok := getMacroValue(request, path...); ok {
+				values[name] = value
+				break
+			}
+		}
+	}
+	return values
+}
+
+// impMacroValues adds the values of the imp macros of the HTTP request imp to the request ones
+func impMacroValues(values map[string]string, imp json.RawMessage) map[string]string {
+	impValues := make(map[string]string, len(values)+2)
+	for name, value := range values {
+		impValues[name] = value
+	}
+	impValues["imp_id"], _ = getMacroValue(imp, "id")
+	impValues["tagid"], _ = getMacroValue(imp, "tagid")
+	return impValues
+}
+
+// requestAccountID returns the account id of the request JSON, like the auction does once the request is parsed
+func requestAccountID(request []byte) string {
+	for _, distributionChannel := range []string{"app", "site"} {
+		publisherJSON, _, _, err := jsonparser.Get(request, distributionChannel, "publisher")
+		if err != nil {
+			continue
+		}
+		var publisher openrtb2.Publisher
+		if err := json.Unmarshal(publisherJSON, &publisher); err == nil {
+			return getAccountID(&publisher)
+		}
+	}
+	return metrics.PublisherUnknown
+}
+
+// getMacroValue returns the string or the number at the path of the JSON
+func getMacroValue(data []byte, path ...string) (string, bool) {
+	value, dataType, _, err := jsonparser.Get(data, path...)
+	if err != nil {
+		return "", false
+	}
+	switch dataType {
+	case jsonparser.String:
+		if value, err := jsonparser.ParseString(value); err == nil {
+			return value, true
+		}
+	case jsonparser.Number:
+		return string(value), true
+	case jsonparser.Boolean:
+		if value, err := jsonparser.ParseBoolean(value); err == nil {
+			return strconv.FormatBool(value), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,103 @@
+package openrtb2
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessStoredRequestsMacros(t *testing.T) {
+	storedRequests := map[string]json.RawMessage{
+		"macro-req": json.RawMessage(`{"tmax":500,"ext":{"prebid":{"targeting":{"pricegranularity":"{{granularity}}"}}}}`),
+	}
+	storedImps := map[string]json.RawMessage{
+		"macro-imp": json.RawMessage(`{"banner":{"format":[{"w":300,"h":250}]},"ext":{"appnexus":{"placementId":12883451,"keywords":"page={{page}},gdpr={{gdpr}},tag={{tagid}},acct={{account_id}},{{unknown}}"}}}`),
+		"plain-imp": json.RawMessage(`{"banner":{"format":[{"w":300,"h":250}]},"ext":{"appnexus":{"placementId":1}}}`),
+	}
+	accounts := map[string]json.RawMessage{
+		"macro_acct": json.RawMessage(`{"stored_request_macros":{"granularity":"high","page":"account-page"}}`),
+	}
+
+	testCases := []struct {
+		description     string
+		givenRequest    string
+		expectedRequest string
+	}{
+		{
+			description: "Request and imp macros",
+			givenRequest: `{"id":"req","site":{"page":"https://example.com/\"a\"","publisher":{"id":"macro_acct"}},"regs":{"ext":{"gdpr":1}},"ext":{"prebid":{"storedrequest":{"id":"macro-req"}}},` +
+				`"imp":[{"id":"imp-1","tagid":"top","ext":{"prebid":{"storedrequest":{"id":"macro-imp"}}}},{"id":"imp-2","tagid":"side","ext":{"prebid":{"storedrequest":{"id":"macro-imp"}}}}]}`,
+			expectedRequest: `{"id":"req","tmax":500,"site":{"page":"https://example.com/\"a\"","publisher":{"id":"macro_acct"}},"regs":{"ext":{"gdpr":1}},"ext":{"prebid":{"storedrequest":{"id":"macro-req"},"targeting":{"pricegranularity":"high"}}},` +
+				`"imp":[` +
+				`{"id":"imp-1","tagid":"top","banner":{"format":[{"w":300,"h":250}]},"ext":{"appnexus":{"placementId":12883451,"keywords":"page=https://example.com/\"a\",gdpr=1,tag=top,acct=macro_acct,{{unknown}}"},"prebid":{"storedrequest":{"id":"macro-imp"}}}},` +
+				`{"id":"imp-2","tagid":"side","banner":{"format":[{"w":300,"h":250}]},"ext":{"appnexus":{"placementId":12883451,"keywords":"page=https://example.com/\"a\",gdpr=1,tag=side,acct=macro_acct,{{unknown}}"},"prebid":{"storedrequest":{"id":"macro-imp"}}}}]}`,
+		},
+		{
+			description:  "Missing built-in values",
+			givenRequest: `{"id":"req","app":{"bundle":"com.example"},"imp":[{"id":"imp-1","ext":{"prebid":{"storedrequest":{"id":"macro-imp"}}}}]}`,
+			expectedRequest: `{"id":"req","app":{"bundle":"com.example"},` +
+				`"imp":[{"id":"imp-1","banner":{"format":[{"w":300,"h":250}]},"ext":{"appnexus":{"placementId":12883451,"keywords":"page=,gdpr=,tag=,acct=,{{unknown}}"},"prebid":{"storedrequest":{"id":"macro-imp"}}}}]}`,
+		},
+		{
+			description:     "No macros",
+			givenRequest:    `{"id":"req","site":{"page":"https://example.com"},"imp":[{"id":"imp-1","ext":{"prebid":{"storedrequest":{"id":"plain-imp"}}}}]}`,
+			expectedRequest: `{"id":"req","site":{"page":"https://example.com"},"imp":[{"id":"imp-1","banner":{"format":[{"w":300,"h":250}]},"ext":{"appnexus":{"placementId":1},"prebid":{"storedrequest":{"id":"plain-imp"}}}}]}`,
+		},
+	}
+
+	cfg := &config.Configuration{}
+	cfg.MarshalAccountDefaults()
+	deps := &endpointDeps{
+		storedReqFetcher: &macroStoredReqFetcher{requests: storedRequests, imps: storedImps},
+		accounts:         &macroAccountFetcher{accounts: accounts},
+		cfg:              cfg,
+	}
+
+	for _, test := range testCases {
+		impInfo, errs := parseImpInfo([]byte(test.givenRequest))
+		assert.Empty(t, errs, test.description+":parse")
+
+		resolvedRequest, _, errs := deps.processStoredRequests(context.Background(), []byte(test.givenRequest), impInfo, deps.newAccountLookups())
+
+		assert.Empty(t, errs, test.description+":errs")
+		assert.JSONEq(t, test.expectedRequest, string(resolvedRequest), test.description+":request")
+	}
+
+	assert.Contains(t, string(storedImps["macro-imp"]), "{{page}}", "The fetched Stored Imps should not be modified")
+}
+
+type macroStoredReqFetcher struct {
+	requests map[string]json.RawMessage
+	imps     map[string]json.RawMessage
+}
+
+func (f *macroStoredReqFetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (map[string]json.RawMessage, map[string]json.RawMessage, []error) {
+	requestData := make(map[string]json.RawMessage)
+	for _, id := range requestIDs {
+		requestData[id] = f.requests[id]
+	}
+	impData := make(map[string]json.RawMessage)
+	for _, id := range impIDs {
+		impData[id] = f.imps[id]
+	}
+	return requestData, impData, nil
+}
+
+func (f *macroStoredReqFetcher) FetchResponses(ctx context.Context, ids []string) (map[string]json.RawMessage, []error) {
+	return nil, nil
+}
+
+type macroAccountFetcher struct {
+	accounts map[string]json.RawMessage
+}
+
+func (f *macroAccountFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
+	if account, ok := f.accounts[accountID]; ok {
+		return account, nil
+	}
+	return nil, []error{stored_requests.NotFoundError{ID: accountID, DataType: "Account"}}
+}
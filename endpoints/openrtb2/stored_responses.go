@@ -0,0 +1,112 @@
+package openrtb2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/buger/jsonparser"
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// processStoredResponses fetches the stored responses which the imps of the request ask for in
+// imp.ext.prebid.storedauctionresponse and imp.ext.prebid.storedbidresponse. The stored auction responses are
+// returned by imp id, and the stored bid responses by imp id and bidder.
+func (deps *endpointDeps) processStoredResponses(ctx context.Context, req *openrtb_ext.RequestWrapper) (map[string]exchange.StoredResponse, map[string]map[openrtb_ext.BidderName]exchange.StoredResponse, []error) {
+	var aliases map[string]string
+	if reqExt, err := req.GetRequestExt(); err == nil && reqExt.GetPrebid() != nil {
+		aliases = reqExt.GetPrebid().Aliases
+	}
+
+	auctionResponseIDs := make(map[string]string)
+	bidResponseIDs := make(map[string]map[openrtb_ext.BidderName]string)
+	var ids []string
+	seenIDs := make(map[string]struct{})
+	addID := func(id string) {
+		if _, ok := seenIDs[id]; !ok {
+			seenIDs[id] = struct{}{}
+			ids = append(ids, id)
+		}
+	}
+
+	for i, imp := range req.Imp {
+		impExtPrebidJSON, _, _, err := jsonparser.Get(imp.Ext, openrtb_ext.PrebidExtKey)
+		if err != nil {
+			continue
+		}
+		var impExtPrebid openrtb_ext.ExtImpPrebid
+		if err := json.Unmarshal(impExtPrebidJSON, &impExtPrebid); err != nil {
+			return nil, nil, []error{fmt.Errorf("request.imp[%d].ext.prebid is invalid: %v", i, err)}
+		}
+
+		if impExtPrebid.StoredAuctionResponse != nil {
+			if len(impExtPrebid.StoredBidResponse) > 0 {
+				return nil, nil, []error{fmt.Errorf("request.imp[%d].ext.prebid must not have both storedauctionresponse and storedbidresponse", i)}
+			}
+			if impExtPrebid.StoredAuctionResponse.ID == "" {
+				return nil, nil, []error{fmt.Errorf("request.imp[%d].ext.prebid.storedauctionresponse.id is required", i)}
+			}
+			auctionResponseIDs[imp.ID] = impExtPrebid.StoredAuctionResponse.ID
+			addID(impExtPrebid.StoredAuctionResponse.ID)
+		}
+
+		for j, storedBidResponse := range impExtPrebid.StoredBidResponse {
+			if storedBidResponse.ID == "" {
+				return nil, nil, []error{fmt.Errorf("request.imp[%d].ext.prebid.storedbidresponse[%d].id is required", i, j)}
+			}
+			if !deps.isBidderOrAlias(storedBidResponse.Bidder, aliases) {
+				return nil, nil, []error{fmt.Errorf("request.imp[%d].ext.prebid.storedbidresponse[%d].bidder is not a known bidder: %s", i, j, storedBidResponse.Bidder)}
+			}
+			bidder := openrtb_ext.BidderName(storedBidResponse.Bidder)
+			if _, ok := bidResponseIDs[imp.ID][bidder]; ok {
+				return nil, nil, []error{fmt.Errorf("request.imp[%d].ext.prebid.storedbidresponse has more than one response for the bidder %s", i, bidder)}
+			}
+			if bidResponseIDs[imp.ID] == nil {
+				bidResponseIDs[imp.ID] = make(map[openrtb_ext.BidderName]string)
+			}
+			bidResponseIDs[imp.ID][bidder] = storedBidResponse.ID
+			addID(storedBidResponse.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil, nil
+	}
+
+	storedResponseData, errs := deps.storedReqFetcher.FetchResponses(ctx, ids)
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	storedResponses := make(map[string]exchange.StoredResponse, len(ids))
+	for _, id := range ids {
+		var seatBids []openrtb2.SeatBid
+		if err := json.Unmarshal(storedResponseData[id], &seatBids); err != nil {
+			return nil, nil, []error{fmt.Errorf("Stored Response with ID=\"%s\" has invalid seatbid JSON: %v", id, err)}
+		}
+		storedResponses[id] = exchange.StoredResponse{ID: id, SeatBids: seatBids}
+	}
+
+	auctionResponses := make(map[string]exchange.StoredResponse, len(auctionResponseIDs))
+	for impID, id := range auctionResponseIDs {
+		// The seats of a stored auction response tell the bidders its bids are made for
+		for i, seatBid := range storedResponses[id].SeatBids {
+			if seatBid.Seat == "" {
+				return nil, nil, []error{fmt.Errorf("Stored Response with ID=\"%s\" is missing seatbid[%d].seat", id, i)}
+			}
+		}
+		auctionResponses[impID] = storedResponses[id]
+	}
+
+	bidResponses := make(map[string]map[openrtb_ext.BidderName]exchange.StoredResponse, len(bidResponseIDs))
+	for impID, bidderIDs := range bidResponseIDs {
+		bidResponses[impID] = make(map[openrtb_ext.BidderName]exchange.StoredResponse, len(bidderIDs))
+		for bidder, id := range bidderIDs {
+			bidResponses[impID][bidder] = storedResponses[id]
+		}
+	}
+
+	return auctionResponses, bidResponses, nil
+}
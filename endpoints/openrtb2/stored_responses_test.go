@@ -0,0 +1,125 @@
+package openrtb2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessStoredResponses(t *testing.T) {
+	auctionResponse := exchange.StoredResponse{
+		ID:       "auction-resp",
+		SeatBids: []openrtb2.SeatBid{{Seat: "appnexus", Bid: []openrtb2.Bid{{ID: "bid-1", Price: 1.5}}}},
+	}
+	bidResponse := exchange.StoredResponse{
+		ID:       "bid-resp",
+		SeatBids: []openrtb2.SeatBid{{Bid: []openrtb2.Bid{{ID: "bid-2", Price: 2.5}}}},
+	}
+
+	testCases := []struct {
+		description              string
+		givenRequestExt          json.RawMessage
+		givenImpExts             []json.RawMessage
+		expectedAuctionResponses map[string]exchange.StoredResponse
+		expectedBidResponses     map[string]map[openrtb_ext.BidderName]exchange.StoredResponse
+		expectedErrs             []error
+	}{
+		{
+			description:  "No Stored Responses",
+			givenImpExts: []json.RawMessage{json.RawMessage(`{"prebid":{"bidder":{"appnexus":{"placementId":1}}}}`)},
+		},
+		{
+			description: "Stored Auction And Bid Responses",
+			givenImpExts: []json.RawMessage{
+				json.RawMessage(`{"prebid":{"storedauctionresponse":{"id":"auction-resp"}}}`),
+				json.RawMessage(`{"prebid":{"storedbidresponse":[{"id":"bid-resp","bidder":"appnexus"},{"id":"bid-resp","bidder":"rubicon"}]}}`),
+			},
+			expectedAuctionResponses: map[string]exchange.StoredResponse{"imp-0": auctionResponse},
+			expectedBidResponses: map[string]map[openrtb_ext.BidderName]exchange.StoredResponse{
+				"imp-1": {"appnexus": bidResponse, "rubicon": bidResponse},
+			},
+		},
+		{
+			description:     "Stored Bid Response For Alias",
+			givenRequestExt: json.RawMessage(`{"prebid":{"aliases":{"apn":"appnexus"}}}`),
+			givenImpExts:    []json.RawMessage{json.RawMessage(`{"prebid":{"storedbidresponse":[{"id":"bid-resp","bidder":"apn"}]}}`)},
+			expectedBidResponses: map[string]map[openrtb_ext.BidderName]exchange.StoredResponse{
+				"imp-0": {"apn": bidResponse},
+			},
+		},
+		{
+			description:  "Both Stored Auction And Bid Responses",
+			givenImpExts: []json.RawMessage{json.RawMessage(`{"prebid":{"storedauctionresponse":{"id":"auction-resp"},"storedbidresponse":[{"id":"bid-resp","bidder":"appnexus"}]}}`)},
+			expectedErrs: []error{errors.New("request.imp[0].ext.prebid must not have both storedauctionresponse and storedbidresponse")},
+		},
+		{
+			description:  "Stored Auction Response Missing ID",
+			givenImpExts: []json.RawMessage{json.RawMessage(`{"prebid":{"storedauctionresponse":{}}}`)},
+			expectedErrs: []error{errors.New("request.imp[0].ext.prebid.storedauctionresponse.id is required")},
+		},
+		{
+			description:  "Stored Bid Response Missing ID",
+			givenImpExts: []json.RawMessage{json.RawMessage(`{"prebid":{"storedbidresponse":[{"bidder":"appnexus"}]}}`)},
+			expectedErrs: []error{errors.New("request.imp[0].ext.prebid.storedbidresponse[0].id is required")},
+		},
+		{
+			description:  "Stored Bid Response Unknown Bidder",
+			givenImpExts: []json.RawMessage{json.RawMessage(`{"prebid":{"storedbidresponse":[{"id":"bid-resp","bidder":"unknown"}]}}`)},
+			expectedErrs: []error{errors.New("request.imp[0].ext.prebid.storedbidresponse[0].bidder is not a known bidder: unknown")},
+		},
+		{
+			description:  "Stored Bid Response Duplicated Bidder",
+			givenImpExts: []json.RawMessage{json.RawMessage(`{"prebid":{"storedbidresponse":[{"id":"bid-resp","bidder":"appnexus"},{"id":"auction-resp","bidder":"appnexus"}]}}`)},
+			expectedErrs: []error{errors.New("request.imp[0].ext.prebid.storedbidresponse has more than one response for the bidder appnexus")},
+		},
+		{
+			description:  "Stored Response Not Found",
+			givenImpExts: []json.RawMessage{json.RawMessage(`{"prebid":{"storedauctionresponse":{"id":"unknown-resp"}}}`)},
+			expectedErrs: []error{stored_requests.NotFoundError{ID: "unknown-resp", DataType: "Response"}},
+		},
+		{
+			description:  "Stored Response Invalid",
+			givenImpExts: []json.RawMessage{json.RawMessage(`{"prebid":{"storedauctionresponse":{"id":"invalid-resp"}}}`)},
+			expectedErrs: []error{errors.New(`Stored Response with ID="invalid-resp" has invalid seatbid JSON: json: cannot unmarshal object into Go value of type []openrtb2.SeatBid`)},
+		},
+		{
+			description:  "Stored Auction Response Missing Seat",
+			givenImpExts: []json.RawMessage{json.RawMessage(`{"prebid":{"storedauctionresponse":{"id":"no-seat-resp"}}}`)},
+			expectedErrs: []error{errors.New(`Stored Response with ID="no-seat-resp" is missing seatbid[0].seat`)},
+		},
+	}
+
+	deps := &endpointDeps{
+		storedReqFetcher: &mockStoredReqFetcher{},
+		bidderMap:        openrtb_ext.BuildBidderMap(),
+	}
+
+	for _, test := range testCases {
+		req := &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{Ext: test.givenRequestExt}}
+		for i, impExt := range test.givenImpExts {
+			req.Imp = append(req.Imp, openrtb2.Imp{ID: fmt.Sprintf("imp-%d", i), Ext: impExt})
+		}
+
+		auctionResponses, bidResponses, errs := deps.processStoredResponses(context.Background(), req)
+
+		assert.Equal(t, test.expectedErrs, errs, test.description+":errs")
+		if len(test.expectedAuctionResponses) > 0 {
+			assert.Equal(t, test.expectedAuctionResponses, auctionResponses, test.description+":auction")
+		} else {
+			assert.Empty(t, auctionResponses, test.description+":auction")
+		}
+		if len(test.expectedBidResponses) > 0 {
+			assert.Equal(t, test.expectedBidResponses, bidResponses, test.description+":bid")
+		} else {
+			assert.Empty(t, bidResponses, test.description+":bid")
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package openrtb2
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/errortypes"
+)
+
+// structuredUserAgent is the OpenRTB 2.6 device.sua object, built by clients from User-Agent Client Hints.
+// The OpenRTB 2.5 models used by this server don't know about it, so it's read from the raw request.
+type structuredUserAgent struct {
+	Browsers []brandVersion `json:"browsers,omitempty"`
+	Platform *brandVersion  `json:"platform,omitempty"`
+	Mobile   *int8          `json:"mobile,omitempty"`
+	Model    string         `json:"model,omitempty"`
+}
+
+type brandVersion struct {
+	Brand   string   `json:"brand"`
+	Version []string `json:"version,omitempty"`
+}
+
+// normalizeUserAgent keeps device.ua consistent with device.sua. If the request only carries device.sua, a best effort
+// device.ua is synthesized from it, so bidders which don't understand client hints still get a user agent. If both
+// are present, a warning is returned when they disagree on the operating system. A client provided device.ua is
+// never overwritten, and a malformed device.sua is skipped with a warning.
+func normalizeUserAgent(requestJson []byte, bidReq *openrtb2.BidRequest) []error {
+	suaJson, dataType, _, err := jsonparser.Get(requestJson, "device", "sua")
+	if dataType == jsonparser.NotExist || dataType == jsonparser.Null || err != nil || bidReq.Device == nil {
+		return nil
+	}
+
+	var sua structuredUserAgent
+	if err := json.Unmarshal(suaJson, &sua); err != nil {
+		return []error{&errortypes.Warning{
+			Message:     fmt.Sprintf("request.device.sua is invalid and was ignored: %v", err),
+			WarningCode: errortypes.InvalidStructuredUserAgentWarningCode,
+		}}
+	}
+
+	if bidReq.Device.UA == "" {
+		bidReq.Device.UA = synthesizeUserAgent(sua)
+		return nil
+	}
+
+	if sua.Platform == nil {
+		return nil
+	}
+	uaOS := operatingSystemFromUserAgent(bidReq.Device.UA)
+	suaOS := normalizeOperatingSystem(sua.Platform.Brand)
+	if uaOS != "" && suaOS != "" && uaOS != suaOS {
+		return []error{&errortypes.Warning{
+			Message:     fmt.Sprintf("request.device.ua operating system %s is inconsistent with request.device.sua platform %s", uaOS, sua.Platform.Brand),
+			WarningCode: errortypes.InconsistentUserAgentWarningCode,
+		}}
+	}
+	return nil
+}
+
+// synthesizeUserAgent builds a legacy user agent string resembling the one a browser with the given client hints sends.
+func synthesizeUserAgent(sua structuredUserAgent) string {
+	var ua strings.Builder
+	ua.WriteString("Mozilla/5.0")
+
+	if sua.Platform != nil {
+		if platform := userAgentPlatform(*sua.Platform, sua.Model); platform != "" {
+			ua.WriteString(" (" + platform + ")")
+		}
+	}
+
+	browser, browserVersion := userAgentBrowser(sua.Browsers)
+	if browser == "" {
+		return ua.String()
+	}
+
+	ua.WriteString(" AppleWebKit/537.36 (KHTML, like Gecko) ")
+	if browser != "Chrome" {
+		// Chromium based browsers always advertise the Chrome version as well
+		for _, b := range sua.Browsers {
+			if b.Brand == "Chromium" || b.Brand == "Google Chrome" {
+				ua.WriteString("Chrome/" + joinVersion(b.Version, ".") + " ")
+				break
+			}
+		}
+	}
+	ua.WriteString(browser)
+	if browserVersion != "" {
+		ua.WriteString("/" + browserVersion)
+	}
+	if sua.Mobile != nil && *sua.Mobile == 1 {
+		ua.WriteString(" Mobile")
+	}
+	ua.WriteString(" Safari/537.36")
+	return ua.String()
+}
+
+func userAgentPlatform(platform brandVersion, model string) string {
+	switch normalizeOperatingSystem(platform.Brand) {
+	case "Windows":
+		return "Windows NT " + joinVersion(platform.Version, ".")
+	case "macOS":
+		return "Macintosh; Intel Mac OS X " + joinVersion(platform.Version, "_")
+	case "iOS":
+		return "iPhone; CPU iPhone OS " + joinVersion(platform.Version, "_") + " like Mac OS X"
+	case "Android":
+		if model != "" {
+			return "Linux; Android " + joinVersion(platform.Version, ".") + "; " + model
+		}
+		return "Linux; Android " + joinVersion(platform.Version, ".")
+	case "Chrome OS":
+		return "X11; CrOS x86_64 " + joinVersion(platform.Version, ".")
+	case "Linux":
+		return "X11; Linux x86_64"
+	default:
+		return strings.TrimSpace(platform.Brand + " " + joinVersion(platform.Version, "."))
+	}
+}
+
+// userAgentBrowser picks the browser token to use in the user agent, ignoring the GREASE brands browsers add to keep
+// servers from relying on the brand list order.
+func userAgentBrowser(browsers []brandVersion) (string, string) {
+	var chromium *brandVersion
+	for i, b := range browsers {
+		switch {
+		case strings.Contains(b.Brand, "Not") && strings.Contains(b.Brand, "Brand"):
+			continue
+		case b.Brand == "Chromium":
+			chromium = &browsers[i]
+		case b.Brand == "Google Chrome":
+			return "Chrome", joinVersion(b.Version, ".")
+		case b.Brand == "Microsoft Edge":
+			return "Edg", joinVersion(b.Version, ".")
+		default:
+			return strings.ReplaceAll(b.Brand, " ", ""), joinVersion(b.Version, ".")
+		}
+	}
+	if chromium != nil {
+		return "Chrome", joinVersion(chromium.Version, ".")
+	}
+	return "", ""
+}
+
+// operatingSystemFromUserAgent detects the operating system of a legacy user agent string, returning
+// an empty string if it isn't recognized.
+func operatingSystemFromUserAgent(ua string) string {
+	switch {
+	case strings.Contains(ua, "Windows"):
+		return "Windows"
+	case strings.Contains(ua, "Android"):
+		return "Android"
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"), strings.Contains(ua, "iPod"):
+		return "iOS"
+	case strings.Contains(ua, "CrOS"):
+		return "Chrome OS"
+	case strings.Contains(ua, "Macintosh"), strings.Contains(ua, "Mac OS X"):
+		return "macOS"
+	case strings.Contains(ua, "Linux"):
+		return "Linux"
+	default:
+		return ""
+	}
+}
+
+// normalizeOperatingSystem maps the device.sua platform brands to the names used by operatingSystemFromUserAgent.
+func normalizeOperatingSystem(platform string) string {
+	switch strings.ToLower(platform) {
+	case "windows":
+		return "Windows"
+	case "android":
+		return "Android"
+	case "ios", "ipados":
+		return "iOS"
+	case "chrome os", "chromeos":
+		return "Chrome OS"
+	case "macos", "mac os x":
+		return "macOS"
+	case "linux":
+		return "Linux"
+	default:
+		return ""
+	}
+}
+
+func joinVersion(version []string, separator string) string {
+	return strings.Join(version, separator)
+}
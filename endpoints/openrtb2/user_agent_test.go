@@ -0,0 +1,93 @@
+package openrtb2
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeUserAgent(t *testing.T) {
+	testCases := []struct {
+		description  string
+		requestJson  string
+		givenUA      string
+		expectedUA   string
+		expectedErrs []error
+	}{
+		{
+			description:  "SUA only - Chrome on Windows",
+			requestJson:  `{"device":{"sua":{"browsers":[{"brand":" Not A;Brand","version":["99"]},{"brand":"Chromium","version":["96","0","4664","110"]},{"brand":"Google Chrome","version":["96","0","4664","110"]}],"platform":{"brand":"Windows","version":["10","0"]},"mobile":0}}}`,
+			givenUA:      "",
+			expectedUA:   "Mozilla/5.0 (Windows NT 10.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/96.0.4664.110 Safari/537.36",
+			expectedErrs: nil,
+		},
+		{
+			description:  "SUA only - Edge on Android",
+			requestJson:  `{"device":{"sua":{"browsers":[{"brand":"Chromium","version":["96","0"]},{"brand":"Microsoft Edge","version":["96","0"]}],"platform":{"brand":"Android","version":["12"]},"mobile":1,"model":"Pixel 6"}}}`,
+			givenUA:      "",
+			expectedUA:   "Mozilla/5.0 (Linux; Android 12; Pixel 6) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/96.0 Edg/96.0 Mobile Safari/537.36",
+			expectedErrs: nil,
+		},
+		{
+			description:  "UA only",
+			requestJson:  `{"device":{"ua":"Mozilla/5.0 (Windows NT 10.0)"}}`,
+			givenUA:      "Mozilla/5.0 (Windows NT 10.0)",
+			expectedUA:   "Mozilla/5.0 (Windows NT 10.0)",
+			expectedErrs: nil,
+		},
+		{
+			description:  "Consistent UA and SUA",
+			requestJson:  `{"device":{"sua":{"platform":{"brand":"macOS","version":["12","1"]}}}}`,
+			givenUA:      "Mozilla/5.0 (Macintosh; Intel Mac OS X 12_1)",
+			expectedUA:   "Mozilla/5.0 (Macintosh; Intel Mac OS X 12_1)",
+			expectedErrs: nil,
+		},
+		{
+			description: "Inconsistent UA and SUA",
+			requestJson: `{"device":{"sua":{"platform":{"brand":"Android","version":["12"]}}}}`,
+			givenUA:     "Mozilla/5.0 (iPhone; CPU iPhone OS 15_1 like Mac OS X)",
+			expectedUA:  "Mozilla/5.0 (iPhone; CPU iPhone OS 15_1 like Mac OS X)",
+			expectedErrs: []error{&errortypes.Warning{
+				Message:     "request.device.ua operating system iOS is inconsistent with request.device.sua platform Android",
+				WarningCode: errortypes.InconsistentUserAgentWarningCode,
+			}},
+		},
+		{
+			description: "Malformed SUA",
+			requestJson: `{"device":{"sua":{"platform":"Windows"}}}`,
+			givenUA:     "",
+			expectedUA:  "",
+			expectedErrs: []error{&errortypes.Warning{
+				Message:     "request.device.sua is invalid and was ignored: json: cannot unmarshal string",
+				WarningCode: errortypes.InvalidStructuredUserAgentWarningCode,
+			}},
+		},
+		{
+			description: "Malformed SUA with UA",
+			requestJson: `{"device":{"sua":{"platform":"Android"}}}`,
+			givenUA:     "Mozilla/5.0 (iPhone; CPU iPhone OS 15_1 like Mac OS X)",
+			expectedUA:  "Mozilla/5.0 (iPhone; CPU iPhone OS 15_1 like Mac OS X)",
+			expectedErrs: []error{&errortypes.Warning{
+				Message:     "request.device.sua is invalid and was ignored: json: cannot unmarshal string",
+				WarningCode: errortypes.InvalidStructuredUserAgentWarningCode,
+			}},
+		},
+	}
+
+	for _, test := range testCases {
+		bidReq := &openrtb2.BidRequest{Device: &openrtb2.Device{UA: test.givenUA}}
+
+		errs := normalizeUserAgent([]byte(test.requestJson), bidReq)
+
+		assert.Equal(t, test.expectedUA, bidReq.Device.UA, test.description+":ua")
+		if len(test.expectedErrs) == 0 {
+			assert.Empty(t, errs, test.description+":errors")
+		} else if assert.Len(t, errs, len(test.expectedErrs), test.description+":errors") {
+			assert.Contains(t, errs[0].Error(), test.expectedErrs[0].Error(), test.description+":errors")
+			assert.IsType(t, test.expectedErrs[0], errs[0], test.description+":errors")
+			assert.Equal(t, errortypes.ReadCode(test.expectedErrs[0]), errortypes.ReadCode(errs[0]), test.description+":code")
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package openrtb2
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/userid"
+	"github.com/prebid/prebid-server/usersync"
+)
+
+// setUserID sends the first party id of the user to the bidders of the accounts enabling it. The id is minted, and
+// its cookie set on the response, for the users who don't have one yet. The app requests, which don't carry the
+// cookies of the host, the users who opted out of the syncs, and the users whose GDPR consent or CCPA opt-out forbid
+// the host cookies, are left without an id. The request must be rebuilt before, so its privacy policies are read
+// as sent to the bidders.
+func (deps *endpointDeps) setUserID(ctx context.Context, w http.ResponseWriter, r *http.Request, req *openrtb_ext.RequestWrapper, account *config.Account, requestType metrics.RequestType, usersyncs *usersync.Cookie) error {
+	cfg := &deps.cfg.UserID
+	if !cfg.Enabled || !account.UserID.Enabled || req.Site == nil || !usersyncs.AllowSyncs() {
+		return nil
+	}
+
+	requestExt, err := req.GetRequestExt()
+	if err != nil {
+		return err
+	}
+	var aliases map[string]string
+	if prebid := requestExt.GetPrebid(); prebid != nil {
+		aliases = prebid.Aliases
+	}
+	privacyConfig := config.Privacy{CCPA: deps.cfg.CCPA, GDPR: deps.cfg.GDPR, GPP: deps.cfg.GPP}
+	if !exchange.HostCookiesAllowed(ctx, req, account, requestType, aliases, deps.gdprPerms, privacyConfig) {
+		return nil
+	}
+
+	id, ok := userid.ParseCookieFromRequest(r, cfg)
+	if !ok {
+		if id, err = deps.uuidGenerator.Generate(); err != nil {
+			return err
+		}
+		userid.SetCookieOnResponse(w, cfg, deps.cfg.HostCookie.Domain, id)
+	}
+
+	if err := userid.AddEID(req, cfg.Source, userid.PublisherID(cfg, id, account.ID), account.UserID.Bidders); err != nil {
+		return err
+	}
+	return req.RebuildRequest()
+}
@@ -0,0 +1,187 @@
+package openrtb2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/userid"
+	"github.com/prebid/prebid-server/usersync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetUserID(t *testing.T) {
+	userIDConfig := config.UserID{Enabled: true, CookieName: "fpid", TTLDays: 30, SigningKey: "secret", Source: "prebid.org"}
+	enabledAccount := config.Account{ID: "1001", UserID: config.AccountUserID{Enabled: true}}
+	optedOut := usersync.NewCookie()
+	optedOut.SetOptOut(true)
+
+	// A cookie minted earlier for the user
+	w := httptest.NewRecorder()
+	userid.SetCookieOnResponse(w, &userIDConfig, "", "existing-id")
+	existingCookie := (&http.Response{Header: w.Header()}).Cookies()[0]
+
+	testCases := []struct {
+		description       string
+		givenHostEnabled  bool
+		givenAccount      config.Account
+		givenApp          bool
+		givenRegs         *openrtb2.Regs
+		givenCookie       *http.Cookie
+		givenUsersyncs    *usersync.Cookie
+		expectedID        string
+		expectedSetCookie bool
+	}{
+		{
+			description:       "New user",
+			givenHostEnabled:  true,
+			givenAccount:      enabledAccount,
+			givenUsersyncs:    usersync.NewCookie(),
+			expectedID:        "minted-id",
+			expectedSetCookie: true,
+		},
+		{
+			description:      "Returning user",
+			givenHostEnabled: true,
+			givenAccount:     enabledAccount,
+			givenCookie:      existingCookie,
+			givenUsersyncs:   usersync.NewCookie(),
+			expectedID:       "existing-id",
+		},
+		{
+			description:       "Forged cookie",
+			givenHostEnabled:  true,
+			givenAccount:      enabledAccount,
+			givenCookie:       &http.Cookie{Name: "fpid", Value: "other-id.c2lnbmF0dXJl"},
+			givenUsersyncs:    usersync.NewCookie(),
+			expectedID:        "minted-id",
+			expectedSetCookie: true,
+		},
+		{
+			description:    "Disabled by the host",
+			givenAccount:   enabledAccount,
+			givenUsersyncs: usersync.NewCookie(),
+		},
+		{
+			description:      "Disabled by the account",
+			givenHostEnabled: true,
+			givenAccount:     config.Account{ID: "1001"},
+			givenUsersyncs:   usersync.NewCookie(),
+		},
+		{
+			description:      "App request",
+			givenHostEnabled: true,
+			givenAccount:     enabledAccount,
+			givenApp:         true,
+			givenUsersyncs:   usersync.NewCookie(),
+		},
+		{
+			description:      "Opted out user",
+			givenHostEnabled: true,
+			givenAccount:     enabledAccount,
+			givenUsersyncs:   optedOut,
+		},
+		{
+			description:      "GDPR without consent",
+			givenHostEnabled: true,
+			givenAccount:     enabledAccount,
+			givenRegs:        &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr":1}`)},
+			givenUsersyncs:   usersync.NewCookie(),
+		},
+		{
+			description:      "CCPA opt-out",
+			givenHostEnabled: true,
+			givenAccount:     enabledAccount,
+			givenRegs:        &openrtb2.Regs{Ext: json.RawMessage(`{"us_privacy":"1YYN"}`)},
+			givenUsersyncs:   usersync.NewCookie(),
+		},
+		{
+			description:       "CCPA without opt-out",
+			givenHostEnabled:  true,
+			givenAccount:      enabledAccount,
+			givenRegs:         &openrtb2.Regs{Ext: json.RawMessage(`{"us_privacy":"1YNN"}`)},
+			givenUsersyncs:    usersync.NewCookie(),
+			expectedID:        "minted-id",
+			expectedSetCookie: true,
+		},
+	}
+
+	for _, test := range testCases {
+		hostConfig := userIDConfig
+		hostConfig.Enabled = test.givenHostEnabled
+		deps := &endpointDeps{
+			cfg: &config.Configuration{
+				UserID: hostConfig,
+				GDPR:   config.GDPR{Enabled: true},
+				CCPA:   config.CCPA{Enforce: true},
+			},
+			uuidGenerator: fakeUUIDGenerator{id: "minted-id"},
+			gdprPerms:     &userIDMockPermissions{},
+		}
+
+		req := &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{Site: &openrtb2.Site{}, Regs: test.givenRegs}}
+		if test.givenApp {
+			req.BidRequest = &openrtb2.BidRequest{App: &openrtb2.App{}}
+		}
+		r := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+		if test.givenCookie != nil {
+			r.AddCookie(test.givenCookie)
+		}
+		w := httptest.NewRecorder()
+
+		err := deps.setUserID(context.Background(), w, r, req, &test.givenAccount, metrics.ReqTypeORTB2Web, test.givenUsersyncs)
+		require.NoError(t, err, test.description)
+		require.NoError(t, req.RebuildRequest(), test.description)
+
+		if test.expectedID == "" {
+			assert.Nil(t, req.User, test.description+":user")
+		} else {
+			expectedUserExt, _ := json.Marshal(map[string]interface{}{
+				"eids": []openrtb_ext.ExtUserEid{{
+					Source: "prebid.org",
+					Uids:   []openrtb_ext.ExtUserEidUid{{ID: userid.PublisherID(&userIDConfig, test.expectedID, "1001"), Atype: 1}},
+				}},
+			})
+			require.NotNil(t, req.User, test.description+":user")
+			assert.JSONEq(t, string(expectedUserExt), string(req.User.Ext), test.description+":user.ext")
+		}
+		assert.Equal(t, test.expectedSetCookie, w.Header().Get("Set-Cookie") != "", test.description+":Set-Cookie")
+	}
+}
+
+func TestSetUserIDMintError(t *testing.T) {
+	deps := &endpointDeps{
+		cfg:           &config.Configuration{UserID: config.UserID{Enabled: true, CookieName: "fpid"}},
+		uuidGenerator: fakeUUIDGenerator{err: errors.New("no entropy")},
+	}
+	req := &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{Site: &openrtb2.Site{}}}
+	account := &config.Account{UserID: config.AccountUserID{Enabled: true}}
+
+	err := deps.setUserID(context.Background(), httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil), req, account, metrics.ReqTypeORTB2Web, usersync.NewCookie())
+
+	assert.EqualError(t, err, "no entropy")
+}
+
+// userIDMockPermissions lets the host set its cookies unless GDPR applies without a consent
+type userIDMockPermissions struct{}
+
+func (p *userIDMockPermissions) HostCookiesAllowed(ctx context.Context, gdprSignal gdpr.Signal, consent string) (bool, error) {
+	return gdprSignal != gdpr.SignalYes || consent != "", nil
+}
+
+func (p *userIDMockPermissions) BidderSyncAllowed(ctx context.Context, bidder openrtb_ext.BidderName, gdprSignal gdpr.Signal, consent string) (bool, error) {
+	return true, nil
+}
+
+func (p *userIDMockPermissions) AuctionActivitiesAllowed(ctx context.Context, bidder openrtb_ext.BidderName, PublisherID string, gdprSignal gdpr.Signal, consent string, weakVendorEnforcement bool) (bool, bool, bool, error) {
+	return true, true, true, nil
+}
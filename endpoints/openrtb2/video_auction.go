@@ -24,7 +24,6 @@ import (
 
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
-	accountService "github.com/prebid/prebid-server/account"
 	"github.com/prebid/prebid-server/analytics"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/exchange"
@@ -82,7 +81,8 @@ func NewVideoEndpoint(
 		bidderMap,
 		cache,
 		videoEndpointRegexp,
-		ipValidator}).VideoAuctionEndpoint), nil
+		ipValidator,
+		nil}).VideoAuctionEndpoint), nil
 }
 
 /*
@@ -157,7 +157,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	}
 	requestJson, err := ioutil.ReadAll(lr)
 	if err != nil {
-		handleError(&labels, w, []error{err}, &vo, &debugLog)
+		deps.handleError(&labels, w, []error{err}, &vo, &debugLog)
 		return
 	}
 
@@ -176,27 +176,27 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 
 	if err != nil {
 		if deps.cfg.VideoStoredRequestRequired {
-			handleError(&labels, w, []error{err}, &vo, &debugLog)
+			deps.handleError(&labels, w, []error{err}, &vo, &debugLog)
 			return
 		}
 	} else {
 		storedRequest, errs := deps.loadStoredVideoRequest(context.Background(), storedRequestId)
 		if len(errs) > 0 {
-			handleError(&labels, w, errs, &vo, &debugLog)
+			deps.handleError(&labels, w, errs, &vo, &debugLog)
 			return
 		}
 
 		//merge incoming req with stored video req
 		resolvedRequest, err = jsonpatch.MergePatch(storedRequest, requestJson)
 		if err != nil {
-			handleError(&labels, w, []error{err}, &vo, &debugLog)
+			deps.handleError(&labels, w, []error{err}, &vo, &debugLog)
 			return
 		}
 	}
 	//unmarshal and validate combined result
 	videoBidReq, errL, podErrors := deps.parseVideoRequest(resolvedRequest, r.Header)
 	if len(errL) > 0 {
-		handleError(&labels, w, errL, &vo, &debugLog)
+		deps.handleError(&labels, w, errL, &vo, &debugLog)
 		return
 	}
 
@@ -206,7 +206,22 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	if deps.defaultRequest {
 		if err := json.Unmarshal(deps.defReqJSON, bidReq); err != nil {
 			err = fmt.Errorf("Invalid JSON in Default Request Settings: %s", err)
-			handleError(&labels, w, []error{err}, &vo, &debugLog)
+			deps.handleError(&labels, w, []error{err}, &vo, &debugLog)
+			return
+		}
+	}
+
+	// The default request of the account goes over the one of the host, and under the video request merged below
+	accounts := deps.newAccountLookups()
+	accountDefReqJSON, err := accountDefaultRequest(context.Background(), requestAccountID(resolvedRequest), accounts)
+	if err != nil {
+		deps.handleError(&labels, w, []error{err}, &vo, &debugLog)
+		return
+	}
+	if accountDefReqJSON != nil {
+		if err := json.Unmarshal(accountDefReqJSON, bidReq); err != nil {
+			err = fmt.Errorf("the default_request of the account is invalid: %v", err)
+			deps.handleError(&labels, w, []error{err}, &vo, &debugLog)
 			return
 		}
 	}
@@ -234,7 +249,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		}
 		err := errors.New(fmt.Sprintf("all pods are incorrect: %s", strings.Join(resPodErr, "; ")))
 		errL = append(errL, err)
-		handleError(&labels, w, errL, &vo, &debugLog)
+		deps.handleError(&labels, w, errL, &vo, &debugLog)
 		return
 	}
 
@@ -244,9 +259,22 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	// Populate any "missing" OpenRTB fields with info from other sources, (e.g. HTTP request headers).
 	deps.setFieldsImplicitly(r, bidReq) // move after merge
 
-	errL = deps.validateRequest(&openrtb_ext.RequestWrapper{BidRequest: bidReq})
+	reqWrapper := &openrtb_ext.RequestWrapper{BidRequest: bidReq}
+	errL = mergeAccountAliases(context.Background(), reqWrapper, accounts)
+	if errortypes.ContainsFatalError(errL) {
+		deps.handleError(&labels, w, errL, &vo, &debugLog)
+		return
+	}
+
+	errL = append(errL, deps.validateRequest(reqWrapper)...)
 	if errortypes.ContainsFatalError(errL) {
-		handleError(&labels, w, errL, &vo, &debugLog)
+		deps.handleError(&labels, w, errL, &vo, &debugLog)
+		return
+	}
+
+	// The account aliases are written back to request.ext, for the exchange to call them
+	if err := reqWrapper.RebuildRequest(); err != nil {
+		deps.handleError(&labels, w, []error{err}, &vo, &debugLog)
 		return
 	}
 
@@ -273,11 +301,13 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	}
 
 	// Look up account now that we have resolved the pubID value
-	account, acctIDErrs := accountService.GetAccount(ctx, deps.cfg, deps.accounts, labels.PubID)
+	account, acctIDErrs := accounts.getAccount(ctx, labels.PubID)
 	if len(acctIDErrs) > 0 {
-		handleError(&labels, w, acctIDErrs, &vo, &debugLog)
+		deps.handleError(&labels, w, acctIDErrs, &vo, &debugLog)
 		return
 	}
+	applyDebugToken(account, r)
+	vo.Account = account
 
 	secGPC := r.Header.Get("Sec-GPC")
 
@@ -289,6 +319,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 		StartTime:                  start,
 		LegacyLabels:               labels,
 		GlobalPrivacyControlHeader: secGPC,
+		SeatNonBid:                 &vo.SeatNonBid,
 	}
 
 	response, err := deps.ex.HoldAuction(ctx, auctionRequest, &debugLog)
@@ -296,15 +327,15 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	vo.Response = response
 	if err != nil {
 		errL := []error{err}
-		handleError(&labels, w, errL, &vo, &debugLog)
+		deps.handleError(&labels, w, errL, &vo, &debugLog)
 		return
 	}
 
 	//build simplified response
-	bidResp, err := buildVideoResponse(response, podErrors)
+	bidResp, err := buildVideoResponse(response, podErrors, &account.Targeting)
 	if err != nil {
 		errL := []error{err}
-		handleError(&labels, w, errL, &vo, &debugLog)
+		deps.handleError(&labels, w, errL, &vo, &debugLog)
 		return
 	}
 	if bidReq.Test == 1 {
@@ -332,7 +363,7 @@ func (deps *endpointDeps) VideoAuctionEndpoint(w http.ResponseWriter, r *http.Re
 	//resp, err := json.Marshal(response)
 	if err != nil {
 		errL := []error{err}
-		handleError(&labels, w, errL, &vo, &debugLog)
+		deps.handleError(&labels, w, errL, &vo, &debugLog)
 		return
 	}
 
@@ -348,7 +379,7 @@ func cleanupVideoBidRequest(videoReq *openrtb_ext.BidRequestVideo, podErrors []P
 	return videoReq
 }
 
-func handleError(labels *metrics.Labels, w http.ResponseWriter, errL []error, vo *analytics.VideoObject, debugLog *exchange.DebugLog) {
+func (deps *endpointDeps) handleError(labels *metrics.Labels, w http.ResponseWriter, errL []error, vo *analytics.VideoObject, debugLog *exchange.DebugLog) {
 	if debugLog != nil && debugLog.DebugEnabledOrOverridden {
 		if rawUUID, err := uuid.NewV4(); err == nil {
 			debugLog.CacheKey = rawUUID.String()
@@ -374,7 +405,9 @@ func handleError(labels *metrics.Labels, w http.ResponseWriter, errL []error, vo
 	w.WriteHeader(status)
 	vo.Status = status
 	fmt.Fprintf(w, "Critical error while running the video endpoint: %v", errors)
-	glog.Errorf("/openrtb2/video Critical error: %v", errors)
+	if !deps.cfg.Analytics.RequestLog.LogsAllErrors() {
+		glog.Errorf("/openrtb2/video Critical error: %v", errors)
+	}
 	vo.Errors = append(vo.Errors, errL...)
 }
 
@@ -477,7 +510,7 @@ func minMax(array []int) (int, int) {
 	return min, max
 }
 
-func buildVideoResponse(bidresponse *openrtb2.BidResponse, podErrors []PodError) (*openrtb_ext.BidResponseVideo, error) {
+func buildVideoResponse(bidresponse *openrtb2.BidResponse, podErrors []PodError, targeting *config.AccountTargeting) (*openrtb_ext.BidResponseVideo, error) {
 
 	adPods := make([]*openrtb_ext.AdPod, 0)
 	anyBidsReturned := false
@@ -489,7 +522,7 @@ func buildVideoResponse(bidresponse *openrtb2.BidResponse, podErrors []PodError)
 			if err := json.Unmarshal(bid.Ext, &tempRespBidExt); err != nil {
 				return nil, err
 			}
-			if tempRespBidExt.Prebid.Targeting[formatTargetingKey(openrtb_ext.HbVastCacheKey, seatBid.Seat)] == "" {
+			if tempRespBidExt.Prebid.Targeting[formatTargetingKey(openrtb_ext.HbVastCacheKey, seatBid.Seat, targeting)] == "" {
 				continue
 			}
 
@@ -498,9 +531,9 @@ func buildVideoResponse(bidresponse *openrtb2.BidResponse, podErrors []PodError)
 			podId, _ := strconv.ParseInt(podNum, 0, 64)
 
 			videoTargeting := openrtb_ext.VideoTargeting{
-				HbPb:       tempRespBidExt.Prebid.Targeting[formatTargetingKey(openrtb_ext.HbpbConstantKey, seatBid.Seat)],
-				HbPbCatDur: tempRespBidExt.Prebid.Targeting[formatTargetingKey(openrtb_ext.HbCategoryDurationKey, seatBid.Seat)],
-				HbCacheID:  tempRespBidExt.Prebid.Targeting[formatTargetingKey(openrtb_ext.HbVastCacheKey, seatBid.Seat)],
+				HbPb:       tempRespBidExt.Prebid.Targeting[formatTargetingKey(openrtb_ext.HbpbConstantKey, seatBid.Seat, targeting)],
+				HbPbCatDur: tempRespBidExt.Prebid.Targeting[formatTargetingKey(openrtb_ext.HbCategoryDurationKey, seatBid.Seat, targeting)],
+				HbCacheID:  tempRespBidExt.Prebid.Targeting[formatTargetingKey(openrtb_ext.HbVastCacheKey, seatBid.Seat, targeting)],
 			}
 
 			adPod := findAdPod(podId, adPods)
@@ -538,12 +571,9 @@ func buildVideoResponse(bidresponse *openrtb2.BidResponse, podErrors []PodError)
 	return &openrtb_ext.BidResponseVideo{AdPods: adPods}, nil
 }
 
-func formatTargetingKey(key openrtb_ext.TargetingKey, bidderName string) string {
-	fullKey := fmt.Sprintf("%s_%s", string(key), bidderName)
-	if len(fullKey) > exchange.MaxKeyLength {
-		return string(fullKey[0:exchange.MaxKeyLength])
-	}
-	return fullKey
+// formatTargetingKey returns the bidder key of the targeting, named the way the account names its keys
+func formatTargetingKey(key openrtb_ext.TargetingKey, bidderName string, targeting *config.AccountTargeting) string {
+	return targeting.BidderKey(key, openrtb_ext.BidderName(bidderName), exchange.MaxKeyLength)
 }
 
 func findAdPod(podInd int64, pods []*openrtb_ext.AdPod) *openrtb_ext.AdPod {
@@ -573,15 +603,15 @@ func mergeData(videoRequest *openrtb_ext.BidRequestVideo, bidRequest *openrtb2.B
 
 	if videoRequest.Site != nil {
 		bidRequest.Site = videoRequest.Site
-		if &videoRequest.Content != nil {
-			bidRequest.Site.Content = &videoRequest.Content
+		if videoRequest.Content != nil {
+			bidRequest.Site.Content = videoRequest.Content
 		}
 	}
 
 	if videoRequest.App != nil {
 		bidRequest.App = videoRequest.App
-		if &videoRequest.Content != nil {
-			bidRequest.App.Content = &videoRequest.Content
+		if videoRequest.Content != nil {
+			bidRequest.App.Content = videoRequest.Content
 		}
 	}
 
@@ -637,6 +667,7 @@ func createBidExtension(videoRequest *openrtb_ext.BidRequestVideo) ([]byte, erro
 			WithCategory: false,
 		}
 	}
+	inclBrandCat.ExcludeAdvertiserDomains = videoRequest.PodConfig.ExcludeAdvertiserDomains
 
 	var durationRangeSec []int
 	if !videoRequest.PodConfig.RequireExactDuration {
@@ -22,6 +22,7 @@ import (
 	"github.com/prebid/prebid-server/prebid_cache_client"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
 
+	"github.com/buger/jsonparser"
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	gometrics "github.com/rcrowley/go-metrics"
 	"github.com/stretchr/testify/assert"
@@ -179,6 +180,44 @@ func TestCreateBidExtensionExactDurTrueNoPriceRange(t *testing.T) {
 	assert.Equal(t, resExt.Prebid.Targeting.PriceGranularity, openrtb_ext.PriceGranularityFromString("med"), "Price granularity is incorrect")
 }
 
+func TestCreateBidExtensionExcludeAdvertiserDomains(t *testing.T) {
+	testCases := []struct {
+		description          string
+		givenBrandCategory   *openrtb_ext.IncludeBrandCategory
+		expectedWithCategory bool
+	}{
+		{
+			description:          "With the brand categories",
+			givenBrandCategory:   &openrtb_ext.IncludeBrandCategory{PrimaryAdserver: 1},
+			expectedWithCategory: true,
+		},
+		{
+			description:          "Without the brand categories",
+			givenBrandCategory:   nil,
+			expectedWithCategory: false,
+		},
+	}
+
+	for _, test := range testCases {
+		videoRequest := openrtb_ext.BidRequestVideo{
+			IncludeBrandCategory: test.givenBrandCategory,
+			PodConfig: openrtb_ext.PodConfig{
+				DurationRangeSec:         []int{15, 30},
+				ExcludeAdvertiserDomains: true,
+			},
+		}
+		res, err := createBidExtension(&videoRequest)
+		assert.NoError(t, err, test.description+":error should be nil")
+
+		resExt := &openrtb_ext.ExtRequest{}
+		if err := json.Unmarshal(res, &resExt); err != nil {
+			assert.Fail(t, "Unable to unmarshal bid extension")
+		}
+		assert.Equal(t, test.expectedWithCategory, resExt.Prebid.Targeting.IncludeBrandCategory.WithCategory, test.description+":with category")
+		assert.True(t, resExt.Prebid.Targeting.IncludeBrandCategory.ExcludeAdvertiserDomains, test.description+":exclude advertiser domains")
+	}
+}
+
 func TestVideoEndpointDebugQueryTrue(t *testing.T) {
 	ex := &mockExchangeVideo{
 		cache: &mockCacheClient{},
@@ -221,6 +260,128 @@ func TestVideoEndpointDebugQueryTrue(t *testing.T) {
 	assert.Equal(t, resp.AdPods[4].Targeting[0].HbPbCatDur, "20.00_395_30s", "Incorrect number of Ad Pods in response")
 }
 
+func TestVideoEndpointAccountDefaultRequest(t *testing.T) {
+	ex := &mockExchangeVideo{
+		cache: &mockCacheClient{},
+	}
+	reqData, err := ioutil.ReadFile("sample-requests/video/video_valid_sample.json")
+	if err != nil {
+		t.Fatalf("Failed to fetch a valid request: %v", err)
+	}
+	reqBody, err := jsonparser.Set(getRequestPayload(t, reqData), []byte(`{"id":"default_acct"}`), "site", "publisher")
+	if err != nil {
+		t.Fatalf("Failed to set the publisher of the request: %v", err)
+	}
+
+	deps := mockDeps(t, ex)
+	deps.accounts = &macroAccountFetcher{accounts: map[string]json.RawMessage{
+		"default_acct": json.RawMessage(`{"default_request":{"cur":["EUR"],"bcat":["IAB25"]}}`),
+	}}
+	deps.defaultRequest = true
+	deps.defReqJSON = []byte(`{"cur":["USD"],"at":1}`)
+	deps.cfg.MarshalAccountDefaults()
+
+	req := httptest.NewRequest("POST", "/openrtb2/video", bytes.NewReader(reqBody))
+	recorder := httptest.NewRecorder()
+
+	deps.VideoAuctionEndpoint(recorder, req, nil)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	if assert.NotNil(t, ex.lastRequest, "The request should reach the exchange") {
+		assert.Equal(t, []string{"EUR"}, ex.lastRequest.Cur, "The account default request should win over the host one")
+		assert.Equal(t, int64(1), ex.lastRequest.AT, "The host default request should still apply")
+		assert.Equal(t, []string{"IAB25"}, ex.lastRequest.BCat)
+		assert.Equal(t, "prebid.com", ex.lastRequest.Site.Page, "The video request should win over the default requests")
+	}
+}
+
+func TestVideoEndpointAccountAliases(t *testing.T) {
+	ex := &mockExchangeVideo{
+		cache: &mockCacheClient{},
+	}
+	reqData, err := ioutil.ReadFile("sample-requests/video/video_valid_sample.json")
+	if err != nil {
+		t.Fatalf("Failed to fetch a valid request: %v", err)
+	}
+	reqBody := getRequestPayload(t, reqData)
+	for _, path := range [][]string{{"site", "publisher"}, {"podconfig", "pods", "[0]", "configid"}, {"podconfig", "pods", "[1]", "configid"}} {
+		value := []byte(`"alias-config"`)
+		if path[0] == "site" {
+			value = []byte(`{"id":"alias_acct"}`)
+		}
+		if reqBody, err = jsonparser.Set(reqBody, value, path...); err != nil {
+			t.Fatalf("Failed to set the %v of the request: %v", path, err)
+		}
+	}
+
+	deps := mockDeps(t, ex)
+	deps.accounts = &macroAccountFetcher{accounts: map[string]json.RawMessage{
+		"alias_acct": json.RawMessage(`{"aliases":{"appnexusAlias":"appnexus"}}`),
+	}}
+	deps.cfg.MarshalAccountDefaults()
+
+	req := httptest.NewRequest("POST", "/openrtb2/video", bytes.NewReader(reqBody))
+	recorder := httptest.NewRecorder()
+
+	deps.VideoAuctionEndpoint(recorder, req, nil)
+
+	assert.Equal(t, http.StatusOK, recorder.Code, "The account alias should be a known bidder")
+	if assert.NotNil(t, ex.lastRequest, "The request should reach the exchange") {
+		aliases, _, _, err := jsonparser.Get(ex.lastRequest.Ext, "prebid", "aliases")
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"appnexusAlias":"appnexus"}`, string(aliases), "The account aliases should be sent to the exchange")
+	}
+}
+
+func TestVideoEndpointAccountDebugToken(t *testing.T) {
+	testCases := []struct {
+		description        string
+		givenToken         string
+		expectedDebugAllow bool
+	}{
+		{
+			description:        "Correct token",
+			givenToken:         "secret-token",
+			expectedDebugAllow: true,
+		},
+		{
+			description:        "Wrong token",
+			givenToken:         "wrong-token",
+			expectedDebugAllow: false,
+		},
+		{
+			description:        "Missing token",
+			givenToken:         "",
+			expectedDebugAllow: false,
+		},
+	}
+
+	reqData, err := ioutil.ReadFile("sample-requests/video/video_valid_sample.json")
+	if err != nil {
+		t.Fatalf("Failed to fetch a valid request: %v", err)
+	}
+	reqBody := string(getRequestPayload(t, reqData))
+
+	for _, test := range testCases {
+		ex := &mockExchangeVideo{
+			cache: &mockCacheClient{},
+		}
+		deps := mockDeps(t, ex)
+		deps.cfg.AccountDefaults = config.Account{DebugAllow: true, DebugToken: "secret-token"}
+
+		req := httptest.NewRequest("POST", "/openrtb2/video", strings.NewReader(reqBody))
+		if test.givenToken != "" {
+			req.Header.Set(exchange.DebugTokenHeader, test.givenToken)
+		}
+		recorder := httptest.NewRecorder()
+
+		deps.VideoAuctionEndpoint(recorder, req, nil)
+
+		assert.Equal(t, http.StatusOK, recorder.Code, test.description+":status")
+		assert.Equal(t, test.expectedDebugAllow, ex.lastAccount.DebugAllow, test.description+":debug_allow")
+	}
+}
+
 func TestVideoEndpointDebugQueryFalse(t *testing.T) {
 	ex := &mockExchangeVideo{
 		cache: &mockCacheClient{},
@@ -702,7 +863,7 @@ func TestVideoBuildVideoResponseMissedCacheForOneBid(t *testing.T) {
 	seatBids = append(seatBids, seatBid)
 	openRtbBidResp.SeatBid = seatBids
 
-	bidRespVideo, err := buildVideoResponse(&openRtbBidResp, podErrors)
+	bidRespVideo, err := buildVideoResponse(&openRtbBidResp, podErrors, &config.AccountTargeting{})
 	assert.NoError(t, err, "Should be no error")
 	assert.Len(t, bidRespVideo.AdPods, 1, "AdPods length should be 1")
 	assert.Len(t, bidRespVideo.AdPods[0].Targeting, 2, "AdPod Targeting length should be 2")
@@ -739,7 +900,7 @@ func TestVideoBuildVideoResponseMissedCacheForAllBids(t *testing.T) {
 	seatBids = append(seatBids, seatBid)
 	openRtbBidResp.SeatBid = seatBids
 
-	bidRespVideo, err := buildVideoResponse(&openRtbBidResp, podErrors)
+	bidRespVideo, err := buildVideoResponse(&openRtbBidResp, podErrors, &config.AccountTargeting{})
 	assert.Nil(t, bidRespVideo, "bid response should be nil")
 	assert.Equal(t, "caching failed for all bids", err.Error(), "error should be caching failed for all bids")
 }
@@ -779,7 +940,7 @@ func TestVideoBuildVideoResponsePodErrors(t *testing.T) {
 	podErr2.PodIndex = 2
 	podErrors = append(podErrors, podErr2)
 
-	bidRespVideo, err := buildVideoResponse(&openRtbBidResp, podErrors)
+	bidRespVideo, err := buildVideoResponse(&openRtbBidResp, podErrors, &config.AccountTargeting{})
 	assert.NoError(t, err, "Error should be nil")
 	assert.Len(t, bidRespVideo.AdPods, 3, "AdPods length should be 3")
 	assert.Len(t, bidRespVideo.AdPods[0].Targeting, 2, "First ad pod should be correct and contain 2 targeting elements")
@@ -791,11 +952,44 @@ func TestVideoBuildVideoResponseNoBids(t *testing.T) {
 	openRtbBidResp := openrtb2.BidResponse{}
 	podErrors := make([]PodError, 0, 0)
 	openRtbBidResp.SeatBid = make([]openrtb2.SeatBid, 0)
-	bidRespVideo, err := buildVideoResponse(&openRtbBidResp, podErrors)
+	bidRespVideo, err := buildVideoResponse(&openRtbBidResp, podErrors, &config.AccountTargeting{})
 	assert.NoError(t, err, "Error should be nil")
 	assert.Len(t, bidRespVideo.AdPods, 0, "AdPods length should be 0")
 }
 
+func TestMergeDataContent(t *testing.T) {
+	testCases := []struct {
+		description     string
+		givenContent    *openrtb2.Content
+		expectedContent *openrtb2.Content
+	}{
+		{
+			description:     "Content passed through",
+			givenContent:    &openrtb2.Content{Series: "TvName", Genre: "news", Language: "en"},
+			expectedContent: &openrtb2.Content{Series: "TvName", Genre: "news", Language: "en"},
+		},
+		{
+			description:     "No content",
+			givenContent:    nil,
+			expectedContent: nil,
+		},
+	}
+
+	for _, test := range testCases {
+		bidReq := &openrtb2.BidRequest{}
+		videoReq := &openrtb_ext.BidRequestVideo{
+			Site:    &openrtb2.Site{Page: "site.com/index"},
+			App:     &openrtb2.App{Bundle: "test.bundle"},
+			Content: test.givenContent,
+		}
+
+		assert.NoError(t, mergeData(videoReq, bidReq), test.description)
+
+		assert.Equal(t, test.expectedContent, bidReq.Site.Content, test.description+":site content")
+		assert.Equal(t, test.expectedContent, bidReq.App.Content, test.description+":app content")
+	}
+}
+
 func TestMergeOpenRTBToVideoRequest(t *testing.T) {
 	var bidReq = &openrtb2.BidRequest{}
 	var videoReq = &openrtb_ext.BidRequestVideo{}
@@ -849,6 +1043,7 @@ func TestMergeOpenRTBToVideoRequest(t *testing.T) {
 }
 
 func TestHandleError(t *testing.T) {
+	deps := &endpointDeps{cfg: &config.Configuration{}}
 	vo := analytics.VideoObject{
 		Status: 200,
 		Errors: make([]error, 0),
@@ -865,7 +1060,7 @@ func TestHandleError(t *testing.T) {
 	recorder := httptest.NewRecorder()
 	err1 := errors.New("Error for testing handleError 1")
 	err2 := errors.New("Error for testing handleError 2")
-	handleError(&labels, recorder, []error{err1, err2}, &vo, nil)
+	deps.handleError(&labels, recorder, []error{err1, err2}, &vo, nil)
 
 	assert.Equal(t, metrics.RequestStatusErr, labels.RequestStatus, "labels.RequestStatus should indicate an error")
 	assert.Equal(t, 500, recorder.Code, "Error status should be written to writer")
@@ -1020,6 +1215,7 @@ func TestParseVideoRequestWithDecodedUserAgentInHeader(t *testing.T) {
 }
 
 func TestHandleErrorDebugLog(t *testing.T) {
+	deps := &endpointDeps{cfg: &config.Configuration{}}
 	vo := analytics.VideoObject{
 		Status: 200,
 		Errors: make([]error, 0),
@@ -1049,7 +1245,7 @@ func TestHandleErrorDebugLog(t *testing.T) {
 		DebugOverride:            false,
 		DebugEnabledOrOverridden: true,
 	}
-	handleError(&labels, recorder, []error{err1, err2}, &vo, &debugLog)
+	deps.handleError(&labels, recorder, []error{err1, err2}, &vo, &debugLog)
 
 	assert.Equal(t, metrics.RequestStatusErr, labels.RequestStatus, "labels.RequestStatus should indicate an error")
 	assert.Equal(t, 500, recorder.Code, "Error status should be written to writer")
@@ -1195,15 +1391,20 @@ func TestVideoEndpointAppendBidderNames(t *testing.T) {
 }
 
 func TestFormatTargetingKey(t *testing.T) {
-	res := formatTargetingKey(openrtb_ext.HbCategoryDurationKey, "appnexus")
+	res := formatTargetingKey(openrtb_ext.HbCategoryDurationKey, "appnexus", &config.AccountTargeting{})
 	assert.Equal(t, "hb_pb_cat_dur_appnex", res, "Tergeting key constructed incorrectly")
 }
 
 func TestFormatTargetingKeyLongKey(t *testing.T) {
-	res := formatTargetingKey(openrtb_ext.HbpbConstantKey, "20.00")
+	res := formatTargetingKey(openrtb_ext.HbpbConstantKey, "20.00", &config.AccountTargeting{})
 	assert.Equal(t, "hb_pb_20.00", res, "Tergeting key constructed incorrectly")
 }
 
+func TestFormatTargetingKeyAccountTargeting(t *testing.T) {
+	res := formatTargetingKey(openrtb_ext.HbCategoryDurationKey, "appnexus", &config.AccountTargeting{Prefix: "pbs", MaxKeyLength: 30})
+	assert.Equal(t, "pbs_pb_cat_dur_appnexus", res, "Tergeting key constructed incorrectly")
+}
+
 func mockDepsWithMetrics(t *testing.T, ex *mockExchangeVideo) (*endpointDeps, *metrics.Metrics, *mockAnalyticsModule) {
 	mockModule := &mockAnalyticsModule{}
 
@@ -1226,6 +1427,7 @@ func mockDepsWithMetrics(t *testing.T, ex *mockExchangeVideo) (*endpointDeps, *m
 		nil,
 		nil,
 		hardcodedResponseIPValidator{response: true},
+		nil,
 	}
 	return deps, metrics, mockModule
 }
@@ -1269,6 +1471,7 @@ func mockDeps(t *testing.T, ex *mockExchangeVideo) *endpointDeps {
 		ex.cache,
 		regexp.MustCompile(`[<>]`),
 		hardcodedResponseIPValidator{response: true},
+		nil,
 	}
 
 	return deps
@@ -1292,6 +1495,7 @@ func mockDepsAppendBidderNames(t *testing.T, ex *mockExchangeAppendBidderNames)
 		ex.cache,
 		regexp.MustCompile(`[<>]`),
 		hardcodedResponseIPValidator{response: true},
+		nil,
 	}
 
 	return deps
@@ -1315,6 +1519,7 @@ func mockDepsNoBids(t *testing.T, ex *mockExchangeVideoNoBids) *endpointDeps {
 		ex.cache,
 		regexp.MustCompile(`[<>]`),
 		hardcodedResponseIPValidator{response: true},
+		nil,
 	}
 
 	return edep
@@ -1342,13 +1547,19 @@ func (cf mockVideoStoredReqFetcher) FetchRequests(ctx context.Context, requestID
 	return testVideoStoredRequestData, testVideoStoredImpData, nil
 }
 
+func (cf mockVideoStoredReqFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	return nil, nil
+}
+
 type mockExchangeVideo struct {
 	lastRequest *openrtb2.BidRequest
+	lastAccount config.Account
 	cache       *mockCacheClient
 }
 
 func (m *mockExchangeVideo) HoldAuction(ctx context.Context, r exchange.AuctionRequest, debugLog *exchange.DebugLog) (*openrtb2.BidResponse, error) {
 	m.lastRequest = r.BidRequest
+	m.lastAccount = r.Account
 	if debugLog != nil && debugLog.Enabled {
 		m.cache.called = true
 	}
@@ -1430,6 +1641,7 @@ var testVideoStoredImpData = map[string]json.RawMessage{
 	"fba10607-0c12-43d1-ad07-b8a513bc75d6": json.RawMessage(`{"ext": {"appnexus": {"placementId": 14997137}}}`),
 	"8b452b41-2681-4a20-9086-6f16ffad7773": json.RawMessage(`{"ext": {"appnexus": {"placementId": 15016213}}}`),
 	"87d82a45-35c3-46cc-9315-2e3eeb91d0f2": json.RawMessage(`{"ext": {"appnexus": {"placementId": 15062775}}}`),
+	"alias-config":                         json.RawMessage(`{"ext": {"appnexusAlias": {"placementId": 15062775}}}`),
 }
 
 var testVideoStoredRequestData = map[string]json.RawMessage{
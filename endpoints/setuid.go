@@ -14,6 +14,10 @@ import (
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/gdpr"
 	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/privacy"
+	"github.com/prebid/prebid-server/privacy/ccpa"
+	gdprPrivacy "github.com/prebid/prebid-server/privacy/gdpr"
+	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/usersync"
 	"github.com/prebid/prebid-server/util/httputil"
 )
@@ -26,7 +30,8 @@ const (
 	chromeiOSStrLen = len(chromeiOSStr)
 )
 
-func NewSetUIDEndpoint(cfg config.HostCookie, syncersByBidder map[string]usersync.Syncer, perms gdpr.Permissions, pbsanalytics analytics.PBSAnalyticsModule, metricsEngine metrics.MetricsEngine) httprouter.Handle {
+func NewSetUIDEndpoint(pbsConfig *config.Configuration, syncersByBidder map[string]usersync.Syncer, perms gdpr.Permissions, accountsFetcher stored_requests.AccountFetcher, pbsanalytics analytics.PBSAnalyticsModule, metricsEngine metrics.MetricsEngine) httprouter.Handle {
+	cfg := pbsConfig.HostCookie
 	cookieTTL := time.Duration(cfg.TTL) * 24 * time.Hour
 
 	// convert map of syncers by bidder to map of syncers by key
@@ -86,6 +91,23 @@ func NewSetUIDEndpoint(cfg config.HostCookie, syncersByBidder map[string]usersyn
 			return
 		}
 
+		account, err := getAccount(pbsConfig, accountsFetcher, query.Get("account"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			metricsEngine.RecordSetUid(metrics.SetUidBadRequest)
+			so.Status = http.StatusBadRequest
+			return
+		}
+
+		if !activityAllowsSetUID(account, syncer, r) {
+			w.WriteHeader(http.StatusUnavailableForLegalReasons)
+			w.Write([]byte("The account does not allow the bidder to sync"))
+			metricsEngine.RecordSetUid(metrics.SetUidAccountBlocked)
+			so.Status = http.StatusUnavailableForLegalReasons
+			return
+		}
+
 		uid := query.Get("uid")
 		so.UID = uid
 
@@ -103,6 +125,19 @@ func NewSetUIDEndpoint(cfg config.HostCookie, syncersByBidder map[string]usersyn
 		setSiteCookie := siteCookieCheck(r.UserAgent())
 		pc.SetCookieOnResponse(w, setSiteCookie, &cfg, cookieTTL)
 
+		// The bidders continuing their sync flow after Prebid Server get their redirect in place of the response format
+		chainRedirect, err := syncer.GetChainRedirect(uid, privacy.Policies{
+			GDPR: gdprPrivacy.Policy{Signal: query.Get("gdpr"), Consent: query.Get("gdpr_consent")},
+			CCPA: ccpa.Policy{Consent: query.Get("us_privacy")},
+		})
+		if err != nil {
+			so.Errors = append(so.Errors, err)
+		} else if chainRedirect != "" {
+			http.Redirect(w, r, chainRedirect, http.StatusFound)
+			so.Status = http.StatusFound
+			return
+		}
+
 		switch responseFormat {
 		case "i":
 			w.Header().Add("Content-Type", httputil.Pixel1x1PNG.ContentType)
@@ -132,6 +167,14 @@ func getSyncer(query url.Values, syncersByKey map[string]usersync.Syncer) (users
 	return syncer, nil
 }
 
+// activityAllowsSetUID tells if the syncUser activity controls of the account allow the bidder of the syncer to sync
+// the user. The bidders sharing a syncer are matched through its key.
+func activityAllowsSetUID(account *config.Account, syncer usersync.Syncer, r *http.Request) bool {
+	activityControl := privacy.NewActivityControl(account.Privacy)
+	component := privacy.Component{Type: config.ComponentTypeBidder, Name: syncer.Key()}
+	return activityControl.Allow(privacy.ActivitySyncUser, component, privacy.ActivityRequest{GPC: parseGPCHeader(r)})
+}
+
 // getResponseFormat reads the format query parameter or falls back to the syncer's default.
 // Returns either "b" (iframe), "i" (redirect), or an empty string "" (legacy behavior of an
 // empty response body with no content type).
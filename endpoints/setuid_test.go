@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -17,6 +18,7 @@ import (
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/usersync"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
 	analyticsConf "github.com/prebid/prebid-server/analytics/config"
 	metricsConf "github.com/prebid/prebid-server/metrics/config"
@@ -373,6 +375,145 @@ func TestSetUIDEndpointMetrics(t *testing.T) {
 	}
 }
 
+func TestSetUIDEndpointAccount(t *testing.T) {
+	testCases := []struct {
+		description          string
+		uri                  string
+		givenGPC             string
+		expectedStatusCode   int
+		expectedBody         string
+		expectedSetUIDStatus metrics.SetUidStatus
+	}{
+		{
+			description:          "Account Allows Bidder",
+			uri:                  "/setuid?bidder=pubmatic&uid=123&account=blocking",
+			expectedStatusCode:   http.StatusOK,
+			expectedSetUIDStatus: metrics.SetUidOK,
+		},
+		{
+			description:          "Account Blocks Bidder",
+			uri:                  "/setuid?bidder=rubicon&uid=123&account=blocking",
+			expectedStatusCode:   http.StatusUnavailableForLegalReasons,
+			expectedBody:         "The account does not allow the bidder to sync",
+			expectedSetUIDStatus: metrics.SetUidAccountBlocked,
+		},
+		{
+			description:          "Account Blocks Bidder For GPC",
+			uri:                  "/setuid?bidder=pubmatic&uid=123&account=blocking",
+			givenGPC:             "1",
+			expectedStatusCode:   http.StatusUnavailableForLegalReasons,
+			expectedBody:         "The account does not allow the bidder to sync",
+			expectedSetUIDStatus: metrics.SetUidAccountBlocked,
+		},
+		{
+			description:          "Account Defaults Block Bidder",
+			uri:                  "/setuid?bidder=adnxs&uid=123",
+			expectedStatusCode:   http.StatusUnavailableForLegalReasons,
+			expectedBody:         "The account does not allow the bidder to sync",
+			expectedSetUIDStatus: metrics.SetUidAccountBlocked,
+		},
+		{
+			description:          "Account Blacklisted",
+			uri:                  "/setuid?bidder=pubmatic&uid=123&account=blacklisted",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedBody:         "Prebid-server has disabled Account ID: blacklisted, please reach out to the prebid server host.",
+			expectedSetUIDStatus: metrics.SetUidBadRequest,
+		},
+	}
+
+	var (
+		defaultsPrivacy = config.AccountPrivacy{AllowActivities: config.AllowActivities{SyncUser: config.Activity{
+			Rules: []config.ActivityRule{{Condition: config.ActivityCondition{ComponentName: []string{"adnxs"}}, Allow: false}},
+		}}}
+		accountsFetcher = FakeAccountsFetcher{AccountData: map[string]json.RawMessage{
+			"blocking": json.RawMessage(`{"privacy":{"allowactivities":{"syncUser":{"rules":[{"condition":{"componentName":["rubicon"]},"allow":false},{"condition":{"gpc":"1"},"allow":false}]}}}}`),
+		}}
+		syncersByBidder = map[string]usersync.Syncer{
+			"pubmatic": fakeSyncer{key: "pubmatic", defaultSyncType: usersync.SyncTypeIFrame},
+			"rubicon":  fakeSyncer{key: "rubicon", defaultSyncType: usersync.SyncTypeIFrame},
+			"appnexus": fakeSyncer{key: "adnxs", defaultSyncType: usersync.SyncTypeIFrame},
+		}
+		perms = &mockPermsSetUID{allowHost: true, personalInfoAllowed: true}
+	)
+	pbsConfig := &config.Configuration{
+		AccountDefaults:    config.Account{Privacy: defaultsPrivacy},
+		BlacklistedAcctMap: map[string]bool{"blacklisted": true},
+	}
+	assert.NoError(t, pbsConfig.MarshalAccountDefaults())
+	analytics := analyticsConf.NewPBSAnalytics(&pbsConfig.Analytics)
+
+	for _, test := range testCases {
+		metricsEngine := &metrics.MetricsEngineMock{}
+		metricsEngine.On("RecordSetUid", test.expectedSetUIDStatus).Once()
+		metricsEngine.On("RecordSyncerSet", mock.Anything, mock.Anything).Maybe()
+
+		request := httptest.NewRequest("GET", test.uri, nil)
+		if test.givenGPC != "" {
+			request.Header.Set("Sec-GPC", test.givenGPC)
+		}
+		response := httptest.NewRecorder()
+		endpoint := NewSetUIDEndpoint(pbsConfig, syncersByBidder, perms, accountsFetcher, analytics, metricsEngine)
+		endpoint(response, request, nil)
+
+		assert.Equal(t, test.expectedStatusCode, response.Code, test.description+":status_code")
+		if test.expectedBody != "" {
+			assert.Equal(t, test.expectedBody, response.Body.String(), test.description+":body")
+		}
+		metricsEngine.AssertExpectations(t)
+	}
+}
+
+func TestSetUIDEndpointChainRedirect(t *testing.T) {
+	syncersByBidder := map[string]usersync.Syncer{
+		"pubmatic": fakeSyncer{key: "pubmatic", defaultSyncType: usersync.SyncTypeRedirect, chainRedirect: "https://pubmatic.com/chain"},
+		"rubicon":  fakeSyncer{key: "rubicon", defaultSyncType: usersync.SyncTypeRedirect},
+	}
+
+	testCases := []struct {
+		description        string
+		uri                string
+		expectedStatusCode int
+		expectedLocation   string
+		expectedSyncs      map[string]string
+	}{
+		{
+			description:        "Chained Redirect",
+			uri:                "/setuid?bidder=pubmatic&uid=123&gdpr=0&us_privacy=1YNN",
+			expectedStatusCode: http.StatusFound,
+			expectedLocation:   "https://pubmatic.com/chain?uid=123&gdpr=0&us_privacy=1YNN",
+			expectedSyncs:      map[string]string{"pubmatic": "123"},
+		},
+		{
+			description:        "Chained Redirect Overrides Format",
+			uri:                "/setuid?bidder=pubmatic&uid=123&f=b",
+			expectedStatusCode: http.StatusFound,
+			expectedLocation:   "https://pubmatic.com/chain?uid=123&gdpr=&us_privacy=",
+			expectedSyncs:      map[string]string{"pubmatic": "123"},
+		},
+		{
+			description:        "No Chained Redirect",
+			uri:                "/setuid?bidder=rubicon&uid=123",
+			expectedStatusCode: http.StatusOK,
+			expectedSyncs:      map[string]string{"rubicon": "123"},
+		},
+	}
+
+	for _, test := range testCases {
+		cfg := &config.Configuration{}
+		metricsEngine := &metricsConf.DummyMetricsEngine{}
+		perms := &mockPermsSetUID{allowHost: true, personalInfoAllowed: true}
+		analytics := analyticsConf.NewPBSAnalytics(&cfg.Analytics)
+
+		response := httptest.NewRecorder()
+		endpoint := NewSetUIDEndpoint(cfg, syncersByBidder, perms, FakeAccountsFetcher{}, analytics, metricsEngine)
+		endpoint(response, httptest.NewRequest("GET", test.uri, nil), nil)
+
+		assert.Equal(t, test.expectedStatusCode, response.Code, test.description+":status_code")
+		assert.Equal(t, test.expectedLocation, response.Header().Get("Location"), test.description+":location")
+		assertHasSyncs(t, test.description, response, test.expectedSyncs)
+	}
+}
+
 func TestOptedOut(t *testing.T) {
 	request := httptest.NewRequest("GET", "/setuid?bidder=pubmatic&uid=123", nil)
 	cookie := usersync.NewCookie()
@@ -530,7 +671,7 @@ func doRequest(req *http.Request, metrics metrics.MetricsEngine, syncersBidderNa
 		syncersByBidder[bidderName] = fakeSyncer{key: syncerKey, defaultSyncType: usersync.SyncTypeIFrame}
 	}
 
-	endpoint := NewSetUIDEndpoint(cfg.HostCookie, syncersByBidder, perms, analytics, metrics)
+	endpoint := NewSetUIDEndpoint(&cfg, syncersByBidder, perms, FakeAccountsFetcher{}, analytics, metrics)
 	response := httptest.NewRecorder()
 	endpoint(response, req, nil)
 	return response
@@ -580,6 +721,7 @@ func (g *mockPermsSetUID) AuctionActivitiesAllowed(ctx context.Context, bidder o
 type fakeSyncer struct {
 	key             string
 	defaultSyncType usersync.SyncType
+	chainRedirect   string
 }
 
 func (s fakeSyncer) Key() string {
@@ -597,3 +739,10 @@ func (s fakeSyncer) SupportsType(syncTypes []usersync.SyncType) bool {
 func (s fakeSyncer) GetSync(syncTypes []usersync.SyncType, privacyPolicies privacy.Policies) (usersync.Sync, error) {
 	return usersync.Sync{}, nil
 }
+
+func (s fakeSyncer) GetChainRedirect(uid string, privacyPolicies privacy.Policies) (string, error) {
+	if s.chainRedirect == "" {
+		return "", nil
+	}
+	return s.chainRedirect + "?uid=" + uid + "&gdpr=" + privacyPolicies.GDPR.Signal + "&us_privacy=" + privacyPolicies.CCPA.Consent, nil
+}
@@ -12,6 +12,7 @@ const (
 	BlacklistedAcctErrorCode
 	AcctRequiredErrorCode
 	NoConversionRateErrorCode
+	BidderCircuitOpenErrorCode
 )
 
 // Defines numeric codes for well-known warnings.
@@ -21,6 +22,17 @@ const (
 	AccountLevelDebugDisabledWarningCode
 	BidderLevelDebugDisabledWarningCode
 	DisabledCurrencyConversionWarningCode
+	PrivateAuctionBidRejectedWarningCode
+	PrivateAuctionWithoutDealsWarningCode
+	InvalidBidderParamsWarningCode
+	PriceGranularityGapWarningCode
+	BidBelowFloorWarningCode
+	BidAdjustmentNotAppliedWarningCode
+	BidValidationWarningCode
+	MultiBidWarningCode
+	FirstPartyDataWarningCode
+	InvalidStructuredUserAgentWarningCode
+	InconsistentUserAgentWarningCode
 )
 
 // Coder provides an error or warning code with severity.
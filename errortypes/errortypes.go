@@ -165,6 +165,24 @@ func (err *BidderTemporarilyDisabled) Severity() Severity {
 	return SeverityWarning
 }
 
+// BidderCircuitOpen should be used when a bidder wasn't called because its circuit breaker is open
+// after sustained failures. The auction goes on without the bidder, so it's a warning.
+type BidderCircuitOpen struct {
+	Message string
+}
+
+func (err *BidderCircuitOpen) Error() string {
+	return err.Message
+}
+
+func (err *BidderCircuitOpen) Code() int {
+	return BidderCircuitOpenErrorCode
+}
+
+func (err *BidderCircuitOpen) Severity() Severity {
+	return SeverityWarning
+}
+
 // Warning is a generic non-fatal error.
 type Warning struct {
 	Message     string
@@ -0,0 +1,72 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// adPod is a long-form video ad pod, made of the imps sharing the same imp.video.ext.podid. Each pod is auctioned on
+// its own, so the competitive separation of its bids doesn't reach into the other pods of the request.
+type adPod struct {
+	id string
+	// duration is the total duration of the pod. 0 leaves the duration of its ads unbounded.
+	duration int
+	// requiredDurations are the only durations allowed for the ads of the pod, if any
+	requiredDurations []int
+}
+
+// getAdPods maps the ids of the imps of the request to the ad pod they belong to. Imps outside of any pod aren't
+// listed. The first imp of a pod setting its duration or required durations sets them for the whole pod.
+func getAdPods(bidRequest *openrtb2.BidRequest) (map[string]*adPod, []error) {
+	var errs []error
+	pods := make(map[string]*adPod)
+	podsByID := make(map[string]*adPod)
+
+	for _, imp := range bidRequest.Imp {
+		if imp.Video == nil || len(imp.Video.Ext) == 0 {
+			continue
+		}
+		var videoExt openrtb_ext.ExtImpVideo
+		if err := json.Unmarshal(imp.Video.Ext, &videoExt); err != nil {
+			errs = append(errs, fmt.Errorf("imp %s isn't part of an ad pod, its video.ext is invalid: %v", imp.ID, err))
+			continue
+		}
+		if videoExt.PodID == "" {
+			continue
+		}
+
+		pod, ok := podsByID[videoExt.PodID]
+		if !ok {
+			pod = &adPod{id: videoExt.PodID}
+			podsByID[videoExt.PodID] = pod
+		}
+		if pod.duration == 0 {
+			pod.duration = videoExt.PodDur
+		}
+		if len(pod.requiredDurations) == 0 {
+			pod.requiredDurations = videoExt.RqdDurs
+		}
+		pods[imp.ID] = pod
+	}
+	return pods, errs
+}
+
+// rejectDuration returns the reason for rejecting an ad of the given duration from the pod, or an empty string if the
+// ad fits in the pod.
+func (pod *adPod) rejectDuration(duration int) string {
+	if pod.duration > 0 && duration > pod.duration {
+		return "Bid duration exceeds the ad pod duration"
+	}
+	if len(pod.requiredDurations) == 0 {
+		return ""
+	}
+	for _, requiredDuration := range pod.requiredDurations {
+		if duration == requiredDuration {
+			return ""
+		}
+	}
+	return "Bid duration isn't one of the required durations of the ad pod"
+}
@@ -0,0 +1,125 @@
+package exchange
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAdPods(t *testing.T) {
+	bidRequest := &openrtb2.BidRequest{
+		Imp: []openrtb2.Imp{
+			{ID: "pod1_0", Video: &openrtb2.Video{Ext: json.RawMessage(`{"podid":"pod1","poddur":60}`)}},
+			{ID: "pod1_1", Video: &openrtb2.Video{Ext: json.RawMessage(`{"podid":"pod1","poddur":90,"rqddurs":[15,30]}`)}},
+			{ID: "pod2_0", Video: &openrtb2.Video{Ext: json.RawMessage(`{"podid":"pod2"}`)}},
+			{ID: "no-pod", Video: &openrtb2.Video{Ext: json.RawMessage(`{"plcmt":1}`)}},
+			{ID: "banner", Banner: &openrtb2.Banner{}},
+			{ID: "invalid", Video: &openrtb2.Video{Ext: json.RawMessage(`{"podid":1}`)}},
+		},
+	}
+
+	pods, errs := getAdPods(bidRequest)
+
+	pod1 := &adPod{id: "pod1", duration: 60, requiredDurations: []int{15, 30}}
+	assert.Equal(t, map[string]*adPod{
+		"pod1_0": pod1,
+		"pod1_1": pod1,
+		"pod2_0": {id: "pod2"},
+	}, pods)
+	assert.Same(t, pods["pod1_0"], pods["pod1_1"], "imps of the same pod share it")
+	if assert.Len(t, errs, 1) {
+		assert.Contains(t, errs[0].Error(), "imp invalid isn't part of an ad pod, its video.ext is invalid")
+	}
+}
+
+func TestAdPodRejectDuration(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenPod       adPod
+		givenDuration  int
+		expectedReason string
+	}{
+		{
+			description:   "Unbounded pod",
+			givenPod:      adPod{id: "pod"},
+			givenDuration: 120,
+		},
+		{
+			description:   "Fits in the pod duration",
+			givenPod:      adPod{id: "pod", duration: 60},
+			givenDuration: 60,
+		},
+		{
+			description:    "Exceeds the pod duration",
+			givenPod:       adPod{id: "pod", duration: 60},
+			givenDuration:  61,
+			expectedReason: "Bid duration exceeds the ad pod duration",
+		},
+		{
+			description:   "Required duration",
+			givenPod:      adPod{id: "pod", requiredDurations: []int{15, 30}},
+			givenDuration: 30,
+		},
+		{
+			description:    "Not a required duration",
+			givenPod:       adPod{id: "pod", requiredDurations: []int{15, 30}},
+			givenDuration:  20,
+			expectedReason: "Bid duration isn't one of the required durations of the ad pod",
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedReason, test.givenPod.rejectDuration(test.givenDuration), test.description)
+	}
+}
+
+func TestCategoryMappingAdPods(t *testing.T) {
+	categoriesFetcher, err := newCategoryFetcher("./test/category-mapping")
+	if err != nil {
+		t.Errorf("Failed to create a category Fetcher: %v", err)
+	}
+
+	requestExt := newExtRequest()
+	requestExt.Prebid.Targeting.DurationRangeSec = []int{15, 30, 60}
+	targData := &targetData{
+		priceGranularity: requestExt.Prebid.Targeting.PriceGranularity,
+		includeWinners:   true,
+	}
+	pod1 := &adPod{id: "pod1", duration: 60}
+	pod2 := &adPod{id: "pod2", requiredDurations: []int{15, 30}}
+	adPods := map[string]*adPod{"pod1_0": pod1, "pod1_1": pod1, "pod2_0": pod2}
+
+	videoBid := func(id, impID string, price float64, cat string, duration int) *pbsOrtbBid {
+		return &pbsOrtbBid{
+			bid:      &openrtb2.Bid{ID: id, ImpID: impID, Price: price, Cat: []string{cat}},
+			bidType:  openrtb_ext.BidTypeVideo,
+			bidVideo: &openrtb_ext.ExtBidPrebidVideo{Duration: duration},
+		}
+	}
+	adapterBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		"appnexus": {bids: []*pbsOrtbBid{
+			videoBid("pod1-low", "pod1_0", 10, "IAB1-3", 30),
+			videoBid("pod1-high", "pod1_1", 12, "IAB1-3", 25),
+			videoBid("pod2-same-category", "pod2_0", 8, "IAB1-3", 30),
+			videoBid("pod2-wrong-duration", "pod2_0", 6, "IAB1-4", 20),
+			videoBid("pod1-too-long", "pod1_0", 4, "IAB1-4", 90),
+		}, currency: "USD"},
+	}
+
+	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, adPods, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"pod1-high":          "12.00_Electronics_30s",
+		"pod2-same-category": "8.00_Electronics_30s",
+	}, bidCategory, "bids are deduplicated within their pod only")
+	assert.ElementsMatch(t, []string{
+		"bid rejected [bid ID: pod1-low] reason: Bid was deduplicated",
+		"bid rejected [bid ID: pod2-wrong-duration] reason: Bid duration isn't one of the required durations of the ad pod",
+		"bid rejected [bid ID: pod1-too-long] reason: Bid duration exceeds the ad pod duration",
+	}, rejections)
+	assert.Len(t, adapterBids["appnexus"].bids, 2)
+}
@@ -1,17 +1,21 @@
 package exchange
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/adapters/genericortb"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
 func BuildAdapters(client *http.Client, cfg *config.Configuration, infos config.BidderInfos, me metrics.MetricsEngine) (map[openrtb_ext.BidderName]adaptedBidder, []error) {
-	bidders, errs := buildBidders(cfg.Adapters, infos, newAdapterBuilders())
+	builders := newAdapterBuilders()
+	bidders, errs := buildBidders(cfg.Adapters, infos, builders)
 	if len(errs) > 0 {
 		return nil, errs
 	}
@@ -19,13 +23,80 @@ func BuildAdapters(client *http.Client, cfg *config.Configuration, infos config.
 	exchangeBidders := make(map[openrtb_ext.BidderName]adaptedBidder, len(bidders))
 	for bidderName, bidder := range bidders {
 		info := infos[string(bidderName)]
-		exchangeBidder := adaptBidder(bidder, client, cfg, me, bidderName, info.Debug)
+		bidderClient := bidderHTTPClient(client, info.HTTPClient)
+		exchangeBidder := adaptBidder(bidder, bidderClient, cfg, me, bidderName, info.Debug, info.Compression)
 		exchangeBidder = addValidatedBidderMiddleware(exchangeBidder)
+		if cfg.AllowAliasEndpointOverrides {
+			exchangeBidder = newEndpointOverrideBidder(exchangeBidder, endpointBidderBuilder(bidderName, cfg, info, builders, bidderClient, me))
+		}
 		exchangeBidders[bidderName] = exchangeBidder
 	}
 	return exchangeBidders, nil
 }
 
+// endpointBidderBuilder returns the function building a bidder as BuildAdapters does, but on another endpoint than
+// the one of its config
+func endpointBidderBuilder(bidderName openrtb_ext.BidderName, cfg *config.Configuration, info config.BidderInfo, builders map[openrtb_ext.BidderName]adapters.Builder, client *http.Client, me metrics.MetricsEngine) func(endpoint string) (adaptedBidder, error) {
+	var adapterCfg config.Adapter
+	for bidder, bidderCfg := range cfg.Adapters {
+		if name, ok := openrtb_ext.NormalizeBidderName(bidder); ok && name == bidderName {
+			adapterCfg = bidderCfg
+			break
+		}
+	}
+	builder := builders[bidderName]
+	if adapterCfg.GenericORTB != nil {
+		builder = genericortb.Builder
+	}
+
+	return func(endpoint string) (adaptedBidder, error) {
+		endpointCfg := adapterCfg
+		endpointCfg.Endpoint = endpoint
+		bidder, err := builder(bidderName, endpointCfg)
+		if err != nil {
+			return nil, err
+		}
+		exchangeBidder := adaptBidder(adapters.BuildInfoAwareBidder(bidder, info), client, cfg, me, bidderName, info.Debug, info.Compression)
+		return addValidatedBidderMiddleware(exchangeBidder), nil
+	}
+}
+
+// bidderHTTPClient returns the client calling a bidder. A bidder tuning its HTTP client gets a transport, and so a
+// connection pool, of its own, based on the transport of the shared client.
+func bidderHTTPClient(client *http.Client, info *config.HTTPClientInfo) *http.Client {
+	if info == nil {
+		return client
+	}
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		return client
+	}
+
+	bidderTransport := transport.Clone()
+	if info.MaxIdleConns > 0 {
+		bidderTransport.MaxIdleConns = info.MaxIdleConns
+	}
+	if info.MaxIdleConnsPerHost > 0 {
+		bidderTransport.MaxIdleConnsPerHost = info.MaxIdleConnsPerHost
+	}
+	if info.IdleConnTimeout > 0 {
+		bidderTransport.IdleConnTimeout = time.Duration(info.IdleConnTimeout) * time.Second
+	}
+	if info.EnableHTTP2 {
+		bidderTransport.ForceAttemptHTTP2 = true
+	}
+	if info.TLSSessionCacheSize > 0 {
+		if bidderTransport.TLSClientConfig == nil {
+			bidderTransport.TLSClientConfig = &tls.Config{}
+		}
+		bidderTransport.TLSClientConfig.ClientSessionCache = tls.NewLRUClientSessionCache(info.TLSSessionCacheSize)
+	}
+
+	bidderClient := *client
+	bidderClient.Transport = bidderTransport
+	return &bidderClient
+}
+
 func buildBidders(adapterConfig map[string]config.Adapter, infos config.BidderInfos, builders map[openrtb_ext.BidderName]adapters.Builder) (map[openrtb_ext.BidderName]adapters.Bidder, []error) {
 	bidders := make(map[openrtb_ext.BidderName]adapters.Bidder)
 	var errs []error
@@ -44,6 +115,9 @@ func buildBidders(adapterConfig map[string]config.Adapter, infos config.BidderIn
 		}
 
 		builder, builderFound := builders[bidderName]
+		if cfg.GenericORTB != nil {
+			builder, builderFound = genericortb.Builder, true
+		}
 		if !builderFound {
 			errs = append(errs, fmt.Errorf("%v: builder not registered", bidder))
 			continue
@@ -1,13 +1,17 @@
 package exchange
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/prebid/prebid-server/adapters"
 	"github.com/prebid/prebid-server/adapters/appnexus"
+	"github.com/prebid/prebid-server/adapters/genericortb"
 	"github.com/prebid/prebid-server/adapters/rubicon"
 	"github.com/prebid/prebid-server/config"
 	metrics "github.com/prebid/prebid-server/metrics/config"
@@ -26,12 +30,12 @@ func TestBuildAdapters(t *testing.T) {
 
 	appnexusBidder, _ := appnexus.Builder(openrtb_ext.BidderAppnexus, config.Adapter{})
 	appnexusBidderWithInfo := adapters.BuildInfoAwareBidder(appnexusBidder, infoEnabled)
-	appnexusBidderAdapted := adaptBidder(appnexusBidderWithInfo, client, &config.Configuration{}, metricEngine, openrtb_ext.BidderAppnexus, nil)
+	appnexusBidderAdapted := adaptBidder(appnexusBidderWithInfo, client, &config.Configuration{}, metricEngine, openrtb_ext.BidderAppnexus, nil, nil)
 	appnexusValidated := addValidatedBidderMiddleware(appnexusBidderAdapted)
 
 	rubiconBidder, _ := rubicon.Builder(openrtb_ext.BidderRubicon, config.Adapter{})
 	rubiconBidderWithInfo := adapters.BuildInfoAwareBidder(rubiconBidder, infoEnabled)
-	rubiconBidderAdapted := adaptBidder(rubiconBidderWithInfo, client, &config.Configuration{}, metricEngine, openrtb_ext.BidderRubicon, nil)
+	rubiconBidderAdapted := adaptBidder(rubiconBidderWithInfo, client, &config.Configuration{}, metricEngine, openrtb_ext.BidderRubicon, nil, nil)
 	rubiconbidderValidated := addValidatedBidderMiddleware(rubiconBidderAdapted)
 
 	testCases := []struct {
@@ -83,6 +87,41 @@ func TestBuildAdapters(t *testing.T) {
 	}
 }
 
+func TestBidderHTTPClient(t *testing.T) {
+	certPool := x509.NewCertPool()
+	client := &http.Client{
+		Timeout: time.Second,
+		Transport: &http.Transport{
+			MaxIdleConns:        10,
+			MaxIdleConnsPerHost: 2,
+			IdleConnTimeout:     time.Minute,
+			TLSClientConfig:     &tls.Config{RootCAs: certPool},
+		},
+	}
+
+	assert.Same(t, client, bidderHTTPClient(client, nil), "Bidders not tuning their client share the general one")
+
+	bidderClient := bidderHTTPClient(client, &config.HTTPClientInfo{
+		MaxIdleConnsPerHost: 50,
+		EnableHTTP2:         true,
+		TLSSessionCacheSize: 16,
+	})
+
+	assert.Equal(t, time.Second, bidderClient.Timeout)
+	bidderTransport, ok := bidderClient.Transport.(*http.Transport)
+	if assert.True(t, ok) {
+		assert.Equal(t, 10, bidderTransport.MaxIdleConns, "unset values are inherited")
+		assert.Equal(t, 50, bidderTransport.MaxIdleConnsPerHost)
+		assert.Equal(t, time.Minute, bidderTransport.IdleConnTimeout, "unset values are inherited")
+		assert.True(t, bidderTransport.ForceAttemptHTTP2)
+		assert.Same(t, certPool, bidderTransport.TLSClientConfig.RootCAs)
+		assert.NotNil(t, bidderTransport.TLSClientConfig.ClientSessionCache)
+	}
+	generalTransport := client.Transport.(*http.Transport)
+	assert.Equal(t, 2, generalTransport.MaxIdleConnsPerHost, "the general client must not be modified")
+	assert.Nil(t, generalTransport.TLSClientConfig.ClientSessionCache, "the general client must not be modified")
+}
+
 func TestBuildBidders(t *testing.T) {
 	appnexusBidder := fakeBidder{"a"}
 	appnexusBuilder := fakeBuilder{appnexusBidder, nil}.Builder
@@ -91,6 +130,9 @@ func TestBuildBidders(t *testing.T) {
 	rubiconBidder := fakeBidder{"b"}
 	rubiconBuilder := fakeBuilder{rubiconBidder, nil}.Builder
 
+	genericORTBConfig := config.Adapter{Endpoint: "http://somebidder.com/bid", GenericORTB: &config.GenericORTB{}}
+	genericORTBBidder, _ := genericortb.Builder(openrtb_ext.BidderAppnexus, genericORTBConfig)
+
 	testCases := []struct {
 		description     string
 		adapterConfig   map[string]config.Adapter
@@ -169,6 +211,15 @@ func TestBuildBidders(t *testing.T) {
 				openrtb_ext.BidderRubicon: adapters.BuildInfoAwareBidder(rubiconBidder, infoEnabled),
 			},
 		},
+		{
+			description:   "Success - Generic ORTB",
+			adapterConfig: map[string]config.Adapter{"appnexus": genericORTBConfig},
+			bidderInfos:   map[string]config.BidderInfo{"appnexus": infoEnabled},
+			builders:      map[openrtb_ext.BidderName]adapters.Builder{},
+			expectedBidders: map[openrtb_ext.BidderName]adapters.Bidder{
+				openrtb_ext.BidderAppnexus: adapters.BuildInfoAwareBidder(genericORTBBidder, infoEnabled),
+			},
+		},
 		{
 			description:   "Success - Ignores Adapter Config Case",
 			adapterConfig: map[string]config.Adapter{"AppNexus": {}},
@@ -0,0 +1,92 @@
+package exchange
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// maxEndpointOverrides is the most endpoints a bidder keeps built bidders for. The bidders of the endpoints past it
+// are built on each call, which bounds the memory used by requests setting many endpoints.
+const maxEndpointOverrides = 100
+
+// endpointOverridableBidder is implemented by the bidders which can be called on another endpoint than the one of
+// their config, as set by the alias overrides of a request.
+type endpointOverridableBidder interface {
+	withEndpoint(endpoint string) (adaptedBidder, error)
+}
+
+// endpointOverrideBidder calls the bidder of its config, and builds it again for the endpoints of the aliases
+type endpointOverrideBidder struct {
+	adaptedBidder
+	build func(endpoint string) (adaptedBidder, error)
+
+	mutex   sync.Mutex
+	bidders map[string]adaptedBidder
+}
+
+func newEndpointOverrideBidder(bidder adaptedBidder, build func(endpoint string) (adaptedBidder, error)) *endpointOverrideBidder {
+	return &endpointOverrideBidder{
+		adaptedBidder: bidder,
+		build:         build,
+		bidders:       make(map[string]adaptedBidder),
+	}
+}
+
+func (b *endpointOverrideBidder) withEndpoint(endpoint string) (adaptedBidder, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if bidder, ok := b.bidders[endpoint]; ok {
+		return bidder, nil
+	}
+
+	bidder, err := b.build(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if len(b.bidders) < maxEndpointOverrides {
+		b.bidders[endpoint] = bidder
+	}
+	return bidder, nil
+}
+
+// getBidder returns the bidder called for a bidder request, which is built on the endpoint of its alias override
+// when it sets one
+func (e *exchange) getBidder(bidderRequest BidderRequest) (adaptedBidder, error) {
+	bidder := e.adapterMap[bidderRequest.BidderCoreName]
+	if bidderRequest.AliasEndpoint == "" {
+		return bidder, nil
+	}
+
+	overridableBidder, ok := bidder.(endpointOverridableBidder)
+	if !ok {
+		return nil, &errortypes.BadInput{
+			Message: fmt.Sprintf("the endpoint of alias %s can't be overridden", bidderRequest.BidderName),
+		}
+	}
+	overrideBidder, err := overridableBidder.withEndpoint(bidderRequest.AliasEndpoint)
+	if err != nil {
+		return nil, &errortypes.BadInput{
+			Message: fmt.Sprintf("unable to call alias %s on its endpoint: %v", bidderRequest.BidderName, err),
+		}
+	}
+	return overrideBidder, nil
+}
+
+// getAliasGVLIDs returns the GVL vendor IDs of the aliases of the request whose overrides set one
+func getAliasGVLIDs(requestExt *openrtb_ext.ExtRequest, aliases map[string]string) map[openrtb_ext.BidderName]uint16 {
+	if requestExt == nil || len(requestExt.Prebid.AliasOverrides) == 0 {
+		return nil
+	}
+
+	aliasGVLIDs := make(map[openrtb_ext.BidderName]uint16)
+	for alias, override := range requestExt.Prebid.AliasOverrides {
+		if _, isAlias := aliases[alias]; isAlias && override != nil && override.GVLVendorID != 0 {
+			aliasGVLIDs[openrtb_ext.BidderName(alias)] = override.GVLVendorID
+		}
+	}
+	return aliasGVLIDs
+}
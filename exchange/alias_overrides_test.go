@@ -0,0 +1,131 @@
+package exchange
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	metricsConfig "github.com/prebid/prebid-server/metrics/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointOverrideBidder(t *testing.T) {
+	var builtEndpoints []string
+	bidder := newEndpointOverrideBidder(&mockAdaptedBidder{}, func(endpoint string) (adaptedBidder, error) {
+		if endpoint == "invalid" {
+			return nil, errors.New("invalid endpoint")
+		}
+		builtEndpoints = append(builtEndpoints, endpoint)
+		return &mockAdaptedBidder{}, nil
+	})
+
+	first, err := bidder.withEndpoint("https://alias.com/1")
+	assert.NoError(t, err)
+	again, err := bidder.withEndpoint("https://alias.com/1")
+	assert.NoError(t, err)
+	assert.True(t, first == again, "the bidder of an endpoint is built once")
+
+	_, err = bidder.withEndpoint("invalid")
+	assert.EqualError(t, err, "invalid endpoint")
+	assert.Equal(t, []string{"https://alias.com/1"}, builtEndpoints)
+}
+
+func TestEndpointOverrideBidderCacheLimit(t *testing.T) {
+	builds := 0
+	bidder := newEndpointOverrideBidder(&mockAdaptedBidder{}, func(endpoint string) (adaptedBidder, error) {
+		builds++
+		return &mockAdaptedBidder{}, nil
+	})
+	for i := 0; i < maxEndpointOverrides; i++ {
+		bidder.withEndpoint(string(rune('a' + i)))
+	}
+
+	bidder.withEndpoint("past the limit")
+	bidder.withEndpoint("past the limit")
+
+	assert.Len(t, bidder.bidders, maxEndpointOverrides)
+	assert.Equal(t, maxEndpointOverrides+2, builds, "the bidders past the limit are built on each call")
+}
+
+func TestGetBidder(t *testing.T) {
+	coreBidder := &mockAdaptedBidder{}
+	aliasBidder := &mockAdaptedBidder{}
+	e := &exchange{adapterMap: map[openrtb_ext.BidderName]adaptedBidder{
+		openrtb_ext.BidderAppnexus: newEndpointOverrideBidder(coreBidder, func(endpoint string) (adaptedBidder, error) {
+			if endpoint == "invalid" {
+				return nil, errors.New("invalid endpoint")
+			}
+			return aliasBidder, nil
+		}),
+		openrtb_ext.BidderRubicon: coreBidder,
+	}}
+
+	testCases := []struct {
+		description    string
+		givenRequest   BidderRequest
+		expectedBidder adaptedBidder
+		expectedError  error
+	}{
+		{
+			description:    "No endpoint override",
+			givenRequest:   BidderRequest{BidderName: "apnAlias", BidderCoreName: openrtb_ext.BidderAppnexus},
+			expectedBidder: e.adapterMap[openrtb_ext.BidderAppnexus],
+		},
+		{
+			description:    "Endpoint override",
+			givenRequest:   BidderRequest{BidderName: "apnAlias", BidderCoreName: openrtb_ext.BidderAppnexus, AliasEndpoint: "https://alias.com"},
+			expectedBidder: aliasBidder,
+		},
+		{
+			description:   "Endpoint override failing to build",
+			givenRequest:  BidderRequest{BidderName: "apnAlias", BidderCoreName: openrtb_ext.BidderAppnexus, AliasEndpoint: "invalid"},
+			expectedError: &errortypes.BadInput{Message: "unable to call alias apnAlias on its endpoint: invalid endpoint"},
+		},
+		{
+			description:   "Endpoint override of a bidder which doesn't allow it",
+			givenRequest:  BidderRequest{BidderName: "rubiconAlias", BidderCoreName: openrtb_ext.BidderRubicon, AliasEndpoint: "https://alias.com"},
+			expectedError: &errortypes.BadInput{Message: "the endpoint of alias rubiconAlias can't be overridden"},
+		},
+	}
+
+	for _, test := range testCases {
+		bidder, err := e.getBidder(test.givenRequest)
+
+		assert.True(t, test.expectedBidder == bidder, test.description+":bidder")
+		assert.Equal(t, test.expectedError, err, test.description+":error")
+	}
+}
+
+func TestGetAliasGVLIDs(t *testing.T) {
+	requestExt := &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+		AliasOverrides: map[string]*openrtb_ext.ExtAliasOverride{
+			"apnAlias":     {GVLVendorID: 42},
+			"rubiconAlias": {Endpoint: "https://alias.com"},
+			"notAnAlias":   {GVLVendorID: 43},
+		},
+	}}
+	aliases := map[string]string{"apnAlias": "appnexus", "rubiconAlias": "rubicon"}
+
+	assert.Equal(t, map[openrtb_ext.BidderName]uint16{"apnAlias": 42}, getAliasGVLIDs(requestExt, aliases))
+	assert.Nil(t, getAliasGVLIDs(nil, aliases))
+}
+
+func TestBuildAdaptersWithEndpointOverrides(t *testing.T) {
+	cfg := &config.Configuration{
+		Adapters:                    map[string]config.Adapter{"appnexus": {Endpoint: "https://appnexus.com"}},
+		AllowAliasEndpointOverrides: true,
+	}
+
+	bidders, errs := BuildAdapters(&http.Client{}, cfg, map[string]config.BidderInfo{"appnexus": infoEnabled}, &metricsConfig.DummyMetricsEngine{})
+
+	assert.Empty(t, errs)
+	overridableBidder, ok := bidders[openrtb_ext.BidderAppnexus].(endpointOverridableBidder)
+	if assert.True(t, ok, "the bidders are overridable when the host allows it") {
+		aliasBidder, err := overridableBidder.withEndpoint("https://alias.com")
+		assert.NoError(t, err)
+		assert.IsType(t, &validatedBidder{}, aliasBidder)
+	}
+}
@@ -19,6 +19,7 @@ import (
 
 const (
 	DebugOverrideHeader string = "x-pbs-debug-override"
+	DebugTokenHeader    string = "x-pbs-debug-token"
 )
 
 type DebugLog struct {
@@ -113,7 +114,7 @@ func newAuction(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, numImps int
 			for _, bid := range seatBid.bids {
 				cpm := bid.bid.Price
 				wbid, ok := winningBids[bid.bid.ImpID]
-				if !ok || isNewWinningBid(bid.bid, wbid.bid, preferDeals) {
+				if !ok || isNewWinningBid(bid, wbid, preferDeals) {
 					winningBids[bid.bid.ImpID] = bid
 				}
 				if bidMap, ok := winningBidsByBidder[bid.bid.ImpID]; ok {
@@ -132,27 +133,72 @@ func newAuction(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, numImps int
 	return &auction{
 		winningBids:         winningBids,
 		winningBidsByBidder: winningBidsByBidder,
+		extraBidsByBidder:   getExtraBidsByBidder(seatBids, winningBidsByBidder),
 	}
 }
 
+// getExtraBidsByBidder lists the extra bids of the multibid bidders on each imp, which are the bids with a target
+// bidder code besides the top bid of the bidder. It is nil if there aren't any.
+func getExtraBidsByBidder(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, winningBidsByBidder map[string]map[openrtb_ext.BidderName]*pbsOrtbBid) map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid {
+	var extraBidsByBidder map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid
+	for bidderName, seatBid := range seatBids {
+		if seatBid == nil {
+			continue
+		}
+		for _, bid := range seatBid.bids {
+			if bid.targetBidderCode == "" || winningBidsByBidder[bid.bid.ImpID][bidderName] == bid {
+				continue
+			}
+			if extraBidsByBidder == nil {
+				extraBidsByBidder = make(map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid)
+			}
+			if _, ok := extraBidsByBidder[bid.bid.ImpID]; !ok {
+				extraBidsByBidder[bid.bid.ImpID] = make(map[openrtb_ext.BidderName][]*pbsOrtbBid)
+			}
+			extraBidsByBidder[bid.bid.ImpID][bidderName] = append(extraBidsByBidder[bid.bid.ImpID][bidderName], bid)
+		}
+	}
+	return extraBidsByBidder
+}
+
 // isNewWinningBid calculates if the new bid (nbid) will win against the current winning bid (wbid) given preferDeals.
-func isNewWinningBid(bid, wbid *openrtb2.Bid, preferDeals bool) bool {
+// When deals are preferred, deal bids win over open market bids, and the deal bid of the higher deal priority wins
+// over the other deal bids, whatever their price.
+func isNewWinningBid(bid, wbid *pbsOrtbBid, preferDeals bool) bool {
 	if preferDeals {
-		if len(wbid.DealID) > 0 && len(bid.DealID) == 0 {
+		if len(wbid.bid.DealID) > 0 && len(bid.bid.DealID) == 0 {
 			return false
 		}
-		if len(wbid.DealID) == 0 && len(bid.DealID) > 0 {
+		if len(wbid.bid.DealID) == 0 && len(bid.bid.DealID) > 0 {
 			return true
 		}
+		if len(bid.bid.DealID) > 0 && bid.dealPriority != wbid.dealPriority {
+			return bid.dealPriority > wbid.dealPriority
+		}
 	}
-	return bid.Price > wbid.Price
+	return bid.bid.Price > wbid.bid.Price
 }
 
-func (a *auction) setRoundedPrices(priceGranularity openrtb_ext.PriceGranularity) {
+// targetedBidsByBidder lists the bids targeted on each imp by each bidder, which are the top bid of the bidder followed
+// by its multibid extra bids.
+func (a *auction) targetedBidsByBidder() map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid {
+	targetedBids := make(map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid, len(a.winningBidsByBidder))
+	for impID, topBidsPerImp := range a.winningBidsByBidder {
+		targetedBids[impID] = make(map[openrtb_ext.BidderName][]*pbsOrtbBid, len(topBidsPerImp))
+		for bidderName, topBidPerBidder := range topBidsPerImp {
+			targetedBids[impID][bidderName] = append([]*pbsOrtbBid{topBidPerBidder}, a.extraBidsByBidder[impID][bidderName]...)
+		}
+	}
+	return targetedBids
+}
+
+func (a *auction) setRoundedPrices(targData *targetData) {
 	roundedPrices := make(map[*pbsOrtbBid]string, 5*len(a.winningBids))
-	for _, topBidsPerImp := range a.winningBidsByBidder {
-		for _, topBidPerBidder := range topBidsPerImp {
-			roundedPrices[topBidPerBidder] = GetPriceBucket(topBidPerBidder.bid.Price, priceGranularity)
+	for _, targetedBidsPerImp := range a.targetedBidsByBidder() {
+		for bidderName, targetedBidsPerBidder := range targetedBidsPerImp {
+			for _, targetedBid := range targetedBidsPerBidder {
+				roundedPrices[targetedBid] = GetPriceBucket(targetedBid.bid.Price, targData.bidPriceGranularity(bidderName, targetedBid.bidType))
+			}
 		}
 	}
 	a.roundedPrices = roundedPrices
@@ -176,6 +222,10 @@ func (a *auction) doCache(ctx context.Context, cache prebid_cache_client.Client,
 		// assert:  category of winning bids never duplicated
 		if rawUuid, err := uuid.NewV4(); err == nil {
 			hbCacheID = rawUuid.String()
+			if targData.cacheKey != "" {
+				// Prebid Cache refuses the keys it already has, so the key of the publisher gets a suffix of each auction
+				hbCacheID = targData.cacheKey + "_" + hbCacheID
+			}
 			competitiveExclusion = true
 		} else {
 			errs = append(errs, errors.New("failed to create custom cache key"))
@@ -186,64 +236,69 @@ func (a *auction) doCache(ctx context.Context, cache prebid_cache_client.Client,
 	for _, imp := range bidRequest.Imp {
 		expByImp[imp.ID] = imp.Exp
 	}
-	for _, topBidsPerImp := range a.winningBidsByBidder {
-		for bidderName, topBidPerBidder := range topBidsPerImp {
-			impID := topBidPerBidder.bid.ImpID
-			isOverallWinner := a.winningBids[impID] == topBidPerBidder
-			if !includeBidderKeys && !isOverallWinner {
-				continue
-			}
-			var customCacheKey string
-			var catDur string
-			useCustomCacheKey := false
-			if competitiveExclusion && isOverallWinner || includeBidderKeys {
-				// set custom cache key for winning bid when competitive exclusion applies
-				catDur = bidCategory[topBidPerBidder.bid.ID]
-				if len(catDur) > 0 {
-					customCacheKey = fmt.Sprintf("%s_%s", catDur, hbCacheID)
-					useCustomCacheKey = true
+	for _, targetedBidsPerImp := range a.targetedBidsByBidder() {
+		for bidderName, targetedBidsPerBidder := range targetedBidsPerImp {
+			for _, targetedBid := range targetedBidsPerBidder {
+				impID := targetedBid.bid.ImpID
+				isOverallWinner := a.winningBids[impID] == targetedBid
+				if !includeBidderKeys && !isOverallWinner {
+					continue
 				}
-			}
-			if bids {
-				if jsonBytes, err := json.Marshal(topBidPerBidder.bid); err == nil {
-					jsonBytes, err = evTracking.modifyBidJSON(topBidPerBidder, bidderName, jsonBytes)
-					if err != nil {
-						errs = append(errs, err)
-					}
-					if useCustomCacheKey {
-						// not allowed if bids is true; log error and cache normally
-						errs = append(errs, errors.New("cannot use custom cache key for non-vast bids"))
+				var customCacheKey string
+				var catDur string
+				useCustomCacheKey := false
+				if competitiveExclusion && isOverallWinner || includeBidderKeys {
+					// set custom cache key for winning bid when competitive exclusion applies
+					catDur = bidCategory[targetedBid.bid.ID]
+					if len(catDur) > 0 {
+						customCacheKey = fmt.Sprintf("%s_%s", catDur, hbCacheID)
+						useCustomCacheKey = true
 					}
-					toCache = append(toCache, prebid_cache_client.Cacheable{
-						Type:       prebid_cache_client.TypeJSON,
-						Data:       jsonBytes,
-						TTLSeconds: cacheTTL(expByImp[impID], topBidPerBidder.bid.Exp, defTTL(topBidPerBidder.bidType, defaultTTLs), ttlBuffer),
-					})
-					bidIndices[len(toCache)-1] = topBidPerBidder.bid
-				} else {
-					errs = append(errs, err)
 				}
-			}
-			if vast && topBidPerBidder.bidType == openrtb_ext.BidTypeVideo {
-				vastXML := makeVAST(topBidPerBidder.bid)
-				if jsonBytes, err := json.Marshal(vastXML); err == nil {
-					if useCustomCacheKey {
+				ttl := capTTL(cacheTTL(expByImp[impID], targetedBid.bid.Exp, defTTL(targetedBid.bidType, defaultTTLs), ttlBuffer), defTTL(targetedBid.bidType, &targData.cacheMaxTTLs))
+				// the video bids of the accounts caching only the VAST XML get their hb_uuid key alone
+				vastOnly := vast && targData.cacheVASTXMLOnly && targetedBid.bidType == openrtb_ext.BidTypeVideo
+				if bids && !vastOnly {
+					if jsonBytes, err := json.Marshal(targetedBid.bid); err == nil {
+						jsonBytes, err = evTracking.modifyBidJSON(targetedBid, bidderName, jsonBytes)
+						if err != nil {
+							errs = append(errs, err)
+						}
+						if useCustomCacheKey {
+							// not allowed if bids is true; log error and cache normally
+							errs = append(errs, errors.New("cannot use custom cache key for non-vast bids"))
+						}
 						toCache = append(toCache, prebid_cache_client.Cacheable{
-							Type:       prebid_cache_client.TypeXML,
+							Type:       prebid_cache_client.TypeJSON,
 							Data:       jsonBytes,
-							TTLSeconds: cacheTTL(expByImp[impID], topBidPerBidder.bid.Exp, defTTL(topBidPerBidder.bidType, defaultTTLs), ttlBuffer),
-							Key:        customCacheKey,
+							TTLSeconds: ttl,
 						})
+						bidIndices[len(toCache)-1] = targetedBid.bid
 					} else {
-						toCache = append(toCache, prebid_cache_client.Cacheable{
-							Type:       prebid_cache_client.TypeXML,
-							Data:       jsonBytes,
-							TTLSeconds: cacheTTL(expByImp[impID], topBidPerBidder.bid.Exp, defTTL(topBidPerBidder.bidType, defaultTTLs), ttlBuffer),
-						})
+						errs = append(errs, err)
+					}
+				}
+				if vast && targetedBid.bidType == openrtb_ext.BidTypeVideo {
+					vastXML := makeVAST(targetedBid.bid)
+					if jsonBytes, err := json.Marshal(vastXML); err == nil {
+						if useCustomCacheKey {
+							toCache = append(toCache, prebid_cache_client.Cacheable{
+								Type:       prebid_cache_client.TypeXML,
+								Data:       jsonBytes,
+								TTLSeconds: ttl,
+								Key:        customCacheKey,
+							})
+						} else {
+							toCache = append(toCache, prebid_cache_client.Cacheable{
+								Type:       prebid_cache_client.TypeXML,
+								Data:       jsonBytes,
+								TTLSeconds: ttl,
+							})
+						}
+						vastIndices[len(toCache)-1] = targetedBid.bid
+					} else {
+						errs = append(errs, err)
 					}
-					vastIndices[len(toCache)-1] = topBidPerBidder.bid
-				} else {
-					errs = append(errs, err)
 				}
 			}
 		}
@@ -337,6 +392,14 @@ func cacheTTL(impTTL int64, bidTTL int64, defTTL int64, buffer int64) (ttl int64
 	return addBuffer(bidTTL, buffer)
 }
 
+// capTTL caps the TTL at the max TTL, if there is one. The TTLs left to prebid cache are capped as well.
+func capTTL(ttl int64, maxTTL int64) int64 {
+	if maxTTL > 0 && (ttl <= 0 || ttl > maxTTL) {
+		return maxTTL
+	}
+	return ttl
+}
+
 func addBuffer(base int64, buffer int64) int64 {
 	if base <= 0 {
 		return 0
@@ -363,6 +426,8 @@ type auction struct {
 	winningBids map[string]*pbsOrtbBid
 	// winningBidsByBidder stores the highest bid on each imp by each bidder.
 	winningBidsByBidder map[string]map[openrtb_ext.BidderName]*pbsOrtbBid
+	// extraBidsByBidder stores the other bids of the multibid bidders on each imp, which are targeted and cached as well.
+	extraBidsByBidder map[string]map[openrtb_ext.BidderName][]*pbsOrtbBid
 	// roundedPrices stores the price strings rounded for each bid according to the price granularity.
 	roundedPrices map[*pbsOrtbBid]string
 	// cacheIds stores the UUIDs from Prebid Cache for fetching the full bid JSON.
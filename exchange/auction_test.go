@@ -251,6 +251,9 @@ func runCacheSpec(t *testing.T, fileDisplayName string, specData *cacheSpec) {
 		includeBidderKeys: specData.TargetDataIncludeBidderKeys,
 		includeCacheBids:  specData.TargetDataIncludeCacheBids,
 		includeCacheVast:  specData.TargetDataIncludeCacheVast,
+		cacheKey:          specData.TargetDataCacheKey,
+		cacheMaxTTLs:      specData.TargetDataCacheMaxTTLs,
+		cacheVASTXMLOnly:  specData.TargetDataCacheVASTXMLOnly,
 	}
 
 	testAuction := &auction{
@@ -349,6 +352,14 @@ func TestNewAuction(t *testing.T) {
 			DealID: "BigDeal",
 		},
 	}
+	bid1p044dp5 := pbsOrtbBid{
+		bid: &openrtb2.Bid{
+			ImpID:  "imp1",
+			Price:  0.44,
+			DealID: "PriorityDeal",
+		},
+		dealPriority: 5,
+	}
 	bid2p123 := pbsOrtbBid{
 		bid: &openrtb2.Bid{
 			ImpID: "imp2",
@@ -497,6 +508,54 @@ func TestNewAuction(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "Auction with 2 deals of different priorities, prefer deals",
+			seatBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+				"appnexus": {
+					bids: []*pbsOrtbBid{&bid1p166d},
+				},
+				"rubicon": {
+					bids: []*pbsOrtbBid{&bid1p044dp5},
+				},
+			},
+			numImps:     1,
+			preferDeals: true,
+			expectedAuction: auction{
+				winningBids: map[string]*pbsOrtbBid{
+					"imp1": &bid1p044dp5,
+				},
+				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+					"imp1": {
+						"appnexus": &bid1p166d,
+						"rubicon":  &bid1p044dp5,
+					},
+				},
+			},
+		},
+		{
+			description: "Auction with 2 deals of different priorities, no preference",
+			seatBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+				"appnexus": {
+					bids: []*pbsOrtbBid{&bid1p166d},
+				},
+				"rubicon": {
+					bids: []*pbsOrtbBid{&bid1p044dp5},
+				},
+			},
+			numImps:     1,
+			preferDeals: false,
+			expectedAuction: auction{
+				winningBids: map[string]*pbsOrtbBid{
+					"imp1": &bid1p166d,
+				},
+				winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+					"imp1": {
+						"appnexus": &bid1p166d,
+						"rubicon":  &bid1p044dp5,
+					},
+				},
+			},
+		},
 		{
 			description: "Auction with 3 bids and 2 deals",
 			seatBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
@@ -547,6 +606,9 @@ type cacheSpec struct {
 	EventsDataEnabledForAccount bool                            `json:"eventsDataEnabledForAccount"`
 	EventsDataEnabledForRequest bool                            `json:"eventsDataEnabledForRequest"`
 	DebugLog                    DebugLog                        `json:"debugLog,omitempty"`
+	TargetDataCacheKey          string                          `json:"targetDataCacheKey,omitempty"`
+	TargetDataCacheMaxTTLs      config.DefaultTTLs              `json:"targetDataCacheMaxTTLs,omitempty"`
+	TargetDataCacheVASTXMLOnly  bool                            `json:"targetDataCacheVASTXMLOnly,omitempty"`
 }
 
 type pbsBid struct {
@@ -561,6 +623,38 @@ type cacheComparator struct {
 	actualKeys   []string
 }
 
+func TestDoCachePublisherKey(t *testing.T) {
+	cacheKeys := func() []string {
+		bid := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "bid1", ImpID: "imp1", Price: 1, AdM: "<VAST></VAST>"}, bidType: openrtb_ext.BidTypeVideo}
+		auc := newAuction(map[openrtb_ext.BidderName]*pbsOrtbSeatBid{"appnexus": {bids: []*pbsOrtbBid{bid}}}, 1, false)
+		targData := &targetData{
+			priceGranularity: openrtb_ext.PriceGranularityFromString("med"),
+			includeWinners:   true,
+			includeCacheVast: true,
+			cacheKey:         "publisher-key",
+		}
+		auc.setRoundedPrices(targData)
+		cache := &mockCache{}
+
+		errs := auc.doCache(context.Background(), cache, targData, &eventTracking{}, &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "imp1"}}}, 60, &config.DefaultTTLs{}, map[string]string{"bid1": "11_sports_30s"}, nil)
+
+		assert.Empty(t, errs)
+		keys := make([]string, 0, len(cache.items))
+		for _, item := range cache.items {
+			keys = append(keys, item.Key)
+		}
+		return keys
+	}
+
+	first := cacheKeys()
+	second := cacheKeys()
+
+	if assert.Len(t, first, 1) && assert.Len(t, second, 1) {
+		assert.Regexp(t, `^11_sports_30s_publisher-key_.+`, first[0], "the key of the publisher starts the cache key")
+		assert.NotEqual(t, first[0], second[0], "each auction caches under a key of its own")
+	}
+}
+
 type mockCache struct {
 	scheme string
 	host   string
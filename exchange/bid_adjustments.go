@@ -0,0 +1,125 @@
+package exchange
+
+import (
+	"fmt"
+
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// getBidderAdjustmentFactor returns the factor the bidder applies to all of its bids as they are received. Bidders with
+// media type factors are left to applyBidAdjustments instead, since their factor depends on the type of each bid.
+func getBidderAdjustmentFactor(factors *openrtb_ext.ExtBidAdjustmentFactors, bidderName openrtb_ext.BidderName) float64 {
+	if factors == nil || hasMediaTypeAdjustmentFactors(factors, bidderName) {
+		return 1.0
+	}
+	if factor, ok := factors.Bidders[string(bidderName)]; ok {
+		return factor
+	}
+	return 1.0
+}
+
+func hasMediaTypeAdjustmentFactors(factors *openrtb_ext.ExtBidAdjustmentFactors, bidderName openrtb_ext.BidderName) bool {
+	for _, bidderFactors := range factors.MediaTypes {
+		if _, ok := bidderFactors[string(bidderName)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// applyBidAdjustments applies the media type factors of bidrequest.ext.prebid.bidadjustmentfactors, and then the
+// rules of bidrequest.ext.prebid.bidadjustments to the bid prices. A rule which would bring the price of a bid to zero
+// or below is not applied.
+func applyBidAdjustments(adapterBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, adapterExtra map[openrtb_ext.BidderName]*seatResponseExtra, factors *openrtb_ext.ExtBidAdjustmentFactors, rules *openrtb_ext.ExtRequestPrebidBidAdjustments, conversions currency.Conversions) {
+	for bidderName, seatBid := range adapterBids {
+		mediaTypeFactors := factors != nil && hasMediaTypeAdjustmentFactors(factors, bidderName)
+
+		for _, bid := range seatBid.bids {
+			if bid.bid == nil {
+				continue
+			}
+
+			if mediaTypeFactors {
+				if factor, ok := factors.MediaTypes[bid.bidType][string(bidderName)]; ok {
+					bid.bid.Price *= factor
+				} else if factor, ok := factors.Bidders[string(bidderName)]; ok {
+					bid.bid.Price *= factor
+				}
+			}
+
+			if rules == nil {
+				continue
+			}
+			adjustments := findBidAdjustments(rules, bid.bidType, bidderName, bid.bid.DealID)
+			price, err := adjustPrice(bid.bid.Price, seatBid.currency, adjustments, conversions)
+			if err != nil {
+				if extra, ok := adapterExtra[bidderName]; ok {
+					extra.Warnings = append(extra.Warnings, openrtb_ext.ExtBidderMessage{
+						Code:    errortypes.BidAdjustmentNotAppliedWarningCode,
+						Message: fmt.Sprintf("bid adjustment of bid %s not applied: %v", bid.bid.ID, err),
+					})
+				}
+				continue
+			}
+			if price > 0 {
+				bid.bid.Price = price
+			}
+		}
+	}
+}
+
+// findBidAdjustments returns the most specific rule matching the bid. The media type is the most significant part of
+// the match, followed by the bidder and the deal id, so an exact media type always wins over a "*" media type.
+func findBidAdjustments(rules *openrtb_ext.ExtRequestPrebidBidAdjustments, bidType openrtb_ext.BidType, bidderName openrtb_ext.BidderName, dealID string) []openrtb_ext.ExtBidAdjustment {
+	wildcard := openrtb_ext.BidAdjustmentWildcard
+	dealIDs := []string{wildcard}
+	if dealID != "" {
+		dealIDs = []string{dealID, wildcard}
+	}
+
+	for _, mediaType := range []string{string(bidType), wildcard} {
+		bidders, ok := rules.MediaType[mediaType]
+		if !ok {
+			continue
+		}
+		for _, bidder := range []string{string(bidderName), wildcard} {
+			deals, ok := bidders[bidder]
+			if !ok {
+				continue
+			}
+			for _, deal := range dealIDs {
+				if adjustments, ok := deals[deal]; ok {
+					return adjustments
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// adjustPrice applies the adjustments in order to a price made in the given currency.
+func adjustPrice(price float64, priceCurrency string, adjustments []openrtb_ext.ExtBidAdjustment, conversions currency.Conversions) (float64, error) {
+	for _, adjustment := range adjustments {
+		switch adjustment.AdjType {
+		case openrtb_ext.BidAdjustmentTypeMultiplier:
+			price *= adjustment.Value
+		case openrtb_ext.BidAdjustmentTypeCPM, openrtb_ext.BidAdjustmentTypeStatic:
+			adjustmentCurrency := adjustment.Currency
+			if adjustmentCurrency == "" {
+				adjustmentCurrency = "USD"
+			}
+			rate, err := conversions.GetRate(adjustmentCurrency, priceCurrency)
+			if err != nil {
+				return 0, err
+			}
+			if adjustment.AdjType == openrtb_ext.BidAdjustmentTypeCPM {
+				price -= adjustment.Value * rate
+			} else {
+				price = adjustment.Value * rate
+			}
+		}
+	}
+	return price, nil
+}
@@ -0,0 +1,125 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBidderAdjustmentFactor(t *testing.T) {
+	factors := &openrtb_ext.ExtBidAdjustmentFactors{
+		Bidders:    map[string]float64{"appnexus": 0.9, "rubicon": 1.1},
+		MediaTypes: map[openrtb_ext.BidType]map[string]float64{openrtb_ext.BidTypeVideo: {"rubicon": 1.2}},
+	}
+
+	assert.Equal(t, 0.9, getBidderAdjustmentFactor(factors, "appnexus"), "bidder factor")
+	assert.Equal(t, 1.0, getBidderAdjustmentFactor(factors, "rubicon"), "media type factors")
+	assert.Equal(t, 1.0, getBidderAdjustmentFactor(factors, "openx"), "no factor")
+	assert.Equal(t, 1.0, getBidderAdjustmentFactor(nil, "appnexus"), "no factors")
+}
+
+func TestApplyBidAdjustments(t *testing.T) {
+	conversions := currency.NewRates(map[string]map[string]float64{
+		"USD": {"EUR": 0.5},
+	})
+	bid := func(id string, price float64, bidType openrtb_ext.BidType, dealID string) *pbsOrtbBid {
+		return &pbsOrtbBid{bid: &openrtb2.Bid{ID: id, Price: price, DealID: dealID}, bidType: bidType}
+	}
+
+	testCases := []struct {
+		description      string
+		givenFactors     *openrtb_ext.ExtBidAdjustmentFactors
+		givenRules       *openrtb_ext.ExtRequestPrebidBidAdjustments
+		givenBids        []*pbsOrtbBid
+		expectedPrices   []float64
+		expectedWarnings []openrtb_ext.ExtBidderMessage
+	}{
+		{
+			description: "Media type factors take precedence over the bidder factor",
+			givenFactors: &openrtb_ext.ExtBidAdjustmentFactors{
+				Bidders:    map[string]float64{"appnexus": 0.5},
+				MediaTypes: map[openrtb_ext.BidType]map[string]float64{openrtb_ext.BidTypeVideo: {"appnexus": 2}},
+			},
+			givenBids:      []*pbsOrtbBid{bid("video", 1, openrtb_ext.BidTypeVideo, ""), bid("banner", 1, openrtb_ext.BidTypeBanner, "")},
+			expectedPrices: []float64{2, 0.5},
+		},
+		{
+			description:    "Bidder factor only, already applied by the bidder",
+			givenFactors:   &openrtb_ext.ExtBidAdjustmentFactors{Bidders: map[string]float64{"appnexus": 0.5}},
+			givenBids:      []*pbsOrtbBid{bid("banner", 1, openrtb_ext.BidTypeBanner, "")},
+			expectedPrices: []float64{1},
+		},
+		{
+			description: "Most specific rule wins",
+			givenRules: &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: map[string]map[string]map[string][]openrtb_ext.ExtBidAdjustment{
+				"banner": {
+					"appnexus": {
+						"deal-1": {{AdjType: openrtb_ext.BidAdjustmentTypeMultiplier, Value: 2}},
+						"*":      {{AdjType: openrtb_ext.BidAdjustmentTypeMultiplier, Value: 3}},
+					},
+					"*": {"*": {{AdjType: openrtb_ext.BidAdjustmentTypeMultiplier, Value: 4}}},
+				},
+				"*": {"*": {"*": {{AdjType: openrtb_ext.BidAdjustmentTypeMultiplier, Value: 5}}}},
+			}},
+			givenBids: []*pbsOrtbBid{
+				bid("deal", 1, openrtb_ext.BidTypeBanner, "deal-1"),
+				bid("other-deal", 1, openrtb_ext.BidTypeBanner, "deal-2"),
+				bid("open-market", 1, openrtb_ext.BidTypeBanner, ""),
+				bid("video", 1, openrtb_ext.BidTypeVideo, ""),
+			},
+			expectedPrices: []float64{2, 3, 3, 5},
+		},
+		{
+			description: "Rule adjustments are applied in order, converted to the bid currency",
+			givenRules: &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: map[string]map[string]map[string][]openrtb_ext.ExtBidAdjustment{
+				"*": {"appnexus": {"*": {
+					{AdjType: openrtb_ext.BidAdjustmentTypeStatic, Value: 4},
+					{AdjType: openrtb_ext.BidAdjustmentTypeCPM, Value: 1, Currency: "EUR"},
+					{AdjType: openrtb_ext.BidAdjustmentTypeMultiplier, Value: 0.5},
+				}}},
+			}},
+			givenBids:      []*pbsOrtbBid{bid("banner", 10, openrtb_ext.BidTypeBanner, "")},
+			expectedPrices: []float64{1},
+		},
+		{
+			description: "Rule bringing the price to zero is not applied",
+			givenRules: &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: map[string]map[string]map[string][]openrtb_ext.ExtBidAdjustment{
+				"*": {"*": {"*": {{AdjType: openrtb_ext.BidAdjustmentTypeCPM, Value: 1}}}},
+			}},
+			givenBids:      []*pbsOrtbBid{bid("banner", 0.5, openrtb_ext.BidTypeBanner, "")},
+			expectedPrices: []float64{0.5},
+		},
+		{
+			description: "Rule currency without conversion rate",
+			givenRules: &openrtb_ext.ExtRequestPrebidBidAdjustments{MediaType: map[string]map[string]map[string][]openrtb_ext.ExtBidAdjustment{
+				"*": {"*": {"*": {{AdjType: openrtb_ext.BidAdjustmentTypeCPM, Value: 1, Currency: "JPY"}}}},
+			}},
+			givenBids:      []*pbsOrtbBid{bid("banner", 2, openrtb_ext.BidTypeBanner, "")},
+			expectedPrices: []float64{2},
+			expectedWarnings: []openrtb_ext.ExtBidderMessage{{
+				Code:    errortypes.BidAdjustmentNotAppliedWarningCode,
+				Message: "bid adjustment of bid banner not applied: Currency conversion rate not found: 'JPY' => 'USD'",
+			}},
+		},
+	}
+
+	for _, test := range testCases {
+		adapterBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+			"appnexus": {currency: "USD", bids: test.givenBids},
+		}
+		adapterExtra := map[openrtb_ext.BidderName]*seatResponseExtra{"appnexus": {}}
+
+		applyBidAdjustments(adapterBids, adapterExtra, test.givenFactors, test.givenRules, conversions)
+
+		actualPrices := make([]float64, 0, len(test.givenBids))
+		for _, bid := range adapterBids["appnexus"].bids {
+			actualPrices = append(actualPrices, bid.bid.Price)
+		}
+		assert.Equal(t, test.expectedPrices, actualPrices, test.description)
+		assert.Equal(t, test.expectedWarnings, adapterExtra["appnexus"].Warnings, test.description)
+	}
+}
@@ -0,0 +1,332 @@
+package exchange
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	nativeRequests "github.com/mxmCherry/openrtb/v15/native1/request"
+	nativeResponse "github.com/mxmCherry/openrtb/v15/native1/response"
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// bidValidation is a check of the account validations, run on each bid once all of them are in
+type bidValidation struct {
+	rule       metrics.BidValidationRule
+	mode       config.ValidationMode
+	statusCode openrtb_ext.NonBidStatusCode
+	validate   func(bid *pbsOrtbBid, imp *openrtb2.Imp) error
+}
+
+// newBidValidations returns the validations the account doesn't skip
+func newBidValidations(cfg config.AccountValidations) []bidValidation {
+	all := []bidValidation{
+		{
+			rule:       metrics.BidValidationAdmPresence,
+			mode:       cfg.AdmPresence,
+			statusCode: openrtb_ext.NonBidResponseRejectedInvalidCreative,
+			validate:   validateAdmPresence,
+		},
+		{
+			rule:       metrics.BidValidationCreativeSize,
+			mode:       cfg.BannerCreativeSize,
+			statusCode: openrtb_ext.NonBidResponseRejectedCreativeSizeNotAllowed,
+			validate:   validateBannerCreativeSize,
+		},
+		{
+			rule:       metrics.BidValidationSecureMarkup,
+			mode:       cfg.SecureMarkup,
+			statusCode: openrtb_ext.NonBidResponseRejectedCreativeNotSecure,
+			validate:   validateSecureMarkup,
+		},
+		{
+			rule:       metrics.BidValidationNativeAssets,
+			mode:       cfg.NativeAssets,
+			statusCode: openrtb_ext.NonBidResponseRejectedInvalidCreative,
+			validate:   validateNativeAssets,
+		},
+	}
+
+	validations := make([]bidValidation, 0, len(all))
+	for _, validation := range all {
+		if validation.mode == config.ValidationWarn || validation.mode == config.ValidationEnforce {
+			validations = append(validations, validation)
+		}
+	}
+	return validations
+}
+
+// validateBids runs the validations of the account on the bids of every seat. Seats are validated concurrently, since
+// the markup checks can get expensive on large auctions. Bids failing an enforced validation are dropped and listed as
+// non bids, while the failures of the other validations only add a warning. It returns true if any bids remain.
+func validateBids(bidRequest *openrtb2.BidRequest, adapterBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, adapterExtra map[openrtb_ext.BidderName]*seatResponseExtra, coreBidderNames map[openrtb_ext.BidderName]openrtb_ext.BidderName, cfg config.AccountValidations, me metrics.MetricsEngine) bool {
+	validations := newBidValidations(cfg)
+	if len(validations) == 0 {
+		return len(adapterBids) > 0
+	}
+
+	imps := make(map[string]*openrtb2.Imp, len(bidRequest.Imp))
+	for i := range bidRequest.Imp {
+		imps[bidRequest.Imp[i].ID] = &bidRequest.Imp[i]
+	}
+
+	var wg sync.WaitGroup
+	for bidderName, seatBid := range adapterBids {
+		coreBidder, ok := coreBidderNames[bidderName]
+		if !ok {
+			coreBidder = bidderName
+		}
+		extra, ok := adapterExtra[bidderName]
+		if !ok {
+			extra = &seatResponseExtra{}
+		}
+
+		wg.Add(1)
+		go func(seatBid *pbsOrtbSeatBid, coreBidder openrtb_ext.BidderName, extra *seatResponseExtra) {
+			defer wg.Done()
+			validateSeatBid(seatBid, imps, validations, coreBidder, extra, me)
+		}(seatBid, coreBidder, extra)
+	}
+	wg.Wait()
+
+	bidsFound := false
+	for _, seatBid := range adapterBids {
+		if len(seatBid.bids) > 0 {
+			bidsFound = true
+		}
+	}
+	return bidsFound
+}
+
+// validateSeatBid runs the validations on the bids of a single seat. It only writes to the seat and its extra data,
+// so that seats can be validated concurrently.
+func validateSeatBid(seatBid *pbsOrtbSeatBid, imps map[string]*openrtb2.Imp, validations []bidValidation, coreBidder openrtb_ext.BidderName, extra *seatResponseExtra, me metrics.MetricsEngine) {
+	validBids := make([]*pbsOrtbBid, 0, len(seatBid.bids))
+	for _, bid := range seatBid.bids {
+		if bid.bid == nil {
+			continue
+		}
+		imp, ok := imps[bid.bid.ImpID]
+		if !ok {
+			validBids = append(validBids, bid)
+			continue
+		}
+
+		dropped := false
+		for _, validation := range validations {
+			err := validation.validate(bid, imp)
+			if err == nil {
+				continue
+			}
+
+			enforced := validation.mode == config.ValidationEnforce
+			me.RecordBidValidationFailure(coreBidder, validation.rule, enforced)
+			if !enforced {
+				extra.Warnings = append(extra.Warnings, openrtb_ext.ExtBidderMessage{
+					Code:    errortypes.BidValidationWarningCode,
+					Message: fmt.Sprintf("bid %s failed the %s validation: %v", bid.bid.ID, validation.rule, err),
+				})
+				continue
+			}
+
+			extra.Warnings = append(extra.Warnings, openrtb_ext.ExtBidderMessage{
+				Code:    errortypes.BidValidationWarningCode,
+				Message: fmt.Sprintf("bid %s dropped by the %s validation: %v", bid.bid.ID, validation.rule, err),
+			})
+			extra.NonBids = append(extra.NonBids, makeNonBid(bid, validation.statusCode))
+			dropped = true
+			break
+		}
+		if !dropped {
+			validBids = append(validBids, bid)
+		}
+	}
+	seatBid.bids = validBids
+}
+
+func validateAdmPresence(bid *pbsOrtbBid, imp *openrtb2.Imp) error {
+	if bid.bid.AdM == "" && bid.bid.NURL == "" {
+		return errors.New("the bid has neither adm nor nurl")
+	}
+	return nil
+}
+
+func validateBannerCreativeSize(bid *pbsOrtbBid, imp *openrtb2.Imp) error {
+	if bid.bidType != openrtb_ext.BidTypeBanner || imp.Banner == nil {
+		return nil
+	}
+	if bid.bid.W == 0 || bid.bid.H == 0 {
+		return errors.New("the bid has no size")
+	}
+
+	for _, format := range imp.Banner.Format {
+		if format.W == bid.bid.W && format.H == bid.bid.H {
+			return nil
+		}
+	}
+	if imp.Banner.W != nil && imp.Banner.H != nil && *imp.Banner.W == bid.bid.W && *imp.Banner.H == bid.bid.H {
+		return nil
+	}
+	return fmt.Errorf("the size %dx%d isn't one of the sizes of imp %s", bid.bid.W, bid.bid.H, imp.ID)
+}
+
+func validateSecureMarkup(bid *pbsOrtbBid, imp *openrtb2.Imp) error {
+	if imp.Secure == nil || *imp.Secure != 1 {
+		return nil
+	}
+
+	adm := strings.ToLower(bid.bid.AdM)
+	if strings.Contains(adm, "http:") || strings.Contains(adm, "http%3a") {
+		return fmt.Errorf("the markup loads insecure resources in secure imp %s", imp.ID)
+	}
+	if strings.HasPrefix(strings.ToLower(bid.bid.NURL), "http:") {
+		return fmt.Errorf("the nurl is insecure in secure imp %s", imp.ID)
+	}
+	return nil
+}
+
+// validateNativeAssets checks the native markup of the bid against the native request of its imp. The markup can be a
+// Native 1.2 response, or a response of the older versions wrapped in a native object. The markups which leave their
+// assets to an assetsurl or dcourl can't be checked.
+func validateNativeAssets(bid *pbsOrtbBid, imp *openrtb2.Imp) error {
+	if bid.bidType != openrtb_ext.BidTypeNative || imp.Native == nil || bid.bid.AdM == "" {
+		return nil
+	}
+	var request nativeRequests.Request
+	if err := json.Unmarshal([]byte(imp.Native.Request), &request); err != nil {
+		// The requests are validated by the endpoints, so this would be a bidder's own request
+		return nil
+	}
+
+	markup, err := parseNativeMarkup(bid.bid.AdM)
+	if err != nil {
+		return fmt.Errorf("the native markup isn't valid: %v", err)
+	}
+	if len(markup.Assets) == 0 && (markup.AssetsURL != "" || markup.DCOURL != "") {
+		return nil
+	}
+
+	requested := make(map[int64]nativeRequests.Asset, len(request.Assets))
+	for _, asset := range request.Assets {
+		requested[asset.ID] = asset
+	}
+	found := make(map[int64]struct{}, len(markup.Assets))
+	for i, asset := range markup.Assets {
+		if asset.ID == nil {
+			return fmt.Errorf("the native asset %d has no id", i)
+		}
+		requestedAsset, ok := requested[*asset.ID]
+		if !ok {
+			return fmt.Errorf("the native asset %d isn't one of the assets of imp %s", *asset.ID, imp.ID)
+		}
+		if responseType, requestType := nativeResponseAssetType(asset), nativeRequestAssetType(requestedAsset); responseType != requestType {
+			return fmt.Errorf("the native asset %d is a %s asset, but imp %s requested a %s asset", *asset.ID, responseType, imp.ID, requestType)
+		}
+		found[*asset.ID] = struct{}{}
+	}
+	for _, asset := range request.Assets {
+		if _, ok := found[asset.ID]; asset.Required == 1 && !ok {
+			return fmt.Errorf("the required native asset %d of imp %s is missing", asset.ID, imp.ID)
+		}
+	}
+	return nil
+}
+
+// parseNativeMarkup reads a Native 1.2 response, or unwraps the native object of the older versions
+func parseNativeMarkup(adm string) (*nativeResponse.Response, error) {
+	var wrapper struct {
+		Native *nativeResponse.Response `json:"native"`
+	}
+	if err := json.Unmarshal([]byte(adm), &wrapper); err != nil {
+		return nil, err
+	}
+	if wrapper.Native != nil {
+		return wrapper.Native, nil
+	}
+
+	var markup nativeResponse.Response
+	if err := json.Unmarshal([]byte(adm), &markup); err != nil {
+		return nil, err
+	}
+	return &markup, nil
+}
+
+func nativeRequestAssetType(asset nativeRequests.Asset) string {
+	switch {
+	case asset.Title != nil:
+		return "title"
+	case asset.Img != nil:
+		return "img"
+	case asset.Video != nil:
+		return "video"
+	case asset.Data != nil:
+		return "data"
+	}
+	return "untyped"
+}
+
+func nativeResponseAssetType(asset nativeResponse.Asset) string {
+	switch {
+	case asset.Title != nil:
+		return "title"
+	case asset.Img != nil:
+		return "img"
+	case asset.Video != nil:
+		return "video"
+	case asset.Data != nil:
+		return "data"
+	}
+	return "untyped"
+}
+
+func makeNonBid(bid *pbsOrtbBid, statusCode openrtb_ext.NonBidStatusCode) openrtb_ext.NonBid {
+	return openrtb_ext.NonBid{
+		ImpId:      bid.bid.ImpID,
+		StatusCode: statusCode,
+		Ext: openrtb_ext.NonBidExt{
+			Prebid: openrtb_ext.NonBidExtPrebid{
+				Bid: openrtb_ext.NonBidExtPrebidBid{
+					Price:   bid.bid.Price,
+					ADomain: bid.bid.ADomain,
+					CrID:    bid.bid.CrID,
+					DealID:  bid.bid.DealID,
+					W:       bid.bid.W,
+					H:       bid.bid.H,
+				},
+			},
+		},
+	}
+}
+
+// makeSeatNonBids collects the non bids of each seat for bidresponse.ext.seatnonbid, ordered by seat.
+func makeSeatNonBids(adapterExtra map[openrtb_ext.BidderName]*seatResponseExtra, blockedNonBids map[openrtb_ext.BidderName][]openrtb_ext.NonBid) []openrtb_ext.SeatNonBid {
+	var seatNonBids []openrtb_ext.SeatNonBid
+	for bidderName, extra := range adapterExtra {
+		if len(extra.NonBids) > 0 {
+			seatNonBids = append(seatNonBids, openrtb_ext.SeatNonBid{
+				Seat:   string(bidderName),
+				NonBid: extra.NonBids,
+			})
+		}
+	}
+	// The bidders blocked before the auction were never called, so they have no extra
+	for bidderName, nonBids := range blockedNonBids {
+		if len(nonBids) > 0 {
+			seatNonBids = append(seatNonBids, openrtb_ext.SeatNonBid{
+				Seat:   string(bidderName),
+				NonBid: nonBids,
+			})
+		}
+	}
+	sort.Slice(seatNonBids, func(i, j int) bool {
+		return seatNonBids[i].Seat < seatNonBids[j].Seat
+	})
+	return seatNonBids
+}
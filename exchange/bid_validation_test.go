@@ -0,0 +1,309 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+type bidValidationFailure struct {
+	bidder   openrtb_ext.BidderName
+	rule     metrics.BidValidationRule
+	enforced bool
+}
+
+func TestValidateBids(t *testing.T) {
+	secure := int8(1)
+	bidRequest := &openrtb2.BidRequest{
+		Imp: []openrtb2.Imp{
+			{ID: "banner-imp", Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}}},
+			{ID: "secure-imp", Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}}, Secure: &secure},
+		},
+	}
+	bidsOf := func() map[openrtb_ext.BidderName]*pbsOrtbSeatBid {
+		return map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+			"appnexus": {bids: []*pbsOrtbBid{
+				{bid: &openrtb2.Bid{ID: "valid", ImpID: "banner-imp", Price: 1, AdM: "<div/>", W: 300, H: 250}, bidType: openrtb_ext.BidTypeBanner},
+				{bid: &openrtb2.Bid{ID: "wrong-size", ImpID: "banner-imp", Price: 2, AdM: "<div/>", W: 728, H: 90, CrID: "cr"}, bidType: openrtb_ext.BidTypeBanner},
+			}},
+			"rubicon": {bids: []*pbsOrtbBid{
+				{bid: &openrtb2.Bid{ID: "insecure", ImpID: "secure-imp", Price: 3, AdM: `<img src="http://ad.com/a.png">`, W: 300, H: 250}, bidType: openrtb_ext.BidTypeBanner},
+			}},
+		}
+	}
+
+	testCases := []struct {
+		description       string
+		givenValidations  config.AccountValidations
+		expectedBidIDs    map[openrtb_ext.BidderName][]string
+		expectedWarnings  map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage
+		expectedNonBids   map[openrtb_ext.BidderName][]openrtb_ext.NonBid
+		expectedMetrics   []bidValidationFailure
+		expectedBidsFound bool
+	}{
+		{
+			description:       "Skipped",
+			givenValidations:  config.AccountValidations{BannerCreativeSize: config.ValidationSkip},
+			expectedBidIDs:    map[openrtb_ext.BidderName][]string{"appnexus": {"valid", "wrong-size"}, "rubicon": {"insecure"}},
+			expectedWarnings:  map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage{},
+			expectedNonBids:   map[openrtb_ext.BidderName][]openrtb_ext.NonBid{},
+			expectedBidsFound: true,
+		},
+		{
+			description:      "Warned",
+			givenValidations: config.AccountValidations{BannerCreativeSize: config.ValidationWarn},
+			expectedBidIDs:   map[openrtb_ext.BidderName][]string{"appnexus": {"valid", "wrong-size"}, "rubicon": {"insecure"}},
+			expectedWarnings: map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage{
+				"appnexus": {{
+					Code:    errortypes.BidValidationWarningCode,
+					Message: "bid wrong-size failed the creative_size validation: the size 728x90 isn't one of the sizes of imp banner-imp",
+				}},
+			},
+			expectedNonBids:   map[openrtb_ext.BidderName][]openrtb_ext.NonBid{},
+			expectedMetrics:   []bidValidationFailure{{"appnexus", metrics.BidValidationCreativeSize, false}},
+			expectedBidsFound: true,
+		},
+		{
+			description:      "Enforced",
+			givenValidations: config.AccountValidations{BannerCreativeSize: config.ValidationEnforce, SecureMarkup: config.ValidationEnforce},
+			expectedBidIDs:   map[openrtb_ext.BidderName][]string{"appnexus": {"valid"}},
+			expectedWarnings: map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage{
+				"appnexus": {{
+					Code:    errortypes.BidValidationWarningCode,
+					Message: "bid wrong-size dropped by the creative_size validation: the size 728x90 isn't one of the sizes of imp banner-imp",
+				}},
+				"rubicon": {{
+					Code:    errortypes.BidValidationWarningCode,
+					Message: "bid insecure dropped by the secure_markup validation: the markup loads insecure resources in secure imp secure-imp",
+				}},
+			},
+			expectedNonBids: map[openrtb_ext.BidderName][]openrtb_ext.NonBid{
+				"appnexus": {{
+					ImpId:      "banner-imp",
+					StatusCode: openrtb_ext.NonBidResponseRejectedCreativeSizeNotAllowed,
+					Ext:        openrtb_ext.NonBidExt{Prebid: openrtb_ext.NonBidExtPrebid{Bid: openrtb_ext.NonBidExtPrebidBid{Price: 2, CrID: "cr", W: 728, H: 90}}},
+				}},
+				"rubicon": {{
+					ImpId:      "secure-imp",
+					StatusCode: openrtb_ext.NonBidResponseRejectedCreativeNotSecure,
+					Ext:        openrtb_ext.NonBidExt{Prebid: openrtb_ext.NonBidExtPrebid{Bid: openrtb_ext.NonBidExtPrebidBid{Price: 3, W: 300, H: 250}}},
+				}},
+			},
+			expectedMetrics: []bidValidationFailure{
+				{"appnexus", metrics.BidValidationCreativeSize, true},
+				{"rubicon", metrics.BidValidationSecureMarkup, true},
+			},
+			expectedBidsFound: true,
+		},
+	}
+
+	for _, test := range testCases {
+		adapterBids := bidsOf()
+		adapterExtra := map[openrtb_ext.BidderName]*seatResponseExtra{"appnexus": {}, "rubicon": {}}
+		metricsMock := &metrics.MetricsEngineMock{}
+		for _, failure := range test.expectedMetrics {
+			metricsMock.On("RecordBidValidationFailure", failure.bidder, failure.rule, failure.enforced).Return()
+		}
+
+		bidsFound := validateBids(bidRequest, adapterBids, adapterExtra, nil, test.givenValidations, metricsMock)
+
+		assert.Equal(t, test.expectedBidsFound, bidsFound, test.description+":bids_found")
+		actualBidIDs := make(map[openrtb_ext.BidderName][]string)
+		for bidderName, seatBid := range adapterBids {
+			for _, bid := range seatBid.bids {
+				actualBidIDs[bidderName] = append(actualBidIDs[bidderName], bid.bid.ID)
+			}
+		}
+		assert.Equal(t, test.expectedBidIDs, actualBidIDs, test.description+":bids")
+		actualWarnings := make(map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage)
+		actualNonBids := make(map[openrtb_ext.BidderName][]openrtb_ext.NonBid)
+		for bidderName, extra := range adapterExtra {
+			if len(extra.Warnings) > 0 {
+				actualWarnings[bidderName] = extra.Warnings
+			}
+			if len(extra.NonBids) > 0 {
+				actualNonBids[bidderName] = extra.NonBids
+			}
+		}
+		assert.Equal(t, test.expectedWarnings, actualWarnings, test.description+":warnings")
+		assert.Equal(t, test.expectedNonBids, actualNonBids, test.description+":nonbids")
+		metricsMock.AssertExpectations(t)
+	}
+}
+
+func TestValidateBidRules(t *testing.T) {
+	w, h := int64(320), int64(50)
+	secure := int8(1)
+	bannerImp := &openrtb2.Imp{ID: "imp", Banner: &openrtb2.Banner{W: &w, H: &h}}
+	secureImp := &openrtb2.Imp{ID: "imp", Secure: &secure}
+	nativeImp := &openrtb2.Imp{ID: "imp", Native: &openrtb2.Native{Request: `{"assets":[{"id":1,"required":1,"title":{"len":90}},{"id":2,"img":{"type":3}}]}`}}
+
+	testCases := []struct {
+		description string
+		validate    func(bid *pbsOrtbBid, imp *openrtb2.Imp) error
+		bid         *pbsOrtbBid
+		imp         *openrtb2.Imp
+		expectedErr string
+	}{
+		{
+			description: "Adm present",
+			validate:    validateAdmPresence,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{AdM: "<div/>"}},
+			imp:         bannerImp,
+		},
+		{
+			description: "Nurl present",
+			validate:    validateAdmPresence,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{NURL: "https://win.com"}},
+			imp:         bannerImp,
+		},
+		{
+			description: "No adm nor nurl",
+			validate:    validateAdmPresence,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{}},
+			imp:         bannerImp,
+			expectedErr: "the bid has neither adm nor nurl",
+		},
+		{
+			description: "Banner size",
+			validate:    validateBannerCreativeSize,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{W: 320, H: 50}, bidType: openrtb_ext.BidTypeBanner},
+			imp:         bannerImp,
+		},
+		{
+			description: "Banner without size",
+			validate:    validateBannerCreativeSize,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{}, bidType: openrtb_ext.BidTypeBanner},
+			imp:         bannerImp,
+			expectedErr: "the bid has no size",
+		},
+		{
+			description: "Video bids aren't checked",
+			validate:    validateBannerCreativeSize,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{}, bidType: openrtb_ext.BidTypeVideo},
+			imp:         bannerImp,
+		},
+		{
+			description: "Secure markup",
+			validate:    validateSecureMarkup,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{AdM: `<img src="https://ad.com/a.png">`, NURL: "https://win.com"}},
+			imp:         secureImp,
+		},
+		{
+			description: "Encoded insecure url",
+			validate:    validateSecureMarkup,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{AdM: `<img src="https://ad.com/r?u=HTTP%3A%2F%2Fad.com">`}},
+			imp:         secureImp,
+			expectedErr: "the markup loads insecure resources in secure imp imp",
+		},
+		{
+			description: "Insecure nurl",
+			validate:    validateSecureMarkup,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{NURL: "http://win.com"}},
+			imp:         secureImp,
+			expectedErr: "the nurl is insecure in secure imp imp",
+		},
+		{
+			description: "Native assets",
+			validate:    validateNativeAssets,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{AdM: `{"assets":[{"id":1,"title":{"text":"title"}},{"id":2,"img":{"url":"https://ad.com/a.png"}}]}`}, bidType: openrtb_ext.BidTypeNative},
+			imp:         nativeImp,
+		},
+		{
+			description: "Native assets wrapped in a native object",
+			validate:    validateNativeAssets,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{AdM: `{"native":{"assets":[{"id":1,"title":{"text":"title"}}]}}`}, bidType: openrtb_ext.BidTypeNative},
+			imp:         nativeImp,
+		},
+		{
+			description: "Native assets left to an assetsurl",
+			validate:    validateNativeAssets,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{AdM: `{"assetsurl":"https://ad.com/assets"}`}, bidType: openrtb_ext.BidTypeNative},
+			imp:         nativeImp,
+		},
+		{
+			description: "Invalid native markup",
+			validate:    validateNativeAssets,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{AdM: `<div/>`}, bidType: openrtb_ext.BidTypeNative},
+			imp:         nativeImp,
+			expectedErr: "the native markup isn't valid: invalid character '<' looking for beginning of value",
+		},
+		{
+			description: "Native asset without id",
+			validate:    validateNativeAssets,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{AdM: `{"assets":[{"title":{"text":"title"}}]}`}, bidType: openrtb_ext.BidTypeNative},
+			imp:         nativeImp,
+			expectedErr: "the native asset 0 has no id",
+		},
+		{
+			description: "Native asset not requested",
+			validate:    validateNativeAssets,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{AdM: `{"assets":[{"id":1,"title":{"text":"title"}},{"id":3,"data":{"value":"5"}}]}`}, bidType: openrtb_ext.BidTypeNative},
+			imp:         nativeImp,
+			expectedErr: "the native asset 3 isn't one of the assets of imp imp",
+		},
+		{
+			description: "Native asset of another type",
+			validate:    validateNativeAssets,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{AdM: `{"assets":[{"id":1,"title":{"text":"title"}},{"id":2,"data":{"value":"5"}}]}`}, bidType: openrtb_ext.BidTypeNative},
+			imp:         nativeImp,
+			expectedErr: "the native asset 2 is a data asset, but imp imp requested a img asset",
+		},
+		{
+			description: "Required native asset missing",
+			validate:    validateNativeAssets,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{AdM: `{"assets":[{"id":2,"img":{"url":"https://ad.com/a.png"}}]}`}, bidType: openrtb_ext.BidTypeNative},
+			imp:         nativeImp,
+			expectedErr: "the required native asset 1 of imp imp is missing",
+		},
+		{
+			description: "Banner bids aren't checked for native assets",
+			validate:    validateNativeAssets,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{AdM: `<div/>`}, bidType: openrtb_ext.BidTypeBanner},
+			imp:         nativeImp,
+		},
+		{
+			description: "Insecure imp",
+			validate:    validateSecureMarkup,
+			bid:         &pbsOrtbBid{bid: &openrtb2.Bid{AdM: `<img src="http://ad.com/a.png">`}},
+			imp:         bannerImp,
+		},
+	}
+
+	for _, test := range testCases {
+		err := test.validate(test.bid, test.imp)
+
+		if test.expectedErr != "" {
+			assert.EqualError(t, err, test.expectedErr, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+		}
+	}
+}
+
+func TestMakeSeatNonBids(t *testing.T) {
+	nonBid := openrtb_ext.NonBid{ImpId: "imp", StatusCode: openrtb_ext.NonBidResponseRejectedInvalidCreative}
+	adapterExtra := map[openrtb_ext.BidderName]*seatResponseExtra{
+		"rubicon":  {NonBids: []openrtb_ext.NonBid{nonBid}},
+		"openx":    {},
+		"appnexus": {NonBids: []openrtb_ext.NonBid{nonBid, nonBid}},
+	}
+
+	blockedNonBid := openrtb_ext.NonBid{ImpId: "imp", StatusCode: openrtb_ext.NonBidRequestBlockedPrivacy}
+	blockedNonBids := map[openrtb_ext.BidderName][]openrtb_ext.NonBid{
+		"pubmatic": {blockedNonBid},
+	}
+
+	seatNonBids := makeSeatNonBids(adapterExtra, blockedNonBids)
+
+	assert.Equal(t, []openrtb_ext.SeatNonBid{
+		{Seat: "appnexus", NonBid: []openrtb_ext.NonBid{nonBid, nonBid}},
+		{Seat: "pubmatic", NonBid: []openrtb_ext.NonBid{blockedNonBid}},
+		{Seat: "rubicon", NonBid: []openrtb_ext.NonBid{nonBid}},
+	}, seatNonBids)
+}
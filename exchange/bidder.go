@@ -8,8 +8,11 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
 	"net/http/httptrace"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/golang/glog"
@@ -17,6 +20,7 @@ import (
 	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/version"
 
+	"github.com/buger/jsonparser"
 	nativeRequests "github.com/mxmCherry/openrtb/v15/native1/request"
 	nativeResponse "github.com/mxmCherry/openrtb/v15/native1/response"
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
@@ -25,6 +29,7 @@ import (
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/tracing"
 	"golang.org/x/net/context/ctxhttp"
 )
 
@@ -64,6 +69,7 @@ type adaptedBidder interface {
 // pbsOrtbBid.dealPriority is optionally provided by adapters and used internally by the exchange to support deal targeted campaigns.
 // pbsOrtbBid.dealTierSatisfied is set to true by exchange.updateHbPbCatDur if deal tier satisfied otherwise it will be set to false
 // pbsOrtbBid.generatedBidID is unique bid id generated by prebid server if generate bid id option is enabled in config
+// pbsOrtbBid.targetBidderCode is set by the exchange on the extra bids of multibid bidders, and replaces the bidder code in their targeting keys
 type pbsOrtbBid struct {
 	bid               *openrtb2.Bid
 	bidMeta           *openrtb_ext.ExtBidPrebidMeta
@@ -74,6 +80,9 @@ type pbsOrtbBid struct {
 	dealPriority      int
 	dealTierSatisfied bool
 	generatedBidID    string
+	targetBidderCode  string
+	// storedResponse is set on the bids which come from a stored response
+	storedResponse *openrtb_ext.ExtBidPrebidStoredResponse
 }
 
 // pbsOrtbSeatBid is a SeatBid returned by an adaptedBidder.
@@ -88,13 +97,15 @@ type pbsOrtbSeatBid struct {
 	// httpCalls is the list of debugging info. It should only be populated if the request.test == 1.
 	// This will become response.ext.debug.httpcalls.{bidder} on the final Response.
 	httpCalls []*openrtb_ext.ExtHttpCall
+	// seat is the seatbid.seat for these bids. If empty, the bidder name is used.
+	seat string
 }
 
 // adaptBidder converts an adapters.Bidder into an exchange.adaptedBidder.
 //
 // The name refers to the "Adapter" architecture pattern, and should not be confused with a Prebid "Adapter"
 // (which is being phased out and replaced by Bidder for OpenRTB auctions)
-func adaptBidder(bidder adapters.Bidder, client *http.Client, cfg *config.Configuration, me metrics.MetricsEngine, name openrtb_ext.BidderName, debugInfo *config.DebugInfo) adaptedBidder {
+func adaptBidder(bidder adapters.Bidder, client *http.Client, cfg *config.Configuration, me metrics.MetricsEngine, name openrtb_ext.BidderName, debugInfo *config.DebugInfo, compressionInfo *config.CompressionInfo) adaptedBidder {
 	return &bidderAdapter{
 		Bidder:     bidder,
 		BidderName: name,
@@ -104,7 +115,11 @@ func adaptBidder(bidder adapters.Bidder, client *http.Client, cfg *config.Config
 			Debug:              cfg.Debug,
 			DisableConnMetrics: cfg.Metrics.Disabled.AdapterConnectionMetrics,
 			DebugInfo:          config.DebugInfo{Allow: parseDebugInfo(debugInfo)},
+			Retry:              cfg.Adapters[strings.ToLower(string(name))].Retry,
+			Seat:               cfg.Adapters[strings.ToLower(string(name))].Seat,
+			Compression:        parseCompressionInfo(compressionInfo),
 		},
+		breaker: newCircuitBreaker(cfg.Adapters[strings.ToLower(string(name))].CircuitBreaker),
 	}
 }
 
@@ -115,18 +130,29 @@ func parseDebugInfo(info *config.DebugInfo) bool {
 	return info.Allow
 }
 
+func parseCompressionInfo(info *config.CompressionInfo) config.CompressionInfo {
+	if info == nil {
+		return config.CompressionInfo{}
+	}
+	return *info
+}
+
 type bidderAdapter struct {
 	Bidder     adapters.Bidder
 	BidderName openrtb_ext.BidderName
 	Client     *http.Client
 	me         metrics.MetricsEngine
 	config     bidderAdapterConfig
+	breaker    *circuitBreaker
 }
 
 type bidderAdapterConfig struct {
 	Debug              config.Debug
 	DisableConnMetrics bool
 	DebugInfo          config.DebugInfo
+	Retry              config.AdapterRetry
+	Seat               string
+	Compression        config.CompressionInfo
 }
 
 func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed, headerDebugAllowed bool) (*pbsOrtbSeatBid, []error) {
@@ -139,6 +165,11 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.B
 		}
 		return nil, errs
 	}
+	allowed, probe := bidder.breaker.allow()
+	if !allowed {
+		errs = append(errs, &errortypes.BidderCircuitOpen{Message: fmt.Sprintf("The bidder %s was not called because its circuit breaker is open after sustained failures", name)})
+		return nil, errs
+	}
 	xPrebidHeader := buildXPrebidHeader(request, version.Ver)
 
 	for i := 0; i < len(reqData); i++ {
@@ -172,11 +203,17 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.B
 		currency:  defaultCurrency,
 		httpCalls: make([]*openrtb_ext.ExtHttpCall, 0, len(reqData)),
 	}
+	// The configured seat belongs to the core bidder, aliases keep their own code
+	if name == bidder.BidderName {
+		seatBid.seat = bidder.config.Seat
+	}
 
 	// If the bidder made multiple requests, we still want them to enter as many bids as possible...
 	// even if the timeout occurs sometime halfway through.
 	for i := 0; i < len(reqData); i++ {
 		httpInfo := <-responseChannel
+		bidder.breaker.record(isBidderFailure(httpInfo), probe)
+		captureHTTPCall(ctx, name, httpInfo)
 		// If this is a test bid, capture debugging info from the requests.
 		// Write debug data to ext in case if:
 		// - headerDebugAllowed (debug override header specified correct) - it overrides all other debug restrictions
@@ -214,6 +251,9 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.B
 				if len(request.Cur) == 0 {
 					request.Cur = []string{defaultCurrency}
 				}
+				if bidResponse.Seat != "" {
+					seatBid.seat = bidResponse.Seat
+				}
 
 				// Try to get a conversion rate
 				// Try to get the first currency from request.cur having a match in the rate converter,
@@ -257,7 +297,7 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.B
 							bidMeta:      bidResponse.Bids[i].BidMeta,
 							bidType:      bidResponse.Bids[i].BidType,
 							bidVideo:     bidResponse.Bids[i].BidVideo,
-							dealPriority: bidResponse.Bids[i].DealPriority,
+							dealPriority: getDealPriority(bidResponse.Bids[i]),
 						})
 					}
 				} else {
@@ -273,6 +313,27 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.B
 	return seatBid, errs
 }
 
+// getDealPriority returns the deal priority an adapter set on a bid, falling back to bid.ext.prebid.dealpriority for
+// the deal bids of adapters which only pass it through the bid ext.
+func getDealPriority(typedBid *adapters.TypedBid) int {
+	if typedBid.DealPriority != 0 || typedBid.Bid == nil || typedBid.Bid.DealID == "" {
+		return typedBid.DealPriority
+	}
+	if dealPriority, err := jsonparser.GetInt(typedBid.Bid.Ext, "prebid", "dealpriority"); err == nil {
+		return int(dealPriority)
+	}
+	return 0
+}
+
+// isBidderFailure reports whether a bidder call failed in a way which means the bidder is unhealthy. Bidders
+// rejecting the request with a 4xx status were still able to answer.
+func isBidderFailure(httpInfo *httpCallInfo) bool {
+	if httpInfo.err == nil {
+		return false
+	}
+	return httpInfo.response == nil || httpInfo.response.StatusCode >= http.StatusInternalServerError
+}
+
 func addNativeTypes(bid *openrtb2.Bid, request *openrtb2.BidRequest) (*nativeResponse.Response, []error) {
 	var errs []error
 	var nativeMarkup *nativeResponse.Response
@@ -389,14 +450,118 @@ func (bidder *bidderAdapter) doRequest(ctx context.Context, req *adapters.Reques
 }
 
 func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.RequestData, logger util.LogMsg) *httpCallInfo {
-	httpReq, err := http.NewRequest(req.Method, req.Uri, bytes.NewBuffer(req.Body))
+	httpInfo := bidder.doTracedRequestAttempt(ctx, req, logger)
+
+	if backoff, ok := bidder.retryBackoff(ctx, httpInfo); ok {
+		select {
+		case <-time.After(backoff):
+			bidder.me.RecordAdapterRetry(bidder.BidderName)
+			httpInfo = bidder.doTracedRequestAttempt(ctx, req, logger)
+			if httpInfo.err == nil {
+				bidder.me.RecordAdapterRetryRecovered(bidder.BidderName)
+			}
+		case <-ctx.Done():
+		}
+	}
+
+	return httpInfo
+}
+
+// retryBackoff determines whether a failed bidder call should be retried and, if so, how long to wait before
+// doing so. Only idempotent calls answered with one of the configured status codes, and calls whose connection
+// failed before any response was read, are retried. They are retried only if the jittered backoff leaves some of
+// the auction time budget for the retry itself.
+func (bidder *bidderAdapter) retryBackoff(ctx context.Context, httpInfo *httpCallInfo) (time.Duration, bool) {
+	retry := bidder.config.Retry
+	if !bidder.isRetryable(httpInfo) {
+		return 0, false
+	}
+
+	// Jitter between half and the full backoff so that retries from many auctions don't hit the bidder in lockstep.
+	backoff := time.Duration(retry.BackoffMs) * time.Millisecond
+	if backoff > 0 {
+		backoff = backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+	}
+
+	if deadline, hasDeadline := ctx.Deadline(); hasDeadline && time.Until(deadline) <= backoff {
+		return 0, false
+	}
+
+	return backoff, true
+}
+
+func (bidder *bidderAdapter) isRetryable(httpInfo *httpCallInfo) bool {
+	retry := bidder.config.Retry
+	if retry.ConnectionErrors && httpInfo.response == nil && isConnectionError(httpInfo.err) {
+		return true
+	}
+
+	if !retry.Enabled || httpInfo.response == nil {
+		return false
+	}
+
+	if httpInfo.request.Method != http.MethodGet && httpInfo.request.Method != http.MethodHead {
+		return false
+	}
+
+	for _, statusCode := range retry.StatusCodes {
+		if httpInfo.response.StatusCode == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// isConnectionError tells whether the bidder refused or reset the connection. Neither leaves a response to read,
+// so the bidder can be called again.
+func isConnectionError(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// doTracedRequestAttempt makes the request attempt in a client span of the trace of the auction, if it is traced
+func (bidder *bidderAdapter) doTracedRequestAttempt(ctx context.Context, req *adapters.RequestData, logger util.LogMsg) *httpCallInfo {
+	ctx, span := tracing.StartSpan(ctx, "bidder.request", tracing.SpanKindClient)
+	if span == nil {
+		return bidder.doRequestAttempt(ctx, req, logger)
+	}
+
+	span.SetAttribute("prebid.bidder", string(bidder.BidderName))
+	span.SetAttribute("http.method", req.Method)
+	httpInfo := bidder.doRequestAttempt(ctx, req, logger)
+	if httpInfo.response != nil {
+		span.SetAttribute("http.status_code", httpInfo.response.StatusCode)
+	}
+	span.SetError(httpInfo.err)
+	span.End()
+	return httpInfo
+}
+
+func (bidder *bidderAdapter) doRequestAttempt(ctx context.Context, req *adapters.RequestData, logger util.LogMsg) *httpCallInfo {
+	body := req.Body
+	gzipRequest := bidder.config.Compression.GZIPRequests && len(req.Body) > 0
+	gzipResponse := bidder.config.Compression.GZIPResponses
+	if gzipRequest {
+		compressedBody, err := gzipCompress(req.Body)
+		if err != nil {
+			return &httpCallInfo{
+				request: req,
+				err:     err,
+			}
+		}
+		body = compressedBody
+		if saved := len(req.Body) - len(body); saved > 0 {
+			bidder.me.RecordAdapterGzipBytesSaved(bidder.BidderName, metrics.AdapterCompressionRequest, saved)
+		}
+	}
+
+	httpReq, err := http.NewRequest(req.Method, req.Uri, bytes.NewBuffer(body))
 	if err != nil {
 		return &httpCallInfo{
 			request: req,
 			err:     err,
 		}
 	}
-	httpReq.Header = req.Headers
+	httpReq.Header = tracing.InjectHeaders(ctx, compressionHeaders(req.Headers, gzipRequest, gzipResponse))
 
 	// If adapter connection metrics are not disabled, add the client trace
 	// to get complete connection info into our metrics
@@ -437,6 +602,23 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 	}
 	defer httpResp.Body.Close()
 
+	if gzipResponse && httpResp.Header.Get("Content-Encoding") == gzipEncoding {
+		decompressedBody, err := gzipDecompress(respBody)
+		if err != nil {
+			return &httpCallInfo{
+				request: req,
+				err: &errortypes.BadServerResponse{
+					Message: fmt.Sprintf("Server responded with an invalid gzip body: %v", err),
+				},
+			}
+		}
+		if saved := len(decompressedBody) - len(respBody); saved > 0 {
+			bidder.me.RecordAdapterGzipBytesSaved(bidder.BidderName, metrics.AdapterCompressionResponse, saved)
+		}
+		respBody = decompressedBody
+		httpResp.Header.Del("Content-Encoding")
+	}
+
 	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 400 {
 		err = &errortypes.BadServerResponse{
 			Message: fmt.Sprintf("Server responded with failure status: %d. Set request.test = 1 for debugging info.", httpResp.StatusCode),
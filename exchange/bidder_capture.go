@@ -0,0 +1,66 @@
+package exchange
+
+import (
+	"context"
+	"time"
+
+	"github.com/prebid/prebid-server/biddercapture"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// bidderCaptureContextKey holds the bidderCapture of the bidder calls sampled for capture
+const bidderCaptureContextKey = ContextKey("bidderCapture")
+
+// bidderCapture captures the HTTP calls made for a bidder request
+type bidderCapture struct {
+	capturer  *biddercapture.Capturer
+	requestID string
+	account   string
+}
+
+// sampleBidderCaptures picks the bidder requests whose calls are captured, by the sampling of the account
+func sampleBidderCaptures(bidderRequests []BidderRequest, capturer *biddercapture.Capturer, account config.Account) {
+	for i := range bidderRequests {
+		if capturer.Sampled(account.BidderCapture, bidderRequests[i].BidderCoreName) {
+			bidderRequests[i].capture = &bidderCapture{
+				capturer:  capturer,
+				requestID: bidderRequests[i].BidRequest.ID,
+				account:   account.ID,
+			}
+		}
+	}
+}
+
+func withBidderCapture(ctx context.Context, capture *bidderCapture) context.Context {
+	if capture == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, bidderCaptureContextKey, capture)
+}
+
+// captureHTTPCall captures an HTTP call of a bidder if its bidder request was sampled for capture
+func captureHTTPCall(ctx context.Context, bidder openrtb_ext.BidderName, httpInfo *httpCallInfo) {
+	capture, ok := ctx.Value(bidderCaptureContextKey).(*bidderCapture)
+	if !ok || httpInfo == nil || httpInfo.request == nil {
+		return
+	}
+
+	call := biddercapture.Call{
+		Timestamp:      time.Now(),
+		RequestID:      capture.requestID,
+		Account:        capture.account,
+		Bidder:         string(bidder),
+		Method:         httpInfo.request.Method,
+		Uri:            httpInfo.request.Uri,
+		RequestHeaders: filterHeader(httpInfo.request.Headers),
+		RequestBody:    string(httpInfo.request.Body),
+	}
+	if httpInfo.err != nil {
+		call.Error = httpInfo.err.Error()
+	} else if httpInfo.response != nil {
+		call.Status = httpInfo.response.StatusCode
+		call.ResponseBody = string(httpInfo.response.Body)
+	}
+	capture.capturer.Capture(call)
+}
@@ -0,0 +1,139 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/biddercapture"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	metricsConfig "github.com/prebid/prebid-server/metrics/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFileCapturer returns a capturer writing to a file of a temporary directory, along with a function shutting the
+// capturer down and returning the captured calls
+func newFileCapturer(t *testing.T) (*biddercapture.Capturer, func() []biddercapture.Call) {
+	dir, err := ioutil.TempDir("", "biddercapture")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "capture.log")
+
+	capturer, err := biddercapture.NewCapturer(config.BidderCapture{Enabled: true, Sink: config.BidderCaptureSinkFile, Filename: filename, BufferSize: 10}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return capturer, func() []biddercapture.Call {
+		capturer.Shutdown()
+		defer os.RemoveAll(dir)
+
+		content, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var calls []biddercapture.Call
+		for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+			var call biddercapture.Call
+			if line != "" && json.Unmarshal([]byte(line), &call) == nil {
+				calls = append(calls, call)
+			}
+		}
+		return calls
+	}
+}
+
+func TestSampleBidderCaptures(t *testing.T) {
+	capturer, shutdown := newFileCapturer(t)
+	defer shutdown()
+
+	bidderRequests := []BidderRequest{
+		{BidderName: "appnexus", BidderCoreName: "appnexus", BidRequest: &openrtb2.BidRequest{ID: "req1"}},
+		{BidderName: "rubicon", BidderCoreName: "rubicon", BidRequest: &openrtb2.BidRequest{ID: "req1"}},
+	}
+	account := config.Account{
+		ID:            "acct1",
+		BidderCapture: config.AccountBidderCapture{Enabled: true, Bidders: map[string]float64{"appnexus": 100}},
+	}
+
+	sampleBidderCaptures(bidderRequests, capturer, account)
+
+	assert.Equal(t, &bidderCapture{capturer: capturer, requestID: "req1", account: "acct1"}, bidderRequests[0].capture)
+	assert.Nil(t, bidderRequests[1].capture)
+}
+
+func TestSampleBidderCapturesDisabled(t *testing.T) {
+	bidderRequests := []BidderRequest{{BidderName: "appnexus", BidderCoreName: "appnexus", BidRequest: &openrtb2.BidRequest{ID: "req1"}}}
+	account := config.Account{BidderCapture: config.AccountBidderCapture{Enabled: true, SamplePercent: 100}}
+
+	sampleBidderCaptures(bidderRequests, nil, account)
+
+	assert.Nil(t, bidderRequests[0].capture)
+}
+
+func TestCaptureHTTPCall(t *testing.T) {
+	capturer, shutdown := newFileCapturer(t)
+	ctx := withBidderCapture(context.Background(), &bidderCapture{capturer: capturer, requestID: "req1", account: "acct1"})
+
+	request := &adapters.RequestData{
+		Method:  http.MethodPost,
+		Uri:     "https://bidder.com",
+		Body:    []byte(`{"id":"req1"}`),
+		Headers: http.Header{"Authorization": []string{"secret"}, "Content-Type": []string{"application/json"}},
+	}
+	captureHTTPCall(ctx, "appnexus", &httpCallInfo{request: request, response: &adapters.ResponseData{StatusCode: 200, Body: []byte(`{"id":"resp1"}`)}})
+	captureHTTPCall(ctx, "appnexus", &httpCallInfo{request: request, err: errors.New("connection refused")})
+	captureHTTPCall(context.Background(), "rubicon", &httpCallInfo{request: request})
+
+	calls := shutdown()
+	if assert.Len(t, calls, 2) {
+		assert.Equal(t, "req1", calls[0].RequestID)
+		assert.Equal(t, "acct1", calls[0].Account)
+		assert.Equal(t, "appnexus", calls[0].Bidder)
+		assert.Equal(t, `{"id":"req1"}`, calls[0].RequestBody)
+		assert.Equal(t, map[string][]string{"Content-Type": {"application/json"}}, calls[0].RequestHeaders, "the authorization header isn't captured")
+		assert.Equal(t, 200, calls[0].Status)
+		assert.Equal(t, `{"id":"resp1"}`, calls[0].ResponseBody)
+		assert.Equal(t, "connection refused", calls[1].Error)
+	}
+}
+
+func TestRequestBidCapture(t *testing.T) {
+	server := httptest.NewServer(mockHandler(200, "getBody", `{"bid":false}`))
+	defer server.Close()
+
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method:  "POST",
+			Uri:     server.URL,
+			Body:    []byte(`{"key":"val"}`),
+			Headers: http.Header{},
+		},
+		bidResponse: &adapters.BidderResponse{},
+	}
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil)
+	capturer, shutdown := newFileCapturer(t)
+	ctx := withBidderCapture(context.Background(), &bidderCapture{capturer: capturer, requestID: "req1", account: "acct1"})
+
+	_, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "appnexus", 1, currency.NewConstantRates(), &adapters.ExtraRequestInfo{}, true, false)
+
+	assert.Empty(t, errs)
+	calls := shutdown()
+	if assert.Len(t, calls, 1) {
+		assert.Equal(t, server.URL, calls[0].Uri)
+		assert.Equal(t, `{"key":"val"}`, calls[0].RequestBody)
+		assert.Equal(t, `{"bid":false}`, calls[0].ResponseBody)
+	}
+}
@@ -0,0 +1,53 @@
+package exchange
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+)
+
+const gzipEncoding = "gzip"
+
+// compressionHeaders returns the headers of a bidder call with the gzip negotiation of the bidder added. The adapter's
+// headers are reused by retries, so they are copied rather than modified.
+func compressionHeaders(headers http.Header, gzipRequest, gzipResponse bool) http.Header {
+	if !gzipRequest && !gzipResponse {
+		return headers
+	}
+
+	compressionHeaders := headers.Clone()
+	if compressionHeaders == nil {
+		compressionHeaders = http.Header{}
+	}
+	if gzipRequest {
+		compressionHeaders.Set("Content-Encoding", gzipEncoding)
+	}
+	if gzipResponse {
+		// Setting Accept-Encoding turns off the transparent decompression of the transport, so the bytes saved
+		// can be measured
+		compressionHeaders.Set("Accept-Encoding", gzipEncoding)
+	}
+	return compressionHeaders
+}
+
+func gzipCompress(body []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
+
+func gzipDecompress(body []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
@@ -8,10 +8,13 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/http/httptrace"
+	"os"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -26,6 +29,7 @@ import (
 	"github.com/prebid/prebid-server/metrics"
 	metricsConfig "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/tracing"
 	"github.com/prebid/prebid-server/version"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -92,7 +96,7 @@ func TestSingleBidder(t *testing.T) {
 		}
 		bidderImpl.bidResponse = mockBidderResponse
 
-		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, test.debugInfo)
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, test.debugInfo, nil)
 		currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
 
 		seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", bidAdjustment, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, false)
@@ -172,7 +176,7 @@ func TestRequestBidRemovesSensitiveHeaders(t *testing.T) {
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, DebugContextKey, true)
 
-	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, debugInfo)
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, debugInfo, nil)
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
 	seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", 1, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, false)
 
@@ -213,7 +217,7 @@ func TestSetGPCHeader(t *testing.T) {
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, DebugContextKey, true)
 
-	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, debugInfo)
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, debugInfo, nil)
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
 	seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", 1, currencyConverter.Rates(), &adapters.ExtraRequestInfo{GlobalPrivacyControlHeader: "1"}, true, false)
 
@@ -251,7 +255,7 @@ func TestSetGPCHeaderNil(t *testing.T) {
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, DebugContextKey, true)
 
-	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, debugInfo)
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, debugInfo, nil)
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
 	seatBid, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", 1, currencyConverter.Rates(), &adapters.ExtraRequestInfo{GlobalPrivacyControlHeader: "1"}, true, false)
 
@@ -309,7 +313,7 @@ func TestMultiBidder(t *testing.T) {
 			}},
 		bidResponse: mockBidderResponse,
 	}
-	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil)
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
 	seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, true)
 
@@ -663,7 +667,7 @@ func TestMultiCurrencies(t *testing.T) {
 		)
 
 		// Execute:
-		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil)
 		currencyConverter := currency.NewRateConverter(
 			&http.Client{},
 			mockedHTTPServer.URL,
@@ -816,7 +820,7 @@ func TestMultiCurrencies_RateConverterNotSet(t *testing.T) {
 		}
 
 		// Execute:
-		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil)
 		currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
 		seatBid, errs := bidder.requestBid(
 			context.Background(),
@@ -978,7 +982,7 @@ func TestMultiCurrencies_RequestCurrencyPick(t *testing.T) {
 		}
 
 		// Execute:
-		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil)
 		currencyConverter := currency.NewRateConverter(
 			&http.Client{},
 			mockedHTTPServer.URL,
@@ -1288,7 +1292,7 @@ func TestMobileNativeTypes(t *testing.T) {
 			},
 			bidResponse: tc.mockBidderResponse,
 		}
-		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil)
 		currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
 
 		seatBids, _ := bidder.requestBid(
@@ -1311,7 +1315,7 @@ func TestMobileNativeTypes(t *testing.T) {
 }
 
 func TestErrorReporting(t *testing.T) {
-	bidder := adaptBidder(&bidRejector{}, nil, &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+	bidder := adaptBidder(&bidRejector{}, nil, &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil)
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
 	bids, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, false)
 	if bids != nil {
@@ -1532,7 +1536,7 @@ func TestCallRecordAdapterConnections(t *testing.T) {
 	metrics.On("RecordAdapterConnections", expectedAdapterName, false, mock.MatchedBy(compareConnWaitTime)).Once()
 
 	// Run requestBid using an http.Client with a mock handler
-	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, metrics, openrtb_ext.BidderAppnexus, nil)
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, metrics, openrtb_ext.BidderAppnexus, nil, nil)
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
 	_, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", bidAdjustment, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, true)
 
@@ -1709,6 +1713,393 @@ func TestTimeoutNotificationOn(t *testing.T) {
 	assert.EqualValues(t, logExpected, logActual)
 }
 
+func TestDoRequestRetry(t *testing.T) {
+	testCases := []struct {
+		description        string
+		method             string
+		timeout            time.Duration
+		expectedStatusCode int
+		expectedCalls      int
+		expectedRetries    int
+		expectedRecoveries int
+	}{
+		{
+			description:        "Idempotent request is retried after a 503",
+			method:             http.MethodGet,
+			timeout:            time.Second,
+			expectedStatusCode: http.StatusOK,
+			expectedCalls:      2,
+			expectedRetries:    1,
+			expectedRecoveries: 1,
+		},
+		{
+			description:        "Remaining deadline is too short for the backoff",
+			method:             http.MethodGet,
+			timeout:            50 * time.Millisecond,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedCalls:      1,
+			expectedRetries:    0,
+		},
+		{
+			description:        "Non idempotent request is never retried",
+			method:             http.MethodPost,
+			timeout:            time.Second,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedCalls:      1,
+			expectedRetries:    0,
+		},
+	}
+
+	for _, test := range testCases {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"bid":true}`))
+		}))
+
+		metricsMock := &metrics.MetricsEngineMock{}
+		metricsMock.On("RecordAdapterRetry", openrtb_ext.BidderAppnexus).Return()
+		metricsMock.On("RecordAdapterRetryRecovered", openrtb_ext.BidderAppnexus).Return()
+
+		bidder := &bidderAdapter{
+			Bidder:     &goodSingleBidder{},
+			BidderName: openrtb_ext.BidderAppnexus,
+			Client:     server.Client(),
+			config: bidderAdapterConfig{
+				DisableConnMetrics: true,
+				Retry: config.AdapterRetry{
+					Enabled:     true,
+					StatusCodes: []int{http.StatusBadGateway, http.StatusServiceUnavailable},
+					BackoffMs:   200,
+				},
+			},
+			me: metricsMock,
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), test.timeout)
+		httpInfo := bidder.doRequestImpl(ctx, &adapters.RequestData{Method: test.method, Uri: server.URL}, glog.Warningf)
+		cancel()
+		server.Close()
+
+		if assert.NotNil(t, httpInfo.response, test.description) {
+			assert.Equal(t, test.expectedStatusCode, httpInfo.response.StatusCode, test.description)
+		}
+		assert.Equal(t, test.expectedCalls, calls, test.description)
+		metricsMock.AssertNumberOfCalls(t, "RecordAdapterRetry", test.expectedRetries)
+		metricsMock.AssertNumberOfCalls(t, "RecordAdapterRetryRecovered", test.expectedRecoveries)
+	}
+}
+
+// connectionErrorTransport refuses the connection of the first failures round trips
+type connectionErrorTransport struct {
+	failures int
+	calls    int
+}
+
+func (transport *connectionErrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport.calls++
+	if transport.calls <= transport.failures {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestDoRequestRetryConnectionErrors(t *testing.T) {
+	testCases := []struct {
+		description        string
+		connectionErrors   bool
+		failures           int
+		expectedCalls      int
+		expectedRetries    int
+		expectedRecoveries int
+		expectError        bool
+	}{
+		{
+			description:        "Refused connection is retried and recovers",
+			connectionErrors:   true,
+			failures:           1,
+			expectedCalls:      2,
+			expectedRetries:    1,
+			expectedRecoveries: 1,
+		},
+		{
+			description:      "Refused connection is retried once only",
+			connectionErrors: true,
+			failures:         2,
+			expectedCalls:    2,
+			expectedRetries:  1,
+			expectError:      true,
+		},
+		{
+			description:   "Connection error retries are disabled",
+			failures:      1,
+			expectedCalls: 1,
+			expectError:   true,
+		},
+	}
+
+	server := httptest.NewServer(mockHandler(http.StatusOK, "getBody", `{"bid":true}`))
+	defer server.Close()
+
+	for _, test := range testCases {
+		transport := &connectionErrorTransport{failures: test.failures}
+
+		metricsMock := &metrics.MetricsEngineMock{}
+		metricsMock.On("RecordAdapterRetry", openrtb_ext.BidderAppnexus).Return()
+		metricsMock.On("RecordAdapterRetryRecovered", openrtb_ext.BidderAppnexus).Return()
+
+		bidder := &bidderAdapter{
+			Bidder:     &goodSingleBidder{},
+			BidderName: openrtb_ext.BidderAppnexus,
+			Client:     &http.Client{Transport: transport},
+			config: bidderAdapterConfig{
+				DisableConnMetrics: true,
+				Retry:              config.AdapterRetry{ConnectionErrors: test.connectionErrors, BackoffMs: 10},
+			},
+			me: metricsMock,
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		httpInfo := bidder.doRequestImpl(ctx, &adapters.RequestData{Method: http.MethodPost, Uri: server.URL, Body: []byte(`{}`)}, glog.Warningf)
+		cancel()
+
+		if test.expectError {
+			assert.True(t, isConnectionError(httpInfo.err), test.description)
+		} else {
+			assert.NoError(t, httpInfo.err, test.description)
+		}
+		assert.Equal(t, test.expectedCalls, transport.calls, test.description)
+		metricsMock.AssertNumberOfCalls(t, "RecordAdapterRetry", test.expectedRetries)
+		metricsMock.AssertNumberOfCalls(t, "RecordAdapterRetryRecovered", test.expectedRecoveries)
+	}
+}
+
+func TestDoRequestCompression(t *testing.T) {
+	requestBody := []byte(`{"imp":[` + strings.Repeat(`{"id":"imp","banner":{"w":300,"h":250}},`, 20) + `{"id":"last"}]}`)
+	responseBody := []byte(`{"seatbid":[` + strings.Repeat(`{"bid":[{"id":"bid","impid":"imp","price":1}]},`, 20) + `{}]}`)
+
+	var receivedHeaders http.Header
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedHeaders = r.Header
+		receivedBody, _ = ioutil.ReadAll(r.Body)
+		if r.Header.Get("Accept-Encoding") == "gzip" {
+			compressedBody, _ := gzipCompress(responseBody)
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(compressedBody)
+			return
+		}
+		w.Write(responseBody)
+	}))
+	defer server.Close()
+
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterGzipBytesSaved", openrtb_ext.BidderAppnexus, metrics.AdapterCompressionRequest, mock.AnythingOfType("int")).Return()
+	metricsMock.On("RecordAdapterGzipBytesSaved", openrtb_ext.BidderAppnexus, metrics.AdapterCompressionResponse, mock.AnythingOfType("int")).Return()
+
+	bidder := &bidderAdapter{
+		Bidder:     &goodSingleBidder{},
+		BidderName: openrtb_ext.BidderAppnexus,
+		Client:     server.Client(),
+		config: bidderAdapterConfig{
+			DisableConnMetrics: true,
+			Compression:        config.CompressionInfo{GZIPRequests: true, GZIPResponses: true},
+		},
+		me: metricsMock,
+	}
+	req := &adapters.RequestData{Method: http.MethodPost, Uri: server.URL, Body: requestBody, Headers: http.Header{"Content-Type": []string{"application/json"}}}
+
+	httpInfo := bidder.doRequestImpl(context.Background(), req, glog.Warningf)
+
+	assert.NoError(t, httpInfo.err)
+	assert.Equal(t, "gzip", receivedHeaders.Get("Content-Encoding"))
+	assert.Equal(t, "application/json", receivedHeaders.Get("Content-Type"))
+	decompressedBody, err := gzipDecompress(receivedBody)
+	assert.NoError(t, err)
+	assert.Equal(t, requestBody, decompressedBody, "the bidder must receive the compressed request body")
+	if assert.NotNil(t, httpInfo.response) {
+		assert.Equal(t, responseBody, httpInfo.response.Body, "the adapter must receive the decompressed response body")
+		assert.Empty(t, httpInfo.response.Headers.Get("Content-Encoding"))
+	}
+	assert.Equal(t, http.Header{"Content-Type": []string{"application/json"}}, req.Headers, "the adapter's headers must not be modified")
+	metricsMock.AssertExpectations(t)
+}
+
+func TestDoRequestInvalidGzipResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write([]byte(`{"bid":true}`))
+	}))
+	defer server.Close()
+
+	bidder := &bidderAdapter{
+		Bidder:     &goodSingleBidder{},
+		BidderName: openrtb_ext.BidderAppnexus,
+		Client:     server.Client(),
+		config: bidderAdapterConfig{
+			DisableConnMetrics: true,
+			Compression:        config.CompressionInfo{GZIPResponses: true},
+		},
+		me: &metricsConfig.DummyMetricsEngine{},
+	}
+
+	httpInfo := bidder.doRequestImpl(context.Background(), &adapters.RequestData{Method: http.MethodGet, Uri: server.URL}, glog.Warningf)
+
+	assert.IsType(t, &errortypes.BadServerResponse{}, httpInfo.err)
+}
+
+func TestDoRequestTracing(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer collector.Close()
+	var receivedHeaders http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedHeaders = r.Header
+	}))
+	defer server.Close()
+
+	tracer := tracing.NewTracer(config.Tracing{
+		Enabled:            true,
+		Endpoint:           collector.URL,
+		SamplePercent:      100,
+		PropagationHeaders: []string{"traceparent"},
+		BatchSize:          1,
+		FlushIntervalMs:    1000,
+		TimeoutMs:          1000,
+		BufferSize:         10,
+	}, collector.Client())
+	defer tracer.Shutdown()
+	ctx, _ := tracer.StartServerSpan(context.Background(), http.Header{}, "/openrtb2/auction")
+
+	bidder := &bidderAdapter{
+		Bidder:     &goodSingleBidder{},
+		BidderName: openrtb_ext.BidderAppnexus,
+		Client:     server.Client(),
+		config:     bidderAdapterConfig{DisableConnMetrics: true},
+		me:         &metricsConfig.DummyMetricsEngine{},
+	}
+	req := &adapters.RequestData{Method: http.MethodPost, Uri: server.URL, Headers: http.Header{"Content-Type": []string{"application/json"}}}
+
+	httpInfo := bidder.doRequestImpl(ctx, req, glog.Warningf)
+
+	assert.NoError(t, httpInfo.err)
+	auctionTraceParent := tracing.InjectHeaders(ctx, nil).Get("traceparent")
+	bidderTraceParent := receivedHeaders.Get("traceparent")
+	if assert.Len(t, bidderTraceParent, len(auctionTraceParent)) {
+		assert.Equal(t, auctionTraceParent[:36], bidderTraceParent[:36], "the bidder must be called in the trace of the auction")
+		assert.NotEqual(t, auctionTraceParent, bidderTraceParent, "the bidder must be called from a span of its own")
+	}
+	assert.Equal(t, http.Header{"Content-Type": []string{"application/json"}}, req.Headers, "the adapter's headers must not be modified")
+}
+
+func TestRequestBidSeat(t *testing.T) {
+	testCases := []struct {
+		description  string
+		seatConfig   string
+		bidderName   openrtb_ext.BidderName
+		responseSeat string
+		expectedSeat string
+	}{
+		{
+			description:  "Configured seat used when the adapter doesn't set one",
+			seatConfig:   "appnexus-seat",
+			bidderName:   openrtb_ext.BidderAppnexus,
+			expectedSeat: "appnexus-seat",
+		},
+		{
+			description:  "Unconfigured bidder falls back to the bidder code",
+			bidderName:   openrtb_ext.BidderAppnexus,
+			expectedSeat: "appnexus",
+		},
+		{
+			description:  "Adapter provided seat is preserved",
+			seatConfig:   "appnexus-seat",
+			bidderName:   openrtb_ext.BidderAppnexus,
+			responseSeat: "alternate-code",
+			expectedSeat: "alternate-code",
+		},
+		{
+			description:  "Configured seat isn't applied to aliases",
+			seatConfig:   "appnexus-seat",
+			bidderName:   "appnexusAlias",
+			expectedSeat: "appnexusAlias",
+		},
+	}
+
+	server := httptest.NewServer(mockHandler(http.StatusOK, "getBody", `{"bid":true}`))
+	defer server.Close()
+
+	for _, test := range testCases {
+		bidderImpl := &goodSingleBidder{
+			httpRequest: &adapters.RequestData{
+				Method:  "POST",
+				Uri:     server.URL,
+				Headers: http.Header{},
+			},
+			bidResponse: &adapters.BidderResponse{
+				Bids: []*adapters.TypedBid{{Bid: &openrtb2.Bid{ID: "bid", Price: 1}, BidType: openrtb_ext.BidTypeBanner}},
+				Seat: test.responseSeat,
+			},
+		}
+		cfg := &config.Configuration{
+			Adapters: map[string]config.Adapter{
+				"appnexus": {Seat: test.seatConfig},
+			},
+		}
+
+		bidder := adaptBidder(bidderImpl, server.Client(), cfg, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil)
+		currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
+		seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, test.bidderName, 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, false)
+		assert.Empty(t, errs, test.description+":errors")
+
+		e := &exchange{}
+		auc := &auction{}
+		adapterExtra := map[openrtb_ext.BidderName]*seatResponseExtra{test.bidderName: {}}
+		openrtbSeatBid := e.makeSeatBid(seatBid, test.bidderName, adapterExtra, auc, false, nil)
+		assert.Equal(t, test.expectedSeat, openrtbSeatBid.Seat, test.description)
+	}
+}
+
+func TestRequestBidCircuitBreaker(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method:  "POST",
+			Uri:     server.URL,
+			Headers: http.Header{},
+		},
+	}
+	cfg := &config.Configuration{
+		Adapters: map[string]config.Adapter{
+			"appnexus": {CircuitBreaker: config.AdapterCircuitBreaker{Enabled: true, WindowSize: 2, FailureRatePercent: 100, CooldownMs: 60000}},
+		},
+	}
+	bidder := adaptBidder(bidderImpl, server.Client(), cfg, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil)
+	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
+
+	for i := 0; i < 2; i++ {
+		_, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, false)
+		if assert.Len(t, errs, 1) {
+			assert.IsType(t, &errortypes.BadServerResponse{}, errs[0])
+		}
+	}
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true, false)
+	assert.Nil(t, seatBid)
+	assert.Equal(t, []error{&errortypes.BidderCircuitOpen{Message: "The bidder appnexus was not called because its circuit breaker is open after sustained failures"}}, errs)
+	assert.Len(t, errortypes.WarningOnly(errs), 1, "the auction goes on without the bidder")
+	assert.Equal(t, 2, calls, "the bidder must not be called while the circuit breaker is open")
+}
+
 func TestParseDebugInfoTrue(t *testing.T) {
 	debugInfo := &config.DebugInfo{Allow: true}
 	resDebugInfo := parseDebugInfo(debugInfo)
@@ -1726,6 +2117,11 @@ func TestParseDebugInfoIsNil(t *testing.T) {
 	assert.True(t, resDebugInfo, "Debug Allow value should be true")
 }
 
+func TestParseCompressionInfo(t *testing.T) {
+	assert.Equal(t, config.CompressionInfo{}, parseCompressionInfo(nil), "Compression should be disabled")
+	assert.Equal(t, config.CompressionInfo{GZIPRequests: true}, parseCompressionInfo(&config.CompressionInfo{GZIPRequests: true}))
+}
+
 func wrapWithBidderInfo(bidder adapters.Bidder) adapters.Bidder {
 	bidderInfo := config.BidderInfo{
 		Enabled: true,
@@ -1798,6 +2194,39 @@ func (bidder *mixedMultiBidder) MakeBids(internalRequest *openrtb2.BidRequest, e
 	return bidder.bidResponse, []error{errors.New("The bidResponse weren't ideal.")}
 }
 
+func TestGetDealPriority(t *testing.T) {
+	testCases := []struct {
+		description      string
+		givenBid         *adapters.TypedBid
+		expectedPriority int
+	}{
+		{
+			description:      "Set by the adapter",
+			givenBid:         &adapters.TypedBid{Bid: &openrtb2.Bid{DealID: "deal", Ext: json.RawMessage(`{"prebid":{"dealpriority":3}}`)}, DealPriority: 5},
+			expectedPriority: 5,
+		},
+		{
+			description:      "Passed through the bid ext",
+			givenBid:         &adapters.TypedBid{Bid: &openrtb2.Bid{DealID: "deal", Ext: json.RawMessage(`{"prebid":{"dealpriority":3}}`)}},
+			expectedPriority: 3,
+		},
+		{
+			description:      "Not a deal bid",
+			givenBid:         &adapters.TypedBid{Bid: &openrtb2.Bid{Ext: json.RawMessage(`{"prebid":{"dealpriority":3}}`)}},
+			expectedPriority: 0,
+		},
+		{
+			description:      "No deal priority",
+			givenBid:         &adapters.TypedBid{Bid: &openrtb2.Bid{DealID: "deal"}},
+			expectedPriority: 0,
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedPriority, getDealPriority(test.givenBid), test.description)
+	}
+}
+
 type bidRejector struct {
 	httpRequest  *adapters.RequestData
 	httpResponse *adapters.ResponseData
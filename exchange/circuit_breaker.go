@@ -0,0 +1,136 @@
+package exchange
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker tracks the outcome of a bidder's most recent calls and stops calling the bidder once too
+// many of them failed. It's shared by all the auctions calling the bidder, so it must be safe for concurrent use.
+type circuitBreaker struct {
+	windowSize         int
+	failureRatePercent int
+	cooldown           time.Duration
+	now                func() time.Time
+
+	lock sync.Mutex
+	// outcomes is a ring buffer of the most recent calls, true meaning the call failed.
+	outcomes []bool
+	next     int
+	calls    int
+	failures int
+	state    circuitState
+	openedAt time.Time
+	probing  bool
+}
+
+// newCircuitBreaker returns nil if the circuit breaker is disabled. A nil breaker always allows calls.
+func newCircuitBreaker(cfg config.AdapterCircuitBreaker) *circuitBreaker {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &circuitBreaker{
+		windowSize:         cfg.WindowSize,
+		failureRatePercent: cfg.FailureRatePercent,
+		cooldown:           time.Duration(cfg.CooldownMs) * time.Millisecond,
+		now:                time.Now,
+		outcomes:           make([]bool, cfg.WindowSize),
+	}
+}
+
+// allow reports whether the bidder can be called, and whether the call is the probe of a half-open breaker. Once
+// the cooldown of an open breaker is over, a single caller is allowed through to probe the bidder, and everyone
+// else keeps being short-circuited until the probe reports back via record.
+func (cb *circuitBreaker) allow() (allowed bool, probe bool) {
+	if cb == nil {
+		return true, false
+	}
+
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	switch cb.state {
+	case circuitOpen:
+		if cb.now().Sub(cb.openedAt) < cb.cooldown {
+			return false, false
+		}
+		cb.state = circuitHalfOpen
+		cb.probing = true
+		return true, true
+	case circuitHalfOpen:
+		if cb.probing {
+			return false, false
+		}
+		cb.probing = true
+		return true, true
+	default:
+		return true, false
+	}
+}
+
+// record adds the outcome of a call to the bidder. Only the probe decides whether a half-open breaker opens or
+// closes, since the calls allowed before the breaker opened may still report back while it is half-open.
+func (cb *circuitBreaker) record(failed bool, probe bool) {
+	if cb == nil {
+		return
+	}
+
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	switch cb.state {
+	case circuitHalfOpen:
+		if !probe {
+			return
+		}
+		if failed {
+			cb.open()
+		} else {
+			cb.close()
+		}
+	case circuitClosed:
+		if cb.calls == cb.windowSize {
+			if cb.outcomes[cb.next] {
+				cb.failures--
+			}
+		} else {
+			cb.calls++
+		}
+		cb.outcomes[cb.next] = failed
+		cb.next = (cb.next + 1) % cb.windowSize
+		if failed {
+			cb.failures++
+		}
+
+		if cb.calls == cb.windowSize && cb.failures*100 >= cb.failureRatePercent*cb.windowSize {
+			cb.open()
+		}
+	}
+}
+
+func (cb *circuitBreaker) open() {
+	cb.state = circuitOpen
+	cb.openedAt = cb.now()
+	cb.probing = false
+}
+
+func (cb *circuitBreaker) close() {
+	cb.state = circuitClosed
+	cb.probing = false
+	cb.next = 0
+	cb.calls = 0
+	cb.failures = 0
+	for i := range cb.outcomes {
+		cb.outcomes[i] = false
+	}
+}
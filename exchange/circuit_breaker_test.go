@@ -0,0 +1,139 @@
+package exchange
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCircuitBreaker(now *time.Time) *circuitBreaker {
+	cb := newCircuitBreaker(config.AdapterCircuitBreaker{
+		Enabled:            true,
+		WindowSize:         4,
+		FailureRatePercent: 50,
+		CooldownMs:         1000,
+	})
+	cb.now = func() time.Time { return *now }
+	return cb
+}
+
+func isAllowed(cb *circuitBreaker) bool {
+	allowed, _ := cb.allow()
+	return allowed
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	cb := newCircuitBreaker(config.AdapterCircuitBreaker{Enabled: false})
+
+	assert.Nil(t, cb)
+	cb.record(true, false)
+	assert.True(t, isAllowed(cb))
+}
+
+func TestCircuitBreakerOpensOnFailureRate(t *testing.T) {
+	now := time.Now()
+	cb := newTestCircuitBreaker(&now)
+
+	// The window isn't full yet, so the breaker can't open
+	cb.record(true, false)
+	cb.record(true, false)
+	cb.record(true, false)
+	assert.True(t, isAllowed(cb), "partial window")
+
+	// 3 out of the last 4 calls failed
+	cb.record(false, false)
+	assert.False(t, isAllowed(cb), "failure rate reached")
+
+	now = now.Add(999 * time.Millisecond)
+	assert.False(t, isAllowed(cb), "within cooldown")
+}
+
+func TestCircuitBreakerStaysClosedBelowFailureRate(t *testing.T) {
+	now := time.Now()
+	cb := newTestCircuitBreaker(&now)
+
+	// The window slides, so only ever 1 of the last 4 calls failed
+	for i := 0; i < 10; i++ {
+		cb.record(i%4 == 0, false)
+		assert.True(t, isAllowed(cb), "call %d", i)
+	}
+}
+
+func TestCircuitBreakerProbe(t *testing.T) {
+	now := time.Now()
+	cb := newTestCircuitBreaker(&now)
+	for i := 0; i < 4; i++ {
+		cb.record(true, false)
+	}
+	assert.False(t, isAllowed(cb), "open")
+
+	// Once the cooldown is over, a single probe is let through
+	now = now.Add(time.Second)
+	allowed, probe := cb.allow()
+	assert.True(t, allowed, "probe allowed")
+	assert.True(t, probe, "probe marked")
+	assert.False(t, isAllowed(cb), "probe in flight")
+
+	// A failed probe opens the breaker for another cooldown
+	cb.record(true, true)
+	assert.False(t, isAllowed(cb), "probe failed")
+	now = now.Add(time.Second)
+	assert.True(t, isAllowed(cb), "second probe allowed")
+
+	// A successful probe closes the breaker with a fresh window
+	cb.record(false, true)
+	allowed, probe = cb.allow()
+	assert.True(t, allowed, "probe succeeded")
+	assert.False(t, probe, "closed breaker calls aren't probes")
+	cb.record(true, false)
+	cb.record(true, false)
+	cb.record(true, false)
+	assert.True(t, isAllowed(cb), "fresh window")
+}
+
+func TestCircuitBreakerHalfOpenIgnoresStaleCalls(t *testing.T) {
+	now := time.Now()
+	cb := newTestCircuitBreaker(&now)
+	for i := 0; i < 4; i++ {
+		cb.record(true, false)
+	}
+	now = now.Add(time.Second)
+	assert.True(t, isAllowed(cb), "probe allowed")
+
+	// The calls allowed before the breaker opened report back while the probe is in flight
+	cb.record(false, false)
+	assert.False(t, isAllowed(cb), "a stale success doesn't close the breaker")
+	cb.record(true, false)
+	assert.False(t, isAllowed(cb), "a stale failure doesn't reopen the breaker")
+	now = now.Add(time.Second)
+	assert.False(t, isAllowed(cb), "the probe is still in flight")
+
+	cb.record(false, true)
+	assert.True(t, isAllowed(cb), "probe succeeded")
+}
+
+func TestCircuitBreakerConcurrentUse(t *testing.T) {
+	cb := newCircuitBreaker(config.AdapterCircuitBreaker{
+		Enabled:            true,
+		WindowSize:         10,
+		FailureRatePercent: 50,
+		CooldownMs:         60000,
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if allowed, probe := cb.allow(); allowed {
+				cb.record(true, probe)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.False(t, isAllowed(cb))
+}
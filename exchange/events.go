@@ -10,6 +10,7 @@ import (
 	"github.com/prebid/prebid-server/endpoints/events"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/winnotification"
 )
 
 // eventTracking has configuration fields needed for adding event tracking to an auction response
@@ -32,7 +33,7 @@ func getEventTracking(requestExtPrebid *openrtb_ext.ExtRequestPrebid, ts time.Ti
 		auctionTimestampMs: ts.UnixNano() / 1e+6,
 		integration:        "", // TODO: add integration support, see #1428
 		bidderInfos:        bidderInfos,
-		externalURL:        externalURL,
+		externalURL:        account.EventsURL(externalURL),
 	}
 }
 
@@ -50,6 +51,46 @@ func (ev *eventTracking) modifyBidsForEvents(seatBids map[openrtb_ext.BidderName
 	return seatBids
 }
 
+// saveWinNotifications hands the burl, or else the nurl, of the bids of the bidders whose win notifications are fired
+// server side to the notifier, and removes it from the bids so that it isn't fired by the clients too. It runs before
+// the bids are modified for the events, as the nurl of the VAST bids without an AdM is their VAST, not a notification.
+// The event endpoint only takes the events of the accounts which enable them, so the requests can't enable it alone.
+func (ev *eventTracking) saveWinNotifications(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, notifier *winnotification.Notifier, auctionID string) {
+	if !ev.enabledForAccount {
+		return
+	}
+	for bidderName, seatBid := range seatBids {
+		if !notifier.Fires(bidderName.String()) {
+			continue
+		}
+		for _, pbsBid := range seatBid.bids {
+			bid := pbsBid.bid
+			url := &bid.BURL
+			if len(*url) == 0 && len(bid.AdM) > 0 {
+				url = &bid.NURL
+			}
+			if len(*url) == 0 {
+				continue
+			}
+			bidID := bid.ID
+			if len(pbsBid.generatedBidID) > 0 {
+				bidID = pbsBid.generatedBidID
+			}
+			macros := winnotification.Macros{
+				AuctionID: auctionID,
+				BidID:     bid.ID,
+				ImpID:     bid.ImpID,
+				SeatID:    bidderName.String(),
+				AdID:      bid.AdID,
+				Price:     bid.Price,
+				Currency:  seatBid.currency,
+			}
+			notifier.Save(ev.accountID, bidderName.String(), bidID, macros.Expand(*url))
+			*url = ""
+		}
+	}
+}
+
 // isModifyingVASTXMLAllowed returns true if this bidder config allows modifying VAST XML for event tracking
 func (ev *eventTracking) isModifyingVASTXMLAllowed(bidderName string) bool {
 	return ev.bidderInfos[bidderName].ModifyingVastXmlAllowed && (ev.enabledForAccount || ev.enabledForRequest)
@@ -1,10 +1,15 @@
 package exchange
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/winnotification"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -152,3 +157,97 @@ func Test_eventsData_modifyBidJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestGetEventTrackingExternalURL(t *testing.T) {
+	account := &config.Account{ID: "123456", EventsEnabled: true}
+	assert.Equal(t, "http://localhost", getEventTracking(nil, time.Time{}, account, nil, "http://localhost").externalURL, "server")
+
+	account.EventsExternalURL = "http://events.publisher.com"
+	assert.Equal(t, "http://events.publisher.com", getEventTracking(nil, time.Time{}, account, nil, "http://localhost").externalURL, "account")
+}
+
+func TestModifyBidVAST(t *testing.T) {
+	evData := &eventTracking{
+		enabledForAccount:  true,
+		accountID:          "123456",
+		auctionTimestampMs: 1234567890,
+		externalURL:        "http://events.publisher.com",
+	}
+	bid := &pbsOrtbBid{bid: &openrtb2.Bid{
+		ID:  "BID-1",
+		AdM: `<VAST version="3.0"><Ad><InLine><Impression></Impression><Creatives><Creative><Linear><MediaFiles></MediaFiles></Linear></Creative></Creatives></InLine></Ad></VAST>`,
+	}, bidType: openrtb_ext.BidTypeVideo}
+
+	evData.modifyBidVAST(bid, openrtb_ext.BidderOpenx)
+
+	assert.Equal(t, `<VAST version="3.0"><Ad><InLine>`+
+		`<Impression><![CDATA[http://events.publisher.com/event?t=imp&b=BID-1&a=123456&bidder=openx&f=b&ts=1234567890]]></Impression>`+
+		`<Creatives><Creative><Linear>`+
+		`<VideoClicks><ClickTracking><![CDATA[http://events.publisher.com/event?t=click&b=BID-1&a=123456&bidder=openx&f=b&ts=1234567890]]></ClickTracking></VideoClicks>`+
+		`<MediaFiles></MediaFiles></Linear></Creative></Creatives></InLine></Ad></VAST>`, bid.bid.AdM)
+}
+
+func TestSaveWinNotifications(t *testing.T) {
+	fired := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fired <- r.URL.String()
+	}))
+	defer server.Close()
+
+	notifier := winnotification.NewNotifier(config.WinNotifications{
+		Enabled:    true,
+		Bidders:    []string{"appnexus"},
+		TTLSeconds: 60,
+		SizeBytes:  512 * 1024,
+		TimeoutMs:  1000,
+	}, server.Client())
+	seatBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		openrtb_ext.BidderAppnexus: {
+			currency: "EUR",
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb2.Bid{ID: "burl", ImpID: "imp", Price: 1.5, AdM: "<div>", BURL: server.URL + "/burl?p=${AUCTION_PRICE}&c=${AUCTION_CURRENCY}&a=${AUCTION_ID}", NURL: server.URL + "/nurl"}},
+				{bid: &openrtb2.Bid{ID: "nurl", ImpID: "imp", AdM: "<div>", NURL: server.URL + "/nurl?i=${AUCTION_IMP_ID}"}, generatedBidID: "generated"},
+				{bid: &openrtb2.Bid{ID: "vast", ImpID: "imp", NURL: server.URL + "/vast"}, bidType: openrtb_ext.BidTypeVideo},
+			},
+		},
+		openrtb_ext.BidderRubicon: {
+			bids: []*pbsOrtbBid{{bid: &openrtb2.Bid{ID: "rubicon", BURL: server.URL + "/burl"}}},
+		},
+	}
+	evData := &eventTracking{enabledForAccount: true, accountID: "acct"}
+
+	evData.saveWinNotifications(seatBids, notifier, "req")
+
+	appnexusBids := seatBids[openrtb_ext.BidderAppnexus].bids
+	assert.Empty(t, appnexusBids[0].bid.BURL, "The fired burl should be removed")
+	assert.Equal(t, server.URL+"/nurl", appnexusBids[0].bid.NURL, "The nurl should be kept when the burl is fired")
+	assert.Empty(t, appnexusBids[1].bid.NURL, "The fired nurl should be removed")
+	assert.Equal(t, server.URL+"/vast", appnexusBids[2].bid.NURL, "The nurl of the bids without an adm should be kept")
+	assert.Equal(t, server.URL+"/burl", seatBids[openrtb_ext.BidderRubicon].bids[0].bid.BURL, "The urls of the other bidders should be kept")
+
+	assert.True(t, notifier.Notify("acct", "appnexus", "burl"))
+	assert.Equal(t, "/burl?p=1.5&c=EUR&a=req", <-fired)
+	assert.True(t, notifier.Notify("acct", "appnexus", "generated"))
+	assert.Equal(t, "/nurl?i=imp", <-fired)
+	assert.False(t, notifier.Notify("acct", "appnexus", "vast"))
+	assert.False(t, notifier.Notify("acct", "rubicon", "rubicon"))
+}
+
+func TestSaveWinNotificationsEventsDisabled(t *testing.T) {
+	notifier := winnotification.NewNotifier(config.WinNotifications{
+		Enabled:    true,
+		Bidders:    []string{"appnexus"},
+		TTLSeconds: 60,
+		SizeBytes:  512 * 1024,
+		TimeoutMs:  1000,
+	}, http.DefaultClient)
+	seatBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		openrtb_ext.BidderAppnexus: {bids: []*pbsOrtbBid{{bid: &openrtb2.Bid{ID: "bid", BURL: "http://bidder.com/burl"}}}},
+	}
+	evData := &eventTracking{enabledForRequest: true, accountID: "acct"}
+
+	evData.saveWinNotifications(seatBids, notifier, "req")
+
+	assert.Equal(t, "http://bidder.com/burl", seatBids[openrtb_ext.BidderAppnexus].bids[0].bid.BURL)
+	assert.False(t, notifier.Notify("acct", "appnexus", "bid"))
+}
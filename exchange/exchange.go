@@ -15,16 +15,23 @@ import (
 	"time"
 
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/biddercapture"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/firstpartydata"
+	"github.com/prebid/prebid-server/floors"
 	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/geolocation"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/prebid_cache_client"
 	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/prebid/prebid-server/tracing"
+	"github.com/prebid/prebid-server/trafficshaping"
 	"github.com/prebid/prebid-server/usersync"
 	"github.com/prebid/prebid-server/util/maputil"
+	"github.com/prebid/prebid-server/winnotification"
 
 	"github.com/buger/jsonparser"
 	"github.com/gofrs/uuid"
@@ -38,6 +45,8 @@ const DebugContextKey = ContextKey("debugInfo")
 
 type extCacheInstructions struct {
 	cacheBids, cacheVAST, returnCreative bool
+	// cacheKey is the cache key given by the publisher, if any
+	cacheKey string
 }
 
 // Exchange runs Auctions. Implementations must be threadsafe, and will be shared across many goroutines.
@@ -66,6 +75,12 @@ type exchange struct {
 	privacyConfig     config.Privacy
 	categoriesFetcher stored_requests.CategoryFetcher
 	bidIDGenerator    BidIDGenerator
+	floorsFetcher     *floors.Fetcher
+	tmaxAdjustments   config.TmaxAdjustments
+	bidderCapturer    *biddercapture.Capturer
+	firstPartyData    config.FirstPartyData
+	geoResolver       geolocation.Resolver
+	winNotifier       *winnotification.Notifier
 }
 
 // Container to pass out response ext data from the GetAllBids goroutines back into the main thread
@@ -76,6 +91,8 @@ type seatResponseExtra struct {
 	// httpCalls is the list of debugging info. It should only be populated if the request.test == 1.
 	// This will become response.ext.debug.httpcalls.{bidder} on the final Response.
 	HttpCalls []*openrtb_ext.ExtHttpCall
+	// NonBids lists the bids dropped by the exchange, for response.ext.seatnonbid
+	NonBids []openrtb_ext.NonBid
 }
 
 type bidResponseWrapper struct {
@@ -112,7 +129,7 @@ func (randomDeduplicateBidBooleanGenerator) Generate() bool {
 	return rand.Intn(100) < 50
 }
 
-func NewExchange(adapters map[openrtb_ext.BidderName]adaptedBidder, cache prebid_cache_client.Client, cfg *config.Configuration, syncersByBidder map[string]usersync.Syncer, metricsEngine metrics.MetricsEngine, infos config.BidderInfos, gDPR gdpr.Permissions, currencyConverter *currency.RateConverter, categoriesFetcher stored_requests.CategoryFetcher) Exchange {
+func NewExchange(adapters map[openrtb_ext.BidderName]adaptedBidder, cache prebid_cache_client.Client, cfg *config.Configuration, syncersByBidder map[string]usersync.Syncer, metricsEngine metrics.MetricsEngine, infos config.BidderInfos, gDPR gdpr.Permissions, currencyConverter *currency.RateConverter, categoriesFetcher stored_requests.CategoryFetcher, floorsFetcher *floors.Fetcher, bidderCapturer *biddercapture.Capturer, geoResolver geolocation.Resolver, winNotifier *winnotification.Notifier) Exchange {
 	bidderToSyncerKey := map[string]string{}
 	for bidder, syncer := range syncersByBidder {
 		bidderToSyncerKey[bidder] = syncer.Key()
@@ -131,16 +148,25 @@ func NewExchange(adapters map[openrtb_ext.BidderName]adaptedBidder, cache prebid
 		cacheTime:         time.Duration(cfg.CacheURL.ExpectedTimeMillis) * time.Millisecond,
 		categoriesFetcher: categoriesFetcher,
 		currencyConverter: currencyConverter,
-		externalURL:       cfg.ExternalURL,
+		externalURL:       cfg.EventsURL(),
+		floorsFetcher:     floorsFetcher,
 		gDPR:              gDPR,
 		me:                metricsEngine,
 		gdprDefaultValue:  gdprDefaultValue,
 		privacyConfig: config.Privacy{
-			CCPA: cfg.CCPA,
-			GDPR: cfg.GDPR,
-			LMT:  cfg.LMT,
+			CCPA:          cfg.CCPA,
+			GDPR:          cfg.GDPR,
+			LMT:           cfg.LMT,
+			LGPD:          cfg.LGPD,
+			GPP:           cfg.GPP,
+			ScrubProfiles: cfg.ScrubProfiles,
 		},
-		bidIDGenerator: &bidIDGenerator{cfg.GenerateBidID},
+		bidIDGenerator:  &bidIDGenerator{cfg.GenerateBidID},
+		tmaxAdjustments: cfg.TmaxAdjustments,
+		firstPartyData:  cfg.FirstPartyData,
+		bidderCapturer:  bidderCapturer,
+		geoResolver:     geoResolver,
+		winNotifier:     winNotifier,
 	}
 }
 
@@ -160,10 +186,24 @@ type AuctionRequest struct {
 	Warnings                   []error
 	GlobalPrivacyControlHeader string
 	ImpExtInfoMap              map[string]ImpExtInfo
+	// StoredAuctionResponses replace the bids of every bidder on an imp, by imp id
+	StoredAuctionResponses map[string]StoredResponse
+	// StoredBidResponses replace the bids of a bidder on an imp, by imp id and bidder
+	StoredBidResponses map[string]map[openrtb_ext.BidderName]StoredResponse
+	// SeatNonBid receives the bids dropped by the auction when not nil, whether or not the request asked for them, so
+	// that they can be logged by the analytics modules
+	SeatNonBid *[]openrtb_ext.SeatNonBid
 
 	// LegacyLabels is included here for temporary compatability with cleanOpenRTBRequests
 	// in HoldAuction until we get to factoring it away. Do not use for anything new.
 	LegacyLabels metrics.Labels
+
+	// firstPartyData applies the first party data of the request to each bidder as the request is split
+	firstPartyData *firstpartydata.Resolver
+	// geo is the location of the device looked up from its IP address, when the request has no device.geo.country
+	geo *geolocation.GeoInfo
+	// blockedNonBids are the imps of the bidders the privacy policies kept the request away from, by bidder
+	blockedNonBids map[openrtb_ext.BidderName][]openrtb_ext.NonBid
 }
 
 // BidderRequest holds the bidder specific request and all other
@@ -173,9 +213,16 @@ type BidderRequest struct {
 	BidderName     openrtb_ext.BidderName
 	BidderCoreName openrtb_ext.BidderName
 	BidderLabels   metrics.AdapterLabels
+	// AliasEndpoint is the endpoint the alias overrides of the request call the bidder on, if any
+	AliasEndpoint string
+	// capture is set when the calls of the bidder are sampled for capture
+	capture *bidderCapture
 }
 
 func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *DebugLog) (*openrtb2.BidResponse, error) {
+	ctx, span := tracing.StartSpan(ctx, "exchange.auction", tracing.SpanKindInternal)
+	defer span.End()
+
 	var err error
 	requestExt, err := extractBidRequestExt(r.BidRequest)
 	if err != nil {
@@ -186,6 +233,7 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 	targData := getExtTargetData(requestExt, &cacheInstructions)
 	if targData != nil {
 		_, targData.cacheHost, targData.cachePath = e.cache.GetExtCacheData()
+		targData.applyAccount(&r.Account)
 	}
 
 	if debugLog == nil {
@@ -205,12 +253,51 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 
 	recordImpMetrics(r.BidRequest, e.me)
 
+	// The devices without a country are located from their IP address, to tell which privacy regulations apply
+	r.geo = e.locateDevice(ctx, r.BidRequest)
+
 	// Make our best guess if GDPR applies
-	gdprDefaultValue := e.parseGDPRDefaultValue(r.BidRequest)
+	gdprDefaultValue := e.parseGDPRDefaultValue(r.BidRequest, r.geo)
+
+	// Transaction ids are filled in before the request is split, so that every bidder gets the same ones
+	if r.Account.GenerateTIDs {
+		if err := fillTransactionIDs(r.BidRequest); err != nil {
+			return nil, err
+		}
+	}
+
+	// Get currency rates conversions for the auction
+	conversions := e.getAuctionCurrencyRates(requestExt.Prebid.CurrencyConversions)
+
+	// Fetched floors are merged before the request is split, so that every bidder is sent the same floors
+	if rules := e.floorsFetcher.Fetch(r.Account.PriceFloors.Fetch); rules != nil {
+		applyFetchedFloors(r.BidRequest, rules, conversions)
+	}
+
+	// The Sec-GPC header is enforced as the GPC signal of the request
+	if err := applyGPCHeader(r.BidRequest, r.GlobalPrivacyControlHeader); err != nil {
+		return nil, err
+	}
+
+	// First party data is read once, and applied to each bidder as the request is split
+	fpdResolver, fpdWarnings := firstpartydata.NewResolver(r.BidRequest, requestExt, e.firstPartyData)
+	r.Warnings = append(r.Warnings, fpdWarnings...)
+	r.firstPartyData = fpdResolver
 
-	// Slice of BidRequests, each a copy of the original cleaned to only contain bidder data for the named bidder
+	// Slice of BidRequests, each a copy of the original cleaned to only contain bidder data for the named bidder. The
+	// imps of the bidders blocked by the privacy policies are kept for the seat non bids.
+	r.blockedNonBids = make(map[openrtb_ext.BidderName][]openrtb_ext.NonBid)
 	bidderRequests, privacyLabels, errs := cleanOpenRTBRequests(ctx, r, requestExt, e.bidderToSyncerKey, e.gDPR, e.me, gdprDefaultValue, e.privacyConfig, &r.Account)
 
+	// Traffic shaping keeps the requests outside of the share of a bidder away from it
+	trafficShaping := trafficshaping.Assign(r.BidRequest.ID, r.Account.TrafficShaping)
+	bidderRequests = removeShapedBidders(bidderRequests, trafficShaping)
+	// The imps answered by stored responses are not sent to the bidders the responses are stored for
+	bidderRequests, storedBidImps := removeStoredResponseImps(bidderRequests, r.StoredAuctionResponses, r.StoredBidResponses)
+	// The bidders not supporting the multi-format imps only get their preferred media type
+	applyPreferredMediaTypes(bidderRequests, requestExt, &r.Account, e.bidderInfo, e.me)
+	sampleBidderCaptures(bidderRequests, e.bidderCapturer, r.Account)
+
 	e.me.RecordRequestPrivacy(privacyLabels)
 
 	// List of bidders we have requests for.
@@ -221,11 +308,55 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 	auctionCtx, cancel := e.makeAuctionContext(ctx, cacheInstructions.cacheBids)
 	defer cancel()
 
-	// Get currency rates conversions for the auction
-	conversions := e.getAuctionCurrencyRates(requestExt.Prebid.CurrencyConversions)
-
 	adapterBids, adapterExtra, anyBidsReturned := e.getAllBids(auctionCtx, bidderRequests, bidAdjustmentFactors, conversions, r.Account.DebugAllow, r.GlobalPrivacyControlHeader, debugLog.DebugOverride)
 
+	coreBidderNames := make(map[openrtb_ext.BidderName]openrtb_ext.BidderName, len(bidderRequests))
+	for _, bidderRequest := range bidderRequests {
+		coreBidderNames[bidderRequest.BidderName] = bidderRequest.BidderCoreName
+	}
+
+	// The stored bids run through the rest of the auction just like the live ones
+	storedBidders, storedBidsFound := mergeStoredResponses(adapterBids, adapterExtra, r.BidRequest, r.StoredAuctionResponses, r.StoredBidResponses, storedBidImps)
+	for _, bidder := range storedBidders {
+		liveAdapters = append(liveAdapters, bidder)
+		if coreBidder, ok := requestExt.Prebid.Aliases[bidder.String()]; ok {
+			coreBidderNames[bidder] = openrtb_ext.BidderName(coreBidder)
+		}
+	}
+	anyBidsReturned = anyBidsReturned || storedBidsFound
+
+	if anyBidsReturned {
+		anyBidsReturned = validateBids(r.BidRequest, adapterBids, adapterExtra, coreBidderNames, r.Account.Validations, e.me)
+	}
+
+	// Bid adjustments which depend on each bid are applied before anything compares bid prices
+	if anyBidsReturned && (bidAdjustmentFactors != nil || requestExt.Prebid.BidAdjustments != nil) {
+		applyBidAdjustments(adapterBids, adapterExtra, bidAdjustmentFactors, requestExt.Prebid.BidAdjustments, conversions)
+	}
+
+	// Deals only auctions ignore the open market entirely, so there is no fallback if no deal bids exist.
+	if anyBidsReturned && (r.Account.DealsOnly || requestExt.Prebid.DealsOnly) {
+		anyBidsReturned = removeNonDealBids(adapterBids)
+	}
+
+	// Private auctions are restricted to the deals listed on the imp, so open market bids must never win them.
+	privateAuctionDeals, privateAuctionWarnings := getPrivateAuctionDeals(r.BidRequest)
+	r.Warnings = append(r.Warnings, privateAuctionWarnings...)
+	if anyBidsReturned && len(privateAuctionDeals) > 0 {
+		anyBidsReturned = removePrivateAuctionIneligibleBids(adapterBids, adapterExtra, privateAuctionDeals)
+	}
+
+	if anyBidsReturned && r.Account.PriceFloors.EnforceFloors {
+		anyBidsReturned = enforceFloors(r.BidRequest, adapterBids, adapterExtra, coreBidderNames, conversions, r.Account.PriceFloors, e.me)
+	}
+
+	// Multibid bidders may return several bids per imp, the best priced of which are all targeted and cached
+	multiBids, multiBidWarnings := getMultiBid(requestExt.Prebid.MultiBid)
+	r.Warnings = append(r.Warnings, multiBidWarnings...)
+	if anyBidsReturned && len(multiBids) > 0 {
+		applyMultiBid(adapterBids, adapterExtra, multiBids)
+	}
+
 	var auc *auction
 	var cacheErrs []error
 	var bidResponseExt *openrtb_ext.ExtBidResponse
@@ -235,7 +366,9 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 		//If includebrandcategory is present in ext then CE feature is on.
 		if requestExt.Prebid.Targeting != nil && requestExt.Prebid.Targeting.IncludeBrandCategory != nil {
 			var rejections []string
-			bidCategory, adapterBids, rejections, err = applyCategoryMapping(ctx, requestExt, adapterBids, e.categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+			adPods, podErrs := getAdPods(r.BidRequest)
+			errs = append(errs, podErrs...)
+			bidCategory, adapterBids, rejections, err = applyCategoryMapping(ctx, requestExt, adapterBids, adPods, e.categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
 			if err != nil {
 				return nil, fmt.Errorf("Error in category mapping : %s", err.Error())
 			}
@@ -256,12 +389,13 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 		}
 
 		evTracking := getEventTracking(&requestExt.Prebid, r.StartTime, &r.Account, e.bidderInfo, e.externalURL)
+		evTracking.saveWinNotifications(adapterBids, e.winNotifier, r.BidRequest.ID)
 		adapterBids = evTracking.modifyBidsForEvents(adapterBids)
 
 		if targData != nil {
 			// A non-nil auction is only needed if targeting is active. (It is used below this block to extract cache keys)
-			auc = newAuction(adapterBids, len(r.BidRequest.Imp), targData.preferDeals)
-			auc.setRoundedPrices(targData.priceGranularity)
+			auc = newAuction(adapterBids, len(r.BidRequest.Imp), targData.preferDeals || r.Account.PreferDeals)
+			auc.setRoundedPrices(targData)
 
 			if requestExt.Prebid.SupportDeals {
 				dealErrs := applyDealSupport(r.BidRequest, auc, bidCategory)
@@ -317,11 +451,136 @@ func (e *exchange) HoldAuction(ctx context.Context, r AuctionRequest, debugLog *
 		bidResponseExt.Warnings[openrtb_ext.BidderReservedGeneral] = append(bidResponseExt.Warnings[openrtb_ext.BidderReservedGeneral], generalWarning)
 	}
 
+	seatNonBids := makeSeatNonBids(adapterExtra, r.blockedNonBids)
+	if r.SeatNonBid != nil {
+		*r.SeatNonBid = seatNonBids
+	}
+	if requestExt.Prebid.ReturnAllBidStatus {
+		bidResponseExt.SeatNonBid = seatNonBids
+	}
+
+	if passthrough := makeResponsePassthrough(requestExt.Prebid.Passthrough, trafficShaping); passthrough != nil {
+		if bidResponseExt.Prebid == nil {
+			bidResponseExt.Prebid = &openrtb_ext.ExtResponsePrebid{}
+		}
+		bidResponseExt.Prebid.Passthrough = passthrough
+	}
+
 	// Build the response
 	return e.buildBidResponse(ctx, liveAdapters, adapterBids, r.BidRequest, adapterExtra, auc, bidResponseExt, cacheInstructions.returnCreative, r.ImpExtInfoMap, errs)
 }
 
-func (e *exchange) parseGDPRDefaultValue(bidRequest *openrtb2.BidRequest) gdpr.Signal {
+// removeNonDealBids drops all bids without a deal ID and removes any seat left without bids.
+// It returns true if at least one deal bid remains.
+func removeNonDealBids(adapterBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid) bool {
+	dealBidsFound := false
+	for bidderName, seatBid := range adapterBids {
+		dealBids := make([]*pbsOrtbBid, 0, len(seatBid.bids))
+		for _, bid := range seatBid.bids {
+			if bid.bid != nil && len(bid.bid.DealID) > 0 {
+				dealBids = append(dealBids, bid)
+			}
+		}
+
+		if len(dealBids) == 0 {
+			delete(adapterBids, bidderName)
+			continue
+		}
+		seatBid.bids = dealBids
+		dealBidsFound = true
+	}
+	return dealBidsFound
+}
+
+// getPrivateAuctionDeals returns the IDs of the deals eligible to win each imp with pmp.private_auction = 1.
+// An imp in a private auction without any listed deals maps to an empty set, making every bid on it ineligible.
+func getPrivateAuctionDeals(bidRequest *openrtb2.BidRequest) (map[string]map[string]struct{}, []error) {
+	var privateAuctionDeals map[string]map[string]struct{}
+	var warnings []error
+
+	for _, imp := range bidRequest.Imp {
+		if imp.PMP == nil || imp.PMP.PrivateAuction != 1 {
+			continue
+		}
+		if privateAuctionDeals == nil {
+			privateAuctionDeals = make(map[string]map[string]struct{})
+		}
+
+		deals := make(map[string]struct{}, len(imp.PMP.Deals))
+		for _, deal := range imp.PMP.Deals {
+			deals[deal.ID] = struct{}{}
+		}
+		privateAuctionDeals[imp.ID] = deals
+
+		if len(deals) == 0 {
+			warnings = append(warnings, &errortypes.Warning{
+				Message:     fmt.Sprintf("imp %s is a private auction without any deals, no bids are eligible", imp.ID),
+				WarningCode: errortypes.PrivateAuctionWithoutDealsWarningCode,
+			})
+		}
+	}
+	return privateAuctionDeals, warnings
+}
+
+// removePrivateAuctionIneligibleBids drops the bids on private auction imps which don't match one of the imp's deals,
+// recording a warning for the bidder of each dropped bid, and removes any seat left without bids.
+// It returns true if at least one bid remains.
+func removePrivateAuctionIneligibleBids(adapterBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, adapterExtra map[openrtb_ext.BidderName]*seatResponseExtra, privateAuctionDeals map[string]map[string]struct{}) bool {
+	bidsFound := false
+	for bidderName, seatBid := range adapterBids {
+		eligibleBids := make([]*pbsOrtbBid, 0, len(seatBid.bids))
+		for _, bid := range seatBid.bids {
+			if bid.bid == nil {
+				continue
+			}
+			if deals, isPrivate := privateAuctionDeals[bid.bid.ImpID]; isPrivate {
+				if _, isEligible := deals[bid.bid.DealID]; !isEligible {
+					if extra, ok := adapterExtra[bidderName]; ok {
+						extra.Warnings = append(extra.Warnings, openrtb_ext.ExtBidderMessage{
+							Code:    errortypes.PrivateAuctionBidRejectedWarningCode,
+							Message: fmt.Sprintf("bid %s dropped because imp %s is a private auction and deal %q is not one of its deals", bid.bid.ID, bid.bid.ImpID, bid.bid.DealID),
+						})
+					}
+					continue
+				}
+			}
+			eligibleBids = append(eligibleBids, bid)
+		}
+
+		if len(eligibleBids) == 0 {
+			delete(adapterBids, bidderName)
+			continue
+		}
+		seatBid.bids = eligibleBids
+		bidsFound = true
+	}
+	return bidsFound
+}
+
+// locateDevice looks up the location of the device of a request without a device.geo.country. The failed lookups are
+// only counted by the metrics, the request is then handled as if its location was unknown.
+func (e *exchange) locateDevice(ctx context.Context, bidRequest *openrtb2.BidRequest) *geolocation.GeoInfo {
+	device := bidRequest.Device
+	if e.geoResolver == nil || device == nil || (device.Geo != nil && device.Geo.Country != "") {
+		return nil
+	}
+
+	ip := device.IP
+	if ip == "" {
+		ip = device.IPv6
+	}
+	if ip == "" {
+		return nil
+	}
+
+	geo, err := e.geoResolver.Lookup(ctx, ip)
+	if err != nil {
+		return nil
+	}
+	return geo
+}
+
+func (e *exchange) parseGDPRDefaultValue(bidRequest *openrtb2.BidRequest, located *geolocation.GeoInfo) gdpr.Signal {
 	gdprDefaultValue := e.gdprDefaultValue
 	var geo *openrtb2.Geo = nil
 
@@ -330,6 +589,9 @@ func (e *exchange) parseGDPRDefaultValue(bidRequest *openrtb2.BidRequest) gdpr.S
 	} else if bidRequest.Device != nil && bidRequest.Device.Geo != nil {
 		geo = bidRequest.Device.Geo
 	}
+	if (geo == nil || geo.Country == "") && located != nil {
+		geo = &openrtb2.Geo{Country: located.Country}
+	}
 	if geo != nil {
 		// If we have a country set, and it is on the list, we assume GDPR applies if not set on the request.
 		// Otherwise we assume it does not apply as long as it appears "valid" (is 3 characters long).
@@ -431,7 +693,7 @@ func (e *exchange) makeAuctionContext(ctx context.Context, needsCache bool) (auc
 func (e *exchange) getAllBids(
 	ctx context.Context,
 	bidderRequests []BidderRequest,
-	bidAdjustments map[string]float64,
+	bidAdjustments *openrtb_ext.ExtBidAdjustmentFactors,
 	conversions currency.Conversions,
 	accountDebugAllowed bool,
 	globalPrivacyControlHeader string,
@@ -460,15 +722,23 @@ func (e *exchange) getAllBids(
 			}()
 			start := time.Now()
 
-			adjustmentFactor := 1.0
-			if givenAdjustment, ok := bidAdjustments[string(bidderRequest.BidderName)]; ok {
-				adjustmentFactor = givenAdjustment
-			}
+			adjustmentFactor := getBidderAdjustmentFactor(bidAdjustments, bidderRequest.BidderName)
 			reqInfo := adapters.NewExtraRequestInfo(conversions)
 			reqInfo.PbsEntryPoint = bidderRequest.BidderLabels.RType
 			reqInfo.GlobalPrivacyControlHeader = globalPrivacyControlHeader
 
-			bids, err := e.adapterMap[bidderRequest.BidderCoreName].requestBid(ctx, bidderRequest.BidRequest, bidderRequest.BidderName, adjustmentFactor, conversions, &reqInfo, accountDebugAllowed, headerDebugAllowed)
+			var bids *pbsOrtbSeatBid
+			var err []error
+			bidderCtx, cancel, tmaxErr := makeBidderContext(ctx, bidderRequest.BidRequest, e.tmaxAdjustments)
+			defer cancel()
+			bidder, bidderErr := e.getBidder(bidderRequest)
+			if tmaxErr != nil {
+				err = []error{tmaxErr}
+			} else if bidderErr != nil {
+				err = []error{bidderErr}
+			} else {
+				bids, err = bidder.requestBid(withBidderCapture(bidderCtx, bidderRequest.capture), bidderRequest.BidRequest, bidderRequest.BidderName, adjustmentFactor, conversions, &reqInfo, accountDebugAllowed, headerDebugAllowed)
+			}
 
 			// Add in time reporting
 			elapsed := time.Since(start)
@@ -479,11 +749,17 @@ func (e *exchange) getAllBids(
 			if bids != nil {
 				ae.HttpCalls = bids.httpCalls
 			}
+			if bidderRequest.BidRequest != nil {
+				ae.NonBids = makeErrorNonBids(bidderRequest.BidRequest.Imp, bids, err)
+			}
 
 			// Timing statistics
 			e.me.RecordAdapterTime(bidderRequest.BidderLabels, time.Since(start))
 			bidderRequest.BidderLabels.AdapterBids = bidsToMetric(brw.adapterBids)
 			bidderRequest.BidderLabels.AdapterErrors = errorsToMetric(err)
+			if bidderRequest.BidderName != bidderRequest.BidderCoreName {
+				e.me.RecordAdapterAliasRequest(bidderRequest.BidderCoreName, bidderRequest.BidderName.String(), bidderRequest.BidderLabels.AdapterBids)
+			}
 			// Append any bid validation errors to the error list
 			ae.Errors = errsToBidderErrors(err)
 			ae.Warnings = errsToBidderWarnings(err)
@@ -644,7 +920,7 @@ func encodeBidResponseExt(bidResponseExt *openrtb_ext.ExtBidResponse) ([]byte, e
 	return buffer.Bytes(), err
 }
 
-func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtRequest, seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, categoriesFetcher stored_requests.CategoryFetcher, targData *targetData, booleanGenerator deduplicateChanceGenerator) (map[string]string, map[openrtb_ext.BidderName]*pbsOrtbSeatBid, []string, error) {
+func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtRequest, seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, adPods map[string]*adPod, categoriesFetcher stored_requests.CategoryFetcher, targData *targetData, booleanGenerator deduplicateChanceGenerator) (map[string]string, map[openrtb_ext.BidderName]*pbsOrtbSeatBid, []string, error) {
 	res := make(map[string]string)
 
 	type bidDedupe struct {
@@ -700,6 +976,14 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 				duration = bid.bidVideo.Duration
 				category = bid.bidVideo.PrimaryCategory
 			}
+			pod := adPods[bid.bid.ImpID]
+			if pod != nil {
+				if reason := pod.rejectDuration(duration); reason != "" {
+					bidsToRemove = append(bidsToRemove, bidInd)
+					rejections = updateRejections(rejections, bidID, reason)
+					continue
+				}
+			}
 			if brandCatExt.WithCategory && category == "" {
 				bidIabCat := bid.bid.Cat
 				if len(bidIabCat) != 1 {
@@ -728,10 +1012,11 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 
 			// TODO: consider should we remove bids with zero duration here?
 
-			pb = GetPriceBucket(bid.bid.Price, targData.priceGranularity)
+			pb = GetPriceBucket(bid.bid.Price, targData.bidPriceGranularity(bidderName, bid.bidType))
 
 			newDur := duration
-			if len(requestExt.Prebid.Targeting.DurationRangeSec) > 0 {
+			// The ads of pods with required durations already have one of the exact durations the pod accepts
+			if len(requestExt.Prebid.Targeting.DurationRangeSec) > 0 && (pod == nil || len(pod.requiredDurations) == 0) {
 				durationRange := requestExt.Prebid.Targeting.DurationRangeSec
 				sort.Ints(durationRange)
 				//if the bid is above the range of the listed durations (and outside the buffer), reject the bid
@@ -757,6 +1042,10 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 				categoryDuration = fmt.Sprintf("%s_%ds", pb, newDur)
 				dupeKey = categoryDuration
 			}
+			if pod != nil {
+				// Bids only compete with the other bids of their pod
+				dupeKey = fmt.Sprintf("%s|%s", pod.id, dupeKey)
+			}
 
 			if appendBidderNames {
 				categoryDuration = fmt.Sprintf("%s_%s", categoryDuration, bidderName.String())
@@ -832,9 +1121,66 @@ func applyCategoryMapping(ctx context.Context, requestExt *openrtb_ext.ExtReques
 		seatBids[seatBidInd].bids = nil
 	}
 
+	if brandCatExt.ExcludeAdvertiserDomains {
+		rejections = applyAdvertiserDomainExclusion(seatBids, res, rejections)
+	}
+
 	return res, seatBids, rejections, nil
 }
 
+// applyAdvertiserDomainExclusion removes the bids sharing an advertiser domain with a higher bid across the whole
+// request, pods included. The bids left by the category deduplication are ranked by price, then by bidder and bid id
+// so that the outcome doesn't depend on the order of the seat bids.
+func applyAdvertiserDomainExclusion(seatBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, res map[string]string, rejections []string) []string {
+	type rankedBid struct {
+		bidderName openrtb_ext.BidderName
+		bid        *pbsOrtbBid
+	}
+
+	ranked := make([]rankedBid, 0)
+	for bidderName, seatBid := range seatBids {
+		for _, bid := range seatBid.bids {
+			ranked = append(ranked, rankedBid{bidderName: bidderName, bid: bid})
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].bid.bid.Price != ranked[j].bid.bid.Price {
+			return ranked[i].bid.bid.Price > ranked[j].bid.bid.Price
+		}
+		if ranked[i].bidderName != ranked[j].bidderName {
+			return ranked[i].bidderName < ranked[j].bidderName
+		}
+		return ranked[i].bid.bid.ID < ranked[j].bid.bid.ID
+	})
+
+	takenDomains := make(map[string]bool)
+	for _, candidate := range ranked {
+		excluded := false
+		for _, domain := range candidate.bid.bid.ADomain {
+			if takenDomains[strings.ToLower(domain)] {
+				excluded = true
+				break
+			}
+		}
+		if excluded {
+			removeBidById(seatBids[candidate.bidderName], candidate.bid.bid.ID)
+			delete(res, candidate.bid.bid.ID)
+			rejections = updateRejections(rejections, candidate.bid.bid.ID, "Bid was deduplicated by advertiser domain")
+			continue
+		}
+		for _, domain := range candidate.bid.bid.ADomain {
+			takenDomains[strings.ToLower(domain)] = true
+		}
+	}
+
+	for _, seatBid := range seatBids {
+		if len(seatBid.bids) == 0 {
+			seatBid.bids = nil
+		}
+	}
+	return rejections
+}
+
 func removeBidById(seatBid *pbsOrtbSeatBid, bidID string) {
 	//Find index of bid to remove
 	dupeBidIndex := -1
@@ -885,7 +1231,7 @@ func (e *exchange) makeExtBidResponse(adapterBids map[openrtb_ext.BidderName]*pb
 		}
 	}
 	if !r.StartTime.IsZero() {
-		// auctiontimestamp is the only response.ext.prebid attribute we may emit
+		// auctiontimestamp and passthrough are the only response.ext.prebid attributes we may emit
 		bidResponseExt.Prebid = &openrtb_ext.ExtResponsePrebid{
 			AuctionTimestamp: r.StartTime.UnixNano() / 1e+6,
 		}
@@ -920,6 +1266,9 @@ func (e *exchange) makeSeatBid(adapterBid *pbsOrtbSeatBid, adapter openrtb_ext.B
 		Seat:  adapter.String(),
 		Group: 0, // Prebid cannot support roadblocking
 	}
+	if adapterBid.seat != "" {
+		seatBid.Seat = adapterBid.seat
+	}
 
 	var errList []error
 	seatBid.Bid, errList = e.makeBid(adapterBid.bids, auc, returnCreative, impExtInfoMap)
@@ -944,6 +1293,7 @@ func (e *exchange) makeBid(bids []*pbsOrtbBid, auc *auction, returnCreative bool
 			Meta:              bid.bidMeta,
 			Video:             bid.bidVideo,
 			BidId:             bid.generatedBidID,
+			StoredResponse:    bid.storedResponse,
 		}
 
 		if cacheInfo, found := e.getBidCacheInfo(bid, auc); found {
@@ -21,6 +21,7 @@ import (
 	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/geolocation"
 	"github.com/prebid/prebid-server/metrics"
 	metricsConf "github.com/prebid/prebid-server/metrics/config"
 	metricsConfig "github.com/prebid/prebid-server/metrics/config"
@@ -66,7 +67,7 @@ func TestNewExchange(t *testing.T) {
 	}
 
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	e := NewExchange(adapters, nil, cfg, map[string]usersync.Syncer{}, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}).(*exchange)
+	e := NewExchange(adapters, nil, cfg, map[string]usersync.Syncer{}, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}, nil, nil, nil, nil).(*exchange)
 	for _, bidderName := range knownAdapters {
 		if _, ok := e.adapterMap[bidderName]; !ok {
 			t.Errorf("NewExchange produced an Exchange without bidder %s", bidderName)
@@ -114,7 +115,7 @@ func TestCharacterEscape(t *testing.T) {
 	}
 
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	e := NewExchange(adapters, nil, cfg, map[string]usersync.Syncer{}, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}).(*exchange)
+	e := NewExchange(adapters, nil, cfg, map[string]usersync.Syncer{}, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}, nil, nil, nil, nil).(*exchange)
 
 	// 	3) Build all the parameters e.buildBidResponse(ctx.Background(), liveA... ) needs
 	//liveAdapters []openrtb_ext.BidderName,
@@ -319,7 +320,7 @@ func TestDebugBehaviour(t *testing.T) {
 	for _, test := range testCases {
 
 		e.adapterMap = map[openrtb_ext.BidderName]adaptedBidder{
-			openrtb_ext.BidderAppnexus: adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, &config.DebugInfo{Allow: test.debugData.bidderLevelDebugAllowed}),
+			openrtb_ext.BidderAppnexus: adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, &config.DebugInfo{Allow: test.debugData.bidderLevelDebugAllowed}, nil),
 		}
 
 		//request level debug key
@@ -420,6 +421,117 @@ func TestDebugBehaviour(t *testing.T) {
 	}
 }
 
+func TestHoldAuctionTransactionIDs(t *testing.T) {
+	testCases := []struct {
+		description       string
+		generateTIDs      bool
+		source            *openrtb2.Source
+		impExt            string
+		expectedSourceTID string
+		expectedImpTID    string
+		expectGenerated   bool
+	}{
+		{
+			description:     "Missing ids are generated",
+			generateTIDs:    true,
+			impExt:          `{"appnexus":{"placementid":1},"telaria":{"seatCode":"1"}}`,
+			expectGenerated: true,
+		},
+		{
+			description:       "Existing ids are preserved",
+			generateTIDs:      true,
+			source:            &openrtb2.Source{TID: "source-tid"},
+			impExt:            `{"appnexus":{"placementid":1},"telaria":{"seatCode":"1"},"tid":"imp-tid"}`,
+			expectedSourceTID: "source-tid",
+			expectedImpTID:    "imp-tid",
+		},
+		{
+			description:  "Ids aren't generated when the account disables it",
+			generateTIDs: false,
+			impExt:       `{"appnexus":{"placementid":1},"telaria":{"seatCode":"1"}}`,
+		},
+	}
+
+	noBidServer := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(204)
+	}
+	server := httptest.NewServer(http.HandlerFunc(noBidServer))
+	defer server.Close()
+
+	categoriesFetcher, err := newCategoryFetcher("./test/category-mapping")
+	if err != nil {
+		t.Errorf("Failed to create a category Fetcher: %v", err)
+	}
+
+	e := new(exchange)
+	e.cache = &wellBehavedCache{}
+	e.me = &metricsConf.DummyMetricsEngine{}
+	e.gDPR = gdpr.AlwaysAllow{}
+	e.currencyConverter = currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
+	e.categoriesFetcher = categoriesFetcher
+
+	for _, test := range testCases {
+		bidders := map[openrtb_ext.BidderName]*goodSingleBidder{}
+		e.adapterMap = map[openrtb_ext.BidderName]adaptedBidder{}
+		for _, bidderName := range []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus, openrtb_ext.BidderTelaria} {
+			bidders[bidderName] = &goodSingleBidder{
+				httpRequest: &adapters.RequestData{
+					Method:  "POST",
+					Uri:     server.URL,
+					Headers: http.Header{},
+				},
+				bidResponse: &adapters.BidderResponse{},
+			}
+			e.adapterMap[bidderName] = adaptBidder(bidders[bidderName], server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, bidderName, nil, nil)
+		}
+
+		auctionRequest := AuctionRequest{
+			BidRequest: &openrtb2.BidRequest{
+				ID: "some-request-id",
+				Imp: []openrtb2.Imp{{
+					ID:     "some-impression-id",
+					Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}},
+					Ext:    json.RawMessage(test.impExt),
+				}},
+				Site:   &openrtb2.Site{Page: "prebid.org", Ext: json.RawMessage(`{"amp":0}`)},
+				Source: test.source,
+				Ext:    json.RawMessage(`{"prebid":{}}`),
+			},
+			Account:   config.Account{GenerateTIDs: test.generateTIDs},
+			UserSyncs: &emptyUsersync{},
+		}
+
+		_, err := e.HoldAuction(context.Background(), auctionRequest, nil)
+		assert.NoError(t, err, test.description)
+
+		appnexusRequest := bidders[openrtb_ext.BidderAppnexus].bidRequest
+		telariaRequest := bidders[openrtb_ext.BidderTelaria].bidRequest
+		if !assert.NotNil(t, appnexusRequest, test.description) || !assert.NotNil(t, telariaRequest, test.description) {
+			continue
+		}
+
+		var sourceTID string
+		if appnexusRequest.Source != nil {
+			sourceTID = appnexusRequest.Source.TID
+		}
+		impTID, _ := jsonparser.GetString(appnexusRequest.Imp[0].Ext, "tid")
+		if test.expectGenerated {
+			assert.NotEmpty(t, sourceTID, test.description+":source.tid")
+			assert.NotEmpty(t, impTID, test.description+":imp.ext.tid")
+		} else {
+			assert.Equal(t, test.expectedSourceTID, sourceTID, test.description+":source.tid")
+			assert.Equal(t, test.expectedImpTID, impTID, test.description+":imp.ext.tid")
+		}
+
+		// Every bidder gets the same ids
+		telariaImpTID, _ := jsonparser.GetString(telariaRequest.Imp[0].Ext, "tid")
+		assert.Equal(t, impTID, telariaImpTID, test.description+":imp.ext.tid across bidders")
+		if telariaRequest.Source != nil {
+			assert.Equal(t, sourceTID, telariaRequest.Source.TID, test.description+":source.tid across bidders")
+		}
+	}
+}
+
 func TestTwoBiddersDebugDisabledAndEnabled(t *testing.T) {
 
 	type testCase struct {
@@ -497,8 +609,8 @@ func TestTwoBiddersDebugDisabledAndEnabled(t *testing.T) {
 		}
 
 		e.adapterMap = map[openrtb_ext.BidderName]adaptedBidder{
-			openrtb_ext.BidderAppnexus: adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, &config.DebugInfo{Allow: testCase.bidder1DebugEnabled}),
-			openrtb_ext.BidderTelaria:  adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, &config.DebugInfo{Allow: testCase.bidder2DebugEnabled}),
+			openrtb_ext.BidderAppnexus: adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, &config.DebugInfo{Allow: testCase.bidder1DebugEnabled}, nil),
+			openrtb_ext.BidderTelaria:  adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, &config.DebugInfo{Allow: testCase.bidder2DebugEnabled}, nil),
 		}
 		// Run test
 		outBidResponse, err := e.HoldAuction(context.Background(), auctionRequest, &debugLog)
@@ -652,7 +764,7 @@ func TestOverrideWithCustomCurrency(t *testing.T) {
 		}
 
 		e.adapterMap = map[openrtb_ext.BidderName]adaptedBidder{
-			openrtb_ext.BidderAppnexus: adaptBidder(oneDollarBidBidder, mockAppnexusBidService.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil),
+			openrtb_ext.BidderAppnexus: adaptBidder(oneDollarBidBidder, mockAppnexusBidService.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil),
 		}
 
 		// Set custom rates in extension
@@ -722,7 +834,7 @@ func TestAdapterCurrency(t *testing.T) {
 		categoriesFetcher: nilCategoryFetcher{},
 		bidIDGenerator:    &mockBidIDGenerator{false, false},
 		adapterMap: map[openrtb_ext.BidderName]adaptedBidder{
-			openrtb_ext.BidderName("foo"): adaptBidder(mockBidder, nil, &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderName("foo"), nil),
+			openrtb_ext.BidderName("foo"): adaptBidder(mockBidder, nil, &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderName("foo"), nil, nil),
 		},
 	}
 
@@ -762,6 +874,76 @@ func TestAdapterCurrency(t *testing.T) {
 	}
 }
 
+func TestHoldAuctionSeatNonBid(t *testing.T) {
+	testCases := []struct {
+		description        string
+		givenRequestExt    json.RawMessage
+		expectedSeatNonBid bool
+	}{
+		{
+			description:        "Seat non bids requested",
+			givenRequestExt:    json.RawMessage(`{"prebid": {"returnallbidstatus": true}}`),
+			expectedSeatNonBid: true,
+		},
+		{
+			description:        "Seat non bids not requested",
+			givenRequestExt:    nil,
+			expectedSeatNonBid: false,
+		},
+	}
+
+	for _, test := range testCases {
+		// The bidder fails without making a request, so its imp is left without a bid
+		mockBidder := &mockBidder{}
+		mockBidder.On("MakeRequests", mock.Anything, mock.Anything).Return([]*adapters.RequestData(nil), []error(nil))
+
+		e := exchange{
+			cache:             &wellBehavedCache{},
+			me:                &metricsConf.DummyMetricsEngine{},
+			gDPR:              gdpr.AlwaysAllow{},
+			currencyConverter: currency.NewRateConverter(&http.Client{}, "", time.Duration(0)),
+			categoriesFetcher: nilCategoryFetcher{},
+			bidIDGenerator:    &mockBidIDGenerator{false, false},
+			adapterMap: map[openrtb_ext.BidderName]adaptedBidder{
+				openrtb_ext.BidderName("foo"): adaptBidder(mockBidder, nil, &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderName("foo"), nil, nil),
+			},
+		}
+
+		var seatNonBid []openrtb_ext.SeatNonBid
+		auctionRequest := AuctionRequest{
+			BidRequest: &openrtb2.BidRequest{
+				ID: "some-request-id",
+				Imp: []openrtb2.Imp{{
+					ID:     "some-impression-id",
+					Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}},
+					Ext:    json.RawMessage(`{"foo": {"placementId": 1}}`),
+				}},
+				Site: &openrtb2.Site{Page: "prebid.org"},
+				Ext:  test.givenRequestExt,
+			},
+			Account:    config.Account{},
+			UserSyncs:  &emptyUsersync{},
+			SeatNonBid: &seatNonBid,
+		}
+		response, err := e.HoldAuction(context.Background(), auctionRequest, &DebugLog{})
+		assert.NoError(t, err, test.description)
+
+		expectedSeatNonBid := []openrtb_ext.SeatNonBid{
+			{Seat: "foo", NonBid: []openrtb_ext.NonBid{{ImpId: "some-impression-id", StatusCode: openrtb_ext.NonBidErrorGeneral}}},
+		}
+		assert.Equal(t, expectedSeatNonBid, seatNonBid, test.description+":analytics")
+
+		var responseExt openrtb_ext.ExtBidResponse
+		if assert.NoError(t, json.Unmarshal(response.Ext, &responseExt), test.description) {
+			if test.expectedSeatNonBid {
+				assert.Equal(t, expectedSeatNonBid, responseExt.SeatNonBid, test.description+":response")
+			} else {
+				assert.Empty(t, responseExt.SeatNonBid, test.description+":response")
+			}
+		}
+	}
+}
+
 func TestGetAuctionCurrencyRates(t *testing.T) {
 
 	pbsRates := map[string]map[string]float64{
@@ -1103,7 +1285,7 @@ func TestReturnCreativeEndToEnd(t *testing.T) {
 
 	e := new(exchange)
 	e.adapterMap = map[openrtb_ext.BidderName]adaptedBidder{
-		openrtb_ext.BidderAppnexus: adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil),
+		openrtb_ext.BidderAppnexus: adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil),
 	}
 	e.cache = &wellBehavedCache{}
 	e.me = &metricsConf.DummyMetricsEngine{}
@@ -1205,7 +1387,7 @@ func TestGetBidCacheInfoEndToEnd(t *testing.T) {
 	}
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
 	pbc := pbc.NewClient(&http.Client{}, &cfg.CacheURL, &cfg.ExtCacheURL, testEngine)
-	e := NewExchange(adapters, pbc, cfg, map[string]usersync.Syncer{}, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}).(*exchange)
+	e := NewExchange(adapters, pbc, cfg, map[string]usersync.Syncer{}, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}, nil, nil, nil, nil).(*exchange)
 	// 	3) Build all the parameters e.buildBidResponse(ctx.Background(), liveA... ) needs
 	liveAdapters := []openrtb_ext.BidderName{bidderName}
 
@@ -1393,7 +1575,7 @@ func TestBidReturnsCreative(t *testing.T) {
 	}
 	e := new(exchange)
 	e.adapterMap = map[openrtb_ext.BidderName]adaptedBidder{
-		openrtb_ext.BidderAppnexus: adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil),
+		openrtb_ext.BidderAppnexus: adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil),
 	}
 	e.cache = &wellBehavedCache{}
 	e.me = &metricsConf.DummyMetricsEngine{}
@@ -1559,7 +1741,7 @@ func TestBidResponseCurrency(t *testing.T) {
 	}
 
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	e := NewExchange(adapters, nil, cfg, map[string]usersync.Syncer{}, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}).(*exchange)
+	e := NewExchange(adapters, nil, cfg, map[string]usersync.Syncer{}, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}, nil, nil, nil, nil).(*exchange)
 
 	liveAdapters := make([]openrtb_ext.BidderName, 1)
 	liveAdapters[0] = "appnexus"
@@ -1698,7 +1880,7 @@ func TestBidResponseImpExtInfo(t *testing.T) {
 	cfg := &config.Configuration{Adapters: make(map[string]config.Adapter, 1)}
 	cfg.Adapters["appnexus"] = config.Adapter{Endpoint: "http://ib.adnxs.com"}
 
-	e := NewExchange(nil, nil, cfg, map[string]usersync.Syncer{}, &metricsConf.DummyMetricsEngine{}, nil, gdpr.AlwaysAllow{}, nil, nilCategoryFetcher{}).(*exchange)
+	e := NewExchange(nil, nil, cfg, map[string]usersync.Syncer{}, &metricsConf.DummyMetricsEngine{}, nil, gdpr.AlwaysAllow{}, nil, nilCategoryFetcher{}, nil, nil, nil, nil).(*exchange)
 
 	liveAdapters := make([]openrtb_ext.BidderName, 1)
 	liveAdapters[0] = "appnexus"
@@ -1797,7 +1979,7 @@ func TestRaceIntegration(t *testing.T) {
 	}
 
 	debugLog := DebugLog{}
-	ex := NewExchange(adapters, &wellBehavedCache{}, cfg, map[string]usersync.Syncer{}, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, &nilCategoryFetcher{}).(*exchange)
+	ex := NewExchange(adapters, &wellBehavedCache{}, cfg, map[string]usersync.Syncer{}, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, &nilCategoryFetcher{}, nil, nil, nil, nil).(*exchange)
 	_, err = ex.HoldAuction(context.Background(), auctionRequest, &debugLog)
 	if err != nil {
 		t.Errorf("HoldAuction returned unexpected error: %v", err)
@@ -1889,7 +2071,7 @@ func TestPanicRecovery(t *testing.T) {
 	}
 
 	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
-	e := NewExchange(adapters, nil, cfg, map[string]usersync.Syncer{}, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}).(*exchange)
+	e := NewExchange(adapters, nil, cfg, map[string]usersync.Syncer{}, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, nilCategoryFetcher{}, nil, nil, nil, nil).(*exchange)
 
 	chBids := make(chan *bidResponseWrapper, 1)
 	panicker := func(bidderRequest BidderRequest, conversions currency.Conversions) {
@@ -1962,7 +2144,7 @@ func TestPanicRecoveryHighLevel(t *testing.T) {
 		t.Errorf("Failed to create a category Fetcher: %v", error)
 	}
 
-	e := NewExchange(adapters, &mockCache{}, cfg, map[string]usersync.Syncer{}, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, categoriesFetcher).(*exchange)
+	e := NewExchange(adapters, &mockCache{}, cfg, map[string]usersync.Syncer{}, &metricsConf.DummyMetricsEngine{}, biddersInfo, gdpr.AlwaysAllow{}, currencyConverter, categoriesFetcher, nil, nil, nil, nil).(*exchange)
 
 	e.adapterMap[openrtb_ext.BidderBeachfront] = panicingAdapter{}
 	e.adapterMap[openrtb_ext.BidderAppnexus] = panicingAdapter{}
@@ -2138,6 +2320,7 @@ func runSpec(t *testing.T, filename string, spec *exchangeSpec) {
 			ID:            "testaccount",
 			EventsEnabled: spec.EventsEnabled,
 			DebugAllow:    true,
+			DealsOnly:     spec.DealsOnly,
 		},
 		UserSyncs: mockIdFetcher(spec.IncomingRequest.Usersyncs),
 	}
@@ -2394,10 +2577,10 @@ func TestCategoryMapping(t *testing.T) {
 	bid3 := openrtb2.Bid{ID: "bid_id3", ImpID: "imp_id3", Price: 30.0000, Cat: cats3, W: 1, H: 1}
 	bid4 := openrtb2.Bid{ID: "bid_id4", ImpID: "imp_id4", Price: 40.0000, Cat: cats4, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_2 := pbsOrtbBid{&bid2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, nil, 0, false, ""}
-	bid1_3 := pbsOrtbBid{&bid3, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30, PrimaryCategory: "AdapterOverride"}, nil, 0, false, ""}
-	bid1_4 := pbsOrtbBid{&bid4, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_1 := pbsOrtbBid{&bid1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+	bid1_2 := pbsOrtbBid{&bid2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, nil, 0, false, "", "", nil}
+	bid1_3 := pbsOrtbBid{&bid3, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30, PrimaryCategory: "AdapterOverride"}, nil, 0, false, "", "", nil}
+	bid1_4 := pbsOrtbBid{&bid4, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
 
 	innerBids := []*pbsOrtbBid{
 		&bid1_1,
@@ -2411,7 +2594,7 @@ func TestCategoryMapping(t *testing.T) {
 
 	adapterBids[bidderName1] = &seatBid
 
-	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, nil, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
 
 	assert.Equal(t, nil, err, "Category mapping error should be empty")
 	assert.Equal(t, 1, len(rejections), "There should be 1 bid rejection message")
@@ -2449,10 +2632,10 @@ func TestCategoryMappingNoIncludeBrandCategory(t *testing.T) {
 	bid3 := openrtb2.Bid{ID: "bid_id3", ImpID: "imp_id3", Price: 30.0000, Cat: cats3, W: 1, H: 1}
 	bid4 := openrtb2.Bid{ID: "bid_id4", ImpID: "imp_id4", Price: 40.0000, Cat: cats4, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_2 := pbsOrtbBid{&bid2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, nil, 0, false, ""}
-	bid1_3 := pbsOrtbBid{&bid3, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30, PrimaryCategory: "AdapterOverride"}, nil, 0, false, ""}
-	bid1_4 := pbsOrtbBid{&bid4, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 50}, nil, 0, false, ""}
+	bid1_1 := pbsOrtbBid{&bid1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+	bid1_2 := pbsOrtbBid{&bid2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, nil, 0, false, "", "", nil}
+	bid1_3 := pbsOrtbBid{&bid3, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30, PrimaryCategory: "AdapterOverride"}, nil, 0, false, "", "", nil}
+	bid1_4 := pbsOrtbBid{&bid4, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 50}, nil, 0, false, "", "", nil}
 
 	innerBids := []*pbsOrtbBid{
 		&bid1_1,
@@ -2466,7 +2649,7 @@ func TestCategoryMappingNoIncludeBrandCategory(t *testing.T) {
 
 	adapterBids[bidderName1] = &seatBid
 
-	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, nil, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
 
 	assert.Equal(t, nil, err, "Category mapping error should be empty")
 	assert.Empty(t, rejections, "There should be no bid rejection messages")
@@ -2503,9 +2686,9 @@ func TestCategoryMappingTranslateCategoriesNil(t *testing.T) {
 	bid2 := openrtb2.Bid{ID: "bid_id2", ImpID: "imp_id2", Price: 20.0000, Cat: cats2, W: 1, H: 1}
 	bid3 := openrtb2.Bid{ID: "bid_id3", ImpID: "imp_id3", Price: 30.0000, Cat: cats3, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_2 := pbsOrtbBid{&bid2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, nil, 0, false, ""}
-	bid1_3 := pbsOrtbBid{&bid3, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_1 := pbsOrtbBid{&bid1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+	bid1_2 := pbsOrtbBid{&bid2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, nil, 0, false, "", "", nil}
+	bid1_3 := pbsOrtbBid{&bid3, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
 
 	innerBids := []*pbsOrtbBid{
 		&bid1_1,
@@ -2518,7 +2701,7 @@ func TestCategoryMappingTranslateCategoriesNil(t *testing.T) {
 
 	adapterBids[bidderName1] = &seatBid
 
-	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, nil, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
 
 	assert.Equal(t, nil, err, "Category mapping error should be empty")
 	assert.Equal(t, 1, len(rejections), "There should be 1 bid rejection message")
@@ -2585,9 +2768,9 @@ func TestCategoryMappingTranslateCategoriesFalse(t *testing.T) {
 	bid2 := openrtb2.Bid{ID: "bid_id2", ImpID: "imp_id2", Price: 20.0000, Cat: cats2, W: 1, H: 1}
 	bid3 := openrtb2.Bid{ID: "bid_id3", ImpID: "imp_id3", Price: 30.0000, Cat: cats3, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_2 := pbsOrtbBid{&bid2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, nil, 0, false, ""}
-	bid1_3 := pbsOrtbBid{&bid3, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_1 := pbsOrtbBid{&bid1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+	bid1_2 := pbsOrtbBid{&bid2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 40}, nil, 0, false, "", "", nil}
+	bid1_3 := pbsOrtbBid{&bid3, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
 
 	innerBids := []*pbsOrtbBid{
 		&bid1_1,
@@ -2600,7 +2783,7 @@ func TestCategoryMappingTranslateCategoriesFalse(t *testing.T) {
 
 	adapterBids[bidderName1] = &seatBid
 
-	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, nil, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
 
 	assert.Equal(t, nil, err, "Category mapping error should be empty")
 	assert.Empty(t, rejections, "There should be no bid rejection messages")
@@ -2637,11 +2820,11 @@ func TestCategoryDedupe(t *testing.T) {
 	bid4 := openrtb2.Bid{ID: "bid_id4", ImpID: "imp_id4", Price: 20.0000, Cat: cats4, W: 1, H: 1}
 	bid5 := openrtb2.Bid{ID: "bid_id5", ImpID: "imp_id5", Price: 20.0000, Cat: cats1, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_2 := pbsOrtbBid{&bid2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 50}, nil, 0, false, ""}
-	bid1_3 := pbsOrtbBid{&bid3, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_4 := pbsOrtbBid{&bid4, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_5 := pbsOrtbBid{&bid5, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_1 := pbsOrtbBid{&bid1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+	bid1_2 := pbsOrtbBid{&bid2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 50}, nil, 0, false, "", "", nil}
+	bid1_3 := pbsOrtbBid{&bid3, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+	bid1_4 := pbsOrtbBid{&bid4, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+	bid1_5 := pbsOrtbBid{&bid5, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
 
 	selectedBids := make(map[string]int)
 	expectedCategories := map[string]string{
@@ -2670,7 +2853,7 @@ func TestCategoryDedupe(t *testing.T) {
 
 		adapterBids[bidderName1] = &seatBid
 
-		bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+		bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, nil, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
 
 		assert.Equal(t, nil, err, "Category mapping error should be empty")
 		assert.Equal(t, 3, len(rejections), "There should be 2 bid rejection messages")
@@ -2716,11 +2899,11 @@ func TestNoCategoryDedupe(t *testing.T) {
 	bid4 := openrtb2.Bid{ID: "bid_id4", ImpID: "imp_id4", Price: 20.0000, Cat: cats4, W: 1, H: 1}
 	bid5 := openrtb2.Bid{ID: "bid_id5", ImpID: "imp_id5", Price: 10.0000, Cat: cats1, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_2 := pbsOrtbBid{&bid2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_3 := pbsOrtbBid{&bid3, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_4 := pbsOrtbBid{&bid4, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_5 := pbsOrtbBid{&bid5, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_1 := pbsOrtbBid{&bid1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+	bid1_2 := pbsOrtbBid{&bid2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+	bid1_3 := pbsOrtbBid{&bid3, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+	bid1_4 := pbsOrtbBid{&bid4, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+	bid1_5 := pbsOrtbBid{&bid5, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
 
 	selectedBids := make(map[string]int)
 	expectedCategories := map[string]string{
@@ -2750,7 +2933,7 @@ func TestNoCategoryDedupe(t *testing.T) {
 
 		adapterBids[bidderName1] = &seatBid
 
-		bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+		bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, nil, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
 
 		assert.Equal(t, nil, err, "Category mapping error should be empty")
 		assert.Equal(t, 2, len(rejections), "There should be 2 bid rejection messages")
@@ -2796,8 +2979,8 @@ func TestCategoryMappingBidderName(t *testing.T) {
 	bid1 := openrtb2.Bid{ID: "bid_id1", ImpID: "imp_id1", Price: 10.0000, Cat: cats1, W: 1, H: 1}
 	bid2 := openrtb2.Bid{ID: "bid_id2", ImpID: "imp_id2", Price: 10.0000, Cat: cats2, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_2 := pbsOrtbBid{&bid2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_1 := pbsOrtbBid{&bid1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+	bid1_2 := pbsOrtbBid{&bid2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
 
 	innerBids1 := []*pbsOrtbBid{
 		&bid1_1,
@@ -2815,7 +2998,7 @@ func TestCategoryMappingBidderName(t *testing.T) {
 	adapterBids[bidderName1] = &seatBid1
 	adapterBids[bidderName2] = &seatBid2
 
-	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, nil, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
 
 	assert.NoError(t, err, "Category mapping error should be empty")
 	assert.Empty(t, rejections, "There should be 0 bid rejection messages")
@@ -2826,6 +3009,92 @@ func TestCategoryMappingBidderName(t *testing.T) {
 	assert.Len(t, bidCategory, 2, "Bidders category mapping doesn't match")
 }
 
+func TestCategoryMappingAdvertiserDomainExclusion(t *testing.T) {
+	categoriesFetcher, error := newCategoryFetcher("./test/category-mapping")
+	if error != nil {
+		t.Errorf("Failed to create a category Fetcher: %v", error)
+	}
+
+	testCases := []struct {
+		description        string
+		givenAdPods        map[string]*adPod
+		expectedBids       map[string][]string
+		expectedRejections []string
+	}{
+		{
+			description: "Across the request",
+			givenAdPods: nil,
+			expectedBids: map[string][]string{
+				"bidder1": {"bid_id1"},
+				"bidder2": {"bid_id4"},
+			},
+			expectedRejections: []string{
+				"bid rejected [bid ID: bid_id2] reason: Bid was deduplicated by advertiser domain",
+				"bid rejected [bid ID: bid_id3] reason: Bid was deduplicated by advertiser domain",
+			},
+		},
+		{
+			description: "Across the pods",
+			givenAdPods: map[string]*adPod{
+				"imp_id1": {id: "1"},
+				"imp_id2": {id: "1"},
+				"imp_id3": {id: "2"},
+				"imp_id4": {id: "2"},
+			},
+			expectedBids: map[string][]string{
+				"bidder1": {"bid_id1"},
+				"bidder2": {"bid_id4"},
+			},
+			expectedRejections: []string{
+				"bid rejected [bid ID: bid_id2] reason: Bid was deduplicated by advertiser domain",
+				"bid rejected [bid ID: bid_id3] reason: Bid was deduplicated by advertiser domain",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		requestExt := newExtRequest()
+		requestExt.Prebid.Targeting.IncludeBrandCategory.ExcludeAdvertiserDomains = true
+		requestExt.Prebid.Targeting.DurationRangeSec = []int{15, 30}
+
+		targData := &targetData{
+			priceGranularity: requestExt.Prebid.Targeting.PriceGranularity,
+			includeWinners:   true,
+		}
+
+		bid1 := openrtb2.Bid{ID: "bid_id1", ImpID: "imp_id1", Price: 20.0000, Cat: []string{"IAB1-1"}, ADomain: []string{"brand.com"}, W: 1, H: 1}
+		bid2 := openrtb2.Bid{ID: "bid_id2", ImpID: "imp_id2", Price: 15.0000, Cat: []string{"IAB1-2"}, ADomain: []string{"Brand.com", "other.com"}, W: 1, H: 1}
+		bid3 := openrtb2.Bid{ID: "bid_id3", ImpID: "imp_id3", Price: 10.0000, Cat: []string{"IAB1-3"}, ADomain: []string{"brand.com"}, W: 1, H: 1}
+		bid4 := openrtb2.Bid{ID: "bid_id4", ImpID: "imp_id4", Price: 5.0000, Cat: []string{"IAB1-4"}, ADomain: []string{"other.com"}, W: 1, H: 1}
+
+		bid1_1 := pbsOrtbBid{&bid1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+		bid1_2 := pbsOrtbBid{&bid2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+		bid2_1 := pbsOrtbBid{&bid3, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+		bid2_2 := pbsOrtbBid{&bid4, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+
+		adapterBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+			"bidder1": {bids: []*pbsOrtbBid{&bid1_1, &bid1_2}, currency: "USD"},
+			"bidder2": {bids: []*pbsOrtbBid{&bid2_1, &bid2_2}, currency: "USD"},
+		}
+
+		bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, test.givenAdPods, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+
+		assert.NoError(t, err, test.description+":category mapping error should be empty")
+		assert.ElementsMatch(t, test.expectedRejections, rejections, test.description+":rejections")
+		bidIDs := make(map[string][]string)
+		for bidderName, seatBid := range adapterBids {
+			for _, bid := range seatBid.bids {
+				bidIDs[bidderName.String()] = append(bidIDs[bidderName.String()], bid.bid.ID)
+			}
+		}
+		assert.Equal(t, test.expectedBids, bidIDs, test.description+":bids")
+		for _, bidID := range []string{"bid_id1", "bid_id2", "bid_id3", "bid_id4"} {
+			_, found := bidCategory[bidID]
+			assert.Equal(t, strings.Contains(fmt.Sprint(test.expectedBids), bidID), found, test.description+":category of "+bidID)
+		}
+	}
+}
+
 func TestCategoryMappingBidderNameNoCategories(t *testing.T) {
 
 	categoriesFetcher, error := newCategoryFetcher("./test/category-mapping")
@@ -2850,8 +3119,8 @@ func TestCategoryMappingBidderNameNoCategories(t *testing.T) {
 	bid1 := openrtb2.Bid{ID: "bid_id1", ImpID: "imp_id1", Price: 10.0000, Cat: cats1, W: 1, H: 1}
 	bid2 := openrtb2.Bid{ID: "bid_id2", ImpID: "imp_id2", Price: 12.0000, Cat: cats2, W: 1, H: 1}
 
-	bid1_1 := pbsOrtbBid{&bid1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_2 := pbsOrtbBid{&bid2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_1 := pbsOrtbBid{&bid1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+	bid1_2 := pbsOrtbBid{&bid2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
 
 	innerBids1 := []*pbsOrtbBid{
 		&bid1_1,
@@ -2869,7 +3138,7 @@ func TestCategoryMappingBidderNameNoCategories(t *testing.T) {
 	adapterBids[bidderName1] = &seatBid1
 	adapterBids[bidderName2] = &seatBid2
 
-	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+	bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, nil, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
 
 	assert.NoError(t, err, "Category mapping error should be empty")
 	assert.Empty(t, rejections, "There should be 0 bid rejection messages")
@@ -2962,7 +3231,7 @@ func TestBidRejectionErrors(t *testing.T) {
 		innerBids := []*pbsOrtbBid{}
 		for _, bid := range test.bids {
 			currentBid := pbsOrtbBid{
-				bid, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: test.duration}, nil, 0, false, ""}
+				bid, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: test.duration}, nil, 0, false, "", "", nil}
 			innerBids = append(innerBids, &currentBid)
 		}
 
@@ -2970,7 +3239,7 @@ func TestBidRejectionErrors(t *testing.T) {
 
 		adapterBids[bidderName] = &seatBid
 
-		bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &test.reqExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+		bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &test.reqExt, adapterBids, nil, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
 
 		if len(test.expectedCatDur) > 0 {
 			// Bid deduplication case
@@ -3010,8 +3279,8 @@ func TestCategoryMappingTwoBiddersOneBidEachNoCategorySamePrice(t *testing.T) {
 	bidApn1 := openrtb2.Bid{ID: "bid_idApn1", ImpID: "imp_idApn1", Price: 10.0000, Cat: cats1, W: 1, H: 1}
 	bidApn2 := openrtb2.Bid{ID: "bid_idApn2", ImpID: "imp_idApn2", Price: 10.0000, Cat: cats2, W: 1, H: 1}
 
-	bid1_Apn1 := pbsOrtbBid{&bidApn1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_Apn2 := pbsOrtbBid{&bidApn2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_Apn1 := pbsOrtbBid{&bidApn1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+	bid1_Apn2 := pbsOrtbBid{&bidApn2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
 
 	innerBidsApn1 := []*pbsOrtbBid{
 		&bid1_Apn1,
@@ -3033,7 +3302,7 @@ func TestCategoryMappingTwoBiddersOneBidEachNoCategorySamePrice(t *testing.T) {
 		adapterBids[bidderNameApn1] = &seatBidApn1
 		adapterBids[bidderNameApn2] = &seatBidApn2
 
-		bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
+		bidCategory, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, nil, categoriesFetcher, targData, &randomDeduplicateBidBooleanGenerator{})
 
 		assert.NoError(t, err, "Category mapping error should be empty")
 		assert.Len(t, rejections, 1, "There should be 1 bid rejection message")
@@ -3090,11 +3359,11 @@ func TestCategoryMappingTwoBiddersManyBidsEachNoCategorySamePrice(t *testing.T)
 	bidApn2_1 := openrtb2.Bid{ID: "bid_idApn2_1", ImpID: "imp_idApn2_1", Price: 10.0000, Cat: cats2, W: 1, H: 1}
 	bidApn2_2 := openrtb2.Bid{ID: "bid_idApn2_2", ImpID: "imp_idApn2_2", Price: 20.0000, Cat: cats2, W: 1, H: 1}
 
-	bid1_Apn1_1 := pbsOrtbBid{&bidApn1_1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_Apn1_2 := pbsOrtbBid{&bidApn1_2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_Apn1_1 := pbsOrtbBid{&bidApn1_1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+	bid1_Apn1_2 := pbsOrtbBid{&bidApn1_2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
 
-	bid1_Apn2_1 := pbsOrtbBid{&bidApn2_1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_Apn2_2 := pbsOrtbBid{&bidApn2_2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_Apn2_1 := pbsOrtbBid{&bidApn2_1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+	bid1_Apn2_2 := pbsOrtbBid{&bidApn2_2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
 
 	innerBidsApn1 := []*pbsOrtbBid{
 		&bid1_Apn1_1,
@@ -3117,7 +3386,7 @@ func TestCategoryMappingTwoBiddersManyBidsEachNoCategorySamePrice(t *testing.T)
 	adapterBids[bidderNameApn1] = &seatBidApn1
 	adapterBids[bidderNameApn2] = &seatBidApn2
 
-	_, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, categoriesFetcher, targData, &fakeRandomDeduplicateBidBooleanGenerator{true})
+	_, adapterBids, rejections, err := applyCategoryMapping(nil, &requestExt, adapterBids, nil, categoriesFetcher, targData, &fakeRandomDeduplicateBidBooleanGenerator{true})
 
 	assert.NoError(t, err, "Category mapping error should be empty")
 
@@ -3172,9 +3441,9 @@ func TestRemoveBidById(t *testing.T) {
 	bidApn1_2 := openrtb2.Bid{ID: "bid_idApn1_2", ImpID: "imp_idApn1_2", Price: 20.0000, Cat: cats1, W: 1, H: 1}
 	bidApn1_3 := openrtb2.Bid{ID: "bid_idApn1_3", ImpID: "imp_idApn1_3", Price: 10.0000, Cat: cats1, W: 1, H: 1}
 
-	bid1_Apn1_1 := pbsOrtbBid{&bidApn1_1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_Apn1_2 := pbsOrtbBid{&bidApn1_2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
-	bid1_Apn1_3 := pbsOrtbBid{&bidApn1_3, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, ""}
+	bid1_Apn1_1 := pbsOrtbBid{&bidApn1_1, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+	bid1_Apn1_2 := pbsOrtbBid{&bidApn1_2, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
+	bid1_Apn1_3 := pbsOrtbBid{&bidApn1_3, nil, "video", nil, &openrtb_ext.ExtBidPrebidVideo{Duration: 30}, nil, 0, false, "", "", nil}
 
 	type aTest struct {
 		desc      string
@@ -3299,7 +3568,7 @@ func TestApplyDealSupport(t *testing.T) {
 			},
 		}
 
-		bid := pbsOrtbBid{&openrtb2.Bid{ID: "123456"}, nil, "video", map[string]string{}, &openrtb_ext.ExtBidPrebidVideo{}, nil, test.dealPriority, false, ""}
+		bid := pbsOrtbBid{&openrtb2.Bid{ID: "123456"}, nil, "video", map[string]string{}, &openrtb_ext.ExtBidPrebidVideo{}, nil, test.dealPriority, false, "", "", nil}
 		bidCategory := map[string]string{
 			bid.bid.ID: test.targ["hb_pb_cat_dur"],
 		}
@@ -3466,7 +3735,7 @@ func TestUpdateHbPbCatDur(t *testing.T) {
 	}
 
 	for _, test := range testCases {
-		bid := pbsOrtbBid{&openrtb2.Bid{ID: "123456"}, nil, "video", map[string]string{}, &openrtb_ext.ExtBidPrebidVideo{}, nil, test.dealPriority, false, ""}
+		bid := pbsOrtbBid{&openrtb2.Bid{ID: "123456"}, nil, "video", map[string]string{}, &openrtb_ext.ExtBidPrebidVideo{}, nil, test.dealPriority, false, "", "", nil}
 		bidCategory := map[string]string{
 			bid.bid.ID: test.targ["hb_pb_cat_dur"],
 		}
@@ -3478,6 +3747,311 @@ func TestUpdateHbPbCatDur(t *testing.T) {
 	}
 }
 
+func TestRemoveNonDealBids(t *testing.T) {
+	dealBid := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "deal-bid", DealID: "deal-1"}}
+	otherDealBid := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "other-deal-bid", DealID: "deal-2"}}
+	openMarketBid := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "open-market-bid"}}
+
+	testCases := []struct {
+		description       string
+		givenAdapterBids  map[openrtb_ext.BidderName]*pbsOrtbSeatBid
+		expectedBids      map[openrtb_ext.BidderName][]*pbsOrtbBid
+		expectedDealFound bool
+	}{
+		{
+			description: "Mixed Deal And Open Market Bids",
+			givenAdapterBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+				"appnexus": {bids: []*pbsOrtbBid{dealBid, openMarketBid}},
+				"rubicon":  {bids: []*pbsOrtbBid{otherDealBid}},
+			},
+			expectedBids: map[openrtb_ext.BidderName][]*pbsOrtbBid{
+				"appnexus": {dealBid},
+				"rubicon":  {otherDealBid},
+			},
+			expectedDealFound: true,
+		},
+		{
+			description: "Seat Without Deal Bids Removed",
+			givenAdapterBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+				"appnexus": {bids: []*pbsOrtbBid{dealBid}},
+				"rubicon":  {bids: []*pbsOrtbBid{openMarketBid}},
+			},
+			expectedBids: map[openrtb_ext.BidderName][]*pbsOrtbBid{
+				"appnexus": {dealBid},
+			},
+			expectedDealFound: true,
+		},
+		{
+			description: "No Deal Bids",
+			givenAdapterBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+				"appnexus": {bids: []*pbsOrtbBid{openMarketBid}},
+			},
+			expectedBids:      map[openrtb_ext.BidderName][]*pbsOrtbBid{},
+			expectedDealFound: false,
+		},
+	}
+
+	for _, test := range testCases {
+		dealFound := removeNonDealBids(test.givenAdapterBids)
+
+		assert.Equal(t, test.expectedDealFound, dealFound, test.description+":deal_found")
+		actualBids := make(map[openrtb_ext.BidderName][]*pbsOrtbBid, len(test.givenAdapterBids))
+		for bidderName, seatBid := range test.givenAdapterBids {
+			actualBids[bidderName] = seatBid.bids
+		}
+		assert.Equal(t, test.expectedBids, actualBids, test.description+":bids")
+	}
+}
+
+func TestGetPrivateAuctionDeals(t *testing.T) {
+	testCases := []struct {
+		description      string
+		givenImps        []openrtb2.Imp
+		expectedDeals    map[string]map[string]struct{}
+		expectedWarnings []error
+	}{
+		{
+			description: "No Private Auctions",
+			givenImps: []openrtb2.Imp{
+				{ID: "imp-1"},
+				{ID: "imp-2", PMP: &openrtb2.PMP{PrivateAuction: 0, Deals: []openrtb2.Deal{{ID: "deal-1"}}}},
+			},
+			expectedDeals:    nil,
+			expectedWarnings: nil,
+		},
+		{
+			description: "Private Auction With Deals",
+			givenImps: []openrtb2.Imp{
+				{ID: "imp-1"},
+				{ID: "imp-2", PMP: &openrtb2.PMP{PrivateAuction: 1, Deals: []openrtb2.Deal{{ID: "deal-1"}, {ID: "deal-2"}}}},
+			},
+			expectedDeals: map[string]map[string]struct{}{
+				"imp-2": {"deal-1": {}, "deal-2": {}},
+			},
+			expectedWarnings: nil,
+		},
+		{
+			description: "Private Auction Without Deals",
+			givenImps: []openrtb2.Imp{
+				{ID: "imp-1", PMP: &openrtb2.PMP{PrivateAuction: 1}},
+			},
+			expectedDeals: map[string]map[string]struct{}{
+				"imp-1": {},
+			},
+			expectedWarnings: []error{&errortypes.Warning{
+				Message:     "imp imp-1 is a private auction without any deals, no bids are eligible",
+				WarningCode: errortypes.PrivateAuctionWithoutDealsWarningCode,
+			}},
+		},
+	}
+
+	for _, test := range testCases {
+		deals, warnings := getPrivateAuctionDeals(&openrtb2.BidRequest{Imp: test.givenImps})
+
+		assert.Equal(t, test.expectedDeals, deals, test.description+":deals")
+		assert.Equal(t, test.expectedWarnings, warnings, test.description+":warnings")
+	}
+}
+
+func TestRemovePrivateAuctionIneligibleBids(t *testing.T) {
+	matchingDealBid := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "matching-deal-bid", ImpID: "private-imp", DealID: "deal-1"}}
+	otherDealBid := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "other-deal-bid", ImpID: "private-imp", DealID: "deal-2"}}
+	openMarketBid := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "open-market-bid", ImpID: "private-imp"}}
+	publicImpBid := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "public-imp-bid", ImpID: "public-imp"}}
+	noDealsImpBid := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "no-deals-imp-bid", ImpID: "no-deals-imp", DealID: "deal-1"}}
+
+	privateAuctionDeals := map[string]map[string]struct{}{
+		"private-imp":  {"deal-1": {}},
+		"no-deals-imp": {},
+	}
+
+	testCases := []struct {
+		description       string
+		givenAdapterBids  map[openrtb_ext.BidderName]*pbsOrtbSeatBid
+		expectedBids      map[openrtb_ext.BidderName][]*pbsOrtbBid
+		expectedWarnings  map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage
+		expectedBidsFound bool
+	}{
+		{
+			description: "Matching Deal Bid",
+			givenAdapterBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+				"appnexus": {bids: []*pbsOrtbBid{matchingDealBid, publicImpBid}},
+			},
+			expectedBids: map[openrtb_ext.BidderName][]*pbsOrtbBid{
+				"appnexus": {matchingDealBid, publicImpBid},
+			},
+			expectedWarnings:  map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage{},
+			expectedBidsFound: true,
+		},
+		{
+			description: "Non Matching Bids",
+			givenAdapterBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+				"appnexus": {bids: []*pbsOrtbBid{matchingDealBid, openMarketBid}},
+				"rubicon":  {bids: []*pbsOrtbBid{otherDealBid}},
+			},
+			expectedBids: map[openrtb_ext.BidderName][]*pbsOrtbBid{
+				"appnexus": {matchingDealBid},
+			},
+			expectedWarnings: map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage{
+				"appnexus": {{
+					Code:    errortypes.PrivateAuctionBidRejectedWarningCode,
+					Message: `bid open-market-bid dropped because imp private-imp is a private auction and deal "" is not one of its deals`,
+				}},
+				"rubicon": {{
+					Code:    errortypes.PrivateAuctionBidRejectedWarningCode,
+					Message: `bid other-deal-bid dropped because imp private-imp is a private auction and deal "deal-2" is not one of its deals`,
+				}},
+			},
+			expectedBidsFound: true,
+		},
+		{
+			description: "Private Auction Without Deals",
+			givenAdapterBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+				"appnexus": {bids: []*pbsOrtbBid{noDealsImpBid}},
+			},
+			expectedBids: map[openrtb_ext.BidderName][]*pbsOrtbBid{},
+			expectedWarnings: map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage{
+				"appnexus": {{
+					Code:    errortypes.PrivateAuctionBidRejectedWarningCode,
+					Message: `bid no-deals-imp-bid dropped because imp no-deals-imp is a private auction and deal "deal-1" is not one of its deals`,
+				}},
+			},
+			expectedBidsFound: false,
+		},
+	}
+
+	for _, test := range testCases {
+		adapterExtra := make(map[openrtb_ext.BidderName]*seatResponseExtra, len(test.givenAdapterBids))
+		for bidderName := range test.givenAdapterBids {
+			adapterExtra[bidderName] = &seatResponseExtra{}
+		}
+
+		bidsFound := removePrivateAuctionIneligibleBids(test.givenAdapterBids, adapterExtra, privateAuctionDeals)
+
+		assert.Equal(t, test.expectedBidsFound, bidsFound, test.description+":bids_found")
+		actualBids := make(map[openrtb_ext.BidderName][]*pbsOrtbBid, len(test.givenAdapterBids))
+		for bidderName, seatBid := range test.givenAdapterBids {
+			actualBids[bidderName] = seatBid.bids
+		}
+		assert.Equal(t, test.expectedBids, actualBids, test.description+":bids")
+		actualWarnings := make(map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage)
+		for bidderName, extra := range adapterExtra {
+			if len(extra.Warnings) > 0 {
+				actualWarnings[bidderName] = extra.Warnings
+			}
+		}
+		assert.Equal(t, test.expectedWarnings, actualWarnings, test.description+":warnings")
+	}
+}
+
+// fakeGeoResolver locates the IP addresses of its map, and fails to locate the other ones
+type fakeGeoResolver struct {
+	geos map[string]*geolocation.GeoInfo
+}
+
+func (r fakeGeoResolver) Lookup(ctx context.Context, ip string) (*geolocation.GeoInfo, error) {
+	if geo, ok := r.geos[ip]; ok {
+		return geo, nil
+	}
+	return nil, errors.New("lookup failed")
+}
+
+func TestLocateDevice(t *testing.T) {
+	resolver := fakeGeoResolver{geos: map[string]*geolocation.GeoInfo{
+		"81.2.69.142": {Country: "GBR", Region: "ENG"},
+		"2001:db8::1": {Country: "DEU"},
+	}}
+
+	testCases := []struct {
+		description   string
+		givenResolver geolocation.Resolver
+		givenDevice   *openrtb2.Device
+		expectedGeo   *geolocation.GeoInfo
+	}{
+		{
+			description:   "Without resolver",
+			givenDevice:   &openrtb2.Device{IP: "81.2.69.142"},
+			givenResolver: nil,
+		},
+		{
+			description:   "Without device",
+			givenResolver: resolver,
+		},
+		{
+			description:   "IPv4 address",
+			givenResolver: resolver,
+			givenDevice:   &openrtb2.Device{IP: "81.2.69.142", IPv6: "2001:db8::1", Geo: &openrtb2.Geo{City: "London"}},
+			expectedGeo:   &geolocation.GeoInfo{Country: "GBR", Region: "ENG"},
+		},
+		{
+			description:   "IPv6 address",
+			givenResolver: resolver,
+			givenDevice:   &openrtb2.Device{IPv6: "2001:db8::1"},
+			expectedGeo:   &geolocation.GeoInfo{Country: "DEU"},
+		},
+		{
+			description:   "Device with a country",
+			givenResolver: resolver,
+			givenDevice:   &openrtb2.Device{IP: "81.2.69.142", Geo: &openrtb2.Geo{Country: "FRA"}},
+		},
+		{
+			description:   "Failed lookup",
+			givenResolver: resolver,
+			givenDevice:   &openrtb2.Device{IP: "8.8.8.8"},
+		},
+	}
+
+	for _, test := range testCases {
+		e := exchange{geoResolver: test.givenResolver}
+
+		geo := e.locateDevice(context.Background(), &openrtb2.BidRequest{Device: test.givenDevice})
+
+		assert.Equal(t, test.expectedGeo, geo, test.description)
+	}
+}
+
+func TestParseGDPRDefaultValueLocated(t *testing.T) {
+	testCases := []struct {
+		description   string
+		givenRequest  *openrtb2.BidRequest
+		givenLocated  *geolocation.GeoInfo
+		expectedValue gdpr.Signal
+	}{
+		{
+			description:   "Unknown location",
+			givenRequest:  &openrtb2.BidRequest{},
+			expectedValue: gdpr.SignalYes,
+		},
+		{
+			description:   "Located outside of the EEA",
+			givenRequest:  &openrtb2.BidRequest{Device: &openrtb2.Device{Geo: &openrtb2.Geo{City: "New York"}}},
+			givenLocated:  &geolocation.GeoInfo{Country: "USA", Region: "NY"},
+			expectedValue: gdpr.SignalNo,
+		},
+		{
+			description:   "Located in the EEA",
+			givenRequest:  &openrtb2.BidRequest{},
+			givenLocated:  &geolocation.GeoInfo{Country: "FRA"},
+			expectedValue: gdpr.SignalYes,
+		},
+		{
+			description:   "User geo over the location",
+			givenRequest:  &openrtb2.BidRequest{User: &openrtb2.User{Geo: &openrtb2.Geo{Country: "FIN"}}},
+			givenLocated:  &geolocation.GeoInfo{Country: "USA"},
+			expectedValue: gdpr.SignalYes,
+		},
+	}
+
+	for _, test := range testCases {
+		e := exchange{
+			gdprDefaultValue: gdpr.SignalYes,
+			privacyConfig:    config.Privacy{GDPR: config.GDPR{EEACountriesMap: map[string]struct{}{"FIN": {}, "FRA": {}}}},
+		}
+
+		assert.Equal(t, test.expectedValue, e.parseGDPRDefaultValue(test.givenRequest, test.givenLocated), test.description)
+	}
+}
+
 func TestMakeBidExtJSON(t *testing.T) {
 
 	type aTest struct {
@@ -3659,6 +4233,7 @@ type exchangeSpec struct {
 	AssumeGDPRApplies bool                   `json:"assume_gdpr_applies"`
 	DebugLog          *DebugLog              `json:"debuglog,omitempty"`
 	EventsEnabled     bool                   `json:"events_enabled,omitempty"`
+	DealsOnly         bool                   `json:"deals_only,omitempty"`
 	StartTime         int64                  `json:"start_time_ms,omitempty"`
 	BidIDGenerator    *mockBidIDGenerator    `json:"bidIDGenerator,omitempty"`
 }
@@ -0,0 +1,120 @@
+package exchange
+
+import (
+	"fmt"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/floors"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// enforceFloors drops the bids priced below the floor of their imp. Floors are converted from imp.bidfloorcur to the
+// currency of the seat with the auction's conversion rates, and multiplied by the bidder's adjustment factor, if the
+// account has one. Bids whose floor can't be converted are dropped as well, since they can't be shown to clear it.
+// It returns true if any bids remain.
+func enforceFloors(bidRequest *openrtb2.BidRequest, adapterBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, adapterExtra map[openrtb_ext.BidderName]*seatResponseExtra, coreBidderNames map[openrtb_ext.BidderName]openrtb_ext.BidderName, conversions currency.Conversions, floorsConfig config.AccountPriceFloors, me metrics.MetricsEngine) bool {
+	floors := make(map[string]openrtb2.Imp, len(bidRequest.Imp))
+	for _, imp := range bidRequest.Imp {
+		if imp.BidFloor > 0 {
+			floors[imp.ID] = imp
+		}
+	}
+
+	bidsFound := false
+	for bidderName, seatBid := range adapterBids {
+		coreBidder, ok := coreBidderNames[bidderName]
+		if !ok {
+			coreBidder = bidderName
+		}
+		adjustmentFactor := getFloorAdjustmentFactor(floorsConfig.AdjustmentFactors, bidderName, coreBidder)
+
+		eligibleBids := make([]*pbsOrtbBid, 0, len(seatBid.bids))
+		for _, bid := range seatBid.bids {
+			if bid.bid == nil {
+				continue
+			}
+			imp, hasFloor := floors[bid.bid.ImpID]
+			if !hasFloor {
+				eligibleBids = append(eligibleBids, bid)
+				continue
+			}
+
+			var message string
+			var statusCode openrtb_ext.NonBidStatusCode
+			floorCur := imp.BidFloorCur
+			if floorCur == "" {
+				floorCur = "USD"
+			}
+			rate, err := conversions.GetRate(floorCur, seatBid.currency)
+			if err != nil {
+				me.RecordFloorsRejectedBid(coreBidder, metrics.FloorsRejectNoConversionRate)
+				message = fmt.Sprintf("bid %s dropped because the floor of imp %s can't be converted from %s to %s", bid.bid.ID, imp.ID, floorCur, seatBid.currency)
+				statusCode = openrtb_ext.NonBidResponseRejectedGeneral
+			} else if floor := imp.BidFloor * rate * adjustmentFactor; bid.bid.Price < floor {
+				me.RecordFloorsRejectedBid(coreBidder, metrics.FloorsRejectBelowFloor)
+				message = fmt.Sprintf("bid %s dropped because its price %.4f %s is below the floor %.4f %s of imp %s", bid.bid.ID, bid.bid.Price, seatBid.currency, floor, seatBid.currency, imp.ID)
+				statusCode = openrtb_ext.NonBidResponseRejectedBelowFloor
+			} else {
+				eligibleBids = append(eligibleBids, bid)
+				continue
+			}
+
+			if extra, ok := adapterExtra[bidderName]; ok {
+				extra.Warnings = append(extra.Warnings, openrtb_ext.ExtBidderMessage{
+					Code:    errortypes.BidBelowFloorWarningCode,
+					Message: message,
+				})
+				extra.NonBids = append(extra.NonBids, makeNonBid(bid, statusCode))
+			}
+		}
+
+		if len(eligibleBids) == 0 {
+			delete(adapterBids, bidderName)
+			continue
+		}
+		seatBid.bids = eligibleBids
+		bidsFound = true
+	}
+	return bidsFound
+}
+
+// getFloorAdjustmentFactor looks up the adjustment factor of a bidder by its name, falling back to its core bidder for aliases.
+func getFloorAdjustmentFactor(adjustmentFactors map[string]float64, bidderName, coreBidder openrtb_ext.BidderName) float64 {
+	if factor, ok := adjustmentFactors[string(bidderName)]; ok && factor > 0 {
+		return factor
+	}
+	if factor, ok := adjustmentFactors[string(coreBidder)]; ok && factor > 0 {
+		return factor
+	}
+	return 1.0
+}
+
+// applyFetchedFloors merges the fetched floors of the account into the imps of the request. The highest of the fetched
+// floor and imp.bidfloor wins, so a publisher can still raise the floor of a single request. An imp floor which can't
+// be converted to the currency of the fetched floors is left as is.
+func applyFetchedFloors(bidRequest *openrtb2.BidRequest, rules *floors.Rules, conversions currency.Conversions) {
+	for i := range bidRequest.Imp {
+		imp := &bidRequest.Imp[i]
+		floor, ok := rules.Floor(bidRequest, imp)
+		if !ok {
+			continue
+		}
+
+		if imp.BidFloor > 0 {
+			impCur := imp.BidFloorCur
+			if impCur == "" {
+				impCur = "USD"
+			}
+			rate, err := conversions.GetRate(impCur, rules.Currency)
+			if err != nil || imp.BidFloor*rate >= floor {
+				continue
+			}
+		}
+		imp.BidFloor = floor
+		imp.BidFloorCur = rules.Currency
+	}
+}
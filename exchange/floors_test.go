@@ -0,0 +1,206 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/floors"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEnforceFloors(t *testing.T) {
+	bidRequest := &openrtb2.BidRequest{
+		Imp: []openrtb2.Imp{
+			{ID: "usd-imp", BidFloor: 1.0},
+			{ID: "eur-imp", BidFloor: 1.0, BidFloorCur: "EUR"},
+			{ID: "jpy-imp", BidFloor: 100, BidFloorCur: "JPY"},
+			{ID: "no-floor-imp"},
+		},
+	}
+	conversions := currency.NewRates(map[string]map[string]float64{
+		"EUR": {"USD": 1.2},
+	})
+
+	testCases := []struct {
+		description        string
+		givenAdapterBids   map[openrtb_ext.BidderName]*pbsOrtbSeatBid
+		floorsConfig       config.AccountPriceFloors
+		expectedBidIDs     map[openrtb_ext.BidderName][]string
+		expectedWarnings   map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage
+		expectedNonBids    map[openrtb_ext.BidderName][]openrtb_ext.NonBidStatusCode
+		expectedRejections map[metrics.FloorsRejectReason]int
+		expectedBidsFound  bool
+	}{
+		{
+			description: "Bids at or above the floor are kept",
+			givenAdapterBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+				"appnexus": {currency: "USD", bids: []*pbsOrtbBid{
+					{bid: &openrtb2.Bid{ID: "at-floor", ImpID: "usd-imp", Price: 1.0}},
+					{bid: &openrtb2.Bid{ID: "no-floor", ImpID: "no-floor-imp", Price: 0.01}},
+				}},
+			},
+			expectedBidIDs:    map[openrtb_ext.BidderName][]string{"appnexus": {"at-floor", "no-floor"}},
+			expectedWarnings:  map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage{},
+			expectedNonBids:   map[openrtb_ext.BidderName][]openrtb_ext.NonBidStatusCode{},
+			expectedBidsFound: true,
+		},
+		{
+			description: "Floor is converted to the seat currency",
+			givenAdapterBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+				"appnexus": {currency: "USD", bids: []*pbsOrtbBid{
+					{bid: &openrtb2.Bid{ID: "above-floor", ImpID: "eur-imp", Price: 1.25}},
+					{bid: &openrtb2.Bid{ID: "below-floor", ImpID: "eur-imp", Price: 1.1}},
+				}},
+			},
+			expectedBidIDs: map[openrtb_ext.BidderName][]string{"appnexus": {"above-floor"}},
+			expectedWarnings: map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage{
+				"appnexus": {{
+					Code:    errortypes.BidBelowFloorWarningCode,
+					Message: "bid below-floor dropped because its price 1.1000 USD is below the floor 1.2000 USD of imp eur-imp",
+				}},
+			},
+			expectedNonBids: map[openrtb_ext.BidderName][]openrtb_ext.NonBidStatusCode{
+				"appnexus": {openrtb_ext.NonBidResponseRejectedBelowFloor},
+			},
+			expectedRejections: map[metrics.FloorsRejectReason]int{metrics.FloorsRejectBelowFloor: 1},
+			expectedBidsFound:  true,
+		},
+		{
+			description: "Floor without conversion rate",
+			givenAdapterBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+				"appnexus": {currency: "USD", bids: []*pbsOrtbBid{
+					{bid: &openrtb2.Bid{ID: "jpy-bid", ImpID: "jpy-imp", Price: 10}},
+				}},
+			},
+			expectedBidIDs: map[openrtb_ext.BidderName][]string{},
+			expectedWarnings: map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage{
+				"appnexus": {{
+					Code:    errortypes.BidBelowFloorWarningCode,
+					Message: "bid jpy-bid dropped because the floor of imp jpy-imp can't be converted from JPY to USD",
+				}},
+			},
+			expectedNonBids: map[openrtb_ext.BidderName][]openrtb_ext.NonBidStatusCode{
+				"appnexus": {openrtb_ext.NonBidResponseRejectedGeneral},
+			},
+			expectedRejections: map[metrics.FloorsRejectReason]int{metrics.FloorsRejectNoConversionRate: 1},
+			expectedBidsFound:  false,
+		},
+		{
+			description: "Bidder adjustment factor",
+			givenAdapterBids: map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+				"appnexus": {currency: "USD", bids: []*pbsOrtbBid{
+					{bid: &openrtb2.Bid{ID: "appnexus-bid", ImpID: "usd-imp", Price: 1.2}},
+				}},
+				"rubicon": {currency: "USD", bids: []*pbsOrtbBid{
+					{bid: &openrtb2.Bid{ID: "rubicon-bid", ImpID: "usd-imp", Price: 1.2}},
+				}},
+			},
+			floorsConfig:   config.AccountPriceFloors{AdjustmentFactors: map[string]float64{"rubicon": 1.5}},
+			expectedBidIDs: map[openrtb_ext.BidderName][]string{"appnexus": {"appnexus-bid"}},
+			expectedWarnings: map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage{
+				"rubicon": {{
+					Code:    errortypes.BidBelowFloorWarningCode,
+					Message: "bid rubicon-bid dropped because its price 1.2000 USD is below the floor 1.5000 USD of imp usd-imp",
+				}},
+			},
+			expectedNonBids: map[openrtb_ext.BidderName][]openrtb_ext.NonBidStatusCode{
+				"rubicon": {openrtb_ext.NonBidResponseRejectedBelowFloor},
+			},
+			expectedRejections: map[metrics.FloorsRejectReason]int{metrics.FloorsRejectBelowFloor: 1},
+			expectedBidsFound:  true,
+		},
+	}
+
+	for _, test := range testCases {
+		adapterExtra := make(map[openrtb_ext.BidderName]*seatResponseExtra, len(test.givenAdapterBids))
+		for bidderName := range test.givenAdapterBids {
+			adapterExtra[bidderName] = &seatResponseExtra{}
+		}
+		metricsMock := &metrics.MetricsEngineMock{}
+		for reason, count := range test.expectedRejections {
+			metricsMock.On("RecordFloorsRejectedBid", mock.Anything, reason).Return().Times(count)
+		}
+
+		bidsFound := enforceFloors(bidRequest, test.givenAdapterBids, adapterExtra, nil, conversions, test.floorsConfig, metricsMock)
+
+		assert.Equal(t, test.expectedBidsFound, bidsFound, test.description+":bids_found")
+		actualBidIDs := make(map[openrtb_ext.BidderName][]string, len(test.givenAdapterBids))
+		for bidderName, seatBid := range test.givenAdapterBids {
+			for _, bid := range seatBid.bids {
+				actualBidIDs[bidderName] = append(actualBidIDs[bidderName], bid.bid.ID)
+			}
+		}
+		assert.Equal(t, test.expectedBidIDs, actualBidIDs, test.description+":bids")
+		actualWarnings := make(map[openrtb_ext.BidderName][]openrtb_ext.ExtBidderMessage)
+		for bidderName, extra := range adapterExtra {
+			if len(extra.Warnings) > 0 {
+				actualWarnings[bidderName] = extra.Warnings
+			}
+		}
+		assert.Equal(t, test.expectedWarnings, actualWarnings, test.description+":warnings")
+		actualNonBids := make(map[openrtb_ext.BidderName][]openrtb_ext.NonBidStatusCode)
+		for bidderName, extra := range adapterExtra {
+			for _, nonBid := range extra.NonBids {
+				actualNonBids[bidderName] = append(actualNonBids[bidderName], nonBid.StatusCode)
+			}
+		}
+		assert.Equal(t, test.expectedNonBids, actualNonBids, test.description+":non_bids")
+		metricsMock.AssertExpectations(t)
+	}
+}
+
+func TestGetFloorAdjustmentFactor(t *testing.T) {
+	adjustmentFactors := map[string]float64{"appnexus": 1.1, "appnexusAlias": 1.2, "rubicon": -1}
+
+	assert.Equal(t, 1.1, getFloorAdjustmentFactor(adjustmentFactors, "appnexus", "appnexus"), "core bidder")
+	assert.Equal(t, 1.2, getFloorAdjustmentFactor(adjustmentFactors, "appnexusAlias", "appnexus"), "alias")
+	assert.Equal(t, 1.1, getFloorAdjustmentFactor(adjustmentFactors, "otherAlias", "appnexus"), "alias without factor")
+	assert.Equal(t, 1.0, getFloorAdjustmentFactor(adjustmentFactors, "rubicon", "rubicon"), "invalid factor")
+	assert.Equal(t, 1.0, getFloorAdjustmentFactor(nil, "appnexus", "appnexus"), "no factors")
+}
+
+func TestApplyFetchedFloors(t *testing.T) {
+	rules := &floors.Rules{
+		Currency: "EUR",
+		Schema:   floors.Schema{Fields: []string{"mediaType"}},
+		Values:   map[string]float64{"banner": 1.0},
+	}
+	assert.NoError(t, rules.Validate())
+	conversions := currency.NewRates(map[string]map[string]float64{
+		"USD": {"EUR": 0.8},
+	})
+
+	bidRequest := &openrtb2.BidRequest{
+		Imp: []openrtb2.Imp{
+			{ID: "no-floor", Banner: &openrtb2.Banner{}},
+			{ID: "lower-floor", Banner: &openrtb2.Banner{}, BidFloor: 1.2},
+			{ID: "higher-floor", Banner: &openrtb2.Banner{}, BidFloor: 1.5},
+			{ID: "unconvertible-floor", Banner: &openrtb2.Banner{}, BidFloor: 0.5, BidFloorCur: "JPY"},
+			{ID: "no-rule", Video: &openrtb2.Video{}, BidFloor: 0.5},
+		},
+	}
+
+	applyFetchedFloors(bidRequest, rules, conversions)
+
+	expectedFloors := []struct {
+		floor    float64
+		currency string
+	}{
+		{1.0, "EUR"},
+		{1.0, "EUR"},
+		{1.5, ""},
+		{0.5, "JPY"},
+		{0.5, ""},
+	}
+	for i, expected := range expectedFloors {
+		imp := bidRequest.Imp[i]
+		assert.Equal(t, expected.floor, imp.BidFloor, imp.ID+":bidfloor")
+		assert.Equal(t, expected.currency, imp.BidFloorCur, imp.ID+":bidfloorcur")
+	}
+}
@@ -5,10 +5,19 @@ import (
 
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/privacy/gpp"
 )
 
-// ExtractGDPR will pull the gdpr flag from an openrtb request
-func extractGDPR(bidRequest *openrtb2.BidRequest) (gdpr.Signal, error) {
+// ExtractGDPR will pull the gdpr flag from an openrtb request. The gpp_sid of the GPP policy takes precedence over the
+// gdpr flag when it is present.
+func extractGDPR(bidRequest *openrtb2.BidRequest, gppPolicy gpp.ParsedPolicy) (gdpr.Signal, error) {
+	if applies, signaled := gppPolicy.GDPRSignal(); signaled {
+		if applies {
+			return gdpr.SignalYes, nil
+		}
+		return gdpr.SignalNo, nil
+	}
+
 	var re regsExt
 	var err error
 	if bidRequest.Regs != nil && bidRequest.Regs.Ext != nil {
@@ -20,8 +29,13 @@ func extractGDPR(bidRequest *openrtb2.BidRequest) (gdpr.Signal, error) {
 	return gdpr.Signal(*re.GDPR), nil
 }
 
-// ExtractConsent will pull the consent string from an openrtb request
-func extractConsent(bidRequest *openrtb2.BidRequest) (consent string, err error) {
+// ExtractConsent will pull the consent string from an openrtb request. The TCF EU v2 section of the GPP policy takes
+// precedence over user.ext.consent when it is present.
+func extractConsent(bidRequest *openrtb2.BidRequest, gppPolicy gpp.ParsedPolicy) (consent string, err error) {
+	if tcfConsent, ok := gppPolicy.TCFConsent(); ok {
+		return tcfConsent, nil
+	}
+
 	var ue userExt
 	if bidRequest.User != nil && bidRequest.User.Ext != nil {
 		err = json.Unmarshal(bidRequest.User.Ext, &ue)
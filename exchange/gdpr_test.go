@@ -6,6 +6,7 @@ import (
 
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/privacy/gpp"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -13,6 +14,7 @@ func TestExtractGDPR(t *testing.T) {
 	tests := []struct {
 		description string
 		giveRegs    *openrtb2.Regs
+		giveGPP     gpp.ParsedPolicy
 		wantGDPR    gdpr.Signal
 		wantError   bool
 	}{
@@ -47,6 +49,18 @@ func TestExtractGDPR(t *testing.T) {
 			wantGDPR:    gdpr.SignalAmbiguous,
 			wantError:   true,
 		},
+		{
+			description: "GPP section ids list TCF EU v2",
+			giveRegs:    &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr": 0}`)},
+			giveGPP:     gpp.ParsedPolicy{SectionIDs: []gpp.SectionID{gpp.SectionTCFEUV2}},
+			wantGDPR:    gdpr.SignalYes,
+		},
+		{
+			description: "GPP section ids don't list TCF EU v2",
+			giveRegs:    &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr": 1}`)},
+			giveGPP:     gpp.ParsedPolicy{SectionIDs: []gpp.SectionID{gpp.SectionUSPV1}},
+			wantGDPR:    gdpr.SignalNo,
+		},
 	}
 
 	for _, tt := range tests {
@@ -54,7 +68,7 @@ func TestExtractGDPR(t *testing.T) {
 			Regs: tt.giveRegs,
 		}
 
-		result, err := extractGDPR(&bidReq)
+		result, err := extractGDPR(&bidReq, tt.giveGPP)
 		assert.Equal(t, tt.wantGDPR, result, tt.description)
 
 		if tt.wantError {
@@ -69,6 +83,7 @@ func TestExtractConsent(t *testing.T) {
 	tests := []struct {
 		description string
 		giveUser    *openrtb2.User
+		giveGPP     gpp.ParsedPolicy
 		wantConsent string
 		wantError   bool
 	}{
@@ -98,6 +113,27 @@ func TestExtractConsent(t *testing.T) {
 			wantConsent: "",
 			wantError:   true,
 		},
+		{
+			description: "GPP TCF EU v2 section",
+			giveUser:    &openrtb2.User{Ext: json.RawMessage(`{"consent": "BOS2bx5OS2bx5ABABBAAABoAAAAAFA"}`)},
+			giveGPP: gpp.ParsedPolicy{GPP: gpp.GPP{
+				SectionIDs: []gpp.SectionID{gpp.SectionTCFEUV2},
+				Sections:   map[gpp.SectionID]gpp.Section{gpp.SectionTCFEUV2: {ID: gpp.SectionTCFEUV2, Value: "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"}},
+			}},
+			wantConsent: "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA",
+		},
+		{
+			description: "GPP TCF EU v2 section left out by the section ids",
+			giveUser:    &openrtb2.User{Ext: json.RawMessage(`{"consent": "BOS2bx5OS2bx5ABABBAAABoAAAAAFA"}`)},
+			giveGPP: gpp.ParsedPolicy{
+				GPP: gpp.GPP{
+					SectionIDs: []gpp.SectionID{gpp.SectionTCFEUV2},
+					Sections:   map[gpp.SectionID]gpp.Section{gpp.SectionTCFEUV2: {ID: gpp.SectionTCFEUV2, Value: "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"}},
+				},
+				SectionIDs: []gpp.SectionID{gpp.SectionUSPV1},
+			},
+			wantConsent: "BOS2bx5OS2bx5ABABBAAABoAAAAAFA",
+		},
 	}
 
 	for _, tt := range tests {
@@ -105,7 +141,7 @@ func TestExtractConsent(t *testing.T) {
 			User: tt.giveUser,
 		}
 
-		result, err := extractConsent(&bidReq)
+		result, err := extractConsent(&bidReq, tt.giveGPP)
 		assert.Equal(t, tt.wantConsent, result, tt.description)
 
 		if tt.wantError {
@@ -0,0 +1,126 @@
+package exchange
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// maxMultiBids is the most bids a bidder may return per imp through ext.prebid.multibid
+const maxMultiBids = 9
+
+// multiBid is the multibid configuration of a bidder. The extra bids of the bidder on an imp are targeted with the
+// targetBidderCodePrefix followed by their rank by price, starting at 2 since the top bid keeps the bidder code. They
+// get no targeting keys without a targetBidderCodePrefix.
+type multiBid struct {
+	maxBids                int
+	targetBidderCodePrefix string
+}
+
+// getMultiBid reads ext.prebid.multibid by bidder. Invalid entries are dropped with a warning, and out of range
+// maxbids are brought back within 1 and maxMultiBids.
+func getMultiBid(extMultiBids []*openrtb_ext.ExtMultiBid) (map[openrtb_ext.BidderName]multiBid, []error) {
+	var warnings []error
+	multiBids := make(map[openrtb_ext.BidderName]multiBid)
+
+	for _, extMultiBid := range extMultiBids {
+		if extMultiBid == nil {
+			continue
+		}
+		if extMultiBid.MaxBids == nil {
+			warnings = append(warnings, multiBidWarning("maxbids isn't set for the multibid of %s, it is ignored", multiBidBidders(extMultiBid)))
+			continue
+		}
+
+		maxBids := *extMultiBid.MaxBids
+		if maxBids < 1 {
+			warnings = append(warnings, multiBidWarning("maxbids of the multibid of %s must be at least 1, it was raised to 1", multiBidBidders(extMultiBid)))
+			maxBids = 1
+		} else if maxBids > maxMultiBids {
+			warnings = append(warnings, multiBidWarning("maxbids of the multibid of %s must be at most %d, it was lowered to %d", multiBidBidders(extMultiBid), maxMultiBids, maxMultiBids))
+			maxBids = maxMultiBids
+		}
+
+		bidders := extMultiBid.Bidders
+		prefix := extMultiBid.TargetBidderCodePrefix
+		if extMultiBid.Bidder != "" {
+			if len(extMultiBid.Bidders) > 0 {
+				warnings = append(warnings, multiBidWarning("the multibid of %s sets both bidder and bidders, bidders are ignored", extMultiBid.Bidder))
+			}
+			bidders = []string{extMultiBid.Bidder}
+		} else if prefix != "" {
+			warnings = append(warnings, multiBidWarning("targetbiddercodeprefix applies to a single bidder, it is ignored for the multibid of %v", extMultiBid.Bidders))
+			prefix = ""
+		}
+
+		for _, bidder := range bidders {
+			if _, ok := multiBids[openrtb_ext.BidderName(bidder)]; ok {
+				warnings = append(warnings, multiBidWarning("multibid is set more than once for %s, only the first one is used", bidder))
+				continue
+			}
+			multiBids[openrtb_ext.BidderName(bidder)] = multiBid{maxBids: maxBids, targetBidderCodePrefix: prefix}
+		}
+	}
+	return multiBids, warnings
+}
+
+func multiBidBidders(extMultiBid *openrtb_ext.ExtMultiBid) string {
+	if extMultiBid.Bidder != "" {
+		return extMultiBid.Bidder
+	}
+	return fmt.Sprintf("%v", extMultiBid.Bidders)
+}
+
+func multiBidWarning(format string, args ...interface{}) error {
+	return &errortypes.Warning{
+		Message:     fmt.Sprintf(format, args...),
+		WarningCode: errortypes.MultiBidWarningCode,
+	}
+}
+
+// applyMultiBid keeps the maxbids best priced bids of the multibid bidders on each imp, and sets the target bidder code
+// of their extra bids when the bidder has a prefix. The order of the kept bids is left as is.
+func applyMultiBid(adapterBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, adapterExtra map[openrtb_ext.BidderName]*seatResponseExtra, multiBids map[openrtb_ext.BidderName]multiBid) {
+	for bidderName, seatBid := range adapterBids {
+		bidderMultiBid, ok := multiBids[bidderName]
+		if !ok || seatBid == nil {
+			continue
+		}
+		prefix := bidderMultiBid.targetBidderCodePrefix
+
+		bidsByImp := make(map[string][]*pbsOrtbBid)
+		for _, bid := range seatBid.bids {
+			bidsByImp[bid.bid.ImpID] = append(bidsByImp[bid.bid.ImpID], bid)
+		}
+
+		dropped := make(map[*pbsOrtbBid]bool)
+		for _, impBids := range bidsByImp {
+			sort.SliceStable(impBids, func(i, j int) bool {
+				return impBids[i].bid.Price > impBids[j].bid.Price
+			})
+			for rank, bid := range impBids {
+				if rank >= bidderMultiBid.maxBids {
+					dropped[bid] = true
+				} else if rank > 0 && prefix != "" {
+					bid.targetBidderCode = prefix + strconv.Itoa(rank+1)
+				}
+			}
+		}
+		if len(dropped) == 0 {
+			continue
+		}
+
+		keptBids := make([]*pbsOrtbBid, 0, len(seatBid.bids)-len(dropped))
+		for _, bid := range seatBid.bids {
+			if !dropped[bid] {
+				keptBids = append(keptBids, bid)
+			} else if extra, ok := adapterExtra[bidderName]; ok {
+				extra.NonBids = append(extra.NonBids, makeNonBid(bid, openrtb_ext.NonBidResponseRejectedGeneral))
+			}
+		}
+		seatBid.bids = keptBids
+	}
+}
@@ -0,0 +1,155 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetMultiBid(t *testing.T) {
+	maxBids := func(maxBids int) *int { return &maxBids }
+
+	testCases := []struct {
+		description       string
+		givenMultiBids    []*openrtb_ext.ExtMultiBid
+		expectedMultiBids map[openrtb_ext.BidderName]multiBid
+		expectedWarnings  []string
+	}{
+		{
+			description:       "No multibid",
+			expectedMultiBids: map[openrtb_ext.BidderName]multiBid{},
+		},
+		{
+			description: "Single bidder and several bidders",
+			givenMultiBids: []*openrtb_ext.ExtMultiBid{
+				{Bidder: "appnexus", MaxBids: maxBids(3), TargetBidderCodePrefix: "apn"},
+				{Bidders: []string{"rubicon", "openx"}, MaxBids: maxBids(2)},
+			},
+			expectedMultiBids: map[openrtb_ext.BidderName]multiBid{
+				"appnexus": {maxBids: 3, targetBidderCodePrefix: "apn"},
+				"rubicon":  {maxBids: 2},
+				"openx":    {maxBids: 2},
+			},
+		},
+		{
+			description: "Invalid entries",
+			givenMultiBids: []*openrtb_ext.ExtMultiBid{
+				{Bidder: "appnexus"},
+				{Bidder: "rubicon", Bidders: []string{"openx"}, MaxBids: maxBids(20)},
+				{Bidders: []string{"openx", "rubicon"}, MaxBids: maxBids(0), TargetBidderCodePrefix: "prefix"},
+			},
+			expectedMultiBids: map[openrtb_ext.BidderName]multiBid{
+				"rubicon": {maxBids: 9},
+				"openx":   {maxBids: 1},
+			},
+			expectedWarnings: []string{
+				"maxbids isn't set for the multibid of appnexus, it is ignored",
+				"maxbids of the multibid of rubicon must be at most 9, it was lowered to 9",
+				"the multibid of rubicon sets both bidder and bidders, bidders are ignored",
+				"maxbids of the multibid of [openx rubicon] must be at least 1, it was raised to 1",
+				"targetbiddercodeprefix applies to a single bidder, it is ignored for the multibid of [openx rubicon]",
+				"multibid is set more than once for rubicon, only the first one is used",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		multiBids, warnings := getMultiBid(test.givenMultiBids)
+
+		assert.Equal(t, test.expectedMultiBids, multiBids, test.description)
+		actualWarnings := make([]string, 0, len(warnings))
+		for _, warning := range warnings {
+			actualWarnings = append(actualWarnings, warning.Error())
+		}
+		assert.ElementsMatch(t, test.expectedWarnings, actualWarnings, test.description)
+	}
+}
+
+func TestApplyMultiBid(t *testing.T) {
+	bid := func(id, impID string, price float64) *pbsOrtbBid {
+		return &pbsOrtbBid{bid: &openrtb2.Bid{ID: id, ImpID: impID, Price: price}, bidType: openrtb_ext.BidTypeBanner}
+	}
+	adapterBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		"appnexus": {bids: []*pbsOrtbBid{
+			bid("apn-1", "imp1", 1),
+			bid("apn-3", "imp1", 3),
+			bid("apn-2", "imp1", 2),
+			bid("apn-4", "imp2", 4),
+		}},
+		"rubicon": {bids: []*pbsOrtbBid{
+			bid("rubicon-1", "imp1", 1),
+			bid("rubicon-2", "imp1", 2),
+		}},
+		"openx": {bids: []*pbsOrtbBid{
+			bid("openx-1", "imp1", 1),
+			bid("openx-2", "imp1", 2),
+		}},
+	}
+	adapterExtra := map[openrtb_ext.BidderName]*seatResponseExtra{"appnexus": {}, "rubicon": {}, "openx": {}}
+	multiBids := map[openrtb_ext.BidderName]multiBid{
+		"appnexus": {maxBids: 2, targetBidderCodePrefix: "apn"},
+		"rubicon":  {maxBids: 3},
+	}
+
+	applyMultiBid(adapterBids, adapterExtra, multiBids)
+
+	bidIDs := func(bids []*pbsOrtbBid) map[string]string {
+		targetBidderCodes := make(map[string]string, len(bids))
+		for _, bid := range bids {
+			targetBidderCodes[bid.bid.ID] = bid.targetBidderCode
+		}
+		return targetBidderCodes
+	}
+	assert.Equal(t, map[string]string{"apn-3": "", "apn-2": "apn2", "apn-4": ""}, bidIDs(adapterBids["appnexus"].bids), "maxbids best priced bids are kept")
+	if assert.Len(t, adapterExtra["appnexus"].NonBids, 1) {
+		assert.Equal(t, openrtb_ext.NonBidResponseRejectedGeneral, adapterExtra["appnexus"].NonBids[0].StatusCode)
+	}
+	assert.Equal(t, map[string]string{"rubicon-1": "", "rubicon-2": ""}, bidIDs(adapterBids["rubicon"].bids), "the extra bids get no target bidder code without a prefix")
+	assert.Empty(t, adapterExtra["rubicon"].NonBids)
+	assert.Equal(t, map[string]string{"openx-1": "", "openx-2": ""}, bidIDs(adapterBids["openx"].bids), "bidders without multibid are left as is")
+}
+
+func TestMultiBidTargetingAndCache(t *testing.T) {
+	apnTop := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "apn-top", ImpID: "imp1", Price: 3}, bidType: openrtb_ext.BidTypeBanner}
+	apnExtra := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "apn-extra", ImpID: "imp1", Price: 2}, bidType: openrtb_ext.BidTypeBanner, targetBidderCode: "apn2"}
+	rubiconTop := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "rubicon-top", ImpID: "imp1", Price: 1}, bidType: openrtb_ext.BidTypeBanner}
+	rubiconExtra := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "rubicon-extra", ImpID: "imp1", Price: 0.5}, bidType: openrtb_ext.BidTypeBanner}
+	adapterBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		"appnexus": {bids: []*pbsOrtbBid{apnExtra, apnTop}},
+		"rubicon":  {bids: []*pbsOrtbBid{rubiconTop, rubiconExtra}},
+	}
+	targData := &targetData{
+		priceGranularity:  openrtb_ext.PriceGranularityFromString("med"),
+		includeWinners:    true,
+		includeBidderKeys: true,
+		includeCacheBids:  true,
+	}
+
+	auc := newAuction(adapterBids, 1, false)
+	auc.setRoundedPrices(targData)
+	cache := &mockCache{}
+	errs := auc.doCache(context.Background(), cache, targData, &eventTracking{}, &openrtb2.BidRequest{Imp: []openrtb2.Imp{{ID: "imp1"}}}, 60, &config.DefaultTTLs{}, nil, nil)
+	targData.setTargeting(auc, false, nil)
+
+	assert.Empty(t, errs)
+	assert.Len(t, cache.items, 3, "the extra bids with a target bidder code are cached")
+	assert.Equal(t, map[string]string{
+		"hb_pb":              "3.00",
+		"hb_bidder":          "appnexus",
+		"hb_pb_appnexus":     "3.00",
+		"hb_bidder_appnexus": "appnexus",
+	}, apnTop.bidTargets)
+	assert.Equal(t, map[string]string{
+		"hb_pb_apn2":     "2.00",
+		"hb_bidder_apn2": "apn2",
+	}, apnExtra.bidTargets, "the extra bids are targeted with their target bidder code")
+	assert.Equal(t, map[string]string{
+		"hb_pb_rubicon":     "1.00",
+		"hb_bidder_rubicon": "rubicon",
+	}, rubiconTop.bidTargets)
+	assert.Empty(t, rubiconExtra.bidTargets, "the extra bids without a target bidder code aren't targeted")
+}
@@ -0,0 +1,88 @@
+package exchange
+
+import (
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// applyPreferredMediaTypes keeps only the preferred media type of the multi-format imps sent to the bidders which
+// don't support them, so that they get an imp they can bid on. The request.ext.prebid.biddercontrols of the request
+// win over the preferred media types of the account. The imps without the preferred media type, or the imps of the
+// bidders without one, are sent as they are.
+func applyPreferredMediaTypes(bidderRequests []BidderRequest, requestExt *openrtb_ext.ExtRequest, account *config.Account, bidderInfos config.BidderInfos, me metrics.MetricsEngine) {
+	for _, bidderRequest := range bidderRequests {
+		info, ok := bidderInfos[string(bidderRequest.BidderCoreName)]
+		if !ok || info.SupportsMultiFormat() {
+			continue
+		}
+
+		mediaType := preferredMediaType(bidderRequest.BidderName, requestExt, account)
+		if mediaType == "" {
+			continue
+		}
+
+		for i := range bidderRequest.BidRequest.Imp {
+			for _, filtered := range keepMediaType(&bidderRequest.BidRequest.Imp[i], mediaType) {
+				me.RecordAdapterMediaTypeFiltered(bidderRequest.BidderCoreName, filtered)
+			}
+		}
+	}
+}
+
+// preferredMediaType returns the media type the bidder prefers in the multi-format imps, if any
+func preferredMediaType(bidder openrtb_ext.BidderName, requestExt *openrtb_ext.ExtRequest, account *config.Account) openrtb_ext.BidType {
+	if requestExt != nil {
+		if control, ok := requestExt.Prebid.BidderControls[bidder.String()]; ok && control.PreferredMediaType != "" {
+			return control.PreferredMediaType
+		}
+	}
+	if account != nil {
+		return account.PreferredMediaType[bidder.String()]
+	}
+	return ""
+}
+
+// keepMediaType removes the other media types of the imp if it is a multi-format imp of the media type, and returns
+// the media types removed
+func keepMediaType(imp *openrtb2.Imp, mediaType openrtb_ext.BidType) []metrics.ImpMediaType {
+	mediaTypes := 0
+	hasMediaType := false
+	for _, present := range []struct {
+		mediaType openrtb_ext.BidType
+		present   bool
+	}{
+		{openrtb_ext.BidTypeBanner, imp.Banner != nil},
+		{openrtb_ext.BidTypeVideo, imp.Video != nil},
+		{openrtb_ext.BidTypeAudio, imp.Audio != nil},
+		{openrtb_ext.BidTypeNative, imp.Native != nil},
+	} {
+		if present.present {
+			mediaTypes++
+			hasMediaType = hasMediaType || present.mediaType == mediaType
+		}
+	}
+	if mediaTypes < 2 || !hasMediaType {
+		return nil
+	}
+
+	var filtered []metrics.ImpMediaType
+	if imp.Banner != nil && mediaType != openrtb_ext.BidTypeBanner {
+		imp.Banner = nil
+		filtered = append(filtered, metrics.ImpTypeBanner)
+	}
+	if imp.Video != nil && mediaType != openrtb_ext.BidTypeVideo {
+		imp.Video = nil
+		filtered = append(filtered, metrics.ImpTypeVideo)
+	}
+	if imp.Audio != nil && mediaType != openrtb_ext.BidTypeAudio {
+		imp.Audio = nil
+		filtered = append(filtered, metrics.ImpTypeAudio)
+	}
+	if imp.Native != nil && mediaType != openrtb_ext.BidTypeNative {
+		imp.Native = nil
+		filtered = append(filtered, metrics.ImpTypeNative)
+	}
+	return filtered
+}
@@ -0,0 +1,93 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyPreferredMediaTypes(t *testing.T) {
+	falseValue := false
+	multiFormatImp := openrtb2.Imp{ID: "multi", Banner: &openrtb2.Banner{}, Video: &openrtb2.Video{}, Native: &openrtb2.Native{}}
+	bannerImp := openrtb2.Imp{ID: "banner", Banner: &openrtb2.Banner{}}
+
+	testCases := []struct {
+		description      string
+		givenInfo        config.BidderInfo
+		givenRequestExt  *openrtb_ext.ExtRequest
+		givenAccount     *config.Account
+		givenImp         openrtb2.Imp
+		expectedImp      openrtb2.Imp
+		expectedFiltered []metrics.ImpMediaType
+	}{
+		{
+			description:      "Preferred media type of the account",
+			givenInfo:        config.BidderInfo{MultiFormatSupported: &falseValue},
+			givenAccount:     &config.Account{PreferredMediaType: map[string]openrtb_ext.BidType{"appnexus": openrtb_ext.BidTypeVideo}},
+			givenImp:         multiFormatImp,
+			expectedImp:      openrtb2.Imp{ID: "multi", Video: &openrtb2.Video{}},
+			expectedFiltered: []metrics.ImpMediaType{metrics.ImpTypeBanner, metrics.ImpTypeNative},
+		},
+		{
+			description: "Preferred media type of the request wins over the one of the account",
+			givenInfo:   config.BidderInfo{MultiFormatSupported: &falseValue},
+			givenRequestExt: &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+				BidderControls: map[string]openrtb_ext.ExtBidderControl{"appnexus": {PreferredMediaType: openrtb_ext.BidTypeNative}},
+			}},
+			givenAccount:     &config.Account{PreferredMediaType: map[string]openrtb_ext.BidType{"appnexus": openrtb_ext.BidTypeVideo}},
+			givenImp:         multiFormatImp,
+			expectedImp:      openrtb2.Imp{ID: "multi", Native: &openrtb2.Native{}},
+			expectedFiltered: []metrics.ImpMediaType{metrics.ImpTypeBanner, metrics.ImpTypeVideo},
+		},
+		{
+			description:  "Bidder supporting the multi-format imps",
+			givenInfo:    config.BidderInfo{},
+			givenAccount: &config.Account{PreferredMediaType: map[string]openrtb_ext.BidType{"appnexus": openrtb_ext.BidTypeVideo}},
+			givenImp:     multiFormatImp,
+			expectedImp:  multiFormatImp,
+		},
+		{
+			description:  "No preferred media type",
+			givenInfo:    config.BidderInfo{MultiFormatSupported: &falseValue},
+			givenAccount: &config.Account{},
+			givenImp:     multiFormatImp,
+			expectedImp:  multiFormatImp,
+		},
+		{
+			description:  "Preferred media type missing from the imp",
+			givenInfo:    config.BidderInfo{MultiFormatSupported: &falseValue},
+			givenAccount: &config.Account{PreferredMediaType: map[string]openrtb_ext.BidType{"appnexus": openrtb_ext.BidTypeAudio}},
+			givenImp:     multiFormatImp,
+			expectedImp:  multiFormatImp,
+		},
+		{
+			description:  "Single format imp",
+			givenInfo:    config.BidderInfo{MultiFormatSupported: &falseValue},
+			givenAccount: &config.Account{PreferredMediaType: map[string]openrtb_ext.BidType{"appnexus": openrtb_ext.BidTypeVideo}},
+			givenImp:     bannerImp,
+			expectedImp:  bannerImp,
+		},
+	}
+
+	for _, test := range testCases {
+		metricsMock := &metrics.MetricsEngineMock{}
+		for _, mediaType := range test.expectedFiltered {
+			metricsMock.On("RecordAdapterMediaTypeFiltered", openrtb_ext.BidderAppnexus, mediaType).Once()
+		}
+		bidderRequests := []BidderRequest{{
+			BidderName:     openrtb_ext.BidderAppnexus,
+			BidderCoreName: openrtb_ext.BidderAppnexus,
+			BidRequest:     &openrtb2.BidRequest{Imp: []openrtb2.Imp{test.givenImp}},
+		}}
+
+		applyPreferredMediaTypes(bidderRequests, test.givenRequestExt, test.givenAccount, config.BidderInfos{"appnexus": test.givenInfo}, metricsMock)
+
+		assert.Equal(t, []openrtb2.Imp{test.expectedImp}, bidderRequests[0].BidRequest.Imp, test.description)
+		metricsMock.AssertExpectations(t)
+		metricsMock.AssertNumberOfCalls(t, "RecordAdapterMediaTypeFiltered", len(test.expectedFiltered))
+	}
+}
@@ -14,12 +14,17 @@ func GetPriceBucket(cpm float64, config openrtb_ext.PriceGranularity) string {
 	increment := 0.0
 	precision := config.Precision
 
+	// Ranges are validated to be ordered with increasing max and not to overlap, so the last range holds
+	// the overall max and no range past the first one starting above the cpm can contain it.
+	if len(config.Ranges) > 0 {
+		bucketMax = config.Ranges[len(config.Ranges)-1].Max
+	}
 	for i := 0; i < len(config.Ranges); i++ {
-		if config.Ranges[i].Max > bucketMax {
-			bucketMax = config.Ranges[i].Max
+		if cpm < config.Ranges[i].Min {
+			break
 		}
 		// find what range cpm is in
-		if cpm >= config.Ranges[i].Min && cpm <= config.Ranges[i].Max {
+		if cpm <= config.Ranges[i].Max {
 			increment = config.Ranges[i].Increment
 		}
 	}
@@ -107,6 +107,17 @@ func TestGetPriceBucketString(t *testing.T) {
 				},
 			},
 		},
+		{
+			groupDesc: "Cpm within a gap between custom ranges, return empty string since it does not belong into any range",
+			cpm:       5.5,
+			testCases: []aTest{
+				{
+					"custom with gap",
+					openrtb_ext.PriceGranularity{Precision: 2, Ranges: []openrtb_ext.GranularityRange{{Max: 5, Increment: 0.1}, {Min: 6, Max: 10, Increment: 0.5}}},
+					"",
+				},
+			},
+		},
 		{
 			groupDesc: "Negative Cpm, return empty string since it does not belong into any range",
 			cpm:       -1.876,
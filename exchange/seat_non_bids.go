@@ -0,0 +1,50 @@
+package exchange
+
+import (
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// makeImpNonBids returns a non bid with the status code for each of the imps, for the bidders which had no chance to bid
+// on them
+func makeImpNonBids(imps []openrtb2.Imp, statusCode openrtb_ext.NonBidStatusCode) []openrtb_ext.NonBid {
+	nonBids := make([]openrtb_ext.NonBid, 0, len(imps))
+	for _, imp := range imps {
+		nonBids = append(nonBids, openrtb_ext.NonBid{ImpId: imp.ID, StatusCode: statusCode})
+	}
+	return nonBids
+}
+
+// makeErrorNonBids returns the non bids of the imps left without a bid by a bidder which failed. The imps are timed
+// out if one of the errors is a timeout.
+func makeErrorNonBids(imps []openrtb2.Imp, bids *pbsOrtbSeatBid, errs []error) []openrtb_ext.NonBid {
+	fatalErrs := errortypes.FatalOnly(errs)
+	if len(fatalErrs) == 0 {
+		return nil
+	}
+
+	statusCode := openrtb_ext.NonBidErrorGeneral
+	for _, err := range fatalErrs {
+		if errortypes.ReadCode(err) == errortypes.TimeoutErrorCode {
+			statusCode = openrtb_ext.NonBidErrorTimedOut
+			break
+		}
+	}
+
+	bidImps := make(map[string]struct{})
+	if bids != nil {
+		for _, bid := range bids.bids {
+			if bid.bid != nil {
+				bidImps[bid.bid.ImpID] = struct{}{}
+			}
+		}
+	}
+	var nonBids []openrtb_ext.NonBid
+	for _, imp := range imps {
+		if _, ok := bidImps[imp.ID]; !ok {
+			nonBids = append(nonBids, openrtb_ext.NonBid{ImpId: imp.ID, StatusCode: statusCode})
+		}
+	}
+	return nonBids
+}
@@ -0,0 +1,76 @@
+package exchange
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakeImpNonBids(t *testing.T) {
+	imps := []openrtb2.Imp{{ID: "imp1"}, {ID: "imp2"}}
+
+	nonBids := makeImpNonBids(imps, openrtb_ext.NonBidRequestBlockedPrivacy)
+
+	assert.Equal(t, []openrtb_ext.NonBid{
+		{ImpId: "imp1", StatusCode: openrtb_ext.NonBidRequestBlockedPrivacy},
+		{ImpId: "imp2", StatusCode: openrtb_ext.NonBidRequestBlockedPrivacy},
+	}, nonBids)
+}
+
+func TestMakeErrorNonBids(t *testing.T) {
+	imps := []openrtb2.Imp{{ID: "imp1"}, {ID: "imp2"}}
+
+	testCases := []struct {
+		description     string
+		givenBids       *pbsOrtbSeatBid
+		givenErrs       []error
+		expectedNonBids []openrtb_ext.NonBid
+	}{
+		{
+			description: "No errors",
+			givenBids:   nil,
+			givenErrs:   nil,
+		},
+		{
+			description: "Warnings only",
+			givenBids:   nil,
+			givenErrs:   []error{&errortypes.Warning{Message: "warning"}},
+		},
+		{
+			description: "Error",
+			givenBids:   nil,
+			givenErrs:   []error{errors.New("failed")},
+			expectedNonBids: []openrtb_ext.NonBid{
+				{ImpId: "imp1", StatusCode: openrtb_ext.NonBidErrorGeneral},
+				{ImpId: "imp2", StatusCode: openrtb_ext.NonBidErrorGeneral},
+			},
+		},
+		{
+			description: "Timeout among the errors",
+			givenBids:   nil,
+			givenErrs:   []error{errors.New("failed"), &errortypes.Timeout{Message: "timed out"}},
+			expectedNonBids: []openrtb_ext.NonBid{
+				{ImpId: "imp1", StatusCode: openrtb_ext.NonBidErrorTimedOut},
+				{ImpId: "imp2", StatusCode: openrtb_ext.NonBidErrorTimedOut},
+			},
+		},
+		{
+			description: "Imps with a bid are left out",
+			givenBids:   &pbsOrtbSeatBid{bids: []*pbsOrtbBid{{bid: &openrtb2.Bid{ImpID: "imp1"}}}},
+			givenErrs:   []error{&errortypes.Timeout{Message: "timed out"}},
+			expectedNonBids: []openrtb_ext.NonBid{
+				{ImpId: "imp2", StatusCode: openrtb_ext.NonBidErrorTimedOut},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		nonBids := makeErrorNonBids(imps, test.givenBids, test.givenErrs)
+
+		assert.Equal(t, test.expectedNonBids, nonBids, test.description)
+	}
+}
@@ -0,0 +1,143 @@
+package exchange
+
+import (
+	"github.com/buger/jsonparser"
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+const (
+	storedResponseTypeAuction = "auction"
+	storedResponseTypeBid     = "bid"
+)
+
+// StoredResponse holds the seatbids of a stored response, which stand in for the live bids on an impression
+type StoredResponse struct {
+	ID       string
+	SeatBids []openrtb2.SeatBid
+}
+
+// removeStoredResponseImps drops the impressions answered by stored responses from the bidder requests, and the bidder
+// requests left without impressions. It returns the ids of the impressions each bidder has stored bid responses for.
+func removeStoredResponseImps(bidderRequests []BidderRequest, auctionResponses map[string]StoredResponse, bidResponses map[string]map[openrtb_ext.BidderName]StoredResponse) ([]BidderRequest, map[openrtb_ext.BidderName][]string) {
+	if len(auctionResponses) == 0 && len(bidResponses) == 0 {
+		return bidderRequests, nil
+	}
+
+	storedBidImps := make(map[openrtb_ext.BidderName][]string)
+	liveRequests := make([]BidderRequest, 0, len(bidderRequests))
+	for _, bidderRequest := range bidderRequests {
+		liveImps := make([]openrtb2.Imp, 0, len(bidderRequest.BidRequest.Imp))
+		for _, imp := range bidderRequest.BidRequest.Imp {
+			if _, ok := auctionResponses[imp.ID]; ok {
+				continue
+			}
+			if _, ok := bidResponses[imp.ID][bidderRequest.BidderName]; ok {
+				storedBidImps[bidderRequest.BidderName] = append(storedBidImps[bidderRequest.BidderName], imp.ID)
+				continue
+			}
+			liveImps = append(liveImps, imp)
+		}
+
+		if len(liveImps) == len(bidderRequest.BidRequest.Imp) {
+			liveRequests = append(liveRequests, bidderRequest)
+		} else if len(liveImps) > 0 {
+			liveRequest := *bidderRequest.BidRequest
+			liveRequest.Imp = liveImps
+			bidderRequest.BidRequest = &liveRequest
+			liveRequests = append(liveRequests, bidderRequest)
+		}
+	}
+	return liveRequests, storedBidImps
+}
+
+// mergeStoredResponses adds the bids of the stored responses to the seats of the bidders they are stored for. The
+// stored auction responses bid for the bidders named by their seats, and the stored bid responses for the bidders of
+// the impressions removed from the bidder requests. It returns the bidders given stored bids and whether there are any.
+func mergeStoredResponses(adapterBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid, adapterExtra map[openrtb_ext.BidderName]*seatResponseExtra, bidRequest *openrtb2.BidRequest, auctionResponses map[string]StoredResponse, bidResponses map[string]map[openrtb_ext.BidderName]StoredResponse, storedBidImps map[openrtb_ext.BidderName][]string) ([]openrtb_ext.BidderName, bool) {
+	if len(auctionResponses) == 0 && len(storedBidImps) == 0 {
+		return nil, false
+	}
+
+	imps := make(map[string]*openrtb2.Imp, len(bidRequest.Imp))
+	for i := range bidRequest.Imp {
+		imps[bidRequest.Imp[i].ID] = &bidRequest.Imp[i]
+	}
+
+	// Stored bids are priced in the currency of the auction, like the live bids once converted
+	currency := "USD"
+	if len(bidRequest.Cur) > 0 {
+		currency = bidRequest.Cur[0]
+	}
+
+	var storedBidders []openrtb_ext.BidderName
+	addBid := func(bidder openrtb_ext.BidderName, bid *pbsOrtbBid) {
+		seatBid, ok := adapterBids[bidder]
+		if !ok {
+			seatBid = &pbsOrtbSeatBid{currency: currency}
+			adapterBids[bidder] = seatBid
+		}
+		if _, ok := adapterExtra[bidder]; !ok {
+			adapterExtra[bidder] = &seatResponseExtra{}
+			storedBidders = append(storedBidders, bidder)
+		}
+		seatBid.bids = append(seatBid.bids, bid)
+	}
+
+	bidsFound := false
+	for impID, storedResponse := range auctionResponses {
+		imp, ok := imps[impID]
+		if !ok {
+			continue
+		}
+		storedResponseExt := &openrtb_ext.ExtBidPrebidStoredResponse{ID: storedResponse.ID, Type: storedResponseTypeAuction}
+		for _, seatBid := range storedResponse.SeatBids {
+			for _, bid := range seatBid.Bid {
+				addBid(openrtb_ext.BidderName(seatBid.Seat), makeStoredBid(bid, imp, storedResponseExt))
+				bidsFound = true
+			}
+		}
+	}
+	for bidder, impIDs := range storedBidImps {
+		for _, impID := range impIDs {
+			storedResponse := bidResponses[impID][bidder]
+			storedResponseExt := &openrtb_ext.ExtBidPrebidStoredResponse{ID: storedResponse.ID, Type: storedResponseTypeBid}
+			for _, seatBid := range storedResponse.SeatBids {
+				for _, bid := range seatBid.Bid {
+					addBid(bidder, makeStoredBid(bid, imps[impID], storedResponseExt))
+					bidsFound = true
+				}
+			}
+		}
+	}
+	return storedBidders, bidsFound
+}
+
+// makeStoredBid makes a bid of the impression out of a stored bid. Its type is read from bid.ext.prebid.type, or else
+// is the first media type of the impression.
+func makeStoredBid(bid openrtb2.Bid, imp *openrtb2.Imp, storedResponse *openrtb_ext.ExtBidPrebidStoredResponse) *pbsOrtbBid {
+	bid.ImpID = imp.ID
+
+	var bidType openrtb_ext.BidType
+	if value, err := jsonparser.GetString(bid.Ext, openrtb_ext.PrebidExtKey, "type"); err == nil {
+		bidType, _ = openrtb_ext.ParseBidType(value)
+	}
+	if bidType == "" {
+		switch {
+		case imp.Banner != nil:
+			bidType = openrtb_ext.BidTypeBanner
+		case imp.Video != nil:
+			bidType = openrtb_ext.BidTypeVideo
+		case imp.Audio != nil:
+			bidType = openrtb_ext.BidTypeAudio
+		case imp.Native != nil:
+			bidType = openrtb_ext.BidTypeNative
+		}
+	}
+
+	return &pbsOrtbBid{
+		bid:            &bid,
+		bidType:        bidType,
+		storedResponse: storedResponse,
+	}
+}
@@ -0,0 +1,140 @@
+package exchange
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveStoredResponseImps(t *testing.T) {
+	bidderRequests := []BidderRequest{
+		{
+			BidderName: "appnexus",
+			BidRequest: &openrtb2.BidRequest{ID: "req", Imp: []openrtb2.Imp{{ID: "imp-1"}, {ID: "imp-2"}, {ID: "imp-3"}}},
+		},
+		{
+			BidderName: "rubicon",
+			BidRequest: &openrtb2.BidRequest{ID: "req", Imp: []openrtb2.Imp{{ID: "imp-1"}, {ID: "imp-3"}}},
+		},
+		{
+			BidderName: "openx",
+			BidRequest: &openrtb2.BidRequest{ID: "req", Imp: []openrtb2.Imp{{ID: "imp-1"}}},
+		},
+	}
+	auctionResponses := map[string]StoredResponse{
+		"imp-1": {ID: "auction-resp"},
+	}
+	bidResponses := map[string]map[openrtb_ext.BidderName]StoredResponse{
+		"imp-2": {"appnexus": {ID: "bid-resp"}},
+		"imp-3": {"rubicon": {ID: "bid-resp"}},
+	}
+
+	liveRequests, storedBidImps := removeStoredResponseImps(bidderRequests, auctionResponses, bidResponses)
+
+	if assert.Len(t, liveRequests, 1) {
+		assert.Equal(t, openrtb_ext.BidderName("appnexus"), liveRequests[0].BidderName)
+		assert.Equal(t, []openrtb2.Imp{{ID: "imp-3"}}, liveRequests[0].BidRequest.Imp)
+	}
+	assert.Equal(t, map[openrtb_ext.BidderName][]string{"appnexus": {"imp-2"}, "rubicon": {"imp-3"}}, storedBidImps)
+	assert.Len(t, bidderRequests[0].BidRequest.Imp, 3, "The bidder requests must not be modified")
+}
+
+func TestRemoveStoredResponseImpsNone(t *testing.T) {
+	bidderRequests := []BidderRequest{
+		{
+			BidderName: "appnexus",
+			BidRequest: &openrtb2.BidRequest{ID: "req", Imp: []openrtb2.Imp{{ID: "imp-1"}}},
+		},
+	}
+
+	liveRequests, storedBidImps := removeStoredResponseImps(bidderRequests, nil, nil)
+
+	assert.Equal(t, bidderRequests, liveRequests)
+	assert.Nil(t, storedBidImps)
+}
+
+func TestMergeStoredResponses(t *testing.T) {
+	bidRequest := &openrtb2.BidRequest{
+		ID:  "req",
+		Cur: []string{"EUR"},
+		Imp: []openrtb2.Imp{
+			{ID: "imp-1", Banner: &openrtb2.Banner{}},
+			{ID: "imp-2", Video: &openrtb2.Video{}},
+		},
+	}
+	liveBid := &pbsOrtbBid{bid: &openrtb2.Bid{ID: "live", ImpID: "imp-3", Price: 1}, bidType: openrtb_ext.BidTypeBanner}
+	adapterBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		"appnexus": {bids: []*pbsOrtbBid{liveBid}, currency: "USD"},
+	}
+	adapterExtra := map[openrtb_ext.BidderName]*seatResponseExtra{
+		"appnexus": {ResponseTimeMillis: 5},
+	}
+	auctionResponses := map[string]StoredResponse{
+		"imp-1": {
+			ID: "auction-resp",
+			SeatBids: []openrtb2.SeatBid{
+				{Seat: "appnexus", Bid: []openrtb2.Bid{{ID: "auction-bid-1", ImpID: "other-imp", Price: 2}}},
+				{Seat: "rubicon", Bid: []openrtb2.Bid{{ID: "auction-bid-2", Price: 3, Ext: json.RawMessage(`{"prebid":{"type":"native"}}`)}}},
+			},
+		},
+	}
+	bidResponses := map[string]map[openrtb_ext.BidderName]StoredResponse{
+		"imp-2": {
+			"openx": {
+				ID: "bid-resp",
+				SeatBids: []openrtb2.SeatBid{
+					{Seat: "ignored", Bid: []openrtb2.Bid{{ID: "bid-bid", Price: 4}}},
+				},
+			},
+		},
+	}
+	storedBidImps := map[openrtb_ext.BidderName][]string{"openx": {"imp-2"}}
+
+	storedBidders, bidsFound := mergeStoredResponses(adapterBids, adapterExtra, bidRequest, auctionResponses, bidResponses, storedBidImps)
+
+	assert.True(t, bidsFound)
+	assert.ElementsMatch(t, []openrtb_ext.BidderName{"rubicon", "openx"}, storedBidders)
+
+	auctionResponse := &openrtb_ext.ExtBidPrebidStoredResponse{ID: "auction-resp", Type: "auction"}
+	bidResponse := &openrtb_ext.ExtBidPrebidStoredResponse{ID: "bid-resp", Type: "bid"}
+	expectedBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		"appnexus": {
+			bids: []*pbsOrtbBid{
+				liveBid,
+				{bid: &openrtb2.Bid{ID: "auction-bid-1", ImpID: "imp-1", Price: 2}, bidType: openrtb_ext.BidTypeBanner, storedResponse: auctionResponse},
+			},
+			currency: "USD",
+		},
+		"rubicon": {
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb2.Bid{ID: "auction-bid-2", ImpID: "imp-1", Price: 3, Ext: json.RawMessage(`{"prebid":{"type":"native"}}`)}, bidType: openrtb_ext.BidTypeNative, storedResponse: auctionResponse},
+			},
+			currency: "EUR",
+		},
+		"openx": {
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb2.Bid{ID: "bid-bid", ImpID: "imp-2", Price: 4}, bidType: openrtb_ext.BidTypeVideo, storedResponse: bidResponse},
+			},
+			currency: "EUR",
+		},
+	}
+	assert.Equal(t, expectedBids, adapterBids)
+	assert.Equal(t, &seatResponseExtra{ResponseTimeMillis: 5}, adapterExtra["appnexus"])
+	assert.Equal(t, &seatResponseExtra{}, adapterExtra["rubicon"])
+	assert.Equal(t, &seatResponseExtra{}, adapterExtra["openx"])
+}
+
+func TestMergeStoredResponsesNone(t *testing.T) {
+	adapterBids := map[openrtb_ext.BidderName]*pbsOrtbSeatBid{}
+	adapterExtra := map[openrtb_ext.BidderName]*seatResponseExtra{}
+
+	storedBidders, bidsFound := mergeStoredResponses(adapterBids, adapterExtra, &openrtb2.BidRequest{}, nil, nil, nil)
+
+	assert.False(t, bidsFound)
+	assert.Empty(t, storedBidders)
+	assert.Empty(t, adapterBids)
+	assert.Empty(t, adapterExtra)
+}
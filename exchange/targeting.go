@@ -4,6 +4,7 @@ import (
 	"strconv"
 
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
@@ -24,9 +25,53 @@ type targetData struct {
 	includeCacheVast  bool
 	includeFormat     bool
 	preferDeals       bool
+	// mediaTypePriceGranularity and bidderPriceGranularity override the price granularity for some media types and bidders
+	mediaTypePriceGranularity *openrtb_ext.MediaTypePriceGranularity
+	bidderPriceGranularity    map[openrtb_ext.BidderName]openrtb_ext.PriceGranularity
+	// keys names the keys of the account, and excludeDeals and excludeCache leave out the deal and cache keys
+	keys         config.AccountTargeting
+	excludeDeals bool
+	excludeCache bool
 	// cacheHost and cachePath exist to supply cache host and path as targeting parameters
 	cacheHost string
 	cachePath string
+	// cacheKey is the key of the publisher starting the category keys, and cacheMaxTTLs and cacheVASTXMLOnly are the cache
+	// settings of the account
+	cacheKey         string
+	cacheMaxTTLs     config.DefaultTTLs
+	cacheVASTXMLOnly bool
+}
+
+// bidPriceGranularity returns the price granularity of the bids of the bidder and media type: the one of the bidder, of
+// the media type, or else of the request
+func (targData *targetData) bidPriceGranularity(bidderName openrtb_ext.BidderName, bidType openrtb_ext.BidType) openrtb_ext.PriceGranularity {
+	if priceGranularity, ok := targData.bidderPriceGranularity[bidderName]; ok {
+		return priceGranularity
+	}
+	if priceGranularity := targData.mediaTypePriceGranularity.ForBidType(bidType); priceGranularity != nil {
+		return *priceGranularity
+	}
+	return targData.priceGranularity
+}
+
+// applyAccount customizes the keys and the caching of the bids with the targeting and cache settings of the account
+func (targData *targetData) applyAccount(account *config.Account) {
+	targeting := account.Targeting
+	targData.keys = targeting
+	if targeting.IncludeFormat != nil {
+		targData.includeFormat = *targeting.IncludeFormat
+	}
+	targData.excludeDeals = targeting.IncludeDeals != nil && !*targeting.IncludeDeals
+	targData.excludeCache = targeting.IncludeCache != nil && !*targeting.IncludeCache
+
+	if account.Cache.Host != "" {
+		targData.cacheHost = account.Cache.Host
+	}
+	if account.Cache.Path != "" {
+		targData.cachePath = account.Cache.Path
+	}
+	targData.cacheMaxTTLs = account.Cache.MaxTTL
+	targData.cacheVASTXMLOnly = account.Cache.VASTXMLOnly
 }
 
 // setTargeting writes all the targeting params into the bids.
@@ -36,58 +81,68 @@ type targetData struct {
 // it's ok if those stay in the auction. For now, this method implements a very naive cache strategy.
 // In the future, we should implement a more clever retry & backoff strategy to balance the success rate & performance.
 func (targData *targetData) setTargeting(auc *auction, isApp bool, categoryMapping map[string]string) {
-	for impId, topBidsPerImp := range auc.winningBidsByBidder {
+	for impId, targetedBidsPerImp := range auc.targetedBidsByBidder() {
 		overallWinner := auc.winningBids[impId]
-		for bidderName, topBidPerBidder := range topBidsPerImp {
-			isOverallWinner := overallWinner == topBidPerBidder
-
-			targets := make(map[string]string, 10)
-			if cpm, ok := auc.roundedPrices[topBidPerBidder]; ok {
-				targData.addKeys(targets, openrtb_ext.HbpbConstantKey, cpm, bidderName, isOverallWinner)
-			}
-			targData.addKeys(targets, openrtb_ext.HbBidderConstantKey, string(bidderName), bidderName, isOverallWinner)
-			if hbSize := makeHbSize(topBidPerBidder.bid); hbSize != "" {
-				targData.addKeys(targets, openrtb_ext.HbSizeConstantKey, hbSize, bidderName, isOverallWinner)
-			}
-			if cacheID, ok := auc.cacheIds[topBidPerBidder.bid]; ok {
-				targData.addKeys(targets, openrtb_ext.HbCacheKey, cacheID, bidderName, isOverallWinner)
-			}
-			if vastID, ok := auc.vastCacheIds[topBidPerBidder.bid]; ok {
-				targData.addKeys(targets, openrtb_ext.HbVastCacheKey, vastID, bidderName, isOverallWinner)
-			}
-			if targData.includeFormat {
-				targData.addKeys(targets, openrtb_ext.HbFormatKey, string(topBidPerBidder.bidType), bidderName, isOverallWinner)
+		for bidderName, targetedBidsPerBidder := range targetedBidsPerImp {
+			for i, targetedBid := range targetedBidsPerBidder {
+				// The extra bids of multibid bidders are targeted under their own bidder code
+				targetBidderCode := bidderName
+				if i > 0 {
+					targetBidderCode = openrtb_ext.BidderName(targetedBid.targetBidderCode)
+				}
+				targData.setBidTargeting(auc, targetedBid, targetBidderCode, overallWinner == targetedBid, isApp, categoryMapping)
 			}
+		}
+	}
+}
 
-			if targData.cacheHost != "" {
-				targData.addKeys(targets, openrtb_ext.HbConstantCacheHostKey, targData.cacheHost, bidderName, isOverallWinner)
-			}
-			if targData.cachePath != "" {
-				targData.addKeys(targets, openrtb_ext.HbConstantCachePathKey, targData.cachePath, bidderName, isOverallWinner)
-			}
+// setBidTargeting writes the targeting params of a bid, with the keys of the given bidder code.
+func (targData *targetData) setBidTargeting(auc *auction, bid *pbsOrtbBid, bidderName openrtb_ext.BidderName, isOverallWinner bool, isApp bool, categoryMapping map[string]string) {
+	targets := make(map[string]string, 10)
+	if cpm, ok := auc.roundedPrices[bid]; ok {
+		targData.addKeys(targets, openrtb_ext.HbpbConstantKey, cpm, bidderName, isOverallWinner)
+	}
+	targData.addKeys(targets, openrtb_ext.HbBidderConstantKey, string(bidderName), bidderName, isOverallWinner)
+	if hbSize := makeHbSize(bid.bid); hbSize != "" {
+		targData.addKeys(targets, openrtb_ext.HbSizeConstantKey, hbSize, bidderName, isOverallWinner)
+	}
+	if cacheID, ok := auc.cacheIds[bid.bid]; ok && !targData.excludeCache {
+		targData.addKeys(targets, openrtb_ext.HbCacheKey, cacheID, bidderName, isOverallWinner)
+	}
+	if vastID, ok := auc.vastCacheIds[bid.bid]; ok && !targData.excludeCache {
+		targData.addKeys(targets, openrtb_ext.HbVastCacheKey, vastID, bidderName, isOverallWinner)
+	}
+	if targData.includeFormat {
+		targData.addKeys(targets, openrtb_ext.HbFormatKey, string(bid.bidType), bidderName, isOverallWinner)
+	}
 
-			if deal := topBidPerBidder.bid.DealID; len(deal) > 0 {
-				targData.addKeys(targets, openrtb_ext.HbDealIDConstantKey, deal, bidderName, isOverallWinner)
-			}
+	if targData.cacheHost != "" && !targData.excludeCache {
+		targData.addKeys(targets, openrtb_ext.HbConstantCacheHostKey, targData.cacheHost, bidderName, isOverallWinner)
+	}
+	if targData.cachePath != "" && !targData.excludeCache {
+		targData.addKeys(targets, openrtb_ext.HbConstantCachePathKey, targData.cachePath, bidderName, isOverallWinner)
+	}
 
-			if isApp {
-				targData.addKeys(targets, openrtb_ext.HbEnvKey, openrtb_ext.HbEnvKeyApp, bidderName, isOverallWinner)
-			}
-			if len(categoryMapping) > 0 {
-				targData.addKeys(targets, openrtb_ext.HbCategoryDurationKey, categoryMapping[topBidPerBidder.bid.ID], bidderName, isOverallWinner)
-			}
+	if deal := bid.bid.DealID; len(deal) > 0 && !targData.excludeDeals {
+		targData.addKeys(targets, openrtb_ext.HbDealIDConstantKey, deal, bidderName, isOverallWinner)
+	}
 
-			topBidPerBidder.bidTargets = targets
-		}
+	if isApp {
+		targData.addKeys(targets, openrtb_ext.HbEnvKey, openrtb_ext.HbEnvKeyApp, bidderName, isOverallWinner)
 	}
+	if len(categoryMapping) > 0 {
+		targData.addKeys(targets, openrtb_ext.HbCategoryDurationKey, categoryMapping[bid.bid.ID], bidderName, isOverallWinner)
+	}
+
+	bid.bidTargets = targets
 }
 
 func (targData *targetData) addKeys(keys map[string]string, key openrtb_ext.TargetingKey, value string, bidderName openrtb_ext.BidderName, overallWinner bool) {
 	if targData.includeBidderKeys {
-		keys[key.BidderKey(bidderName, MaxKeyLength)] = value
+		keys[targData.keys.BidderKey(key, bidderName, MaxKeyLength)] = value
 	}
 	if targData.includeWinners && overallWinner {
-		keys[string(key)] = value
+		keys[targData.keys.WinnerKey(key)] = value
 	}
 }
 
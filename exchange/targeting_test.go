@@ -143,7 +143,7 @@ func buildAdapterMap(bids map[openrtb_ext.BidderName][]*openrtb2.Bid, mockServer
 		adapterMap[bidder] = adaptBidder(&mockTargetingBidder{
 			mockServerURL: mockServerURL,
 			bids:          bids,
-		}, client, &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+		}, client, &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil, nil)
 	}
 	return adapterMap
 }
@@ -422,13 +422,219 @@ var TargetingTests []TargetingTestData = []TargetingTestData{
 			},
 		},
 	},
+	{
+		Description: "Targeting with the price granularity of the media types and bidders",
+		TargetData: targetData{
+			priceGranularity: openrtb_ext.PriceGranularityFromString("med"),
+			mediaTypePriceGranularity: &openrtb_ext.MediaTypePriceGranularity{
+				Video: &openrtb_ext.PriceGranularity{Precision: 2, Ranges: []openrtb_ext.GranularityRange{{Min: 0, Max: 20, Increment: 0.5}}},
+			},
+			bidderPriceGranularity: map[openrtb_ext.BidderName]openrtb_ext.PriceGranularity{
+				openrtb_ext.BidderRubicon: {Precision: 1, Ranges: []openrtb_ext.GranularityRange{{Min: 0, Max: 20, Increment: 1}}},
+			},
+			includeBidderKeys: true,
+		},
+		Auction: auction{
+			winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				"ImpId-1": {
+					openrtb_ext.BidderAppnexus: {
+						bid:     bid123,
+						bidType: openrtb_ext.BidTypeVideo,
+					},
+					openrtb_ext.BidderRubicon: {
+						bid:     bid123,
+						bidType: openrtb_ext.BidTypeVideo,
+					},
+					openrtb_ext.BidderIx: {
+						bid:     bid123,
+						bidType: openrtb_ext.BidTypeBanner,
+					},
+				},
+			},
+		},
+		ExpectedBidTargetsByBidder: map[string]map[openrtb_ext.BidderName]map[string]string{
+			"ImpId-1": {
+				openrtb_ext.BidderAppnexus: {
+					"hb_bidder_appnexus": "appnexus",
+					"hb_pb_appnexus":     "1.00",
+				},
+				openrtb_ext.BidderRubicon: {
+					"hb_bidder_rubicon": "rubicon",
+					"hb_pb_rubicon":     "1.0",
+				},
+				openrtb_ext.BidderIx: {
+					"hb_bidder_ix": "ix",
+					"hb_pb_ix":     "1.20",
+				},
+			},
+		},
+	},
+	{
+		Description: "Targeting with the key prefix and max key length of the account",
+		TargetData: targetData{
+			priceGranularity:  openrtb_ext.PriceGranularityFromString("med"),
+			includeWinners:    true,
+			includeBidderKeys: true,
+			keys:              config.AccountTargeting{Prefix: "pbs", MaxKeyLength: 16},
+		},
+		Auction: auction{
+			winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				"ImpId-1": {
+					openrtb_ext.BidderAppnexus: {
+						bid:     bid123,
+						bidType: openrtb_ext.BidTypeBanner,
+					},
+					openrtb_ext.BidderRubicon: {
+						bid:     bid084,
+						bidType: openrtb_ext.BidTypeBanner,
+					},
+				},
+			},
+		},
+		ExpectedBidTargetsByBidder: map[string]map[openrtb_ext.BidderName]map[string]string{
+			"ImpId-1": {
+				openrtb_ext.BidderAppnexus: {
+					"pbs_bidder":       "appnexus",
+					"pbs_bidder__428a": "appnexus",
+					"pbs_pb":           "1.20",
+					"pbs_pb_appnexus":  "1.20",
+				},
+				openrtb_ext.BidderRubicon: {
+					"pbs_bidder__62e4": "rubicon",
+					"pbs_pb_rubicon":   "0.80",
+				},
+			},
+		},
+	},
+	{
+		Description: "Targeting without the deal and cache keys",
+		TargetData: targetData{
+			priceGranularity:  openrtb_ext.PriceGranularityFromString("med"),
+			includeBidderKeys: true,
+			cacheHost:         "cache.prebid.com",
+			cachePath:         "cache",
+			excludeDeals:      true,
+			excludeCache:      true,
+		},
+		Auction: auction{
+			winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				"ImpId-1": {
+					openrtb_ext.BidderRubicon: {
+						bid:     bid111,
+						bidType: openrtb_ext.BidTypeBanner,
+					},
+				},
+			},
+			cacheIds: map[*openrtb2.Bid]string{
+				bid111: "cacheme",
+			},
+		},
+		ExpectedBidTargetsByBidder: map[string]map[openrtb_ext.BidderName]map[string]string{
+			"ImpId-1": {
+				openrtb_ext.BidderRubicon: {
+					"hb_bidder_rubicon": "rubicon",
+					"hb_pb_rubicon":     "1.10",
+				},
+			},
+		},
+	},
+}
+
+func TestBidPriceGranularity(t *testing.T) {
+	high := openrtb_ext.PriceGranularityFromString("high")
+	low := openrtb_ext.PriceGranularityFromString("low")
+	targData := &targetData{
+		priceGranularity:          openrtb_ext.PriceGranularityFromString("med"),
+		mediaTypePriceGranularity: &openrtb_ext.MediaTypePriceGranularity{Video: &high},
+		bidderPriceGranularity:    map[openrtb_ext.BidderName]openrtb_ext.PriceGranularity{openrtb_ext.BidderRubicon: low},
+	}
+
+	assert.Equal(t, low, targData.bidPriceGranularity(openrtb_ext.BidderRubicon, openrtb_ext.BidTypeVideo), "Price granularity of the bidder")
+	assert.Equal(t, high, targData.bidPriceGranularity(openrtb_ext.BidderAppnexus, openrtb_ext.BidTypeVideo), "Price granularity of the media type")
+	assert.Equal(t, targData.priceGranularity, targData.bidPriceGranularity(openrtb_ext.BidderAppnexus, openrtb_ext.BidTypeBanner), "Price granularity of the request")
+}
+
+func TestApplyAccount(t *testing.T) {
+	trueValue, falseValue := true, false
+
+	testCases := []struct {
+		description     string
+		givenTargetData targetData
+		givenAccount    config.Account
+		expectedFormat  bool
+		expectedNoDeals bool
+		expectedNoCache bool
+	}{
+		{
+			description:     "Account without targeting settings",
+			givenTargetData: targetData{includeFormat: true},
+			expectedFormat:  true,
+		},
+		{
+			description:     "Account overriding the format and excluding the deals and cache keys",
+			givenTargetData: targetData{includeFormat: true},
+			givenAccount:    config.Account{Targeting: config.AccountTargeting{IncludeFormat: &falseValue, IncludeDeals: &falseValue, IncludeCache: &falseValue}},
+			expectedNoDeals: true,
+			expectedNoCache: true,
+		},
+		{
+			description:    "Account including the format and the deals and cache keys",
+			givenAccount:   config.Account{Targeting: config.AccountTargeting{IncludeFormat: &trueValue, IncludeDeals: &trueValue, IncludeCache: &trueValue}},
+			expectedFormat: true,
+		},
+	}
+
+	for _, test := range testCases {
+		targData := test.givenTargetData
+		targData.applyAccount(&test.givenAccount)
+
+		assert.Equal(t, test.givenAccount.Targeting, targData.keys, test.description)
+		assert.Equal(t, test.expectedFormat, targData.includeFormat, test.description)
+		assert.Equal(t, test.expectedNoDeals, targData.excludeDeals, test.description)
+		assert.Equal(t, test.expectedNoCache, targData.excludeCache, test.description)
+	}
+}
+
+func TestApplyAccountCache(t *testing.T) {
+	testCases := []struct {
+		description        string
+		givenAccount       config.Account
+		expectedTargetData targetData
+	}{
+		{
+			description:        "Account without cache settings",
+			expectedTargetData: targetData{cacheHost: "cache.prebid.com", cachePath: "cache"},
+		},
+		{
+			description: "Account with cache settings",
+			givenAccount: config.Account{Cache: config.AccountCache{
+				MaxTTL:      config.DefaultTTLs{Video: 600},
+				VASTXMLOnly: true,
+				Host:        "cache.publisher.com",
+				Path:        "pbc",
+			}},
+			expectedTargetData: targetData{
+				cacheHost:        "cache.publisher.com",
+				cachePath:        "pbc",
+				cacheMaxTTLs:     config.DefaultTTLs{Video: 600},
+				cacheVASTXMLOnly: true,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		targData := targetData{cacheHost: "cache.prebid.com", cachePath: "cache"}
+		targData.applyAccount(&test.givenAccount)
+
+		assert.Equal(t, test.expectedTargetData, targData, test.description)
+	}
 }
 
 func TestSetTargeting(t *testing.T) {
 	for _, test := range TargetingTests {
 		auc := &test.Auction
 		// Set rounded prices from the auction data
-		auc.setRoundedPrices(test.TargetData.priceGranularity)
+		auc.setRoundedPrices(&test.TargetData)
 		winningBids := make(map[string]*pbsOrtbBid)
 		// Set winning bids from the auction data
 		for imp, bidsByBidder := range auc.winningBidsByBidder {
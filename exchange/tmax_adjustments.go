@@ -0,0 +1,35 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+)
+
+// makeBidderContext applies the tmax adjustments to the call of a bidder. The tmax of the bidder request is lowered
+// to the time the bidder has to answer, and the returned context enforces the bidder deadline on its HTTP calls.
+// An error is returned if the bidder is left with too little time to be called.
+func makeBidderContext(ctx context.Context, bidRequest *openrtb2.BidRequest, adjustments config.TmaxAdjustments) (context.Context, context.CancelFunc, error) {
+	deadline, hasDeadline := ctx.Deadline()
+	if !adjustments.Enabled || !hasDeadline {
+		return ctx, func() {}, nil
+	}
+
+	remaining := time.Until(deadline) - time.Duration(adjustments.PBSResponsePreparationDurationMs)*time.Millisecond
+	bidderDuration := remaining * time.Duration(adjustments.BidderTmaxPercent) / 100
+	bidderTmax := bidderDuration - time.Duration(adjustments.BidderNetworkLatencyBufferMs)*time.Millisecond
+
+	if bidderTmax < time.Millisecond || bidderTmax < time.Duration(adjustments.BidderResponseDurationMinMs)*time.Millisecond {
+		return ctx, func() {}, &errortypes.Timeout{
+			Message: fmt.Sprintf("The bidder was not called because it would have had %dms to respond", bidderTmax.Milliseconds()),
+		}
+	}
+
+	bidRequest.TMax = bidderTmax.Milliseconds()
+	bidderCtx, cancel := context.WithDeadline(ctx, time.Now().Add(bidderDuration))
+	return bidderCtx, cancel, nil
+}
@@ -0,0 +1,89 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakeBidderContext(t *testing.T) {
+	adjustments := config.TmaxAdjustments{
+		Enabled:                          true,
+		BidderTmaxPercent:                50,
+		BidderNetworkLatencyBufferMs:     50,
+		PBSResponsePreparationDurationMs: 200,
+		BidderResponseDurationMinMs:      100,
+	}
+
+	testCases := []struct {
+		description       string
+		givenAdjustments  config.TmaxAdjustments
+		givenTimeout      time.Duration
+		expectedTmax      int64
+		expectedDuration  time.Duration
+		expectedError     bool
+		expectSameContext bool
+	}{
+		{
+			description:       "Adjustments disabled",
+			givenAdjustments:  config.TmaxAdjustments{BidderTmaxPercent: 50},
+			givenTimeout:      time.Second,
+			expectedTmax:      1000,
+			expectSameContext: true,
+		},
+		{
+			description:      "Bidder gets its share of the time left for bidding",
+			givenAdjustments: adjustments,
+			givenTimeout:     time.Second,
+			expectedTmax:     350,
+			expectedDuration: 400 * time.Millisecond,
+		},
+		{
+			description:       "Not enough time left for the bidder",
+			givenAdjustments:  adjustments,
+			givenTimeout:      400 * time.Millisecond,
+			expectedTmax:      1000,
+			expectedError:     true,
+			expectSameContext: true,
+		},
+	}
+
+	for _, test := range testCases {
+		ctx, cancelAuction := context.WithTimeout(context.Background(), test.givenTimeout)
+		bidRequest := &openrtb2.BidRequest{TMax: 1000}
+
+		bidderCtx, cancel, err := makeBidderContext(ctx, bidRequest, test.givenAdjustments)
+
+		if test.expectedError {
+			assert.IsType(t, &errortypes.Timeout{}, err, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+		}
+		// Some time passes between the creation of the contexts, which can cost a millisecond of tmax
+		assert.InDelta(t, test.expectedTmax, bidRequest.TMax, 1, test.description)
+		if test.expectSameContext {
+			assert.Equal(t, ctx, bidderCtx, test.description)
+		} else if deadline, ok := bidderCtx.Deadline(); assert.True(t, ok, test.description) {
+			assert.InDelta(t, test.expectedDuration, time.Until(deadline), float64(10*time.Millisecond), test.description)
+		}
+
+		cancel()
+		cancelAuction()
+	}
+}
+
+func TestMakeBidderContextWithoutDeadline(t *testing.T) {
+	bidRequest := &openrtb2.BidRequest{TMax: 1000}
+
+	bidderCtx, cancel, err := makeBidderContext(context.Background(), bidRequest, config.TmaxAdjustments{Enabled: true, BidderTmaxPercent: 50})
+	defer cancel()
+
+	assert.NoError(t, err)
+	assert.Equal(t, context.Background(), bidderCtx)
+	assert.Equal(t, int64(1000), bidRequest.TMax)
+}
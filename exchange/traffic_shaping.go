@@ -0,0 +1,53 @@
+package exchange
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/trafficshaping"
+)
+
+// removeShapedBidders drops the requests of the bidders the auction was kept away from by traffic shaping. Shaping is
+// configured for core bidders, so it applies to their aliases as well.
+func removeShapedBidders(bidderRequests []BidderRequest, assignment openrtb_ext.ExtTrafficShaping) []BidderRequest {
+	if len(assignment) == 0 {
+		return bidderRequests
+	}
+
+	shapedRequests := make([]BidderRequest, 0, len(bidderRequests))
+	for _, bidderRequest := range bidderRequests {
+		if !trafficshaping.Excludes(assignment, bidderRequest.BidderCoreName) {
+			shapedRequests = append(shapedRequests, bidderRequest)
+		}
+	}
+	return shapedRequests
+}
+
+// makeResponsePassthrough returns bidrequest.ext.prebid.passthrough with the traffic shaping of the request added to it.
+// A passthrough which isn't an object is returned as is, without the traffic shaping.
+func makeResponsePassthrough(passthrough json.RawMessage, assignment openrtb_ext.ExtTrafficShaping) json.RawMessage {
+	if len(assignment) == 0 {
+		return passthrough
+	}
+
+	assignmentJSON, err := json.Marshal(assignment)
+	if err != nil {
+		return passthrough
+	}
+	if len(bytes.TrimSpace(passthrough)) == 0 {
+		return json.RawMessage(`{"` + openrtb_ext.TrafficShapingPassthroughKey + `":` + string(assignmentJSON) + `}`)
+	}
+	if bytes.TrimSpace(passthrough)[0] != '{' {
+		return passthrough
+	}
+
+	// jsonparser.Set may write into the passthrough of the request, so it is given a copy
+	requestPassthrough := make([]byte, len(passthrough))
+	copy(requestPassthrough, passthrough)
+	if responsePassthrough, err := jsonparser.Set(requestPassthrough, assignmentJSON, openrtb_ext.TrafficShapingPassthroughKey); err == nil {
+		return responsePassthrough
+	}
+	return passthrough
+}
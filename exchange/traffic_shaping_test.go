@@ -0,0 +1,94 @@
+package exchange
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveShapedBidders(t *testing.T) {
+	bidderRequests := []BidderRequest{
+		{BidderName: "appnexus", BidderCoreName: "appnexus"},
+		{BidderName: "districtm", BidderCoreName: "appnexus"},
+		{BidderName: "rubicon", BidderCoreName: "rubicon"},
+		{BidderName: "openx", BidderCoreName: "openx"},
+	}
+
+	testCases := []struct {
+		description     string
+		givenAssignment openrtb_ext.ExtTrafficShaping
+		expectedBidders []openrtb_ext.BidderName
+	}{
+		{
+			description:     "No traffic shaping",
+			expectedBidders: []openrtb_ext.BidderName{"appnexus", "districtm", "rubicon", "openx"},
+		},
+		{
+			description: "Excluded bidder and its alias are removed",
+			givenAssignment: openrtb_ext.ExtTrafficShaping{
+				"appnexus": openrtb_ext.TrafficShapingExcluded,
+				"rubicon":  openrtb_ext.TrafficShapingIncluded,
+			},
+			expectedBidders: []openrtb_ext.BidderName{"rubicon", "openx"},
+		},
+	}
+
+	for _, test := range testCases {
+		shapedRequests := removeShapedBidders(bidderRequests, test.givenAssignment)
+
+		actualBidders := make([]openrtb_ext.BidderName, 0, len(shapedRequests))
+		for _, bidderRequest := range shapedRequests {
+			actualBidders = append(actualBidders, bidderRequest.BidderName)
+		}
+		assert.Equal(t, test.expectedBidders, actualBidders, test.description)
+	}
+}
+
+func TestMakeResponsePassthrough(t *testing.T) {
+	assignment := openrtb_ext.ExtTrafficShaping{"appnexus": openrtb_ext.TrafficShapingExcluded}
+
+	testCases := []struct {
+		description         string
+		givenPassthrough    json.RawMessage
+		givenAssignment     openrtb_ext.ExtTrafficShaping
+		expectedPassthrough json.RawMessage
+	}{
+		{
+			description: "Neither passthrough nor traffic shaping",
+		},
+		{
+			description:         "Passthrough only",
+			givenPassthrough:    json.RawMessage(`{"key":"value"}`),
+			expectedPassthrough: json.RawMessage(`{"key":"value"}`),
+		},
+		{
+			description:         "Traffic shaping only",
+			givenAssignment:     assignment,
+			expectedPassthrough: json.RawMessage(`{"trafficshaping":{"appnexus":"excluded"}}`),
+		},
+		{
+			description:         "Traffic shaping added to the passthrough",
+			givenPassthrough:    json.RawMessage(`{"key":"value"}`),
+			givenAssignment:     assignment,
+			expectedPassthrough: json.RawMessage(`{"key":"value","trafficshaping":{"appnexus":"excluded"}}`),
+		},
+		{
+			description:         "Passthrough which isn't an object",
+			givenPassthrough:    json.RawMessage(`["value"]`),
+			givenAssignment:     assignment,
+			expectedPassthrough: json.RawMessage(`["value"]`),
+		},
+	}
+
+	for _, test := range testCases {
+		passthrough := makeResponsePassthrough(test.givenPassthrough, test.givenAssignment)
+
+		if test.expectedPassthrough == nil {
+			assert.Nil(t, passthrough, test.description)
+		} else {
+			assert.JSONEq(t, string(test.expectedPassthrough), string(passthrough), test.description)
+		}
+	}
+}
@@ -11,24 +11,61 @@ import (
 	"github.com/prebid/go-gdpr/vendorconsent"
 
 	"github.com/buger/jsonparser"
+	"github.com/gofrs/uuid"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/gdpr"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/privacy/ccpa"
+	"github.com/prebid/prebid-server/privacy/gpp"
 	"github.com/prebid/prebid-server/privacy/lmt"
 )
 
 var integrationTypeMap = map[metrics.RequestType]config.IntegrationType{
-	metrics.ReqTypeAMP:      config.IntegrationTypeAMP,
-	metrics.ReqTypeORTB2App: config.IntegrationTypeApp,
-	metrics.ReqTypeVideo:    config.IntegrationTypeVideo,
-	metrics.ReqTypeORTB2Web: config.IntegrationTypeWeb,
+	metrics.ReqTypeAMP:       config.IntegrationTypeAMP,
+	metrics.ReqTypeORTB2App:  config.IntegrationTypeApp,
+	metrics.ReqTypeVideo:     config.IntegrationTypeVideo,
+	metrics.ReqTypeORTB2Web:  config.IntegrationTypeWeb,
+	metrics.ReqTypeORTB2DOOH: config.IntegrationTypeDOOH,
 }
 
 const unknownBidder string = ""
 
+// fillTransactionIDs generates random source.tid and imp.ext.tid transaction ids where the request doesn't have them.
+func fillTransactionIDs(req *openrtb2.BidRequest) error {
+	if req.Source == nil {
+		req.Source = &openrtb2.Source{}
+	}
+	if req.Source.TID == "" {
+		rawUUID, err := uuid.NewV4()
+		if err != nil {
+			return fmt.Errorf("error creating a random source.tid: %v", err)
+		}
+		req.Source.TID = rawUUID.String()
+	}
+
+	for i := range req.Imp {
+		if tid, _ := jsonparser.GetString(req.Imp[i].Ext, openrtb_ext.TIDExtKey); tid != "" {
+			continue
+		}
+		rawUUID, err := uuid.NewV4()
+		if err != nil {
+			return fmt.Errorf("error creating a random request.imp[%d].ext.tid: %v", i, err)
+		}
+		impExt := req.Imp[i].Ext
+		if len(impExt) == 0 {
+			impExt = json.RawMessage(`{}`)
+		}
+		impExt, err = jsonparser.Set(impExt, []byte(`"`+rawUUID.String()+`"`), openrtb_ext.TIDExtKey)
+		if err != nil {
+			return fmt.Errorf("request.imp[%d].ext is invalid: %v", i, err)
+		}
+		req.Imp[i].Ext = impExt
+	}
+	return nil
+}
+
 func BidderToPrebidSChains(sChains []*openrtb_ext.ExtRequestPrebidSChain) (map[string]*openrtb_ext.ExtRequestPrebidSChainSChain, error) {
 	bidderToSChains := make(map[string]*openrtb_ext.ExtRequestPrebidSChainSChain)
 
@@ -73,23 +110,35 @@ func cleanOpenRTBRequests(ctx context.Context,
 	}
 
 	var allBidderRequests []BidderRequest
-	allBidderRequests, errs = getAuctionBidderRequests(req, requestExt, bidderToSyncerKey, impsByBidder, aliases)
+	allBidderRequests, errs = getAuctionBidderRequests(req, requestExt, bidderToSyncerKey, impsByBidder, aliases, metricsEngine)
 
 	if len(allBidderRequests) == 0 {
 		return
 	}
 
-	gdprSignal, err := extractGDPR(req.BidRequest)
+	integrationType := getIntegrationType(req.LegacyLabels.RType, requestExt)
+
+	var gppParsedPolicy gpp.ParsedPolicy
+	if gppEnabled(&req.Account, privacyConfig, integrationType) {
+		gppPolicy, err := gpp.ReadFromRequest(req.BidRequest)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		// the sections which can't be parsed are reported as warnings by the endpoints
+		gppParsedPolicy, _ = gppPolicy.Parse()
+	}
+
+	gdprSignal, err := extractGDPR(req.BidRequest, gppParsedPolicy)
 	if err != nil {
 		errs = append(errs, err)
 	}
-	consent, err := extractConsent(req.BidRequest)
+	consent, err := extractConsent(req.BidRequest, gppParsedPolicy)
 	if err != nil {
 		errs = append(errs, err)
 	}
 	gdprEnforced := gdprSignal == gdpr.SignalYes || (gdprSignal == gdpr.SignalAmbiguous && gdprDefaultValue == gdpr.SignalYes)
 
-	ccpaEnforcer, err := extractCCPA(req.BidRequest, privacyConfig, &req.Account, aliases, integrationTypeMap[req.LegacyLabels.RType])
+	ccpaEnforcer, err := extractCCPA(req.BidRequest, gppParsedPolicy, privacyConfig, &req.Account, aliases, integrationType)
 	if err != nil {
 		errs = append(errs, err)
 	}
@@ -98,8 +147,13 @@ func cleanOpenRTBRequests(ctx context.Context,
 
 	// request level privacy policies
 	privacyEnforcement := privacy.Enforcement{
-		COPPA: req.BidRequest.Regs != nil && req.BidRequest.Regs.COPPA == 1,
-		LMT:   lmtEnforcer.ShouldEnforce(unknownBidder),
+		COPPA:         req.BidRequest.Regs != nil && req.BidRequest.Regs.COPPA == 1,
+		LMT:           lmtEnforcer.ShouldEnforce(unknownBidder),
+		LGPD:          extractLGPD(req, privacyConfig),
+		ScrubProfiles: privacyConfig.ScrubProfiles,
+	}
+	for profile := range privacyEnforcement.ActiveScrubProfiles() {
+		metricsEngine.RecordPrivacyScrub(metrics.PrivacyScrubProfile(profile))
 	}
 
 	privacyLabels.CCPAProvided = ccpaEnforcer.CanEnforce()
@@ -107,7 +161,13 @@ func cleanOpenRTBRequests(ctx context.Context,
 	privacyLabels.COPPAEnforced = privacyEnforcement.COPPA
 	privacyLabels.LMTEnforced = lmtEnforcer.ShouldEnforce(unknownBidder)
 
-	gdprEnforced = gdprEnforced && gdprEnabled(&req.Account, privacyConfig, integrationTypeMap[req.LegacyLabels.RType])
+	gdprEnforced = gdprEnforced && gdprEnabled(&req.Account, privacyConfig, integrationType)
+
+	aliasGVLIDs := getAliasGVLIDs(requestExt, aliases)
+	gDPR = gdpr.WithAliasGVLIDs(gDPR, aliasGVLIDs)
+	if account != nil {
+		gDPR = gdpr.WithAccountConfig(gDPR, account.GDPR)
+	}
 
 	if gdprEnforced {
 		privacyLabels.GDPREnforced = true
@@ -118,6 +178,9 @@ func cleanOpenRTBRequests(ctx context.Context,
 		}
 	}
 
+	activityControl := privacy.NewActivityControl(req.Account.Privacy)
+	activityRequest := newActivityRequest(req)
+
 	// bidder level privacy policies
 	allowedBidderRequests = make([]BidderRequest, 0, len(allBidderRequests))
 	for _, bidderRequest := range allBidderRequests {
@@ -126,6 +189,12 @@ func cleanOpenRTBRequests(ctx context.Context,
 		// CCPA
 		privacyEnforcement.CCPA = ccpaEnforcer.ShouldEnforce(bidderRequest.BidderName.String())
 
+		// Activity controls
+		component := privacy.Component{Type: config.ComponentTypeBidder, Name: bidderRequest.BidderName.String()}
+		privacyEnforcement.UFPD = !activityControl.Allow(privacy.ActivityTransmitUserFPD, component, activityRequest)
+		privacyEnforcement.PreciseGeo = !activityControl.Allow(privacy.ActivityTransmitPreciseGeo, component, activityRequest)
+		privacyEnforcement.EIDs = !activityControl.Allow(privacy.ActivityTransmitEids, component, activityRequest)
+
 		// GDPR
 		if gdprEnforced {
 			weakVendorEnforcement := false
@@ -138,7 +207,12 @@ func cleanOpenRTBRequests(ctx context.Context,
 				}
 			}
 			var publisherID = req.LegacyLabels.PubID
-			bidReq, geo, id, err := gDPR.AuctionActivitiesAllowed(ctx, bidderRequest.BidderCoreName, publisherID, gdprSignal, consent, weakVendorEnforcement)
+			// aliases with a GVL vendor ID of their own are checked against it rather than the one of their core bidder
+			gdprBidder := bidderRequest.BidderCoreName
+			if _, ok := aliasGVLIDs[bidderRequest.BidderName]; ok {
+				gdprBidder = bidderRequest.BidderName
+			}
+			bidReq, geo, id, err := gDPR.AuctionActivitiesAllowed(ctx, gdprBidder, publisherID, gdprSignal, consent, weakVendorEnforcement)
 			bidRequestAllowed = bidReq
 
 			if err == nil {
@@ -150,7 +224,18 @@ func cleanOpenRTBRequests(ctx context.Context,
 			}
 
 			if !bidRequestAllowed {
+				if req.blockedNonBids != nil {
+					req.blockedNonBids[bidderRequest.BidderName] = makeImpNonBids(bidderRequest.BidRequest.Imp, openrtb_ext.NonBidRequestBlockedPrivacy)
+				}
 				metricsEngine.RecordAdapterGDPRRequestBlocked(bidderRequest.BidderCoreName)
+				metricsEngine.RecordTCFPurposeBlocked(metrics.TCFRequestBlocked)
+			} else if err == nil {
+				if !id {
+					metricsEngine.RecordTCFPurposeBlocked(metrics.TCFPurposes2To10)
+				}
+				if !geo {
+					metricsEngine.RecordTCFPurposeBlocked(metrics.TCFSpecialFeature1)
+				}
 			}
 		}
 
@@ -163,6 +248,15 @@ func cleanOpenRTBRequests(ctx context.Context,
 	return
 }
 
+// getIntegrationType returns the integration type of the request, which is dooh for the requests of the dooh channel
+// and else the one of the endpoint
+func getIntegrationType(requestType metrics.RequestType, requestExt *openrtb_ext.ExtRequest) config.IntegrationType {
+	if requestExt != nil && requestExt.Prebid.Channel != nil && requestExt.Prebid.Channel.Name == string(config.IntegrationTypeDOOH) {
+		return config.IntegrationTypeDOOH
+	}
+	return integrationTypeMap[requestType]
+}
+
 func gdprEnabled(account *config.Account, privacyConfig config.Privacy, integrationType config.IntegrationType) bool {
 	if accountEnabled := account.GDPR.EnabledForIntegrationType(integrationType); accountEnabled != nil {
 		return *accountEnabled
@@ -177,12 +271,23 @@ func ccpaEnabled(account *config.Account, privacyConfig config.Privacy, requestT
 	return privacyConfig.CCPA.Enforce
 }
 
-func extractCCPA(orig *openrtb2.BidRequest, privacyConfig config.Privacy, account *config.Account, aliases map[string]string, requestType config.IntegrationType) (privacy.PolicyEnforcer, error) {
+func gppEnabled(account *config.Account, privacyConfig config.Privacy, integrationType config.IntegrationType) bool {
+	if accountEnabled := account.GPP.EnabledForIntegrationType(integrationType); accountEnabled != nil {
+		return *accountEnabled
+	}
+	return privacyConfig.GPP.Enabled
+}
+
+func extractCCPA(orig *openrtb2.BidRequest, gppPolicy gpp.ParsedPolicy, privacyConfig config.Privacy, account *config.Account, aliases map[string]string, requestType config.IntegrationType) (privacy.PolicyEnforcer, error) {
 	// Quick extra wrapper until RequestWrapper makes its way into CleanRequests
 	ccpaPolicy, err := ccpa.ReadFromRequestWrapper(&openrtb_ext.RequestWrapper{BidRequest: orig})
 	if err != nil {
 		return privacy.NilPolicyEnforcer{}, err
 	}
+	// the USP v1 and US law sections of the GPP policy take precedence over regs.ext.us_privacy
+	if usPrivacy, ok := gppPolicy.USPrivacy(); ok {
+		ccpaPolicy.Consent = usPrivacy
+	}
 
 	validBidders := GetValidBidders(aliases)
 	ccpaParsedPolicy, err := ccpaPolicy.Parse(validBidders)
@@ -197,6 +302,40 @@ func extractCCPA(orig *openrtb2.BidRequest, privacyConfig config.Privacy, accoun
 	return ccpaEnforcer, nil
 }
 
+// HostCookiesAllowed tells if the privacy policies of the request let the host store its own ids on the user, as the
+// cookie syncs do: the GDPR consent must allow the host vendor to store them, and the user must not have opted out
+// of the sale of their data under CCPA. The policies which can't be read forbid it.
+func HostCookiesAllowed(ctx context.Context, req *openrtb_ext.RequestWrapper, account *config.Account, requestType metrics.RequestType, aliases map[string]string, gDPR gdpr.Permissions, privacyConfig config.Privacy) bool {
+	integrationType := integrationTypeMap[requestType]
+
+	var gppParsedPolicy gpp.ParsedPolicy
+	if gppEnabled(account, privacyConfig, integrationType) {
+		gppPolicy, err := gpp.ReadFromRequest(req.BidRequest)
+		if err != nil {
+			return false
+		}
+		gppParsedPolicy, _ = gppPolicy.Parse()
+	}
+
+	if gdprEnabled(account, privacyConfig, integrationType) {
+		gdprSignal, err := extractGDPR(req.BidRequest, gppParsedPolicy)
+		if err != nil {
+			return false
+		}
+		consent, err := extractConsent(req.BidRequest, gppParsedPolicy)
+		if err != nil {
+			return false
+		}
+		allowed, err := gdpr.WithAccountConfig(gDPR, account.GDPR).HostCookiesAllowed(ctx, gdprSignal, consent)
+		if !allowed || err != nil {
+			return false
+		}
+	}
+
+	ccpaEnforcer, err := extractCCPA(req.BidRequest, gppParsedPolicy, privacyConfig, account, aliases, integrationType)
+	return err == nil && !ccpaEnforcer.ShouldEnforce(unknownBidder)
+}
+
 func extractLMT(orig *openrtb2.BidRequest, privacyConfig config.Privacy) privacy.PolicyEnforcer {
 	return privacy.EnabledPolicyEnforcer{
 		Enabled:        privacyConfig.LMT.Enforce,
@@ -204,11 +343,73 @@ func extractLMT(orig *openrtb2.BidRequest, privacyConfig config.Privacy) privacy
 	}
 }
 
+// applyGPCHeader maps the Sec-GPC header of the request into its regs.ext, unless the request carries the signals
+// itself. The header sets regs.ext.gpc, which the activity controls are matched against, and regs.ext.us_privacy to
+// an opt out of sale, as the US National section of GPP does for a GPC signal, when the request has neither a
+// us_privacy string nor a GPP section of a US law.
+func applyGPCHeader(bidRequest *openrtb2.BidRequest, header string) error {
+	if header != "1" {
+		return nil
+	}
+
+	reqWrap := &openrtb_ext.RequestWrapper{BidRequest: bidRequest}
+	regsExt, err := reqWrap.GetRegExt()
+	if err != nil {
+		return err
+	}
+
+	if ext := regsExt.GetExt(); ext["gpc"] == nil {
+		ext["gpc"] = json.RawMessage(`"1"`)
+		regsExt.SetExt(ext)
+	}
+	if regsExt.GetUSPrivacy() == "" {
+		gppPolicy, err := gpp.ReadFromRequest(bidRequest)
+		if err != nil {
+			return err
+		}
+		gppParsedPolicy, _ := gppPolicy.Parse()
+		if _, ok := gppParsedPolicy.USPrivacy(); !ok {
+			regsExt.SetUSPrivacy(ccpa.GPCConsent)
+		}
+	}
+	return reqWrap.RebuildRequest()
+}
+
+// extractLGPD tells if the Brazilian LGPD applies to the request, from its device.geo.country or else the location
+// of its device
+func extractLGPD(req AuctionRequest, privacyConfig config.Privacy) bool {
+	if !privacyConfig.LGPD.Enforce {
+		return false
+	}
+
+	var country string
+	if req.BidRequest.Device != nil && req.BidRequest.Device.Geo != nil {
+		country = req.BidRequest.Device.Geo.Country
+	}
+	if country == "" && req.geo != nil {
+		country = req.geo.Country
+	}
+	_, found := privacyConfig.LGPD.CountriesMap[strings.ToUpper(country)]
+	return found
+}
+
+// newActivityRequest reads the signals of the request which the activity conditions are matched against. The
+// requests without device.geo.country are matched against the location of their device.
+func newActivityRequest(req AuctionRequest) privacy.ActivityRequest {
+	activityRequest := privacy.NewActivityRequest(req.BidRequest)
+	if activityRequest.Country == "" && req.geo != nil {
+		activityRequest.Country = req.geo.Country
+		activityRequest.Region = req.geo.Region
+	}
+	return activityRequest
+}
+
 func getAuctionBidderRequests(req AuctionRequest,
 	requestExt *openrtb_ext.ExtRequest,
 	bidderToSyncerKey map[string]string,
 	impsByBidder map[string][]openrtb2.Imp,
-	aliases map[string]string) ([]BidderRequest, []error) {
+	aliases map[string]string,
+	metricsEngine metrics.MetricsEngine) ([]BidderRequest, []error) {
 
 	bidderRequests := make([]BidderRequest, 0, len(impsByBidder))
 
@@ -232,20 +433,38 @@ func getAuctionBidderRequests(req AuctionRequest,
 		return nil, []error{err}
 	}
 
+	activityControl := privacy.NewActivityControl(req.Account.Privacy)
+	activityRequest := newActivityRequest(req)
+
 	var errs []error
 	for bidder, imps := range impsByBidder {
 		coreBidder := resolveBidder(bidder, aliases)
 
+		component := privacy.Component{Type: config.ComponentTypeBidder, Name: bidder}
+		if !activityControl.Allow(privacy.ActivityFetchBids, component, activityRequest) {
+			continue
+		}
+
 		reqCopy := *req.BidRequest
 		reqCopy.Imp = imps
 		reqCopy.Ext = reqExt
 
 		prepareSource(&reqCopy, bidder, sChainsByBidder)
 
-		if err := removeUnpermissionedEids(&reqCopy, bidder, requestExt); err != nil {
+		enrichUser := activityControl.Allow(privacy.ActivityEnrichUserFPD, component, activityRequest)
+		if err := req.firstPartyData.Apply(&reqCopy, bidder, enrichUser); err != nil {
+			errs = append(errs, fmt.Errorf("unable to apply the first party data of %s because %v", bidder, err))
+			continue
+		}
+
+		eidsStripped, err := removeUnpermissionedEids(&reqCopy, bidder, requestExt)
+		if err != nil {
 			errs = append(errs, fmt.Errorf("unable to enforce request.ext.prebid.data.eidpermissions because %v", err))
 			continue
 		}
+		if eidsStripped > 0 {
+			metricsEngine.RecordAdapterEidsStripped(coreBidder, eidsStripped)
+		}
 
 		bidderRequest := BidderRequest{
 			BidderName:     openrtb_ext.BidderName(bidder),
@@ -261,6 +480,12 @@ func getAuctionBidderRequests(req AuctionRequest,
 			},
 		}
 
+		if requestExt != nil && coreBidder != openrtb_ext.BidderName(bidder) {
+			if override, ok := requestExt.Prebid.AliasOverrides[bidder]; ok && override != nil {
+				bidderRequest.AliasEndpoint = override.Endpoint
+			}
+		}
+
 		syncerKey := bidderToSyncerKey[string(coreBidder)]
 		if hadSync := prepareUser(&reqCopy, bidder, syncerKey, explicitBuyerUIDs, req.UserSyncs); !hadSync && req.BidRequest.App == nil {
 			bidderRequest.BidderLabels.CookieFlag = metrics.CookieFlagNo
@@ -280,6 +505,14 @@ func getExtJson(req *openrtb2.BidRequest, unpackedExt *openrtb_ext.ExtRequest) (
 
 	extCopy := *unpackedExt
 	extCopy.Prebid.SChains = nil
+	extCopy.Prebid.AliasOverrides = nil
+	extCopy.Prebid.BidderConfigs = nil
+	extCopy.Prebid.Analytics = nil
+	if extCopy.Prebid.Data != nil && len(extCopy.Prebid.Data.Bidders) > 0 {
+		dataCopy := *extCopy.Prebid.Data
+		dataCopy.Bidders = nil
+		extCopy.Prebid.Data = &dataCopy
+	}
 	return json.Marshal(extCopy)
 }
 
@@ -406,6 +639,9 @@ func createSanitizedImpExt(impExt, impExtPrebid map[string]json.RawMessage) (map
 	sanitizedImpExt := make(map[string]json.RawMessage, 3)
 
 	delete(impExtPrebid, openrtb_ext.PrebidExtBidderKey)
+	// The stored responses are resolved by the exchange, so they are not sent to the bidders
+	delete(impExtPrebid, openrtb_ext.PrebidExtStoredAuctionResponseKey)
+	delete(impExtPrebid, openrtb_ext.PrebidExtStoredBidResponseKey)
 	if len(impExtPrebid) > 0 {
 		if impExtPrebidJSON, err := json.Marshal(impExtPrebid); err == nil {
 			sanitizedImpExt[openrtb_ext.PrebidExtKey] = impExtPrebidJSON
@@ -426,6 +662,10 @@ func createSanitizedImpExt(impExt, impExtPrebid map[string]json.RawMessage) (map
 		sanitizedImpExt[openrtb_ext.SKAdNExtKey] = v
 	}
 
+	if v, exists := impExt[openrtb_ext.TIDExtKey]; exists {
+		sanitizedImpExt[openrtb_ext.TIDExtKey] = v
+	}
+
 	return sanitizedImpExt, nil
 }
 
@@ -455,6 +695,7 @@ func isSpecialField(bidder string) bool {
 	return bidder == openrtb_ext.FirstPartyDataContextExtKey ||
 		bidder == openrtb_ext.FirstPartyDataExtKey ||
 		bidder == openrtb_ext.SKAdNExtKey ||
+		bidder == openrtb_ext.TIDExtKey ||
 		bidder == openrtb_ext.PrebidExtKey
 }
 
@@ -491,37 +732,38 @@ func copyWithBuyerUID(user *openrtb2.User, buyerUID string) *openrtb2.User {
 	return user
 }
 
-// removeUnpermissionedEids modifies the request to remove any request.user.ext.eids not permissions for the specific bidder
-func removeUnpermissionedEids(request *openrtb2.BidRequest, bidder string, requestExt *openrtb_ext.ExtRequest) error {
+// removeUnpermissionedEids modifies the request to remove any request.user.ext.eids not permissions for the specific bidder,
+// and returns the number of eids removed
+func removeUnpermissionedEids(request *openrtb2.BidRequest, bidder string, requestExt *openrtb_ext.ExtRequest) (int, error) {
 	// ensure request might have eids (as much as we can check before unmarshalling)
 	if request.User == nil || len(request.User.Ext) == 0 {
-		return nil
+		return 0, nil
 	}
 
 	// ensure request has eid permissions to enforce
 	if requestExt == nil || requestExt.Prebid.Data == nil || len(requestExt.Prebid.Data.EidPermissions) == 0 {
-		return nil
+		return 0, nil
 	}
 
 	// low level unmarshal to preserve other request.user.ext values. prebid server is non-destructive.
 	var userExt map[string]json.RawMessage
 	if err := json.Unmarshal(request.User.Ext, &userExt); err != nil {
-		return err
+		return 0, err
 	}
 
 	eidsJSON, eidsSpecified := userExt["eids"]
 	if !eidsSpecified {
-		return nil
+		return 0, nil
 	}
 
 	var eids []openrtb_ext.ExtUserEid
 	if err := json.Unmarshal(eidsJSON, &eids); err != nil {
-		return err
+		return 0, err
 	}
 
 	// exit early if there are no eids (empty array)
 	if len(eids) == 0 {
-		return nil
+		return 0, nil
 	}
 
 	// translate eid permissions to a map for quick lookup
@@ -551,7 +793,7 @@ func removeUnpermissionedEids(request *openrtb2.BidRequest, bidder string, reque
 
 	// exit early if all eids are allowed and nothing needs to be removed
 	if len(eids) == len(eidsAllowed) {
-		return nil
+		return 0, nil
 	}
 
 	// marshal eidsAllowed back to userExt
@@ -560,7 +802,7 @@ func removeUnpermissionedEids(request *openrtb2.BidRequest, bidder string, reque
 	} else {
 		eidsRaw, err := json.Marshal(eidsAllowed)
 		if err != nil {
-			return err
+			return 0, err
 		}
 		userExt["eids"] = eidsRaw
 	}
@@ -568,15 +810,15 @@ func removeUnpermissionedEids(request *openrtb2.BidRequest, bidder string, reque
 	// exit early if userExt is empty
 	if len(userExt) == 0 {
 		setUserExtWithCopy(request, nil)
-		return nil
+		return len(eids) - len(eidsAllowed), nil
 	}
 
 	userExtJSON, err := json.Marshal(userExt)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	setUserExtWithCopy(request, userExtJSON)
-	return nil
+	return len(eids) - len(eidsAllowed), nil
 }
 
 func setUserExtWithCopy(request *openrtb2.BidRequest, userExtJSON json.RawMessage) {
@@ -650,6 +892,7 @@ func getExtCacheInstructions(requestExt *openrtb_ext.ExtRequest) extCacheInstruc
 	foundVastRC := false
 
 	if requestExt != nil && requestExt.Prebid.Cache != nil {
+		cacheInstructions.cacheKey = requestExt.Prebid.Cache.Key
 		if requestExt.Prebid.Cache.Bids != nil {
 			cacheInstructions.cacheBids = true
 			if requestExt.Prebid.Cache.Bids.ReturnCreative != nil {
@@ -683,8 +926,19 @@ func getExtTargetData(requestExt *openrtb_ext.ExtRequest, cacheInstructions *ext
 			includeBidderKeys: requestExt.Prebid.Targeting.IncludeBidderKeys,
 			includeCacheBids:  cacheInstructions.cacheBids,
 			includeCacheVast:  cacheInstructions.cacheVAST,
+			cacheKey:          cacheInstructions.cacheKey,
 			includeFormat:     requestExt.Prebid.Targeting.IncludeFormat,
 			preferDeals:       requestExt.Prebid.Targeting.PreferDeals,
+
+			mediaTypePriceGranularity: requestExt.Prebid.Targeting.MediaTypePriceGranularity,
+		}
+		for bidder, control := range requestExt.Prebid.BidderControls {
+			if control.PriceGranularity != nil {
+				if targData.bidderPriceGranularity == nil {
+					targData.bidderPriceGranularity = make(map[openrtb_ext.BidderName]openrtb_ext.PriceGranularity)
+				}
+				targData.bidderPriceGranularity[openrtb_ext.BidderName(bidder)] = *control.PriceGranularity
+			}
 		}
 	}
 	return targData
@@ -694,8 +948,8 @@ func getDebugInfo(bidRequest *openrtb2.BidRequest, requestExt *openrtb_ext.ExtRe
 	return (bidRequest != nil && bidRequest.Test == 1) || (requestExt != nil && requestExt.Prebid.Debug)
 }
 
-func getExtBidAdjustmentFactors(requestExt *openrtb_ext.ExtRequest) map[string]float64 {
-	var bidAdjustmentFactors map[string]float64
+func getExtBidAdjustmentFactors(requestExt *openrtb_ext.ExtRequest) *openrtb_ext.ExtBidAdjustmentFactors {
+	var bidAdjustmentFactors *openrtb_ext.ExtBidAdjustmentFactors
 	if requestExt != nil {
 		bidAdjustmentFactors = requestExt.Prebid.BidAdjustmentFactors
 	}
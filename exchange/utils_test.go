@@ -10,9 +10,12 @@ import (
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/firstpartydata"
 	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/geolocation"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/privacy"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -241,6 +244,18 @@ func TestCreateSanitizedImpExt(t *testing.T) {
 			expected:          map[string]json.RawMessage{},
 			expectedError:     "",
 		},
+		{
+			description: "imp.ext.tid",
+			givenImpExt: map[string]json.RawMessage{
+				"prebid": json.RawMessage(`"ignoredInFavorOfSeparatelyUnmarshalledImpExtPrebid"`),
+				"tid":    json.RawMessage(`"anyTID"`),
+			},
+			givenImpExtPrebid: map[string]json.RawMessage{},
+			expected: map[string]json.RawMessage{
+				"tid": json.RawMessage(`"anyTID"`),
+			},
+			expectedError: "",
+		},
 		{
 			description: "imp.ext.prebid - Bidders Only",
 			givenImpExt: map[string]json.RawMessage{
@@ -478,6 +493,7 @@ func TestCleanOpenRTBRequests(t *testing.T) {
 
 	for _, test := range testCases {
 		metricsMock := metrics.MetricsEngineMock{}
+		metricsMock.On("RecordPrivacyScrub", metrics.PrivacyScrubProfileCOPPA).Return()
 		bidderToSyncerKey := map[string]string{}
 		permissions := permissionsMock{allowAllBidders: true, passGeo: true, passID: true}
 		bidderRequests, _, err := cleanOpenRTBRequests(context.Background(), test.req, nil, bidderToSyncerKey, &permissions, &metricsMock, gdpr.SignalNo, privacyConfig, nil)
@@ -708,6 +724,76 @@ func TestCleanOpenRTBRequestsCCPAErrors(t *testing.T) {
 	}
 }
 
+func TestCleanOpenRTBRequestsEidPermissions(t *testing.T) {
+	req := newBidRequest(t)
+	req.User.Ext = json.RawMessage(`{"eids":[{"source":"source1","id":"anyID"},{"source":"source2","id":"anyID"}]}`)
+	req.Ext = json.RawMessage(`{"prebid":{"data":{"eidpermissions":[{"source":"source1","bidders":["otherBidder"]}]}}}`)
+
+	var reqExtStruct openrtb_ext.ExtRequest
+	err := json.Unmarshal(req.Ext, &reqExtStruct)
+	assert.NoError(t, err, "marshal_ext")
+
+	auctionReq := AuctionRequest{
+		BidRequest: req,
+		UserSyncs:  &emptyUsersync{},
+	}
+
+	bidderToSyncerKey := map[string]string{}
+	permissions := permissionsMock{allowAllBidders: true, passGeo: true, passID: true}
+	metricsMock := metrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterEidsStripped", openrtb_ext.BidderAppnexus, 1).Return()
+	bidderRequests, _, errs := cleanOpenRTBRequests(context.Background(), auctionReq, &reqExtStruct, bidderToSyncerKey, &permissions, &metricsMock, gdpr.SignalNo, config.Privacy{}, nil)
+
+	assert.Empty(t, errs)
+	if assert.Len(t, bidderRequests, 1) {
+		assert.JSONEq(t, `{"eids":[{"source":"source2","id":"anyID"}]}`, string(bidderRequests[0].BidRequest.User.Ext))
+	}
+	metricsMock.AssertExpectations(t)
+}
+
+func TestCleanOpenRTBRequestsFirstPartyData(t *testing.T) {
+	req := newBidRequest(t)
+	req.Site.Ext = json.RawMessage(`{"data":{"section":"news"}}`)
+	req.Imp[0].Ext = json.RawMessage(`{"appnexus": {"placementId": 1}, "rubicon": {}}`)
+	req.Ext = json.RawMessage(`{"prebid":{"data":{"bidders":["appnexus"]},"bidderconfig":[{"bidders":["rubicon"],"config":{"ortb2":{"site":{"keywords":"rubicon keywords"}}}}]}}`)
+
+	var reqExtStruct openrtb_ext.ExtRequest
+	err := json.Unmarshal(req.Ext, &reqExtStruct)
+	assert.NoError(t, err, "marshal_ext")
+
+	fpdResolver, warnings := firstpartydata.NewResolver(req, &reqExtStruct, config.FirstPartyData{
+		SiteAttributes: []string{"keywords"},
+		Conflict:       config.FirstPartyDataConflictBidder,
+	})
+	assert.Empty(t, warnings, "warnings")
+
+	auctionReq := AuctionRequest{
+		BidRequest:     req,
+		UserSyncs:      &emptyUsersync{},
+		firstPartyData: fpdResolver,
+	}
+
+	bidderToSyncerKey := map[string]string{}
+	permissions := permissionsMock{allowAllBidders: true, passGeo: true, passID: true}
+	metrics := metrics.MetricsEngineMock{}
+	bidderRequests, _, errs := cleanOpenRTBRequests(context.Background(), auctionReq, &reqExtStruct, bidderToSyncerKey, &permissions, &metrics, gdpr.SignalNo, config.Privacy{}, nil)
+
+	assert.Empty(t, errs)
+	assert.Len(t, bidderRequests, 2)
+	for _, bidderRequest := range bidderRequests {
+		switch bidderRequest.BidderName {
+		case openrtb_ext.BidderAppnexus:
+			assert.JSONEq(t, `{"data":{"section":"news"}}`, string(bidderRequest.BidRequest.Site.Ext), "appnexus is sent the global first party data")
+			assert.Empty(t, bidderRequest.BidRequest.Site.Keywords)
+		case openrtb_ext.BidderRubicon:
+			assert.Nil(t, bidderRequest.BidRequest.Site.Ext, "rubicon isn't sent the global first party data")
+			assert.Equal(t, "rubicon keywords", bidderRequest.BidRequest.Site.Keywords)
+		}
+		assert.JSONEq(t, `{"prebid":{"data":{"eidpermissions":null}}}`, string(bidderRequest.BidRequest.Ext), "the first party data config isn't sent to the bidders")
+	}
+	assert.JSONEq(t, `{"data":{"section":"news"}}`, string(req.Site.Ext), "the auction request is left as is")
+}
+
 func TestCleanOpenRTBRequestsCOPPA(t *testing.T) {
 	testCases := []struct {
 		description         string
@@ -744,14 +830,16 @@ func TestCleanOpenRTBRequestsCOPPA(t *testing.T) {
 
 		bidderToSyncerKey := map[string]string{}
 		permissions := permissionsMock{allowAllBidders: true, passGeo: true, passID: true}
-		metrics := metrics.MetricsEngineMock{}
-		bidderRequests, privacyLabels, errs := cleanOpenRTBRequests(context.Background(), auctionReq, nil, bidderToSyncerKey, &permissions, &metrics, gdpr.SignalNo, config.Privacy{}, nil)
+		metricsMock := metrics.MetricsEngineMock{}
+		metricsMock.On("RecordPrivacyScrub", metrics.PrivacyScrubProfileCOPPA).Return()
+		bidderRequests, privacyLabels, errs := cleanOpenRTBRequests(context.Background(), auctionReq, nil, bidderToSyncerKey, &permissions, &metricsMock, gdpr.SignalNo, config.Privacy{}, nil)
 		result := bidderRequests[0]
 
 		assert.Nil(t, errs)
 		if test.expectDataScrub {
 			assert.Equal(t, result.BidRequest.User.BuyerUID, "", test.description+":User.BuyerUID")
 			assert.Equal(t, result.BidRequest.User.Yob, int64(0), test.description+":User.Yob")
+			metricsMock.AssertCalled(t, "RecordPrivacyScrub", metrics.PrivacyScrubProfileCOPPA)
 		} else {
 			assert.NotEqual(t, result.BidRequest.User.BuyerUID, "", test.description+":User.BuyerUID")
 			assert.NotEqual(t, result.BidRequest.User.Yob, int64(0), test.description+":User.Yob")
@@ -760,6 +848,165 @@ func TestCleanOpenRTBRequestsCOPPA(t *testing.T) {
 	}
 }
 
+func TestApplyGPCHeader(t *testing.T) {
+	testCases := []struct {
+		description  string
+		givenHeader  string
+		givenRegs    *openrtb2.Regs
+		expectedRegs *openrtb2.Regs
+	}{
+		{
+			description:  "No header",
+			givenRegs:    &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr":1}`)},
+			expectedRegs: &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr":1}`)},
+		},
+		{
+			description:  "Header without regs",
+			givenHeader:  "1",
+			expectedRegs: &openrtb2.Regs{Ext: json.RawMessage(`{"gpc":"1","us_privacy":"1-Y-"}`)},
+		},
+		{
+			description:  "Header with other signals",
+			givenHeader:  "1",
+			givenRegs:    &openrtb2.Regs{COPPA: 1, Ext: json.RawMessage(`{"gdpr":1}`)},
+			expectedRegs: &openrtb2.Regs{COPPA: 1, Ext: json.RawMessage(`{"gdpr":1,"gpc":"1","us_privacy":"1-Y-"}`)},
+		},
+		{
+			description:  "Header with a us_privacy string and a GPC signal",
+			givenHeader:  "1",
+			givenRegs:    &openrtb2.Regs{Ext: json.RawMessage(`{"gpc":0,"us_privacy":"1NNN"}`)},
+			expectedRegs: &openrtb2.Regs{Ext: json.RawMessage(`{"gpc":0,"us_privacy":"1NNN"}`)},
+		},
+		{
+			description:  "Header with a GPP section of a US law",
+			givenHeader:  "1",
+			givenRegs:    &openrtb2.Regs{Ext: json.RawMessage(`{"gpp":"DBABTA~1YNN","gpp_sid":[6]}`)},
+			expectedRegs: &openrtb2.Regs{Ext: json.RawMessage(`{"gpc":"1","gpp":"DBABTA~1YNN","gpp_sid":[6]}`)},
+		},
+		{
+			description:  "Header opting in",
+			givenHeader:  "0",
+			expectedRegs: nil,
+		},
+	}
+
+	for _, test := range testCases {
+		req := &openrtb2.BidRequest{Regs: test.givenRegs}
+
+		assert.NoError(t, applyGPCHeader(req, test.givenHeader), test.description)
+		assert.Equal(t, test.expectedRegs, req.Regs, test.description)
+	}
+}
+
+func TestNewActivityRequestLocated(t *testing.T) {
+	testCases := []struct {
+		description  string
+		givenRequest AuctionRequest
+		expected     privacy.ActivityRequest
+	}{
+		{
+			description:  "Device geo",
+			givenRequest: AuctionRequest{BidRequest: &openrtb2.BidRequest{Device: &openrtb2.Device{Geo: &openrtb2.Geo{Country: "USA", Region: "CA"}}}},
+			expected:     privacy.ActivityRequest{Country: "USA", Region: "CA"},
+		},
+		{
+			description: "Located device",
+			givenRequest: AuctionRequest{
+				BidRequest: &openrtb2.BidRequest{Device: &openrtb2.Device{IP: "12.1.1.1"}},
+				geo:        &geolocation.GeoInfo{Country: "USA", Region: "VA"},
+			},
+			expected: privacy.ActivityRequest{Country: "USA", Region: "VA"},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, newActivityRequest(test.givenRequest), test.description)
+	}
+}
+
+func TestCleanOpenRTBRequestsLGPD(t *testing.T) {
+	testCases := []struct {
+		description      string
+		givenEnforce     bool
+		givenCountry     string
+		givenGeo         *geolocation.GeoInfo
+		expectScrub      bool
+		expectedBuyerUID string
+		expectedIFA      string
+		expectedIP       string
+	}{
+		{
+			description:      "LGPD country",
+			givenEnforce:     true,
+			givenCountry:     "bra",
+			expectScrub:      true,
+			expectedBuyerUID: "their-id",
+			expectedIFA:      "",
+			expectedIP:       "132.173.230.0",
+		},
+		{
+			description:      "Other country",
+			givenEnforce:     true,
+			givenCountry:     "USA",
+			expectedBuyerUID: "their-id",
+			expectedIFA:      "ifa",
+			expectedIP:       "132.173.230.74",
+		},
+		{
+			description:      "Device located in an LGPD country",
+			givenEnforce:     true,
+			givenGeo:         &geolocation.GeoInfo{Country: "BRA"},
+			expectScrub:      true,
+			expectedBuyerUID: "their-id",
+			expectedIFA:      "",
+			expectedIP:       "132.173.230.0",
+		},
+		{
+			description:      "LGPD not enforced",
+			givenCountry:     "BRA",
+			expectedBuyerUID: "their-id",
+			expectedIFA:      "ifa",
+			expectedIP:       "132.173.230.74",
+		},
+	}
+
+	for _, test := range testCases {
+		req := newBidRequest(t)
+		req.Device.Geo = &openrtb2.Geo{Country: test.givenCountry}
+
+		privacyConfig := config.Privacy{
+			LGPD: config.LGPD{Enforce: test.givenEnforce, CountriesMap: map[string]struct{}{"BRA": {}}},
+			ScrubProfiles: config.ScrubProfiles{
+				LGPD: config.ScrubProfile{User: config.ScrubUserEIDs, DeviceIDs: true, Geo: config.ScrubGeoReduced},
+			},
+		}
+
+		auctionReq := AuctionRequest{
+			BidRequest: req,
+			UserSyncs:  &emptyUsersync{},
+			geo:        test.givenGeo,
+		}
+
+		permissions := permissionsMock{allowAllBidders: true, passGeo: true, passID: true}
+		metricsMock := metrics.MetricsEngineMock{}
+		metricsMock.On("RecordPrivacyScrub", metrics.PrivacyScrubProfileLGPD).Return()
+		bidderRequests, _, errs := cleanOpenRTBRequests(context.Background(), auctionReq, nil, map[string]string{}, &permissions, &metricsMock, gdpr.SignalNo, privacyConfig, nil)
+
+		assert.Empty(t, errs, test.description)
+		if assert.Len(t, bidderRequests, 1, test.description) {
+			result := bidderRequests[0].BidRequest
+			assert.Equal(t, test.expectedBuyerUID, result.User.BuyerUID, test.description+":User.BuyerUID")
+			assert.Equal(t, test.expectedIFA, result.Device.IFA, test.description+":Device.IFA")
+			assert.Equal(t, test.expectedIP, result.Device.IP, test.description+":Device.IP")
+		}
+		if test.expectScrub {
+			metricsMock.AssertCalled(t, "RecordPrivacyScrub", metrics.PrivacyScrubProfileLGPD)
+		} else {
+			metricsMock.AssertNotCalled(t, "RecordPrivacyScrub", metrics.PrivacyScrubProfileLGPD)
+		}
+	}
+}
+
 func TestCleanOpenRTBRequestsSChain(t *testing.T) {
 	testCases := []struct {
 		description   string
@@ -1346,7 +1593,7 @@ func TestGetExtBidAdjustmentFactors(t *testing.T) {
 	testCases := []struct {
 		desc                    string
 		inRequestExt            *openrtb_ext.ExtRequest
-		outBidAdjustmentFactors map[string]float64
+		outBidAdjustmentFactors *openrtb_ext.ExtBidAdjustmentFactors
 	}{
 		{
 			desc:                    "Nil request ext",
@@ -1360,8 +1607,8 @@ func TestGetExtBidAdjustmentFactors(t *testing.T) {
 		},
 		{
 			desc:                    "Non-nil request ext, valid BidAdjustmentFactors field",
-			inRequestExt:            &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{BidAdjustmentFactors: map[string]float64{"bid-factor": 1.0}}},
-			outBidAdjustmentFactors: map[string]float64{"bid-factor": 1.0},
+			inRequestExt:            &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{BidAdjustmentFactors: &openrtb_ext.ExtBidAdjustmentFactors{Bidders: map[string]float64{"bid-factor": 1.0}}}},
+			outBidAdjustmentFactors: &openrtb_ext.ExtBidAdjustmentFactors{Bidders: map[string]float64{"bid-factor": 1.0}},
 		},
 	}
 	for _, test := range testCases {
@@ -1653,13 +1900,16 @@ func TestCleanOpenRTBRequestsGDPR(t *testing.T) {
 			gdprDefaultValue = gdpr.SignalNo
 		}
 
+		metricsMock := &metrics.MetricsEngineMock{}
+		metricsMock.On("RecordTCFPurposeBlocked", mock.Anything).Return()
+
 		results, privacyLabels, errs := cleanOpenRTBRequests(
 			context.Background(),
 			auctionReq,
 			nil,
 			bidderToSyncerKey,
 			&permissionsMock{allowAllBidders: true, passGeo: !test.gdprScrub, passID: !test.gdprScrub, activitiesError: test.permissionsError},
-			&metrics.MetricsEngineMock{},
+			metricsMock,
 			gdprDefaultValue,
 			privacyConfig,
 			nil)
@@ -1678,10 +1928,255 @@ func TestCleanOpenRTBRequestsGDPR(t *testing.T) {
 			assert.NotEqual(t, result.BidRequest.User.BuyerUID, "", test.description+":User.BuyerUID")
 			assert.NotEqual(t, result.BidRequest.Device.DIDMD5, "", test.description+":Device.DIDMD5")
 		}
+		if test.gdprScrub && test.permissionsError == nil {
+			metricsMock.AssertCalled(t, "RecordTCFPurposeBlocked", metrics.TCFPurposes2To10)
+			metricsMock.AssertCalled(t, "RecordTCFPurposeBlocked", metrics.TCFSpecialFeature1)
+		} else {
+			metricsMock.AssertNotCalled(t, "RecordTCFPurposeBlocked", mock.Anything)
+		}
+		assert.Equal(t, test.expectPrivacyLabels, privacyLabels, test.description+":PrivacyLabels")
+	}
+}
+
+func TestCleanOpenRTBRequestsGPP(t *testing.T) {
+	tcf2Consent := "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"
+	falseValue := false
+
+	testCases := []struct {
+		description         string
+		regsExt             json.RawMessage
+		userExt             json.RawMessage
+		accountGPP          config.AccountGPP
+		channel             string
+		expectPrivacyLabels metrics.PrivacyLabels
+	}{
+		{
+			description: "GPP TCF EU v2 section takes precedence over gdpr and user consent",
+			regsExt:     json.RawMessage(`{"gdpr":0,"gpp":"DBABMA~` + tcf2Consent + `","gpp_sid":[2]}`),
+			userExt:     json.RawMessage(`{"consent":"malformed"}`),
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				GDPREnforced:   true,
+				GDPRTCFVersion: metrics.TCFVersionV2,
+			},
+		},
+		{
+			description: "GPP section ids without TCF EU v2 take precedence over gdpr",
+			regsExt:     json.RawMessage(`{"gdpr":1,"gpp":"DBABTA~1YYN","gpp_sid":[6]}`),
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				CCPAProvided: true,
+				CCPAEnforced: true,
+			},
+		},
+		{
+			description: "GPP USP v1 section takes precedence over us_privacy",
+			regsExt:     json.RawMessage(`{"us_privacy":"1NNN","gpp":"DBABTA~1YYN"}`),
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				CCPAProvided: true,
+				CCPAEnforced: true,
+			},
+		},
+		{
+			description: "GPP USP v1 section left out by the section ids",
+			regsExt:     json.RawMessage(`{"us_privacy":"1NNN","gpp":"DBABTA~1YYN","gpp_sid":[7]}`),
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				CCPAProvided: true,
+				CCPAEnforced: false,
+			},
+		},
+		{
+			description: "GPP disabled by the account leaves the legacy signals in place",
+			regsExt:     json.RawMessage(`{"us_privacy":"1NNN","gpp":"DBABTA~1YYN"}`),
+			accountGPP:  config.AccountGPP{Enabled: &falseValue},
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				CCPAProvided: true,
+				CCPAEnforced: false,
+			},
+		},
+		{
+			description: "GPP disabled by the account for the dooh channel",
+			regsExt:     json.RawMessage(`{"us_privacy":"1NNN","gpp":"DBABTA~1YYN"}`),
+			accountGPP:  config.AccountGPP{IntegrationEnabled: config.AccountIntegration{DOOH: &falseValue}},
+			channel:     "dooh",
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				CCPAProvided: true,
+				CCPAEnforced: false,
+			},
+		},
+		{
+			description: "GPP disabled by the account for another channel",
+			regsExt:     json.RawMessage(`{"us_privacy":"1NNN","gpp":"DBABTA~1YYN"}`),
+			accountGPP:  config.AccountGPP{IntegrationEnabled: config.AccountIntegration{DOOH: &falseValue}},
+			channel:     "web",
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				CCPAProvided: true,
+				CCPAEnforced: true,
+			},
+		},
+		{
+			description: "Invalid GPP string leaves the legacy signals in place",
+			regsExt:     json.RawMessage(`{"us_privacy":"1NYN","gpp":"malformed"}`),
+			expectPrivacyLabels: metrics.PrivacyLabels{
+				CCPAProvided: true,
+				CCPAEnforced: true,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		req := newBidRequest(t)
+		req.User.Ext = test.userExt
+		req.Regs = &openrtb2.Regs{Ext: test.regsExt}
+
+		privacyConfig := config.Privacy{
+			CCPA: config.CCPA{
+				Enforce: true,
+			},
+			GDPR: config.GDPR{
+				Enabled:      true,
+				DefaultValue: "0",
+				TCF2: config.TCF2{
+					Enabled: true,
+				},
+			},
+			GPP: config.GPP{
+				Enabled: true,
+			},
+		}
+
+		auctionReq := AuctionRequest{
+			BidRequest: req,
+			UserSyncs:  &emptyUsersync{},
+			Account:    config.Account{GPP: test.accountGPP},
+		}
+
+		var requestExt *openrtb_ext.ExtRequest
+		if test.channel != "" {
+			requestExt = &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{Channel: &openrtb_ext.ExtRequestPrebidChannel{Name: test.channel}}}
+		}
+
+		_, privacyLabels, errs := cleanOpenRTBRequests(
+			context.Background(),
+			auctionReq,
+			requestExt,
+			map[string]string{},
+			&permissionsMock{allowAllBidders: true, passGeo: true, passID: true},
+			&metrics.MetricsEngineMock{},
+			gdpr.SignalNo,
+			privacyConfig,
+			nil)
+
+		assert.Empty(t, errs, test.description+":errors")
 		assert.Equal(t, test.expectPrivacyLabels, privacyLabels, test.description+":PrivacyLabels")
 	}
 }
 
+func TestCleanOpenRTBRequestsActivities(t *testing.T) {
+	falseValue := false
+	denyBidder := func(bidder string) config.Activity {
+		return config.Activity{Rules: []config.ActivityRule{{Condition: config.ActivityCondition{ComponentName: []string{bidder}}}}}
+	}
+
+	testCases := []struct {
+		description      string
+		givenActivities  config.AllowActivities
+		givenRegsExt     json.RawMessage
+		expectedBidders  []openrtb_ext.BidderName
+		expectedAppnexus *openrtb2.BidRequest
+	}{
+		{
+			description:     "Fetch bids denied to a bidder",
+			givenActivities: config.AllowActivities{FetchBids: denyBidder("rubicon")},
+			expectedBidders: []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus},
+		},
+		{
+			description:     "Fetch bids denied by default",
+			givenActivities: config.AllowActivities{FetchBids: config.Activity{Default: &falseValue}},
+		},
+		{
+			description:     "Transmit user first party data denied to a bidder",
+			givenActivities: config.AllowActivities{TransmitUserFPD: denyBidder("appnexus")},
+			expectedBidders: []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus, openrtb_ext.BidderRubicon},
+			expectedAppnexus: &openrtb2.BidRequest{
+				Device: &openrtb2.Device{IP: "132.173.230.74", Geo: &openrtb2.Geo{Lat: 123.456, Country: "USA"}},
+				User:   &openrtb2.User{Ext: json.RawMessage(`{}`)},
+			},
+		},
+		{
+			description: "Transmit precise geo denied to a bidder in a country",
+			givenActivities: config.AllowActivities{TransmitPreciseGeo: config.Activity{Rules: []config.ActivityRule{
+				{Condition: config.ActivityCondition{ComponentName: []string{"appnexus"}, Geo: []string{"USA"}}},
+			}}},
+			expectedBidders: []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus, openrtb_ext.BidderRubicon},
+			expectedAppnexus: &openrtb2.BidRequest{
+				Device: &openrtb2.Device{DIDMD5: "some device ID hash", IP: "132.173.230.0", Geo: &openrtb2.Geo{Lat: 123.46, Country: "USA"}},
+				User:   &openrtb2.User{ID: "our-id", Yob: 1982, Ext: json.RawMessage(`{"eids":[{"source":"source","uids":[{"id":"1"}]}]}`)},
+			},
+		},
+		{
+			description: "Transmit eids denied to a bidder with the GPC signal",
+			givenActivities: config.AllowActivities{TransmitEids: config.Activity{Rules: []config.ActivityRule{
+				{Condition: config.ActivityCondition{ComponentName: []string{"appnexus"}, GPC: "1"}},
+			}}},
+			givenRegsExt:    json.RawMessage(`{"gpc":"1"}`),
+			expectedBidders: []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus, openrtb_ext.BidderRubicon},
+			expectedAppnexus: &openrtb2.BidRequest{
+				Device: &openrtb2.Device{DIDMD5: "some device ID hash", IP: "132.173.230.74", Geo: &openrtb2.Geo{Lat: 123.456, Country: "USA"}},
+				User:   &openrtb2.User{ID: "our-id", Yob: 1982, Ext: json.RawMessage(`{}`)},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		req := &openrtb2.BidRequest{
+			Site:   &openrtb2.Site{Page: "www.some.domain.com"},
+			Device: &openrtb2.Device{DIDMD5: "some device ID hash", IP: "132.173.230.74", Geo: &openrtb2.Geo{Lat: 123.456, Country: "USA"}},
+			User:   &openrtb2.User{ID: "our-id", Yob: 1982, Ext: json.RawMessage(`{"eids":[{"source":"source","uids":[{"id":"1"}]}]}`)},
+			Imp: []openrtb2.Imp{{
+				ID:     "some-imp-id",
+				Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}},
+				Ext:    json.RawMessage(`{"appnexus": {"placementId": 1}, "rubicon": {}}`),
+			}},
+		}
+		if test.givenRegsExt != nil {
+			req.Regs = &openrtb2.Regs{Ext: test.givenRegsExt}
+		}
+
+		auctionReq := AuctionRequest{
+			BidRequest: req,
+			UserSyncs:  &emptyUsersync{},
+			Account:    config.Account{Privacy: config.AccountPrivacy{AllowActivities: test.givenActivities}},
+		}
+
+		bidderRequests, _, errs := cleanOpenRTBRequests(
+			context.Background(),
+			auctionReq,
+			nil,
+			map[string]string{},
+			&permissionsMock{allowAllBidders: true, passGeo: true, passID: true},
+			&metrics.MetricsEngineMock{},
+			gdpr.SignalNo,
+			config.Privacy{},
+			nil)
+		assert.Empty(t, errs, test.description+":errors")
+
+		var bidders []openrtb_ext.BidderName
+		for _, bidderRequest := range bidderRequests {
+			bidders = append(bidders, bidderRequest.BidderName)
+			if test.expectedAppnexus == nil {
+				continue
+			}
+			if bidderRequest.BidderName == openrtb_ext.BidderAppnexus {
+				assert.Equal(t, test.expectedAppnexus.Device, bidderRequest.BidRequest.Device, test.description+":appnexus device")
+				assert.Equal(t, test.expectedAppnexus.User, bidderRequest.BidRequest.User, test.description+":appnexus user")
+			} else {
+				assert.Equal(t, req.Device, bidderRequest.BidRequest.Device, test.description+":rubicon device")
+				assert.Equal(t, req.User, bidderRequest.BidRequest.User, test.description+":rubicon user")
+			}
+		}
+		assert.ElementsMatch(t, test.expectedBidders, bidders, test.description+":bidders")
+	}
+}
+
 func TestCleanOpenRTBRequestsGDPRBlockBidRequest(t *testing.T) {
 	testCases := []struct {
 		description            string
@@ -1737,13 +2232,15 @@ func TestCleanOpenRTBRequestsGDPRBlockBidRequest(t *testing.T) {
 		}
 
 		auctionReq := AuctionRequest{
-			BidRequest: req,
-			UserSyncs:  &emptyUsersync{},
-			Account:    accountConfig,
+			BidRequest:     req,
+			UserSyncs:      &emptyUsersync{},
+			Account:        accountConfig,
+			blockedNonBids: map[openrtb_ext.BidderName][]openrtb_ext.NonBid{},
 		}
 
 		metricsMock := metrics.MetricsEngineMock{}
 		metricsMock.Mock.On("RecordAdapterGDPRRequestBlocked", mock.Anything).Return()
+		metricsMock.Mock.On("RecordTCFPurposeBlocked", mock.Anything).Return()
 
 		bidderToSyncerKey := map[string]string{}
 		results, _, errs := cleanOpenRTBRequests(
@@ -1768,10 +2265,17 @@ func TestCleanOpenRTBRequestsGDPRBlockBidRequest(t *testing.T) {
 
 		for _, blockedBidder := range test.expectedBlockedBidders {
 			metricsMock.AssertCalled(t, "RecordAdapterGDPRRequestBlocked", blockedBidder)
+			assert.Equal(t, []openrtb_ext.NonBid{{ImpId: req.Imp[0].ID, StatusCode: openrtb_ext.NonBidRequestBlockedPrivacy}},
+				auctionReq.blockedNonBids[blockedBidder], test.description+":non_bids")
 		}
+		assert.Len(t, auctionReq.blockedNonBids, len(test.expectedBlockedBidders), test.description+":non_bids")
 		for _, allowedBidder := range test.expectedBidders {
 			metricsMock.AssertNotCalled(t, "RecordAdapterGDPRRequestBlocked", allowedBidder)
 		}
+		metricsMock.AssertNumberOfCalls(t, "RecordTCFPurposeBlocked", len(test.expectedBlockedBidders))
+		if len(test.expectedBlockedBidders) > 0 {
+			metricsMock.AssertCalled(t, "RecordTCFPurposeBlocked", metrics.TCFRequestBlocked)
+		}
 	}
 }
 
@@ -1982,10 +2486,11 @@ func TestRemoveUnpermissionedEids(t *testing.T) {
 	bidder := "bidderA"
 
 	testCases := []struct {
-		description     string
-		userExt         json.RawMessage
-		eidPermissions  []openrtb_ext.ExtRequestPrebidDataEidPermission
-		expectedUserExt json.RawMessage
+		description      string
+		userExt          json.RawMessage
+		eidPermissions   []openrtb_ext.ExtRequestPrebidDataEidPermission
+		expectedUserExt  json.RawMessage
+		expectedStripped int
 	}{
 		{
 			description: "Extension Nil",
@@ -2077,7 +2582,8 @@ func TestRemoveUnpermissionedEids(t *testing.T) {
 			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
 				{Source: "source1", Bidders: []string{"otherBidder"}},
 			},
-			expectedUserExt: nil,
+			expectedUserExt:  nil,
+			expectedStripped: 1,
 		},
 		{
 			description: "Denied - Keep Other Data",
@@ -2085,7 +2591,8 @@ func TestRemoveUnpermissionedEids(t *testing.T) {
 			eidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{
 				{Source: "source1", Bidders: []string{"otherBidder"}},
 			},
-			expectedUserExt: json.RawMessage(`{"otherdata":42}`),
+			expectedUserExt:  json.RawMessage(`{"otherdata":42}`),
+			expectedStripped: 1,
 		},
 		{
 			description: "Mix Of Allowed By Specific Bidder, Allowed By Lack Of Matching Source, Denied, Keep Other Data",
@@ -2094,7 +2601,8 @@ func TestRemoveUnpermissionedEids(t *testing.T) {
 				{Source: "source1", Bidders: []string{"bidderA"}},
 				{Source: "source3", Bidders: []string{"otherBidder"}},
 			},
-			expectedUserExt: json.RawMessage(`{"eids":[{"source":"source1","id":"anyID"},{"source":"source2","id":"anyID"}],"other":42}`),
+			expectedUserExt:  json.RawMessage(`{"eids":[{"source":"source1","id":"anyID"},{"source":"source2","id":"anyID"}],"other":42}`),
+			expectedStripped: 1,
 		},
 	}
 
@@ -2115,8 +2623,9 @@ func TestRemoveUnpermissionedEids(t *testing.T) {
 			User: &openrtb2.User{Ext: test.expectedUserExt},
 		}
 
-		resultErr := removeUnpermissionedEids(request, bidder, requestExt)
+		resultStripped, resultErr := removeUnpermissionedEids(request, bidder, requestExt)
 		assert.NoError(t, resultErr, test.description)
+		assert.Equal(t, test.expectedStripped, resultStripped, test.description+":stripped")
 		assert.Equal(t, expectedRequest, request, test.description)
 	}
 }
@@ -2159,7 +2668,7 @@ func TestRemoveUnpermissionedEidsUnmarshalErrors(t *testing.T) {
 			},
 		}
 
-		resultErr := removeUnpermissionedEids(request, "bidderA", requestExt)
+		_, resultErr := removeUnpermissionedEids(request, "bidderA", requestExt)
 		assert.EqualError(t, resultErr, test.expectedErr, test.description)
 	}
 }
@@ -2223,8 +2732,9 @@ func TestRemoveUnpermissionedEidsEmptyValidations(t *testing.T) {
 	for _, test := range testCases {
 		requestExpected := *test.request
 
-		resultErr := removeUnpermissionedEids(test.request, "bidderA", test.requestExt)
+		resultStripped, resultErr := removeUnpermissionedEids(test.request, "bidderA", test.requestExt)
 		assert.NoError(t, resultErr, test.description+":err")
+		assert.Zero(t, resultStripped, test.description+":stripped")
 		assert.Equal(t, &requestExpected, test.request, test.description+":request")
 	}
 }
@@ -2342,3 +2852,17 @@ func TestBuildXPrebidHeader(t *testing.T) {
 		assert.Equal(t, test.result, result, test.description+":result")
 	}
 }
+
+func TestGetExtJsonRemovesAnalytics(t *testing.T) {
+	req := &openrtb2.BidRequest{Ext: json.RawMessage(`{"prebid":{"debug":true,"analytics":{"pubstack":{"scopeid":"scope"}}}}`)}
+	unpackedExt := &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+		Debug:     true,
+		Analytics: map[string]json.RawMessage{"pubstack": json.RawMessage(`{"scopeid":"scope"}`)},
+	}}
+
+	ext, err := getExtJson(req, unpackedExt)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, string(ext), "analytics", "The options of the analytics modules shouldn't be sent to the bidders")
+	assert.NotNil(t, unpackedExt.Prebid.Analytics, "The ext of the request should be left as it is")
+}
@@ -0,0 +1,272 @@
+package firstpartydata
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// allBidders is the bidder of the bidder configs and of the global first party data which apply to every bidder
+const allBidders = "*"
+
+// Resolver applies the first party data of a request to the requests of its bidders
+type Resolver struct {
+	conflict string
+	// globalBidders are the bidders sent the global first party data, every bidder is sent it when nil
+	globalBidders map[string]bool
+	bidderConfigs map[string]*bidderFPD
+}
+
+// bidderFPD holds the site, app and user of a bidder config, reduced to the attributes allowed by the host
+type bidderFPD struct {
+	site json.RawMessage
+	app  json.RawMessage
+	user json.RawMessage
+}
+
+// NewResolver reads the first party data of a request. The attributes of the bidder configs the host doesn't allow
+// and the objects of the bidder configs which can't be merged into the request are dropped with a warning. It returns
+// nil if the request has neither ext.prebid.data.bidders nor ext.prebid.bidderconfig.
+func NewResolver(request *openrtb2.BidRequest, requestExt *openrtb_ext.ExtRequest, cfg config.FirstPartyData) (*Resolver, []error) {
+	if requestExt == nil {
+		return nil, nil
+	}
+	var globalBidders []string
+	if requestExt.Prebid.Data != nil {
+		globalBidders = requestExt.Prebid.Data.Bidders
+	}
+	if len(globalBidders) == 0 && len(requestExt.Prebid.BidderConfigs) == 0 {
+		return nil, nil
+	}
+
+	resolver := &Resolver{
+		conflict:      cfg.Conflict,
+		bidderConfigs: make(map[string]*bidderFPD),
+	}
+	if len(globalBidders) > 0 {
+		resolver.globalBidders = make(map[string]bool, len(globalBidders))
+		for _, bidder := range globalBidders {
+			resolver.globalBidders[bidder] = true
+		}
+		if resolver.globalBidders[allBidders] {
+			resolver.globalBidders = nil
+		}
+	}
+
+	var warnings []error
+	for i, bidderConfig := range requestExt.Prebid.BidderConfigs {
+		if bidderConfig.Config == nil || bidderConfig.Config.ORTB2 == nil {
+			continue
+		}
+		ortb2 := bidderConfig.Config.ORTB2
+
+		var fpd bidderFPD
+		var objectWarnings []error
+		fpd.site, objectWarnings = resolver.readObject(i, "site", ortb2.Site, cfg.SiteAttributes, request.Site != nil, request.Site, &openrtb2.Site{})
+		warnings = append(warnings, objectWarnings...)
+		fpd.app, objectWarnings = resolver.readObject(i, "app", ortb2.App, cfg.AppAttributes, request.App != nil, request.App, &openrtb2.App{})
+		warnings = append(warnings, objectWarnings...)
+		fpd.user, objectWarnings = resolver.readObject(i, "user", ortb2.User, cfg.UserAttributes, true, request.User, &openrtb2.User{})
+		warnings = append(warnings, objectWarnings...)
+
+		for _, bidder := range bidderConfig.Bidders {
+			if _, ok := resolver.bidderConfigs[bidder]; ok {
+				warnings = append(warnings, fpdWarning("bidderconfig is set more than once for %s, only the first one is used", bidder))
+				continue
+			}
+			resolver.bidderConfigs[bidder] = &fpd
+		}
+	}
+	return resolver, warnings
+}
+
+// readObject keeps the attributes of an object of a bidder config the host allows, and checks that they can be merged
+// into the object of the request. The user is always merged, since bidder configs may set it on requests without one.
+func (r *Resolver) readObject(index int, name string, patch json.RawMessage, allowedAttributes []string, inRequest bool, requestObject interface{}, result interface{}) (json.RawMessage, []error) {
+	if len(patch) == 0 {
+		return nil, nil
+	}
+	if !inRequest {
+		return nil, []error{fpdWarning("request.ext.prebid.bidderconfig[%d].config.ortb2.%s is set but the request has no %s, it is ignored", index, name, name)}
+	}
+
+	var attributes map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &attributes); err != nil || attributes == nil {
+		return nil, []error{fpdWarning("request.ext.prebid.bidderconfig[%d].config.ortb2.%s must be an object, it is ignored", index, name)}
+	}
+
+	allowed := make(map[string]bool, len(allowedAttributes))
+	for _, attribute := range allowedAttributes {
+		allowed[attribute] = true
+	}
+	var disallowed []string
+	for attribute := range attributes {
+		if !allowed[attribute] {
+			disallowed = append(disallowed, attribute)
+			delete(attributes, attribute)
+		}
+	}
+	sort.Strings(disallowed)
+
+	var warnings []error
+	for _, attribute := range disallowed {
+		warnings = append(warnings, fpdWarning("request.ext.prebid.bidderconfig[%d].config.ortb2.%s.%s isn't allowed by the host, it is ignored", index, name, attribute))
+	}
+	if len(attributes) == 0 {
+		return nil, warnings
+	}
+
+	allowedPatch, err := json.Marshal(attributes)
+	if err == nil {
+		err = r.merge(requestObject, allowedPatch, result)
+	}
+	if err != nil {
+		return nil, append(warnings, fpdWarning("request.ext.prebid.bidderconfig[%d].config.ortb2.%s can't be merged into request.%s, it is ignored: %v", index, name, name, err))
+	}
+	return allowedPatch, warnings
+}
+
+// Apply sets the site, app and user of the request of a bidder from the first party data it is allowed. The user of its
+// bidder config is left out unless enrichUser is set, as the account may deny the bidder the enrichUfpd activity. The
+// request is expected to be a shallow copy of the auction request, so its objects are copied before being changed.
+func (r *Resolver) Apply(request *openrtb2.BidRequest, bidder string, enrichUser bool) error {
+	if r == nil {
+		return nil
+	}
+
+	if r.globalBidders != nil && !r.globalBidders[bidder] {
+		if err := removeGlobalFPD(request); err != nil {
+			return err
+		}
+	}
+
+	fpd, ok := r.bidderConfigs[bidder]
+	if !ok {
+		if fpd, ok = r.bidderConfigs[allBidders]; !ok {
+			return nil
+		}
+	}
+
+	if fpd.site != nil && request.Site != nil {
+		site := &openrtb2.Site{}
+		if err := r.merge(request.Site, fpd.site, site); err != nil {
+			return err
+		}
+		request.Site = site
+	}
+	if fpd.app != nil && request.App != nil {
+		app := &openrtb2.App{}
+		if err := r.merge(request.App, fpd.app, app); err != nil {
+			return err
+		}
+		request.App = app
+	}
+	if fpd.user != nil && enrichUser {
+		user := &openrtb2.User{}
+		if err := r.merge(request.User, fpd.user, user); err != nil {
+			return err
+		}
+		request.User = user
+	}
+	return nil
+}
+
+// merge merges the object of a bidder config into the object of the request. The attributes both of them set are
+// taken from the bidder config, unless the host lets the request win the conflicts.
+func (r *Resolver) merge(requestObject interface{}, patch json.RawMessage, result interface{}) error {
+	requestJSON, err := json.Marshal(requestObject)
+	if err != nil {
+		return err
+	}
+	if string(requestJSON) == "null" {
+		requestJSON = []byte(`{}`)
+	}
+
+	var merged []byte
+	if r.conflict == config.FirstPartyDataConflictRequest {
+		merged, err = jsonpatch.MergePatch(patch, requestJSON)
+	} else {
+		merged, err = jsonpatch.MergePatch(requestJSON, patch)
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(merged, result)
+}
+
+// removeGlobalFPD removes the global first party data of the request of a bidder which isn't allowed it
+func removeGlobalFPD(request *openrtb2.BidRequest) error {
+	if request.Site != nil {
+		siteCopy := *request.Site
+		ext, err := removeExtData(siteCopy.Ext)
+		if err != nil {
+			return fmt.Errorf("site.ext is invalid: %v", err)
+		}
+		siteCopy.Ext = ext
+		siteCopy.Content = removeContentData(siteCopy.Content)
+		request.Site = &siteCopy
+	}
+	if request.App != nil {
+		appCopy := *request.App
+		ext, err := removeExtData(appCopy.Ext)
+		if err != nil {
+			return fmt.Errorf("app.ext is invalid: %v", err)
+		}
+		appCopy.Ext = ext
+		appCopy.Content = removeContentData(appCopy.Content)
+		request.App = &appCopy
+	}
+	if request.User != nil {
+		userCopy := *request.User
+		ext, err := removeExtData(userCopy.Ext)
+		if err != nil {
+			return fmt.Errorf("user.ext is invalid: %v", err)
+		}
+		userCopy.Ext = ext
+		userCopy.Data = nil
+		request.User = &userCopy
+	}
+	return nil
+}
+
+func removeContentData(content *openrtb2.Content) *openrtb2.Content {
+	if content == nil || content.Data == nil {
+		return content
+	}
+	contentCopy := *content
+	contentCopy.Data = nil
+	return &contentCopy
+}
+
+func removeExtData(ext json.RawMessage) (json.RawMessage, error) {
+	if len(ext) == 0 {
+		return ext, nil
+	}
+
+	var extMap map[string]json.RawMessage
+	if err := json.Unmarshal(ext, &extMap); err != nil {
+		return nil, err
+	}
+	if _, ok := extMap[openrtb_ext.FirstPartyDataExtKey]; !ok {
+		return ext, nil
+	}
+
+	delete(extMap, openrtb_ext.FirstPartyDataExtKey)
+	if len(extMap) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(extMap)
+}
+
+func fpdWarning(format string, args ...interface{}) error {
+	return &errortypes.Warning{
+		Message:     fmt.Sprintf(format, args...),
+		WarningCode: errortypes.FirstPartyDataWarningCode,
+	}
+}
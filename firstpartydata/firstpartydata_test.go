@@ -0,0 +1,276 @@
+package firstpartydata
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+var testConfig = config.FirstPartyData{
+	SiteAttributes: []string{"page", "keywords", "ext"},
+	AppAttributes:  []string{"bundle", "ext"},
+	UserAttributes: []string{"yob", "keywords", "ext"},
+	Conflict:       config.FirstPartyDataConflictBidder,
+}
+
+func TestNewResolverWithoutFPD(t *testing.T) {
+	request := &openrtb2.BidRequest{Site: &openrtb2.Site{Page: "page"}}
+
+	testCases := []struct {
+		description string
+		requestExt  *openrtb_ext.ExtRequest
+	}{
+		{
+			description: "No request ext",
+		},
+		{
+			description: "Only eid permissions",
+			requestExt: &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+				Data: &openrtb_ext.ExtRequestPrebidData{
+					EidPermissions: []openrtb_ext.ExtRequestPrebidDataEidPermission{{Source: "source1", Bidders: []string{"appnexus"}}},
+				},
+			}},
+		},
+	}
+
+	for _, test := range testCases {
+		resolver, warnings := NewResolver(request, test.requestExt, testConfig)
+
+		assert.Nil(t, resolver, test.description)
+		assert.Empty(t, warnings, test.description)
+		assert.NoError(t, resolver.Apply(request, "appnexus", true), test.description+":apply")
+	}
+}
+
+func TestNewResolverWarnings(t *testing.T) {
+	request := &openrtb2.BidRequest{Site: &openrtb2.Site{Page: "page"}}
+	requestExt := &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+		BidderConfigs: []openrtb_ext.BidderConfig{
+			{
+				Bidders: []string{"appnexus"},
+				Config: &openrtb_ext.Config{ORTB2: &openrtb_ext.ORTB2{
+					Site: json.RawMessage(`{"page":"bidder page","name":"bidder name","id":"bidder id"}`),
+					App:  json.RawMessage(`{"bundle":"bundle"}`),
+				}},
+			},
+			{
+				Bidders: []string{"rubicon", "appnexus"},
+				Config: &openrtb_ext.Config{ORTB2: &openrtb_ext.ORTB2{
+					Site: json.RawMessage(`{"keywords":5}`),
+					User: json.RawMessage(`[]`),
+				}},
+			},
+		},
+	}}
+
+	resolver, warnings := NewResolver(request, requestExt, testConfig)
+
+	if assert.NotNil(t, resolver) {
+		assert.Equal(t, json.RawMessage(`{"page":"bidder page"}`), resolver.bidderConfigs["appnexus"].site)
+		assert.Nil(t, resolver.bidderConfigs["appnexus"].app)
+		assert.Nil(t, resolver.bidderConfigs["rubicon"].site)
+		assert.Nil(t, resolver.bidderConfigs["rubicon"].user)
+	}
+	expectedWarnings := []string{
+		"request.ext.prebid.bidderconfig[0].config.ortb2.site.id isn't allowed by the host, it is ignored",
+		"request.ext.prebid.bidderconfig[0].config.ortb2.site.name isn't allowed by the host, it is ignored",
+		"request.ext.prebid.bidderconfig[0].config.ortb2.app is set but the request has no app, it is ignored",
+		"request.ext.prebid.bidderconfig[1].config.ortb2.site can't be merged into request.site, it is ignored: ",
+		"request.ext.prebid.bidderconfig[1].config.ortb2.user must be an object, it is ignored",
+		"bidderconfig is set more than once for appnexus, only the first one is used",
+	}
+	if assert.Len(t, warnings, len(expectedWarnings)) {
+		for i, warning := range warnings {
+			// The merge error is left out, its wording depends on the json package
+			assert.True(t, strings.HasPrefix(warning.Error(), expectedWarnings[i]), "expected %q, got %q", expectedWarnings[i], warning.Error())
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	newRequest := func() *openrtb2.BidRequest {
+		return &openrtb2.BidRequest{
+			Site: &openrtb2.Site{
+				Page:     "page",
+				Keywords: "request keywords",
+				Content:  &openrtb2.Content{ID: "content", Data: []openrtb2.Data{{ID: "site data"}}},
+				Ext:      json.RawMessage(`{"data":{"section":"news"},"amp":1}`),
+			},
+			User: &openrtb2.User{
+				ID:   "user",
+				Data: []openrtb2.Data{{ID: "user data"}},
+				Ext:  json.RawMessage(`{"data":{"interests":["cars"]}}`),
+			},
+		}
+	}
+	requestExt := &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+		Data: &openrtb_ext.ExtRequestPrebidData{Bidders: []string{"appnexus", "rubicon"}},
+		BidderConfigs: []openrtb_ext.BidderConfig{
+			{
+				Bidders: []string{"appnexus", "openx"},
+				Config: &openrtb_ext.Config{ORTB2: &openrtb_ext.ORTB2{
+					Site: json.RawMessage(`{"keywords":"bidder keywords","ext":{"data":{"rating":"pg"}}}`),
+					User: json.RawMessage(`{"yob":1980}`),
+				}},
+			},
+			{
+				Bidders: []string{"*"},
+				Config: &openrtb_ext.Config{ORTB2: &openrtb_ext.ORTB2{
+					User: json.RawMessage(`{"keywords":"all bidders"}`),
+				}},
+			},
+		},
+	}}
+
+	testCases := []struct {
+		description           string
+		givenConfig           config.FirstPartyData
+		givenBidder           string
+		givenNoUserEnrichment bool
+		expectedSite          *openrtb2.Site
+		expectedUser          *openrtb2.User
+	}{
+		{
+			description: "Global and bidder first party data",
+			givenConfig: testConfig,
+			givenBidder: "appnexus",
+			expectedSite: &openrtb2.Site{
+				Page:     "page",
+				Keywords: "bidder keywords",
+				Content:  &openrtb2.Content{ID: "content", Data: []openrtb2.Data{{ID: "site data"}}},
+				Ext:      json.RawMessage(`{"amp":1,"data":{"rating":"pg","section":"news"}}`),
+			},
+			expectedUser: &openrtb2.User{
+				ID:   "user",
+				Yob:  1980,
+				Data: []openrtb2.Data{{ID: "user data"}},
+				Ext:  json.RawMessage(`{"data":{"interests":["cars"]}}`),
+			},
+		},
+		{
+			description: "Request winning the conflicts with the bidder first party data",
+			givenConfig: config.FirstPartyData{
+				SiteAttributes: testConfig.SiteAttributes,
+				UserAttributes: testConfig.UserAttributes,
+				Conflict:       config.FirstPartyDataConflictRequest,
+			},
+			givenBidder: "appnexus",
+			expectedSite: &openrtb2.Site{
+				Page:     "page",
+				Keywords: "request keywords",
+				Content:  &openrtb2.Content{ID: "content", Data: []openrtb2.Data{{ID: "site data"}}},
+				Ext:      json.RawMessage(`{"amp":1,"data":{"rating":"pg","section":"news"}}`),
+			},
+			expectedUser: &openrtb2.User{
+				ID:   "user",
+				Yob:  1980,
+				Data: []openrtb2.Data{{ID: "user data"}},
+				Ext:  json.RawMessage(`{"data":{"interests":["cars"]}}`),
+			},
+		},
+		{
+			description:           "Bidder denied the enrichment of the user",
+			givenConfig:           testConfig,
+			givenBidder:           "appnexus",
+			givenNoUserEnrichment: true,
+			expectedSite: &openrtb2.Site{
+				Page:     "page",
+				Keywords: "bidder keywords",
+				Content:  &openrtb2.Content{ID: "content", Data: []openrtb2.Data{{ID: "site data"}}},
+				Ext:      json.RawMessage(`{"amp":1,"data":{"rating":"pg","section":"news"}}`),
+			},
+			expectedUser: &openrtb2.User{
+				ID:   "user",
+				Data: []openrtb2.Data{{ID: "user data"}},
+				Ext:  json.RawMessage(`{"data":{"interests":["cars"]}}`),
+			},
+		},
+		{
+			description: "Bidder first party data without the global one",
+			givenConfig: testConfig,
+			givenBidder: "openx",
+			expectedSite: &openrtb2.Site{
+				Page:     "page",
+				Keywords: "bidder keywords",
+				Content:  &openrtb2.Content{ID: "content"},
+				Ext:      json.RawMessage(`{"amp":1,"data":{"rating":"pg"}}`),
+			},
+			expectedUser: &openrtb2.User{
+				ID:  "user",
+				Yob: 1980,
+			},
+		},
+		{
+			description: "Global and wildcard bidder first party data",
+			givenConfig: testConfig,
+			givenBidder: "rubicon",
+			expectedSite: &openrtb2.Site{
+				Page:     "page",
+				Keywords: "request keywords",
+				Content:  &openrtb2.Content{ID: "content", Data: []openrtb2.Data{{ID: "site data"}}},
+				Ext:      json.RawMessage(`{"data":{"section":"news"},"amp":1}`),
+			},
+			expectedUser: &openrtb2.User{
+				ID:       "user",
+				Keywords: "all bidders",
+				Data:     []openrtb2.Data{{ID: "user data"}},
+				Ext:      json.RawMessage(`{"data":{"interests":["cars"]}}`),
+			},
+		},
+		{
+			description: "Wildcard bidder first party data without the global one",
+			givenConfig: testConfig,
+			givenBidder: "pubmatic",
+			expectedSite: &openrtb2.Site{
+				Page:     "page",
+				Keywords: "request keywords",
+				Content:  &openrtb2.Content{ID: "content"},
+				Ext:      json.RawMessage(`{"amp":1}`),
+			},
+			expectedUser: &openrtb2.User{
+				ID:       "user",
+				Keywords: "all bidders",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		resolver, warnings := NewResolver(newRequest(), requestExt, test.givenConfig)
+		assert.Empty(t, warnings, test.description+":warnings")
+
+		request := newRequest()
+		err := resolver.Apply(request, test.givenBidder, !test.givenNoUserEnrichment)
+
+		assert.NoError(t, err, test.description+":err")
+		assert.Equal(t, test.expectedSite, request.Site, test.description+":site")
+		assert.Equal(t, test.expectedUser, request.User, test.description+":user")
+	}
+}
+
+func TestApplyDoesNotChangeSharedObjects(t *testing.T) {
+	request := &openrtb2.BidRequest{
+		Site: &openrtb2.Site{Page: "page", Ext: json.RawMessage(`{"data":{"section":"news"}}`)},
+	}
+	requestExt := &openrtb_ext.ExtRequest{Prebid: openrtb_ext.ExtRequestPrebid{
+		Data: &openrtb_ext.ExtRequestPrebidData{Bidders: []string{"appnexus"}},
+		BidderConfigs: []openrtb_ext.BidderConfig{{
+			Bidders: []string{"rubicon"},
+			Config:  &openrtb_ext.Config{ORTB2: &openrtb_ext.ORTB2{User: json.RawMessage(`{"yob":1980}`)}},
+		}},
+	}}
+	resolver, _ := NewResolver(request, requestExt, testConfig)
+
+	bidderRequest := *request
+	err := resolver.Apply(&bidderRequest, "rubicon", true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &openrtb2.Site{Page: "page", Ext: json.RawMessage(`{"data":{"section":"news"}}`)}, request.Site, "the site of the request is left as is")
+	assert.Nil(t, request.User)
+	assert.Equal(t, &openrtb2.Site{Page: "page"}, bidderRequest.Site)
+	assert.Equal(t, &openrtb2.User{Yob: 1980}, bidderRequest.User, "the user is created for the bidder configs setting one")
+}
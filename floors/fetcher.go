@@ -0,0 +1,141 @@
+package floors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/util/timeutil"
+)
+
+// Fetcher loads the floors files of the accounts in the background, and caches them between two fetches.
+type Fetcher struct {
+	httpClient httpClient
+	time       timeutil.Time
+
+	mutex   sync.Mutex
+	entries map[string]*fetchEntry
+}
+
+// fetchEntry holds the floors of a single floors file URL
+type fetchEntry struct {
+	rules       *Rules
+	lastUpdated time.Time
+	lastAttempt time.Time
+	fetching    bool
+}
+
+// NewFetcher returns a new Fetcher
+func NewFetcher(httpClient httpClient) *Fetcher {
+	return &Fetcher{
+		httpClient: httpClient,
+		time:       &timeutil.RealTime{},
+		entries:    make(map[string]*fetchEntry),
+	}
+}
+
+// Fetch returns the floors of the account, or nil if they haven't been fetched yet or have gone stale. It never
+// blocks on the network: a refresh is started in the background whenever the floors are older than the fetch period,
+// so the first requests of an account are processed without its fetched floors.
+func (f *Fetcher) Fetch(cfg config.AccountFloorsFetch) *Rules {
+	if f == nil || !cfg.Enabled || cfg.URL == "" {
+		return nil
+	}
+
+	now := f.time.Now()
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	entry, ok := f.entries[cfg.URL]
+	if !ok {
+		entry = &fetchEntry{}
+		f.entries[cfg.URL] = entry
+	}
+	if !entry.fetching && (entry.lastAttempt.IsZero() || now.Sub(entry.lastAttempt) >= time.Duration(cfg.Period)*time.Second) {
+		entry.fetching = true
+		entry.lastAttempt = now
+		go f.refresh(entry, cfg)
+	}
+
+	if entry.rules == nil || (cfg.MaxAge > 0 && now.Sub(entry.lastUpdated) > time.Duration(cfg.MaxAge)*time.Second) {
+		return nil
+	}
+	return entry.rules
+}
+
+// refresh replaces the floors of the entry with the ones currently hosted at the URL. The previous floors are kept if
+// the new ones can't be fetched or are invalid.
+func (f *Fetcher) refresh(entry *fetchEntry, cfg config.AccountFloorsFetch) {
+	rules, err := f.fetch(cfg)
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	entry.fetching = false
+	if err != nil {
+		glog.Warningf("Error fetching the floors from %s: %v", cfg.URL, err)
+		return
+	}
+	entry.rules = rules
+	entry.lastUpdated = f.time.Now()
+}
+
+func (f *Fetcher) fetch(cfg config.AccountFloorsFetch) (*Rules, error) {
+	ctx := context.Background()
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.Timeout)*time.Millisecond)
+		defer cancel()
+	}
+
+	request, err := http.NewRequest("GET", cfg.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := f.httpClient.Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		message := fmt.Sprintf("The floors request failed with status code %d", response.StatusCode)
+		return nil, &errortypes.BadServerResponse{Message: message}
+	}
+
+	var body io.Reader = response.Body
+	maxSize := int64(cfg.MaxFileSizeKB) * 1024
+	if maxSize > 0 {
+		body = io.LimitReader(response.Body, maxSize+1)
+	}
+	bytesJSON, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize > 0 && int64(len(bytesJSON)) > maxSize {
+		return nil, fmt.Errorf("the floors file is larger than %d KB", cfg.MaxFileSizeKB)
+	}
+
+	rules := &Rules{}
+	if err := json.Unmarshal(bytesJSON, rules); err != nil {
+		return nil, err
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid floors file: %v", err)
+	}
+	return rules, nil
+}
+
+type httpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
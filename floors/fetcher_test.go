@@ -0,0 +1,178 @@
+package floors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTime struct {
+	mutex sync.Mutex
+	time  time.Time
+}
+
+func (ft *fakeTime) Now() time.Time {
+	ft.mutex.Lock()
+	defer ft.mutex.Unlock()
+	return ft.time
+}
+
+func (ft *fakeTime) add(d time.Duration) {
+	ft.mutex.Lock()
+	defer ft.mutex.Unlock()
+	ft.time = ft.time.Add(d)
+}
+
+// floorsServer serves the floors file, and counts how many times it was requested
+type floorsServer struct {
+	mutex    sync.Mutex
+	body     string
+	status   int
+	requests int
+}
+
+func (fs *floorsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	fs.requests++
+	w.WriteHeader(fs.status)
+	w.Write([]byte(fs.body))
+}
+
+func (fs *floorsServer) set(status int, body string) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	fs.status = status
+	fs.body = body
+}
+
+func (fs *floorsServer) requestCount() int {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	return fs.requests
+}
+
+const validFloors = `{"currency":"EUR","schema":{"fields":["mediaType"]},"values":{"banner":1.5}}`
+
+func newTestFetcher() (*Fetcher, *fakeTime) {
+	ft := &fakeTime{time: time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)}
+	fetcher := NewFetcher(&http.Client{})
+	fetcher.time = ft
+	return fetcher, ft
+}
+
+// waitForFetch waits for the background refreshes of the fetcher to finish
+func waitForFetch(t *testing.T, fetcher *Fetcher) {
+	assert.Eventually(t, func() bool {
+		fetcher.mutex.Lock()
+		defer fetcher.mutex.Unlock()
+		for _, entry := range fetcher.entries {
+			if entry.fetching {
+				return false
+			}
+		}
+		return true
+	}, time.Second, time.Millisecond)
+}
+
+func TestFetchDisabled(t *testing.T) {
+	server := &floorsServer{status: http.StatusOK, body: validFloors}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	fetcher, _ := newTestFetcher()
+
+	assert.Nil(t, fetcher.Fetch(config.AccountFloorsFetch{Enabled: false, URL: httpServer.URL}), "disabled")
+	assert.Nil(t, fetcher.Fetch(config.AccountFloorsFetch{Enabled: true}), "no url")
+	assert.Nil(t, (*Fetcher)(nil).Fetch(config.AccountFloorsFetch{Enabled: true, URL: httpServer.URL}), "no fetcher")
+	assert.Equal(t, 0, server.requestCount())
+}
+
+func TestFetchRefresh(t *testing.T) {
+	server := &floorsServer{status: http.StatusOK, body: validFloors}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	fetcher, ft := newTestFetcher()
+	cfg := config.AccountFloorsFetch{Enabled: true, URL: httpServer.URL, Timeout: 1000, Period: 60, MaxAge: 300}
+
+	// The first request doesn't wait for the floors
+	assert.Nil(t, fetcher.Fetch(cfg), "first request")
+	waitForFetch(t, fetcher)
+
+	rules := fetcher.Fetch(cfg)
+	if assert.NotNil(t, rules, "fetched") {
+		assert.Equal(t, "EUR", rules.Currency)
+		assert.Equal(t, map[string]float64{"banner": 1.5}, rules.Values)
+	}
+	assert.Equal(t, 1, server.requestCount(), "cached within the period")
+
+	// Failed refreshes keep the previous floors until they go stale
+	server.set(http.StatusInternalServerError, "")
+	ft.add(61 * time.Second)
+	assert.NotNil(t, fetcher.Fetch(cfg), "refresh failing")
+	waitForFetch(t, fetcher)
+	assert.Equal(t, 2, server.requestCount(), "refreshed after the period")
+	assert.NotNil(t, fetcher.Fetch(cfg), "previous floors kept")
+
+	ft.add(240 * time.Second)
+	assert.Nil(t, fetcher.Fetch(cfg), "stale")
+	waitForFetch(t, fetcher)
+	assert.Equal(t, 3, server.requestCount())
+
+	// A successful refresh brings them back
+	server.set(http.StatusOK, validFloors)
+	ft.add(61 * time.Second)
+	fetcher.Fetch(cfg)
+	waitForFetch(t, fetcher)
+	assert.NotNil(t, fetcher.Fetch(cfg), "refreshed")
+}
+
+func TestFetchInvalidFile(t *testing.T) {
+	testCases := []struct {
+		description string
+		status      int
+		body        string
+		expectedErr string
+	}{
+		{
+			description: "Bad status",
+			status:      http.StatusNotFound,
+			expectedErr: "The floors request failed with status code 404",
+		},
+		{
+			description: "Malformed JSON",
+			status:      http.StatusOK,
+			body:        `{"schema":`,
+			expectedErr: "unexpected end of JSON input",
+		},
+		{
+			description: "Schema violation",
+			status:      http.StatusOK,
+			body:        `{"schema":{"fields":["mediaType"]},"values":{"banner|300x250":1}}`,
+			expectedErr: "invalid floors file: rule banner|300x250 doesn't match the 1 schema fields",
+		},
+		{
+			description: "Too large",
+			status:      http.StatusOK,
+			body:        `{"schema":{"fields":["mediaType"]},"values":{"banner":1` + strings.Repeat(" ", 1024) + `}}`,
+			expectedErr: "the floors file is larger than 1 KB",
+		},
+	}
+
+	for _, test := range testCases {
+		server := &floorsServer{status: test.status, body: test.body}
+		httpServer := httptest.NewServer(server)
+		fetcher, _ := newTestFetcher()
+
+		rules, err := fetcher.fetch(config.AccountFloorsFetch{Enabled: true, URL: httpServer.URL, MaxFileSizeKB: 1})
+
+		assert.Nil(t, rules, test.description)
+		assert.EqualError(t, err, test.expectedErr, test.description)
+		httpServer.Close()
+	}
+}
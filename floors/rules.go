@@ -0,0 +1,190 @@
+package floors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"golang.org/x/text/currency"
+)
+
+const (
+	defaultDelimiter = "|"
+	wildcard         = "*"
+)
+
+// Schema fields supported by the rules
+const (
+	FieldMediaType  = "mediaType"
+	FieldSize       = "size"
+	FieldDomain     = "domain"
+	FieldAdUnitCode = "adUnitCode"
+)
+
+var supportedFields = map[string]bool{
+	FieldMediaType:  true,
+	FieldSize:       true,
+	FieldDomain:     true,
+	FieldAdUnitCode: true,
+}
+
+// Rules holds the floors of an account in the Prebid floors file format, for example:
+//
+//	{"currency":"USD","schema":{"fields":["mediaType","size"]},"values":{"banner|300x250":1.5,"*|*":0.5},"default":0.1}
+type Rules struct {
+	Currency string             `json:"currency,omitempty"`
+	Schema   Schema             `json:"schema"`
+	Values   map[string]float64 `json:"values"`
+	Default  float64            `json:"default,omitempty"`
+}
+
+// Schema lists the fields the keys of the rule values are made of.
+type Schema struct {
+	Fields    []string `json:"fields"`
+	Delimiter string   `json:"delimiter,omitempty"`
+}
+
+// Validate checks the rules against the floors schema, and normalizes them so that they can be looked up.
+func (r *Rules) Validate() error {
+	if r.Currency == "" {
+		r.Currency = "USD"
+	} else if _, err := currency.ParseISO(r.Currency); err != nil {
+		return fmt.Errorf("currency %s is not a valid ISO 4217 code", r.Currency)
+	}
+	if r.Schema.Delimiter == "" {
+		r.Schema.Delimiter = defaultDelimiter
+	}
+	if len(r.Schema.Fields) == 0 {
+		return fmt.Errorf("schema.fields must not be empty")
+	}
+	for _, field := range r.Schema.Fields {
+		if !supportedFields[field] {
+			return fmt.Errorf("schema field %s is not supported", field)
+		}
+	}
+	if r.Default < 0 {
+		return fmt.Errorf("default must be a positive number")
+	}
+
+	values := make(map[string]float64, len(r.Values))
+	for key, value := range r.Values {
+		if len(strings.Split(key, r.Schema.Delimiter)) != len(r.Schema.Fields) {
+			return fmt.Errorf("rule %s doesn't match the %d schema fields", key, len(r.Schema.Fields))
+		}
+		if value < 0 {
+			return fmt.Errorf("rule %s must have a positive floor", key)
+		}
+		values[strings.ToLower(key)] = value
+	}
+	r.Values = values
+	return nil
+}
+
+// Floor returns the floor of the imp, in the currency of the rules. The most specific rule wins: exact values are
+// preferred over wildcards, and wildcards are preferred on the last fields of the schema. The default floor applies if
+// no rule matches.
+func (r *Rules) Floor(request *openrtb2.BidRequest, imp *openrtb2.Imp) (float64, bool) {
+	impValues := make([]string, len(r.Schema.Fields))
+	for i, field := range r.Schema.Fields {
+		impValues[i] = strings.ToLower(fieldValue(field, request, imp))
+	}
+
+	fieldCount := len(impValues)
+	key := make([]string, fieldCount)
+	for wildcards := 0; wildcards <= fieldCount; wildcards++ {
+		for _, mask := range wildcardMasks(fieldCount, wildcards) {
+			for i := range key {
+				if mask&(1<<uint(fieldCount-1-i)) != 0 {
+					key[i] = wildcard
+				} else {
+					key[i] = impValues[i]
+				}
+			}
+			if floor, ok := r.Values[strings.Join(key, r.Schema.Delimiter)]; ok {
+				return floor, true
+			}
+		}
+	}
+
+	if r.Default > 0 {
+		return r.Default, true
+	}
+	return 0, false
+}
+
+// wildcardMasks lists the masks with the given amount of wildcards, ordered so that the wildcards on the last fields
+// come first. The bit of the first field is the most significant one.
+func wildcardMasks(fieldCount, wildcards int) []int {
+	var masks []int
+	for mask := 0; mask < 1<<uint(fieldCount); mask++ {
+		if bitCount(mask) == wildcards {
+			masks = append(masks, mask)
+		}
+	}
+	return masks
+}
+
+func bitCount(n int) int {
+	count := 0
+	for ; n > 0; n &= n - 1 {
+		count++
+	}
+	return count
+}
+
+// fieldValue returns the value of a schema field for the imp, or the wildcard if it can't be told.
+func fieldValue(field string, request *openrtb2.BidRequest, imp *openrtb2.Imp) string {
+	switch field {
+	case FieldMediaType:
+		return mediaType(imp)
+	case FieldSize:
+		return size(imp)
+	case FieldDomain:
+		if request.Site != nil && request.Site.Domain != "" {
+			return request.Site.Domain
+		}
+		if request.App != nil && request.App.Domain != "" {
+			return request.App.Domain
+		}
+	case FieldAdUnitCode:
+		if imp.TagID != "" {
+			return imp.TagID
+		}
+	}
+	return wildcard
+}
+
+func mediaType(imp *openrtb2.Imp) string {
+	mediaTypes := make([]string, 0, 1)
+	if imp.Banner != nil {
+		mediaTypes = append(mediaTypes, "banner")
+	}
+	if imp.Video != nil {
+		mediaTypes = append(mediaTypes, "video")
+	}
+	if imp.Audio != nil {
+		mediaTypes = append(mediaTypes, "audio")
+	}
+	if imp.Native != nil {
+		mediaTypes = append(mediaTypes, "native")
+	}
+	if len(mediaTypes) != 1 {
+		return wildcard
+	}
+	return mediaTypes[0]
+}
+
+func size(imp *openrtb2.Imp) string {
+	if imp.Banner != nil && imp.Video == nil {
+		if len(imp.Banner.Format) == 1 {
+			return fmt.Sprintf("%dx%d", imp.Banner.Format[0].W, imp.Banner.Format[0].H)
+		}
+		if len(imp.Banner.Format) == 0 && imp.Banner.W != nil && imp.Banner.H != nil {
+			return fmt.Sprintf("%dx%d", *imp.Banner.W, *imp.Banner.H)
+		}
+	}
+	if imp.Video != nil && imp.Banner == nil && imp.Video.W > 0 && imp.Video.H > 0 {
+		return fmt.Sprintf("%dx%d", imp.Video.W, imp.Video.H)
+	}
+	return wildcard
+}
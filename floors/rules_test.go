@@ -0,0 +1,142 @@
+package floors
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRulesValidate(t *testing.T) {
+	testCases := []struct {
+		description   string
+		rules         Rules
+		expectedRules Rules
+		expectedError string
+	}{
+		{
+			description: "Defaults and lowercase keys",
+			rules: Rules{
+				Schema: Schema{Fields: []string{"mediaType", "adUnitCode"}},
+				Values: map[string]float64{"banner|Top-Slot": 1.5},
+			},
+			expectedRules: Rules{
+				Currency: "USD",
+				Schema:   Schema{Fields: []string{"mediaType", "adUnitCode"}, Delimiter: "|"},
+				Values:   map[string]float64{"banner|top-slot": 1.5},
+			},
+		},
+		{
+			description:   "Invalid currency",
+			rules:         Rules{Currency: "XYZ", Schema: Schema{Fields: []string{"size"}}},
+			expectedError: "currency XYZ is not a valid ISO 4217 code",
+		},
+		{
+			description:   "No fields",
+			rules:         Rules{},
+			expectedError: "schema.fields must not be empty",
+		},
+		{
+			description:   "Unsupported field",
+			rules:         Rules{Schema: Schema{Fields: []string{"gptSlot"}}},
+			expectedError: "schema field gptSlot is not supported",
+		},
+		{
+			description:   "Negative default",
+			rules:         Rules{Schema: Schema{Fields: []string{"size"}}, Default: -1},
+			expectedError: "default must be a positive number",
+		},
+		{
+			description:   "Key not matching the fields",
+			rules:         Rules{Schema: Schema{Fields: []string{"mediaType", "size"}}, Values: map[string]float64{"banner": 1}},
+			expectedError: "rule banner doesn't match the 2 schema fields",
+		},
+		{
+			description:   "Negative floor",
+			rules:         Rules{Schema: Schema{Fields: []string{"size"}, Delimiter: ","}, Values: map[string]float64{"300x250": -1}},
+			expectedError: "rule 300x250 must have a positive floor",
+		},
+	}
+
+	for _, test := range testCases {
+		err := test.rules.Validate()
+
+		if test.expectedError != "" {
+			assert.EqualError(t, err, test.expectedError, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+			assert.Equal(t, test.expectedRules, test.rules, test.description)
+		}
+	}
+}
+
+func TestRulesFloor(t *testing.T) {
+	rules := Rules{
+		Schema: Schema{Fields: []string{"mediaType", "size", "domain"}},
+		Values: map[string]float64{
+			"banner|300x250|example.com": 3,
+			"banner|300x250|*":           2,
+			"banner|*|example.com":       1.5,
+			"*|300x250|*":                1.2,
+			"video|*|*":                  1,
+		},
+		Default: 0.1,
+	}
+	assert.NoError(t, rules.Validate())
+
+	banner := func(formats ...openrtb2.Format) *openrtb2.Banner { return &openrtb2.Banner{Format: formats} }
+	testCases := []struct {
+		description   string
+		request       *openrtb2.BidRequest
+		imp           openrtb2.Imp
+		expectedFloor float64
+	}{
+		{
+			description:   "Exact match",
+			request:       &openrtb2.BidRequest{Site: &openrtb2.Site{Domain: "Example.com"}},
+			imp:           openrtb2.Imp{Banner: banner(openrtb2.Format{W: 300, H: 250})},
+			expectedFloor: 3,
+		},
+		{
+			description:   "Wildcard on the last field wins",
+			request:       &openrtb2.BidRequest{Site: &openrtb2.Site{Domain: "other.com"}},
+			imp:           openrtb2.Imp{Banner: banner(openrtb2.Format{W: 300, H: 250})},
+			expectedFloor: 2,
+		},
+		{
+			description:   "Multiple sizes",
+			request:       &openrtb2.BidRequest{App: &openrtb2.App{Domain: "example.com"}},
+			imp:           openrtb2.Imp{Banner: banner(openrtb2.Format{W: 300, H: 250}, openrtb2.Format{W: 728, H: 90})},
+			expectedFloor: 1.5,
+		},
+		{
+			description:   "Multiple media types",
+			request:       &openrtb2.BidRequest{},
+			imp:           openrtb2.Imp{Banner: banner(openrtb2.Format{W: 300, H: 250}), Native: &openrtb2.Native{}},
+			expectedFloor: 1.2,
+		},
+		{
+			description:   "Video",
+			request:       &openrtb2.BidRequest{},
+			imp:           openrtb2.Imp{Video: &openrtb2.Video{W: 640, H: 480}},
+			expectedFloor: 1,
+		},
+		{
+			description:   "Default",
+			request:       &openrtb2.BidRequest{},
+			imp:           openrtb2.Imp{Native: &openrtb2.Native{}},
+			expectedFloor: 0.1,
+		},
+	}
+
+	for _, test := range testCases {
+		floor, ok := rules.Floor(test.request, &test.imp)
+
+		assert.True(t, ok, test.description)
+		assert.Equal(t, test.expectedFloor, floor, test.description)
+	}
+
+	rules.Default = 0
+	_, ok := rules.Floor(&openrtb2.BidRequest{}, &openrtb2.Imp{Native: &openrtb2.Native{}})
+	assert.False(t, ok, "No match without default")
+}
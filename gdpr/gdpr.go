@@ -74,6 +74,97 @@ func NewPermissions(ctx context.Context, cfg config.GDPR, vendorIDs map[openrtb_
 	return permissionsImpl
 }
 
+// WithAliasGVLIDs returns the permissions of an auction calling aliases with GVL vendor IDs of their own. Their
+// auction activities are then checked against their own vendor when they are asked for by alias name. Permissions
+// which don't enforce GDPR are returned as is.
+func WithAliasGVLIDs(perms Permissions, aliasGVLIDs map[openrtb_ext.BidderName]uint16) Permissions {
+	if len(aliasGVLIDs) == 0 {
+		return perms
+	}
+
+	switch p := perms.(type) {
+	case *permissionsImpl:
+		aliasPerms := *p
+		aliasPerms.aliasVendorIDs = aliasGVLIDs
+		return &aliasPerms
+	case *AllowHostCookies:
+		aliasPerms := *p.permissionsImpl
+		aliasPerms.aliasVendorIDs = aliasGVLIDs
+		return &AllowHostCookies{permissionsImpl: &aliasPerms}
+	}
+	return perms
+}
+
+// WithAccountConfig returns the permissions of an auction for an account which overrides the enforcement of some
+// purposes or of the special feature 1. Permissions which don't enforce GDPR, and accounts overriding nothing, get the
+// permissions as is.
+func WithAccountConfig(perms Permissions, account config.AccountGDPR) Permissions {
+	switch p := perms.(type) {
+	case *permissionsImpl:
+		if accountPerms, ok := p.withAccountConfig(account); ok {
+			return accountPerms
+		}
+	case *AllowHostCookies:
+		if accountPerms, ok := p.permissionsImpl.withAccountConfig(account); ok {
+			return &AllowHostCookies{permissionsImpl: accountPerms}
+		}
+	}
+	return perms
+}
+
+func (p *permissionsImpl) withAccountConfig(account config.AccountGDPR) (*permissionsImpl, bool) {
+	overridden := false
+	purposeConfigs := make(map[consentconstants.Purpose]config.TCF2Purpose, len(p.purposeConfigs))
+	for purpose, purposeConfig := range p.purposeConfigs {
+		purposeConfigs[purpose] = purposeConfig
+	}
+	for i, accountPurpose := range account.PurposeConfigs() {
+		purpose := consentconstants.Purpose(i + 1)
+		purposeConfig := purposeConfigs[purpose]
+		if accountPurpose.EnforcePurpose != nil {
+			purposeConfig.EnforcePurpose = *accountPurpose.EnforcePurpose
+			overridden = true
+		}
+		if accountPurpose.EnforceVendors != nil {
+			purposeConfig.EnforceVendors = *accountPurpose.EnforceVendors
+			overridden = true
+		}
+		if accountPurpose.VendorExceptions != nil {
+			purposeConfig.VendorExceptions = accountPurpose.VendorExceptions
+			purposeConfig.VendorExceptionMap = vendorExceptionMap(accountPurpose.VendorExceptions)
+			overridden = true
+		}
+		purposeConfigs[purpose] = purposeConfig
+	}
+
+	cfg := p.cfg
+	if account.SpecialFeature1.Enforce != nil {
+		cfg.TCF2.SpecialPurpose1.Enabled = *account.SpecialFeature1.Enforce
+		overridden = true
+	}
+	if account.SpecialFeature1.VendorExceptions != nil {
+		cfg.TCF2.SpecialPurpose1.VendorExceptions = account.SpecialFeature1.VendorExceptions
+		cfg.TCF2.SpecialPurpose1.VendorExceptionMap = vendorExceptionMap(account.SpecialFeature1.VendorExceptions)
+		overridden = true
+	}
+
+	if !overridden {
+		return nil, false
+	}
+	accountPerms := *p
+	accountPerms.cfg = cfg
+	accountPerms.purposeConfigs = purposeConfigs
+	return &accountPerms, true
+}
+
+func vendorExceptionMap(vendorExceptions []openrtb_ext.BidderName) map[openrtb_ext.BidderName]struct{} {
+	exceptionMap := make(map[openrtb_ext.BidderName]struct{}, len(vendorExceptions))
+	for _, bidder := range vendorExceptions {
+		exceptionMap[bidder] = struct{}{}
+	}
+	return exceptionMap
+}
+
 // An ErrorMalformedConsent will be returned by the Permissions interface if
 // the consent string argument was the reason for the failure.
 type ErrorMalformedConsent struct {
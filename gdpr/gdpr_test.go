@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/prebid/go-gdpr/consentconstants"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/stretchr/testify/assert"
@@ -47,3 +48,129 @@ func TestNewPermissions(t *testing.T) {
 		assert.IsType(t, tt.wantType, perms, tt.description)
 	}
 }
+
+func TestWithAliasGVLIDs(t *testing.T) {
+	aliasGVLIDs := map[openrtb_ext.BidderName]uint16{"someAlias": 42}
+	impl := &permissionsImpl{}
+
+	tests := []struct {
+		description string
+		perms       Permissions
+		aliasGVLIDs map[openrtb_ext.BidderName]uint16
+		wantType    Permissions
+		wantSame    bool
+	}{
+		{
+			description: "GDPR disabled",
+			perms:       &AlwaysAllow{},
+			aliasGVLIDs: aliasGVLIDs,
+			wantType:    &AlwaysAllow{},
+			wantSame:    true,
+		},
+		{
+			description: "No alias vendor IDs",
+			perms:       impl,
+			wantType:    &permissionsImpl{},
+			wantSame:    true,
+		},
+		{
+			description: "Alias vendor IDs",
+			perms:       impl,
+			aliasGVLIDs: aliasGVLIDs,
+			wantType:    &permissionsImpl{},
+		},
+		{
+			description: "Alias vendor IDs with host cookies always allowed",
+			perms:       &AllowHostCookies{permissionsImpl: impl},
+			aliasGVLIDs: aliasGVLIDs,
+			wantType:    &AllowHostCookies{},
+		},
+	}
+
+	for _, tt := range tests {
+		perms := WithAliasGVLIDs(tt.perms, tt.aliasGVLIDs)
+
+		assert.IsType(t, tt.wantType, perms, tt.description)
+		if tt.wantSame {
+			assert.Same(t, tt.perms, perms, tt.description)
+		} else {
+			assert.NotSame(t, tt.perms, perms, tt.description)
+		}
+	}
+	assert.Nil(t, impl.aliasVendorIDs, "the original permissions are left as is")
+}
+
+func TestWithAccountConfig(t *testing.T) {
+	basicEnforcement := config.TCF2BasicEnforcement
+	falseValue := false
+	impl := &permissionsImpl{
+		cfg: config.GDPR{TCF2: config.TCF2{SpecialPurpose1: config.TCF2Purpose{Enabled: true}}},
+		purposeConfigs: map[consentconstants.Purpose]config.TCF2Purpose{
+			1: {Enabled: true, EnforcePurpose: config.TCF2FullEnforcement, EnforceVendors: true},
+			2: {Enabled: true, EnforcePurpose: config.TCF2FullEnforcement, EnforceVendors: true},
+		},
+	}
+	accountGDPR := config.AccountGDPR{
+		Purpose2:        config.AccountGDPRPurpose{EnforcePurpose: &basicEnforcement, EnforceVendors: &falseValue, VendorExceptions: []openrtb_ext.BidderName{"appnexus"}},
+		SpecialFeature1: config.AccountGDPRSpecialFeature{Enforce: &falseValue},
+	}
+
+	tests := []struct {
+		description string
+		perms       Permissions
+		account     config.AccountGDPR
+		wantType    Permissions
+		wantSame    bool
+	}{
+		{
+			description: "GDPR disabled",
+			perms:       &AlwaysAllow{},
+			account:     accountGDPR,
+			wantType:    &AlwaysAllow{},
+			wantSame:    true,
+		},
+		{
+			description: "No account overrides",
+			perms:       impl,
+			wantType:    &permissionsImpl{},
+			wantSame:    true,
+		},
+		{
+			description: "Account overrides",
+			perms:       impl,
+			account:     accountGDPR,
+			wantType:    &permissionsImpl{},
+		},
+		{
+			description: "Account overrides with host cookies always allowed",
+			perms:       &AllowHostCookies{permissionsImpl: impl},
+			account:     accountGDPR,
+			wantType:    &AllowHostCookies{},
+		},
+	}
+
+	for _, tt := range tests {
+		perms := WithAccountConfig(tt.perms, tt.account)
+
+		assert.IsType(t, tt.wantType, perms, tt.description)
+		if tt.wantSame {
+			assert.Same(t, tt.perms, perms, tt.description)
+		} else {
+			assert.NotSame(t, tt.perms, perms, tt.description)
+		}
+	}
+
+	accountPerms := WithAccountConfig(impl, accountGDPR).(*permissionsImpl)
+	assert.Equal(t, config.TCF2Purpose{
+		Enabled:            true,
+		EnforcePurpose:     config.TCF2BasicEnforcement,
+		EnforceVendors:     false,
+		VendorExceptions:   []openrtb_ext.BidderName{"appnexus"},
+		VendorExceptionMap: map[openrtb_ext.BidderName]struct{}{"appnexus": {}},
+	}, accountPerms.purposeConfigs[2], "purpose 2")
+	assert.Equal(t, impl.purposeConfigs[1], accountPerms.purposeConfigs[1], "purpose 1")
+	assert.False(t, accountPerms.cfg.TCF2.SpecialPurpose1.Enabled, "special feature 1")
+
+	assert.Equal(t, config.TCF2FullEnforcement, impl.purposeConfigs[2].EnforcePurpose, "the original permissions are left as is")
+	assert.True(t, impl.cfg.TCF2.SpecialPurpose1.Enabled, "the original permissions are left as is")
+}
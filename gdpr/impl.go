@@ -21,6 +21,8 @@ type permissionsImpl struct {
 	purposeConfigs   map[consentconstants.Purpose]config.TCF2Purpose
 	vendorIDs        map[openrtb_ext.BidderName]uint16
 	fetchVendorList  map[uint8]func(ctx context.Context, id uint16) (vendorlist.VendorList, error)
+	// aliasVendorIDs are the vendor IDs of the aliases of an auction which don't share the vendor of their core bidder
+	aliasVendorIDs map[openrtb_ext.BidderName]uint16
 }
 
 func (p *permissionsImpl) HostCookiesAllowed(ctx context.Context, gdprSignal Signal, consent string) (bool, error) {
@@ -69,7 +71,9 @@ func (p *permissionsImpl) AuctionActivitiesAllowed(ctx context.Context,
 		return false, false, false, nil
 	}
 
-	if id, ok := p.vendorIDs[bidder]; ok {
+	if id, ok := p.aliasVendorIDs[bidder]; ok {
+		return p.allowActivities(ctx, id, bidder, consent, weakVendorEnforcement)
+	} else if id, ok := p.vendorIDs[bidder]; ok {
 		return p.allowActivities(ctx, id, bidder, consent, weakVendorEnforcement)
 	} else if weakVendorEnforcement {
 		return p.allowActivities(ctx, 0, bidder, consent, weakVendorEnforcement)
@@ -174,6 +178,9 @@ const pubRestrictRequireConsent = 1
 const pubRestrictRequireLegitInterest = 2
 
 func (p *permissionsImpl) checkPurpose(consent tcf2.ConsentMetadata, vendor api.Vendor, vendorID uint16, purpose consentconstants.Purpose, vendorException, weakVendorEnforcement bool) bool {
+	if p.purposeConfigs[purpose].EnforcePurpose == config.TCF2NoEnforcement {
+		return true
+	}
 	if purpose == tcf2ConsentConstants.InfoStorageAccess && p.cfg.TCF2.PurposeOneTreatment.Enabled && consent.PurposeOneTreatment() {
 		return p.cfg.TCF2.PurposeOneTreatment.AccessAllowed
 	}
@@ -209,6 +216,9 @@ func (p *permissionsImpl) consentEstablished(consent tcf2.ConsentMetadata, vendo
 	if !p.purposeConfigs[purpose].EnforceVendors {
 		return true
 	}
+	if p.basicEnforcement(purpose) {
+		return consent.VendorConsent(vendorID)
+	}
 	if vendor.Purpose(purpose) && consent.VendorConsent(vendorID) {
 		return true
 	}
@@ -216,7 +226,7 @@ func (p *permissionsImpl) consentEstablished(consent tcf2.ConsentMetadata, vendo
 }
 
 func (p *permissionsImpl) legitInterestEstablished(consent tcf2.ConsentMetadata, vendor api.Vendor, vendorID uint16, purpose consentconstants.Purpose, weakVendorEnforcement bool) bool {
-	if !consent.PurposeLITransparency(purpose) {
+	if !legitInterestAllowed(purpose) || !consent.PurposeLITransparency(purpose) {
 		return false
 	}
 	if weakVendorEnforcement {
@@ -225,12 +235,30 @@ func (p *permissionsImpl) legitInterestEstablished(consent tcf2.ConsentMetadata,
 	if !p.purposeConfigs[purpose].EnforceVendors {
 		return true
 	}
+	if p.basicEnforcement(purpose) {
+		return consent.VendorLegitInterest(vendorID)
+	}
 	if vendor.LegitimateInterest(purpose) && consent.VendorLegitInterest(vendorID) {
 		return true
 	}
 	return false
 }
 
+// basicEnforcement tells if the purpose is enforced without the vendor declarations of the Global Vendor List
+func (p *permissionsImpl) basicEnforcement(purpose consentconstants.Purpose) bool {
+	return p.purposeConfigs[purpose].EnforcePurpose == config.TCF2BasicEnforcement
+}
+
+// legitInterestAllowed tells if the purpose may be processed on the legal basis of legitimate interest. TCF Policy 2.2
+// limits it to the purposes 2 and 7 to 10, the storage and the personalization purposes needing consent.
+func legitInterestAllowed(purpose consentconstants.Purpose) bool {
+	switch purpose {
+	case 1, 3, 4, 5, 6:
+		return false
+	}
+	return true
+}
+
 func (p *permissionsImpl) parseVendor(ctx context.Context, vendorID uint16, consent string) (parsedConsent api.VendorConsents, vendor api.Vendor, err error) {
 	parsedConsent, err = vendorconsent.ParseString(consent)
 	if err != nil {
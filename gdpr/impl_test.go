@@ -329,6 +329,58 @@ func TestAllowActivities(t *testing.T) {
 	}
 }
 
+func TestAllowActivitiesAliasGVLIDs(t *testing.T) {
+	vendor2AndPurpose2Consent := "CPGWbY_PGWbY_GYAAAENABCAAEAAAAAAAAAAACEAAAAA"
+	vendorListData := MarshalVendorList(vendorList{
+		VendorListVersion: 2,
+		Vendors: map[string]*vendor{
+			"2": {
+				ID:       2,
+				Purposes: []int{2},
+			},
+		},
+	})
+
+	perms := &permissionsImpl{
+		cfg: config.GDPR{
+			HostVendorID: 2,
+			TCF2: config.TCF2{
+				Enabled: true,
+				Purpose2: config.TCF2Purpose{
+					Enabled:        true,
+					EnforceVendors: true,
+				},
+			},
+		},
+		gdprDefaultValue: SignalYes,
+		vendorIDs: map[openrtb_ext.BidderName]uint16{
+			openrtb_ext.BidderRubicon: 52,
+		},
+		fetchVendorList: map[uint8]func(ctx context.Context, id uint16) (vendorlist.VendorList, error){
+			tcf2SpecVersion: listFetcher(map[uint16]vendorlist.VendorList{
+				1: parseVendorListDataV2(t, vendorListData),
+			}),
+		},
+	}
+	perms.purposeConfigs = map[consentconstants.Purpose]config.TCF2Purpose{
+		consentconstants.Purpose(2): perms.cfg.TCF2.Purpose2,
+	}
+
+	aliasPerms := WithAliasGVLIDs(perms, map[openrtb_ext.BidderName]uint16{"rubiconalias": 2})
+
+	_, _, passID, err := aliasPerms.AuctionActivitiesAllowed(context.Background(), "rubiconalias", "", SignalYes, vendor2AndPurpose2Consent, false)
+	assert.NoError(t, err)
+	assert.True(t, passID, "the alias is checked against its own vendor")
+
+	_, _, passID, err = aliasPerms.AuctionActivitiesAllowed(context.Background(), openrtb_ext.BidderRubicon, "", SignalYes, vendor2AndPurpose2Consent, false)
+	assert.NoError(t, err)
+	assert.False(t, passID, "the core bidder is checked against the vendor of the core bidder")
+
+	_, _, passID, err = perms.AuctionActivitiesAllowed(context.Background(), "rubiconalias", "", SignalYes, vendor2AndPurpose2Consent, false)
+	assert.NoError(t, err)
+	assert.False(t, passID, "the permissions the alias vendors were added to are left as is")
+}
+
 func buildVendorList34() vendorList {
 	return vendorList{
 		VendorListVersion: 2,
@@ -983,3 +1035,76 @@ func TestBidderSyncAllowedVendorException(t *testing.T) {
 		assert.EqualValuesf(t, td.allowSync, allowSync, "AllowSync failure on %s", td.description)
 	}
 }
+
+func TestAllowActivitiesEnforcePurpose(t *testing.T) {
+	vendorListData := MarshalVendorList(buildVendorList34())
+
+	// COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA : full consents to purposes and vendors 2, 6, 8
+	consent := "COzTVhaOzTVhaGvAAAENAiCIAP_AAH_AAAAAAEEUACCKAAA"
+
+	testDefs := []struct {
+		description    string
+		enforcePurpose string
+		bidder         openrtb_ext.BidderName
+		allowBid       bool
+		passID         bool
+	}{
+		{
+			description:    "Full enforcement, vendor consent without the purpose claimed",
+			enforcePurpose: config.TCF2FullEnforcement,
+			bidder:         openrtb_ext.BidderAppnexus,
+			allowBid:       false,
+			passID:         false,
+		},
+		{
+			description:    "Basic enforcement, vendor consent without the purpose claimed",
+			enforcePurpose: config.TCF2BasicEnforcement,
+			bidder:         openrtb_ext.BidderAppnexus,
+			allowBid:       true,
+			passID:         true,
+		},
+		{
+			description:    "Basic enforcement, purpose claimed without vendor consent",
+			enforcePurpose: config.TCF2BasicEnforcement,
+			bidder:         openrtb_ext.BidderOpenx,
+			allowBid:       false,
+			passID:         false,
+		},
+		{
+			description:    "No enforcement, purpose claimed without vendor consent",
+			enforcePurpose: config.TCF2NoEnforcement,
+			bidder:         openrtb_ext.BidderOpenx,
+			allowBid:       true,
+			passID:         true,
+		},
+	}
+
+	for _, td := range testDefs {
+		perms := allPurposesEnabledPermissions()
+		perms.vendorIDs = map[openrtb_ext.BidderName]uint16{
+			openrtb_ext.BidderAppnexus: 2,
+			openrtb_ext.BidderOpenx:    32,
+		}
+		perms.fetchVendorList = map[uint8]func(ctx context.Context, id uint16) (vendorlist.VendorList, error){
+			tcf2SpecVersion: listFetcher(map[uint16]vendorlist.VendorList{
+				34: parseVendorListDataV2(t, vendorListData),
+			}),
+		}
+		for purpose, purposeConfig := range perms.purposeConfigs {
+			purposeConfig.EnforcePurpose = td.enforcePurpose
+			perms.purposeConfigs[purpose] = purposeConfig
+		}
+
+		allowBid, _, passID, err := perms.AuctionActivitiesAllowed(context.Background(), td.bidder, "", SignalYes, consent, false)
+		assert.NoErrorf(t, err, "Error processing AuctionActivitiesAllowed for %s", td.description)
+		assert.EqualValuesf(t, td.allowBid, allowBid, "AllowBid failure on %s", td.description)
+		assert.EqualValuesf(t, td.passID, passID, "PassID failure on %s", td.description)
+	}
+}
+
+func TestLegitInterestAllowed(t *testing.T) {
+	for purpose := consentconstants.Purpose(1); purpose <= 10; purpose++ {
+		expected := purpose == 2 || purpose >= 7
+		assert.Equal(t, expected, legitInterestAllowed(purpose), "purpose %d", purpose)
+	}
+}
@@ -0,0 +1,70 @@
+package geolocation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/util/task"
+)
+
+// GeoInfo is the location of an IP address
+type GeoInfo struct {
+	// Country is the ISO-3166-1-alpha-3 code of the country, as in device.geo.country
+	Country string
+	// Region is the ISO-3166-2 subdivision code within the country, such as CA for California, if it is known
+	Region string
+}
+
+// Resolver locates IP addresses. Lookup returns nil without an error for the addresses it can't locate.
+type Resolver interface {
+	Lookup(ctx context.Context, ip string) (*GeoInfo, error)
+}
+
+// NewResolver returns the resolver of the config, which is nil when the geolocation is disabled. The lookups are timed
+// by the metrics engine. The shutdown func stops the refresh of the MaxMind DB file.
+func NewResolver(cfg config.GeoLocation, client *http.Client, metricsEngine metrics.MetricsEngine) (resolver Resolver, shutdown func(), err error) {
+	shutdown = func() {}
+	if !cfg.Enabled {
+		return nil, shutdown, nil
+	}
+
+	switch cfg.Type {
+	case config.GeoLocationTypeMaxMind:
+		maxMindResolver, err := NewMaxMindResolver(cfg.MaxMind.DatabasePath)
+		if err != nil {
+			return nil, shutdown, err
+		}
+		if cfg.MaxMind.RefreshIntervalSeconds > 0 {
+			refreshTask := task.NewTickerTask(time.Duration(cfg.MaxMind.RefreshIntervalSeconds)*time.Second, maxMindResolver)
+			refreshTask.Start()
+			shutdown = refreshTask.Stop
+		}
+		resolver = maxMindResolver
+	case config.GeoLocationTypeHTTP:
+		httpResolver, err := NewHTTPResolver(client, cfg.HTTP.Endpoint, time.Duration(cfg.HTTP.TimeoutMs)*time.Millisecond)
+		if err != nil {
+			return nil, shutdown, err
+		}
+		resolver = httpResolver
+	default:
+		return nil, shutdown, fmt.Errorf("the geolocation type %q isn't supported", cfg.Type)
+	}
+	return timedResolver{resolver: resolver, metricsEngine: metricsEngine}, shutdown, nil
+}
+
+// timedResolver records the time taken by the lookups of a resolver
+type timedResolver struct {
+	resolver      Resolver
+	metricsEngine metrics.MetricsEngine
+}
+
+func (r timedResolver) Lookup(ctx context.Context, ip string) (*GeoInfo, error) {
+	start := time.Now()
+	geo, err := r.resolver.Lookup(ctx, ip)
+	r.metricsEngine.RecordGeoLocationLookupTime(err == nil, time.Since(start))
+	return geo, err
+}
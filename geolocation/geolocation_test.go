@@ -0,0 +1,74 @@
+package geolocation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewResolver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "GeoLite2-City.mmdb")
+	writeMMDB(t, path, buildMMDB(t, 6, 24, testNetworks), time.Now())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"country":"GB"}`))
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		description     string
+		givenConfig     config.GeoLocation
+		expectedNil     bool
+		expectedLookups bool
+		expectedError   bool
+	}{
+		{
+			description: "Disabled",
+			givenConfig: config.GeoLocation{Type: config.GeoLocationTypeMaxMind},
+			expectedNil: true,
+		},
+		{
+			description: "MaxMind",
+			givenConfig: config.GeoLocation{Enabled: true, Type: config.GeoLocationTypeMaxMind, MaxMind: config.GeoLocationMaxMind{DatabasePath: path, RefreshIntervalSeconds: 60}},
+		},
+		{
+			description: "HTTP",
+			givenConfig: config.GeoLocation{Enabled: true, Type: config.GeoLocationTypeHTTP, HTTP: config.GeoLocationHTTP{Endpoint: server.URL, TimeoutMs: 1000}},
+		},
+		{
+			description:   "Missing MaxMind DB file",
+			givenConfig:   config.GeoLocation{Enabled: true, Type: config.GeoLocationTypeMaxMind, MaxMind: config.GeoLocationMaxMind{DatabasePath: path + ".missing"}},
+			expectedNil:   true,
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		metricsMock := &metrics.MetricsEngineMock{}
+		metricsMock.On("RecordGeoLocationLookupTime", true, mock.Anything).Return()
+
+		resolver, shutdown, err := NewResolver(test.givenConfig, server.Client(), metricsMock)
+		require.NotNil(t, shutdown, test.description)
+		defer shutdown()
+
+		assert.Equal(t, test.expectedError, err != nil, test.description+":error")
+		if test.expectedNil {
+			assert.Nil(t, resolver, test.description)
+			continue
+		}
+
+		geo, err := resolver.Lookup(context.Background(), "81.2.69.142")
+		assert.NoError(t, err, test.description)
+		assert.Equal(t, "GBR", geo.Country, test.description)
+		metricsMock.AssertNumberOfCalls(t, "RecordGeoLocationLookupTime", 1)
+	}
+}
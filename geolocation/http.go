@@ -0,0 +1,71 @@
+package geolocation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HTTPResolver locates the IP addresses by calling a geolocation service as GET <endpoint>?ip=<ip>. The service
+// responds with a JSON object such as {"country":"USA","region":"CA"}, where the country is either an ISO-3166-1
+// alpha-2 or alpha-3 code, or with a 404 when it can't locate the address.
+type HTTPResolver struct {
+	client   *http.Client
+	endpoint *url.URL
+	timeout  time.Duration
+}
+
+// NewHTTPResolver returns a resolver calling the service at the endpoint, which times out the calls after the timeout
+func NewHTTPResolver(client *http.Client, endpoint string, timeout time.Duration) (*HTTPResolver, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("the geolocation endpoint %s is invalid: %v", endpoint, err)
+	}
+	return &HTTPResolver{client: client, endpoint: endpointURL, timeout: timeout}, nil
+}
+
+type httpGeoResponse struct {
+	Country string `json:"country"`
+	Region  string `json:"region"`
+}
+
+// Lookup implements the Resolver interface
+func (r *HTTPResolver) Lookup(ctx context.Context, ip string) (*GeoInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	lookupURL := *r.endpoint
+	query := lookupURL.Query()
+	query.Set("ip", ip)
+	lookupURL.RawQuery = query.Encode()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, lookupURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := r.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the geolocation service responded with status code %d", response.StatusCode)
+	}
+
+	var geoResponse httpGeoResponse
+	if err := json.NewDecoder(response.Body).Decode(&geoResponse); err != nil {
+		return nil, fmt.Errorf("the geolocation service response is invalid: %v", err)
+	}
+	geo := &GeoInfo{Country: countryAlpha3(geoResponse.Country), Region: geoResponse.Region}
+	if geo.Country == "" {
+		return nil, nil
+	}
+	return geo, nil
+}
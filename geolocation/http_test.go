@@ -0,0 +1,91 @@
+package geolocation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPResolverLookup(t *testing.T) {
+	testCases := []struct {
+		description       string
+		givenStatus       int
+		givenResponseBody string
+		expectedGeo       *GeoInfo
+		expectedError     string
+	}{
+		{
+			description:       "Alpha-2 country and region",
+			givenStatus:       http.StatusOK,
+			givenResponseBody: `{"country":"us","region":"CA"}`,
+			expectedGeo:       &GeoInfo{Country: "USA", Region: "CA"},
+		},
+		{
+			description:       "Alpha-3 country",
+			givenStatus:       http.StatusOK,
+			givenResponseBody: `{"country":"DEU"}`,
+			expectedGeo:       &GeoInfo{Country: "DEU"},
+		},
+		{
+			description:       "Unknown country",
+			givenStatus:       http.StatusOK,
+			givenResponseBody: `{"country":"ZZ"}`,
+		},
+		{
+			description: "Address not located",
+			givenStatus: http.StatusNotFound,
+		},
+		{
+			description:   "Failed lookup",
+			givenStatus:   http.StatusInternalServerError,
+			expectedError: "the geolocation service responded with status code 500",
+		},
+		{
+			description:       "Malformed response",
+			givenStatus:       http.StatusOK,
+			givenResponseBody: `malformed`,
+			expectedError:     "the geolocation service response is invalid: invalid character 'm' looking for beginning of value",
+		},
+	}
+
+	for _, test := range testCases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/lookup?ip=81.2.69.142&key=secret", r.URL.RequestURI(), test.description+":uri")
+			w.WriteHeader(test.givenStatus)
+			w.Write([]byte(test.givenResponseBody))
+		}))
+
+		resolver, err := NewHTTPResolver(server.Client(), server.URL+"/lookup?key=secret", time.Second)
+		require.NoError(t, err, test.description)
+
+		geo, err := resolver.Lookup(context.Background(), "81.2.69.142")
+
+		assert.Equal(t, test.expectedGeo, geo, test.description)
+		if test.expectedError == "" {
+			assert.NoError(t, err, test.description)
+		} else {
+			assert.EqualError(t, err, test.expectedError, test.description)
+		}
+		server.Close()
+	}
+}
+
+func TestHTTPResolverTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	resolver, err := NewHTTPResolver(server.Client(), server.URL, time.Millisecond)
+	require.NoError(t, err)
+
+	geo, err := resolver.Lookup(context.Background(), "81.2.69.142")
+
+	assert.Nil(t, geo)
+	assert.Error(t, err)
+}
@@ -0,0 +1,102 @@
+package geolocation
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// MaxMindResolver locates the IP addresses from a MaxMind DB file, such as GeoIP2 City or GeoLite2 City. The file is
+// read in memory, and reloaded by Run when it changed, so that a ticker task keeps it up to date.
+type MaxMindResolver struct {
+	path   string
+	reader atomic.Value // Should only hold *mmdbReader
+	// modTime is the modification time of the file last read. It is only accessed by Run, which isn't called concurrently.
+	modTime time.Time
+}
+
+// NewMaxMindResolver reads the MaxMind DB file at the path
+func NewMaxMindResolver(path string) (*MaxMindResolver, error) {
+	resolver := &MaxMindResolver{path: path}
+	if err := resolver.Run(); err != nil {
+		return nil, err
+	}
+	return resolver, nil
+}
+
+// Run reloads the file if it changed since it was last read. The resolver keeps the file it last read when the file
+// can't be read anymore.
+func (r *MaxMindResolver) Run() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return r.loadFailed(err)
+	}
+	if info.ModTime().Equal(r.modTime) {
+		return nil
+	}
+
+	buffer, err := ioutil.ReadFile(r.path)
+	if err != nil {
+		return r.loadFailed(err)
+	}
+	reader, err := newMMDBReader(buffer)
+	if err != nil {
+		return r.loadFailed(err)
+	}
+
+	r.reader.Store(reader)
+	r.modTime = info.ModTime()
+	return nil
+}
+
+func (r *MaxMindResolver) loadFailed(err error) error {
+	err = fmt.Errorf("the MaxMind DB file %s could not be read: %v", r.path, err)
+	if r.reader.Load() != nil {
+		glog.Errorf("Geolocation: %v, the previous file is kept", err)
+	}
+	return err
+}
+
+// Lookup implements the Resolver interface. It reads the country.iso_code and subdivisions.0.iso_code of the
+// GeoIP2 records.
+func (r *MaxMindResolver) Lookup(ctx context.Context, ip string) (*GeoInfo, error) {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return nil, fmt.Errorf("%q isn't an IP address", ip)
+	}
+
+	value, err := r.reader.Load().(*mmdbReader).lookup(parsedIP)
+	if err != nil {
+		return nil, fmt.Errorf("the MaxMind DB file %s is invalid: %v", r.path, err)
+	}
+
+	geo := &GeoInfo{Country: countryAlpha3(readString(value, "country", "iso_code"))}
+	if geo.Country == "" {
+		return nil, nil
+	}
+	if record, ok := value.(map[string]interface{}); ok {
+		if subdivisions, ok := record["subdivisions"].([]interface{}); ok && len(subdivisions) > 0 {
+			geo.Region = readString(subdivisions[0], "iso_code")
+		}
+	}
+	return geo, nil
+}
+
+// readString returns the string at the path of keys in nested maps, or an empty string if there is none
+func readString(value interface{}, keys ...string) string {
+	for _, key := range keys {
+		record, ok := value.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		value = record[key]
+	}
+	s, _ := value.(string)
+	return s
+}
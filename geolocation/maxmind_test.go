@@ -0,0 +1,330 @@
+package geolocation
+
+import (
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mmdbTestPointer is encoded as a pointer to the offset in the data section
+type mmdbTestPointer uint
+
+type mmdbTestNetwork struct {
+	cidr string
+	data interface{}
+}
+
+// buildMMDB writes a MaxMind DB with the networks, whose data is written in turn in the data section
+func buildMMDB(t *testing.T, ipVersion int, recordSize uint, networks []mmdbTestNetwork) []byte {
+	// The records are -1 when empty, >= 0 for a node and < -1 for the data at the offset -2-record
+	nodes := [][2]int{{-1, -1}}
+	var data []byte
+	for _, network := range networks {
+		_, ipNet, err := net.ParseCIDR(network.cidr)
+		require.NoError(t, err, network.cidr)
+		ip := ipNet.IP
+		prefix, _ := ipNet.Mask.Size()
+		if ipVersion == 6 && len(ip) == net.IPv4len {
+			ip = append(make(net.IP, 12), ip...)
+			prefix += 96
+		}
+
+		node := 0
+		for i := 0; i < prefix; i++ {
+			bit := (ip[i/8] >> (7 - uint(i%8))) & 1
+			if i == prefix-1 {
+				nodes[node][bit] = -2 - len(data)
+				break
+			}
+			if nodes[node][bit] < 0 {
+				nodes = append(nodes, [2]int{-1, -1})
+				nodes[node][bit] = len(nodes) - 1
+			}
+			node = nodes[node][bit]
+		}
+		data = append(data, encodeMMDB(network.data)...)
+	}
+
+	nodeCount := uint(len(nodes))
+	var tree []byte
+	for _, node := range nodes {
+		var records [2]uint
+		for i, record := range node {
+			switch {
+			case record == -1:
+				records[i] = nodeCount
+			case record >= 0:
+				records[i] = uint(record)
+			default:
+				records[i] = nodeCount + mmdbDataSectionSeparator + uint(-2-record)
+			}
+		}
+		switch recordSize {
+		case 24:
+			tree = append(tree, byte(records[0]>>16), byte(records[0]>>8), byte(records[0]),
+				byte(records[1]>>16), byte(records[1]>>8), byte(records[1]))
+		case 28:
+			tree = append(tree, byte(records[0]>>16), byte(records[0]>>8), byte(records[0]),
+				byte(records[0]>>24<<4|records[1]>>24), byte(records[1]>>16), byte(records[1]>>8), byte(records[1]))
+		default:
+			record := make([]byte, 8)
+			binary.BigEndian.PutUint32(record, uint32(records[0]))
+			binary.BigEndian.PutUint32(record[4:], uint32(records[1]))
+			tree = append(tree, record...)
+		}
+	}
+
+	db := append(tree, make([]byte, mmdbDataSectionSeparator)...)
+	db = append(db, data...)
+	db = append(db, mmdbMetadataStart...)
+	return append(db, encodeMMDB(map[string]interface{}{
+		"node_count":    uint32(nodeCount),
+		"record_size":   uint16(recordSize),
+		"ip_version":    uint16(ipVersion),
+		"database_type": "GeoLite2-City",
+	})...)
+}
+
+func encodeMMDB(value interface{}) []byte {
+	switch v := value.(type) {
+	case string:
+		return append(encodeMMDBControl(mmdbString, len(v)), v...)
+	case uint16:
+		return append(encodeMMDBControl(mmdbUint16, 2), byte(v>>8), byte(v))
+	case uint32:
+		return append(encodeMMDBControl(mmdbUint32, 4), byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	case bool:
+		size := 0
+		if v {
+			size = 1
+		}
+		return encodeMMDBControl(mmdbBoolean, size)
+	case mmdbTestPointer:
+		return []byte{byte(mmdbPointer<<5) | byte(v>>8&0x7), byte(v)}
+	case []interface{}:
+		encoded := encodeMMDBControl(mmdbArray, len(v))
+		for _, item := range v {
+			encoded = append(encoded, encodeMMDB(item)...)
+		}
+		return encoded
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		encoded := encodeMMDBControl(mmdbMap, len(v))
+		for _, key := range keys {
+			encoded = append(encoded, encodeMMDB(key)...)
+			encoded = append(encoded, encodeMMDB(v[key])...)
+		}
+		return encoded
+	}
+	panic("unsupported type")
+}
+
+func encodeMMDBControl(dataType uint, size int) []byte {
+	if dataType > 7 {
+		return []byte{byte(size), byte(dataType - 7)}
+	}
+	return []byte{byte(dataType<<5) | byte(size)}
+}
+
+func geoIP2Record(country string, subdivisions ...string) map[string]interface{} {
+	record := map[string]interface{}{
+		"country": map[string]interface{}{"iso_code": country, "is_in_european_union": country == "DE"},
+	}
+	if len(subdivisions) > 0 {
+		var items []interface{}
+		for _, subdivision := range subdivisions {
+			items = append(items, map[string]interface{}{"iso_code": subdivision})
+		}
+		record["subdivisions"] = items
+	}
+	return record
+}
+
+var testNetworks = []mmdbTestNetwork{
+	{cidr: "81.2.69.0/24", data: geoIP2Record("GB", "ENG")},
+	{cidr: "2001:db8::/32", data: geoIP2Record("DE")},
+	{cidr: "10.0.0.0/8", data: map[string]interface{}{"continent": map[string]interface{}{"code": "EU"}}},
+	{cidr: "1.2.3.0/24", data: mmdbTestPointer(0)},
+	{cidr: "12.0.0.0/8", data: geoIP2Record("US", "CA", "XX")},
+}
+
+func writeMMDB(t *testing.T, path string, db []byte, modTime time.Time) {
+	require.NoError(t, ioutil.WriteFile(path, db, 0644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestMaxMindResolverLookup(t *testing.T) {
+	testCases := []struct {
+		description     string
+		givenIPVersion  int
+		givenRecordSize uint
+		givenIP         string
+		expectedGeo     *GeoInfo
+		expectedError   string
+	}{
+		{
+			description:     "IPv4 address in an IPv6 database",
+			givenIPVersion:  6,
+			givenRecordSize: 28,
+			givenIP:         "81.2.69.142",
+			expectedGeo:     &GeoInfo{Country: "GBR", Region: "ENG"},
+		},
+		{
+			description:     "IPv6 address in an IPv6 database",
+			givenIPVersion:  6,
+			givenRecordSize: 24,
+			givenIP:         "2001:db8::1",
+			expectedGeo:     &GeoInfo{Country: "DEU"},
+		},
+		{
+			description:     "Data behind a pointer",
+			givenIPVersion:  6,
+			givenRecordSize: 28,
+			givenIP:         "1.2.3.4",
+			expectedGeo:     &GeoInfo{Country: "GBR", Region: "ENG"},
+		},
+		{
+			description:     "First subdivision",
+			givenIPVersion:  4,
+			givenRecordSize: 24,
+			givenIP:         "12.1.1.1",
+			expectedGeo:     &GeoInfo{Country: "USA", Region: "CA"},
+		},
+		{
+			description:     "IPv4 address in an IPv4 database",
+			givenIPVersion:  4,
+			givenRecordSize: 32,
+			givenIP:         "81.2.69.142",
+			expectedGeo:     &GeoInfo{Country: "GBR", Region: "ENG"},
+		},
+		{
+			description:     "IPv6 address in an IPv4 database",
+			givenIPVersion:  4,
+			givenRecordSize: 24,
+			givenIP:         "2001:db8::1",
+		},
+		{
+			description:     "Network without data",
+			givenIPVersion:  6,
+			givenRecordSize: 24,
+			givenIP:         "8.8.8.8",
+		},
+		{
+			description:     "Network without country",
+			givenIPVersion:  6,
+			givenRecordSize: 24,
+			givenIP:         "10.1.1.1",
+		},
+		{
+			description:     "Invalid IP address",
+			givenIPVersion:  6,
+			givenRecordSize: 24,
+			givenIP:         "localhost",
+			expectedError:   `"localhost" isn't an IP address`,
+		},
+	}
+
+	for _, test := range testCases {
+		networks := testNetworks
+		if test.givenIPVersion == 4 {
+			networks = []mmdbTestNetwork{testNetworks[0], testNetworks[4]}
+		}
+		path := filepath.Join(t.TempDir(), "GeoLite2-City.mmdb")
+		writeMMDB(t, path, buildMMDB(t, test.givenIPVersion, test.givenRecordSize, networks), time.Now())
+
+		resolver, err := NewMaxMindResolver(path)
+		require.NoError(t, err, test.description)
+
+		geo, err := resolver.Lookup(context.Background(), test.givenIP)
+
+		assert.Equal(t, test.expectedGeo, geo, test.description)
+		if test.expectedError == "" {
+			assert.NoError(t, err, test.description)
+		} else {
+			assert.EqualError(t, err, test.expectedError, test.description)
+		}
+	}
+}
+
+func TestMaxMindResolverRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "GeoLite2-City.mmdb")
+	modTime := time.Now().Add(-time.Hour)
+	writeMMDB(t, path, buildMMDB(t, 6, 24, []mmdbTestNetwork{{cidr: "81.2.69.0/24", data: geoIP2Record("GB")}}), modTime)
+
+	resolver, err := NewMaxMindResolver(path)
+	require.NoError(t, err)
+
+	// The file is read again when it changed only
+	writeMMDB(t, path, buildMMDB(t, 6, 24, []mmdbTestNetwork{{cidr: "81.2.69.0/24", data: geoIP2Record("FR")}}), modTime)
+	assert.NoError(t, resolver.Run(), "unchanged file")
+	geo, _ := resolver.Lookup(context.Background(), "81.2.69.1")
+	assert.Equal(t, &GeoInfo{Country: "GBR"}, geo, "unchanged file")
+
+	writeMMDB(t, path, buildMMDB(t, 6, 24, []mmdbTestNetwork{{cidr: "81.2.69.0/24", data: geoIP2Record("FR")}}), modTime.Add(time.Minute))
+	assert.NoError(t, resolver.Run(), "changed file")
+	geo, _ = resolver.Lookup(context.Background(), "81.2.69.1")
+	assert.Equal(t, &GeoInfo{Country: "FRA"}, geo, "changed file")
+
+	// An invalid file is ignored
+	writeMMDB(t, path, []byte("invalid"), modTime.Add(2*time.Minute))
+	assert.EqualError(t, resolver.Run(), "the MaxMind DB file "+path+" could not be read: the file isn't a MaxMind DB, its metadata is missing", "invalid file")
+	geo, _ = resolver.Lookup(context.Background(), "81.2.69.1")
+	assert.Equal(t, &GeoInfo{Country: "FRA"}, geo, "invalid file")
+}
+
+func TestNewMaxMindResolverErrors(t *testing.T) {
+	db := buildMMDB(t, 6, 24, testNetworks)
+	metadataStart := len(db) - len(encodeMMDB(map[string]interface{}{
+		"node_count":    uint32(0),
+		"record_size":   uint16(0),
+		"ip_version":    uint16(0),
+		"database_type": "GeoLite2-City",
+	}))
+
+	testCases := []struct {
+		description   string
+		givenDB       []byte
+		expectedError string
+	}{
+		{
+			description:   "Unsupported record size",
+			givenDB:       buildMMDB(t, 6, 20, testNetworks),
+			expectedError: "the record size must be 24, 28 or 32. Got 20",
+		},
+		{
+			description:   "Truncated search tree",
+			givenDB:       db[metadataStart-len(mmdbMetadataStart)-100:],
+			expectedError: "the search tree is truncated",
+		},
+		{
+			description:   "Truncated metadata",
+			givenDB:       db[:len(db)-1],
+			expectedError: "the metadata is invalid: the data is truncated",
+		},
+	}
+
+	for _, test := range testCases {
+		path := filepath.Join(t.TempDir(), "GeoLite2-City.mmdb")
+		writeMMDB(t, path, test.givenDB, time.Now())
+
+		_, err := NewMaxMindResolver(path)
+
+		assert.EqualError(t, err, "the MaxMind DB file "+path+" could not be read: "+test.expectedError, test.description)
+	}
+
+	_, err := NewMaxMindResolver(filepath.Join(t.TempDir(), "missing.mmdb"))
+	assert.Error(t, err, "missing file")
+}
@@ -0,0 +1,297 @@
+package geolocation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+)
+
+// The data types of the MaxMind DB format, as specified at https://maxmind.github.io/MaxMind-DB/
+const (
+	mmdbExtended uint = iota
+	mmdbPointer
+	mmdbString
+	mmdbDouble
+	mmdbBytes
+	mmdbUint16
+	mmdbUint32
+	mmdbMap
+	mmdbInt32
+	mmdbUint64
+	mmdbUint128
+	mmdbArray
+	mmdbContainer
+	mmdbEndMarker
+	mmdbBoolean
+	mmdbFloat
+)
+
+// mmdbDataSectionSeparator is the count of zero bytes between the search tree and the data section
+const mmdbDataSectionSeparator = 16
+
+var (
+	mmdbMetadataStart = []byte("\xAB\xCD\xEFMaxMind.com")
+	errMMDBTruncated  = errors.New("the data is truncated")
+)
+
+// mmdbReader looks up the IP addresses in the search tree of a MaxMind DB file read in memory
+type mmdbReader struct {
+	buffer     []byte
+	data       mmdbDecoder
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	// ipv4Start is the node of the IPv4 addresses, which are the ::/96 subnet of the IPv6 trees
+	ipv4Start uint
+}
+
+func newMMDBReader(buffer []byte) (*mmdbReader, error) {
+	metadataStart := bytes.LastIndex(buffer, mmdbMetadataStart)
+	if metadataStart < 0 {
+		return nil, errors.New("the file isn't a MaxMind DB, its metadata is missing")
+	}
+
+	value, _, err := mmdbDecoder{buffer: buffer[metadataStart+len(mmdbMetadataStart):]}.decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("the metadata is invalid: %v", err)
+	}
+	metadata, _ := value.(map[string]interface{})
+	nodeCount, _ := metadata["node_count"].(uint64)
+	recordSize, _ := metadata["record_size"].(uint64)
+	ipVersion, _ := metadata["ip_version"].(uint64)
+
+	if recordSize != 24 && recordSize != 28 && recordSize != 32 {
+		return nil, fmt.Errorf("the record size must be 24, 28 or 32. Got %d", recordSize)
+	}
+	if ipVersion != 4 && ipVersion != 6 {
+		return nil, fmt.Errorf("the ip version must be 4 or 6. Got %d", ipVersion)
+	}
+	treeSize := uint(nodeCount) * uint(recordSize) / 4
+	if treeSize+mmdbDataSectionSeparator > uint(metadataStart) {
+		return nil, errors.New("the search tree is truncated")
+	}
+
+	reader := &mmdbReader{
+		buffer:     buffer[:treeSize],
+		data:       mmdbDecoder{buffer: buffer[treeSize+mmdbDataSectionSeparator : metadataStart]},
+		nodeCount:  uint(nodeCount),
+		recordSize: uint(recordSize),
+		ipVersion:  uint(ipVersion),
+	}
+	if reader.ipVersion == 6 {
+		for i := 0; i < 96 && reader.ipv4Start < reader.nodeCount; i++ {
+			reader.ipv4Start = reader.readRecord(reader.ipv4Start, 0)
+		}
+	}
+	return reader, nil
+}
+
+// lookup returns the data of the network of the IP address, or nil if the database has none
+func (r *mmdbReader) lookup(ip net.IP) (interface{}, error) {
+	node := uint(0)
+	if ipv4 := ip.To4(); ipv4 != nil {
+		ip = ipv4
+		node = r.ipv4Start
+	} else if r.ipVersion == 4 {
+		return nil, nil
+	}
+
+	for i := 0; i < len(ip)*8 && node < r.nodeCount; i++ {
+		bit := (ip[i/8] >> (7 - uint(i%8))) & 1
+		node = r.readRecord(node, bit)
+	}
+
+	switch {
+	case node == r.nodeCount:
+		return nil, nil
+	case node < r.nodeCount:
+		return nil, errors.New("the search tree is deeper than the IP address")
+	}
+	value, _, err := r.data.decode(node - r.nodeCount - mmdbDataSectionSeparator)
+	return value, err
+}
+
+// readRecord returns the left record of the node for a 0 bit, and the right one for a 1 bit
+func (r *mmdbReader) readRecord(node uint, bit byte) uint {
+	b := r.buffer[node*r.recordSize/4:]
+	switch r.recordSize {
+	case 24:
+		b = b[uint(bit)*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		return uint(binary.BigEndian.Uint32(b[uint(bit)*4:]))
+	}
+}
+
+// mmdbDecoder decodes the values of a data section, or of the metadata, of a MaxMind DB file. The maps are decoded as
+// map[string]interface{}, the arrays as []interface{} and the unsigned integers as uint64, except uint128 which are
+// decoded as *big.Int.
+type mmdbDecoder struct {
+	buffer []byte
+}
+
+// decode returns the value at the offset, and the offset past it
+func (d mmdbDecoder) decode(offset uint) (interface{}, uint, error) {
+	dataType, size, offset, err := d.decodeControl(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if dataType == mmdbPointer {
+		pointer, next, err := d.decodePointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := d.decode(pointer)
+		return value, next, err
+	}
+	return d.decodeValue(dataType, size, offset)
+}
+
+func (d mmdbDecoder) decodeControl(offset uint) (dataType uint, size uint, next uint, err error) {
+	control, err := d.read(offset, 1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	offset++
+
+	dataType = uint(control[0] >> 5)
+	if dataType == mmdbExtended {
+		extended, err := d.read(offset, 1)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		dataType = 7 + uint(extended[0])
+		offset++
+	}
+
+	size = uint(control[0] & 0x1F)
+	if dataType == mmdbPointer || size < 29 {
+		return dataType, size, offset, nil
+	}
+
+	sizeBytes, err := d.read(offset, size-28)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	switch size {
+	case 29:
+		size = 29 + readUint(sizeBytes)
+	case 30:
+		size = 285 + readUint(sizeBytes)
+	default:
+		size = 65821 + readUint(sizeBytes)
+	}
+	return dataType, size, offset + uint(len(sizeBytes)), nil
+}
+
+func (d mmdbDecoder) decodePointer(size uint, offset uint) (uint, uint, error) {
+	pointerBytes, err := d.read(offset, (size>>3)&0x3+1)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	pointer := readUint(pointerBytes)
+	switch len(pointerBytes) {
+	case 1:
+		pointer |= (size & 0x7) << 8
+	case 2:
+		pointer = (pointer | (size&0x7)<<16) + 2048
+	case 3:
+		pointer = (pointer | (size&0x7)<<24) + 526336
+	}
+	return pointer, offset + uint(len(pointerBytes)), nil
+}
+
+func (d mmdbDecoder) decodeValue(dataType uint, size uint, offset uint) (interface{}, uint, error) {
+	switch dataType {
+	case mmdbMap:
+		values := make(map[string]interface{}, size)
+		for i := uint(0); i < size; i++ {
+			key, next, err := d.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			keyString, ok := key.(string)
+			if !ok {
+				return nil, 0, fmt.Errorf("the map keys must be strings. Got %T", key)
+			}
+			values[keyString], offset, err = d.decode(next)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+		return values, offset, nil
+	case mmdbArray:
+		values := make([]interface{}, size)
+		for i := range values {
+			var err error
+			if values[i], offset, err = d.decode(offset); err != nil {
+				return nil, 0, err
+			}
+		}
+		return values, offset, nil
+	case mmdbBoolean:
+		return size != 0, offset, nil
+	}
+
+	b, err := d.read(offset, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	offset += size
+
+	switch dataType {
+	case mmdbString:
+		return string(b), offset, nil
+	case mmdbBytes:
+		return append([]byte(nil), b...), offset, nil
+	case mmdbDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("the doubles must be 8 bytes. Got %d", size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), offset, nil
+	case mmdbFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("the floats must be 4 bytes. Got %d", size)
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(b)), offset, nil
+	case mmdbUint16, mmdbUint32, mmdbUint64:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("the unsigned integers must be at most 8 bytes. Got %d", size)
+		}
+		return uint64(readUint(b)), offset, nil
+	case mmdbInt32:
+		if size > 4 {
+			return nil, 0, fmt.Errorf("the signed integers must be at most 4 bytes. Got %d", size)
+		}
+		return int32(readUint(b)), offset, nil
+	case mmdbUint128:
+		return new(big.Int).SetBytes(b), offset, nil
+	}
+	return nil, 0, fmt.Errorf("the data type %d isn't supported", dataType)
+}
+
+func (d mmdbDecoder) read(offset uint, size uint) ([]byte, error) {
+	if offset+size > uint(len(d.buffer)) {
+		return nil, errMMDBTruncated
+	}
+	return d.buffer[offset : offset+size], nil
+}
+
+func readUint(b []byte) uint {
+	var value uint
+	for _, c := range b {
+		value = value<<8 | uint(c)
+	}
+	return value
+}
@@ -22,6 +22,14 @@ type UserSyncTemplateParams struct {
 	USPrivacy   string
 }
 
+// SetUIDRedirectTemplateParams specifies params for the chained redirect URL template of /setuid
+type SetUIDRedirectTemplateParams struct {
+	UID         string
+	GDPR        string
+	GDPRConsent string
+	USPrivacy   string
+}
+
 // ResolveMacros resolves macros in the given template with the provided params
 func ResolveMacros(aTemplate *template.Template, params interface{}) (string, error) {
 	strBuf := bytes.Buffer{}
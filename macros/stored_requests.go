@@ -0,0 +1,58 @@
+package macros
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+var (
+	macroStart = []byte("{{")
+	macroEnd   = []byte("}}")
+)
+
+// HasStoredRequestMacros returns whether the JSON of a stored request or imp may contain {{name}} macros
+func HasStoredRequestMacros(data []byte) bool {
+	return bytes.Contains(data, macroStart)
+}
+
+// ReplaceStoredRequestMacros replaces the {{name}} macros in the JSON of a stored request or imp with the values of
+// their names. The macros are meant to be used inside JSON strings, so the values are escaped as string contents.
+// The macros whose name has no value are left as they are.
+func ReplaceStoredRequestMacros(data []byte, values map[string]string) []byte {
+	if len(values) == 0 || !HasStoredRequestMacros(data) {
+		return data
+	}
+
+	resolved := bytes.NewBuffer(make([]byte, 0, len(data)))
+	for {
+		start := bytes.Index(data, macroStart)
+		if start < 0 {
+			break
+		}
+		end := bytes.Index(data[start+len(macroStart):], macroEnd)
+		if end < 0 {
+			break
+		}
+		end += start + len(macroStart)
+
+		resolved.Write(data[:start])
+		if value, ok := values[string(data[start+len(macroStart):end])]; ok {
+			resolved.Write(escapeJSONString(value))
+		} else {
+			resolved.Write(data[start : end+len(macroEnd)])
+		}
+		data = data[end+len(macroEnd):]
+	}
+	resolved.Write(data)
+	return resolved.Bytes()
+}
+
+// escapeJSONString returns the value as the contents of a JSON string, without the quotes
+func escapeJSONString(value string) []byte {
+	buffer := bytes.Buffer{}
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+	escaped := bytes.TrimSuffix(buffer.Bytes(), []byte("\n"))
+	return escaped[1 : len(escaped)-1]
+}
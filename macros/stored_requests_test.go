@@ -0,0 +1,57 @@
+package macros
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceStoredRequestMacros(t *testing.T) {
+	testCases := []struct {
+		description string
+		data        string
+		values      map[string]string
+		expected    string
+	}{
+		{
+			description: "No macros",
+			data:        `{"tagid":"1"}`,
+			values:      map[string]string{"page": "https://example.com"},
+			expected:    `{"tagid":"1"}`,
+		},
+		{
+			description: "Macros replaced",
+			data:        `{"tagid":"{{tagid}}","ext":{"bidder":{"url":"{{page}}?gdpr={{gdpr}}"}}}`,
+			values:      map[string]string{"tagid": "top", "page": "https://example.com/a", "gdpr": "1"},
+			expected:    `{"tagid":"top","ext":{"bidder":{"url":"https://example.com/a?gdpr=1"}}}`,
+		},
+		{
+			description: "Values escaped",
+			data:        `{"tagid":"{{tagid}}"}`,
+			values:      map[string]string{"tagid": `a"b\c<d>`},
+			expected:    `{"tagid":"a\"b\\c<d>"}`,
+		},
+		{
+			description: "Unknown macros kept",
+			data:        `{"tagid":"{{tagid}}","name":"{{unknown}}"}`,
+			values:      map[string]string{"tagid": "top"},
+			expected:    `{"tagid":"top","name":"{{unknown}}"}`,
+		},
+		{
+			description: "Unterminated macro kept",
+			data:        `{"tagid":"{{tagid}}","name":"{{tagid"}`,
+			values:      map[string]string{"tagid": "top"},
+			expected:    `{"tagid":"top","name":"{{tagid"}`,
+		},
+		{
+			description: "No values",
+			data:        `{"tagid":"{{tagid}}"}`,
+			values:      nil,
+			expected:    `{"tagid":"{{tagid}}"}`,
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, string(ReplaceStoredRequestMacros([]byte(test.data), test.values)), test.description)
+	}
+}
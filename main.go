@@ -46,7 +46,15 @@ func loadConfig() (*config.Configuration, error) {
 func serve(cfg *config.Configuration) error {
 	fetchingInterval := time.Duration(cfg.CurrencyConverter.FetchIntervalSeconds) * time.Second
 	staleRatesThreshold := time.Duration(cfg.CurrencyConverter.StaleRatesSeconds) * time.Second
-	currencyConverter := currency.NewRateConverter(&http.Client{}, cfg.CurrencyConverter.FetchURL, staleRatesThreshold)
+	rateSources := []currency.RateSource{{URL: cfg.CurrencyConverter.FetchURL, StaleRatesThreshold: staleRatesThreshold}}
+	for _, source := range cfg.CurrencyConverter.FallbackSources {
+		rateSources = append(rateSources, currency.RateSource{
+			URL:                 source.URL,
+			File:                source.File,
+			StaleRatesThreshold: time.Duration(source.StaleRatesSeconds) * time.Second,
+		})
+	}
+	currencyConverter := currency.NewRateConverterWithSources(&http.Client{}, rateSources)
 
 	currencyConverterTickerTask := task.NewTickerTask(fetchingInterval, currencyConverter)
 	currencyConverterTickerTask.Start()
@@ -59,7 +67,7 @@ func serve(cfg *config.Configuration) error {
 	pbc.InitPrebidCache(cfg.CacheURL.GetBaseURL())
 
 	corsRouter := router.SupportCORS(r)
-	server.Listen(cfg, router.NoCache{Handler: corsRouter}, router.Admin(currencyConverter, fetchingInterval), r.MetricsEngine)
+	server.Listen(cfg, router.NoCache{Handler: corsRouter}, router.Admin(currencyConverter, fetchingInterval, r.AccountReloader, r.CacheWrites), r.MetricsEngine)
 
 	r.Shutdown()
 	return nil
@@ -3,9 +3,11 @@ package config
 import (
 	"time"
 
+	"github.com/golang/glog"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/metrics"
 	prometheusmetrics "github.com/prebid/prebid-server/metrics/prometheus"
+	statsdmetrics "github.com/prebid/prebid-server/metrics/statsd"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	gometrics "github.com/rcrowley/go-metrics"
 	influxdb "github.com/vrischmann/go-metrics-influxdb"
@@ -40,6 +42,15 @@ func NewMetricsEngine(cfg *config.Configuration, adapterList []openrtb_ext.Bidde
 		returnEngine.PrometheusMetrics = prometheusmetrics.NewMetrics(cfg.Metrics.Prometheus, cfg.Metrics.Disabled, syncerKeys)
 		engineList = append(engineList, returnEngine.PrometheusMetrics)
 	}
+	if cfg.Metrics.StatsD.Address != "" {
+		// Set up the DogStatsD metrics, which are sent to the agent as they are recorded.
+		statsdMetrics, err := statsdmetrics.NewMetrics(cfg.Metrics.StatsD, cfg.Metrics.Disabled)
+		if err != nil {
+			glog.Fatalf("Failed to set up the StatsD metrics. %v", err)
+		}
+		returnEngine.StatsDMetrics = statsdMetrics
+		engineList = append(engineList, returnEngine.StatsDMetrics)
+	}
 
 	// Now return the proper metrics engine
 	if len(engineList) > 1 {
@@ -58,6 +69,7 @@ type DetailedMetricsEngine struct {
 	metrics.MetricsEngine
 	GoMetrics         *metrics.Metrics
 	PrometheusMetrics *prometheusmetrics.Metrics
+	StatsDMetrics     *statsdmetrics.Metrics
 }
 
 // MultiMetricsEngine logs metrics to multiple metrics databases The can be useful in transitioning
@@ -223,6 +235,13 @@ func (me *MultiMetricsEngine) RecordAccountCacheResult(cacheResult metrics.Cache
 	}
 }
 
+// RecordSharedCacheResult across all engines
+func (me *MultiMetricsEngine) RecordSharedCacheResult(dataType metrics.SharedCacheDataType, cacheResult metrics.CacheResult, inc int) {
+	for _, thisME := range *me {
+		thisME.RecordSharedCacheResult(dataType, cacheResult, inc)
+	}
+}
+
 // RecordPrebidCacheRequestTime across all engines
 func (me *MultiMetricsEngine) RecordPrebidCacheRequestTime(success bool, length time.Duration) {
 	for _, thisME := range *me {
@@ -230,6 +249,20 @@ func (me *MultiMetricsEngine) RecordPrebidCacheRequestTime(success bool, length
 	}
 }
 
+// RecordPrebidCacheAsyncWrite across all engines
+func (me *MultiMetricsEngine) RecordPrebidCacheAsyncWrite(result metrics.CacheWriteResult, inc int) {
+	for _, thisME := range *me {
+		thisME.RecordPrebidCacheAsyncWrite(result, inc)
+	}
+}
+
+// RecordGeoLocationLookupTime across all engines
+func (me *MultiMetricsEngine) RecordGeoLocationLookupTime(success bool, length time.Duration) {
+	for _, thisME := range *me {
+		thisME.RecordGeoLocationLookupTime(success, length)
+	}
+}
+
 // RecordRequestQueueTime across all engines
 func (me *MultiMetricsEngine) RecordRequestQueueTime(success bool, requestType metrics.RequestType, length time.Duration) {
 	for _, thisME := range *me {
@@ -258,6 +291,76 @@ func (me *MultiMetricsEngine) RecordAdapterGDPRRequestBlocked(adapter openrtb_ex
 	}
 }
 
+// RecordAdapterRetry across all engines
+func (me *MultiMetricsEngine) RecordAdapterRetry(adapter openrtb_ext.BidderName) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterRetry(adapter)
+	}
+}
+
+// RecordAdapterRetryRecovered across all engines
+func (me *MultiMetricsEngine) RecordAdapterRetryRecovered(adapter openrtb_ext.BidderName) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterRetryRecovered(adapter)
+	}
+}
+
+// RecordTCFPurposeBlocked across all engines
+func (me *MultiMetricsEngine) RecordTCFPurposeBlocked(purpose metrics.TCFPurposeValue) {
+	for _, thisME := range *me {
+		thisME.RecordTCFPurposeBlocked(purpose)
+	}
+}
+
+// RecordPrivacyScrub across all engines
+func (me *MultiMetricsEngine) RecordPrivacyScrub(profile metrics.PrivacyScrubProfile) {
+	for _, thisME := range *me {
+		thisME.RecordPrivacyScrub(profile)
+	}
+}
+
+// RecordAdapterEidsStripped across all engines
+func (me *MultiMetricsEngine) RecordAdapterEidsStripped(adapter openrtb_ext.BidderName, count int) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterEidsStripped(adapter, count)
+	}
+}
+
+// RecordFloorsRejectedBid across all engines
+func (me *MultiMetricsEngine) RecordFloorsRejectedBid(adapter openrtb_ext.BidderName, reason metrics.FloorsRejectReason) {
+	for _, thisME := range *me {
+		thisME.RecordFloorsRejectedBid(adapter, reason)
+	}
+}
+
+// RecordBidValidationFailure across all engines
+func (me *MultiMetricsEngine) RecordBidValidationFailure(adapter openrtb_ext.BidderName, rule metrics.BidValidationRule, enforced bool) {
+	for _, thisME := range *me {
+		thisME.RecordBidValidationFailure(adapter, rule, enforced)
+	}
+}
+
+// RecordAdapterMediaTypeFiltered across all engines
+func (me *MultiMetricsEngine) RecordAdapterMediaTypeFiltered(adapter openrtb_ext.BidderName, mediaType metrics.ImpMediaType) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterMediaTypeFiltered(adapter, mediaType)
+	}
+}
+
+// RecordAdapterGzipBytesSaved across all engines
+func (me *MultiMetricsEngine) RecordAdapterGzipBytesSaved(adapter openrtb_ext.BidderName, compression metrics.AdapterCompression, bytes int) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterGzipBytesSaved(adapter, compression, bytes)
+	}
+}
+
+// RecordAdapterAliasRequest across all engines
+func (me *MultiMetricsEngine) RecordAdapterAliasRequest(adapter openrtb_ext.BidderName, alias string, adapterBid metrics.AdapterBid) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterAliasRequest(adapter, alias, adapterBid)
+	}
+}
+
 // DummyMetricsEngine is a Noop metrics engine in case no metrics are configured. (may also be useful for tests)
 type DummyMetricsEngine struct{}
 
@@ -353,10 +456,22 @@ func (me *DummyMetricsEngine) RecordStoredImpCacheResult(cacheResult metrics.Cac
 func (me *DummyMetricsEngine) RecordAccountCacheResult(cacheResult metrics.CacheResult, inc int) {
 }
 
+// RecordSharedCacheResult as a noop
+func (me *DummyMetricsEngine) RecordSharedCacheResult(dataType metrics.SharedCacheDataType, cacheResult metrics.CacheResult, inc int) {
+}
+
 // RecordPrebidCacheRequestTime as a noop
 func (me *DummyMetricsEngine) RecordPrebidCacheRequestTime(success bool, length time.Duration) {
 }
 
+// RecordPrebidCacheAsyncWrite as a noop
+func (me *DummyMetricsEngine) RecordPrebidCacheAsyncWrite(result metrics.CacheWriteResult, inc int) {
+}
+
+// RecordGeoLocationLookupTime as a noop
+func (me *DummyMetricsEngine) RecordGeoLocationLookupTime(success bool, length time.Duration) {
+}
+
 // RecordRequestQueueTime as a noop
 func (me *DummyMetricsEngine) RecordRequestQueueTime(success bool, requestType metrics.RequestType, length time.Duration) {
 }
@@ -372,3 +487,43 @@ func (me *DummyMetricsEngine) RecordRequestPrivacy(privacy metrics.PrivacyLabels
 // RecordAdapterGDPRRequestBlocked as a noop
 func (me *DummyMetricsEngine) RecordAdapterGDPRRequestBlocked(adapter openrtb_ext.BidderName) {
 }
+
+// RecordAdapterRetry as a noop
+func (me *DummyMetricsEngine) RecordAdapterRetry(adapter openrtb_ext.BidderName) {
+}
+
+// RecordAdapterRetryRecovered as a noop
+func (me *DummyMetricsEngine) RecordAdapterRetryRecovered(adapter openrtb_ext.BidderName) {
+}
+
+// RecordTCFPurposeBlocked as a noop
+func (me *DummyMetricsEngine) RecordTCFPurposeBlocked(purpose metrics.TCFPurposeValue) {
+}
+
+// RecordPrivacyScrub as a noop
+func (me *DummyMetricsEngine) RecordPrivacyScrub(profile metrics.PrivacyScrubProfile) {
+}
+
+// RecordAdapterEidsStripped as a noop
+func (me *DummyMetricsEngine) RecordAdapterEidsStripped(adapter openrtb_ext.BidderName, count int) {
+}
+
+// RecordFloorsRejectedBid as a noop
+func (me *DummyMetricsEngine) RecordFloorsRejectedBid(adapter openrtb_ext.BidderName, reason metrics.FloorsRejectReason) {
+}
+
+// RecordBidValidationFailure as a noop
+func (me *DummyMetricsEngine) RecordBidValidationFailure(adapter openrtb_ext.BidderName, rule metrics.BidValidationRule, enforced bool) {
+}
+
+// RecordAdapterMediaTypeFiltered as a noop
+func (me *DummyMetricsEngine) RecordAdapterMediaTypeFiltered(adapter openrtb_ext.BidderName, mediaType metrics.ImpMediaType) {
+}
+
+// RecordAdapterGzipBytesSaved as a noop
+func (me *DummyMetricsEngine) RecordAdapterGzipBytesSaved(adapter openrtb_ext.BidderName, compression metrics.AdapterCompression, bytes int) {
+}
+
+// RecordAdapterAliasRequest as a noop
+func (me *DummyMetricsEngine) RecordAdapterAliasRequest(adapter openrtb_ext.BidderName, alias string, adapterBid metrics.AdapterBid) {
+}
@@ -35,6 +35,23 @@ func TestGoMetricsEngine(t *testing.T) {
 	}
 }
 
+func TestStatsDMetricsEngine(t *testing.T) {
+	cfg := mainConfig.Configuration{}
+	cfg.Metrics.Influxdb.Host = "localhost"
+	cfg.Metrics.StatsD = mainConfig.StatsDMetrics{Address: "127.0.0.1:8125", FlushIntervalMs: 1000, BufferSize: 10, MaxPacketSize: 1432}
+	adapterList := make([]openrtb_ext.BidderName, 0, 2)
+	testEngine := NewMetricsEngine(&cfg, adapterList, nil)
+	defer testEngine.StatsDMetrics.Client.Close()
+
+	engineList, ok := testEngine.MetricsEngine.(*MultiMetricsEngine)
+	if !ok {
+		t.Fatal("Expected a MultiMetricsEngine with StatsD alongside Influx, but didn't get it")
+	}
+	if len(*engineList) != 2 || (*engineList)[1] != testEngine.StatsDMetrics {
+		t.Errorf("Expected the StatsD metrics in the MultiMetricsEngine, got %v", *engineList)
+	}
+}
+
 // Test the multiengine
 func TestMultiMetricsEngine(t *testing.T) {
 	cfg := mainConfig.Configuration{}
@@ -120,6 +137,8 @@ func TestMultiMetricsEngine(t *testing.T) {
 	metricsEngine.RecordStoredReqCacheResult(metrics.CacheHit, 4)
 	metricsEngine.RecordStoredImpCacheResult(metrics.CacheHit, 5)
 	metricsEngine.RecordAccountCacheResult(metrics.CacheHit, 6)
+	metricsEngine.RecordSharedCacheResult(metrics.SharedCacheImp, metrics.CacheMiss, 7)
+	metricsEngine.RecordSharedCacheResult(metrics.SharedCacheImp, metrics.CacheHit, 8)
 
 	metricsEngine.RecordAdapterGDPRRequestBlocked(openrtb_ext.BidderAppnexus)
 
@@ -166,6 +185,9 @@ func TestMultiMetricsEngine(t *testing.T) {
 	VerifyMetrics(t, "StoredReqCache.Hit", goEngine.StoredReqCacheMeter[metrics.CacheHit].Count(), 4)
 	VerifyMetrics(t, "StoredImpCache.Hit", goEngine.StoredImpCacheMeter[metrics.CacheHit].Count(), 5)
 	VerifyMetrics(t, "AccountCache.Hit", goEngine.AccountCacheMeter[metrics.CacheHit].Count(), 6)
+	VerifyMetrics(t, "SharedCache.Imp.Miss", goEngine.SharedCacheMeter[metrics.SharedCacheImp][metrics.CacheMiss].Count(), 7)
+	VerifyMetrics(t, "SharedCache.Imp.Hit", goEngine.SharedCacheMeter[metrics.SharedCacheImp][metrics.CacheHit].Count(), 8)
+	VerifyMetrics(t, "SharedCache.Request.Hit", goEngine.SharedCacheMeter[metrics.SharedCacheRequest][metrics.CacheHit].Count(), 0)
 
 	VerifyMetrics(t, "AdapterMetrics.AppNexus.GDPRRequestBlocked", goEngine.AdapterMetrics[openrtb_ext.BidderAppnexus].GDPRRequestBlocked.Count(), 1)
 }
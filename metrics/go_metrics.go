@@ -25,11 +25,15 @@ type Metrics struct {
 	RequestsQueueTimer             map[RequestType]map[bool]metrics.Timer
 	PrebidCacheRequestTimerSuccess metrics.Timer
 	PrebidCacheRequestTimerError   metrics.Timer
+	PrebidCacheAsyncWriteMeter     map[CacheWriteResult]metrics.Meter
+	GeoLocationLookupTimerSuccess  metrics.Timer
+	GeoLocationLookupTimerError    metrics.Timer
 	StoredDataFetchTimer           map[StoredDataType]map[StoredDataFetchType]metrics.Timer
 	StoredDataErrorMeter           map[StoredDataType]map[StoredDataError]metrics.Meter
 	StoredReqCacheMeter            map[CacheResult]metrics.Meter
 	StoredImpCacheMeter            map[CacheResult]metrics.Meter
 	AccountCacheMeter              map[CacheResult]metrics.Meter
+	SharedCacheMeter               map[SharedCacheDataType]map[CacheResult]metrics.Meter
 	DNSLookupTimer                 metrics.Timer
 	TLSHandshakeTimer              metrics.Timer
 
@@ -60,6 +64,8 @@ type Metrics struct {
 	PrivacyCOPPARequest      metrics.Meter
 	PrivacyLMTRequest        metrics.Meter
 	PrivacyTCFRequestVersion map[TCFVersionValue]metrics.Meter
+	PrivacyTCFPurposeBlocked map[TCFPurposeValue]metrics.Meter
+	PrivacyScrub             map[PrivacyScrubProfile]metrics.Meter
 
 	AdapterMetrics map[openrtb_ext.BidderName]*AdapterMetrics
 	// Don't export accountMetrics because we need helper functions here to insure its properly populated dynamically
@@ -73,19 +79,27 @@ type Metrics struct {
 
 // AdapterMetrics houses the metrics for a particular adapter
 type AdapterMetrics struct {
-	NoCookieMeter      metrics.Meter
-	ErrorMeters        map[AdapterError]metrics.Meter
-	NoBidMeter         metrics.Meter
-	GotBidsMeter       metrics.Meter
-	RequestTimer       metrics.Timer
-	PriceHistogram     metrics.Histogram
-	BidsReceivedMeter  metrics.Meter
-	PanicMeter         metrics.Meter
-	MarkupMetrics      map[openrtb_ext.BidType]*MarkupDeliveryMetrics
-	ConnCreated        metrics.Counter
-	ConnReused         metrics.Counter
-	ConnWaitTime       metrics.Timer
-	GDPRRequestBlocked metrics.Meter
+	NoCookieMeter       metrics.Meter
+	ErrorMeters         map[AdapterError]metrics.Meter
+	NoBidMeter          metrics.Meter
+	GotBidsMeter        metrics.Meter
+	RequestTimer        metrics.Timer
+	PriceHistogram      metrics.Histogram
+	BidsReceivedMeter   metrics.Meter
+	PanicMeter          metrics.Meter
+	RetryMeter          metrics.Meter
+	RetryRecoveredMeter metrics.Meter
+	EidsStrippedMeter   metrics.Meter
+	FloorsRejected      map[FloorsRejectReason]metrics.Meter
+	ValidationWarned    map[BidValidationRule]metrics.Meter
+	ValidationRejected  map[BidValidationRule]metrics.Meter
+	MediaTypesFiltered  map[ImpMediaType]metrics.Meter
+	GzipBytesSaved      map[AdapterCompression]metrics.Meter
+	MarkupMetrics       map[openrtb_ext.BidType]*MarkupDeliveryMetrics
+	ConnCreated         metrics.Counter
+	ConnReused          metrics.Counter
+	ConnWaitTime        metrics.Timer
+	GDPRRequestBlocked  metrics.Meter
 }
 
 type MarkupDeliveryMetrics struct {
@@ -131,11 +145,15 @@ func NewBlankMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderNa
 		RequestsQueueTimer:             make(map[RequestType]map[bool]metrics.Timer),
 		PrebidCacheRequestTimerSuccess: blankTimer,
 		PrebidCacheRequestTimerError:   blankTimer,
+		PrebidCacheAsyncWriteMeter:     make(map[CacheWriteResult]metrics.Meter),
+		GeoLocationLookupTimerSuccess:  blankTimer,
+		GeoLocationLookupTimerError:    blankTimer,
 		StoredDataFetchTimer:           make(map[StoredDataType]map[StoredDataFetchType]metrics.Timer),
 		StoredDataErrorMeter:           make(map[StoredDataType]map[StoredDataError]metrics.Meter),
 		StoredReqCacheMeter:            make(map[CacheResult]metrics.Meter),
 		StoredImpCacheMeter:            make(map[CacheResult]metrics.Meter),
 		AccountCacheMeter:              make(map[CacheResult]metrics.Meter),
+		SharedCacheMeter:               make(map[SharedCacheDataType]map[CacheResult]metrics.Meter),
 		AmpNoCookieMeter:               blankMeter,
 		CookieSyncMeter:                blankMeter,
 		CookieSyncStatusMeter:          make(map[CookieSyncStatus]metrics.Meter),
@@ -157,6 +175,8 @@ func NewBlankMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderNa
 		PrivacyCOPPARequest:      blankMeter,
 		PrivacyLMTRequest:        blankMeter,
 		PrivacyTCFRequestVersion: make(map[TCFVersionValue]metrics.Meter, len(TCFVersions())),
+		PrivacyTCFPurposeBlocked: make(map[TCFPurposeValue]metrics.Meter, len(TCFPurposes())),
+		PrivacyScrub:             make(map[PrivacyScrubProfile]metrics.Meter, len(PrivacyScrubProfiles())),
 
 		AdapterMetrics:  make(map[openrtb_ext.BidderName]*AdapterMetrics, len(exchanges)),
 		accountMetrics:  make(map[string]*accountMetrics),
@@ -182,10 +202,29 @@ func NewBlankMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderNa
 		newMetrics.AccountCacheMeter[c] = blankMeter
 	}
 
+	for _, dt := range SharedCacheDataTypes() {
+		newMetrics.SharedCacheMeter[dt] = make(map[CacheResult]metrics.Meter)
+		for _, c := range CacheResults() {
+			newMetrics.SharedCacheMeter[dt][c] = blankMeter
+		}
+	}
+
+	for _, r := range CacheWriteResults() {
+		newMetrics.PrebidCacheAsyncWriteMeter[r] = blankMeter
+	}
+
 	for _, v := range TCFVersions() {
 		newMetrics.PrivacyTCFRequestVersion[v] = blankMeter
 	}
 
+	for _, p := range TCFPurposes() {
+		newMetrics.PrivacyTCFPurposeBlocked[p] = blankMeter
+	}
+
+	for _, p := range PrivacyScrubProfiles() {
+		newMetrics.PrivacyScrub[p] = blankMeter
+	}
+
 	for _, dt := range StoredDataTypes() {
 		newMetrics.StoredDataFetchTimer[dt] = make(map[StoredDataFetchType]metrics.Timer)
 		newMetrics.StoredDataErrorMeter[dt] = make(map[StoredDataError]metrics.Meter)
@@ -230,6 +269,11 @@ func NewMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderName, d
 	newMetrics.TLSHandshakeTimer = metrics.GetOrRegisterTimer("tls_handshake_time", registry)
 	newMetrics.PrebidCacheRequestTimerSuccess = metrics.GetOrRegisterTimer("prebid_cache_request_time.ok", registry)
 	newMetrics.PrebidCacheRequestTimerError = metrics.GetOrRegisterTimer("prebid_cache_request_time.err", registry)
+	for _, r := range CacheWriteResults() {
+		newMetrics.PrebidCacheAsyncWriteMeter[r] = metrics.GetOrRegisterMeter(fmt.Sprintf("prebid_cache_async_writes.%s", string(r)), registry)
+	}
+	newMetrics.GeoLocationLookupTimerSuccess = metrics.GetOrRegisterTimer("geolocation_lookup_time.ok", registry)
+	newMetrics.GeoLocationLookupTimerError = metrics.GetOrRegisterTimer("geolocation_lookup_time.err", registry)
 
 	for _, dt := range StoredDataTypes() {
 		for _, ft := range StoredDataFetchTypes() {
@@ -280,6 +324,9 @@ func NewMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderName, d
 		newMetrics.StoredReqCacheMeter[cacheRes] = metrics.GetOrRegisterMeter(fmt.Sprintf("stored_request_cache_%s", string(cacheRes)), registry)
 		newMetrics.StoredImpCacheMeter[cacheRes] = metrics.GetOrRegisterMeter(fmt.Sprintf("stored_imp_cache_%s", string(cacheRes)), registry)
 		newMetrics.AccountCacheMeter[cacheRes] = metrics.GetOrRegisterMeter(fmt.Sprintf("account_cache_%s", string(cacheRes)), registry)
+		for _, dataType := range SharedCacheDataTypes() {
+			newMetrics.SharedCacheMeter[dataType][cacheRes] = metrics.GetOrRegisterMeter(fmt.Sprintf("shared_cache.%s.%s", string(dataType), string(cacheRes)), registry)
+		}
 	}
 
 	newMetrics.RequestsQueueTimer["video"][true] = metrics.GetOrRegisterTimer("queued_requests.video.accepted", registry)
@@ -295,6 +342,12 @@ func NewMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderName, d
 	for _, version := range TCFVersions() {
 		newMetrics.PrivacyTCFRequestVersion[version] = metrics.GetOrRegisterMeter(fmt.Sprintf("privacy.request.tcf.%s", string(version)), registry)
 	}
+	for _, purpose := range TCFPurposes() {
+		newMetrics.PrivacyTCFPurposeBlocked[purpose] = metrics.GetOrRegisterMeter(fmt.Sprintf("privacy.request.tcf.blocked.%s", string(purpose)), registry)
+	}
+	for _, profile := range PrivacyScrubProfiles() {
+		newMetrics.PrivacyScrub[profile] = metrics.GetOrRegisterMeter(fmt.Sprintf("privacy.request.scrub.%s", string(profile)), registry)
+	}
 
 	return newMetrics
 }
@@ -303,15 +356,23 @@ func NewMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderName, d
 func makeBlankAdapterMetrics(disabledMetrics config.DisabledMetrics) *AdapterMetrics {
 	blankMeter := &metrics.NilMeter{}
 	newAdapter := &AdapterMetrics{
-		NoCookieMeter:     blankMeter,
-		ErrorMeters:       make(map[AdapterError]metrics.Meter),
-		NoBidMeter:        blankMeter,
-		GotBidsMeter:      blankMeter,
-		RequestTimer:      &metrics.NilTimer{},
-		PriceHistogram:    &metrics.NilHistogram{},
-		BidsReceivedMeter: blankMeter,
-		PanicMeter:        blankMeter,
-		MarkupMetrics:     makeBlankBidMarkupMetrics(),
+		NoCookieMeter:       blankMeter,
+		ErrorMeters:         make(map[AdapterError]metrics.Meter),
+		NoBidMeter:          blankMeter,
+		GotBidsMeter:        blankMeter,
+		RequestTimer:        &metrics.NilTimer{},
+		PriceHistogram:      &metrics.NilHistogram{},
+		BidsReceivedMeter:   blankMeter,
+		PanicMeter:          blankMeter,
+		RetryMeter:          blankMeter,
+		RetryRecoveredMeter: blankMeter,
+		EidsStrippedMeter:   blankMeter,
+		FloorsRejected:      make(map[FloorsRejectReason]metrics.Meter),
+		ValidationWarned:    make(map[BidValidationRule]metrics.Meter),
+		ValidationRejected:  make(map[BidValidationRule]metrics.Meter),
+		MediaTypesFiltered:  make(map[ImpMediaType]metrics.Meter),
+		GzipBytesSaved:      make(map[AdapterCompression]metrics.Meter),
+		MarkupMetrics:       makeBlankBidMarkupMetrics(),
 	}
 	if !disabledMetrics.AdapterConnectionMetrics {
 		newAdapter.ConnCreated = metrics.NilCounter{}
@@ -324,6 +385,19 @@ func makeBlankAdapterMetrics(disabledMetrics config.DisabledMetrics) *AdapterMet
 	for _, err := range AdapterErrors() {
 		newAdapter.ErrorMeters[err] = blankMeter
 	}
+	for _, reason := range FloorsRejectReasons() {
+		newAdapter.FloorsRejected[reason] = blankMeter
+	}
+	for _, rule := range BidValidationRules() {
+		newAdapter.ValidationWarned[rule] = blankMeter
+		newAdapter.ValidationRejected[rule] = blankMeter
+	}
+	for _, mediaType := range ImpTypes() {
+		newAdapter.MediaTypesFiltered[mediaType] = blankMeter
+	}
+	for _, compression := range AdapterCompressions() {
+		newAdapter.GzipBytesSaved[compression] = blankMeter
+	}
 	return newAdapter
 }
 
@@ -365,6 +439,24 @@ func registerAdapterMetrics(registry metrics.Registry, adapterOrAccount string,
 		am.BidsReceivedMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.bids_received", adapterOrAccount, exchange), registry)
 	}
 	am.PanicMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.requests.panic", adapterOrAccount, exchange), registry)
+	am.RetryMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.requests.retry", adapterOrAccount, exchange), registry)
+	am.RetryRecoveredMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.requests.retry_recovered", adapterOrAccount, exchange), registry)
+	am.EidsStrippedMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.eids_stripped", adapterOrAccount, exchange), registry)
+	for reason := range am.FloorsRejected {
+		am.FloorsRejected[reason] = metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.floors_rejected.%s", adapterOrAccount, exchange, reason), registry)
+	}
+	for rule := range am.ValidationWarned {
+		am.ValidationWarned[rule] = metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.bid_validation.%s.warned", adapterOrAccount, exchange, rule), registry)
+	}
+	for rule := range am.ValidationRejected {
+		am.ValidationRejected[rule] = metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.bid_validation.%s.rejected", adapterOrAccount, exchange, rule), registry)
+	}
+	for mediaType := range am.MediaTypesFiltered {
+		am.MediaTypesFiltered[mediaType] = metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.media_type_filtered.%s", adapterOrAccount, exchange, mediaType), registry)
+	}
+	for compression := range am.GzipBytesSaved {
+		am.GzipBytesSaved[compression] = metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.gzip.%s.bytes_saved", adapterOrAccount, exchange, compression), registry)
+	}
 	am.GDPRRequestBlocked = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.gdpr_request_blocked", adapterOrAccount, exchange), registry)
 }
 
@@ -674,6 +766,12 @@ func (me *Metrics) RecordAccountCacheResult(cacheResult CacheResult, inc int) {
 	me.AccountCacheMeter[cacheResult].Mark(int64(inc))
 }
 
+// RecordSharedCacheResult implements a part of the MetricsEngine interface. Records the
+// hits and misses of the shared cache by the type of the stored data looked up.
+func (me *Metrics) RecordSharedCacheResult(dataType SharedCacheDataType, cacheResult CacheResult, inc int) {
+	me.SharedCacheMeter[dataType][cacheResult].Mark(int64(inc))
+}
+
 // RecordPrebidCacheRequestTime implements a part of the MetricsEngine interface. Records the
 // amount of time taken to store the auction result in Prebid Cache.
 func (me *Metrics) RecordPrebidCacheRequestTime(success bool, length time.Duration) {
@@ -684,6 +782,22 @@ func (me *Metrics) RecordPrebidCacheRequestTime(success bool, length time.Durati
 	}
 }
 
+// RecordPrebidCacheAsyncWrite implements a part of the MetricsEngine interface. Records the values written to
+// Prebid Cache in the background by their result.
+func (me *Metrics) RecordPrebidCacheAsyncWrite(result CacheWriteResult, inc int) {
+	me.PrebidCacheAsyncWriteMeter[result].Mark(int64(inc))
+}
+
+// RecordGeoLocationLookupTime implements a part of the MetricsEngine interface. Records the amount of time taken to
+// locate a device from its IP address.
+func (me *Metrics) RecordGeoLocationLookupTime(success bool, length time.Duration) {
+	if success {
+		me.GeoLocationLookupTimerSuccess.Update(length)
+	} else {
+		me.GeoLocationLookupTimerError.Update(length)
+	}
+}
+
 func (me *Metrics) RecordRequestQueueTime(success bool, requestType RequestType, length time.Duration) {
 	if requestType == ReqTypeVideo { //remove this check when other request types are supported
 		me.RequestsQueueTimer[requestType][success].Update(length)
@@ -737,3 +851,121 @@ func (me *Metrics) RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.Bidde
 
 	am.GDPRRequestBlocked.Mark(1)
 }
+
+// RecordTCFPurposeBlocked implements a part of the MetricsEngine interface
+func (me *Metrics) RecordTCFPurposeBlocked(purpose TCFPurposeValue) {
+	if metric, ok := me.PrivacyTCFPurposeBlocked[purpose]; ok {
+		metric.Mark(1)
+	}
+}
+
+// RecordPrivacyScrub implements a part of the MetricsEngine interface
+func (me *Metrics) RecordPrivacyScrub(profile PrivacyScrubProfile) {
+	if metric, ok := me.PrivacyScrub[profile]; ok {
+		metric.Mark(1)
+	}
+}
+
+// RecordAdapterRetry implements a part of the MetricsEngine interface
+func (me *Metrics) RecordAdapterRetry(adapterName openrtb_ext.BidderName) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to log adapter retry metric for %s: adapter not found", string(adapterName))
+		return
+	}
+
+	am.RetryMeter.Mark(1)
+}
+
+// RecordAdapterRetryRecovered implements a part of the MetricsEngine interface
+func (me *Metrics) RecordAdapterRetryRecovered(adapterName openrtb_ext.BidderName) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to log adapter retry recovered metric for %s: adapter not found", string(adapterName))
+		return
+	}
+
+	am.RetryRecoveredMeter.Mark(1)
+}
+
+// RecordAdapterEidsStripped implements a part of the MetricsEngine interface
+func (me *Metrics) RecordAdapterEidsStripped(adapterName openrtb_ext.BidderName, count int) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to log adapter eids stripped metric for %s: adapter not found", string(adapterName))
+		return
+	}
+
+	am.EidsStrippedMeter.Mark(int64(count))
+}
+
+// RecordFloorsRejectedBid implements a part of the MetricsEngine interface
+func (me *Metrics) RecordFloorsRejectedBid(adapterName openrtb_ext.BidderName, reason FloorsRejectReason) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to log floors rejected bid metric for %s: adapter not found", string(adapterName))
+		return
+	}
+
+	if meter, ok := am.FloorsRejected[reason]; ok {
+		meter.Mark(1)
+	}
+}
+
+// RecordBidValidationFailure implements a part of the MetricsEngine interface
+func (me *Metrics) RecordBidValidationFailure(adapterName openrtb_ext.BidderName, rule BidValidationRule, enforced bool) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to log bid validation metric for %s: adapter not found", string(adapterName))
+		return
+	}
+
+	meters := am.ValidationWarned
+	if enforced {
+		meters = am.ValidationRejected
+	}
+	if meter, ok := meters[rule]; ok {
+		meter.Mark(1)
+	}
+}
+
+// RecordAdapterMediaTypeFiltered implements a part of the MetricsEngine interface
+func (me *Metrics) RecordAdapterMediaTypeFiltered(adapterName openrtb_ext.BidderName, mediaType ImpMediaType) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to log media type filtered metric for %s: adapter not found", string(adapterName))
+		return
+	}
+
+	if meter, ok := am.MediaTypesFiltered[mediaType]; ok {
+		meter.Mark(1)
+	}
+}
+
+// RecordAdapterGzipBytesSaved implements a part of the MetricsEngine interface
+func (me *Metrics) RecordAdapterGzipBytesSaved(adapterName openrtb_ext.BidderName, compression AdapterCompression, bytes int) {
+	am, ok := me.AdapterMetrics[adapterName]
+	if !ok {
+		glog.Errorf("Trying to log gzip bytes saved metric for %s: adapter not found", string(adapterName))
+		return
+	}
+
+	if meter, ok := am.GzipBytesSaved[compression]; ok {
+		meter.Mark(int64(bytes))
+	}
+}
+
+// RecordAdapterAliasRequest implements a part of the MetricsEngine interface. Aliases are set by the requests, so
+// their meters are registered as they are first seen, and the hosts opt into them with
+// metrics.disabled_metrics.adapter_alias_details.
+func (me *Metrics) RecordAdapterAliasRequest(adapterName openrtb_ext.BidderName, alias string, adapterBid AdapterBid) {
+	if me.MetricsDisabled.AdapterAliasDetails {
+		return
+	}
+	if _, ok := me.AdapterMetrics[adapterName]; !ok {
+		glog.Errorf("Trying to log alias request metric for %s: adapter not found", string(adapterName))
+		return
+	}
+
+	metrics.GetOrRegisterMeter(fmt.Sprintf("adapter.%s.alias.%s.requests.%s", adapterName, alias, adapterBid), me.MetricsRegistry).Mark(1)
+}
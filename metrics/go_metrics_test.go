@@ -32,9 +32,13 @@ func TestNewMetrics(t *testing.T) {
 	ensureContains(t, registry, "setuid_requests.opt_out", m.SetUidStatusMeter[SetUidOptOut])
 	ensureContains(t, registry, "setuid_requests.gdpr_blocked_host_cookie", m.SetUidStatusMeter[SetUidGDPRHostCookieBlocked])
 	ensureContains(t, registry, "setuid_requests.syncer_unknown", m.SetUidStatusMeter[SetUidSyncerUnknown])
+	ensureContains(t, registry, "setuid_requests.account_blocked", m.SetUidStatusMeter[SetUidAccountBlocked])
 
 	ensureContains(t, registry, "prebid_cache_request_time.ok", m.PrebidCacheRequestTimerSuccess)
 	ensureContains(t, registry, "prebid_cache_request_time.err", m.PrebidCacheRequestTimerError)
+	ensureContains(t, registry, "prebid_cache_async_writes.ok", m.PrebidCacheAsyncWriteMeter[CacheWriteOK])
+	ensureContains(t, registry, "prebid_cache_async_writes.failed", m.PrebidCacheAsyncWriteMeter[CacheWriteFailed])
+	ensureContains(t, registry, "prebid_cache_async_writes.dropped", m.PrebidCacheAsyncWriteMeter[CacheWriteDropped])
 
 	ensureContains(t, registry, "requests.ok.legacy", m.RequestStatuses[ReqTypeLegacy][RequestStatusOK])
 	ensureContains(t, registry, "requests.badinput.legacy", m.RequestStatuses[ReqTypeLegacy][RequestStatusBadInput])
@@ -56,6 +60,10 @@ func TestNewMetrics(t *testing.T) {
 	ensureContains(t, registry, "requests.badinput.video", m.RequestStatuses[ReqTypeVideo][RequestStatusBadInput])
 	ensureContains(t, registry, "requests.err.video", m.RequestStatuses[ReqTypeVideo][RequestStatusErr])
 	ensureContains(t, registry, "requests.networkerr.video", m.RequestStatuses[ReqTypeVideo][RequestStatusNetworkErr])
+	ensureContains(t, registry, "requests.ok.openrtb2-dooh", m.RequestStatuses[ReqTypeORTB2DOOH][RequestStatusOK])
+	ensureContains(t, registry, "requests.badinput.openrtb2-dooh", m.RequestStatuses[ReqTypeORTB2DOOH][RequestStatusBadInput])
+	ensureContains(t, registry, "requests.err.openrtb2-dooh", m.RequestStatuses[ReqTypeORTB2DOOH][RequestStatusErr])
+	ensureContains(t, registry, "requests.networkerr.openrtb2-dooh", m.RequestStatuses[ReqTypeORTB2DOOH][RequestStatusNetworkErr])
 
 	ensureContains(t, registry, "queued_requests.video.rejected", m.RequestsQueueTimer[ReqTypeVideo][false])
 	ensureContains(t, registry, "queued_requests.video.accepted", m.RequestsQueueTimer[ReqTypeVideo][true])
@@ -373,6 +381,30 @@ func TestRecordPrebidCacheRequestTimeWithNotSuccess(t *testing.T) {
 	assert.Equal(t, m.PrebidCacheRequestTimerError.Count(), int64(1))
 }
 
+func TestRecordPrebidCacheAsyncWrite(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil)
+
+	m.RecordPrebidCacheAsyncWrite(CacheWriteOK, 3)
+	m.RecordPrebidCacheAsyncWrite(CacheWriteDropped, 1)
+
+	assert.Equal(t, int64(3), m.PrebidCacheAsyncWriteMeter[CacheWriteOK].Count())
+	assert.Equal(t, int64(0), m.PrebidCacheAsyncWriteMeter[CacheWriteFailed].Count())
+	assert.Equal(t, int64(1), m.PrebidCacheAsyncWriteMeter[CacheWriteDropped].Count())
+}
+
+func TestRecordGeoLocationLookupTime(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil)
+
+	m.RecordGeoLocationLookupTime(true, 42)
+	m.RecordGeoLocationLookupTime(true, 42)
+	m.RecordGeoLocationLookupTime(false, 42)
+
+	assert.Equal(t, int64(2), m.GeoLocationLookupTimerSuccess.Count(), "success")
+	assert.Equal(t, int64(1), m.GeoLocationLookupTimerError.Count(), "error")
+}
+
 func TestRecordStoredDataFetchTime(t *testing.T) {
 	tests := []struct {
 		description string
@@ -603,6 +635,31 @@ func TestRecordAdapterGDPRRequestBlocked(t *testing.T) {
 	}
 }
 
+func TestRecordTCFPurposeBlocked(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil)
+
+	m.RecordTCFPurposeBlocked(TCFRequestBlocked)
+	m.RecordTCFPurposeBlocked(TCFRequestBlocked)
+	m.RecordTCFPurposeBlocked(TCFSpecialFeature1)
+	m.RecordTCFPurposeBlocked(TCFPurposeValue("unknown"))
+
+	assert.Equal(t, int64(2), m.PrivacyTCFPurposeBlocked[TCFRequestBlocked].Count(), "request blocked")
+	assert.Equal(t, int64(0), m.PrivacyTCFPurposeBlocked[TCFPurposes2To10].Count(), "purposes 2 to 10")
+	assert.Equal(t, int64(1), m.PrivacyTCFPurposeBlocked[TCFSpecialFeature1].Count(), "special feature 1")
+}
+
+func TestRecordPrivacyScrub(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil)
+
+	m.RecordPrivacyScrub(PrivacyScrubProfileCOPPA)
+	m.RecordPrivacyScrub(PrivacyScrubProfile("unknown"))
+
+	assert.Equal(t, int64(1), m.PrivacyScrub[PrivacyScrubProfileCOPPA].Count(), "coppa")
+	assert.Equal(t, int64(0), m.PrivacyScrub[PrivacyScrubProfileLGPD].Count(), "lgpd")
+}
+
 func TestRecordCookieSync(t *testing.T) {
 	registry := metrics.NewRegistry()
 	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus, openrtb_ext.BidderRubicon}, config.DisabledMetrics{}, nil)
@@ -692,3 +749,234 @@ func VerifyMetrics(t *testing.T, name string, expected int64, actual int64) {
 		t.Errorf("Error in metric %s: expected %d, got %d.", name, expected, actual)
 	}
 }
+
+func TestRecordAdapterRetry(t *testing.T) {
+	var fakeBidder openrtb_ext.BidderName = "fooAdvertising"
+
+	tests := []struct {
+		description   string
+		adapterName   openrtb_ext.BidderName
+		expectedCount int64
+	}{
+		{
+			description:   "Known adapter",
+			adapterName:   openrtb_ext.BidderAppnexus,
+			expectedCount: 1,
+		},
+		{
+			description:   "Unknown adapter",
+			adapterName:   fakeBidder,
+			expectedCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		registry := metrics.NewRegistry()
+		m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil)
+
+		m.RecordAdapterRetry(tt.adapterName)
+
+		assert.Equal(t, tt.expectedCount, m.AdapterMetrics[openrtb_ext.BidderAppnexus].RetryMeter.Count(), tt.description)
+	}
+}
+
+func TestRecordAdapterRetryRecovered(t *testing.T) {
+	var fakeBidder openrtb_ext.BidderName = "fooAdvertising"
+
+	tests := []struct {
+		description   string
+		adapterName   openrtb_ext.BidderName
+		expectedCount int64
+	}{
+		{
+			description:   "Known adapter",
+			adapterName:   openrtb_ext.BidderAppnexus,
+			expectedCount: 1,
+		},
+		{
+			description:   "Unknown adapter",
+			adapterName:   fakeBidder,
+			expectedCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		registry := metrics.NewRegistry()
+		m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil)
+
+		m.RecordAdapterRetryRecovered(tt.adapterName)
+
+		assert.Equal(t, tt.expectedCount, m.AdapterMetrics[openrtb_ext.BidderAppnexus].RetryRecoveredMeter.Count(), tt.description)
+	}
+}
+
+func TestRecordAdapterEidsStripped(t *testing.T) {
+	var fakeBidder openrtb_ext.BidderName = "fooAdvertising"
+
+	tests := []struct {
+		description   string
+		adapterName   openrtb_ext.BidderName
+		expectedCount int64
+	}{
+		{
+			description:   "Known adapter",
+			adapterName:   openrtb_ext.BidderAppnexus,
+			expectedCount: 3,
+		},
+		{
+			description:   "Unknown adapter",
+			adapterName:   fakeBidder,
+			expectedCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		registry := metrics.NewRegistry()
+		m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil)
+
+		m.RecordAdapterEidsStripped(tt.adapterName, 3)
+
+		assert.Equal(t, tt.expectedCount, m.AdapterMetrics[openrtb_ext.BidderAppnexus].EidsStrippedMeter.Count(), tt.description)
+	}
+}
+
+func TestRecordAdapterMediaTypeFiltered(t *testing.T) {
+	var fakeBidder openrtb_ext.BidderName = "fooAdvertising"
+
+	tests := []struct {
+		description   string
+		adapterName   openrtb_ext.BidderName
+		expectedCount int64
+	}{
+		{
+			description:   "Known adapter",
+			adapterName:   openrtb_ext.BidderAppnexus,
+			expectedCount: 1,
+		},
+		{
+			description:   "Unknown adapter",
+			adapterName:   fakeBidder,
+			expectedCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		registry := metrics.NewRegistry()
+		m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil)
+
+		m.RecordAdapterMediaTypeFiltered(tt.adapterName, ImpTypeBanner)
+
+		assert.Equal(t, tt.expectedCount, m.AdapterMetrics[openrtb_ext.BidderAppnexus].MediaTypesFiltered[ImpTypeBanner].Count(), tt.description)
+		assert.Equal(t, int64(0), m.AdapterMetrics[openrtb_ext.BidderAppnexus].MediaTypesFiltered[ImpTypeVideo].Count(), tt.description)
+	}
+}
+
+func TestRecordFloorsRejectedBid(t *testing.T) {
+	var fakeBidder openrtb_ext.BidderName = "fooAdvertising"
+
+	tests := []struct {
+		description   string
+		adapterName   openrtb_ext.BidderName
+		expectedCount int64
+	}{
+		{
+			description:   "Known adapter",
+			adapterName:   openrtb_ext.BidderAppnexus,
+			expectedCount: 1,
+		},
+		{
+			description:   "Unknown adapter",
+			adapterName:   fakeBidder,
+			expectedCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		registry := metrics.NewRegistry()
+		m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil)
+
+		m.RecordFloorsRejectedBid(tt.adapterName, FloorsRejectBelowFloor)
+
+		am := m.AdapterMetrics[openrtb_ext.BidderAppnexus]
+		assert.Equal(t, tt.expectedCount, am.FloorsRejected[FloorsRejectBelowFloor].Count(), tt.description)
+		assert.Equal(t, int64(0), am.FloorsRejected[FloorsRejectNoConversionRate].Count(), tt.description)
+	}
+}
+
+func TestRecordBidValidationFailure(t *testing.T) {
+	var fakeBidder openrtb_ext.BidderName = "fooAdvertising"
+
+	tests := []struct {
+		description      string
+		adapterName      openrtb_ext.BidderName
+		enforced         bool
+		expectedWarned   int64
+		expectedRejected int64
+	}{
+		{
+			description:    "Warned",
+			adapterName:    openrtb_ext.BidderAppnexus,
+			enforced:       false,
+			expectedWarned: 1,
+		},
+		{
+			description:      "Rejected",
+			adapterName:      openrtb_ext.BidderAppnexus,
+			enforced:         true,
+			expectedRejected: 1,
+		},
+		{
+			description: "Unknown adapter",
+			adapterName: fakeBidder,
+			enforced:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		registry := metrics.NewRegistry()
+		m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil)
+
+		m.RecordBidValidationFailure(tt.adapterName, BidValidationSecureMarkup, tt.enforced)
+
+		am := m.AdapterMetrics[openrtb_ext.BidderAppnexus]
+		assert.Equal(t, tt.expectedWarned, am.ValidationWarned[BidValidationSecureMarkup].Count(), tt.description)
+		assert.Equal(t, tt.expectedRejected, am.ValidationRejected[BidValidationSecureMarkup].Count(), tt.description)
+		assert.Equal(t, int64(0), am.ValidationRejected[BidValidationCreativeSize].Count(), tt.description)
+	}
+}
+
+func TestRecordAdapterGzipBytesSaved(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil)
+
+	m.RecordAdapterGzipBytesSaved(openrtb_ext.BidderAppnexus, AdapterCompressionRequest, 300)
+	m.RecordAdapterGzipBytesSaved(openrtb_ext.BidderAppnexus, AdapterCompressionRequest, 200)
+	m.RecordAdapterGzipBytesSaved("fooAdvertising", AdapterCompressionRequest, 100)
+
+	am := m.AdapterMetrics[openrtb_ext.BidderAppnexus]
+	assert.Equal(t, int64(500), am.GzipBytesSaved[AdapterCompressionRequest].Count())
+	assert.Equal(t, int64(0), am.GzipBytesSaved[AdapterCompressionResponse].Count())
+}
+
+func TestRecordAdapterAliasRequest(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{}, nil)
+
+	m.RecordAdapterAliasRequest(openrtb_ext.BidderAppnexus, "apnAlias", AdapterBidPresent)
+	m.RecordAdapterAliasRequest(openrtb_ext.BidderAppnexus, "apnAlias", AdapterBidPresent)
+	m.RecordAdapterAliasRequest(openrtb_ext.BidderAppnexus, "apnAlias", AdapterBidNone)
+	m.RecordAdapterAliasRequest("fooAdvertising", "fooAlias", AdapterBidPresent)
+
+	assert.Equal(t, int64(2), metrics.GetOrRegisterMeter("adapter.appnexus.alias.apnAlias.requests.bid", registry).Count())
+	assert.Equal(t, int64(1), metrics.GetOrRegisterMeter("adapter.appnexus.alias.apnAlias.requests.nobid", registry).Count())
+	assert.Nil(t, registry.Get("adapter.fooAdvertising.alias.fooAlias.requests.bid"))
+}
+
+func TestRecordAdapterAliasRequestDisabled(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{AdapterAliasDetails: true}, nil)
+
+	m.RecordAdapterAliasRequest(openrtb_ext.BidderAppnexus, "apnAlias", AdapterBidPresent)
+
+	assert.Nil(t, registry.Get("adapter.appnexus.alias.apnAlias.requests.bid"))
+}
@@ -126,9 +126,24 @@ type AdapterBid string
 // AdapterError : Errors which may have occurred during the adapter's execution
 type AdapterError string
 
+// FloorsRejectReason : Reason a bid was rejected by floor enforcement
+type FloorsRejectReason string
+
+// BidValidationRule : Validation check a bid failed
+type BidValidationRule string
+
+// AdapterCompression : Body of a bidder call which was gzip compressed
+type AdapterCompression string
+
 // CacheResult : Cache hit/miss
 type CacheResult string
 
+// SharedCacheDataType : Stored data looked up in the cache shared by the instances
+type SharedCacheDataType string
+
+// CacheWriteResult : Result of a value written to Prebid Cache in the background
+type CacheWriteResult string
+
 // PublisherUnknown : Default value for Labels.PubID
 const PublisherUnknown = "unknown"
 
@@ -154,6 +169,8 @@ const (
 	ReqTypeORTB2App RequestType = "openrtb2-app"
 	ReqTypeAMP      RequestType = "amp"
 	ReqTypeVideo    RequestType = "video"
+	// ReqTypeORTB2DOOH is the type of the auction requests of the dooh channel
+	ReqTypeORTB2DOOH RequestType = "openrtb2-dooh"
 )
 
 // The media types described in the "imp" json objects
@@ -171,6 +188,7 @@ func RequestTypes() []RequestType {
 		ReqTypeORTB2App,
 		ReqTypeAMP,
 		ReqTypeVideo,
+		ReqTypeORTB2DOOH,
 	}
 }
 
@@ -251,6 +269,49 @@ func AdapterErrors() []AdapterError {
 	}
 }
 
+// Floor enforcement rejection reasons
+const (
+	FloorsRejectBelowFloor       FloorsRejectReason = "below_floor"
+	FloorsRejectNoConversionRate FloorsRejectReason = "no_conversion_rate"
+)
+
+func FloorsRejectReasons() []FloorsRejectReason {
+	return []FloorsRejectReason{
+		FloorsRejectBelowFloor,
+		FloorsRejectNoConversionRate,
+	}
+}
+
+// Bid validation rules
+const (
+	BidValidationCreativeSize BidValidationRule = "creative_size"
+	BidValidationSecureMarkup BidValidationRule = "secure_markup"
+	BidValidationAdmPresence  BidValidationRule = "adm_presence"
+	BidValidationNativeAssets BidValidationRule = "native_assets"
+)
+
+func BidValidationRules() []BidValidationRule {
+	return []BidValidationRule{
+		BidValidationCreativeSize,
+		BidValidationSecureMarkup,
+		BidValidationAdmPresence,
+		BidValidationNativeAssets,
+	}
+}
+
+// Adapter compressions
+const (
+	AdapterCompressionRequest  AdapterCompression = "request"
+	AdapterCompressionResponse AdapterCompression = "response"
+)
+
+func AdapterCompressions() []AdapterCompression {
+	return []AdapterCompression{
+		AdapterCompressionRequest,
+		AdapterCompressionResponse,
+	}
+}
+
 const (
 	// CacheHit represents a cache hit i.e the key was found in cache
 	CacheHit CacheResult = "hit"
@@ -267,6 +328,38 @@ func CacheResults() []CacheResult {
 	}
 }
 
+// Shared cache data types
+const (
+	SharedCacheRequest SharedCacheDataType = "request"
+	SharedCacheImp     SharedCacheDataType = "imp"
+	SharedCacheAccount SharedCacheDataType = "account"
+)
+
+func SharedCacheDataTypes() []SharedCacheDataType {
+	return []SharedCacheDataType{
+		SharedCacheRequest,
+		SharedCacheImp,
+		SharedCacheAccount,
+	}
+}
+
+// Results of the values written to Prebid Cache in the background
+const (
+	CacheWriteOK CacheWriteResult = "ok"
+	// CacheWriteFailed represents a value which Prebid Cache did not store
+	CacheWriteFailed CacheWriteResult = "failed"
+	// CacheWriteDropped represents a value which was not written because the buffer of the writes was full
+	CacheWriteDropped CacheWriteResult = "dropped"
+)
+
+func CacheWriteResults() []CacheWriteResult {
+	return []CacheWriteResult{
+		CacheWriteOK,
+		CacheWriteFailed,
+		CacheWriteDropped,
+	}
+}
+
 // TCFVersionValue : The possible values for TCF versions
 type TCFVersionValue string
 
@@ -292,6 +385,44 @@ func TCFVersionToValue(version int) TCFVersionValue {
 	return TCFVersionErr
 }
 
+// TCFPurposeValue : The TCF purposes and special features whose enforcement blocked a bidder request or a part of it
+type TCFPurposeValue string
+
+const (
+	// TCFRequestBlocked blocks the bidder requests as a whole. The permissions don't tell which of the purpose 2
+	// legal basis, the vendor or the consent string is missing, so they are counted together.
+	TCFRequestBlocked TCFPurposeValue = "request"
+	// TCFPurposes2To10 blocks the user IDs of the bidder requests, when none of them is allowed
+	TCFPurposes2To10 TCFPurposeValue = "purposes2to10"
+	// TCFSpecialFeature1 blocks the precise geolocation data of the bidder requests
+	TCFSpecialFeature1 TCFPurposeValue = "special_feature1"
+)
+
+// TCFPurposes returns the possible values for the TCF purposes blocking bidder requests
+func TCFPurposes() []TCFPurposeValue {
+	return []TCFPurposeValue{
+		TCFRequestBlocked,
+		TCFPurposes2To10,
+		TCFSpecialFeature1,
+	}
+}
+
+// PrivacyScrubProfile : The scrub profiles of the privacy regulations scrubbing the bidder requests as a whole
+type PrivacyScrubProfile string
+
+const (
+	PrivacyScrubProfileCOPPA PrivacyScrubProfile = "coppa"
+	PrivacyScrubProfileLGPD  PrivacyScrubProfile = "lgpd"
+)
+
+// PrivacyScrubProfiles returns the possible values for the privacy scrub profiles
+func PrivacyScrubProfiles() []PrivacyScrubProfile {
+	return []PrivacyScrubProfile{
+		PrivacyScrubProfileCOPPA,
+		PrivacyScrubProfileLGPD,
+	}
+}
+
 // CookieSyncStatus is a status code resulting from a call to the /cookie_sync endpoint.
 type CookieSyncStatus string
 
@@ -342,6 +473,7 @@ const (
 	SetUidOptOut                SetUidStatus = "opt_out"
 	SetUidGDPRHostCookieBlocked SetUidStatus = "gdpr_blocked_host_cookie"
 	SetUidSyncerUnknown         SetUidStatus = "syncer_unknown"
+	SetUidAccountBlocked        SetUidStatus = "account_blocked"
 )
 
 // SetUidStatuses returns possible setuid statuses.
@@ -352,6 +484,7 @@ func SetUidStatuses() []SetUidStatus {
 		SetUidOptOut,
 		SetUidGDPRHostCookieBlocked,
 		SetUidSyncerUnknown,
+		SetUidAccountBlocked,
 	}
 }
 
@@ -401,11 +534,30 @@ type MetricsEngine interface {
 	RecordStoredReqCacheResult(cacheResult CacheResult, inc int)
 	RecordStoredImpCacheResult(cacheResult CacheResult, inc int)
 	RecordAccountCacheResult(cacheResult CacheResult, inc int)
+	// RecordSharedCacheResult records the hits and misses of the cache shared by the instances, like Redis,
+	// which sits between the in-memory cache and the stored data backends.
+	RecordSharedCacheResult(dataType SharedCacheDataType, cacheResult CacheResult, inc int)
 	RecordStoredDataFetchTime(labels StoredDataLabels, length time.Duration)
 	RecordStoredDataError(labels StoredDataLabels)
 	RecordPrebidCacheRequestTime(success bool, length time.Duration)
+	// RecordPrebidCacheAsyncWrite records the values written to Prebid Cache in the background, after the auctions
+	// responded with their keys
+	RecordPrebidCacheAsyncWrite(result CacheWriteResult, inc int)
+	RecordGeoLocationLookupTime(success bool, length time.Duration)
 	RecordRequestQueueTime(success bool, requestType RequestType, length time.Duration)
 	RecordTimeoutNotice(sucess bool)
 	RecordRequestPrivacy(privacy PrivacyLabels)
 	RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.BidderName)
+	RecordTCFPurposeBlocked(purpose TCFPurposeValue)
+	RecordPrivacyScrub(profile PrivacyScrubProfile)
+	RecordAdapterRetry(adapterName openrtb_ext.BidderName)
+	RecordAdapterRetryRecovered(adapterName openrtb_ext.BidderName)
+	RecordAdapterEidsStripped(adapterName openrtb_ext.BidderName, count int)
+	RecordFloorsRejectedBid(adapterName openrtb_ext.BidderName, reason FloorsRejectReason)
+	RecordBidValidationFailure(adapterName openrtb_ext.BidderName, rule BidValidationRule, enforced bool)
+	// RecordAdapterMediaTypeFiltered records the media types removed from the multi-format imps sent to the adapters
+	// which don't support them
+	RecordAdapterMediaTypeFiltered(adapterName openrtb_ext.BidderName, mediaType ImpMediaType)
+	RecordAdapterGzipBytesSaved(adapterName openrtb_ext.BidderName, compression AdapterCompression, bytes int)
+	RecordAdapterAliasRequest(adapterName openrtb_ext.BidderName, alias string, adapterBid AdapterBid)
 }
@@ -126,11 +126,26 @@ func (me *MetricsEngineMock) RecordAccountCacheResult(cacheResult CacheResult, i
 	me.Called(cacheResult, inc)
 }
 
+// RecordSharedCacheResult mock
+func (me *MetricsEngineMock) RecordSharedCacheResult(dataType SharedCacheDataType, cacheResult CacheResult, inc int) {
+	me.Called(dataType, cacheResult, inc)
+}
+
 // RecordPrebidCacheRequestTime mock
 func (me *MetricsEngineMock) RecordPrebidCacheRequestTime(success bool, length time.Duration) {
 	me.Called(success, length)
 }
 
+// RecordPrebidCacheAsyncWrite mock
+func (me *MetricsEngineMock) RecordPrebidCacheAsyncWrite(result CacheWriteResult, inc int) {
+	me.Called(result, inc)
+}
+
+// RecordGeoLocationLookupTime mock
+func (me *MetricsEngineMock) RecordGeoLocationLookupTime(success bool, length time.Duration) {
+	me.Called(success, length)
+}
+
 // RecordRequestQueueTime mock
 func (me *MetricsEngineMock) RecordRequestQueueTime(success bool, requestType RequestType, length time.Duration) {
 	me.Called(success, requestType, length)
@@ -150,3 +165,53 @@ func (me *MetricsEngineMock) RecordRequestPrivacy(privacy PrivacyLabels) {
 func (me *MetricsEngineMock) RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.BidderName) {
 	me.Called(adapterName)
 }
+
+// RecordTCFPurposeBlocked mock
+func (me *MetricsEngineMock) RecordTCFPurposeBlocked(purpose TCFPurposeValue) {
+	me.Called(purpose)
+}
+
+// RecordPrivacyScrub mock
+func (me *MetricsEngineMock) RecordPrivacyScrub(profile PrivacyScrubProfile) {
+	me.Called(profile)
+}
+
+// RecordAdapterRetry mock
+func (me *MetricsEngineMock) RecordAdapterRetry(adapterName openrtb_ext.BidderName) {
+	me.Called(adapterName)
+}
+
+// RecordAdapterRetryRecovered mock
+func (me *MetricsEngineMock) RecordAdapterRetryRecovered(adapterName openrtb_ext.BidderName) {
+	me.Called(adapterName)
+}
+
+// RecordAdapterEidsStripped mock
+func (me *MetricsEngineMock) RecordAdapterEidsStripped(adapterName openrtb_ext.BidderName, count int) {
+	me.Called(adapterName, count)
+}
+
+// RecordFloorsRejectedBid mock
+func (me *MetricsEngineMock) RecordFloorsRejectedBid(adapterName openrtb_ext.BidderName, reason FloorsRejectReason) {
+	me.Called(adapterName, reason)
+}
+
+// RecordBidValidationFailure mock
+func (me *MetricsEngineMock) RecordBidValidationFailure(adapterName openrtb_ext.BidderName, rule BidValidationRule, enforced bool) {
+	me.Called(adapterName, rule, enforced)
+}
+
+// RecordAdapterMediaTypeFiltered mock
+func (me *MetricsEngineMock) RecordAdapterMediaTypeFiltered(adapterName openrtb_ext.BidderName, mediaType ImpMediaType) {
+	me.Called(adapterName, mediaType)
+}
+
+// RecordAdapterGzipBytesSaved mock
+func (me *MetricsEngineMock) RecordAdapterGzipBytesSaved(adapterName openrtb_ext.BidderName, compression AdapterCompression, bytes int) {
+	me.Called(adapterName, compression, bytes)
+}
+
+// RecordAdapterAliasRequest mock
+func (me *MetricsEngineMock) RecordAdapterAliasRequest(adapterName openrtb_ext.BidderName, alias string, adapterBid AdapterBid) {
+	me.Called(adapterName, alias, adapterBid)
+}
@@ -10,13 +10,18 @@ func preloadLabelValues(m *Metrics, syncerKeys []string) {
 		setUidStatusValues        = setUidStatusesAsString()
 		adapterErrorValues        = adapterErrorsAsString()
 		adapterValues             = adaptersAsString()
+		floorsRejectValues        = floorsRejectReasonsAsString()
+		bidValidationValues       = bidValidationRulesAsString()
+		compressionValues         = adapterCompressionsAsString()
 		bidTypeValues             = []string{markupDeliveryAdm, markupDeliveryNurl}
 		boolValues                = boolValuesAsString()
 		cacheResultValues         = cacheResultsAsString()
+		cacheWriteResultValues    = cacheWriteResultsAsString()
 		connectionErrorValues     = []string{connectionAcceptError, connectionCloseError}
 		cookieValues              = cookieTypesAsString()
 		cookieSyncStatusValues    = cookieSyncStatusesAsString()
 		requestTypeValues         = requestTypesAsString()
+		sharedCacheDataTypeValues = sharedCacheDataTypesAsString()
 		requestStatusValues       = requestStatusesAsString()
 		storedDataFetchTypeValues = storedDataFetchTypesAsString()
 		storedDataErrorValues     = storedDataErrorsAsString()
@@ -48,6 +53,10 @@ func preloadLabelValues(m *Metrics, syncerKeys []string) {
 		successLabel: boolValues,
 	})
 
+	preloadLabelValuesForCounter(m.prebidCacheAsyncWrites, map[string][]string{
+		cacheWriteLabel: cacheWriteResultValues,
+	})
+
 	preloadLabelValuesForCounter(m.requests, map[string][]string{
 		requestTypeLabel:   requestTypeValues,
 		requestStatusLabel: requestStatusValues,
@@ -113,6 +122,11 @@ func preloadLabelValues(m *Metrics, syncerKeys []string) {
 		cacheResultLabel: cacheResultValues,
 	})
 
+	preloadLabelValuesForCounter(m.sharedCacheResult, map[string][]string{
+		dataTypeLabel:    sharedCacheDataTypeValues,
+		cacheResultLabel: cacheResultValues,
+	})
+
 	preloadLabelValuesForCounter(m.adapterBids, map[string][]string{
 		adapterLabel:        adapterValues,
 		markupDeliveryLabel: bidTypeValues,
@@ -127,6 +141,34 @@ func preloadLabelValues(m *Metrics, syncerKeys []string) {
 		adapterLabel: adapterValues,
 	})
 
+	preloadLabelValuesForCounter(m.adapterRetries, map[string][]string{
+		adapterLabel: adapterValues,
+	})
+
+	preloadLabelValuesForCounter(m.adapterRetriesRecovered, map[string][]string{
+		adapterLabel: adapterValues,
+	})
+
+	preloadLabelValuesForCounter(m.adapterEidsStripped, map[string][]string{
+		adapterLabel: adapterValues,
+	})
+
+	preloadLabelValuesForCounter(m.adapterFloorsRejectedBids, map[string][]string{
+		adapterLabel:      adapterValues,
+		floorsRejectLabel: floorsRejectValues,
+	})
+
+	preloadLabelValuesForCounter(m.adapterBidValidations, map[string][]string{
+		adapterLabel:       adapterValues,
+		bidValidationLabel: bidValidationValues,
+		enforcedLabel:      boolValues,
+	})
+
+	preloadLabelValuesForCounter(m.adapterGzipBytesSaved, map[string][]string{
+		adapterLabel:     adapterValues,
+		compressionLabel: compressionValues,
+	})
+
 	preloadLabelValuesForHistogram(m.adapterPrices, map[string][]string{
 		adapterLabel: adapterValues,
 	})
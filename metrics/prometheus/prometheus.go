@@ -2,6 +2,7 @@ package prometheusmetrics
 
 import (
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/prebid/prebid-server/config"
@@ -23,6 +24,8 @@ type Metrics struct {
 	impressions                  *prometheus.CounterVec
 	impressionsLegacy            prometheus.Counter
 	prebidCacheWriteTimer        *prometheus.HistogramVec
+	prebidCacheAsyncWrites       *prometheus.CounterVec
+	geoLocationLookupTimer       *prometheus.HistogramVec
 	requests                     *prometheus.CounterVec
 	requestsTimer                *prometheus.HistogramVec
 	requestsQueueTimer           *prometheus.HistogramVec
@@ -30,6 +33,7 @@ type Metrics struct {
 	storedImpressionsCacheResult *prometheus.CounterVec
 	storedRequestCacheResult     *prometheus.CounterVec
 	accountCacheResult           *prometheus.CounterVec
+	sharedCacheResult            *prometheus.CounterVec
 	storedAccountFetchTimer      *prometheus.HistogramVec
 	storedAccountErrors          *prometheus.CounterVec
 	storedAMPFetchTimer          *prometheus.HistogramVec
@@ -47,11 +51,20 @@ type Metrics struct {
 	privacyCOPPA                 *prometheus.CounterVec
 	privacyLMT                   *prometheus.CounterVec
 	privacyTCF                   *prometheus.CounterVec
+	privacyTCFPurposeBlocked     *prometheus.CounterVec
+	privacyScrub                 *prometheus.CounterVec
 
 	// Adapter Metrics
 	adapterBids                *prometheus.CounterVec
 	adapterErrors              *prometheus.CounterVec
 	adapterPanics              *prometheus.CounterVec
+	adapterRetries             *prometheus.CounterVec
+	adapterRetriesRecovered    *prometheus.CounterVec
+	adapterEidsStripped        *prometheus.CounterVec
+	adapterFloorsRejectedBids  *prometheus.CounterVec
+	adapterBidValidations      *prometheus.CounterVec
+	adapterMediaTypesFiltered  *prometheus.CounterVec
+	adapterGzipBytesSaved      *prometheus.CounterVec
 	adapterPrices              *prometheus.HistogramVec
 	adapterRequests            *prometheus.CounterVec
 	adapterRequestsTimer       *prometheus.HistogramVec
@@ -59,13 +72,16 @@ type Metrics struct {
 	adapterCreatedConnections  *prometheus.CounterVec
 	adapterConnectionWaitTime  *prometheus.HistogramVec
 	adapterGDPRBlockedRequests *prometheus.CounterVec
+	adapterAliasRequests       *prometheus.CounterVec
 
 	// Syncer Metrics
 	syncerRequests *prometheus.CounterVec
 	syncerSets     *prometheus.CounterVec
 
 	// Account Metrics
-	accountRequests *prometheus.CounterVec
+	accountRequests             *prometheus.CounterVec
+	accountAdapterRequestsTimer *prometheus.HistogramVec
+	adapterAccounts             *accountLabels
 
 	metricsDisabled config.DisabledMetrics
 }
@@ -75,8 +91,16 @@ const (
 	actionLabel          = "action"
 	adapterErrorLabel    = "adapter_error"
 	adapterLabel         = "adapter"
+	aliasLabel           = "alias"
+	floorsRejectLabel    = "floors_reject_reason"
+	bidValidationLabel   = "bid_validation_rule"
+	enforcedLabel        = "enforced"
+	mediaTypeLabel       = "media_type"
+	compressionLabel     = "compression"
 	bidTypeLabel         = "bid_type"
 	cacheResultLabel     = "cache_result"
+	cacheWriteLabel      = "cache_write_result"
+	dataTypeLabel        = "data_type"
 	connectionErrorLabel = "connection_error"
 	cookieLabel          = "cookie"
 	hasBidsLabel         = "has_bids"
@@ -87,6 +111,8 @@ const (
 	markupDeliveryLabel  = "delivery"
 	optOutLabel          = "opt_out"
 	privacyBlockedLabel  = "privacy_blocked"
+	profileLabel         = "profile"
+	purposeLabel         = "purpose"
 	requestStatusLabel   = "request_status"
 	requestTypeLabel     = "request_type"
 	statusLabel          = "status"
@@ -125,9 +151,22 @@ const (
 	storedDataErrorLabel     = "stored_data_error"
 )
 
+// accountOther labels the accounts seen once the cap of the accounts labeled is reached
+const accountOther = "other"
+
+// defaultTimeBuckets are the bucket boundaries of the timing histograms, unless the config sets them
+var defaultTimeBuckets = []float64{0.05, 0.1, 0.15, 0.20, 0.25, 0.3, 0.4, 0.5, 0.75, 1}
+
 // NewMetrics initializes a new Prometheus metrics instance with preloaded label values.
 func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMetrics, syncerKeys []string) *Metrics {
-	standardTimeBuckets := []float64{0.05, 0.1, 0.15, 0.20, 0.25, 0.3, 0.4, 0.5, 0.75, 1}
+	standardTimeBuckets := defaultTimeBuckets
+	if len(cfg.TimeBuckets) > 0 {
+		standardTimeBuckets = cfg.TimeBuckets
+	}
+	adapterTimeBuckets := standardTimeBuckets
+	if len(cfg.AdapterTimeBuckets) > 0 {
+		adapterTimeBuckets = cfg.AdapterTimeBuckets
+	}
 	cacheWriteTimeBuckets := []float64{0.001, 0.002, 0.005, 0.01, 0.025, 0.05, 0.1, 0.2, 0.3, 0.4, 0.5, 1}
 	priceBuckets := []float64{250, 500, 750, 1000, 1500, 2000, 2500, 3000, 3500, 4000}
 	queuedRequestTimeBuckets := []float64{0, 1, 5, 30, 60, 120, 180, 240, 300}
@@ -174,6 +213,17 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		[]string{successLabel},
 		cacheWriteTimeBuckets)
 
+	metrics.prebidCacheAsyncWrites = newCounter(cfg, metrics.Registry,
+		"prebidcache_async_writes",
+		"Count of values written to Prebid Cache in the background labeled by result.",
+		[]string{cacheWriteLabel})
+
+	metrics.geoLocationLookupTimer = newHistogramVec(cfg, metrics.Registry,
+		"geolocation_lookup_time_seconds",
+		"Seconds to locate a device from its IP address labeled by success or failure.",
+		[]string{successLabel},
+		cacheWriteTimeBuckets)
+
 	metrics.requests = newCounter(cfg, metrics.Registry,
 		"requests",
 		"Count of total requests to Prebid Server labeled by type and status.",
@@ -205,6 +255,11 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"Count of account cache lookups by hits or miss.",
 		[]string{cacheResultLabel})
 
+	metrics.sharedCacheResult = newCounter(cfg, metrics.Registry,
+		"shared_cache_performance",
+		"Count of shared cache lookups by stored data type and hits or miss.",
+		[]string{dataTypeLabel, cacheResultLabel})
+
 	metrics.storedAccountFetchTimer = newHistogramVec(cfg, metrics.Registry,
 		"stored_account_fetch_time_seconds",
 		"Seconds to fetch stored accounts labeled by fetch type",
@@ -290,6 +345,16 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"Count of TCF versions for requests where GDPR was enforced by source and version.",
 		[]string{versionLabel, sourceLabel})
 
+	metrics.privacyTCFPurposeBlocked = newCounter(cfg, metrics.Registry,
+		"privacy_tcf_purpose_blocked",
+		"Count of bidder requests, or of their user IDs or precise geolocation, blocked due to an unsatisfied TCF purpose or special feature.",
+		[]string{purposeLabel})
+
+	metrics.privacyScrub = newCounter(cfg, metrics.Registry,
+		"privacy_scrub",
+		"Count of total requests to Prebid Server scrubbed by the profile of a privacy regulation.",
+		[]string{profileLabel})
+
 	metrics.privacyLMT = newCounter(cfg, metrics.Registry,
 		"privacy_lmt",
 		"Count of total requests to Prebid Server where the LMT flag was set by source",
@@ -302,6 +367,13 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 			[]string{adapterLabel})
 	}
 
+	if !metrics.metricsDisabled.AdapterAliasDetails {
+		metrics.adapterAliasRequests = newCounter(cfg, metrics.Registry,
+			"adapter_alias_requests",
+			"Count of requests to adapters through a request alias labeled by adapter, alias and whether bids were returned.",
+			[]string{adapterLabel, aliasLabel, hasBidsLabel})
+	}
+
 	metrics.adapterBids = newCounter(cfg, metrics.Registry,
 		"adapter_bids",
 		"Count of bids labeled by adapter and markup delivery type (adm or nurl).",
@@ -317,6 +389,41 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"Count of panics labeled by adapter.",
 		[]string{adapterLabel})
 
+	metrics.adapterRetries = newCounter(cfg, metrics.Registry,
+		"adapter_retries",
+		"Count of retried bidder requests labeled by adapter.",
+		[]string{adapterLabel})
+
+	metrics.adapterRetriesRecovered = newCounter(cfg, metrics.Registry,
+		"adapter_retries_recovered",
+		"Count of retried bidder requests which succeeded on the retry labeled by adapter.",
+		[]string{adapterLabel})
+
+	metrics.adapterEidsStripped = newCounter(cfg, metrics.Registry,
+		"adapter_eids_stripped",
+		"Count of user eids stripped from bidder requests by eidpermissions labeled by adapter.",
+		[]string{adapterLabel})
+
+	metrics.adapterFloorsRejectedBids = newCounter(cfg, metrics.Registry,
+		"adapter_floors_rejected_bids",
+		"Count of bids rejected by floor enforcement labeled by adapter and reason.",
+		[]string{adapterLabel, floorsRejectLabel})
+
+	metrics.adapterBidValidations = newCounter(cfg, metrics.Registry,
+		"adapter_bid_validation_failures",
+		"Count of bids failing a validation labeled by adapter, rule and whether the validation was enforced.",
+		[]string{adapterLabel, bidValidationLabel, enforcedLabel})
+
+	metrics.adapterMediaTypesFiltered = newCounter(cfg, metrics.Registry,
+		"adapter_media_types_filtered",
+		"Count of media types removed from the multi-format imps of adapters not supporting them labeled by adapter and media type.",
+		[]string{adapterLabel, mediaTypeLabel})
+
+	metrics.adapterGzipBytesSaved = newCounter(cfg, metrics.Registry,
+		"adapter_gzip_bytes_saved",
+		"Count of bytes saved by gzip compressing the bodies of adapter calls labeled by adapter and compressed body.",
+		[]string{adapterLabel, compressionLabel})
+
 	metrics.adapterPrices = newHistogramVec(cfg, metrics.Registry,
 		"adapter_prices",
 		"Monetary value of the bids labeled by adapter.",
@@ -350,7 +457,7 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"adapter_request_time_seconds",
 		"Seconds to resolve each successful request labeled by adapter.",
 		[]string{adapterLabel},
-		standardTimeBuckets)
+		adapterTimeBuckets)
 
 	metrics.syncerRequests = newCounter(cfg, metrics.Registry,
 		"syncer_requests",
@@ -367,6 +474,15 @@ func NewMetrics(cfg config.PrometheusMetrics, disabledMetrics config.DisabledMet
 		"Count of total requests to Prebid Server labeled by account.",
 		[]string{accountLabel})
 
+	if !metrics.metricsDisabled.AccountAdapterDetails && cfg.MaxAdapterAccounts > 0 {
+		metrics.accountAdapterRequestsTimer = newHistogramVec(cfg, metrics.Registry,
+			"account_adapter_request_time_seconds",
+			"Seconds to resolve each successful request labeled by account and adapter.",
+			[]string{accountLabel, adapterLabel},
+			adapterTimeBuckets)
+		metrics.adapterAccounts = newAccountLabels(cfg.MaxAdapterAccounts)
+	}
+
 	metrics.requestsQueueTimer = newHistogramVec(cfg, metrics.Registry,
 		"request_queue_time",
 		"Seconds request was waiting in queue",
@@ -612,9 +728,55 @@ func (m *Metrics) RecordAdapterTime(labels metrics.AdapterLabels, length time.Du
 		m.adapterRequestsTimer.With(prometheus.Labels{
 			adapterLabel: string(labels.Adapter),
 		}).Observe(length.Seconds())
+
+		if m.accountAdapterRequestsTimer != nil && labels.PubID != metrics.PublisherUnknown {
+			m.accountAdapterRequestsTimer.With(prometheus.Labels{
+				accountLabel: m.adapterAccounts.label(labels.PubID),
+				adapterLabel: string(labels.Adapter),
+			}).Observe(length.Seconds())
+		}
 	}
 }
 
+// accountLabels caps the number of accounts a metric is labeled with. The first accounts seen up to the cap keep
+// their own label, and the others share the "other" label.
+type accountLabels struct {
+	mutex    sync.RWMutex
+	accounts map[string]struct{}
+	max      int
+}
+
+func newAccountLabels(max int) *accountLabels {
+	return &accountLabels{
+		accounts: make(map[string]struct{}, max),
+		max:      max,
+	}
+}
+
+func (l *accountLabels) label(account string) string {
+	l.mutex.RLock()
+	_, labeled := l.accounts[account]
+	full := len(l.accounts) >= l.max
+	l.mutex.RUnlock()
+	if labeled {
+		return account
+	}
+	if full {
+		return accountOther
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if _, labeled := l.accounts[account]; labeled {
+		return account
+	}
+	if len(l.accounts) >= l.max {
+		return accountOther
+	}
+	l.accounts[account] = struct{}{}
+	return account
+}
+
 func (m *Metrics) RecordCookieSync(status metrics.CookieSyncStatus) {
 	m.cookieSync.With(prometheus.Labels{
 		statusLabel: string(status),
@@ -659,12 +821,31 @@ func (m *Metrics) RecordAccountCacheResult(cacheResult metrics.CacheResult, inc
 	}).Add(float64(inc))
 }
 
+func (m *Metrics) RecordSharedCacheResult(dataType metrics.SharedCacheDataType, cacheResult metrics.CacheResult, inc int) {
+	m.sharedCacheResult.With(prometheus.Labels{
+		dataTypeLabel:    string(dataType),
+		cacheResultLabel: string(cacheResult),
+	}).Add(float64(inc))
+}
+
 func (m *Metrics) RecordPrebidCacheRequestTime(success bool, length time.Duration) {
 	m.prebidCacheWriteTimer.With(prometheus.Labels{
 		successLabel: strconv.FormatBool(success),
 	}).Observe(length.Seconds())
 }
 
+func (m *Metrics) RecordPrebidCacheAsyncWrite(result metrics.CacheWriteResult, inc int) {
+	m.prebidCacheAsyncWrites.With(prometheus.Labels{
+		cacheWriteLabel: string(result),
+	}).Add(float64(inc))
+}
+
+func (m *Metrics) RecordGeoLocationLookupTime(success bool, length time.Duration) {
+	m.geoLocationLookupTimer.With(prometheus.Labels{
+		successLabel: strconv.FormatBool(success),
+	}).Observe(length.Seconds())
+}
+
 func (m *Metrics) RecordRequestQueueTime(success bool, requestType metrics.RequestType, length time.Duration) {
 	successLabelFormatted := requestRejectLabel
 	if success {
@@ -725,3 +906,74 @@ func (m *Metrics) RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.Bidder
 		adapterLabel: string(adapterName),
 	}).Inc()
 }
+
+func (m *Metrics) RecordTCFPurposeBlocked(purpose metrics.TCFPurposeValue) {
+	m.privacyTCFPurposeBlocked.With(prometheus.Labels{
+		purposeLabel: string(purpose),
+	}).Inc()
+}
+
+func (m *Metrics) RecordPrivacyScrub(profile metrics.PrivacyScrubProfile) {
+	m.privacyScrub.With(prometheus.Labels{
+		profileLabel: string(profile),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterRetry(adapterName openrtb_ext.BidderName) {
+	m.adapterRetries.With(prometheus.Labels{
+		adapterLabel: string(adapterName),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterRetryRecovered(adapterName openrtb_ext.BidderName) {
+	m.adapterRetriesRecovered.With(prometheus.Labels{
+		adapterLabel: string(adapterName),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterEidsStripped(adapterName openrtb_ext.BidderName, count int) {
+	m.adapterEidsStripped.With(prometheus.Labels{
+		adapterLabel: string(adapterName),
+	}).Add(float64(count))
+}
+
+func (m *Metrics) RecordFloorsRejectedBid(adapterName openrtb_ext.BidderName, reason metrics.FloorsRejectReason) {
+	m.adapterFloorsRejectedBids.With(prometheus.Labels{
+		adapterLabel:      string(adapterName),
+		floorsRejectLabel: string(reason),
+	}).Inc()
+}
+
+func (m *Metrics) RecordBidValidationFailure(adapterName openrtb_ext.BidderName, rule metrics.BidValidationRule, enforced bool) {
+	m.adapterBidValidations.With(prometheus.Labels{
+		adapterLabel:       string(adapterName),
+		bidValidationLabel: string(rule),
+		enforcedLabel:      strconv.FormatBool(enforced),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterMediaTypeFiltered(adapterName openrtb_ext.BidderName, mediaType metrics.ImpMediaType) {
+	m.adapterMediaTypesFiltered.With(prometheus.Labels{
+		adapterLabel:   string(adapterName),
+		mediaTypeLabel: string(mediaType),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterGzipBytesSaved(adapterName openrtb_ext.BidderName, compression metrics.AdapterCompression, bytes int) {
+	m.adapterGzipBytesSaved.With(prometheus.Labels{
+		adapterLabel:     string(adapterName),
+		compressionLabel: string(compression),
+	}).Add(float64(bytes))
+}
+
+func (m *Metrics) RecordAdapterAliasRequest(adapterName openrtb_ext.BidderName, alias string, adapterBid metrics.AdapterBid) {
+	if m.metricsDisabled.AdapterAliasDetails {
+		return
+	}
+
+	m.adapterAliasRequests.With(prometheus.Labels{
+		adapterLabel: string(adapterName),
+		aliasLabel:   alias,
+		hasBidsLabel: strconv.FormatBool(adapterBid == metrics.AdapterBidPresent),
+	}).Inc()
+}
@@ -63,7 +63,7 @@ func TestMetricCountGatekeeping(t *testing.T) {
 	// Verify Per-Adapter Cardinality
 	// - This assertion provides a warning for newly added adapter metrics. Threre are 40+ adapters which makes the
 	//   cost of new per-adapter metrics rather expensive. Thought should be given when adding new per-adapter metrics.
-	assert.True(t, perAdapterCardinalityCount <= 27, "Per-Adapter Cardinality count equals %d \n", perAdapterCardinalityCount)
+	assert.True(t, perAdapterCardinalityCount <= 35, "Per-Adapter Cardinality count equals %d \n", perAdapterCardinalityCount)
 }
 
 func TestConnectionMetrics(t *testing.T) {
@@ -923,6 +923,100 @@ func TestAdapterTimeMetric(t *testing.T) {
 	}
 }
 
+func TestAdapterTimeBuckets(t *testing.T) {
+	testCases := []struct {
+		description            string
+		givenTimeBuckets       []float64
+		givenAdapterBuckets    []float64
+		expectedRequestBuckets []float64
+		expectedAdapterBuckets []float64
+	}{
+		{
+			description:            "Default",
+			expectedRequestBuckets: defaultTimeBuckets,
+			expectedAdapterBuckets: defaultTimeBuckets,
+		},
+		{
+			description:            "Time buckets",
+			givenTimeBuckets:       []float64{0.1, 1},
+			expectedRequestBuckets: []float64{0.1, 1},
+			expectedAdapterBuckets: []float64{0.1, 1},
+		},
+		{
+			description:            "Adapter time buckets",
+			givenTimeBuckets:       []float64{0.1, 1},
+			givenAdapterBuckets:    []float64{0.5, 1, 2},
+			expectedRequestBuckets: []float64{0.1, 1},
+			expectedAdapterBuckets: []float64{0.5, 1, 2},
+		},
+	}
+
+	for _, test := range testCases {
+		m := NewMetrics(config.PrometheusMetrics{TimeBuckets: test.givenTimeBuckets, AdapterTimeBuckets: test.givenAdapterBuckets}, config.DisabledMetrics{}, nil)
+
+		m.RecordRequestTime(metrics.Labels{RType: metrics.ReqTypeORTB2Web, RequestStatus: metrics.RequestStatusOK}, time.Second)
+		m.RecordAdapterTime(metrics.AdapterLabels{Adapter: openrtb_ext.BidderAppnexus}, time.Second)
+
+		requestHistogram := getHistogramFromHistogramVec(m.requestsTimer, requestTypeLabel, string(metrics.ReqTypeORTB2Web))
+		assert.Equal(t, test.expectedRequestBuckets, histogramUpperBounds(requestHistogram), test.description+":request")
+		adapterHistogram := getHistogramFromHistogramVec(m.adapterRequestsTimer, adapterLabel, string(openrtb_ext.BidderAppnexus))
+		assert.Equal(t, test.expectedAdapterBuckets, histogramUpperBounds(adapterHistogram), test.description+":adapter")
+	}
+}
+
+func histogramUpperBounds(histogram dto.Histogram) []float64 {
+	upperBounds := make([]float64, 0, len(histogram.GetBucket()))
+	for _, bucket := range histogram.GetBucket() {
+		upperBounds = append(upperBounds, bucket.GetUpperBound())
+	}
+	return upperBounds
+}
+
+func TestAccountAdapterTimeMetric(t *testing.T) {
+	m := NewMetrics(config.PrometheusMetrics{MaxAdapterAccounts: 2}, config.DisabledMetrics{}, nil)
+	recordTime := func(account string) {
+		m.RecordAdapterTime(metrics.AdapterLabels{Adapter: openrtb_ext.BidderAppnexus, PubID: account}, 500*time.Millisecond)
+	}
+
+	recordTime("acct-1")
+	recordTime("acct-2")
+	recordTime("acct-3")
+	recordTime("acct-1")
+	recordTime("acct-4")
+	recordTime(metrics.PublisherUnknown)
+
+	assertHistogram(t, "acct-1", getHistogramFromHistogramVecByTwoKeys(m.accountAdapterRequestsTimer, accountLabel, "acct-1", adapterLabel, "appnexus"), 2, 1)
+	assertHistogram(t, "acct-2", getHistogramFromHistogramVecByTwoKeys(m.accountAdapterRequestsTimer, accountLabel, "acct-2", adapterLabel, "appnexus"), 1, 0.5)
+	assertHistogram(t, "other", getHistogramFromHistogramVecByTwoKeys(m.accountAdapterRequestsTimer, accountLabel, accountOther, adapterLabel, "appnexus"), 2, 1)
+	assertHistogram(t, "acct-3", getHistogramFromHistogramVecByTwoKeys(m.accountAdapterRequestsTimer, accountLabel, "acct-3", adapterLabel, "appnexus"), 0, 0)
+}
+
+func TestAccountAdapterTimeMetricDisabled(t *testing.T) {
+	testCases := []struct {
+		description        string
+		maxAdapterAccounts int
+		disabled           config.DisabledMetrics
+	}{
+		{
+			description:        "No accounts",
+			maxAdapterAccounts: 0,
+		},
+		{
+			description:        "Account adapter details disabled",
+			maxAdapterAccounts: 10,
+			disabled:           config.DisabledMetrics{AccountAdapterDetails: true},
+		},
+	}
+
+	for _, test := range testCases {
+		m := NewMetrics(config.PrometheusMetrics{MaxAdapterAccounts: test.maxAdapterAccounts}, test.disabled, nil)
+
+		m.RecordAdapterTime(metrics.AdapterLabels{Adapter: openrtb_ext.BidderAppnexus, PubID: "acct"}, time.Second)
+
+		assert.Nil(t, m.accountAdapterRequestsTimer, test.description)
+	}
+}
+
 func TestAdapterPanicMetric(t *testing.T) {
 	m := createMetricsForTesting()
 	adapterName := "anyName"
@@ -999,6 +1093,57 @@ func TestAccountCacheResultMetric(t *testing.T) {
 		})
 }
 
+func TestSharedCacheResultMetric(t *testing.T) {
+	m := createMetricsForTesting()
+
+	hitCount := 12
+	missCount := 3
+	m.RecordSharedCacheResult(metrics.SharedCacheAccount, metrics.CacheHit, hitCount)
+	m.RecordSharedCacheResult(metrics.SharedCacheAccount, metrics.CacheMiss, missCount)
+
+	assertCounterVecValue(t, "", "sharedCacheResult:account:hit", m.sharedCacheResult,
+		float64(hitCount),
+		prometheus.Labels{
+			dataTypeLabel:    string(metrics.SharedCacheAccount),
+			cacheResultLabel: string(metrics.CacheHit),
+		})
+	assertCounterVecValue(t, "", "sharedCacheResult:account:miss", m.sharedCacheResult,
+		float64(missCount),
+		prometheus.Labels{
+			dataTypeLabel:    string(metrics.SharedCacheAccount),
+			cacheResultLabel: string(metrics.CacheMiss),
+		})
+	assertCounterVecValue(t, "", "sharedCacheResult:request:hit", m.sharedCacheResult,
+		0,
+		prometheus.Labels{
+			dataTypeLabel:    string(metrics.SharedCacheRequest),
+			cacheResultLabel: string(metrics.CacheHit),
+		})
+}
+
+func TestPrebidCacheAsyncWriteMetric(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordPrebidCacheAsyncWrite(metrics.CacheWriteOK, 5)
+	m.RecordPrebidCacheAsyncWrite(metrics.CacheWriteFailed, 2)
+
+	assertCounterVecValue(t, "", "prebidCacheAsyncWrites:ok", m.prebidCacheAsyncWrites,
+		5,
+		prometheus.Labels{
+			cacheWriteLabel: string(metrics.CacheWriteOK),
+		})
+	assertCounterVecValue(t, "", "prebidCacheAsyncWrites:failed", m.prebidCacheAsyncWrites,
+		2,
+		prometheus.Labels{
+			cacheWriteLabel: string(metrics.CacheWriteFailed),
+		})
+	assertCounterVecValue(t, "", "prebidCacheAsyncWrites:dropped", m.prebidCacheAsyncWrites,
+		0,
+		prometheus.Labels{
+			cacheWriteLabel: string(metrics.CacheWriteDropped),
+		})
+}
+
 func TestCookieSyncMetric(t *testing.T) {
 	tests := []struct {
 		status metrics.CookieSyncStatus
@@ -1099,6 +1244,10 @@ func TestSetUidMetric(t *testing.T) {
 			status: metrics.SetUidSyncerUnknown,
 			label:  "syncer_unknown",
 		},
+		{
+			status: metrics.SetUidAccountBlocked,
+			label:  "account_blocked",
+		},
 	}
 
 	for _, test := range tests {
@@ -1162,6 +1311,19 @@ func TestPrebidCacheRequestTimeMetric(t *testing.T) {
 	assertHistogram(t, "Error", errorResult, errorExpectedCount, errorExpectedSum)
 }
 
+func TestGeoLocationLookupTimeMetric(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordGeoLocationLookupTime(true, time.Duration(2)*time.Millisecond)
+	m.RecordGeoLocationLookupTime(false, time.Duration(20)*time.Millisecond)
+
+	successResult := getHistogramFromHistogramVec(m.geoLocationLookupTimer, successLabel, "true")
+	assertHistogram(t, "Success", successResult, uint64(1), float64(0.002))
+
+	errorResult := getHistogramFromHistogramVec(m.geoLocationLookupTimer, successLabel, "false")
+	assertHistogram(t, "Error", errorResult, uint64(1), float64(0.02))
+}
+
 func TestMetricAccumulationSpotCheck(t *testing.T) {
 	m := createMetricsForTesting()
 
@@ -1600,3 +1762,173 @@ func TestRecordAdapterGDPRRequestBlocked(t *testing.T) {
 			adapterLabel: string(openrtb_ext.BidderAppnexus),
 		})
 }
+
+func TestRecordTCFPurposeBlocked(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordTCFPurposeBlocked(metrics.TCFSpecialFeature1)
+
+	assertCounterVecValue(t,
+		"Increment TCF purpose blocked counter",
+		"privacy_tcf_purpose_blocked",
+		m.privacyTCFPurposeBlocked,
+		1,
+		prometheus.Labels{
+			purposeLabel: string(metrics.TCFSpecialFeature1),
+		})
+}
+
+func TestRecordPrivacyScrub(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordPrivacyScrub(metrics.PrivacyScrubProfileLGPD)
+
+	assertCounterVecValue(t,
+		"Increment privacy scrub counter",
+		"privacy_scrub",
+		m.privacyScrub,
+		1,
+		prometheus.Labels{
+			profileLabel: string(metrics.PrivacyScrubProfileLGPD),
+		})
+}
+
+func TestRecordAdapterRetry(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAdapterRetry(openrtb_ext.BidderAppnexus)
+
+	assertCounterVecValue(t,
+		"Increment adapter retry counter",
+		"adapter_retries",
+		m.adapterRetries,
+		1,
+		prometheus.Labels{
+			adapterLabel: string(openrtb_ext.BidderAppnexus),
+		})
+}
+
+func TestRecordAdapterRetryRecovered(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAdapterRetryRecovered(openrtb_ext.BidderAppnexus)
+
+	assertCounterVecValue(t,
+		"Increment adapter retry recovered counter",
+		"adapter_retries_recovered",
+		m.adapterRetriesRecovered,
+		1,
+		prometheus.Labels{
+			adapterLabel: string(openrtb_ext.BidderAppnexus),
+		})
+}
+
+func TestRecordAdapterEidsStripped(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAdapterEidsStripped(openrtb_ext.BidderAppnexus, 3)
+
+	assertCounterVecValue(t,
+		"Add adapter eids stripped counter",
+		"adapter_eids_stripped",
+		m.adapterEidsStripped,
+		3,
+		prometheus.Labels{
+			adapterLabel: string(openrtb_ext.BidderAppnexus),
+		})
+}
+
+func TestRecordAdapterMediaTypeFiltered(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAdapterMediaTypeFiltered(openrtb_ext.BidderAppnexus, metrics.ImpTypeVideo)
+
+	assertCounterVecValue(t,
+		"Increment adapter media types filtered counter",
+		"adapter_media_types_filtered",
+		m.adapterMediaTypesFiltered,
+		1,
+		prometheus.Labels{
+			adapterLabel:   string(openrtb_ext.BidderAppnexus),
+			mediaTypeLabel: string(metrics.ImpTypeVideo),
+		})
+}
+
+func TestRecordFloorsRejectedBid(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordFloorsRejectedBid(openrtb_ext.BidderAppnexus, metrics.FloorsRejectNoConversionRate)
+
+	assertCounterVecValue(t,
+		"Increment floors rejected bids counter",
+		"adapter_floors_rejected_bids",
+		m.adapterFloorsRejectedBids,
+		1,
+		prometheus.Labels{
+			adapterLabel:      string(openrtb_ext.BidderAppnexus),
+			floorsRejectLabel: string(metrics.FloorsRejectNoConversionRate),
+		})
+}
+
+func TestRecordBidValidationFailure(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordBidValidationFailure(openrtb_ext.BidderAppnexus, metrics.BidValidationCreativeSize, true)
+
+	assertCounterVecValue(t,
+		"Increment bid validation failures counter",
+		"adapter_bid_validation_failures",
+		m.adapterBidValidations,
+		1,
+		prometheus.Labels{
+			adapterLabel:       string(openrtb_ext.BidderAppnexus),
+			bidValidationLabel: string(metrics.BidValidationCreativeSize),
+			enforcedLabel:      "true",
+		})
+}
+
+func TestRecordAdapterGzipBytesSaved(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAdapterGzipBytesSaved(openrtb_ext.BidderAppnexus, metrics.AdapterCompressionResponse, 300)
+	m.RecordAdapterGzipBytesSaved(openrtb_ext.BidderAppnexus, metrics.AdapterCompressionResponse, 200)
+
+	assertCounterVecValue(t,
+		"Add gzip bytes saved counter",
+		"adapter_gzip_bytes_saved",
+		m.adapterGzipBytesSaved,
+		500,
+		prometheus.Labels{
+			adapterLabel:     string(openrtb_ext.BidderAppnexus),
+			compressionLabel: string(metrics.AdapterCompressionResponse),
+		})
+}
+
+func TestRecordAdapterAliasRequest(t *testing.T) {
+	m := createMetricsForTesting()
+
+	m.RecordAdapterAliasRequest(openrtb_ext.BidderAppnexus, "apnAlias", metrics.AdapterBidPresent)
+	m.RecordAdapterAliasRequest(openrtb_ext.BidderAppnexus, "apnAlias", metrics.AdapterBidNone)
+	m.RecordAdapterAliasRequest(openrtb_ext.BidderAppnexus, "apnAlias", metrics.AdapterBidPresent)
+
+	assertCounterVecValue(t,
+		"Increment adapter alias requests with bids counter",
+		"adapter_alias_requests",
+		m.adapterAliasRequests,
+		2,
+		prometheus.Labels{
+			adapterLabel: string(openrtb_ext.BidderAppnexus),
+			aliasLabel:   "apnAlias",
+			hasBidsLabel: "true",
+		})
+	assertCounterVecValue(t,
+		"Increment adapter alias requests without bids counter",
+		"adapter_alias_requests",
+		m.adapterAliasRequests,
+		1,
+		prometheus.Labels{
+			adapterLabel: string(openrtb_ext.BidderAppnexus),
+			aliasLabel:   "apnAlias",
+			hasBidsLabel: "false",
+		})
+}
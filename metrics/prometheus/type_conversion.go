@@ -25,6 +25,42 @@ func adapterErrorsAsString() []string {
 	return valuesAsString
 }
 
+func floorsRejectReasonsAsString() []string {
+	values := metrics.FloorsRejectReasons()
+	valuesAsString := make([]string, len(values))
+	for i, v := range values {
+		valuesAsString[i] = string(v)
+	}
+	return valuesAsString
+}
+
+func bidValidationRulesAsString() []string {
+	values := metrics.BidValidationRules()
+	valuesAsString := make([]string, len(values))
+	for i, v := range values {
+		valuesAsString[i] = string(v)
+	}
+	return valuesAsString
+}
+
+func adapterCompressionsAsString() []string {
+	values := metrics.AdapterCompressions()
+	valuesAsString := make([]string, len(values))
+	for i, v := range values {
+		valuesAsString[i] = string(v)
+	}
+	return valuesAsString
+}
+
+func sharedCacheDataTypesAsString() []string {
+	values := metrics.SharedCacheDataTypes()
+	valuesAsString := make([]string, len(values))
+	for i, v := range values {
+		valuesAsString[i] = string(v)
+	}
+	return valuesAsString
+}
+
 func boolValuesAsString() []string {
 	return []string{
 		strconv.FormatBool(true),
@@ -41,6 +77,15 @@ func cacheResultsAsString() []string {
 	return valuesAsString
 }
 
+func cacheWriteResultsAsString() []string {
+	values := metrics.CacheWriteResults()
+	valuesAsString := make([]string, len(values))
+	for i, v := range values {
+		valuesAsString[i] = string(v)
+	}
+	return valuesAsString
+}
+
 func cookieTypesAsString() []string {
 	values := metrics.CookieTypes()
 	valuesAsString := make([]string, len(values))
@@ -0,0 +1,165 @@
+package statsdmetrics
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// Client sends DogStatsD metrics to an agent over UDP in the background. The metrics are packed into packets of up
+// to maxPacketSize bytes, which are sent once full or every flush interval. The metrics are dropped when the queue
+// is full, so that a slow agent never holds the requests back, and counted to be logged once per flush interval.
+type Client struct {
+	// dropped counts the metrics dropped since the last flush. It comes first, to be 64 bit aligned for atomic access.
+	dropped uint64
+
+	conn          net.Conn
+	prefix        string
+	tags          string
+	maxPacketSize int
+	flushInterval time.Duration
+	lines         chan string
+	done          chan struct{}
+
+	closeMutex sync.RWMutex
+	closed     bool
+}
+
+// NewClient returns a client sending the metrics to the agent at the address, with the prefix before their names
+// and the tags on top of their own
+func NewClient(address, prefix string, tags []string, flushInterval time.Duration, bufferSize, maxPacketSize int) (*Client, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	sanitizedTags := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		sanitizedTags = append(sanitizedTags, sanitize(tag))
+	}
+	client := &Client{
+		conn:          conn,
+		prefix:        prefix,
+		tags:          strings.Join(sanitizedTags, ","),
+		maxPacketSize: maxPacketSize,
+		flushInterval: flushInterval,
+		lines:         make(chan string, bufferSize),
+		done:          make(chan struct{}),
+	}
+	go client.run()
+	return client, nil
+}
+
+// Count adds the value to the counter of the name
+func (c *Client) Count(name string, value int64, tags ...string) {
+	c.send(name, strconv.FormatInt(value, 10), "c", tags)
+}
+
+// Timing records the duration in milliseconds under the name
+func (c *Client) Timing(name string, value time.Duration, tags ...string) {
+	c.send(name, strconv.FormatFloat(float64(value)/float64(time.Millisecond), 'f', -1, 64), "ms", tags)
+}
+
+// Histogram records the value in the histogram of the name
+func (c *Client) Histogram(name string, value float64, tags ...string) {
+	c.send(name, strconv.FormatFloat(value, 'f', -1, 64), "h", tags)
+}
+
+// Close sends the queued metrics before closing the connection to the agent
+func (c *Client) Close() {
+	c.closeMutex.Lock()
+	if c.closed {
+		c.closeMutex.Unlock()
+		return
+	}
+	c.closed = true
+	close(c.lines)
+	c.closeMutex.Unlock()
+
+	<-c.done
+	c.conn.Close()
+}
+
+// send queues the metric in the DogStatsD format, as in "prebid.requests:1|c|#endpoint:openrtb2-web,status:ok"
+func (c *Client) send(name, value, metricType string, tags []string) {
+	var line strings.Builder
+	line.WriteString(c.prefix)
+	line.WriteString(name)
+	line.WriteByte(':')
+	line.WriteString(value)
+	line.WriteByte('|')
+	line.WriteString(metricType)
+	if len(tags) > 0 || c.tags != "" {
+		line.WriteString("|#")
+		line.WriteString(c.tags)
+		for i, tag := range tags {
+			if i > 0 || c.tags != "" {
+				line.WriteByte(',')
+			}
+			line.WriteString(sanitize(tag))
+		}
+	}
+
+	c.closeMutex.RLock()
+	defer c.closeMutex.RUnlock()
+	if c.closed {
+		return
+	}
+	select {
+	case c.lines <- line.String():
+	default:
+		atomic.AddUint64(&c.dropped, 1)
+	}
+}
+
+func (c *Client) run() {
+	defer close(c.done)
+	ticker := time.NewTicker(c.flushInterval)
+	defer ticker.Stop()
+
+	packet := make([]byte, 0, c.maxPacketSize)
+	for {
+		select {
+		case line, ok := <-c.lines:
+			if !ok {
+				c.write(packet)
+				return
+			}
+			if len(packet) > 0 && len(packet)+1+len(line) > c.maxPacketSize {
+				c.write(packet)
+				packet = packet[:0]
+			}
+			if len(packet) > 0 {
+				packet = append(packet, '\n')
+			}
+			packet = append(packet, line...)
+		case <-ticker.C:
+			if dropped := atomic.SwapUint64(&c.dropped, 0); dropped > 0 {
+				glog.Warningf("StatsD queue is full, %d metrics were dropped", dropped)
+			}
+			c.write(packet)
+			packet = packet[:0]
+		}
+	}
+}
+
+func (c *Client) write(packet []byte) {
+	if len(packet) == 0 {
+		return
+	}
+	if _, err := c.conn.Write(packet); err != nil {
+		glog.Errorf("Failed to send the StatsD metrics: %v", err)
+	}
+}
+
+var tagReplacer = strings.NewReplacer("|", "_", ",", "_", "#", "_", "\n", "_")
+
+// sanitize replaces the characters which separate the fields of the DogStatsD format in a tag
+func sanitize(tag string) string {
+	return tagReplacer.Replace(tag)
+}
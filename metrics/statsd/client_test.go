@@ -0,0 +1,111 @@
+package statsdmetrics
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeAgent returns a UDP listener standing for the DogStatsD agent
+func newFakeAgent(t *testing.T) net.PacketConn {
+	agent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { agent.Close() })
+	return agent
+}
+
+// readPackets returns the packets the agent receives until none arrives for a while
+func readPackets(t *testing.T, agent net.PacketConn) []string {
+	var packets []string
+	buffer := make([]byte, 65536)
+	for {
+		agent.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		n, _, err := agent.ReadFrom(buffer)
+		if err != nil {
+			return packets
+		}
+		packets = append(packets, string(buffer[:n]))
+	}
+}
+
+func TestClientSend(t *testing.T) {
+	agent := newFakeAgent(t)
+	client, err := NewClient(agent.LocalAddr().String(), "prebid.", []string{"env:prod"}, time.Hour, 10, 1432)
+	require.NoError(t, err)
+
+	client.Count("requests", 2, "endpoint:openrtb2-web", "status:o|k")
+	client.Timing("request_time", 1500*time.Microsecond)
+	client.Histogram("adapter_prices", 0.25, "bidder:appnexus")
+	client.Close()
+
+	assert.Equal(t, []string{
+		"prebid.requests:2|c|#env:prod,endpoint:openrtb2-web,status:o_k\n" +
+			"prebid.request_time:1.5|ms|#env:prod\n" +
+			"prebid.adapter_prices:0.25|h|#env:prod,bidder:appnexus",
+	}, readPackets(t, agent))
+}
+
+func TestClientSendWithoutTags(t *testing.T) {
+	agent := newFakeAgent(t)
+	client, err := NewClient(agent.LocalAddr().String(), "", nil, time.Hour, 10, 1432)
+	require.NoError(t, err)
+
+	client.Count("requests", 1)
+	client.Count("imps", 1, "banner:true")
+	client.Close()
+
+	assert.Equal(t, []string{"requests:1|c\nimps:1|c|#banner:true"}, readPackets(t, agent))
+}
+
+func TestClientPacketSize(t *testing.T) {
+	agent := newFakeAgent(t)
+	client, err := NewClient(agent.LocalAddr().String(), "", nil, time.Hour, 10, 20)
+	require.NoError(t, err)
+
+	client.Count("first", 1)
+	client.Count("second", 1)
+	client.Count("third", 1)
+	client.Close()
+
+	packets := readPackets(t, agent)
+	assert.Equal(t, []string{"first:1|c\nsecond:1|c", "third:1|c"}, packets, "The metrics should be split over packets of up to the max size")
+}
+
+func TestClientFlushInterval(t *testing.T) {
+	agent := newFakeAgent(t)
+	client, err := NewClient(agent.LocalAddr().String(), "", nil, 10*time.Millisecond, 10, 1432)
+	require.NoError(t, err)
+	defer client.Close()
+
+	client.Count("requests", 1)
+
+	assert.Equal(t, []string{"requests:1|c"}, readPackets(t, agent), "The metrics should be sent once the flush interval passes")
+}
+
+func TestClientClosed(t *testing.T) {
+	agent := newFakeAgent(t)
+	client, err := NewClient(agent.LocalAddr().String(), "", nil, time.Hour, 10, 1432)
+	require.NoError(t, err)
+
+	client.Close()
+	client.Close()
+	client.Count("requests", 1)
+
+	assert.Empty(t, readPackets(t, agent))
+}
+
+func TestClientDropsWhenFull(t *testing.T) {
+	client := &Client{
+		lines: make(chan string, 1),
+	}
+
+	client.Count("requests", 1)
+	client.Count("requests", 1)
+	client.Count("requests", 1)
+
+	assert.Len(t, client.lines, 1)
+	assert.Equal(t, uint64(2), client.dropped, "The metrics past the size of the queue should be dropped and counted")
+}
@@ -0,0 +1,264 @@
+package statsdmetrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// Metrics defines the DogStatsD metrics backing the MetricsEngine implementation. The metrics are tagged rather
+// than named after their bidder, account, endpoint and status, which the agent aggregates on.
+type Metrics struct {
+	Client *Client
+
+	metricsDisabled config.DisabledMetrics
+}
+
+const (
+	accountTag     = "account"
+	bidderTag      = "bidder"
+	endpointTag    = "endpoint"
+	statusTag      = "status"
+	successTag     = "success"
+	cacheResultTag = "cache_result"
+)
+
+// NewMetrics returns the metrics sent to the DogStatsD agent of the config
+func NewMetrics(cfg config.StatsDMetrics, disabledMetrics config.DisabledMetrics) (*Metrics, error) {
+	client, err := NewClient(cfg.Address, cfg.Prefix, cfg.Tags, time.Duration(cfg.FlushIntervalMs)*time.Millisecond, cfg.BufferSize, cfg.MaxPacketSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Metrics{Client: client, metricsDisabled: disabledMetrics}, nil
+}
+
+func tag(key, value string) string {
+	return key + ":" + value
+}
+
+// requestTags returns the tags of the request metrics, with its account if it is known
+func requestTags(labels metrics.Labels, tags ...string) []string {
+	tags = append(tags, tag(endpointTag, string(labels.RType)))
+	if labels.PubID != "" && labels.PubID != metrics.PublisherUnknown {
+		tags = append(tags, tag(accountTag, labels.PubID))
+	}
+	return tags
+}
+
+// adapterTags returns the tags of the adapter metrics, with its account unless the account adapter details are
+// disabled
+func (m *Metrics) adapterTags(labels metrics.AdapterLabels, tags ...string) []string {
+	tags = append(tags, tag(bidderTag, string(labels.Adapter)), tag(endpointTag, string(labels.RType)))
+	if !m.metricsDisabled.AccountAdapterDetails && labels.PubID != "" && labels.PubID != metrics.PublisherUnknown {
+		tags = append(tags, tag(accountTag, labels.PubID))
+	}
+	return tags
+}
+
+func (m *Metrics) RecordConnectionAccept(success bool) {
+	m.Client.Count("connections_accepted", 1, tag(successTag, strconv.FormatBool(success)))
+}
+
+func (m *Metrics) RecordConnectionClose(success bool) {
+	m.Client.Count("connections_closed", 1, tag(successTag, strconv.FormatBool(success)))
+}
+
+func (m *Metrics) RecordRequest(labels metrics.Labels) {
+	m.Client.Count("requests", 1, requestTags(labels,
+		tag(statusTag, string(labels.RequestStatus)),
+		tag("source", string(labels.Source)),
+		tag("cookie", string(labels.CookieFlag)))...)
+}
+
+func (m *Metrics) RecordImps(labels metrics.ImpLabels) {
+	m.Client.Count("imps", 1,
+		tag("banner", strconv.FormatBool(labels.BannerImps)),
+		tag("video", strconv.FormatBool(labels.VideoImps)),
+		tag("audio", strconv.FormatBool(labels.AudioImps)),
+		tag("native", strconv.FormatBool(labels.NativeImps)))
+}
+
+func (m *Metrics) RecordLegacyImps(labels metrics.Labels, numImps int) {
+	m.Client.Count("imps_legacy", int64(numImps))
+}
+
+func (m *Metrics) RecordRequestTime(labels metrics.Labels, length time.Duration) {
+	if labels.RequestStatus == metrics.RequestStatusOK {
+		m.Client.Timing("request_time", length, requestTags(labels)...)
+	}
+}
+
+func (m *Metrics) RecordAdapterRequest(labels metrics.AdapterLabels) {
+	m.Client.Count("adapter_requests", 1, m.adapterTags(labels,
+		tag("cookie", string(labels.CookieFlag)),
+		tag("has_bids", strconv.FormatBool(labels.AdapterBids == metrics.AdapterBidPresent)))...)
+	for err := range labels.AdapterErrors {
+		m.Client.Count("adapter_errors", 1, m.adapterTags(labels, tag("error", string(err)))...)
+	}
+}
+
+func (m *Metrics) RecordAdapterConnections(adapterName openrtb_ext.BidderName, connWasReused bool, connWaitTime time.Duration) {
+	if m.metricsDisabled.AdapterConnectionMetrics {
+		return
+	}
+	m.Client.Count("adapter_connections", 1, tag(bidderTag, string(adapterName)), tag("reused", strconv.FormatBool(connWasReused)))
+	m.Client.Timing("adapter_connection_wait", connWaitTime, tag(bidderTag, string(adapterName)))
+}
+
+func (m *Metrics) RecordDNSTime(dnsLookupTime time.Duration) {
+	m.Client.Timing("dns_lookup_time", dnsLookupTime)
+}
+
+func (m *Metrics) RecordTLSHandshakeTime(tlsHandshakeTime time.Duration) {
+	m.Client.Timing("tls_handshake_time", tlsHandshakeTime)
+}
+
+func (m *Metrics) RecordAdapterPanic(labels metrics.AdapterLabels) {
+	m.Client.Count("adapter_panics", 1, tag(bidderTag, string(labels.Adapter)))
+}
+
+func (m *Metrics) RecordAdapterBidReceived(labels metrics.AdapterLabels, bidType openrtb_ext.BidType, hasAdm bool) {
+	delivery := "nurl"
+	if hasAdm {
+		delivery = "adm"
+	}
+	m.Client.Count("adapter_bids", 1, tag(bidderTag, string(labels.Adapter)), tag("bid_type", string(bidType)), tag("delivery", delivery))
+}
+
+func (m *Metrics) RecordAdapterPrice(labels metrics.AdapterLabels, cpm float64) {
+	m.Client.Histogram("adapter_prices", cpm, tag(bidderTag, string(labels.Adapter)))
+}
+
+func (m *Metrics) RecordAdapterTime(labels metrics.AdapterLabels, length time.Duration) {
+	if len(labels.AdapterErrors) == 0 {
+		m.Client.Timing("adapter_request_time", length, m.adapterTags(labels)...)
+	}
+}
+
+func (m *Metrics) RecordCookieSync(status metrics.CookieSyncStatus) {
+	m.Client.Count("cookie_sync_requests", 1, tag(statusTag, string(status)))
+}
+
+func (m *Metrics) RecordSyncerRequest(key string, status metrics.SyncerCookieSyncStatus) {
+	m.Client.Count("syncer_requests", 1, tag("syncer", key), tag(statusTag, string(status)))
+}
+
+func (m *Metrics) RecordSetUid(status metrics.SetUidStatus) {
+	m.Client.Count("setuid_requests", 1, tag(statusTag, string(status)))
+}
+
+func (m *Metrics) RecordSyncerSet(key string, status metrics.SyncerSetUidStatus) {
+	m.Client.Count("syncer_sets", 1, tag("syncer", key), tag(statusTag, string(status)))
+}
+
+func (m *Metrics) RecordStoredReqCacheResult(cacheResult metrics.CacheResult, inc int) {
+	m.Client.Count("stored_request_cache_performance", int64(inc), tag(cacheResultTag, string(cacheResult)))
+}
+
+func (m *Metrics) RecordStoredImpCacheResult(cacheResult metrics.CacheResult, inc int) {
+	m.Client.Count("stored_imp_cache_performance", int64(inc), tag(cacheResultTag, string(cacheResult)))
+}
+
+func (m *Metrics) RecordAccountCacheResult(cacheResult metrics.CacheResult, inc int) {
+	m.Client.Count("account_cache_performance", int64(inc), tag(cacheResultTag, string(cacheResult)))
+}
+
+func (m *Metrics) RecordSharedCacheResult(dataType metrics.SharedCacheDataType, cacheResult metrics.CacheResult, inc int) {
+	m.Client.Count("shared_cache_performance", int64(inc), tag("data_type", string(dataType)), tag(cacheResultTag, string(cacheResult)))
+}
+
+func (m *Metrics) RecordStoredDataFetchTime(labels metrics.StoredDataLabels, length time.Duration) {
+	m.Client.Timing("stored_data_fetch_time", length, tag("data_type", string(labels.DataType)), tag("fetch_type", string(labels.DataFetchType)))
+}
+
+func (m *Metrics) RecordStoredDataError(labels metrics.StoredDataLabels) {
+	m.Client.Count("stored_data_errors", 1, tag("data_type", string(labels.DataType)), tag("error", string(labels.Error)))
+}
+
+func (m *Metrics) RecordPrebidCacheRequestTime(success bool, length time.Duration) {
+	m.Client.Timing("prebid_cache_request_time", length, tag(successTag, strconv.FormatBool(success)))
+}
+
+func (m *Metrics) RecordPrebidCacheAsyncWrite(result metrics.CacheWriteResult, inc int) {
+	m.Client.Count("prebid_cache_async_writes", int64(inc), tag("result", string(result)))
+}
+
+func (m *Metrics) RecordGeoLocationLookupTime(success bool, length time.Duration) {
+	m.Client.Timing("geolocation_lookup_time", length, tag(successTag, strconv.FormatBool(success)))
+}
+
+func (m *Metrics) RecordRequestQueueTime(success bool, requestType metrics.RequestType, length time.Duration) {
+	m.Client.Timing("request_queue_time", length, tag(endpointTag, string(requestType)), tag(successTag, strconv.FormatBool(success)))
+}
+
+func (m *Metrics) RecordTimeoutNotice(success bool) {
+	m.Client.Count("timeout_notifications", 1, tag(successTag, strconv.FormatBool(success)))
+}
+
+func (m *Metrics) RecordRequestPrivacy(privacy metrics.PrivacyLabels) {
+	if privacy.CCPAProvided {
+		m.Client.Count("privacy_ccpa", 1, tag("opt_out", strconv.FormatBool(privacy.CCPAEnforced)))
+	}
+	if privacy.COPPAEnforced {
+		m.Client.Count("privacy_coppa", 1)
+	}
+	if privacy.GDPREnforced {
+		m.Client.Count("privacy_tcf", 1, tag("version", string(privacy.GDPRTCFVersion)))
+	}
+	if privacy.LMTEnforced {
+		m.Client.Count("privacy_lmt", 1)
+	}
+}
+
+func (m *Metrics) RecordAdapterGDPRRequestBlocked(adapterName openrtb_ext.BidderName) {
+	if m.metricsDisabled.AdapterGDPRRequestBlocked {
+		return
+	}
+	m.Client.Count("adapter_gdpr_requests_blocked", 1, tag(bidderTag, string(adapterName)))
+}
+
+func (m *Metrics) RecordTCFPurposeBlocked(purpose metrics.TCFPurposeValue) {
+	m.Client.Count("privacy_tcf_purpose_blocked", 1, tag("purpose", string(purpose)))
+}
+
+func (m *Metrics) RecordPrivacyScrub(profile metrics.PrivacyScrubProfile) {
+	m.Client.Count("privacy_scrub", 1, tag("profile", string(profile)))
+}
+
+func (m *Metrics) RecordAdapterRetry(adapterName openrtb_ext.BidderName) {
+	m.Client.Count("adapter_retries", 1, tag(bidderTag, string(adapterName)))
+}
+
+func (m *Metrics) RecordAdapterRetryRecovered(adapterName openrtb_ext.BidderName) {
+	m.Client.Count("adapter_retries_recovered", 1, tag(bidderTag, string(adapterName)))
+}
+
+func (m *Metrics) RecordAdapterEidsStripped(adapterName openrtb_ext.BidderName, count int) {
+	m.Client.Count("adapter_eids_stripped", int64(count), tag(bidderTag, string(adapterName)))
+}
+
+func (m *Metrics) RecordFloorsRejectedBid(adapterName openrtb_ext.BidderName, reason metrics.FloorsRejectReason) {
+	m.Client.Count("adapter_floors_rejected_bids", 1, tag(bidderTag, string(adapterName)), tag("reason", string(reason)))
+}
+
+func (m *Metrics) RecordBidValidationFailure(adapterName openrtb_ext.BidderName, rule metrics.BidValidationRule, enforced bool) {
+	m.Client.Count("adapter_bid_validation_failures", 1, tag(bidderTag, string(adapterName)), tag("rule", string(rule)), tag("enforced", strconv.FormatBool(enforced)))
+}
+
+func (m *Metrics) RecordAdapterMediaTypeFiltered(adapterName openrtb_ext.BidderName, mediaType metrics.ImpMediaType) {
+	m.Client.Count("adapter_media_types_filtered", 1, tag(bidderTag, string(adapterName)), tag("media_type", string(mediaType)))
+}
+
+func (m *Metrics) RecordAdapterGzipBytesSaved(adapterName openrtb_ext.BidderName, compression metrics.AdapterCompression, bytes int) {
+	m.Client.Count("adapter_gzip_bytes_saved", int64(bytes), tag(bidderTag, string(adapterName)), tag("compression", string(compression)))
+}
+
+func (m *Metrics) RecordAdapterAliasRequest(adapterName openrtb_ext.BidderName, alias string, adapterBid metrics.AdapterBid) {
+	if m.metricsDisabled.AdapterAliasDetails {
+		return
+	}
+	m.Client.Count("adapter_alias_requests", 1, tag(bidderTag, string(adapterName)), tag("alias", alias), tag("has_bids", strconv.FormatBool(adapterBid == metrics.AdapterBidPresent)))
+}
@@ -0,0 +1,95 @@
+package statsdmetrics
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordRequest(t *testing.T) {
+	agent := newFakeAgent(t)
+	m, err := NewMetrics(config.StatsDMetrics{Address: agent.LocalAddr().String(), Prefix: "prebid.", FlushIntervalMs: 60000, BufferSize: 10, MaxPacketSize: 1432}, config.DisabledMetrics{})
+	require.NoError(t, err)
+
+	m.RecordRequest(metrics.Labels{RType: metrics.ReqTypeORTB2Web, RequestStatus: metrics.RequestStatusOK, Source: metrics.DemandWeb, CookieFlag: metrics.CookieFlagYes, PubID: "acct"})
+	m.RecordRequest(metrics.Labels{RType: metrics.ReqTypeAMP, RequestStatus: metrics.RequestStatusBadInput, Source: metrics.DemandWeb, CookieFlag: metrics.CookieFlagNo, PubID: metrics.PublisherUnknown})
+	m.RecordRequestTime(metrics.Labels{RType: metrics.ReqTypeORTB2Web, RequestStatus: metrics.RequestStatusOK, PubID: "acct"}, 20*time.Millisecond)
+	m.RecordRequestTime(metrics.Labels{RType: metrics.ReqTypeORTB2Web, RequestStatus: metrics.RequestStatusErr}, 20*time.Millisecond)
+	m.Client.Close()
+
+	assert.Equal(t, []string{
+		"prebid.requests:1|c|#status:ok,source:web,cookie:exists,endpoint:openrtb2-web,account:acct",
+		"prebid.requests:1|c|#status:badinput,source:web,cookie:no,endpoint:amp",
+		"prebid.request_time:20|ms|#endpoint:openrtb2-web,account:acct",
+	}, readLines(t, agent))
+}
+
+func TestRecordAdapterTime(t *testing.T) {
+	testCases := []struct {
+		description   string
+		givenDisabled config.DisabledMetrics
+		givenErrors   map[metrics.AdapterError]struct{}
+		expectedLines []string
+	}{
+		{
+			description:   "Tagged with the account",
+			expectedLines: []string{"adapter_request_time:150|ms|#bidder:appnexus,endpoint:openrtb2-app,account:acct"},
+		},
+		{
+			description:   "Account adapter details disabled",
+			givenDisabled: config.DisabledMetrics{AccountAdapterDetails: true},
+			expectedLines: []string{"adapter_request_time:150|ms|#bidder:appnexus,endpoint:openrtb2-app"},
+		},
+		{
+			description: "Failed request",
+			givenErrors: map[metrics.AdapterError]struct{}{metrics.AdapterErrorTimeout: {}},
+		},
+	}
+
+	for _, test := range testCases {
+		agent := newFakeAgent(t)
+		m, err := NewMetrics(config.StatsDMetrics{Address: agent.LocalAddr().String(), FlushIntervalMs: 60000, BufferSize: 10, MaxPacketSize: 1432}, test.givenDisabled)
+		require.NoError(t, err)
+
+		m.RecordAdapterTime(metrics.AdapterLabels{Adapter: openrtb_ext.BidderAppnexus, RType: metrics.ReqTypeORTB2App, PubID: "acct", AdapterErrors: test.givenErrors}, 150*time.Millisecond)
+		m.Client.Close()
+
+		assert.Equal(t, test.expectedLines, readLines(t, agent), test.description)
+	}
+}
+
+func TestRecordAdapterRequest(t *testing.T) {
+	agent := newFakeAgent(t)
+	m, err := NewMetrics(config.StatsDMetrics{Address: agent.LocalAddr().String(), FlushIntervalMs: 60000, BufferSize: 10, MaxPacketSize: 1432}, config.DisabledMetrics{AccountAdapterDetails: true})
+	require.NoError(t, err)
+
+	m.RecordAdapterRequest(metrics.AdapterLabels{
+		Adapter:       openrtb_ext.BidderRubicon,
+		RType:         metrics.ReqTypeORTB2Web,
+		CookieFlag:    metrics.CookieFlagYes,
+		AdapterBids:   metrics.AdapterBidPresent,
+		AdapterErrors: map[metrics.AdapterError]struct{}{metrics.AdapterErrorTimeout: {}},
+	})
+	m.Client.Close()
+
+	assert.Equal(t, []string{
+		"adapter_requests:1|c|#cookie:exists,has_bids:true,bidder:rubicon,endpoint:openrtb2-web",
+		"adapter_errors:1|c|#error:timeout,bidder:rubicon,endpoint:openrtb2-web",
+	}, readLines(t, agent))
+}
+
+// readLines returns the metrics the agent receives, one per line
+func readLines(t *testing.T, agent net.PacketConn) []string {
+	var lines []string
+	for _, packet := range readPackets(t, agent) {
+		lines = append(lines, strings.Split(packet, "\n")...)
+	}
+	return lines
+}
@@ -3,6 +3,8 @@ package openrtb_ext
 import (
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"strings"
 )
 
 // ExtBid defines the contract for bidresponse.seatbid.bid[i].ext
@@ -14,15 +16,26 @@ type ExtBid struct {
 // DealPriority represents priority of deal bid. If its non deal bid then value will be 0
 // DealTierSatisfied true represents corresponding bid has satisfied the deal tier
 type ExtBidPrebid struct {
-	Cache             *ExtBidPrebidCache  `json:"cache,omitempty"`
-	DealPriority      int                 `json:"dealpriority,omitempty"`
-	DealTierSatisfied bool                `json:"dealtiersatisfied,omitempty"`
-	Meta              *ExtBidPrebidMeta   `json:"meta,omitempty"`
-	Targeting         map[string]string   `json:"targeting,omitempty"`
-	Type              BidType             `json:"type"`
-	Video             *ExtBidPrebidVideo  `json:"video,omitempty"`
-	Events            *ExtBidPrebidEvents `json:"events,omitempty"`
-	BidId             string              `json:"bidid,omitempty"`
+	Cache             *ExtBidPrebidCache          `json:"cache,omitempty"`
+	DealPriority      int                         `json:"dealpriority,omitempty"`
+	DealTierSatisfied bool                        `json:"dealtiersatisfied,omitempty"`
+	Meta              *ExtBidPrebidMeta           `json:"meta,omitempty"`
+	Targeting         map[string]string           `json:"targeting,omitempty"`
+	Type              BidType                     `json:"type"`
+	Video             *ExtBidPrebidVideo          `json:"video,omitempty"`
+	Events            *ExtBidPrebidEvents         `json:"events,omitempty"`
+	BidId             string                      `json:"bidid,omitempty"`
+	StoredResponse    *ExtBidPrebidStoredResponse `json:"storedresponse,omitempty"`
+}
+
+// ExtBidPrebidStoredResponse defines the contract for bidresponse.seatbid.bid[i].ext.prebid.storedresponse, which is
+// set on the bids coming from a stored response rather than a live bidder call
+type ExtBidPrebidStoredResponse struct {
+	// ID is the id of the stored response the bid comes from
+	ID string `json:"id"`
+	// Type is "auction" for the bids of imp.ext.prebid.storedauctionresponse, and "bid" for those of
+	// imp.ext.prebid.storedbidresponse
+	Type string `json:"type"`
 }
 
 // ExtBidPrebidCache defines the contract for  bidresponse.seatbid.bid[i].ext.prebid.cache
@@ -146,6 +159,17 @@ const (
 	HbCategoryDurationKey TargetingKey = "hb_pb_cat_dur"
 )
 
+// DefaultKeyPrefix is the prefix of the targeting keys, which the accounts may replace
+const DefaultKeyPrefix = "hb"
+
+// WithPrefix returns the key with the given prefix in place of the hb one
+func (key TargetingKey) WithPrefix(prefix string) TargetingKey {
+	if prefix == "" || prefix == DefaultKeyPrefix {
+		return key
+	}
+	return TargetingKey(prefix + strings.TrimPrefix(string(key), DefaultKeyPrefix))
+}
+
 func (key TargetingKey) BidderKey(bidder BidderName, maxLength int) string {
 	s := string(key) + "_" + string(bidder)
 	if maxLength != 0 {
@@ -154,6 +178,21 @@ func (key TargetingKey) BidderKey(bidder BidderName, maxLength int) string {
 	return s
 }
 
+// TruncateKey shortens the keys longer than maxLength, ending them with a hash of the full key so that the truncated
+// keys stay unique. The keys are left as they are if maxLength is 0.
+func TruncateKey(key string, maxLength int) string {
+	if maxLength == 0 || len(key) <= maxLength {
+		return key
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	suffix := fmt.Sprintf("_%04x", hash.Sum32()&0xffff)
+	if maxLength <= len(suffix) {
+		return key[:maxLength]
+	}
+	return key[:maxLength-len(suffix)] + suffix
+}
+
 func min(x, y int) int {
 	if x < y {
 		return x
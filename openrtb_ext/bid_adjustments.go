@@ -0,0 +1,90 @@
+package openrtb_ext
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// mediaTypesKey is the key of bidrequest.ext.prebid.bidadjustmentfactors reserved for the media type factors.
+const mediaTypesKey = "mediatypes"
+
+// ExtBidAdjustmentFactors defines the contract for bidrequest.ext.prebid.bidadjustmentfactors. The factors of each
+// bidder are top level keys, while the "mediatypes" key holds the factors of each bidder by media type:
+//
+//	{"appnexus": 0.9, "mediatypes": {"video": {"appnexus": 0.8}}}
+type ExtBidAdjustmentFactors struct {
+	// Bidders maps bidders to the factor applied to all of their bids.
+	Bidders map[string]float64
+	// MediaTypes maps media types to bidder factors, which take precedence over the factors in Bidders.
+	MediaTypes map[BidType]map[string]float64
+}
+
+// UnmarshalJSON reads the bidder factors and the media type factors from the same object.
+func (f *ExtBidAdjustmentFactors) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	f.Bidders = nil
+	f.MediaTypes = nil
+	for key, value := range raw {
+		if key == mediaTypesKey {
+			if err := json.Unmarshal(value, &f.MediaTypes); err != nil {
+				return fmt.Errorf("bidadjustmentfactors.%s must map media types to bidder factors: %v", mediaTypesKey, err)
+			}
+			continue
+		}
+
+		var factor float64
+		if err := json.Unmarshal(value, &factor); err != nil {
+			return fmt.Errorf("bidadjustmentfactors.%s must be a number", key)
+		}
+		if f.Bidders == nil {
+			f.Bidders = make(map[string]float64, len(raw))
+		}
+		f.Bidders[key] = factor
+	}
+	return nil
+}
+
+// MarshalJSON writes the factors back in the bidrequest.ext.prebid.bidadjustmentfactors format.
+func (f ExtBidAdjustmentFactors) MarshalJSON() ([]byte, error) {
+	raw := make(map[string]interface{}, len(f.Bidders)+1)
+	for bidder, factor := range f.Bidders {
+		raw[bidder] = factor
+	}
+	if len(f.MediaTypes) > 0 {
+		raw[mediaTypesKey] = f.MediaTypes
+	}
+	return json.Marshal(raw)
+}
+
+// Bid adjustment types of bidrequest.ext.prebid.bidadjustments rules
+const (
+	// BidAdjustmentTypeMultiplier multiplies the bid price by the value
+	BidAdjustmentTypeMultiplier = "multiplier"
+	// BidAdjustmentTypeCPM subtracts the value from the bid price
+	BidAdjustmentTypeCPM = "cpm"
+	// BidAdjustmentTypeStatic replaces the bid price with the value
+	BidAdjustmentTypeStatic = "static"
+)
+
+// BidAdjustmentWildcard matches any media type, bidder or deal id in bidrequest.ext.prebid.bidadjustments
+const BidAdjustmentWildcard = "*"
+
+// ExtRequestPrebidBidAdjustments defines the contract for bidrequest.ext.prebid.bidadjustments. The rules are keyed by
+// media type, then by bidder and then by deal id, each of which can be the "*" wildcard:
+//
+//	{"mediatype": {"banner": {"appnexus": {"deal-1": [{"adjtype": "cpm", "value": 0.1, "currency": "USD"}]}}}}
+type ExtRequestPrebidBidAdjustments struct {
+	MediaType map[string]map[string]map[string][]ExtBidAdjustment `json:"mediatype,omitempty"`
+}
+
+// ExtBidAdjustment defines the contract for a single adjustment of bidrequest.ext.prebid.bidadjustments
+type ExtBidAdjustment struct {
+	AdjType string  `json:"adjtype"`
+	Value   float64 `json:"value"`
+	// Currency is the currency of the cpm and static values. Defaults to USD.
+	Currency string `json:"currency,omitempty"`
+}
@@ -0,0 +1,67 @@
+package openrtb_ext
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtBidAdjustmentFactorsUnmarshal(t *testing.T) {
+	testCases := []struct {
+		description     string
+		givenJson       string
+		expectedFactors ExtBidAdjustmentFactors
+		expectedError   string
+	}{
+		{
+			description:     "Bidders only",
+			givenJson:       `{"appnexus":0.9,"rubicon":1.1}`,
+			expectedFactors: ExtBidAdjustmentFactors{Bidders: map[string]float64{"appnexus": 0.9, "rubicon": 1.1}},
+		},
+		{
+			description: "Bidders and media types",
+			givenJson:   `{"appnexus":0.9,"mediatypes":{"video":{"appnexus":0.8,"rubicon":1.2}}}`,
+			expectedFactors: ExtBidAdjustmentFactors{
+				Bidders:    map[string]float64{"appnexus": 0.9},
+				MediaTypes: map[BidType]map[string]float64{BidTypeVideo: {"appnexus": 0.8, "rubicon": 1.2}},
+			},
+		},
+		{
+			description:   "Bidder factor which isn't a number",
+			givenJson:     `{"appnexus":"0.9"}`,
+			expectedError: "bidadjustmentfactors.appnexus must be a number",
+		},
+		{
+			description:   "Malformed media types",
+			givenJson:     `{"mediatypes":{"video":0.8}}`,
+			expectedError: "bidadjustmentfactors.mediatypes must map media types to bidder factors",
+		},
+	}
+
+	for _, test := range testCases {
+		var factors ExtBidAdjustmentFactors
+		err := json.Unmarshal([]byte(test.givenJson), &factors)
+
+		if test.expectedError != "" {
+			if assert.Error(t, err, test.description) {
+				assert.Contains(t, err.Error(), test.expectedError, test.description)
+			}
+		} else {
+			assert.NoError(t, err, test.description)
+			assert.Equal(t, test.expectedFactors, factors, test.description)
+		}
+	}
+}
+
+func TestExtBidAdjustmentFactorsMarshal(t *testing.T) {
+	factors := ExtBidAdjustmentFactors{
+		Bidders:    map[string]float64{"appnexus": 0.9},
+		MediaTypes: map[BidType]map[string]float64{BidTypeBanner: {"appnexus": 0.8}},
+	}
+
+	result, err := json.Marshal(ExtRequestPrebid{BidAdjustmentFactors: &factors})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"bidadjustmentfactors":{"appnexus":0.9,"mediatypes":{"banner":{"appnexus":0.8}}}}`, string(result))
+}
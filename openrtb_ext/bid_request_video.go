@@ -73,7 +73,7 @@ type BidRequestVideo struct {
 	//   object; optional
 	// Description:
 	//  Misc content meta data that can be used for targeting the adPod(s)
-	Content openrtb2.Content `json:"content,omitempty"`
+	Content *openrtb2.Content `json:"content,omitempty"`
 
 	// Attribute:
 	//   cacheconfig
@@ -167,6 +167,14 @@ type PodConfig struct {
 	//  Flag indicating exact ad duration requirement. Default is false.
 	RequireExactDuration bool `json:"requireexactduration,omitempty"`
 
+	// Attribute:
+	//   excludeadvertiserdomains
+	// Type:
+	//   boolean, optional
+	//  Flag indicating that no two ads of the response may share an advertiser domain, whether in the same pod or not.
+	//  Default is false.
+	ExcludeAdvertiserDomains bool `json:"excludeadvertiserdomains,omitempty"`
+
 	// Attribute:
 	//   pods
 	// Type:
@@ -1,6 +1,9 @@
 package openrtb_ext
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestBidderKey(t *testing.T) {
 	apnKey := HbpbConstantKey.BidderKey(BidderAppnexus, 50)
@@ -16,6 +19,35 @@ func TestTruncatedKey(t *testing.T) {
 	}
 }
 
+func TestKeyWithPrefix(t *testing.T) {
+	if key := HbpbConstantKey.WithPrefix("pbs"); key != "pbs_pb" {
+		t.Errorf("Bad prefixed targeting key. Expected pbs_pb, got %s", key)
+	}
+	if key := HbpbConstantKey.WithPrefix(""); key != HbpbConstantKey {
+		t.Errorf("Bad prefixed targeting key. Expected hb_pb, got %s", key)
+	}
+}
+
+func TestTruncateKey(t *testing.T) {
+	if key := TruncateKey("hb_pb_appnexus", 20); key != "hb_pb_appnexus" {
+		t.Errorf("Bad truncated targeting key. Expected hb_pb_appnexus, got %s", key)
+	}
+	if key := TruncateKey("hb_pb_appnexus", 0); key != "hb_pb_appnexus" {
+		t.Errorf("Bad truncated targeting key. Expected hb_pb_appnexus, got %s", key)
+	}
+
+	cacheKey := TruncateKey("hb_cache_id_appnexus", 12)
+	if len(cacheKey) != 12 || !strings.HasPrefix(cacheKey, "hb_cach_") {
+		t.Errorf("Bad truncated targeting key. Expected 12 characters starting with hb_cach_, got %s", cacheKey)
+	}
+	if key := TruncateKey("hb_cache_id_appnexus", 12); key != cacheKey {
+		t.Errorf("Truncated targeting keys are not deterministic. Expected %s, got %s", cacheKey, key)
+	}
+	if key := TruncateKey("hb_cache_host_appnexus", 12); key == cacheKey {
+		t.Errorf("Truncated targeting keys of different keys should differ, got %s for both", key)
+	}
+}
+
 func TestBidParsing(t *testing.T) {
 	assertBidParse(t, "banner", BidTypeBanner)
 	assertBidParse(t, "video", BidTypeVideo)
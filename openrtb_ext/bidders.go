@@ -36,6 +36,7 @@ const (
 	BidderReservedGeneral BidderName = "general" // Reserved for non-bidder specific messages when using a map keyed on the bidder name.
 	BidderReservedPrebid  BidderName = "prebid"  // Reserved for Prebid Server configuration.
 	BidderReservedSKAdN   BidderName = "skadn"   // Reserved for Apple's SKAdNetwork OpenRTB extension.
+	BidderReservedTID     BidderName = "tid"     // Reserved for the imp level transaction id.
 )
 
 // IsBidderNameReserved returns true if the specified name is a case insensitive match for a reserved bidder name.
@@ -64,6 +65,10 @@ func IsBidderNameReserved(name string) bool {
 		return true
 	}
 
+	if strings.EqualFold(name, string(BidderReservedTID)) {
+		return true
+	}
+
 	return false
 }
 
@@ -203,9 +208,9 @@ const (
 	BidderZeroClickFraud    BidderName = "zeroclickfraud"
 )
 
-// CoreBidderNames returns a slice of all core bidders.
+// CoreBidderNames returns a slice of all core bidders, including the bidders onboarded through the host config.
 func CoreBidderNames() []BidderName {
-	return []BidderName{
+	coreBidderNames := []BidderName{
 		Bidder33Across,
 		BidderAceex,
 		BidderAcuityAds,
@@ -335,6 +340,7 @@ func CoreBidderNames() []BidderName {
 		BidderYSSP,
 		BidderZeroClickFraud,
 	}
+	return append(coreBidderNames, genericBidderNames...)
 }
 
 // BuildBidderMap builds a map of string to BidderName, to remain compatbile with the
@@ -381,6 +387,21 @@ func NormalizeBidderName(name string) (BidderName, bool) {
 	return bidderName, exists
 }
 
+// genericBidderNames are the bidders onboarded through the host config, which are served by the generic OpenRTB adapter
+var genericBidderNames []BidderName
+
+// RegisterGenericBidders adds the bidders onboarded through the host config to the core bidders. It must be called at
+// startup, before the core bidders are read. Names which are already those of a core bidder are ignored.
+func RegisterGenericBidders(names []string) {
+	for _, name := range names {
+		if _, exists := NormalizeBidderName(name); exists {
+			continue
+		}
+		genericBidderNames = append(genericBidderNames, BidderName(name))
+		bidderNameLookup[strings.ToLower(name)] = BidderName(name)
+	}
+}
+
 // The BidderParamValidator is used to enforce bidrequest.imp[i].ext.{anyBidder} values.
 //
 // This is treated differently from the other types because we rely on JSON-schemas to validate bidder params.
@@ -438,7 +459,13 @@ type bidderParamValidator struct {
 }
 
 func (validator *bidderParamValidator) Validate(name BidderName, ext json.RawMessage) error {
-	result, err := validator.parsedSchemas[name].Validate(gojsonschema.NewBytesLoader(ext))
+	// Bidders which don't publish a schema have nothing to validate their params against.
+	schema, ok := validator.parsedSchemas[name]
+	if !ok {
+		return nil
+	}
+
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(ext))
 	if err != nil {
 		return err
 	}
@@ -2,6 +2,7 @@ package openrtb_ext
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -74,6 +75,16 @@ func TestBidderParamValidatorValidate(t *testing.T) {
 	}
 }
 
+func TestBidderParamValidatorValidateWithoutSchema(t *testing.T) {
+	testValidator := bidderParamValidator{
+		parsedSchemas: map[BidderName]*gojsonschema.Schema{},
+	}
+
+	err := testValidator.Validate(BidderName("foo"), json.RawMessage(`{"anything":"goes"}`))
+
+	assert.NoError(t, err)
+}
+
 func TestBidderParamValidatorSchema(t *testing.T) {
 	testValidator := bidderParamValidator{
 		schemaContents: map[BidderName]string{
@@ -110,6 +121,8 @@ func TestIsBidderNameReserved(t *testing.T) {
 		{"prebid", true},
 		{"PREbid", true},
 		{"PREBID", true},
+		{"tid", true},
+		{"TID", true},
 		{"notreserved", false},
 	}
 
@@ -118,3 +131,20 @@ func TestIsBidderNameReserved(t *testing.T) {
 		assert.Equal(t, test.expected, result, test.bidder)
 	}
 }
+
+func TestRegisterGenericBidders(t *testing.T) {
+	defer func() {
+		for _, name := range genericBidderNames {
+			delete(bidderNameLookup, strings.ToLower(string(name)))
+		}
+		genericBidderNames = nil
+	}()
+
+	RegisterGenericBidders([]string{"somegenericbidder", "appnexus"})
+
+	bidderName, exists := NormalizeBidderName("someGenericBidder")
+	assert.True(t, exists)
+	assert.Equal(t, BidderName("somegenericbidder"), bidderName)
+	assert.Contains(t, CoreBidderNames(), BidderName("somegenericbidder"))
+	assert.Equal(t, []BidderName{"somegenericbidder"}, genericBidderNames, "core bidders aren't registered again")
+}
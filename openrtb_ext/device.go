@@ -15,6 +15,13 @@ const PrebidExtKey = "prebid"
 // PrebidExtBidderKey represents the field name within request.imp.ext.prebid reserved for bidder params.
 const PrebidExtBidderKey = "bidder"
 
+// PrebidExtStoredAuctionResponseKey and PrebidExtStoredBidResponseKey represent the fields within request.imp.ext.prebid
+// which answer the imp with stored responses.
+const (
+	PrebidExtStoredAuctionResponseKey = "storedauctionresponse"
+	PrebidExtStoredBidResponseKey     = "storedbidresponse"
+)
+
 // ExtDevice defines the contract for bidrequest.device.ext
 type ExtDevice struct {
 	// Attribute:
@@ -70,7 +77,7 @@ type ExtDevicePrebid struct {
 
 // ExtDeviceInt defines the contract for bidrequest.device.ext.prebid.interstitial
 type ExtDeviceInt struct {
-	MinWidthPerc  int64 `json:"minwidtheperc"`
+	MinWidthPerc  int64 `json:"minwidthperc"`
 	MinHeightPerc int64 `json:"minheightperc"`
 }
 
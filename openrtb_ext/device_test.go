@@ -107,3 +107,12 @@ func TestParseDeviceExtATTS(t *testing.T) {
 		assert.Equal(t, test.expectedStatus, status, test.description+":status")
 	}
 }
+
+func TestDeviceExtInterstitialRoundTrip(t *testing.T) {
+	var s ExtDevice
+	assert.NoError(t, json.Unmarshal([]byte(`{"prebid":{"interstitial":{"minwidthperc":60,"minheightperc":70}}}`), &s))
+
+	b, err := json.Marshal(s.Prebid)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"interstitial":{"minwidthperc":60,"minheightperc":70}}`, string(b))
+}
@@ -16,6 +16,12 @@ type ExtImpPrebid struct {
 	Bidder map[string]json.RawMessage `json:"bidder"`
 
 	Options *Options `json:"options,omitempty"`
+
+	// StoredAuctionResponse replaces the bids of every bidder on the impression with stored seatbids, if any.
+	StoredAuctionResponse *ExtStoredAuctionResponse `json:"storedauctionresponse,omitempty"`
+
+	// StoredBidResponse replaces the bids of the listed bidders on the impression with stored seatbids, if any.
+	StoredBidResponse []ExtStoredBidResponse `json:"storedbidresponse,omitempty"`
 }
 
 // ExtStoredRequest defines the contract for bidrequest.imp[i].ext.prebid.storedrequest
@@ -23,6 +29,29 @@ type ExtStoredRequest struct {
 	ID string `json:"id"`
 }
 
+// ExtStoredAuctionResponse defines the contract for bidrequest.imp[i].ext.prebid.storedauctionresponse
+type ExtStoredAuctionResponse struct {
+	ID string `json:"id"`
+}
+
+// ExtStoredBidResponse defines the contract for bidrequest.imp[i].ext.prebid.storedbidresponse
+type ExtStoredBidResponse struct {
+	ID     string `json:"id"`
+	Bidder string `json:"bidder"`
+}
+
 type Options struct {
 	EchoVideoAttrs bool `json:"echovideoattrs"`
 }
+
+// ExtImpVideo defines the contract for bidrequest.imp[i].video.ext, which holds the OpenRTB 2.6 ad pod attributes
+type ExtImpVideo struct {
+	// PodID groups the imps of the same ad pod
+	PodID string `json:"podid,omitempty"`
+
+	// PodDur is the total duration of the ad pod, in seconds
+	PodDur int `json:"poddur,omitempty"`
+
+	// RqdDurs lists the exact durations the ads of the pod must have, in seconds
+	RqdDurs []int `json:"rqddurs,omitempty"`
+}
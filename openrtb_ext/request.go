@@ -3,6 +3,9 @@ package openrtb_ext
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+
+	"github.com/prebid/prebid-server/errortypes"
 )
 
 // FirstPartyDataExtKey defines a field name within request.ext and request.imp.ext reserved for first party data.
@@ -14,6 +17,9 @@ const FirstPartyDataContextExtKey = "context"
 // SKAdNExtKey defines the field name within request.ext reserved for Apple's SKAdNetwork.
 const SKAdNExtKey = "skadn"
 
+// TIDExtKey defines the field name within request.imp.ext reserved for the imp level transaction id.
+const TIDExtKey = "tid"
+
 // NativeExchangeSpecificLowerBound defines the lower threshold of exchange specific types for native ads. There is no upper bound.
 const NativeExchangeSpecificLowerBound = 500
 
@@ -26,24 +32,56 @@ type ExtRequest struct {
 
 // ExtRequestPrebid defines the contract for bidrequest.ext.prebid
 type ExtRequestPrebid struct {
-	Aliases              map[string]string         `json:"aliases,omitempty"`
-	BidAdjustmentFactors map[string]float64        `json:"bidadjustmentfactors,omitempty"`
-	Cache                *ExtRequestPrebidCache    `json:"cache,omitempty"`
-	Channel              *ExtRequestPrebidChannel  `json:"channel,omitempty"`
-	Data                 *ExtRequestPrebidData     `json:"data,omitempty"`
-	Debug                bool                      `json:"debug,omitempty"`
-	Events               json.RawMessage           `json:"events,omitempty"`
-	SChains              []*ExtRequestPrebidSChain `json:"schains,omitempty"`
-	StoredRequest        *ExtStoredRequest         `json:"storedrequest,omitempty"`
-	SupportDeals         bool                      `json:"supportdeals,omitempty"`
-	Targeting            *ExtRequestTargeting      `json:"targeting,omitempty"`
+	Aliases              map[string]string               `json:"aliases,omitempty"`
+	BidAdjustmentFactors *ExtBidAdjustmentFactors        `json:"bidadjustmentfactors,omitempty"`
+	BidAdjustments       *ExtRequestPrebidBidAdjustments `json:"bidadjustments,omitempty"`
+	Cache                *ExtRequestPrebidCache          `json:"cache,omitempty"`
+	Channel              *ExtRequestPrebidChannel        `json:"channel,omitempty"`
+	Data                 *ExtRequestPrebidData           `json:"data,omitempty"`
+	DealsOnly            bool                            `json:"dealsonly,omitempty"`
+	Debug                bool                            `json:"debug,omitempty"`
+	Events               json.RawMessage                 `json:"events,omitempty"`
+	SChains              []*ExtRequestPrebidSChain       `json:"schains,omitempty"`
+	StoredRequest        *ExtStoredRequest               `json:"storedrequest,omitempty"`
+	SupportDeals         bool                            `json:"supportdeals,omitempty"`
+	Targeting            *ExtRequestTargeting            `json:"targeting,omitempty"`
 
 	// NoSale specifies bidders with whom the publisher has a legal relationship where the
 	// passing of personally identifiable information doesn't constitute a sale per CCPA law.
 	// The array may contain a single sstar ('*') entry to represent all bidders.
 	NoSale []string `json:"nosale,omitempty"`
 
+	// ReturnAllBidStatus adds the bids dropped by the exchange to bidresponse.ext.seatnonbid
+	ReturnAllBidStatus bool `json:"returnallbidstatus,omitempty"`
+
+	// Passthrough is returned as is in bidresponse.ext.prebid.passthrough
+	Passthrough json.RawMessage `json:"passthrough,omitempty"`
+
+	// MultiBid lets the listed bidders return several bids per imp
+	MultiBid []*ExtMultiBid `json:"multibid,omitempty"`
+
+	// AliasOverrides changes how the aliases of Aliases are called, by alias
+	AliasOverrides map[string]*ExtAliasOverride `json:"aliasoverrides,omitempty"`
+
+	// BidderConfigs are the first party data of some of the bidders, merged into the site, app and user sent to them
+	BidderConfigs []BidderConfig `json:"bidderconfig,omitempty"`
+
 	CurrencyConversions *ExtRequestCurrency `json:"currency,omitempty"`
+
+	// Analytics are the options of the analytics modules, by module name. The options the host lets the requests
+	// override are merged over the options of the account, and the others are ignored.
+	Analytics map[string]json.RawMessage `json:"analytics,omitempty"`
+
+	// BidderControls are the options of the request for some of the bidders, by bidder
+	BidderControls map[string]ExtBidderControl `json:"biddercontrols,omitempty"`
+}
+
+// ExtBidderControl defines the contract for bidrequest.ext.prebid.biddercontrols.BIDDER
+type ExtBidderControl struct {
+	// PreferredMediaType is the media type kept in the multi-format imps sent to the bidder, if it doesn't support them
+	PreferredMediaType BidType `json:"prefmtype,omitempty"`
+	// PriceGranularity overrides the price granularity for the bids of the bidder, over the one of their media type
+	PriceGranularity *PriceGranularity `json:"pricegranularity,omitempty"`
 }
 
 type ExtRequestCurrency struct {
@@ -51,6 +89,45 @@ type ExtRequestCurrency struct {
 	UsePBSRates     *bool                         `json:"usepbsrates"`
 }
 
+// ExtMultiBid defines the contract for bidrequest.ext.prebid.multibid[i]. It configures either a single Bidder, whose
+// extra bids may be given targeting keys of TargetBidderCodePrefix followed by the rank of the bid, or several Bidders.
+type ExtMultiBid struct {
+	Bidder                 string   `json:"bidder,omitempty"`
+	Bidders                []string `json:"bidders,omitempty"`
+	MaxBids                *int     `json:"maxbids,omitempty"`
+	TargetBidderCodePrefix string   `json:"targetbiddercodeprefix,omitempty"`
+}
+
+// ExtAliasOverride defines the contract for bidrequest.ext.prebid.aliasoverrides.{alias}
+type ExtAliasOverride struct {
+	// Endpoint replaces the endpoint of the core bidder, with the same macros. The host must allow it.
+	Endpoint string `json:"endpoint,omitempty"`
+	// GVLVendorID replaces the GVL vendor ID of the core bidder in the GDPR enforcement
+	GVLVendorID uint16 `json:"gvlvendorid,omitempty"`
+	// Params are the default bidder params of the alias. The params of each imp take precedence over them.
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// BidderConfig defines the contract for bidrequest.ext.prebid.bidderconfig[i]. The bidders may hold a single "*"
+// entry for all the bidders without a bidder config of their own.
+type BidderConfig struct {
+	Bidders []string `json:"bidders,omitempty"`
+	Config  *Config  `json:"config,omitempty"`
+}
+
+// Config defines the contract for bidrequest.ext.prebid.bidderconfig[i].config
+type Config struct {
+	ORTB2 *ORTB2 `json:"ortb2,omitempty"`
+}
+
+// ORTB2 defines the contract for bidrequest.ext.prebid.bidderconfig[i].config.ortb2. Each object is merged into the
+// matching object of the request.
+type ORTB2 struct {
+	Site json.RawMessage `json:"site,omitempty"`
+	App  json.RawMessage `json:"app,omitempty"`
+	User json.RawMessage `json:"user,omitempty"`
+}
+
 // ExtRequestPrebid defines the contract for bidrequest.ext.prebid.schains
 type ExtRequestPrebidSChain struct {
 	Bidders []string                     `json:"bidders,omitempty"`
@@ -91,6 +168,9 @@ type ExtRequestPrebidChannel struct {
 type ExtRequestPrebidCache struct {
 	Bids    *ExtRequestPrebidCacheBids `json:"bids"`
 	VastXML *ExtRequestPrebidCacheVAST `json:"vastxml"`
+	// Key is the cache key of the publisher, which starts the cache keys of the video bids with a category, followed by a
+	// suffix of each auction so that the keys stay unique
+	Key string `json:"key,omitempty"`
 }
 
 // UnmarshalJSON prevents nil bids arguments.
@@ -104,6 +184,11 @@ func (ert *ExtRequestPrebidCache) UnmarshalJSON(b []byte) error {
 	if proxy.Bids == nil && proxy.VastXML == nil {
 		return errors.New(`request.ext.prebid.cache requires one of the "bids" or "vastxml" properties`)
 	}
+	for _, c := range proxy.Key {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return errors.New("request.ext.prebid.cache.key must only have letters, digits, dashes and underscores")
+		}
+	}
 
 	*ert = ExtRequestPrebidCache(proxy)
 	return nil
@@ -129,6 +214,34 @@ type ExtRequestTargeting struct {
 	DurationRangeSec     []int                    `json:"durationrangesec"`
 	PreferDeals          bool                     `json:"preferdeals"`
 	AppendBidderNames    bool                     `json:"appendbiddernames,omitempty"`
+	// MediaTypePriceGranularity overrides the price granularity for the bids of some media types
+	MediaTypePriceGranularity *MediaTypePriceGranularity `json:"mediatypepricegranularity,omitempty"`
+}
+
+// MediaTypePriceGranularity defines the contract for bidrequest.ext.prebid.targeting.mediatypepricegranularity
+type MediaTypePriceGranularity struct {
+	Banner *PriceGranularity `json:"banner,omitempty"`
+	Video  *PriceGranularity `json:"video,omitempty"`
+	Audio  *PriceGranularity `json:"audio,omitempty"`
+	Native *PriceGranularity `json:"native,omitempty"`
+}
+
+// ForBidType returns the price granularity of the bids of the media type, or nil if it isn't overridden
+func (mpg *MediaTypePriceGranularity) ForBidType(bidType BidType) *PriceGranularity {
+	if mpg == nil {
+		return nil
+	}
+	switch bidType {
+	case BidTypeBanner:
+		return mpg.Banner
+	case BidTypeVideo:
+		return mpg.Video
+	case BidTypeAudio:
+		return mpg.Audio
+	case BidTypeNative:
+		return mpg.Native
+	}
+	return nil
 }
 
 type ExtIncludeBrandCategory struct {
@@ -136,6 +249,9 @@ type ExtIncludeBrandCategory struct {
 	Publisher           string `json:"publisher"`
 	WithCategory        bool   `json:"withcategory"`
 	TranslateCategories *bool  `json:"translatecategories,omitempty"`
+	// ExcludeAdvertiserDomains extends the competitive exclusion to the advertiser domains of the bids, so that no two
+	// bids sharing an adomain make it to the response, across the pods too
+	ExcludeAdvertiserDomains bool `json:"excludeadvertiserdomains,omitempty"`
 }
 
 // Make an unmarshaller that will set a default PriceGranularity
@@ -169,7 +285,31 @@ type PriceGranularity struct {
 	Ranges    []GranularityRange `json:"ranges,omitempty"`
 }
 
-type PriceGranularityRaw PriceGranularity
+type priceGranularityRaw struct {
+	Precision int                   `json:"precision,omitempty"`
+	Ranges    []granularityRangeRaw `json:"ranges,omitempty"`
+}
+
+// granularityRangeRaw keeps track of whether "min" was supplied, so that overlapping ranges and gaps can be detected
+type granularityRangeRaw struct {
+	Min       *float64 `json:"min"`
+	Max       float64  `json:"max"`
+	Increment float64  `json:"increment"`
+}
+
+// Gaps returns the indexes of the ranges which don't start where the previous range ended. Bids priced
+// within a gap don't fall into any range, so they get no price bucket.
+func (pg PriceGranularity) Gaps() []int {
+	var gaps []int
+	var prevMax float64 = 0
+	for i, gr := range pg.Ranges {
+		if gr.Min > prevMax {
+			gaps = append(gaps, i)
+		}
+		prevMax = gr.Max
+	}
+	return gaps
+}
 
 // GranularityRange struct defines a range of prices used by PriceGranularity
 type GranularityRange struct {
@@ -197,32 +337,43 @@ func (pg *PriceGranularity) UnmarshalJSON(b []byte) error {
 		}
 	}
 	// Not legacy, so we do a normal Unmarshal
-	pgraw := PriceGranularityRaw{}
+	pgraw := priceGranularityRaw{}
 	pgraw.Precision = 2
 	err = json.Unmarshal(b, &pgraw)
 	if err != nil {
 		return err
 	}
 	if pgraw.Precision < 0 {
-		return errors.New("Price granularity error: precision must be non-negative")
+		return &errortypes.BadInput{Message: "Price granularity error: precision must be non-negative"}
 	}
 	if pgraw.Precision > MaxDecimalFigures {
-		return errors.New("Price granularity error: precision of more than 15 significant figures is not supported")
+		return &errortypes.BadInput{Message: "Price granularity error: precision of more than 15 significant figures is not supported"}
 	}
 	if len(pgraw.Ranges) > 0 {
+		ranges := make([]GranularityRange, 0, len(pgraw.Ranges))
 		var prevMax float64 = 0
 		for i, gr := range pgraw.Ranges {
 			if gr.Max <= prevMax {
-				return errors.New("Price granularity error: range list must be ordered with increasing \"max\"")
+				return &errortypes.BadInput{Message: "Price granularity error: range list must be ordered with increasing \"max\""}
 			}
 			if gr.Increment <= 0.0 {
-				return errors.New("Price granularity error: increment must be a nonzero positive number")
+				return &errortypes.BadInput{Message: "Price granularity error: increment must be a nonzero positive number"}
+			}
+			// A range without "min" starts where the previous one ended
+			min := prevMax
+			if gr.Min != nil {
+				min = *gr.Min
+				if min < prevMax {
+					return &errortypes.BadInput{Message: fmt.Sprintf("Price granularity error: range %d overlaps the previous range", i)}
+				}
+				if min >= gr.Max {
+					return &errortypes.BadInput{Message: fmt.Sprintf("Price granularity error: range %d must have \"min\" lower than \"max\"", i)}
+				}
 			}
-			// Enforce that we don't read "min" from the request
-			pgraw.Ranges[i].Min = prevMax
+			ranges = append(ranges, GranularityRange{Min: min, Max: gr.Max, Increment: gr.Increment})
 			prevMax = gr.Max
 		}
-		*pg = PriceGranularity(pgraw)
+		*pg = PriceGranularity{Precision: pgraw.Precision, Ranges: ranges}
 		return nil
 	}
 	// Default to medium if no ranges are specified
@@ -318,6 +469,9 @@ var priceGranularityAuto = PriceGranularity{
 // ExtRequestPrebidData defines Prebid's First Party Data (FPD) and related bid request options.
 type ExtRequestPrebidData struct {
 	EidPermissions []ExtRequestPrebidDataEidPermission `json:"eidpermissions"`
+	// Bidders are the only bidders sent the global first party data of site.ext.data, site.content.data,
+	// app.ext.data, app.content.data, user.ext.data and user.data. All the bidders are sent it when empty.
+	Bidders []string `json:"bidders,omitempty"`
 }
 
 // ExtRequestPrebidDataEidPermission defines a filter rule for filter user.ext.eids
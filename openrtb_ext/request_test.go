@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/prebid/prebid-server/errortypes"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -96,6 +97,13 @@ func TestCacheVast(t *testing.T) {
 	assert.NotNil(t, bids.VastXML)
 }
 
+func TestCacheKey(t *testing.T) {
+	var bids ExtRequestPrebidCache
+	assert.NoError(t, json.Unmarshal([]byte(`{"vastxml":{},"key":"pub_key-1"}`), &bids))
+	assert.Equal(t, "pub_key-1", bids.Key)
+	assert.EqualError(t, json.Unmarshal([]byte(`{"vastxml":{},"key":"pub/key"}`), &bids), "request.ext.prebid.cache.key must only have letters, digits, dashes and underscores")
+}
+
 func TestCacheNothing(t *testing.T) {
 	var bids ExtRequestPrebidCache
 	assert.Error(t, json.Unmarshal([]byte(`{}`), &bids))
@@ -164,17 +172,6 @@ var validGranularityTests []granularityTestData = []granularityTestData{
 			},
 		},
 	},
-	{
-		json: []byte(`{"precision": 2, "ranges": [{"min": 0.5, "max":5, "increment": 0.1}, {"min": 54, "max": 10, "increment": 1}, {"min": -42, "max": 20, "increment": 5}]}`),
-		target: PriceGranularity{
-			Precision: 2,
-			Ranges: []GranularityRange{
-				{Min: 0.0, Max: 5.0, Increment: 0.1},
-				{Min: 5.0, Max: 10.0, Increment: 1.0},
-				{Min: 10.0, Max: 20.0, Increment: 5.0},
-			},
-		},
-	},
 	{
 		json:   []byte(`{}`),
 		target: priceGranularityMed,
@@ -189,6 +186,61 @@ var validGranularityTests []granularityTestData = []granularityTestData{
 	},
 }
 
+func TestGranularityUnmarshalRanges(t *testing.T) {
+	testCases := []struct {
+		description         string
+		json                []byte
+		expectedGranularity PriceGranularity
+		expectedGaps        []int
+		expectedError       error
+	}{
+		{
+			description: "Contiguous ranges",
+			json:        []byte(`{"ranges":[{"min": 0, "max":5, "increment": 0.1}, {"min": 5, "max": 10, "increment": 0.5}]}`),
+			expectedGranularity: PriceGranularity{
+				Precision: 2,
+				Ranges: []GranularityRange{
+					{Min: 0.0, Max: 5.0, Increment: 0.1},
+					{Min: 5.0, Max: 10.0, Increment: 0.5},
+				},
+			},
+		},
+		{
+			description: "Ranges with a gap",
+			json:        []byte(`{"ranges":[{"max":5, "increment": 0.1}, {"min": 6, "max": 10, "increment": 0.5}]}`),
+			expectedGranularity: PriceGranularity{
+				Precision: 2,
+				Ranges: []GranularityRange{
+					{Min: 0.0, Max: 5.0, Increment: 0.1},
+					{Min: 6.0, Max: 10.0, Increment: 0.5},
+				},
+			},
+			expectedGaps: []int{1},
+		},
+		{
+			description:   "Overlapping ranges",
+			json:          []byte(`{"ranges":[{"max":5, "increment": 0.1}, {"min": 4.5, "max": 10, "increment": 0.5}]}`),
+			expectedError: &errortypes.BadInput{Message: "Price granularity error: range 1 overlaps the previous range"},
+		},
+		{
+			description:   "Negative increment",
+			json:          []byte(`{"ranges":[{"max":5, "increment": -0.1}]}`),
+			expectedError: &errortypes.BadInput{Message: "Price granularity error: increment must be a nonzero positive number"},
+		},
+	}
+
+	for _, test := range testCases {
+		var resolved PriceGranularity
+		err := json.Unmarshal(test.json, &resolved)
+
+		assert.Equal(t, test.expectedError, err, test.description+":err")
+		if test.expectedError == nil {
+			assert.Equal(t, test.expectedGranularity, resolved, test.description+":granularity")
+			assert.Equal(t, test.expectedGaps, resolved.Gaps(), test.description+":gaps")
+		}
+	}
+}
+
 func TestGranularityUnmarshalBad(t *testing.T) {
 	testCases := []struct {
 		description          string
@@ -222,6 +274,14 @@ func TestGranularityUnmarshalBad(t *testing.T) {
 			"Max equal to previous max",
 			[]byte(`{"ranges":[{"max":1.0, "increment": 0.07}, {"max" 1.0, "increment": 0.03}]}`),
 		},
+		{
+			"Overlapping ranges",
+			[]byte(`{"ranges":[{"min": 0, "max":5, "increment": 0.1}, {"min": 4, "max": 10, "increment": 1}]}`),
+		},
+		{
+			"Min greater than max",
+			[]byte(`{"ranges":[{"min": 0.5, "max":5, "increment": 0.1}, {"min": 54, "max": 10, "increment": 1}]}`),
+		},
 	}
 
 	for _, test := range testCases {
@@ -230,3 +290,19 @@ func TestGranularityUnmarshalBad(t *testing.T) {
 		assert.Errorf(t, err, "Invalid granularity unmarshalled without error.\nJSON was: %s\n Resolved to: %v. Test: %s", string(test.jsonPriceGranularity), resolved, test.description)
 	}
 }
+
+func TestMediaTypePriceGranularityUnmarshal(t *testing.T) {
+	targeting := ExtRequestTargeting{}
+	err := json.Unmarshal([]byte(`{"pricegranularity":"low","mediatypepricegranularity":{"video":"high","banner":{"precision":1,"ranges":[{"max":10,"increment":0.5}]}}}`), &targeting)
+
+	assert.NoError(t, err)
+	assert.Equal(t, PriceGranularityFromString("low"), targeting.PriceGranularity)
+	assert.Equal(t, &MediaTypePriceGranularity{
+		Banner: &PriceGranularity{Precision: 1, Ranges: []GranularityRange{{Min: 0, Max: 10, Increment: 0.5}}},
+		Video:  &priceGranularityHigh,
+	}, targeting.MediaTypePriceGranularity)
+	assert.Nil(t, targeting.MediaTypePriceGranularity.ForBidType(BidTypeNative))
+
+	err = json.Unmarshal([]byte(`{"mediatypepricegranularity":{"video":{"ranges":[{"max":10,"increment":-1}]}}}`), &targeting)
+	assert.Error(t, err, "Invalid media type granularity unmarshalled without error")
+}
@@ -1,6 +1,10 @@
 package openrtb_ext
 
-import "github.com/mxmCherry/openrtb/v15/openrtb2"
+import (
+	"encoding/json"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+)
 
 // ExtBidResponse defines the contract for bidresponse.ext
 type ExtBidResponse struct {
@@ -18,8 +22,58 @@ type ExtBidResponse struct {
 	Usersync map[BidderName]*ExtResponseSyncData `json:"usersync,omitempty"`
 	// Prebid defines the contract for bidresponse.ext.prebid
 	Prebid *ExtResponsePrebid `json:"prebid,omitempty"`
+	// SeatNonBid lists the bids which were dropped, if requested with bidrequest.ext.prebid.returnallbidstatus
+	SeatNonBid []SeatNonBid `json:"seatnonbid,omitempty"`
+}
+
+// SeatNonBid defines the contract for bidresponse.ext.seatnonbid[i]
+type SeatNonBid struct {
+	Seat   string   `json:"seat"`
+	NonBid []NonBid `json:"nonbid"`
+}
+
+// NonBid defines the contract for bidresponse.ext.seatnonbid[i].nonbid[j]
+type NonBid struct {
+	ImpId      string           `json:"impid"`
+	StatusCode NonBidStatusCode `json:"statuscode"`
+	Ext        NonBidExt        `json:"ext"`
+}
+
+// NonBidExt defines the contract for bidresponse.ext.seatnonbid[i].nonbid[j].ext
+type NonBidExt struct {
+	Prebid NonBidExtPrebid `json:"prebid"`
+}
+
+// NonBidExtPrebid defines the contract for bidresponse.ext.seatnonbid[i].nonbid[j].ext.prebid
+type NonBidExtPrebid struct {
+	Bid NonBidExtPrebidBid `json:"bid"`
+}
+
+// NonBidExtPrebidBid holds the fields of a dropped bid which help telling why it was dropped
+type NonBidExtPrebidBid struct {
+	Price   float64  `json:"price,omitempty"`
+	ADomain []string `json:"adomain,omitempty"`
+	CrID    string   `json:"crid,omitempty"`
+	DealID  string   `json:"dealid,omitempty"`
+	W       int64    `json:"w,omitempty"`
+	H       int64    `json:"h,omitempty"`
 }
 
+// NonBidStatusCode is the standardized reason a bid was dropped
+type NonBidStatusCode int
+
+// Seat non bid status codes
+const (
+	NonBidErrorGeneral                           NonBidStatusCode = 100
+	NonBidErrorTimedOut                          NonBidStatusCode = 101
+	NonBidRequestBlockedPrivacy                  NonBidStatusCode = 204
+	NonBidResponseRejectedGeneral                NonBidStatusCode = 300
+	NonBidResponseRejectedBelowFloor             NonBidStatusCode = 301
+	NonBidResponseRejectedInvalidCreative        NonBidStatusCode = 350
+	NonBidResponseRejectedCreativeSizeNotAllowed NonBidStatusCode = 351
+	NonBidResponseRejectedCreativeNotSecure      NonBidStatusCode = 352
+)
+
 // ExtResponseDebug defines the contract for bidresponse.ext.debug
 type ExtResponseDebug struct {
 	// HttpCalls defines the contract for bidresponse.ext.debug.httpcalls
@@ -38,8 +92,26 @@ type ExtResponseSyncData struct {
 // ExtResponsePrebid defines the contract for bidresponse.ext.prebid
 type ExtResponsePrebid struct {
 	AuctionTimestamp int64 `json:"auctiontimestamp,omitempty"`
+	// Passthrough echoes bidrequest.ext.prebid.passthrough, along with the traffic shaping of the request
+	Passthrough json.RawMessage `json:"passthrough,omitempty"`
 }
 
+// ExtTrafficShaping defines the contract for bidresponse.ext.prebid.passthrough.trafficshaping. It maps the shaped
+// bidders to the group the request was assigned to.
+type ExtTrafficShaping map[string]TrafficShapingGroup
+
+// TrafficShapingGroup tells whether a request was sent to a shaped bidder
+type TrafficShapingGroup string
+
+// Groups a request is assigned to by the traffic shaping of a bidder
+const (
+	TrafficShapingIncluded TrafficShapingGroup = "included"
+	TrafficShapingExcluded TrafficShapingGroup = "excluded"
+)
+
+// TrafficShapingPassthroughKey is the key of the traffic shaping in bidresponse.ext.prebid.passthrough
+const TrafficShapingPassthroughKey = "trafficshaping"
+
 // ExtUserSync defines the contract for bidresponse.ext.usersync.{bidder}.syncs[i]
 type ExtUserSync struct {
 	Url  string       `json:"url"`
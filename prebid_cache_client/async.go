@@ -0,0 +1,160 @@
+package prebid_cache_client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+)
+
+// AsyncClient returns the keys of the values without waiting for Prebid Cache, and writes the values in batches in
+// the background. The keys are generated up front for the values without one, so Prebid Cache must allow the keys
+// to be set. It writes a batch once it is full, and writes the values batched so far at every flush interval, so no
+// value waits longer than the flush interval.
+type AsyncClient struct {
+	client        Client
+	metrics       metrics.MetricsEngine
+	batchSize     int
+	flushInterval time.Duration
+	timeout       time.Duration
+	values        chan Cacheable
+	done          chan struct{}
+
+	closeMutex sync.RWMutex
+	closed     bool
+}
+
+// NewAsyncClient returns a client writing the values with the client in the background
+func NewAsyncClient(client Client, cfg config.AsyncCacheWrites, metrics metrics.MetricsEngine) *AsyncClient {
+	c := &AsyncClient{
+		client:        client,
+		metrics:       metrics,
+		batchSize:     cfg.BatchSize,
+		flushInterval: time.Duration(cfg.FlushIntervalMs) * time.Millisecond,
+		timeout:       time.Duration(cfg.TimeoutMs) * time.Millisecond,
+		values:        make(chan Cacheable, cfg.BufferSize),
+		done:          make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+// PutJson queues the values to be written, and returns the keys they will be stored under. The values which could
+// not be queued, because the buffer is full, get an empty key.
+func (c *AsyncClient) PutJson(ctx context.Context, values []Cacheable) (uuids []string, errs []error) {
+	errs = make([]error, 0, 1)
+	if len(values) < 1 {
+		return nil, errs
+	}
+
+	c.closeMutex.RLock()
+	defer c.closeMutex.RUnlock()
+
+	uuids = make([]string, len(values))
+	dropped := 0
+	for i, value := range values {
+		if c.closed {
+			dropped++
+			continue
+		}
+		if value.Key == "" {
+			// The keys are random rather than derived from the values, since Prebid Cache refuses the keys it
+			// already has, and the identical values of different auctions would share a derived key
+			rawUUID, err := uuid.NewV4()
+			if err != nil {
+				logError(&errs, "Error generating the Prebid Cache key of the value %d: %v", i, err)
+				continue
+			}
+			value.Key = rawUUID.String()
+		}
+
+		select {
+		case c.values <- value:
+			uuids[i] = value.Key
+		default:
+			dropped++
+		}
+	}
+
+	if dropped > 0 {
+		c.metrics.RecordPrebidCacheAsyncWrite(metrics.CacheWriteDropped, dropped)
+		logError(&errs, "Prebid Cache writes buffer is full, %d of %d values were dropped", dropped, len(values))
+	}
+	return uuids, errs
+}
+
+// GetExtCacheData returns the externally accessible cache url of the client writing the values
+func (c *AsyncClient) GetExtCacheData() (string, string, string) {
+	return c.client.GetExtCacheData()
+}
+
+func (c *AsyncClient) run() {
+	defer close(c.done)
+	ticker := time.NewTicker(c.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Cacheable, 0, c.batchSize)
+	for {
+		select {
+		case value, ok := <-c.values:
+			if !ok {
+				c.write(batch)
+				return
+			}
+			batch = append(batch, value)
+			if len(batch) < c.batchSize {
+				continue
+			}
+		case <-ticker.C:
+		}
+		c.write(batch)
+		batch = batch[:0]
+	}
+}
+
+func (c *AsyncClient) write(batch []Cacheable) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	uuids, _ := c.client.PutJson(ctx, batch)
+
+	// The client logs its errors, and returns an empty uuid for every value it could not store
+	stored := 0
+	for _, uuid := range uuids {
+		if uuid != "" {
+			stored++
+		}
+	}
+	if stored > 0 {
+		c.metrics.RecordPrebidCacheAsyncWrite(metrics.CacheWriteOK, stored)
+	}
+	if failed := len(batch) - stored; failed > 0 {
+		c.metrics.RecordPrebidCacheAsyncWrite(metrics.CacheWriteFailed, failed)
+		glog.Errorf("Prebid Cache did not store %d of %d values written in the background", failed, len(batch))
+	}
+}
+
+// Shutdown writes the queued values before stopping
+func (c *AsyncClient) Shutdown() {
+	if c == nil {
+		return
+	}
+
+	c.closeMutex.Lock()
+	if c.closed {
+		c.closeMutex.Unlock()
+		return
+	}
+	c.closed = true
+	close(c.values)
+	c.closeMutex.Unlock()
+
+	<-c.done
+}
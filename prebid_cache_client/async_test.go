@@ -0,0 +1,117 @@
+package prebid_cache_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type fakeBatchClient struct {
+	mutex   sync.Mutex
+	batches [][]Cacheable
+	fail    map[string]bool
+	block   chan struct{}
+}
+
+func (c *fakeBatchClient) PutJson(ctx context.Context, values []Cacheable) ([]string, []error) {
+	if c.block != nil {
+		<-c.block
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.batches = append(c.batches, values)
+
+	uuids := make([]string, len(values))
+	for i, value := range values {
+		if !c.fail[value.Key] {
+			uuids[i] = value.Key
+		}
+	}
+	return uuids, nil
+}
+
+func (c *fakeBatchClient) GetExtCacheData() (string, string, string) {
+	return "https", "pbs.prebid.org", "/cache"
+}
+
+func TestAsyncClientPutJson(t *testing.T) {
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordPrebidCacheAsyncWrite", metrics.CacheWriteOK, 2).Once()
+	metricsMock.On("RecordPrebidCacheAsyncWrite", metrics.CacheWriteFailed, 1).Once()
+	fakeClient := &fakeBatchClient{fail: map[string]bool{"failing": true}}
+	client := NewAsyncClient(fakeClient, config.AsyncCacheWrites{BatchSize: 3, FlushIntervalMs: 60000, TimeoutMs: 1000, BufferSize: 10}, metricsMock)
+
+	uuids, errs := client.PutJson(context.Background(), []Cacheable{
+		{Type: TypeJSON, Data: json.RawMessage(`{"id":"bid1"}`)},
+		{Type: TypeXML, Data: json.RawMessage(`"<VAST></VAST>"`), Key: "11_sports_22_key"},
+		{Type: TypeJSON, Data: json.RawMessage(`{"id":"bid2"}`), Key: "failing"},
+	})
+	client.Shutdown()
+
+	assert.Empty(t, errs)
+	if assert.Len(t, uuids, 3) {
+		assert.NotEmpty(t, uuids[0])
+		assert.Equal(t, "11_sports_22_key", uuids[1])
+		assert.Equal(t, "failing", uuids[2])
+	}
+	if assert.Len(t, fakeClient.batches, 1, "the values are written in a single batch") {
+		assert.Equal(t, uuids[0], fakeClient.batches[0][0].Key, "the values are written under the returned keys")
+	}
+	metricsMock.AssertExpectations(t)
+
+	scheme, host, path := client.GetExtCacheData()
+	assert.Equal(t, "https", scheme)
+	assert.Equal(t, "pbs.prebid.org", host)
+	assert.Equal(t, "/cache", path)
+}
+
+func TestAsyncClientBatches(t *testing.T) {
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordPrebidCacheAsyncWrite", metrics.CacheWriteOK, mock.Anything)
+	fakeClient := &fakeBatchClient{}
+	client := NewAsyncClient(fakeClient, config.AsyncCacheWrites{BatchSize: 2, FlushIntervalMs: 60000, TimeoutMs: 1000, BufferSize: 10}, metricsMock)
+
+	for i := 0; i < 5; i++ {
+		client.PutJson(context.Background(), []Cacheable{{Type: TypeJSON, Data: json.RawMessage(`{}`)}})
+	}
+	client.Shutdown()
+
+	if assert.Len(t, fakeClient.batches, 3, "the full batches are written, and the last one on shutdown") {
+		assert.Len(t, fakeClient.batches[0], 2)
+		assert.Len(t, fakeClient.batches[1], 2)
+		assert.Len(t, fakeClient.batches[2], 1)
+	}
+}
+
+func TestAsyncClientBufferFull(t *testing.T) {
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordPrebidCacheAsyncWrite", metrics.CacheWriteDropped, 1).Once()
+	metricsMock.On("RecordPrebidCacheAsyncWrite", metrics.CacheWriteOK, mock.Anything)
+	fakeClient := &fakeBatchClient{block: make(chan struct{})}
+	client := NewAsyncClient(fakeClient, config.AsyncCacheWrites{BatchSize: 1, FlushIntervalMs: 60000, TimeoutMs: 1000, BufferSize: 1}, metricsMock)
+
+	// The writer holds the first value until unblocked, so the buffer only has room for the second one
+	client.PutJson(context.Background(), []Cacheable{{Type: TypeJSON, Data: json.RawMessage(`{}`)}})
+	assert.Eventually(t, func() bool { return len(client.values) == 0 }, time.Second, time.Millisecond)
+	uuids, errs := client.PutJson(context.Background(), []Cacheable{
+		{Type: TypeJSON, Data: json.RawMessage(`{}`)},
+		{Type: TypeJSON, Data: json.RawMessage(`{}`)},
+	})
+	close(fakeClient.block)
+	client.Shutdown()
+
+	if assert.Len(t, uuids, 2) {
+		assert.NotEmpty(t, uuids[0])
+		assert.Empty(t, uuids[1])
+	}
+	assert.Equal(t, []error{errors.New("Prebid Cache writes buffer is full, 1 of 2 values were dropped")}, errs)
+	metricsMock.AssertExpectations(t)
+}
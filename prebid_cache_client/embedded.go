@@ -0,0 +1,142 @@
+package prebid_cache_client
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/coocood/freecache"
+	"github.com/gofrs/uuid"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+)
+
+// EmbeddedCache stores the values of Prebid Server in its own memory, and serves them from /cache like Prebid Cache
+// does. The values are evicted once their TTL expires, or the least recently used ones once the cache is full.
+type EmbeddedCache struct {
+	lru               *freecache.Cache
+	defaultTTLSeconds int64
+	maxTTLSeconds     int64
+
+	// keysMutex makes the check of a key and the store under it atomic, so a key is only taken once
+	keysMutex sync.Mutex
+}
+
+// The values are stored after a byte of their type, which is served with them
+const (
+	embeddedJSON byte = 'j'
+	embeddedXML  byte = 'x'
+)
+
+func NewEmbeddedCache(cfg config.EmbeddedCache) *EmbeddedCache {
+	return &EmbeddedCache{
+		lru:               freecache.NewCache(cfg.SizeBytes),
+		defaultTTLSeconds: int64(cfg.DefaultTTLSeconds),
+		maxTTLSeconds:     int64(cfg.MaxTTLSeconds),
+	}
+}
+
+// Put stores the values, under their key if they have one and a new uuid otherwise. The returned slice has the uuid
+// of every value, or an empty string for the values which could not be stored.
+func (c *EmbeddedCache) Put(values []Cacheable) []string {
+	uuids := make([]string, len(values))
+	for i, value := range values {
+		uuids[i] = c.put(value)
+	}
+	return uuids
+}
+
+func (c *EmbeddedCache) put(value Cacheable) string {
+	var data []byte
+	switch value.Type {
+	case TypeJSON:
+		data = append([]byte{embeddedJSON}, value.Data...)
+	case TypeXML:
+		// The XML values are sent as JSON strings, but served as they are
+		var xml string
+		if err := json.Unmarshal(value.Data, &xml); err != nil {
+			return ""
+		}
+		data = append([]byte{embeddedXML}, xml...)
+	default:
+		return ""
+	}
+
+	key := value.Key
+	if key == "" {
+		rawUUID, err := uuid.NewV4()
+		if err != nil {
+			return ""
+		}
+		key = rawUUID.String()
+	} else {
+		c.keysMutex.Lock()
+		defer c.keysMutex.Unlock()
+		if _, err := c.lru.Get([]byte(key)); err == nil {
+			// Like Prebid Cache, the values under a key already taken are not stored
+			return ""
+		}
+	}
+
+	ttl := value.TTLSeconds
+	if ttl <= 0 {
+		ttl = c.defaultTTLSeconds
+	}
+	if c.maxTTLSeconds > 0 && ttl > c.maxTTLSeconds {
+		ttl = c.maxTTLSeconds
+	}
+	if err := c.lru.Set([]byte(key), data, int(ttl)); err != nil {
+		return ""
+	}
+	return key
+}
+
+// Get returns the value stored under the uuid, and its type
+func (c *EmbeddedCache) Get(uuid string) (PayloadType, []byte, bool) {
+	data, err := c.lru.Get([]byte(uuid))
+	if err != nil || len(data) == 0 {
+		return "", nil, false
+	}
+	if data[0] == embeddedXML {
+		return TypeXML, data[1:], true
+	}
+	return TypeJSON, data[1:], true
+}
+
+// NewEmbeddedClient returns a client storing the values in the embedded cache, with no request to a Prebid Cache.
+// The external cache should point to the /cache endpoint of Prebid Server.
+func NewEmbeddedClient(cache *EmbeddedCache, extCache *config.ExternalCache, metrics metrics.MetricsEngine) Client {
+	return &embeddedClient{
+		clientImpl: &clientImpl{
+			externalCacheScheme: extCache.Scheme,
+			externalCacheHost:   extCache.Host,
+			externalCachePath:   extCache.Path,
+			metrics:             metrics,
+		},
+		cache: cache,
+	}
+}
+
+type embeddedClient struct {
+	*clientImpl
+	cache *EmbeddedCache
+}
+
+func (c *embeddedClient) PutJson(ctx context.Context, values []Cacheable) (uuids []string, errs []error) {
+	errs = make([]error, 0, 1)
+	if len(values) < 1 {
+		return nil, errs
+	}
+
+	startTime := time.Now()
+	uuids = c.cache.Put(values)
+	c.metrics.RecordPrebidCacheRequestTime(true, time.Since(startTime))
+
+	for i, uuid := range uuids {
+		if uuid == "" {
+			logError(&errs, "The embedded cache could not store the value %d of type %s", i, values[i].Type)
+		}
+	}
+	return uuids, errs
+}
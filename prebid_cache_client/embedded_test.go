@@ -0,0 +1,130 @@
+package prebid_cache_client
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestEmbeddedCache() *EmbeddedCache {
+	return NewEmbeddedCache(config.EmbeddedCache{Enabled: true, SizeBytes: 1024 * 1024, DefaultTTLSeconds: 300, MaxTTLSeconds: 3600})
+}
+
+func TestEmbeddedCachePutAndGet(t *testing.T) {
+	cache := newTestEmbeddedCache()
+
+	uuids := cache.Put([]Cacheable{
+		{Type: TypeJSON, Data: json.RawMessage(`{"id":"bid1"}`)},
+		{Type: TypeXML, Data: json.RawMessage(`"<VAST version=\"3.0\"></VAST>"`), Key: "11_sports_22_key"},
+	})
+
+	if assert.Len(t, uuids, 2) {
+		assert.NotEmpty(t, uuids[0])
+		assert.Equal(t, "11_sports_22_key", uuids[1])
+
+		payloadType, data, found := cache.Get(uuids[0])
+		assert.True(t, found)
+		assert.Equal(t, TypeJSON, payloadType)
+		assert.Equal(t, `{"id":"bid1"}`, string(data))
+
+		payloadType, data, found = cache.Get(uuids[1])
+		assert.True(t, found)
+		assert.Equal(t, TypeXML, payloadType)
+		assert.Equal(t, `<VAST version="3.0"></VAST>`, string(data))
+	}
+
+	_, _, found := cache.Get("unknown")
+	assert.False(t, found)
+}
+
+func TestEmbeddedCachePutInvalid(t *testing.T) {
+	cache := newTestEmbeddedCache()
+	cache.Put([]Cacheable{{Type: TypeJSON, Data: json.RawMessage(`{}`), Key: "taken"}})
+
+	uuids := cache.Put([]Cacheable{
+		{Type: "html", Data: json.RawMessage(`"<div></div>"`)},
+		{Type: TypeXML, Data: json.RawMessage(`{"not":"a string"}`)},
+		{Type: TypeJSON, Data: json.RawMessage(`{"id":"bid1"}`), Key: "taken"},
+	})
+
+	assert.Equal(t, []string{"", "", ""}, uuids)
+	_, data, _ := cache.Get("taken")
+	assert.Equal(t, `{}`, string(data), "values under a key already taken are not stored")
+}
+
+func TestEmbeddedCachePutConcurrentKeys(t *testing.T) {
+	cache := newTestEmbeddedCache()
+
+	var wg sync.WaitGroup
+	uuids := make([][]string, 10)
+	for i := range uuids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			uuids[i] = cache.Put([]Cacheable{{Type: TypeJSON, Data: json.RawMessage(`{}`), Key: "key"}})
+		}(i)
+	}
+	wg.Wait()
+
+	stored := 0
+	for _, putUUIDs := range uuids {
+		if putUUIDs[0] != "" {
+			stored++
+		}
+	}
+	assert.Equal(t, 1, stored, "a key is only taken once")
+}
+
+func TestEmbeddedCacheTTL(t *testing.T) {
+	cache := newTestEmbeddedCache()
+
+	testCases := []struct {
+		description string
+		givenTTL    int64
+		expectedTTL uint32
+	}{
+		{description: "Default TTL", givenTTL: 0, expectedTTL: 300},
+		{description: "TTL of the value", givenTTL: 60, expectedTTL: 60},
+		{description: "TTL capped", givenTTL: 7200, expectedTTL: 3600},
+	}
+
+	for _, test := range testCases {
+		uuids := cache.Put([]Cacheable{{Type: TypeJSON, Data: json.RawMessage(`{}`), TTLSeconds: test.givenTTL}})
+
+		ttl, err := cache.lru.TTL([]byte(uuids[0]))
+		assert.NoError(t, err, test.description)
+		assert.InDelta(t, test.expectedTTL, ttl, 1, test.description)
+	}
+}
+
+func TestEmbeddedClient(t *testing.T) {
+	metricsMock := &metrics.MetricsEngineMock{}
+	metricsMock.On("RecordPrebidCacheRequestTime", true, mock.Anything).Once()
+	cache := newTestEmbeddedCache()
+	client := NewEmbeddedClient(cache, &config.ExternalCache{Scheme: "https", Host: "pbs.prebid.org", Path: "cache"}, metricsMock)
+
+	uuids, errs := client.PutJson(context.Background(), []Cacheable{
+		{Type: TypeJSON, Data: json.RawMessage(`{"id":"bid1"}`)},
+		{Type: "html", Data: json.RawMessage(`"<div></div>"`)},
+	})
+
+	if assert.Len(t, uuids, 2) {
+		_, data, found := cache.Get(uuids[0])
+		assert.True(t, found)
+		assert.Equal(t, `{"id":"bid1"}`, string(data))
+		assert.Empty(t, uuids[1])
+	}
+	assert.Len(t, errs, 1)
+	metricsMock.AssertExpectations(t)
+
+	scheme, host, path := client.GetExtCacheData()
+	assert.Equal(t, "https", scheme)
+	assert.Equal(t, "pbs.prebid.org", host)
+	assert.Equal(t, "/cache", path)
+}
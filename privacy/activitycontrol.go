@@ -0,0 +1,151 @@
+package privacy
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+)
+
+// Activity is a privacy sensitive action of Prebid Server, which the accounts may allow to some components only
+type Activity int
+
+const (
+	ActivitySyncUser Activity = iota
+	ActivityFetchBids
+	ActivityEnrichUserFPD
+	ActivityTransmitUserFPD
+	ActivityTransmitPreciseGeo
+	ActivityTransmitEids
+)
+
+// Component is a part of Prebid Server carrying out an activity, such as a bidder
+type Component struct {
+	Type string
+	Name string
+}
+
+// ActivityRequest holds the signals of a request the activity conditions are matched against
+type ActivityRequest struct {
+	// Country is the ISO-3166-1-alpha-3 code of device.geo.country
+	Country string
+	Region  string
+	// GPC is the Global Privacy Control signal, 1 or 0, or empty when the request has none
+	GPC string
+}
+
+// NewActivityRequest reads the signals of an OpenRTB bid request. The GPC signal is read from regs.ext.gpc, as 1, "1",
+// 0 or "0".
+func NewActivityRequest(bidRequest *openrtb2.BidRequest) ActivityRequest {
+	var request ActivityRequest
+	if bidRequest == nil {
+		return request
+	}
+
+	if bidRequest.Device != nil && bidRequest.Device.Geo != nil {
+		request.Country = bidRequest.Device.Geo.Country
+		request.Region = bidRequest.Device.Geo.Region
+	}
+
+	if bidRequest.Regs != nil && len(bidRequest.Regs.Ext) > 0 {
+		var regsExt struct {
+			GPC json.RawMessage `json:"gpc"`
+		}
+		if err := json.Unmarshal(bidRequest.Regs.Ext, &regsExt); err == nil {
+			switch gpc := strings.Trim(string(regsExt.GPC), `"`); gpc {
+			case "0", "1":
+				request.GPC = gpc
+			}
+		}
+	}
+	return request
+}
+
+// ActivityControl decides whether the components are allowed the activities, from the rules of an account. Its zero
+// value allows every activity.
+type ActivityControl struct {
+	activities map[Activity]config.Activity
+}
+
+// NewActivityControl builds the activity control of the privacy config of an account. The activities without a rule
+// nor a default are left out, so an account controlling no activity gets the zero value.
+func NewActivityControl(cfg config.AccountPrivacy) ActivityControl {
+	var control ActivityControl
+	for activity, activityConfig := range map[Activity]config.Activity{
+		ActivitySyncUser:           cfg.AllowActivities.SyncUser,
+		ActivityFetchBids:          cfg.AllowActivities.FetchBids,
+		ActivityEnrichUserFPD:      cfg.AllowActivities.EnrichUserFPD,
+		ActivityTransmitUserFPD:    cfg.AllowActivities.TransmitUserFPD,
+		ActivityTransmitPreciseGeo: cfg.AllowActivities.TransmitPreciseGeo,
+		ActivityTransmitEids:       cfg.AllowActivities.TransmitEids,
+	} {
+		if activityConfig.Default == nil && len(activityConfig.Rules) == 0 {
+			continue
+		}
+		if control.activities == nil {
+			control.activities = make(map[Activity]config.Activity)
+		}
+		control.activities[activity] = activityConfig
+	}
+	return control
+}
+
+// Allow tells if the component is allowed the activity for the request. The first rule whose condition matches
+// decides, and the default of the activity decides when none does.
+func (c ActivityControl) Allow(activity Activity, component Component, request ActivityRequest) bool {
+	cfg, ok := c.activities[activity]
+	if !ok {
+		return true
+	}
+
+	for _, rule := range cfg.Rules {
+		if conditionMatches(rule.Condition, component, request) {
+			return rule.Allow
+		}
+	}
+	return cfg.Default == nil || *cfg.Default
+}
+
+func conditionMatches(condition config.ActivityCondition, component Component, request ActivityRequest) bool {
+	if len(condition.ComponentType) > 0 && !containsFold(condition.ComponentType, component.Type) {
+		return false
+	}
+	if len(condition.ComponentName) > 0 && !containsFold(condition.ComponentName, component.Name) {
+		return false
+	}
+	if len(condition.Geo) > 0 && !geoMatches(condition.Geo, request) {
+		return false
+	}
+	if condition.GPC != "" && condition.GPC != request.GPC {
+		return false
+	}
+	return true
+}
+
+// geoMatches tells if the request is located in one of the geos, which are either a country or a country and one of
+// its regions separated by a dot, such as USA.CA
+func geoMatches(geos []string, request ActivityRequest) bool {
+	if request.Country == "" {
+		return false
+	}
+	for _, geo := range geos {
+		country, region := geo, ""
+		if i := strings.IndexByte(geo, '.'); i >= 0 {
+			country, region = geo[:i], geo[i+1:]
+		}
+		if strings.EqualFold(country, request.Country) && (region == "" || strings.EqualFold(region, request.Region)) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package privacy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActivityControlAllow(t *testing.T) {
+	falseValue := false
+	appnexus := Component{Type: config.ComponentTypeBidder, Name: "appnexus"}
+	rubicon := Component{Type: config.ComponentTypeBidder, Name: "rubicon"}
+
+	testCases := []struct {
+		description    string
+		givenActivity  config.Activity
+		givenComponent Component
+		givenRequest   ActivityRequest
+		expected       bool
+	}{
+		{
+			description:    "No rules and no default",
+			givenComponent: appnexus,
+			expected:       true,
+		},
+		{
+			description:    "Default denying",
+			givenActivity:  config.Activity{Default: &falseValue},
+			givenComponent: appnexus,
+			expected:       false,
+		},
+		{
+			description: "Component name matching",
+			givenActivity: config.Activity{Rules: []config.ActivityRule{
+				{Condition: config.ActivityCondition{ComponentName: []string{"Appnexus"}}, Allow: false},
+			}},
+			givenComponent: appnexus,
+			expected:       false,
+		},
+		{
+			description: "Component name not matching",
+			givenActivity: config.Activity{Rules: []config.ActivityRule{
+				{Condition: config.ActivityCondition{ComponentName: []string{"appnexus"}}, Allow: false},
+			}},
+			givenComponent: rubicon,
+			expected:       true,
+		},
+		{
+			description: "Component type not matching",
+			givenActivity: config.Activity{Rules: []config.ActivityRule{
+				{Condition: config.ActivityCondition{ComponentType: []string{config.ComponentTypeAnalytics}}, Allow: false},
+			}},
+			givenComponent: appnexus,
+			expected:       true,
+		},
+		{
+			description: "First matching rule deciding",
+			givenActivity: config.Activity{Default: &falseValue, Rules: []config.ActivityRule{
+				{Condition: config.ActivityCondition{ComponentName: []string{"rubicon"}}, Allow: false},
+				{Condition: config.ActivityCondition{ComponentType: []string{config.ComponentTypeBidder}}, Allow: true},
+				{Condition: config.ActivityCondition{ComponentName: []string{"appnexus"}}, Allow: false},
+			}},
+			givenComponent: appnexus,
+			expected:       true,
+		},
+		{
+			description: "Country matching",
+			givenActivity: config.Activity{Rules: []config.ActivityRule{
+				{Condition: config.ActivityCondition{Geo: []string{"CAN", "USA"}}, Allow: false},
+			}},
+			givenComponent: appnexus,
+			givenRequest:   ActivityRequest{Country: "USA", Region: "NY"},
+			expected:       false,
+		},
+		{
+			description: "Region matching",
+			givenActivity: config.Activity{Rules: []config.ActivityRule{
+				{Condition: config.ActivityCondition{Geo: []string{"USA.CA"}}, Allow: false},
+			}},
+			givenComponent: appnexus,
+			givenRequest:   ActivityRequest{Country: "USA", Region: "CA"},
+			expected:       false,
+		},
+		{
+			description: "Region not matching",
+			givenActivity: config.Activity{Rules: []config.ActivityRule{
+				{Condition: config.ActivityCondition{Geo: []string{"USA.CA"}}, Allow: false},
+			}},
+			givenComponent: appnexus,
+			givenRequest:   ActivityRequest{Country: "USA", Region: "NY"},
+			expected:       true,
+		},
+		{
+			description: "Geo condition without device geo",
+			givenActivity: config.Activity{Rules: []config.ActivityRule{
+				{Condition: config.ActivityCondition{Geo: []string{"USA"}}, Allow: false},
+			}},
+			givenComponent: appnexus,
+			expected:       true,
+		},
+		{
+			description: "GPC matching along with the component",
+			givenActivity: config.Activity{Rules: []config.ActivityRule{
+				{Condition: config.ActivityCondition{ComponentName: []string{"appnexus"}, GPC: "1"}, Allow: false},
+			}},
+			givenComponent: appnexus,
+			givenRequest:   ActivityRequest{GPC: "1"},
+			expected:       false,
+		},
+		{
+			description: "GPC not matching",
+			givenActivity: config.Activity{Rules: []config.ActivityRule{
+				{Condition: config.ActivityCondition{GPC: "1"}, Allow: false},
+			}},
+			givenComponent: appnexus,
+			expected:       true,
+		},
+	}
+
+	for _, test := range testCases {
+		control := NewActivityControl(config.AccountPrivacy{AllowActivities: config.AllowActivities{FetchBids: test.givenActivity}})
+
+		assert.Equal(t, test.expected, control.Allow(ActivityFetchBids, test.givenComponent, test.givenRequest), test.description)
+		assert.True(t, control.Allow(ActivitySyncUser, test.givenComponent, test.givenRequest), test.description+":other activity")
+	}
+}
+
+func TestActivityControlZeroValue(t *testing.T) {
+	assert.Equal(t, ActivityControl{}, NewActivityControl(config.AccountPrivacy{}), "an account without rules")
+	assert.True(t, ActivityControl{}.Allow(ActivityTransmitEids, Component{Type: config.ComponentTypeBidder, Name: "appnexus"}, ActivityRequest{}))
+}
+
+func TestNewActivityRequest(t *testing.T) {
+	testCases := []struct {
+		description  string
+		givenRequest *openrtb2.BidRequest
+		expected     ActivityRequest
+	}{
+		{
+			description:  "Empty request",
+			givenRequest: &openrtb2.BidRequest{},
+		},
+		{
+			description: "Geo and GPC",
+			givenRequest: &openrtb2.BidRequest{
+				Device: &openrtb2.Device{Geo: &openrtb2.Geo{Country: "USA", Region: "CA"}},
+				Regs:   &openrtb2.Regs{Ext: json.RawMessage(`{"gpc":"1"}`)},
+			},
+			expected: ActivityRequest{Country: "USA", Region: "CA", GPC: "1"},
+		},
+		{
+			description:  "GPC as a number",
+			givenRequest: &openrtb2.BidRequest{Regs: &openrtb2.Regs{Ext: json.RawMessage(`{"gpc":0}`)}},
+			expected:     ActivityRequest{GPC: "0"},
+		},
+		{
+			description:  "Invalid GPC",
+			givenRequest: &openrtb2.BidRequest{Regs: &openrtb2.Regs{Ext: json.RawMessage(`{"gpc":true}`)}},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, NewActivityRequest(test.givenRequest), test.description)
+	}
+}
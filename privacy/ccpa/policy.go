@@ -7,6 +7,10 @@ import (
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
+// GPCConsent is the consent string standing for a Global Privacy Control signal, which opts the user out of the sale
+// of their personal information without telling whether they were given notice of it.
+const GPCConsent = "1-Y-"
+
 // Policy represents the CCPA regulatory information from an OpenRTB bid request.
 type Policy struct {
 	Consent       string
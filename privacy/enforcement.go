@@ -1,6 +1,9 @@
 package privacy
 
-import "github.com/mxmCherry/openrtb/v15/openrtb2"
+import (
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+)
 
 // Enforcement represents the privacy policies to enforce for an OpenRTB bid request.
 type Enforcement struct {
@@ -9,11 +12,29 @@ type Enforcement struct {
 	GDPRGeo bool
 	GDPRID  bool
 	LMT     bool
+	LGPD    bool
+
+	// UFPD, PreciseGeo and EIDs are set by the activities transmitUfpd, transmitPreciseGeo and transmitEids the
+	// account denies to the bidder
+	UFPD       bool
+	PreciseGeo bool
+	EIDs       bool
+
+	// ScrubProfiles tells what COPPA and LGPD scrub. The empty profiles scrub like DefaultCOPPAScrubProfile and
+	// DefaultLGPDScrubProfile.
+	ScrubProfiles config.ScrubProfiles
 }
 
+var (
+	// DefaultCOPPAScrubProfile is the scrub profile of COPPA when the enforcement has an empty one
+	DefaultCOPPAScrubProfile = config.ScrubProfile{User: config.ScrubUserIDsAndDemographics, DeviceIDs: true, Geo: config.ScrubGeoFull}
+	// DefaultLGPDScrubProfile is the scrub profile of LGPD when the enforcement has an empty one
+	DefaultLGPDScrubProfile = config.ScrubProfile{User: config.ScrubUserIDs, DeviceIDs: true, Geo: config.ScrubGeoReduced}
+)
+
 // Any returns true if at least one privacy policy requires enforcement.
 func (e Enforcement) Any() bool {
-	return e.CCPA || e.COPPA || e.GDPRGeo || e.GDPRID || e.LMT
+	return e.CCPA || e.COPPA || e.GDPRGeo || e.GDPRID || e.LMT || e.LGPD || e.UFPD || e.PreciseGeo || e.EIDs
 }
 
 // Apply cleans personally identifiable information from an OpenRTB bid request.
@@ -21,58 +42,147 @@ func (e Enforcement) Apply(bidRequest *openrtb2.BidRequest) {
 	e.apply(bidRequest, NewScrubber())
 }
 
+// ApplyScrubProfile cleans the device and user of an OpenRTB bid request as the scrub profile tells, and nothing else.
+// Unlike the scrub profiles of the regulations, an empty profile scrubs nothing.
+func ApplyScrubProfile(bidRequest *openrtb2.BidRequest, profile config.ScrubProfile) {
+	applyScrubProfile(bidRequest, profile, NewScrubber())
+}
+
+func applyScrubProfile(bidRequest *openrtb2.BidRequest, profile config.ScrubProfile, scrubber Scrubber) {
+	if bidRequest != nil && profile != (config.ScrubProfile{}) {
+		// None of the policies are enforced, so the profile is the only source of scrubbing
+		Enforcement{}.scrub(bidRequest, scrubber, map[string]config.ScrubProfile{"": profile})
+	}
+}
+
 func (e Enforcement) apply(bidRequest *openrtb2.BidRequest, scrubber Scrubber) {
 	if bidRequest != nil && e.Any() {
-		bidRequest.Device = scrubber.ScrubDevice(bidRequest.Device, e.getDeviceIDScrubStrategy(), e.getIPv4ScrubStrategy(), e.getIPv6ScrubStrategy(), e.getGeoScrubStrategy())
-		bidRequest.User = scrubber.ScrubUser(bidRequest.User, e.getUserScrubStrategy(), e.getGeoScrubStrategy())
+		e.scrub(bidRequest, scrubber, e.ActiveScrubProfiles())
+	}
+}
+
+// scrub cleans the device and user of the request for the policies of the enforcement and the scrub profiles
+func (e Enforcement) scrub(bidRequest *openrtb2.BidRequest, scrubber Scrubber, profiles map[string]config.ScrubProfile) {
+	geo := e.getGeoScrubStrategy(profiles)
+	bidRequest.Device = scrubber.ScrubDevice(bidRequest.Device, e.getDeviceIDScrubStrategy(profiles), e.getIPv4ScrubStrategy(profiles), e.getIPv6ScrubStrategy(profiles), geo)
+	bidRequest.User = scrubber.ScrubUser(bidRequest.User, e.getUserScrubStrategy(profiles), geo)
+}
+
+// ActiveScrubProfiles returns the scrub profiles of the regulations applying to the request, among COPPA and LGPD
+func (e Enforcement) ActiveScrubProfiles() map[string]config.ScrubProfile {
+	profiles := make(map[string]config.ScrubProfile)
+	if e.COPPA {
+		profiles[ScrubProfileCOPPA] = scrubProfileOrDefault(e.ScrubProfiles.COPPA, DefaultCOPPAScrubProfile)
+	}
+	if e.LGPD {
+		profiles[ScrubProfileLGPD] = scrubProfileOrDefault(e.ScrubProfiles.LGPD, DefaultLGPDScrubProfile)
+	}
+	return profiles
+}
+
+func scrubProfileOrDefault(profile, defaultProfile config.ScrubProfile) config.ScrubProfile {
+	if profile == (config.ScrubProfile{}) {
+		return defaultProfile
 	}
+	return profile
 }
 
-func (e Enforcement) getDeviceIDScrubStrategy() ScrubStrategyDeviceID {
-	if e.COPPA || e.GDPRID || e.CCPA || e.LMT {
+// The names of the scrub profiles
+const (
+	ScrubProfileCOPPA = "coppa"
+	ScrubProfileLGPD  = "lgpd"
+)
+
+func (e Enforcement) getDeviceIDScrubStrategy(profiles map[string]config.ScrubProfile) ScrubStrategyDeviceID {
+	if e.GDPRID || e.CCPA || e.LMT || e.UFPD {
 		return ScrubStrategyDeviceIDAll
 	}
 
+	for _, profile := range profiles {
+		if profile.DeviceIDs {
+			return ScrubStrategyDeviceIDAll
+		}
+	}
+
 	return ScrubStrategyDeviceIDNone
 }
 
-func (e Enforcement) getIPv4ScrubStrategy() ScrubStrategyIPV4 {
-	if e.COPPA || e.GDPRGeo || e.CCPA || e.LMT {
+func (e Enforcement) getIPv4ScrubStrategy(profiles map[string]config.ScrubProfile) ScrubStrategyIPV4 {
+	if e.GDPRGeo || e.CCPA || e.LMT || e.PreciseGeo || profilesGeo(profiles) != config.ScrubGeoNone {
 		return ScrubStrategyIPV4Lowest8
 	}
 
 	return ScrubStrategyIPV4None
 }
 
-func (e Enforcement) getIPv6ScrubStrategy() ScrubStrategyIPV6 {
-	if e.COPPA {
+func (e Enforcement) getIPv6ScrubStrategy(profiles map[string]config.ScrubProfile) ScrubStrategyIPV6 {
+	geo := profilesGeo(profiles)
+	if geo == config.ScrubGeoFull {
 		return ScrubStrategyIPV6Lowest32
 	}
 
-	if e.GDPRGeo || e.CCPA || e.LMT {
+	if e.GDPRGeo || e.CCPA || e.LMT || e.PreciseGeo || geo == config.ScrubGeoReduced {
 		return ScrubStrategyIPV6Lowest16
 	}
 
 	return ScrubStrategyIPV6None
 }
 
-func (e Enforcement) getGeoScrubStrategy() ScrubStrategyGeo {
-	if e.COPPA {
+func (e Enforcement) getGeoScrubStrategy(profiles map[string]config.ScrubProfile) ScrubStrategyGeo {
+	geo := profilesGeo(profiles)
+	if geo == config.ScrubGeoFull {
 		return ScrubStrategyGeoFull
 	}
 
-	if e.GDPRGeo || e.CCPA || e.LMT {
+	if e.GDPRGeo || e.CCPA || e.LMT || e.PreciseGeo || geo == config.ScrubGeoReduced {
 		return ScrubStrategyGeoReducedPrecision
 	}
 
 	return ScrubStrategyGeoNone
 }
 
-func (e Enforcement) getUserScrubStrategy() ScrubStrategyUser {
-	if e.COPPA {
+// profilesGeo returns the strictest geo scrubbing of the scrub profiles
+func profilesGeo(profiles map[string]config.ScrubProfile) string {
+	geo := config.ScrubGeoNone
+	for _, profile := range profiles {
+		switch profile.Geo {
+		case config.ScrubGeoFull:
+			return config.ScrubGeoFull
+		case config.ScrubGeoReduced:
+			geo = config.ScrubGeoReduced
+		}
+	}
+	return geo
+}
+
+// profilesUser returns the strictest user scrubbing of the scrub profiles
+func profilesUser(profiles map[string]config.ScrubProfile) string {
+	user := config.ScrubUserNone
+	for _, profile := range profiles {
+		switch profile.User {
+		case config.ScrubUserIDsAndDemographics:
+			return config.ScrubUserIDsAndDemographics
+		case config.ScrubUserIDs:
+			user = config.ScrubUserIDs
+		case config.ScrubUserEIDs:
+			if user == config.ScrubUserNone {
+				user = config.ScrubUserEIDs
+			}
+		}
+	}
+	return user
+}
+
+func (e Enforcement) getUserScrubStrategy(profiles map[string]config.ScrubProfile) ScrubStrategyUser {
+	user := profilesUser(profiles)
+	if e.UFPD || user == config.ScrubUserIDsAndDemographics {
 		return ScrubStrategyUserIDAndDemographic
 	}
 
+	if user == config.ScrubUserIDs {
+		return ScrubStrategyUserID
+	}
+
 	if e.CCPA || e.LMT {
 		return ScrubStrategyUserID
 	}
@@ -81,5 +191,9 @@ func (e Enforcement) getUserScrubStrategy() ScrubStrategyUser {
 		return ScrubStrategyUserID
 	}
 
+	if e.EIDs || user == config.ScrubUserEIDs {
+		return ScrubStrategyUserEIDs
+	}
+
 	return ScrubStrategyUserNone
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -194,6 +195,87 @@ func TestApply(t *testing.T) {
 			expectedUser:       ScrubStrategyUserIDAndDemographic,
 			expectedUserGeo:    ScrubStrategyGeoFull,
 		},
+		{
+			description: "LGPD Only - Default Profile",
+			enforcement: Enforcement{
+				LGPD: true,
+			},
+			expectedDeviceID:   ScrubStrategyDeviceIDAll,
+			expectedDeviceIPv4: ScrubStrategyIPV4Lowest8,
+			expectedDeviceIPv6: ScrubStrategyIPV6Lowest16,
+			expectedDeviceGeo:  ScrubStrategyGeoReducedPrecision,
+			expectedUser:       ScrubStrategyUserID,
+			expectedUserGeo:    ScrubStrategyGeoReducedPrecision,
+		},
+		{
+			description: "COPPA Only - Configured Profile",
+			enforcement: Enforcement{
+				COPPA: true,
+				ScrubProfiles: config.ScrubProfiles{
+					COPPA: config.ScrubProfile{User: config.ScrubUserEIDs, DeviceIDs: false, Geo: config.ScrubGeoNone},
+				},
+			},
+			expectedDeviceID:   ScrubStrategyDeviceIDNone,
+			expectedDeviceIPv4: ScrubStrategyIPV4None,
+			expectedDeviceIPv6: ScrubStrategyIPV6None,
+			expectedDeviceGeo:  ScrubStrategyGeoNone,
+			expectedUser:       ScrubStrategyUserEIDs,
+			expectedUserGeo:    ScrubStrategyGeoNone,
+		},
+		{
+			description: "Interactions: COPPA + LGPD + CCPA - Strictest Profile",
+			enforcement: Enforcement{
+				CCPA:  true,
+				COPPA: true,
+				LGPD:  true,
+				ScrubProfiles: config.ScrubProfiles{
+					COPPA: config.ScrubProfile{User: config.ScrubUserNone, DeviceIDs: false, Geo: config.ScrubGeoFull},
+					LGPD:  config.ScrubProfile{User: config.ScrubUserIDsAndDemographics, DeviceIDs: false, Geo: config.ScrubGeoReduced},
+				},
+			},
+			expectedDeviceID:   ScrubStrategyDeviceIDAll,
+			expectedDeviceIPv4: ScrubStrategyIPV4Lowest8,
+			expectedDeviceIPv6: ScrubStrategyIPV6Lowest32,
+			expectedDeviceGeo:  ScrubStrategyGeoFull,
+			expectedUser:       ScrubStrategyUserIDAndDemographic,
+			expectedUserGeo:    ScrubStrategyGeoFull,
+		},
+		{
+			description: "Activity Transmit UFPD Denied Only",
+			enforcement: Enforcement{
+				UFPD: true,
+			},
+			expectedDeviceID:   ScrubStrategyDeviceIDAll,
+			expectedDeviceIPv4: ScrubStrategyIPV4None,
+			expectedDeviceIPv6: ScrubStrategyIPV6None,
+			expectedDeviceGeo:  ScrubStrategyGeoNone,
+			expectedUser:       ScrubStrategyUserIDAndDemographic,
+			expectedUserGeo:    ScrubStrategyGeoNone,
+		},
+		{
+			description: "Activity Transmit Precise Geo Denied Only",
+			enforcement: Enforcement{
+				PreciseGeo: true,
+			},
+			expectedDeviceID:   ScrubStrategyDeviceIDNone,
+			expectedDeviceIPv4: ScrubStrategyIPV4Lowest8,
+			expectedDeviceIPv6: ScrubStrategyIPV6Lowest16,
+			expectedDeviceGeo:  ScrubStrategyGeoReducedPrecision,
+			expectedUser:       ScrubStrategyUserNone,
+			expectedUserGeo:    ScrubStrategyGeoReducedPrecision,
+		},
+		{
+			description: "Activity Transmit EIDs Denied Only",
+			enforcement: Enforcement{
+				EIDs: true,
+			},
+			expectedDeviceID:   ScrubStrategyDeviceIDNone,
+			expectedDeviceIPv4: ScrubStrategyIPV4None,
+			expectedDeviceIPv6: ScrubStrategyIPV6None,
+			expectedDeviceGeo:  ScrubStrategyGeoNone,
+			expectedUser:       ScrubStrategyUserEIDs,
+			expectedUserGeo:    ScrubStrategyGeoNone,
+		},
 	}
 
 	for _, test := range testCases {
@@ -216,6 +298,57 @@ func TestApply(t *testing.T) {
 	}
 }
 
+func TestActiveScrubProfiles(t *testing.T) {
+	lgpdProfile := config.ScrubProfile{User: config.ScrubUserNone, Geo: config.ScrubGeoFull}
+
+	assert.Empty(t, Enforcement{CCPA: true}.ActiveScrubProfiles(), "no regulation")
+	assert.Equal(t, map[string]config.ScrubProfile{
+		ScrubProfileCOPPA: DefaultCOPPAScrubProfile,
+		ScrubProfileLGPD:  lgpdProfile,
+	}, Enforcement{COPPA: true, LGPD: true, ScrubProfiles: config.ScrubProfiles{LGPD: lgpdProfile}}.ActiveScrubProfiles(), "COPPA and LGPD")
+}
+
+func TestApplyScrubProfile(t *testing.T) {
+	newRequest := func() *openrtb2.BidRequest {
+		return &openrtb2.BidRequest{
+			Device: &openrtb2.Device{IFA: "ifa", IP: "1.2.3.4", Geo: &openrtb2.Geo{Lat: 12.3456, Lon: 45.6789}},
+			User:   &openrtb2.User{ID: "id", BuyerUID: "buyer", Yob: 1980},
+		}
+	}
+
+	req := newRequest()
+	ApplyScrubProfile(req, config.ScrubProfile{})
+	assert.Equal(t, newRequest(), req, "An empty profile should scrub nothing")
+
+	req = newRequest()
+	ApplyScrubProfile(req, config.ScrubProfile{User: config.ScrubUserIDs, Geo: config.ScrubGeoReduced})
+	assert.Equal(t, &openrtb2.BidRequest{
+		Device: &openrtb2.Device{IFA: "ifa", IP: "1.2.3.0", Geo: &openrtb2.Geo{Lat: 12.35, Lon: 45.68}},
+		User:   &openrtb2.User{Yob: 1980},
+	}, req)
+}
+
+func TestApplyScrubProfileStrategies(t *testing.T) {
+	req := &openrtb2.BidRequest{Device: &openrtb2.Device{}, User: &openrtb2.User{}}
+	m := &mockScrubber{}
+	m.On("ScrubDevice", req.Device, ScrubStrategyDeviceIDNone, ScrubStrategyIPV4None, ScrubStrategyIPV6None, ScrubStrategyGeoNone).Return(&openrtb2.Device{}).Once()
+	m.On("ScrubUser", req.User, ScrubStrategyUserEIDs, ScrubStrategyGeoNone).Return(&openrtb2.User{}).Once()
+
+	applyScrubProfile(req, config.ScrubProfile{User: config.ScrubUserEIDs}, m)
+
+	m.AssertExpectations(t)
+}
+
+func TestApplyScrubProfileEmpty(t *testing.T) {
+	m := &mockScrubber{}
+
+	applyScrubProfile(&openrtb2.BidRequest{}, config.ScrubProfile{}, m)
+	applyScrubProfile(nil, config.ScrubProfile{DeviceIDs: true}, m)
+
+	m.AssertNotCalled(t, "ScrubDevice")
+	m.AssertNotCalled(t, "ScrubUser")
+}
+
 func TestApplyNoneApplicable(t *testing.T) {
 	req := &openrtb2.BidRequest{}
 
@@ -0,0 +1,38 @@
+package gdpr
+
+import (
+	"encoding/json"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+)
+
+// AdditionalConsentWriter implements the PolicyWriter interface for the Additional Consent of Google, which lists the
+// ad tech providers outside of the TCF the user consented to. It is written to
+// user.ext.ConsentedProvidersSettings.consented_providers.
+type AdditionalConsentWriter struct {
+	Consent string
+}
+
+// Write mutates an OpenRTB bid request with the Additional Consent string.
+func (c AdditionalConsentWriter) Write(req *openrtb2.BidRequest) error {
+	if c.Consent == "" {
+		return nil
+	}
+
+	if req.User == nil {
+		req.User = &openrtb2.User{}
+	}
+
+	extMap := make(map[string]interface{})
+	if req.User.Ext != nil {
+		if err := json.Unmarshal(req.User.Ext, &extMap); err != nil {
+			return err
+		}
+	}
+	extMap["ConsentedProvidersSettings"] = map[string]string{"consented_providers": c.Consent}
+	ext, err := json.Marshal(extMap)
+	if err == nil {
+		req.User.Ext = ext
+	}
+	return err
+}
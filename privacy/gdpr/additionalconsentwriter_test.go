@@ -0,0 +1,59 @@
+package gdpr
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdditionalConsentWriter(t *testing.T) {
+	testCases := []struct {
+		description   string
+		consent       string
+		request       *openrtb2.BidRequest
+		expected      *openrtb2.BidRequest
+		expectedError bool
+	}{
+		{
+			description: "Empty",
+			consent:     "",
+			request:     &openrtb2.BidRequest{},
+			expected:    &openrtb2.BidRequest{},
+		},
+		{
+			description: "Enabled With Nil Request User Object",
+			consent:     "1~7.12",
+			request:     &openrtb2.BidRequest{},
+			expected: &openrtb2.BidRequest{User: &openrtb2.User{
+				Ext: json.RawMessage(`{"ConsentedProvidersSettings":{"consented_providers":"1~7.12"}}`)}},
+		},
+		{
+			description: "Enabled With Existing Request User Ext Object",
+			consent:     "1~7.12",
+			request: &openrtb2.BidRequest{User: &openrtb2.User{
+				Ext: json.RawMessage(`{"consent":"anyConsent"}`)}},
+			expected: &openrtb2.BidRequest{User: &openrtb2.User{
+				Ext: json.RawMessage(`{"ConsentedProvidersSettings":{"consented_providers":"1~7.12"},"consent":"anyConsent"}`)}},
+		},
+		{
+			description: "Enabled With Existing Malformed Request User Ext Object",
+			consent:     "1~7.12",
+			request: &openrtb2.BidRequest{User: &openrtb2.User{
+				Ext: json.RawMessage(`malformed`)}},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		err := AdditionalConsentWriter{test.consent}.Write(test.request)
+
+		if test.expectedError {
+			assert.Error(t, err, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+			assert.Equal(t, test.expected, test.request, test.description)
+		}
+	}
+}
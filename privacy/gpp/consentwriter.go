@@ -0,0 +1,44 @@
+package gpp
+
+import (
+	"encoding/json"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// ConsentWriter implements the PolicyWriter interface for GPP, writing regs.ext.gpp and regs.ext.gpp_sid
+type ConsentWriter struct {
+	Consent    string
+	SectionIDs []SectionID
+}
+
+// Write mutates an OpenRTB bid request with the GPP string and the sections which apply to the request
+func (c ConsentWriter) Write(req *openrtb2.BidRequest) error {
+	if req == nil || (c.Consent == "" && len(c.SectionIDs) == 0) {
+		return nil
+	}
+
+	reqWrap := &openrtb_ext.RequestWrapper{BidRequest: req}
+	regsExt, err := reqWrap.GetRegExt()
+	if err != nil {
+		return err
+	}
+	ext := regsExt.GetExt()
+	if c.Consent != "" {
+		consent, err := json.Marshal(c.Consent)
+		if err != nil {
+			return err
+		}
+		ext["gpp"] = consent
+	}
+	if len(c.SectionIDs) > 0 {
+		sectionIDs, err := json.Marshal(c.SectionIDs)
+		if err != nil {
+			return err
+		}
+		ext["gpp_sid"] = sectionIDs
+	}
+	regsExt.SetExt(ext)
+	return reqWrap.RebuildRequest()
+}
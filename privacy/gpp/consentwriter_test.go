@@ -0,0 +1,59 @@
+package gpp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsentWriter(t *testing.T) {
+	testCases := []struct {
+		description   string
+		writer        ConsentWriter
+		request       *openrtb2.BidRequest
+		expected      *openrtb2.BidRequest
+		expectedError bool
+	}{
+		{
+			description: "Empty",
+			writer:      ConsentWriter{},
+			request:     &openrtb2.BidRequest{},
+			expected:    &openrtb2.BidRequest{},
+		},
+		{
+			description: "Enabled With Nil Request Regs Object",
+			writer:      ConsentWriter{Consent: "DBABMA~" + tcfSection, SectionIDs: []SectionID{SectionTCFEUV2}},
+			request:     &openrtb2.BidRequest{},
+			expected: &openrtb2.BidRequest{Regs: &openrtb2.Regs{
+				Ext: json.RawMessage(`{"gpp":"DBABMA~` + tcfSection + `","gpp_sid":[2]}`)}},
+		},
+		{
+			description: "Enabled With Existing Request Regs Ext Object - Overwrites",
+			writer:      ConsentWriter{SectionIDs: []SectionID{SectionUSPV1}},
+			request: &openrtb2.BidRequest{Regs: &openrtb2.Regs{
+				Ext: json.RawMessage(`{"gpp_sid":[2],"us_privacy":"1YNN"}`)}},
+			expected: &openrtb2.BidRequest{Regs: &openrtb2.Regs{
+				Ext: json.RawMessage(`{"gpp_sid":[6],"us_privacy":"1YNN"}`)}},
+		},
+		{
+			description: "Enabled With Existing Malformed Request Regs Ext Object",
+			writer:      ConsentWriter{Consent: "DBABMA~" + tcfSection},
+			request: &openrtb2.BidRequest{Regs: &openrtb2.Regs{
+				Ext: json.RawMessage(`malformed`)}},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		err := test.writer.Write(test.request)
+
+		if test.expectedError {
+			assert.Error(t, err, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+			assert.Equal(t, test.expected, test.request, test.description)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package gpp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prebid/go-gdpr/vendorconsent"
+	"github.com/prebid/prebid-server/privacy/ccpa"
+)
+
+// SectionID identifies a section of a GPP string, as listed by the IAB GPP spec
+type SectionID int8
+
+const (
+	SectionTCFEUV2 SectionID = 2
+	SectionUSPV1   SectionID = 6
+	SectionUSNat   SectionID = 7
+	SectionUSCA    SectionID = 8
+	SectionUSVA    SectionID = 9
+	SectionUSCO    SectionID = 10
+	SectionUSUT    SectionID = 11
+	SectionUSCT    SectionID = 12
+)
+
+const (
+	headerType    = 3
+	headerVersion = 1
+)
+
+// Section is a section of a GPP string. USPrivacy is the us_privacy string of the USP v1 section and of the sections
+// of the US laws, which lets them drive the CCPA enforcement.
+type Section struct {
+	ID        SectionID
+	Value     string
+	USPrivacy string
+}
+
+// GPP is a parsed GPP string
+type GPP struct {
+	SectionIDs []SectionID
+	Sections   map[SectionID]Section
+}
+
+// Parse parses a GPP string. The sections which can't be read are left out with an error each, while an invalid
+// header leaves the whole string out. The sections of the other frameworks are kept without being read.
+func Parse(gppString string) (GPP, []error) {
+	parts := strings.Split(gppString, "~")
+	sectionIDs, err := parseHeader(parts[0])
+	if err != nil {
+		return GPP{}, []error{fmt.Errorf("the header is invalid: %v", err)}
+	}
+	if len(sectionIDs) != len(parts)-1 {
+		return GPP{}, []error{fmt.Errorf("the header lists %d sections but the string has %d", len(sectionIDs), len(parts)-1)}
+	}
+
+	var errs []error
+	parsed := GPP{
+		SectionIDs: sectionIDs,
+		Sections:   make(map[SectionID]Section, len(sectionIDs)),
+	}
+	for i, id := range sectionIDs {
+		section, err := parseSection(id, parts[i+1])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("section %d is invalid: %v", id, err))
+			continue
+		}
+		parsed.Sections[id] = section
+	}
+	return parsed, errs
+}
+
+func parseSection(id SectionID, value string) (Section, error) {
+	section := Section{ID: id, Value: value}
+
+	switch id {
+	case SectionTCFEUV2:
+		if _, err := vendorconsent.ParseString(value); err != nil {
+			return Section{}, err
+		}
+	case SectionUSPV1:
+		if !ccpa.ValidateConsent(value) {
+			return Section{}, errors.New("it must be a valid us_privacy string")
+		}
+		section.USPrivacy = value
+	case SectionUSNat, SectionUSCA, SectionUSVA, SectionUSCO, SectionUSUT, SectionUSCT:
+		usPrivacy, err := parseUSSection(id, value)
+		if err != nil {
+			return Section{}, err
+		}
+		section.USPrivacy = usPrivacy
+	}
+	return section, nil
+}
+
+// parseHeader reads the ids of the sections the header lists, in the order of the sections in the string
+func parseHeader(header string) ([]SectionID, error) {
+	reader, err := newBitReader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	if headerTypeValue := reader.readInt(6); headerTypeValue != headerType {
+		return nil, fmt.Errorf("the type must be %d. Got %d", headerType, headerTypeValue)
+	}
+	if version := reader.readInt(6); version != headerVersion {
+		return nil, fmt.Errorf("the version must be %d. Got %d", headerVersion, version)
+	}
+
+	// The section ids are a Fibonacci integer range, whose integers are encoded as offsets from the previous one
+	var ids []SectionID
+	entries := reader.readInt(12)
+	last := 0
+	for i := 0; i < entries; i++ {
+		isRange := reader.readInt(1) == 1
+		start := last + reader.readFibonacci()
+		end := start
+		if isRange {
+			end = start + reader.readFibonacci()
+		}
+		for id := start; id <= end; id++ {
+			ids = append(ids, SectionID(id))
+		}
+		last = end
+	}
+	if reader.err != nil {
+		return nil, reader.err
+	}
+	return ids, nil
+}
+
+// bitReader reads the bits of the web safe base64 encoding of the GPP header and sections, in which each character
+// holds 6 bits. The padding of the standard base64 encoding isn't used, so the strings can't be decoded as is.
+type bitReader struct {
+	values []byte
+	offset int
+	err    error
+}
+
+const base64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+func newBitReader(encoded string) (*bitReader, error) {
+	if encoded == "" {
+		return nil, errors.New("it is empty")
+	}
+	values := make([]byte, len(encoded))
+	for i := 0; i < len(encoded); i++ {
+		value := strings.IndexByte(base64Alphabet, encoded[i])
+		if value < 0 {
+			return nil, fmt.Errorf("%q isn't a web safe base64 character", encoded[i])
+		}
+		values[i] = byte(value)
+	}
+	return &bitReader{values: values}, nil
+}
+
+func (r *bitReader) readBit() int {
+	if r.offset >= len(r.values)*6 {
+		if r.err == nil {
+			r.err = errors.New("it is too short")
+		}
+		return 0
+	}
+	bit := (r.values[r.offset/6] >> (5 - r.offset%6)) & 1
+	r.offset++
+	return int(bit)
+}
+
+func (r *bitReader) readInt(bits int) int {
+	value := 0
+	for i := 0; i < bits; i++ {
+		value = value<<1 | r.readBit()
+	}
+	return value
+}
+
+// readFibonacci reads a Fibonacci coded integer, whose bits add up the Fibonacci numbers from 1 and end on two 1s
+func (r *bitReader) readFibonacci() int {
+	value := 0
+	previous, current := 1, 1
+	lastBit := 0
+	for r.err == nil {
+		bit := r.readBit()
+		if bit == 1 && lastBit == 1 {
+			return value
+		}
+		if bit == 1 {
+			value += current
+		}
+		previous, current = current, previous+current
+		lastBit = bit
+	}
+	return 0
+}
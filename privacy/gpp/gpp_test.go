@@ -0,0 +1,213 @@
+package gpp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	tcfSection = "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"
+	// usNatSection sets SaleOptOutNotice to 1, SaleOptOut to 2 and MspaCoveredTransaction to 2
+	usNatSection = "BVQqAAAAAgA.QA"
+)
+
+// encodeBits encodes a string of 0s and 1s, padded with 0s to a multiple of 6 bits, as web safe base64 characters
+func encodeBits(bits string) string {
+	bits = strings.ReplaceAll(bits, " ", "")
+	for len(bits)%6 != 0 {
+		bits += "0"
+	}
+	var encoded strings.Builder
+	for i := 0; i < len(bits); i += 6 {
+		value := 0
+		for _, bit := range bits[i : i+6] {
+			value = value<<1 | int(bit-'0')
+		}
+		encoded.WriteByte(base64Alphabet[value])
+	}
+	return encoded.String()
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		description        string
+		givenGPP           string
+		expectedSectionIDs []SectionID
+		expectedSections   map[SectionID]Section
+		expectedErrors     []string
+	}{
+		{
+			description:        "TCF EU v2",
+			givenGPP:           "DBABMA~" + tcfSection,
+			expectedSectionIDs: []SectionID{SectionTCFEUV2},
+			expectedSections: map[SectionID]Section{
+				SectionTCFEUV2: {ID: SectionTCFEUV2, Value: tcfSection},
+			},
+		},
+		{
+			description:        "TCF EU v2 and USP v1",
+			givenGPP:           "DBACNY~" + tcfSection + "~1YNN",
+			expectedSectionIDs: []SectionID{SectionTCFEUV2, SectionUSPV1},
+			expectedSections: map[SectionID]Section{
+				SectionTCFEUV2: {ID: SectionTCFEUV2, Value: tcfSection},
+				SectionUSPV1:   {ID: SectionUSPV1, Value: "1YNN", USPrivacy: "1YNN"},
+			},
+		},
+		{
+			description:        "US National",
+			givenGPP:           "DBABLA~" + usNatSection,
+			expectedSectionIDs: []SectionID{SectionUSNat},
+			expectedSections: map[SectionID]Section{
+				SectionUSNat: {ID: SectionUSNat, Value: usNatSection, USPrivacy: "1YNN"},
+			},
+		},
+		{
+			description:        "Range of sections with an invalid one",
+			givenGPP:           encodeBits("000011 000001 000000000001 1 011 0011") + "~" + tcfSection + "~1YNN~1YNN~invalid",
+			expectedSectionIDs: []SectionID{2, 3, 4, 5},
+			expectedSections: map[SectionID]Section{
+				SectionTCFEUV2: {ID: SectionTCFEUV2, Value: tcfSection},
+				3:              {ID: 3, Value: "1YNN"},
+				4:              {ID: 4, Value: "1YNN"},
+				5:              {ID: 5, Value: "invalid"},
+			},
+		},
+		{
+			description:        "Invalid sections",
+			givenGPP:           "DBACNY~invalid~2YNN",
+			expectedSectionIDs: []SectionID{SectionTCFEUV2, SectionUSPV1},
+			expectedSections:   map[SectionID]Section{},
+			expectedErrors: []string{
+				"section 2 is invalid: ",
+				"section 6 is invalid: it must be a valid us_privacy string",
+			},
+		},
+		{
+			description:    "Invalid header type",
+			givenGPP:       "BBABMA~" + tcfSection,
+			expectedErrors: []string{"the header is invalid: the type must be 3. Got 1"},
+		},
+		{
+			description:    "Truncated header",
+			givenGPP:       "DBAB~" + tcfSection,
+			expectedErrors: []string{"the header is invalid: it is too short"},
+		},
+		{
+			description:    "Invalid header character",
+			givenGPP:       "DBA+MA~" + tcfSection,
+			expectedErrors: []string{`the header is invalid: '+' isn't a web safe base64 character`},
+		},
+		{
+			description:    "Missing section",
+			givenGPP:       "DBACNY~" + tcfSection,
+			expectedErrors: []string{"the header lists 2 sections but the string has 1"},
+		},
+	}
+
+	for _, test := range testCases {
+		gpp, errs := Parse(test.givenGPP)
+
+		assert.Equal(t, test.expectedSectionIDs, gpp.SectionIDs, test.description+":section_ids")
+		if test.expectedSections != nil {
+			assert.Equal(t, test.expectedSections, gpp.Sections, test.description+":sections")
+		}
+		if assert.Len(t, errs, len(test.expectedErrors), test.description+":errors") {
+			for i, err := range errs {
+				// The errors of the TCF EU v2 sections are left out, their wording is the one of go-gdpr
+				assert.True(t, strings.HasPrefix(err.Error(), test.expectedErrors[i]), "%s: expected %q, got %q", test.description, test.expectedErrors[i], err.Error())
+			}
+		}
+	}
+}
+
+func TestParseUSSection(t *testing.T) {
+	testCases := []struct {
+		description       string
+		givenID           SectionID
+		givenBits         string
+		expectedUSPrivacy string
+		expectedError     string
+	}{
+		{
+			description: "US National",
+			givenID:     SectionUSNat,
+			// Version, notices, SaleOptOut, SharingOptOut, TargetedAdvertisingOptOut, SensitiveDataProcessing,
+			// KnownChildSensitiveDataConsents, PersonalDataConsents, MspaCoveredTransaction and the MSPA modes
+			givenBits:         "000001 01 10 01 01 01 01 01 01 01 000000000000000000000000 0000 00 01 00 00",
+			expectedUSPrivacy: "1NYY",
+		},
+		{
+			description: "California",
+			givenID:     SectionUSCA,
+			// Version, notices, SaleOptOut, SharingOptOut, SensitiveDataProcessing, KnownChildSensitiveDataConsents,
+			// PersonalDataConsents, MspaCoveredTransaction and the MSPA modes
+			givenBits:         "000001 01 01 01 02 01 000000000000000000 0000 00 02 00 00",
+			expectedUSPrivacy: "1YNN",
+		},
+		{
+			description: "Virginia",
+			givenID:     SectionUSVA,
+			// Version, notices, SaleOptOut, TargetedAdvertisingOptOut, SensitiveDataProcessing,
+			// KnownChildSensitiveDataConsents, MspaCoveredTransaction and the MSPA modes
+			givenBits:         "000001 01 01 01 01 01 0000000000000000 00 01 00 00",
+			expectedUSPrivacy: "1YYY",
+		},
+		{
+			description: "Colorado",
+			givenID:     SectionUSCO,
+			// Version, notices, SaleOptOut, TargetedAdvertisingOptOut, SensitiveDataProcessing,
+			// KnownChildSensitiveDataConsents, MspaCoveredTransaction and the MSPA modes
+			givenBits:         "000001 01 00 01 00 01 00000000000000 00 01 00 00",
+			expectedUSPrivacy: "1--Y",
+		},
+		{
+			description: "Utah",
+			givenID:     SectionUSUT,
+			// Version, notices, SaleOptOut, TargetedAdvertisingOptOut, SensitiveDataProcessing,
+			// KnownChildSensitiveDataConsents, MspaCoveredTransaction and the MSPA modes
+			givenBits:         "000001 01 01 01 01 01 01 0000000000000000 00 02 00 00",
+			expectedUSPrivacy: "1YYN",
+		},
+		{
+			description: "Connecticut",
+			givenID:     SectionUSCT,
+			// Version, notices, SaleOptOut, TargetedAdvertisingOptOut, SensitiveDataProcessing,
+			// KnownChildSensitiveDataConsents, MspaCoveredTransaction and the MSPA modes
+			givenBits:         "000001 01 02 01 02 01 0000000000000000 000000 01 00 00",
+			expectedUSPrivacy: "1NNY",
+		},
+		{
+			description:   "Unknown version",
+			givenID:       SectionUSCA,
+			givenBits:     "000010 01 01 01 02 01 000000000000000000 0000 00 02 00 00",
+			expectedError: "the version must be 1. Got 2",
+		},
+		{
+			description:   "Invalid field value",
+			givenID:       SectionUSCA,
+			givenBits:     "000001 11 01 01 02 01 000000000000000000 0000 00 02 00 00",
+			expectedError: "SaleOptOutNotice must be 0, 1 or 2. Got 3",
+		},
+		{
+			description:   "Truncated core subsection",
+			givenID:       SectionUSCA,
+			givenBits:     "000001 01 01 01 02 01",
+			expectedError: "the core subsection must hold 46 bits. Got 18",
+		},
+	}
+
+	for _, test := range testCases {
+		// The 2s of the bits are the value 2 of the 2 bits fields
+		bits := strings.ReplaceAll(test.givenBits, "02", "10")
+		usPrivacy, err := parseUSSection(test.givenID, encodeBits(bits)+".QA")
+
+		assert.Equal(t, test.expectedUSPrivacy, usPrivacy, test.description)
+		if test.expectedError == "" {
+			assert.NoError(t, err, test.description)
+		} else {
+			assert.EqualError(t, err, test.expectedError, test.description)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package gpp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// Policy represents the GPP regulatory information from an OpenRTB bid request. It is read from regs.ext, since the
+// regs of OpenRTB 2.5 have neither gpp nor gpp_sid.
+type Policy struct {
+	Consent    string
+	SectionIDs []SectionID
+}
+
+// ReadFromRequest extracts the GPP regulatory information from an OpenRTB bid request.
+func ReadFromRequest(req *openrtb2.BidRequest) (Policy, error) {
+	if req == nil || req.Regs == nil || len(req.Regs.Ext) == 0 {
+		return Policy{}, nil
+	}
+
+	var regsExt openrtb_ext.ExtRegs
+	if err := json.Unmarshal(req.Regs.Ext, &regsExt); err != nil {
+		return Policy{}, fmt.Errorf("error reading request.regs.ext: %s", err)
+	}
+
+	policy := Policy{Consent: regsExt.GPP}
+	for _, id := range regsExt.GPPSID {
+		policy.SectionIDs = append(policy.SectionIDs, SectionID(id))
+	}
+	return policy, nil
+}
+
+// ParsedPolicy represents parsed GPP regulatory information. Use this struct to make enforcement decisions.
+type ParsedPolicy struct {
+	GPP        GPP
+	SectionIDs []SectionID
+}
+
+// Parse parses the GPP string of the policy. The errors are warnings, since the sections which could be read still
+// apply to the request.
+func (p Policy) Parse() (ParsedPolicy, []error) {
+	parsed := ParsedPolicy{SectionIDs: p.SectionIDs}
+	if p.Consent == "" {
+		return parsed, nil
+	}
+
+	var errs []error
+	parsed.GPP, errs = Parse(p.Consent)
+	return parsed, errs
+}
+
+// GDPRSignal tells if the request is subject to GDPR, from whether gpp_sid lists the TCF EU v2 section. It signals
+// nothing without gpp_sid.
+func (p ParsedPolicy) GDPRSignal() (applies bool, signaled bool) {
+	if len(p.SectionIDs) == 0 {
+		return false, false
+	}
+	return p.listed(SectionTCFEUV2), true
+}
+
+// TCFConsent returns the consent of the TCF EU v2 section, if it applies to the request
+func (p ParsedPolicy) TCFConsent() (string, bool) {
+	if section, ok := p.section(SectionTCFEUV2); ok {
+		return section.Value, true
+	}
+	return "", false
+}
+
+// USPrivacy returns the us_privacy string of the USP v1 section, or else of the first section of the US laws, which
+// apply to the request
+func (p ParsedPolicy) USPrivacy() (string, bool) {
+	if section, ok := p.section(SectionUSPV1); ok {
+		return section.USPrivacy, true
+	}
+	for _, id := range p.GPP.SectionIDs {
+		if _, isUSSection := usSectionLayouts[id]; !isUSSection {
+			continue
+		}
+		if section, ok := p.section(id); ok {
+			return section.USPrivacy, true
+		}
+	}
+	return "", false
+}
+
+// section returns a valid section of the GPP string which applies to the request. The sections apply when gpp_sid
+// lists them, or when gpp_sid is missing.
+func (p ParsedPolicy) section(id SectionID) (Section, bool) {
+	section, ok := p.GPP.Sections[id]
+	if !ok || (len(p.SectionIDs) > 0 && !p.listed(id)) {
+		return Section{}, false
+	}
+	return section, true
+}
+
+func (p ParsedPolicy) listed(id SectionID) bool {
+	for _, listedID := range p.SectionIDs {
+		if listedID == id {
+			return true
+		}
+	}
+	return false
+}
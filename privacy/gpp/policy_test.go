@@ -0,0 +1,97 @@
+package gpp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadFromRequest(t *testing.T) {
+	testCases := []struct {
+		description    string
+		givenRequest   *openrtb2.BidRequest
+		expectedPolicy Policy
+		expectedError  string
+	}{
+		{
+			description:  "Nil regs",
+			givenRequest: &openrtb2.BidRequest{},
+		},
+		{
+			description:    "GPP and section ids",
+			givenRequest:   &openrtb2.BidRequest{Regs: &openrtb2.Regs{Ext: json.RawMessage(`{"gpp":"DBABTA~1YNN","gpp_sid":[6]}`)}},
+			expectedPolicy: Policy{Consent: "DBABTA~1YNN", SectionIDs: []SectionID{SectionUSPV1}},
+		},
+		{
+			description:   "Malformed regs.ext",
+			givenRequest:  &openrtb2.BidRequest{Regs: &openrtb2.Regs{Ext: json.RawMessage(`malformed`)}},
+			expectedError: "error reading request.regs.ext: invalid character 'm' looking for beginning of value",
+		},
+	}
+
+	for _, test := range testCases {
+		policy, err := ReadFromRequest(test.givenRequest)
+
+		assert.Equal(t, test.expectedPolicy, policy, test.description)
+		if test.expectedError == "" {
+			assert.NoError(t, err, test.description)
+		} else {
+			assert.EqualError(t, err, test.expectedError, test.description)
+		}
+	}
+}
+
+func TestParsedPolicy(t *testing.T) {
+	testCases := []struct {
+		description         string
+		givenPolicy         Policy
+		expectedGDPRApplies bool
+		expectedGDPRSignal  bool
+		expectedTCFConsent  string
+		expectedUSPrivacy   string
+	}{
+		{
+			description: "No GPP",
+		},
+		{
+			description:         "Section ids without GPP",
+			givenPolicy:         Policy{SectionIDs: []SectionID{SectionTCFEUV2}},
+			expectedGDPRApplies: true,
+			expectedGDPRSignal:  true,
+		},
+		{
+			description:        "All sections apply without section ids",
+			givenPolicy:        Policy{Consent: "DBACNY~" + tcfSection + "~1YNN"},
+			expectedTCFConsent: tcfSection,
+			expectedUSPrivacy:  "1YNN",
+		},
+		{
+			description:        "Only the listed sections apply",
+			givenPolicy:        Policy{Consent: "DBACNY~" + tcfSection + "~1YNN", SectionIDs: []SectionID{SectionUSPV1}},
+			expectedGDPRSignal: true,
+			expectedUSPrivacy:  "1YNN",
+		},
+		{
+			description:        "Section of a US law",
+			givenPolicy:        Policy{Consent: "DBABLA~" + usNatSection, SectionIDs: []SectionID{SectionUSNat}},
+			expectedGDPRSignal: true,
+			expectedUSPrivacy:  "1YNN",
+		},
+	}
+
+	for _, test := range testCases {
+		parsed, errs := test.givenPolicy.Parse()
+		assert.Empty(t, errs, test.description+":errors")
+
+		gdprApplies, gdprSignal := parsed.GDPRSignal()
+		tcfConsent, _ := parsed.TCFConsent()
+		usPrivacy, _ := parsed.USPrivacy()
+
+		assert.Equal(t, test.expectedGDPRApplies, gdprApplies, test.description+":gdpr_applies")
+		assert.Equal(t, test.expectedGDPRSignal, gdprSignal, test.description+":gdpr_signal")
+		assert.Equal(t, test.expectedTCFConsent, tcfConsent, test.description+":tcf_consent")
+		assert.Equal(t, test.expectedUSPrivacy, usPrivacy, test.description+":us_privacy")
+	}
+}
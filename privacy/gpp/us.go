@@ -0,0 +1,68 @@
+package gpp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// usSectionLayout locates the fields of the core subsection of the sections of the US laws which make up their
+// us_privacy string. The offsets are in bits, and include the 6 bits of the version.
+type usSectionLayout struct {
+	saleOptOutNotice       int
+	saleOptOut             int
+	mspaCoveredTransaction int
+	length                 int
+}
+
+// usSectionLayouts are the layouts of the version 1 of the sections
+var usSectionLayouts = map[SectionID]usSectionLayout{
+	SectionUSNat: {saleOptOutNotice: 8, saleOptOut: 18, mspaCoveredTransaction: 54, length: 60},
+	SectionUSCA:  {saleOptOutNotice: 6, saleOptOut: 12, mspaCoveredTransaction: 40, length: 46},
+	SectionUSVA:  {saleOptOutNotice: 8, saleOptOut: 12, mspaCoveredTransaction: 34, length: 40},
+	SectionUSCO:  {saleOptOutNotice: 8, saleOptOut: 12, mspaCoveredTransaction: 32, length: 38},
+	SectionUSUT:  {saleOptOutNotice: 8, saleOptOut: 14, mspaCoveredTransaction: 36, length: 42},
+	SectionUSCT:  {saleOptOutNotice: 8, saleOptOut: 12, mspaCoveredTransaction: 38, length: 44},
+}
+
+const usSectionVersion = 1
+
+// parseUSSection reads the sale opt out of a section of the US laws as a us_privacy string. The subsections which
+// follow the core subsection, such as the GPC one, are left unread.
+func parseUSSection(id SectionID, value string) (string, error) {
+	layout := usSectionLayouts[id]
+	core := strings.Split(value, ".")[0]
+
+	reader, err := newBitReader(core)
+	if err != nil {
+		return "", err
+	}
+	if len(reader.values)*6 < layout.length {
+		return "", fmt.Errorf("the core subsection must hold %d bits. Got %d", layout.length, len(reader.values)*6)
+	}
+	if version := reader.readInt(6); version != usSectionVersion {
+		return "", fmt.Errorf("the version must be %d. Got %d", usSectionVersion, version)
+	}
+
+	usPrivacy := []byte{'1', '-', '-', '-'}
+	for i, field := range []struct {
+		name   string
+		offset int
+	}{
+		{"SaleOptOutNotice", layout.saleOptOutNotice},
+		{"SaleOptOut", layout.saleOptOut},
+		{"MspaCoveredTransaction", layout.mspaCoveredTransaction},
+	} {
+		reader.offset = field.offset
+		switch fieldValue := reader.readInt(2); fieldValue {
+		case 0:
+			usPrivacy[i+1] = '-'
+		case 1:
+			usPrivacy[i+1] = 'Y'
+		case 2:
+			usPrivacy[i+1] = 'N'
+		default:
+			return "", fmt.Errorf("%s must be 0, 1 or 2. Got %d", field.name, fieldValue)
+		}
+	}
+	return string(usPrivacy), nil
+}
@@ -58,6 +58,9 @@ const (
 
 	// ScrubStrategyUserID removes the user's buyer id.
 	ScrubStrategyUserID
+
+	// ScrubStrategyUserEIDs removes the user's extended ids.
+	ScrubStrategyUserEIDs
 )
 
 // ScrubStrategyDeviceID defines the approach to remove hardware id and device id data.
@@ -142,6 +145,8 @@ func (scrubber) ScrubUser(user *openrtb2.User, strategy ScrubStrategyUser, geo S
 		userCopy.BuyerUID = ""
 		userCopy.ID = ""
 		userCopy.Ext = scrubUserExtIDs(userCopy.Ext)
+	case ScrubStrategyUserEIDs:
+		userCopy.Ext = scrubUserExtIDs(userCopy.Ext)
 	}
 
 	switch geo {
@@ -371,6 +371,25 @@ func TestScrubUser(t *testing.T) {
 			scrubUser: ScrubStrategyUserNone,
 			scrubGeo:  ScrubStrategyGeoNone,
 		},
+		{
+			description: "User EIDs & Geo None",
+			expected: &openrtb2.User{
+				ID:       "anyID",
+				BuyerUID: "anyBuyerUID",
+				Yob:      42,
+				Gender:   "anyGender",
+				Ext:      json.RawMessage(`{}`),
+				Geo: &openrtb2.Geo{
+					Lat:   123.456,
+					Lon:   678.89,
+					Metro: "some metro",
+					City:  "some city",
+					ZIP:   "some zip",
+				},
+			},
+			scrubUser: ScrubStrategyUserEIDs,
+			scrubGeo:  ScrubStrategyGeoNone,
+		},
 	}
 
 	for _, test := range testCases {
@@ -5,12 +5,13 @@ import (
 	"net/http/pprof"
 	"time"
 
+	"github.com/prebid/prebid-server/account"
 	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/endpoints"
 	"github.com/prebid/prebid-server/version"
 )
 
-func Admin(rateConverter *currency.RateConverter, rateConverterFetchingInterval time.Duration) *http.ServeMux {
+func Admin(rateConverter *currency.RateConverter, rateConverterFetchingInterval time.Duration, accountReloader *account.Reloader, cacheWrites http.Handler) *http.ServeMux {
 	// Add endpoints to the admin server
 	// Making sure to add pprof routes
 	mux := http.NewServeMux()
@@ -23,5 +24,13 @@ func Admin(rateConverter *currency.RateConverter, rateConverterFetchingInterval
 	// Register prebid-server defined admin handlers
 	mux.HandleFunc("/currency/rates", endpoints.NewCurrencyRatesEndpoint(rateConverter, rateConverterFetchingInterval))
 	mux.HandleFunc("/version", endpoints.NewVersionEndpoint(version.Ver, version.Rev))
+	if accountReloader != nil {
+		mux.HandleFunc("/accounts/versions", endpoints.NewAccountVersionsEndpoint(accountReloader))
+	} else {
+		mux.HandleFunc("/accounts/versions", endpoints.NewAccountVersionsEndpoint(nil))
+	}
+	if cacheWrites != nil {
+		mux.Handle("/cache", cacheWrites)
+	}
 	return mux
 }
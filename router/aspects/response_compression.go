@@ -0,0 +1,23 @@
+package aspects
+
+import (
+	"net/http"
+
+	"github.com/NYTimes/gziphandler"
+	"github.com/julienschmidt/httprouter"
+)
+
+// GzipResponse compresses the responses of f with gzip when the client sends "Accept-Encoding: gzip" and the
+// response is at least minSizeBytes long. Smaller responses are written as is.
+func GzipResponse(f httprouter.Handle, minSizeBytes int) (httprouter.Handle, error) {
+	gzipWrapper, err := gziphandler.GzipHandlerWithOpts(gziphandler.MinSize(minSizeBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		gzipWrapper(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			f(w, r, params)
+		})).ServeHTTP(w, r)
+	}, nil
+}
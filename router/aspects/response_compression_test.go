@@ -0,0 +1,87 @@
+package aspects
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGzipResponse(t *testing.T) {
+	largeBody := `{"id":"some-request-id","seatbid":[` + strings.Repeat(`{"bid":[{"id":"bid-id","impid":"imp-id","price":1}],"seat":"appnexus"},`, 50) + `{}]}`
+	smallBody := `{"id":"some-request-id"}`
+
+	testCases := []struct {
+		description      string
+		givenBody        string
+		acceptEncoding   string
+		expectCompressed bool
+	}{
+		{
+			description:      "Large response with gzip accepted",
+			givenBody:        largeBody,
+			acceptEncoding:   "gzip, deflate",
+			expectCompressed: true,
+		},
+		{
+			description:      "Large response without Accept-Encoding",
+			givenBody:        largeBody,
+			acceptEncoding:   "",
+			expectCompressed: false,
+		},
+		{
+			description:      "Small response with gzip accepted",
+			givenBody:        smallBody,
+			acceptEncoding:   "gzip",
+			expectCompressed: false,
+		},
+	}
+
+	for _, test := range testCases {
+		body := test.givenBody
+		handler := func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		}
+
+		gzipHandler, err := GzipResponse(handler, 1024)
+		assert.NoError(t, err, test.description+":handler")
+
+		req := httptest.NewRequest("POST", "/openrtb2/auction", nil)
+		if test.acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", test.acceptEncoding)
+		}
+		recorder := httptest.NewRecorder()
+		gzipHandler(recorder, req, nil)
+
+		respBody := recorder.Body.Bytes()
+		if test.expectCompressed {
+			assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"), test.description+":content_encoding")
+			assert.Less(t, len(respBody), len(test.givenBody), test.description+":compressed_size")
+
+			reader, err := gzip.NewReader(bytes.NewReader(respBody))
+			if assert.NoError(t, err, test.description+":gzip_reader") {
+				respBody, err = ioutil.ReadAll(reader)
+				assert.NoError(t, err, test.description+":gzip_read")
+			}
+		} else {
+			assert.Empty(t, recorder.Header().Get("Content-Encoding"), test.description+":content_encoding")
+		}
+		assert.Equal(t, test.givenBody, string(respBody), test.description+":body")
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"), test.description+":content_type")
+	}
+}
+
+func TestGzipResponseInvalidMinSize(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {}
+
+	_, err := GzipResponse(handler, -1)
+
+	assert.Error(t, err)
+}
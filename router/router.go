@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,9 +17,11 @@ import (
 	"github.com/prebid/prebid-server/endpoints/events"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/util/uuidutil"
+	"github.com/prebid/prebid-server/winnotification"
 
 	"github.com/prebid/prebid-server/metrics"
 
+	"github.com/prebid/prebid-server/account"
 	"github.com/prebid/prebid-server/adapters"
 	"github.com/prebid/prebid-server/adapters/adform"
 	"github.com/prebid/prebid-server/adapters/appnexus"
@@ -29,6 +32,7 @@ import (
 	"github.com/prebid/prebid-server/adapters/rubicon"
 	"github.com/prebid/prebid-server/adapters/sovrn"
 	analyticsConf "github.com/prebid/prebid-server/analytics/config"
+	"github.com/prebid/prebid-server/biddercapture"
 	"github.com/prebid/prebid-server/cache"
 	"github.com/prebid/prebid-server/cache/dummycache"
 	"github.com/prebid/prebid-server/cache/filecache"
@@ -38,7 +42,9 @@ import (
 	infoEndpoints "github.com/prebid/prebid-server/endpoints/info"
 	"github.com/prebid/prebid-server/endpoints/openrtb2"
 	"github.com/prebid/prebid-server/exchange"
+	"github.com/prebid/prebid-server/floors"
 	"github.com/prebid/prebid-server/gdpr"
+	"github.com/prebid/prebid-server/geolocation"
 	metricsConf "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/prebid/prebid-server/pbs"
@@ -46,8 +52,10 @@ import (
 	"github.com/prebid/prebid-server/router/aspects"
 	"github.com/prebid/prebid-server/server/ssl"
 	storedRequestsConf "github.com/prebid/prebid-server/stored_requests/config"
+	"github.com/prebid/prebid-server/tracing"
 	"github.com/prebid/prebid-server/usersync"
 	"github.com/prebid/prebid-server/util/sliceutil"
+	"github.com/prebid/prebid-server/util/task"
 
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
@@ -171,7 +179,11 @@ type Router struct {
 	*httprouter.Router
 	MetricsEngine   *metricsConf.DetailedMetricsEngine
 	ParamsValidator openrtb_ext.BidderParamValidator
-	Shutdown        func()
+	// AccountReloader is set if the accounts are reloaded periodically, to report their versions on the admin server
+	AccountReloader *account.Reloader
+	// CacheWrites is set if the writes to the embedded cache are only served on the admin server
+	CacheWrites http.Handler
+	Shutdown    func()
 }
 
 func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *Router, err error) {
@@ -210,6 +222,10 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		},
 	}
 
+	// The bidders onboarded through config are core bidders once registered, which must be done before the
+	// core bidders are read to load the bidder infos and set up the metrics
+	openrtb_ext.RegisterGenericBidders(genericORTBBidders(cfg.Adapters))
+
 	// Hack because of how legacy handles districtm
 	legacyBidderList := openrtb_ext.CoreBidderNames()
 	legacyBidderList = append(legacyBidderList, openrtb_ext.BidderName("districtm"))
@@ -251,6 +267,9 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		return nil, fmt.Errorf("Prebid Server could not load data cache: %v", err)
 	}
 
+	if errs := analyticsConf.ValidateAccountOptions(&cfg.AccountDefaults); len(errs) > 0 {
+		return nil, fmt.Errorf("The analytics options of account_defaults are invalid: %v", errs)
+	}
 	pbsAnalytics := analyticsConf.NewPBSAnalytics(&cfg.Analytics)
 
 	paramsValidator, err := openrtb_ext.NewBidderParamsValidator(schemaDirectory)
@@ -270,7 +289,19 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 	gdprPerms := gdpr.NewPermissions(context.Background(), cfg.GDPR, gvlVendorIDs, generalHttpClient)
 
 	exchanges = newExchangeMap(cfg)
-	cacheClient := pbc.NewClient(cacheHttpClient, &cfg.CacheURL, &cfg.ExtCacheURL, r.MetricsEngine)
+	var embeddedCache *pbc.EmbeddedCache
+	var asyncCacheClient *pbc.AsyncClient
+	var cacheClient pbc.Client
+	if cfg.CacheURL.Embedded.Enabled {
+		embeddedCache = pbc.NewEmbeddedCache(cfg.CacheURL.Embedded)
+		cacheClient = pbc.NewEmbeddedClient(embeddedCache, &cfg.ExtCacheURL, r.MetricsEngine)
+	} else {
+		cacheClient = pbc.NewClient(cacheHttpClient, &cfg.CacheURL, &cfg.ExtCacheURL, r.MetricsEngine)
+		if cfg.CacheURL.AsyncWrites.Enabled {
+			asyncCacheClient = pbc.NewAsyncClient(cacheClient, cfg.CacheURL.AsyncWrites, r.MetricsEngine)
+			cacheClient = asyncCacheClient
+		}
+	}
 
 	adapters, adaptersErrs := exchange.BuildAdapters(generalHttpClient, cfg, bidderInfos, r.MetricsEngine)
 	if len(adaptersErrs) > 0 {
@@ -278,14 +309,51 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		return nil, errs
 	}
 
-	theExchange := exchange.NewExchange(adapters, cacheClient, cfg, syncersByBidder, r.MetricsEngine, bidderInfos, gdprPerms, rateConvertor, categoriesFetcher)
+	bidderCapturer, err := biddercapture.NewCapturer(cfg.BidderCapture, generalHttpClient)
+	if err != nil {
+		return nil, fmt.Errorf("Prebid Server could not set up the bidder capture: %v", err)
+	}
+	geoResolver, geoShutdown, err := geolocation.NewResolver(cfg.GeoLocation, generalHttpClient, r.MetricsEngine)
+	if err != nil {
+		return nil, fmt.Errorf("Prebid Server could not set up the geolocation: %v", err)
+	}
+	winNotifier := winnotification.NewNotifier(cfg.Event.WinNotifications, generalHttpClient)
+	tracer := tracing.NewTracer(cfg.Tracing, generalHttpClient)
+	var accountReloadTask *task.TickerTask
+	if cfg.AccountReload.Enabled {
+		accountReloader, err := account.NewReloader(accounts, cfg.AccountReload)
+		if err != nil {
+			return nil, fmt.Errorf("Prebid Server could not set up the account reload: %v", err)
+		}
+		accountReloadTask = task.NewTickerTask(cfg.AccountReload.RefreshRateDuration(), accountReloader)
+		accountReloadTask.Start()
+		r.AccountReloader = accountReloader
+		accounts = accountReloader
+	}
+	storedRequestsShutdown := r.Shutdown
+	r.Shutdown = func() {
+		storedRequestsShutdown()
+		bidderCapturer.Shutdown()
+		geoShutdown()
+		tracer.Shutdown()
+		asyncCacheClient.Shutdown()
+		if accountReloadTask != nil {
+			accountReloadTask.Stop()
+		}
+		// The metrics are closed last, to send the ones recorded while the rest shuts down
+		if statsdMetrics := r.MetricsEngine.StatsDMetrics; statsdMetrics != nil {
+			statsdMetrics.Client.Close()
+		}
+	}
+
+	theExchange := exchange.NewExchange(adapters, cacheClient, cfg, syncersByBidder, r.MetricsEngine, bidderInfos, gdprPerms, rateConvertor, categoriesFetcher, floors.NewFetcher(generalHttpClient), bidderCapturer, geoResolver, winNotifier)
 	var uuidGenerator uuidutil.UUIDRandomGenerator
-	openrtbEndpoint, err := openrtb2.NewEndpoint(uuidGenerator, theExchange, paramsValidator, fetcher, accounts, cfg, r.MetricsEngine, pbsAnalytics, disabledBidders, defReqJSON, activeBidders)
+	openrtbEndpoint, err := openrtb2.NewEndpoint(uuidGenerator, theExchange, paramsValidator, fetcher, accounts, cfg, r.MetricsEngine, pbsAnalytics, disabledBidders, defReqJSON, activeBidders, gdprPerms)
 	if err != nil {
 		glog.Fatalf("Failed to create the openrtb2 endpoint handler. %v", err)
 	}
 
-	ampEndpoint, err := openrtb2.NewAmpEndpoint(uuidGenerator, theExchange, paramsValidator, ampFetcher, accounts, cfg, r.MetricsEngine, pbsAnalytics, disabledBidders, defReqJSON, activeBidders)
+	ampEndpoint, err := openrtb2.NewAmpEndpoint(uuidGenerator, theExchange, paramsValidator, ampFetcher, accounts, cfg, r.MetricsEngine, pbsAnalytics, disabledBidders, defReqJSON, activeBidders, gdprPerms)
 	if err != nil {
 		glog.Fatalf("Failed to create the amp endpoint handler. %v", err)
 	}
@@ -295,19 +363,26 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		glog.Fatalf("Failed to create the video endpoint handler. %v", err)
 	}
 
+	if cfg.AuctionResponseCompression.Enabled {
+		openrtbEndpoint, err = aspects.GzipResponse(openrtbEndpoint, cfg.AuctionResponseCompression.MinSizeBytes)
+		if err != nil {
+			glog.Fatalf("Failed to enable the auction endpoint response compression. %v", err)
+		}
+	}
+
 	requestTimeoutHeaders := config.RequestTimeoutHeaders{}
 	if cfg.RequestTimeoutHeaders != requestTimeoutHeaders {
 		videoEndpoint = aspects.QueuedRequestTimeout(videoEndpoint, cfg.RequestTimeoutHeaders, r.MetricsEngine, metrics.ReqTypeVideo)
 	}
 
-	r.POST("/auction", endpoints.Auction(cfg, syncersByBidder, gdprPerms, r.MetricsEngine, dataCache, exchanges))
-	r.POST("/openrtb2/auction", openrtbEndpoint)
-	r.POST("/openrtb2/video", videoEndpoint)
-	r.GET("/openrtb2/amp", ampEndpoint)
-	r.GET("/info/bidders", infoEndpoints.NewBiddersEndpoint(bidderInfos, defaultAliases))
-	r.GET("/info/bidders/:bidderName", infoEndpoints.NewBiddersDetailEndpoint(bidderInfos, cfg.Adapters, defaultAliases))
-	r.GET("/bidders/params", NewJsonDirectoryServer(schemaDirectory, paramsValidator, defaultAliases))
-	r.POST("/cookie_sync", endpoints.NewCookieSyncEndpoint(syncersByBidder, cfg, gdprPerms, r.MetricsEngine, pbsAnalytics, activeBidders).Handle)
+	r.POST("/auction", tracer.Handle("/auction", endpoints.Auction(cfg, syncersByBidder, gdprPerms, r.MetricsEngine, dataCache, exchanges)))
+	r.POST("/openrtb2/auction", tracer.Handle("/openrtb2/auction", openrtbEndpoint))
+	r.POST("/openrtb2/video", tracer.Handle("/openrtb2/video", videoEndpoint))
+	r.GET("/openrtb2/amp", tracer.Handle("/openrtb2/amp", ampEndpoint))
+	r.GET("/info/bidders", tracer.Handle("/info/bidders", infoEndpoints.NewBiddersEndpoint(bidderInfos, defaultAliases)))
+	r.GET("/info/bidders/:bidderName", tracer.Handle("/info/bidders/:bidderName", infoEndpoints.NewBiddersDetailEndpoint(bidderInfos, cfg.Adapters, defaultAliases)))
+	r.GET("/bidders/params", tracer.Handle("/bidders/params", NewJsonDirectoryServer(schemaDirectory, paramsValidator, defaultAliases)))
+	r.POST("/cookie_sync", tracer.Handle("/cookie_sync", endpoints.NewCookieSyncEndpoint(syncersByBidder, cfg, gdprPerms, r.MetricsEngine, pbsAnalytics, accounts, activeBidders).Handle))
 	r.GET("/status", endpoints.NewStatusEndpoint(cfg.StatusResponse))
 	r.GET("/", serveIndex)
 	r.ServeFiles("/static/*filepath", http.Dir("static"))
@@ -315,12 +390,27 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 	// vtrack endpoint
 	if cfg.VTrack.Enabled {
 		vtrackEndpoint := events.NewVTrackEndpoint(cfg, accounts, cacheClient, bidderInfos)
-		r.POST("/vtrack", vtrackEndpoint)
+		r.POST("/vtrack", tracer.Handle("/vtrack", vtrackEndpoint))
+	}
+
+	// embedded cache endpoint
+	if embeddedCache != nil {
+		cachePutEndpoint := tracer.Handle("/cache", endpoints.NewCachePutEndpoint(embeddedCache, cfg.CacheURL.Embedded, cfg.MaxRequestSize))
+		if cfg.CacheURL.Embedded.PublicWrites {
+			r.POST("/cache", cachePutEndpoint)
+			r.PUT("/cache", cachePutEndpoint)
+		} else {
+			cacheWrites := httprouter.New()
+			cacheWrites.POST("/cache", cachePutEndpoint)
+			cacheWrites.PUT("/cache", cachePutEndpoint)
+			r.CacheWrites = cacheWrites
+		}
+		r.GET("/cache", tracer.Handle("/cache", endpoints.NewCacheGetEndpoint(embeddedCache)))
 	}
 
 	// event endpoint
-	eventEndpoint := events.NewEventEndpoint(cfg, accounts, pbsAnalytics)
-	r.GET("/event", eventEndpoint)
+	eventEndpoint := events.NewEventEndpoint(cfg, accounts, pbsAnalytics, winNotifier)
+	r.GET("/event", tracer.Handle("/event", eventEndpoint))
 
 	userSyncDeps := &pbs.UserSyncDeps{
 		HostCookieConfig: &(cfg.HostCookie),
@@ -330,14 +420,27 @@ func New(cfg *config.Configuration, rateConvertor *currency.RateConverter) (r *R
 		PBSAnalytics:     pbsAnalytics,
 	}
 
-	r.GET("/setuid", endpoints.NewSetUIDEndpoint(cfg.HostCookie, syncersByBidder, gdprPerms, pbsAnalytics, r.MetricsEngine))
-	r.GET("/getuids", endpoints.NewGetUIDsEndpoint(cfg.HostCookie))
-	r.POST("/optout", userSyncDeps.OptOut)
-	r.GET("/optout", userSyncDeps.OptOut)
+	r.GET("/setuid", tracer.Handle("/setuid", endpoints.NewSetUIDEndpoint(cfg, syncersByBidder, gdprPerms, accounts, pbsAnalytics, r.MetricsEngine)))
+	r.GET("/getuids", tracer.Handle("/getuids", endpoints.NewGetUIDsEndpoint(cfg.HostCookie)))
+	r.POST("/optout", tracer.Handle("/optout", userSyncDeps.OptOut))
+	r.GET("/optout", tracer.Handle("/optout", userSyncDeps.OptOut))
 
 	return r, nil
 }
 
+// genericORTBBidders returns the names of the bidders onboarded through config, sorted to register them in a
+// stable order
+func genericORTBBidders(adaptersCfg map[string]config.Adapter) []string {
+	var bidders []string
+	for bidderName, adapterCfg := range adaptersCfg {
+		if adapterCfg.GenericORTB != nil {
+			bidders = append(bidders, bidderName)
+		}
+	}
+	sort.Strings(bidders)
+	return bidders
+}
+
 func applyBidderInfoConfigOverrides(bidderInfos config.BidderInfos, adaptersCfg map[string]config.Adapter) error {
 	for bidderName, bidderInfo := range bidderInfos {
 		// bidder name from bidderInfos is case-sensitive, but bidder name from adaptersCfg
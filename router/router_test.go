@@ -47,14 +47,15 @@ func TestNewJsonDirectoryServer(t *testing.T) {
 	var data map[string]json.RawMessage
 	json.Unmarshal(recorder.Body.Bytes(), &data)
 
-	// Make sure that every adapter has a json schema by the same name associated with it.
+	// Make sure that every adapter has a json schema by the same name associated with it. The generic OpenRTB
+	// adapter serves the bidders onboarded through config, which have no schema.
 	adapterFiles, err := ioutil.ReadDir(adapterDirectory)
 	if err != nil {
 		t.Fatalf("Failed to open the adapters directory: %v", err)
 	}
 
 	for _, adapterFile := range adapterFiles {
-		if adapterFile.IsDir() && adapterFile.Name() != "adapterstest" {
+		if adapterFile.IsDir() && adapterFile.Name() != "adapterstest" && adapterFile.Name() != "genericortb" {
 			ensureHasKey(t, data, adapterFile.Name())
 		}
 	}
@@ -74,6 +75,16 @@ func TestExchangeMap(t *testing.T) {
 	}
 }
 
+func TestGenericORTBBidders(t *testing.T) {
+	adaptersCfg := map[string]config.Adapter{
+		"appnexus": {},
+		"genericb": {GenericORTB: &config.GenericORTB{}},
+		"generica": {GenericORTB: &config.GenericORTB{}, Disabled: true},
+	}
+
+	assert.Equal(t, []string{"generica", "genericb"}, genericORTBBidders(adaptersCfg))
+}
+
 func TestApplyBidderInfoConfigOverrides(t *testing.T) {
 	var testCases = []struct {
 		description         string
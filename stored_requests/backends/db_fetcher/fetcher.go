@@ -11,7 +11,7 @@ import (
 	"github.com/prebid/prebid-server/stored_requests"
 )
 
-func NewFetcher(db *sql.DB, queryMaker func(int, int) string) stored_requests.AllFetcher {
+func NewFetcher(db *sql.DB, queryMaker func(int, int) string, responseQueryMaker func(int) string) stored_requests.AllFetcher {
 	if db == nil {
 		glog.Fatalf("The Postgres Stored Request Fetcher requires a database connection. Please report this as a bug.")
 	}
@@ -19,8 +19,9 @@ func NewFetcher(db *sql.DB, queryMaker func(int, int) string) stored_requests.Al
 		glog.Fatalf("The Postgres Stored Request Fetcher requires a queryMaker function. Please report this as a bug.")
 	}
 	return &dbFetcher{
-		db:         db,
-		queryMaker: queryMaker,
+		db:                 db,
+		queryMaker:         queryMaker,
+		responseQueryMaker: responseQueryMaker,
 	}
 }
 
@@ -28,6 +29,8 @@ func NewFetcher(db *sql.DB, queryMaker func(int, int) string) stored_requests.Al
 type dbFetcher struct {
 	db         *sql.DB
 	queryMaker func(numReqs int, numImps int) (query string)
+	// responseQueryMaker builds the query of the Stored Responses, which is empty if none is configured
+	responseQueryMaker func(numIDs int) (query string)
 }
 
 func (fetcher *dbFetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (map[string]json.RawMessage, map[string]json.RawMessage, []error) {
@@ -93,6 +96,55 @@ func (fetcher *dbFetcher) FetchRequests(ctx context.Context, requestIDs []string
 	return storedRequestData, storedImpData, errs
 }
 
+func (fetcher *dbFetcher) FetchResponses(ctx context.Context, ids []string) (map[string]json.RawMessage, []error) {
+	if len(ids) < 1 {
+		return nil, nil
+	}
+
+	var query string
+	if fetcher.responseQueryMaker != nil {
+		query = fetcher.responseQueryMaker(len(ids))
+	}
+	if query == "" {
+		return nil, appendErrors("Response", ids, nil, nil)
+	}
+
+	idInterfaces := make([]interface{}, len(ids))
+	for i := 0; i < len(ids); i++ {
+		idInterfaces[i] = ids[i]
+	}
+
+	rows, err := fetcher.db.QueryContext(ctx, query, idInterfaces...)
+	if err != nil {
+		if err != context.DeadlineExceeded && !isBadInput(err) {
+			glog.Errorf("Error reading from Stored Response DB: %s", err.Error())
+			return nil, appendErrors("Response", ids, nil, nil)
+		}
+		return nil, []error{err}
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			glog.Errorf("error closing DB connection: %v", err)
+		}
+	}()
+
+	storedResponseData := make(map[string]json.RawMessage, len(ids))
+	for rows.Next() {
+		var id string
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, []error{err}
+		}
+		storedResponseData[id] = data
+	}
+
+	if rows.Err() != nil {
+		return nil, []error{rows.Err()}
+	}
+
+	return storedResponseData, appendErrors("Response", ids, storedResponseData, nil)
+}
+
 func (fetcher *dbFetcher) FetchAccount(ctx context.Context, accountID string) (json.RawMessage, []error) {
 	return nil, []error{stored_requests.NotFoundError{accountID, "Account"}}
 }
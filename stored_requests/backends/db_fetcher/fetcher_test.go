@@ -173,6 +173,53 @@ func TestRowErrors(t *testing.T) {
 	assertMapLength(t, 0, data)
 }
 
+// TestResponses makes sure we interpret DB responses properly when some of the stored responses are there.
+func TestResponses(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	mockQuery := "SELECT id, data FROM resp_table WHERE id IN ($1, $2)"
+	mockReturn := sqlmock.NewRows([]string{"id", "data"}).
+		AddRow("resp-id", `[{"seat":"appnexus"}]`)
+	mock.ExpectQuery(fmt.Sprintf("^%s$", regexp.QuoteMeta(mockQuery))).WithArgs("resp-id", "resp-id-2").WillReturnRows(mockReturn)
+	fetcher := &dbFetcher{
+		db: db,
+		responseQueryMaker: func(numIDs int) string {
+			return mockQuery
+		},
+	}
+
+	storedResps, errs := fetcher.FetchResponses(context.Background(), []string{"resp-id", "resp-id-2"})
+
+	assertMockExpectations(t, mock)
+	assertErrorCount(t, 1, errs)
+	assertMapLength(t, 1, storedResps)
+	assertHasData(t, storedResps, "resp-id", `[{"seat":"appnexus"}]`)
+}
+
+// TestResponsesNotConfigured makes sure no stored responses are found when there is no response query.
+func TestResponsesNotConfigured(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	fetcher := &dbFetcher{
+		db: db,
+		responseQueryMaker: func(numIDs int) string {
+			return ""
+		},
+	}
+
+	storedResps, errs := fetcher.FetchResponses(context.Background(), []string{"resp-id"})
+	assertErrorCount(t, 1, errs)
+	assertMapLength(t, 0, storedResps)
+}
+
 func newFetcher(t *testing.T, rows *sqlmock.Rows, query string, args ...driver.Value) (sqlmock.Sqlmock, *dbFetcher) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
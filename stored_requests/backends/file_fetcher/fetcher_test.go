@@ -35,7 +35,17 @@ func TestAccountFetcher(t *testing.T) {
 	account, errs = fetcher.FetchAccount(context.Background(), "nonexistent")
 	assertErrorCount(t, 1, errs)
 	assert.Error(t, errs[0])
-	assert.Equal(t, stored_requests.NotFoundError{"nonexistent", "Account"}, errs[0])
+	assert.Equal(t, stored_requests.NotFoundError{ID: "nonexistent", DataType: "Account"}, errs[0])
+}
+
+func TestResponseFetcher(t *testing.T) {
+	fetcher, err := NewFileFetcher("./test")
+	assert.NoError(t, err, "Failed to create test fetcher")
+
+	storedResponses, errs := fetcher.FetchResponses(context.Background(), []string{"some-response", "nonexistent"})
+	assertErrorCount(t, 1, errs)
+	assert.Equal(t, stored_requests.NotFoundError{ID: "nonexistent", DataType: "Response"}, errs[0])
+	assert.JSONEq(t, `[{"seat":"appnexus","bid":[{"id":"stored-bid","impid":"some-imp","price":0.5,"adm":"<div>stored</div>"}]}]`, string(storedResponses["some-response"]))
 }
 
 func TestInvalidDirectory(t *testing.T) {
@@ -26,6 +26,9 @@ import (
 // Accounts
 // GET {endpoint}?account-ids=["acc1","acc2"]
 //
+// Stored responses
+// GET {endpoint}?response-ids=["resp1","resp2"]
+//
 // The above endpoints should return a payload like:
 //
 // {
@@ -41,6 +44,13 @@ import (
 // }
 // or
 // {
+//   "responses": {
+//     "resp1": [ ... stored seatbids of resp1 ... ],
+//     "resp2": null // If resp2 is not found
+//   },
+// }
+// or
+// {
 //   "accounts": {
 //     "acc1": { ... config data for acc1 ... },
 //     "acc2": { ... config data for acc2 ... },
@@ -97,6 +107,50 @@ func (fetcher *HttpFetcher) FetchRequests(ctx context.Context, requestIDs []stri
 	return
 }
 
+// FetchResponses retrieves the stored responses
+func (fetcher *HttpFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", fetcher.Endpoint+"response-ids=[\""+strings.Join(ids, "\",\"")+"\"]", nil)
+	if err != nil {
+		return nil, []error{
+			fmt.Errorf(`Error fetching stored responses %v via http: build request failed with %v`, ids, err),
+		}
+	}
+	httpResp, err := ctxhttp.Do(ctx, fetcher.client, httpReq)
+	if err != nil {
+		return nil, []error{
+			fmt.Errorf(`Error fetching stored responses %v via http: %v`, ids, err),
+		}
+	}
+	defer httpResp.Body.Close()
+	respBytes, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, []error{
+			fmt.Errorf(`Error fetching stored responses %v via http: error reading response: %v`, ids, err),
+		}
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, []error{
+			fmt.Errorf(`Error fetching stored responses %v via http: unexpected response status %d`, ids, httpResp.StatusCode),
+		}
+	}
+	var responseData storedResponsesContract
+	if err = json.Unmarshal(respBytes, &responseData); err != nil {
+		return nil, []error{
+			fmt.Errorf(`Error fetching stored responses %v via http: failed to parse response: %v`, ids, err),
+		}
+	}
+	for _, id := range ids {
+		if _, ok := responseData.Responses[id]; !ok {
+			errs = append(errs, stored_requests.NotFoundError{ID: id, DataType: "Response"})
+		}
+	}
+	errs = convertNullsToErrs(responseData.Responses, "Response", errs)
+	return responseData.Responses, errs
+}
+
 // FetchAccounts retrieves account configurations
 //
 // Request format is similar to the one for requests:
@@ -267,6 +321,10 @@ type responseContract struct {
 	Imps     map[string]json.RawMessage `json:"imps"`
 }
 
+type storedResponsesContract struct {
+	Responses map[string]json.RawMessage `json:"responses"`
+}
+
 type accountsResponseContract struct {
 	Accounts map[string]json.RawMessage `json:"accounts"`
 }
@@ -159,6 +159,33 @@ func TestFetchAccountNoIDProvided(t *testing.T) {
 	assert.Nil(t, account, "Fetching account with empty id should return nil")
 }
 
+func TestFetchResponses(t *testing.T) {
+	fetcher, close := newTestResponseFetcher(t, []string{"resp-1", "resp-2", "resp-3"})
+	defer close()
+
+	respData, errs := fetcher.FetchResponses(context.Background(), []string{"resp-1", "resp-2", "resp-3"})
+	assertMapKeys(t, respData, "resp-1")
+	assertSameErrMsgs(t, []string{`Stored Response with ID="resp-3" not found.`, `Stored Response with ID="resp-2" not found.`}, errs)
+}
+
+func TestFetchResponsesNoData(t *testing.T) {
+	fetcher, close := newFetcherBrokenBackend()
+	defer close()
+
+	respData, errs := fetcher.FetchResponses(context.Background(), []string{"resp-1"})
+	assert.Len(t, errs, 1, "Fetching from a broken backend should have returned an error")
+	assert.Nil(t, respData, "Fetching from a broken backend should return a nil response map")
+}
+
+func TestFetchResponsesNoIDsProvided(t *testing.T) {
+	fetcher, close := newFetcherBrokenBackend()
+	defer close()
+
+	respData, errs := fetcher.FetchResponses(context.Background(), nil)
+	assert.Empty(t, errs, "Fetching no responses should not return errors")
+	assert.Nil(t, respData, "Fetching no responses should return a nil response map")
+}
+
 func TestErrResponse(t *testing.T) {
 	fetcher, close := newFetcherBrokenBackend()
 	defer close()
@@ -304,6 +331,30 @@ func newAccountHandler(t *testing.T, expectAccIDs []string, jsonifier func(strin
 	}
 }
 
+// newTestResponseFetcher returns the first of the expected ids, null for the second, and leaves the others out
+func newTestResponseFetcher(t *testing.T, expectRespIDs []string) (fetcher *HttpFetcher, closer func()) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		gotRespIDs := richSplit(r.URL.Query().Get("response-ids"))
+		assertMatches(t, gotRespIDs, expectRespIDs)
+
+		respObj := storedResponsesContract{
+			Responses: map[string]json.RawMessage{
+				expectRespIDs[0]: json.RawMessage(`[{"seat":"appnexus"}]`),
+				expectRespIDs[1]: jsonifyToNull(expectRespIDs[1]),
+			},
+		}
+
+		if respBytes, err := json.Marshal(respObj); err != nil {
+			t.Errorf("failed to marshal storedResponsesContract in test:  %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+		} else {
+			w.Write(respBytes)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	return NewFetcher(server.Client(), server.URL), server.Close
+}
+
 func assertMatches(t *testing.T, queryVals []string, expected []string) {
 	t.Helper()
 
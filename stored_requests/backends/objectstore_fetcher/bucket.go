@@ -0,0 +1,196 @@
+package objectstore_fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// maxConcurrentGets caps the number of objects which GetAll downloads at the same time
+const maxConcurrentGets = 8
+
+// ErrNotFound is returned when the bucket has no object with the key
+var ErrNotFound = errors.New("object not found")
+
+// ErrNotModified is returned when the object still has the entity tag given with a conditional GET
+var ErrNotModified = errors.New("object not modified")
+
+// Bucket reads the objects of an S3 or GCS bucket through the S3 compatible REST API. Its requests are signed with
+// HMAC credentials if they are given.
+type Bucket struct {
+	client          *http.Client
+	endpoint        string
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	now             func() time.Time
+}
+
+// Object is the data of a stored object and its entity tag, which changes whenever the data does
+type Object struct {
+	Data json.RawMessage
+	ETag string
+}
+
+// GetResult is the outcome of getting the object with the key from the bucket
+type GetResult struct {
+	Key    string
+	Object Object
+	Err    error
+}
+
+// NewBucket returns a Bucket which uses the Client to read the objects from the endpoint. The endpoint is the url of the
+// bucket, like https://s3.us-east-1.amazonaws.com/bucket or https://storage.googleapis.com/bucket.
+func NewBucket(client *http.Client, endpoint string, region string, accessKeyID string, secretAccessKey string) *Bucket {
+	return &Bucket{
+		client:          client,
+		endpoint:        strings.TrimSuffix(endpoint, "/"),
+		region:          region,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		now:             time.Now,
+	}
+}
+
+// Get downloads the object with the key. If the entity tag the object is already known with is given, the GET is
+// conditional and ErrNotModified is returned if the object still has it.
+func (b *Bucket) Get(ctx context.Context, key string, etag string) (Object, error) {
+	httpReq, err := b.newRequest(ctx, "/"+escapeKey(key), nil)
+	if err != nil {
+		return Object{}, err
+	}
+	if etag != "" {
+		httpReq.Header.Set("If-None-Match", etag)
+	}
+
+	httpResp, err := ctxhttp.Do(ctx, b.client, httpReq)
+	if err != nil {
+		return Object{}, fmt.Errorf("Error getting object %s: %v", key, err)
+	}
+	defer httpResp.Body.Close()
+
+	respBytes, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return Object{}, fmt.Errorf("Error getting object %s: error reading response: %v", key, err)
+	}
+
+	switch httpResp.StatusCode {
+	case http.StatusOK:
+		return Object{Data: respBytes, ETag: httpResp.Header.Get("ETag")}, nil
+	case http.StatusNotModified:
+		return Object{}, ErrNotModified
+	case http.StatusNotFound:
+		return Object{}, ErrNotFound
+	default:
+		return Object{}, fmt.Errorf("Error getting object %s: unexpected response status %d", key, httpResp.StatusCode)
+	}
+}
+
+// GetAll downloads the objects with the keys, a few at a time. The entity tags of the objects already known may be
+// given to only download them again if they have been modified. The results are in the order of the keys.
+func (b *Bucket) GetAll(ctx context.Context, keys []string, etags map[string]string) []GetResult {
+	results := make([]GetResult, len(keys))
+	semaphore := make(chan struct{}, maxConcurrentGets)
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, key string) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			object, err := b.Get(ctx, key, etags[key])
+			results[i] = GetResult{Key: key, Object: object, Err: err}
+		}(i, key)
+	}
+	wg.Wait()
+	return results
+}
+
+// List returns the entity tags of all the objects whose key starts with the prefix, by key
+func (b *Bucket) List(ctx context.Context, prefix string) (map[string]string, error) {
+	etags := make(map[string]string)
+	continuationToken := ""
+	for {
+		query := url.Values{"list-type": []string{"2"}, "prefix": []string{prefix}}
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+		httpReq, err := b.newRequest(ctx, "", query)
+		if err != nil {
+			return nil, err
+		}
+
+		httpResp, err := ctxhttp.Do(ctx, b.client, httpReq)
+		if err != nil {
+			return nil, fmt.Errorf("Error listing objects %s: %v", prefix, err)
+		}
+		respBytes, err := ioutil.ReadAll(httpResp.Body)
+		httpResp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("Error listing objects %s: error reading response: %v", prefix, err)
+		}
+		if httpResp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("Error listing objects %s: unexpected response status %d", prefix, httpResp.StatusCode)
+		}
+
+		var result listBucketResult
+		if err := xml.Unmarshal(respBytes, &result); err != nil {
+			return nil, fmt.Errorf("Error listing objects %s: failed to parse response: %v", prefix, err)
+		}
+		for _, object := range result.Contents {
+			etags[object.Key] = object.ETag
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return etags, nil
+		}
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+// newRequest builds a GET request of the path in the bucket, signed if the bucket has credentials
+func (b *Bucket) newRequest(ctx context.Context, escapedPath string, query url.Values) (*http.Request, error) {
+	rawURL := b.endpoint + escapedPath
+	if len(query) > 0 {
+		rawURL += "?" + query.Encode()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Error building request %s: %v", rawURL, err)
+	}
+	if b.accessKeyID != "" {
+		signRequest(httpReq, b.region, b.accessKeyID, b.secretAccessKey, b.now())
+	}
+	return httpReq, nil
+}
+
+// escapeKey escapes each segment of the key for the path of a request url
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// listBucketResult is the part of the ListObjectsV2 response which the Bucket reads
+type listBucketResult struct {
+	Contents []struct {
+		Key  string `xml:"Key"`
+		ETag string `xml:"ETag"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
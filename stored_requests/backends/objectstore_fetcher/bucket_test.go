@@ -0,0 +1,160 @@
+package objectstore_fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	server, handler := newMockBucketServer(map[string]string{"dir/object.json": `{"value":1}`})
+	defer server.Close()
+	bucket := NewBucket(server.Client(), server.URL+"/bucket", "us-east-1", "", "")
+
+	object, err := bucket.Get(context.Background(), "dir/object.json", "")
+	assert.NoError(t, err)
+	assert.Equal(t, Object{Data: json.RawMessage(`{"value":1}`), ETag: `"etag-dir/object.json"`}, object)
+
+	_, err = bucket.Get(context.Background(), "dir/object.json", `"etag-dir/object.json"`)
+	assert.Equal(t, ErrNotModified, err)
+
+	_, err = bucket.Get(context.Background(), "dir/missing.json", "")
+	assert.Equal(t, ErrNotFound, err)
+
+	handler.statusCode = http.StatusForbidden
+	_, err = bucket.Get(context.Background(), "dir/object.json", "")
+	assert.EqualError(t, err, "Error getting object dir/object.json: unexpected response status 403")
+}
+
+func TestGetSigned(t *testing.T) {
+	server, handler := newMockBucketServer(map[string]string{"object.json": `{}`})
+	defer server.Close()
+
+	_, err := NewBucket(server.Client(), server.URL+"/bucket", "us-east-1", "", "").Get(context.Background(), "object.json", "")
+	assert.NoError(t, err)
+	assert.Empty(t, handler.authorization, "Requests should not be signed without credentials")
+
+	_, err = NewBucket(server.Client(), server.URL+"/bucket", "us-east-1", "access-key", "secret-key").Get(context.Background(), "object.json", "")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(handler.authorization, "AWS4-HMAC-SHA256 Credential=access-key/"), "Requests should be signed with the credentials")
+}
+
+func TestGetAll(t *testing.T) {
+	server, _ := newMockBucketServer(map[string]string{"a.json": `"a"`, "b.json": `"b"`})
+	defer server.Close()
+	bucket := NewBucket(server.Client(), server.URL+"/bucket", "us-east-1", "", "")
+
+	results := bucket.GetAll(context.Background(), []string{"a.json", "b.json", "c.json"}, map[string]string{"b.json": `"etag-b.json"`})
+
+	assert.Equal(t, []GetResult{
+		{Key: "a.json", Object: Object{Data: json.RawMessage(`"a"`), ETag: `"etag-a.json"`}},
+		{Key: "b.json", Err: ErrNotModified},
+		{Key: "c.json", Err: ErrNotFound},
+	}, results)
+}
+
+func TestList(t *testing.T) {
+	server, handler := newMockBucketServer(map[string]string{
+		"prefix/a.json":       `"a"`,
+		"prefix/dir/b.json":   `"b"`,
+		"prefix/dir/c.json":   `"c"`,
+		"other-prefix/d.json": `"d"`,
+	})
+	defer server.Close()
+	handler.pageSize = 2
+	bucket := NewBucket(server.Client(), server.URL+"/bucket", "us-east-1", "", "")
+
+	etags, err := bucket.List(context.Background(), "prefix/")
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"prefix/a.json":     `"etag-prefix/a.json"`,
+		"prefix/dir/b.json": `"etag-prefix/dir/b.json"`,
+		"prefix/dir/c.json": `"etag-prefix/dir/c.json"`,
+	}, etags)
+
+	handler.statusCode = http.StatusInternalServerError
+	_, err = bucket.List(context.Background(), "prefix/")
+	assert.EqualError(t, err, "Error listing objects prefix/: unexpected response status 500")
+}
+
+// mockBucketHandler serves the objects of a bucket named "bucket" through the S3 compatible REST API. The entity tag
+// of an object is made of its key.
+type mockBucketHandler struct {
+	mutex         sync.Mutex
+	objects       map[string]string
+	pageSize      int
+	statusCode    int
+	authorization string
+}
+
+func newMockBucketServer(objects map[string]string) (*httptest.Server, *mockBucketHandler) {
+	handler := &mockBucketHandler{objects: objects}
+	return httptest.NewServer(handler), handler
+}
+
+func (m *mockBucketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.authorization = r.Header.Get("Authorization")
+	if m.statusCode != 0 {
+		w.WriteHeader(m.statusCode)
+		return
+	}
+
+	if r.URL.Path == "/bucket" {
+		m.list(w, r)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+	data, ok := m.objects[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	etag := `"etag-` + key + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Write([]byte(data))
+}
+
+func (m *mockBucketHandler) list(w http.ResponseWriter, r *http.Request) {
+	var keys []string
+	for key := range m.objects {
+		if strings.HasPrefix(key, r.URL.Query().Get("prefix")) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	start, _ := strconv.Atoi(r.URL.Query().Get("continuation-token"))
+	end := len(keys)
+	if m.pageSize > 0 && start+m.pageSize < end {
+		end = start + m.pageSize
+	}
+
+	var body strings.Builder
+	body.WriteString(`<?xml version="1.0" encoding="UTF-8"?><ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`)
+	for _, key := range keys[start:end] {
+		fmt.Fprintf(&body, "<Contents><Key>%s</Key><ETag>&quot;etag-%s&quot;</ETag></Contents>", key, key)
+	}
+	if end < len(keys) {
+		fmt.Fprintf(&body, "<IsTruncated>true</IsTruncated><NextContinuationToken>%d</NextContinuationToken>", end)
+	} else {
+		body.WriteString("<IsTruncated>false</IsTruncated>")
+	}
+	body.WriteString("</ListBucketResult>")
+	w.Write([]byte(body.String()))
+}
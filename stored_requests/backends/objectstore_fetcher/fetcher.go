@@ -0,0 +1,145 @@
+package objectstore_fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/prebid/prebid-server/stored_requests"
+
+	"github.com/golang/glog"
+)
+
+// Directories of the objects under the prefix of the fetcher, the same as the directories of the file fetcher
+const (
+	RequestsDirectory  = "stored_requests"
+	ImpsDirectory      = "stored_imps"
+	ResponsesDirectory = "stored_responses"
+	AccountsDirectory  = "accounts"
+)
+
+// NewFetcher returns a Fetcher which reads the data from the objects of the bucket under the prefix.
+//
+// The objects are expected to be laid out like the files of the file fetcher:
+//
+// {prefix}/stored_requests/{id}.json
+// {prefix}/stored_imps/{id}.json
+// {prefix}/stored_responses/{id}.json
+// {prefix}/accounts/{id}.json
+//
+// The fetcher remembers the objects it has read along with their entity tags, so that the objects which are fetched
+// again are only downloaded if they have been modified since.
+func NewFetcher(bucket *Bucket, prefix string) *ObjectStoreFetcher {
+	glog.Infof("Making objectstore_fetcher for bucket %s with prefix %s", bucket.endpoint, prefix)
+	return &ObjectStoreFetcher{
+		bucket:  bucket,
+		prefix:  prefix,
+		objects: make(map[string]Object),
+	}
+}
+
+type ObjectStoreFetcher struct {
+	bucket  *Bucket
+	prefix  string
+	mutex   sync.Mutex
+	objects map[string]Object
+}
+
+func (fetcher *ObjectStoreFetcher) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (requestData map[string]json.RawMessage, impData map[string]json.RawMessage, errs []error) {
+	requestData, requestErrs := fetcher.fetchObjects(ctx, RequestsDirectory, requestIDs, "Request")
+	impData, impErrs := fetcher.fetchObjects(ctx, ImpsDirectory, impIDs, "Imp")
+	return requestData, impData, append(requestErrs, impErrs...)
+}
+
+// FetchResponses fetches the stored responses from the stored_responses directory
+func (fetcher *ObjectStoreFetcher) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	return fetcher.fetchObjects(ctx, ResponsesDirectory, ids, "Response")
+}
+
+// FetchAccounts fetches the account configurations from the accounts directory
+func (fetcher *ObjectStoreFetcher) FetchAccounts(ctx context.Context, accountIDs []string) (map[string]json.RawMessage, []error) {
+	return fetcher.fetchObjects(ctx, AccountsDirectory, accountIDs, "Account")
+}
+
+// FetchAccount fetchers a single accountID and returns its corresponding json
+func (fetcher *ObjectStoreFetcher) FetchAccount(ctx context.Context, accountID string) (accountJSON json.RawMessage, errs []error) {
+	accountData, errs := fetcher.FetchAccounts(ctx, []string{accountID})
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return accountData[accountID], nil
+}
+
+func (fetcher *ObjectStoreFetcher) FetchCategories(ctx context.Context, primaryAdServer, publisherId, iabCategory string) (string, error) {
+	return "", fmt.Errorf("Categories are not supported by the object storage fetcher")
+}
+
+// fetchObjects reads the objects of the ids from the directory, conditionally for the objects read before
+func (fetcher *ObjectStoreFetcher) fetchObjects(ctx context.Context, directory string, ids []string, dataType string) (data map[string]json.RawMessage, errs []error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(ids))
+	keyIDs := make(map[string]string, len(ids))
+	etags := make(map[string]string, len(ids))
+	fetcher.mutex.Lock()
+	for _, id := range ids {
+		// The ids name objects in their directory only
+		if id == "" || strings.Contains(id, "/") {
+			errs = append(errs, stored_requests.NotFoundError{ID: id, DataType: dataType})
+			continue
+		}
+		key := ObjectKey(fetcher.prefix, directory, id)
+		keys = append(keys, key)
+		keyIDs[key] = id
+		if object, ok := fetcher.objects[key]; ok {
+			etags[key] = object.ETag
+		}
+	}
+	fetcher.mutex.Unlock()
+
+	results := fetcher.bucket.GetAll(ctx, keys, etags)
+
+	data = make(map[string]json.RawMessage, len(results))
+	fetcher.mutex.Lock()
+	defer fetcher.mutex.Unlock()
+	for _, result := range results {
+		id := keyIDs[result.Key]
+		switch {
+		case result.Err == nil:
+			if result.Object.ETag != "" {
+				fetcher.objects[result.Key] = result.Object
+			}
+			data[id] = result.Object.Data
+		case errors.Is(result.Err, ErrNotModified):
+			if object, ok := fetcher.objects[result.Key]; ok {
+				data[id] = object.Data
+			} else {
+				errs = append(errs, fmt.Errorf("Error fetching Stored %s %s from object storage: object is not modified, but its data is unknown", dataType, id))
+			}
+		case errors.Is(result.Err, ErrNotFound):
+			delete(fetcher.objects, result.Key)
+			errs = append(errs, stored_requests.NotFoundError{ID: id, DataType: dataType})
+		default:
+			errs = append(errs, result.Err)
+		}
+	}
+	return data, errs
+}
+
+// ObjectKey returns the key of the object holding the data of the id in the directory under the prefix
+func ObjectKey(prefix string, directory string, id string) string {
+	return DirectoryKeyPrefix(prefix, directory) + id + ".json"
+}
+
+// DirectoryKeyPrefix returns the prefix of the keys of the objects in the directory under the prefix
+func DirectoryKeyPrefix(prefix string, directory string) string {
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		return prefix + "/" + directory + "/"
+	}
+	return directory + "/"
+}
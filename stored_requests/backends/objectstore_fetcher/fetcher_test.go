@@ -0,0 +1,93 @@
+package objectstore_fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/prebid-server/stored_requests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchRequests(t *testing.T) {
+	server, _ := newMockBucketServer(map[string]string{
+		"prefix/stored_requests/req-1.json": `{"tmax":1000}`,
+		"prefix/stored_imps/imp-1.json":     `{"id":"imp-1"}`,
+		"prefix/stored_imps/imp-2.json":     `{"id":"imp-2"}`,
+	})
+	defer server.Close()
+	fetcher := NewFetcher(NewBucket(server.Client(), server.URL+"/bucket", "us-east-1", "", ""), "/prefix/")
+
+	requestData, impData, errs := fetcher.FetchRequests(context.Background(), []string{"req-1", "req-2"}, []string{"imp-1", "imp-2", "../stored_requests/req-1"})
+
+	assert.Equal(t, map[string]json.RawMessage{"req-1": json.RawMessage(`{"tmax":1000}`)}, requestData)
+	assert.Equal(t, map[string]json.RawMessage{"imp-1": json.RawMessage(`{"id":"imp-1"}`), "imp-2": json.RawMessage(`{"id":"imp-2"}`)}, impData)
+	assert.ElementsMatch(t, []error{
+		stored_requests.NotFoundError{ID: "req-2", DataType: "Request"},
+		stored_requests.NotFoundError{ID: "../stored_requests/req-1", DataType: "Imp"},
+	}, errs)
+}
+
+func TestFetchRequestsConditionally(t *testing.T) {
+	server, handler := newMockBucketServer(map[string]string{"stored_requests/req-1.json": `{"tmax":1000}`})
+	defer server.Close()
+	fetcher := NewFetcher(NewBucket(server.Client(), server.URL+"/bucket", "us-east-1", "", ""), "")
+
+	requestData, _, errs := fetcher.FetchRequests(context.Background(), []string{"req-1"}, nil)
+	assert.Empty(t, errs)
+	assert.Equal(t, map[string]json.RawMessage{"req-1": json.RawMessage(`{"tmax":1000}`)}, requestData)
+
+	// The mock answers with 304 to the conditional GET, so the data must come from the previous fetch
+	requestData, _, errs = fetcher.FetchRequests(context.Background(), []string{"req-1"}, nil)
+	assert.Empty(t, errs)
+	assert.Equal(t, map[string]json.RawMessage{"req-1": json.RawMessage(`{"tmax":1000}`)}, requestData)
+
+	delete(handler.objects, "stored_requests/req-1.json")
+	requestData, _, errs = fetcher.FetchRequests(context.Background(), []string{"req-1"}, nil)
+	assert.Equal(t, []error{stored_requests.NotFoundError{ID: "req-1", DataType: "Request"}}, errs)
+	assert.Empty(t, requestData)
+	assert.Empty(t, fetcher.objects, "Deleted objects should be forgotten")
+}
+
+func TestFetchResponses(t *testing.T) {
+	server, _ := newMockBucketServer(map[string]string{"stored_responses/resp-1.json": `[{"seat":"appnexus"}]`})
+	defer server.Close()
+	fetcher := NewFetcher(NewBucket(server.Client(), server.URL+"/bucket", "us-east-1", "", ""), "")
+
+	data, errs := fetcher.FetchResponses(context.Background(), []string{"resp-1", "resp-2"})
+
+	assert.Equal(t, map[string]json.RawMessage{"resp-1": json.RawMessage(`[{"seat":"appnexus"}]`)}, data)
+	assert.Equal(t, []error{stored_requests.NotFoundError{ID: "resp-2", DataType: "Response"}}, errs)
+}
+
+func TestFetchAccount(t *testing.T) {
+	server, handler := newMockBucketServer(map[string]string{"accounts/acc-1.json": `{"disabled":false}`})
+	defer server.Close()
+	fetcher := NewFetcher(NewBucket(server.Client(), server.URL+"/bucket", "us-east-1", "", ""), "")
+
+	account, errs := fetcher.FetchAccount(context.Background(), "acc-1")
+	assert.Empty(t, errs)
+	assert.Equal(t, json.RawMessage(`{"disabled":false}`), account)
+
+	account, errs = fetcher.FetchAccount(context.Background(), "acc-2")
+	assert.Equal(t, []error{stored_requests.NotFoundError{ID: "acc-2", DataType: "Account"}}, errs)
+	assert.Nil(t, account)
+
+	handler.statusCode = 500
+	_, errs = fetcher.FetchAccount(context.Background(), "acc-1")
+	assert.Len(t, errs, 1)
+}
+
+func TestFetchCategories(t *testing.T) {
+	fetcher := NewFetcher(NewBucket(nil, "http://localhost/bucket", "us-east-1", "", ""), "")
+
+	_, err := fetcher.FetchCategories(context.Background(), "freewheel", "", "IAB1-1")
+
+	assert.EqualError(t, err, "Categories are not supported by the object storage fetcher")
+}
+
+func TestObjectKey(t *testing.T) {
+	assert.Equal(t, "stored_imps/imp-1.json", ObjectKey("", ImpsDirectory, "imp-1"))
+	assert.Equal(t, "some/prefix/stored_imps/imp-1.json", ObjectKey("/some/prefix/", ImpsDirectory, "imp-1"))
+	assert.Equal(t, "some/prefix/accounts/", DirectoryKeyPrefix("some/prefix", AccountsDirectory))
+}
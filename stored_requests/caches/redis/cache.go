@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/stored_requests"
+)
+
+// NewCache returns a Cache which keeps the data in Redis, at the keys made of the keyPrefix and the ids. The Redis
+// server can then be shared by many Prebid Server instances, which fetch each id from the backend once for all.
+//
+// Every key expires after the ttl since it was saved. Errors talking to Redis are logged, and the lookups which
+// failed are treated as misses.
+func NewCache(client *Client, keyPrefix string, ttl time.Duration, dataType string) stored_requests.CacheJSON {
+	glog.Infof("Using a Stored %s Redis cache. Key prefix: %s. TTL: %s.", dataType, keyPrefix, ttl)
+	return &cache{
+		client:    client,
+		keyPrefix: keyPrefix,
+		ttl:       ttl,
+		dataType:  dataType,
+	}
+}
+
+type cache struct {
+	client    *Client
+	keyPrefix string
+	ttl       time.Duration
+	dataType  string
+}
+
+func (c *cache) Get(ctx context.Context, ids []string) (data map[string]json.RawMessage) {
+	data = make(map[string]json.RawMessage, len(ids))
+	if len(ids) == 0 {
+		return
+	}
+
+	values, err := c.client.MGet(ctx, c.keys(ids))
+	if err != nil {
+		glog.Errorf("Failed to get the Stored %s data from Redis: %v", c.dataType, err)
+		return
+	}
+	for i, value := range values {
+		if value != nil {
+			data[ids[i]] = value
+		}
+	}
+	return
+}
+
+func (c *cache) Save(ctx context.Context, data map[string]json.RawMessage) {
+	values := make(map[string][]byte, len(data))
+	for id, value := range data {
+		if len(value) > 0 {
+			values[c.keyPrefix+id] = value
+		}
+	}
+	if err := c.client.SetWithTTL(ctx, values, c.ttl); err != nil {
+		glog.Errorf("Failed to save the Stored %s data to Redis: %v", c.dataType, err)
+	}
+}
+
+func (c *cache) Invalidate(ctx context.Context, ids []string) {
+	if err := c.client.Del(ctx, c.keys(ids)); err != nil {
+		glog.Errorf("Failed to invalidate the Stored %s data in Redis: %v", c.dataType, err)
+	}
+}
+
+func (c *cache) keys(ids []string) []string {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = c.keyPrefix + id
+	}
+	return keys
+}
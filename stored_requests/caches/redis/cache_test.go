@@ -0,0 +1,58 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheSaveAndGet(t *testing.T) {
+	server := newFakeServer(t, "")
+	cache := NewCache(NewClient(server.address, "", 0, time.Second, 1), "pbs:stored_requests:imp:", time.Minute, "Imps")
+	ctx := context.Background()
+
+	cache.Save(ctx, map[string]json.RawMessage{
+		"known": json.RawMessage(`{"id":"known"}`),
+		"empty": nil,
+	})
+
+	value, ok := server.value("pbs:stored_requests:imp:known")
+	assert.True(t, ok)
+	assert.Equal(t, `{"id":"known"}`, value)
+	assert.Equal(t, "60000", server.ttl("pbs:stored_requests:imp:known"))
+	_, ok = server.value("pbs:stored_requests:imp:empty")
+	assert.False(t, ok, "Empty data should not be saved")
+
+	data := cache.Get(ctx, []string{"known", "unknown"})
+	assert.Equal(t, map[string]json.RawMessage{"known": json.RawMessage(`{"id":"known"}`)}, data)
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	server := newFakeServer(t, "")
+	cache := NewCache(NewClient(server.address, "", 0, time.Second, 1), "prefix:", time.Minute, "Requests")
+	ctx := context.Background()
+
+	cache.Save(ctx, map[string]json.RawMessage{"a": json.RawMessage(`1`), "b": json.RawMessage(`2`)})
+	cache.Invalidate(ctx, []string{"a"})
+
+	assert.Equal(t, map[string]json.RawMessage{"b": json.RawMessage(`2`)}, cache.Get(ctx, []string{"a", "b"}))
+}
+
+func TestCacheUnavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	cache := NewCache(NewClient(address, "", 0, time.Second, 1), "prefix:", time.Minute, "Accounts")
+	ctx := context.Background()
+
+	cache.Save(ctx, map[string]json.RawMessage{"a": json.RawMessage(`1`)})
+	cache.Invalidate(ctx, []string{"a"})
+	assert.Empty(t, cache.Get(ctx, []string{"a"}), "Lookups should miss when Redis is unavailable")
+}
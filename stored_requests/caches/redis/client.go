@@ -0,0 +1,284 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// ErrorReply is an error the Redis server answered a command with
+type ErrorReply string
+
+func (e ErrorReply) Error() string {
+	return "redis: " + string(e)
+}
+
+// Client sends commands to a Redis server over the RESP protocol. It keeps a pool of at most poolSize idle
+// connections, so that it is safe for concurrent use by multiple goroutines.
+type Client struct {
+	address  string
+	password string
+	db       int
+	timeout  time.Duration
+	idle     chan *conn
+}
+
+type conn struct {
+	netConn net.Conn
+	reader  *bufio.Reader
+	writer  *bufio.Writer
+}
+
+// NewClient returns a Client of the Redis server at the address. The connections are authenticated with the password
+// if it is set, and select the db. The commands of a call are aborted if they take longer than the timeout.
+func NewClient(address string, password string, db int, timeout time.Duration, poolSize int) *Client {
+	if poolSize < 1 {
+		poolSize = 1
+	}
+	return &Client{
+		address:  address,
+		password: password,
+		db:       db,
+		timeout:  timeout,
+		idle:     make(chan *conn, poolSize),
+	}
+}
+
+// MGet returns the values of the keys, in the same order. The values of the keys which don't exist are nil.
+func (c *Client) MGet(ctx context.Context, keys []string) ([][]byte, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	replies, err := c.Do(ctx, append([]string{"MGET"}, keys...))
+	if err != nil {
+		return nil, err
+	}
+	values, ok := replies[0].([]interface{})
+	if !ok || len(values) != len(keys) {
+		return nil, fmt.Errorf("redis: unexpected reply to MGET: %v", replies[0])
+	}
+	data := make([][]byte, len(values))
+	for i, value := range values {
+		data[i], _ = value.([]byte)
+	}
+	return data, nil
+}
+
+// SetWithTTL sets the values of the keys, which expire after the ttl. The commands are pipelined in a single call.
+func (c *Client) SetWithTTL(ctx context.Context, values map[string][]byte, ttl time.Duration) error {
+	if len(values) == 0 {
+		return nil
+	}
+	ttlMillis := strconv.FormatInt(ttl.Milliseconds(), 10)
+	commands := make([][]string, 0, len(values))
+	for key, value := range values {
+		commands = append(commands, []string{"SET", key, string(value), "PX", ttlMillis})
+	}
+	replies, err := c.Do(ctx, commands...)
+	if err != nil {
+		return err
+	}
+	return firstErrorReply(replies)
+}
+
+// Del deletes the keys
+func (c *Client) Del(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	replies, err := c.Do(ctx, append([]string{"DEL"}, keys...))
+	if err != nil {
+		return err
+	}
+	return firstErrorReply(replies)
+}
+
+// Do pipelines the commands on a connection of the pool, and returns their replies in the same order. The replies
+// are strings for the status replies, ErrorReply for the error replies, int64 for the integers, []byte or nil for
+// the bulk strings and []interface{} for the arrays.
+//
+// The error is only set if the commands could not be sent or their replies could not be read.
+func (c *Client) Do(ctx context.Context, commands ...[]string) ([]interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	replies, err := cn.do(ctx, c.timeout, commands)
+	if err != nil {
+		cn.netConn.Close()
+		return nil, err
+	}
+	c.put(cn)
+	return replies, nil
+}
+
+// Close closes the idle connections of the pool
+func (c *Client) Close() {
+	for {
+		select {
+		case cn := <-c.idle:
+			cn.netConn.Close()
+		default:
+			return
+		}
+	}
+}
+
+// get returns an idle connection of the pool, or else dials a new one
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	select {
+	case cn := <-c.idle:
+		return cn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: c.timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return nil, fmt.Errorf("redis: failed to connect to %s: %v", c.address, err)
+	}
+	cn := &conn{
+		netConn: netConn,
+		reader:  bufio.NewReader(netConn),
+		writer:  bufio.NewWriter(netConn),
+	}
+
+	var commands [][]string
+	if c.password != "" {
+		commands = append(commands, []string{"AUTH", c.password})
+	}
+	if c.db != 0 {
+		commands = append(commands, []string{"SELECT", strconv.Itoa(c.db)})
+	}
+	if len(commands) > 0 {
+		replies, err := cn.do(ctx, c.timeout, commands)
+		if err == nil {
+			err = firstErrorReply(replies)
+		}
+		if err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+// put returns the connection to the pool, or closes it if the pool is full
+func (c *Client) put(cn *conn) {
+	select {
+	case c.idle <- cn:
+	default:
+		cn.netConn.Close()
+	}
+}
+
+func (cn *conn) do(ctx context.Context, timeout time.Duration, commands [][]string) ([]interface{}, error) {
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := cn.netConn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	for _, command := range commands {
+		writeCommand(cn.writer, command)
+	}
+	if err := cn.writer.Flush(); err != nil {
+		return nil, fmt.Errorf("redis: failed to send the commands: %v", err)
+	}
+
+	replies := make([]interface{}, len(commands))
+	for i := range commands {
+		reply, err := readReply(cn.reader)
+		if err != nil {
+			return nil, fmt.Errorf("redis: failed to read the reply: %v", err)
+		}
+		replies[i] = reply
+	}
+	return replies, nil
+}
+
+// writeCommand writes the command as an array of bulk strings
+func writeCommand(writer *bufio.Writer, command []string) {
+	writer.WriteString("*" + strconv.Itoa(len(command)) + "\r\n")
+	for _, arg := range command {
+		writer.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n")
+		writer.WriteString(arg)
+		writer.WriteString("\r\n")
+	}
+}
+
+func readReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return ErrorReply(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return nil, nil
+		}
+		data := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		return data[:length], nil
+	case '*':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return nil, nil
+		}
+		values := make([]interface{}, length)
+		for i := range values {
+			if values[i], err = readReply(reader); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unknown reply type %q", line[0])
+	}
+}
+
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("malformed reply line %q", line)
+	}
+	return line[:len(line)-2], nil
+}
+
+func firstErrorReply(replies []interface{}) error {
+	for _, reply := range replies {
+		if err, ok := reply.(ErrorReply); ok {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientSetGetDel(t *testing.T) {
+	server := newFakeServer(t, "")
+	client := NewClient(server.address, "", 0, time.Second, 2)
+	defer client.Close()
+	ctx := context.Background()
+
+	err := client.SetWithTTL(ctx, map[string][]byte{"a": []byte(`{"a":1}`), "b": []byte("b\r\nb")}, 90*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "90000", server.ttl("a"))
+	assert.Equal(t, "90000", server.ttl("b"))
+
+	values, err := client.MGet(ctx, []string{"a", "missing", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte(`{"a":1}`), nil, []byte("b\r\nb")}, values)
+
+	require.NoError(t, client.Del(ctx, []string{"a"}))
+	values, err = client.MGet(ctx, []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{nil, []byte("b\r\nb")}, values)
+}
+
+func TestClientReusesConnections(t *testing.T) {
+	server := newFakeServer(t, "")
+	client := NewClient(server.address, "", 0, time.Second, 1)
+	defer client.Close()
+
+	for i := 0; i < 3; i++ {
+		_, err := client.MGet(context.Background(), []string{"a"})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, server.connections())
+}
+
+func TestClientAuthenticatesAndSelects(t *testing.T) {
+	server := newFakeServer(t, "secret")
+
+	client := NewClient(server.address, "secret", 3, time.Second, 1)
+	defer client.Close()
+	_, err := client.MGet(context.Background(), []string{"a"})
+	assert.NoError(t, err)
+	assert.Equal(t, "3", server.selectedDB())
+
+	badClient := NewClient(server.address, "wrong", 0, time.Second, 1)
+	defer badClient.Close()
+	_, err = badClient.MGet(context.Background(), []string{"a"})
+	assert.EqualError(t, err, "redis: WRONGPASS invalid password")
+}
+
+func TestClientReturnsErrorReplies(t *testing.T) {
+	server := newFakeServer(t, "")
+	client := NewClient(server.address, "", 0, time.Second, 1)
+	defer client.Close()
+
+	replies, err := client.Do(context.Background(), []string{"PING"}, []string{"UNKNOWN"})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"PONG", ErrorReply("ERR unknown command 'UNKNOWN'")}, replies)
+}
+
+func TestClientConnectionError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	client := NewClient(address, "", 0, time.Second, 1)
+	_, err = client.MGet(context.Background(), []string{"a"})
+	assert.Error(t, err)
+}
+
+// fakeServer is a Redis server which implements the few commands the Client uses
+type fakeServer struct {
+	address  string
+	password string
+
+	mutex    sync.Mutex
+	values   map[string]string
+	ttls     map[string]string
+	db       string
+	accepted int
+}
+
+func newFakeServer(t *testing.T, password string) *fakeServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeServer{
+		address:  listener.Addr().String(),
+		password: password,
+		values:   make(map[string]string),
+		ttls:     make(map[string]string),
+	}
+	go func() {
+		for {
+			netConn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.mutex.Lock()
+			server.accepted++
+			server.mutex.Unlock()
+			go server.serve(netConn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeServer) serve(netConn net.Conn) {
+	defer netConn.Close()
+	reader := bufio.NewReader(netConn)
+	writer := bufio.NewWriter(netConn)
+	authenticated := s.password == ""
+	for {
+		command, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		name := strings.ToUpper(command[0])
+		if name == "AUTH" {
+			if command[1] == s.password {
+				authenticated = true
+				writer.WriteString("+OK\r\n")
+			} else {
+				writer.WriteString("-WRONGPASS invalid password\r\n")
+			}
+		} else if !authenticated {
+			writer.WriteString("-NOAUTH Authentication required.\r\n")
+		} else {
+			s.execute(writer, name, command[1:])
+		}
+		if reader.Buffered() == 0 {
+			writer.Flush()
+		}
+	}
+}
+
+func (s *fakeServer) execute(writer *bufio.Writer, name string, args []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	switch name {
+	case "PING":
+		writer.WriteString("+PONG\r\n")
+	case "SELECT":
+		s.db = args[0]
+		writer.WriteString("+OK\r\n")
+	case "MGET":
+		writer.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+		for _, key := range args {
+			if value, ok := s.values[key]; ok {
+				writer.WriteString("$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n")
+			} else {
+				writer.WriteString("$-1\r\n")
+			}
+		}
+	case "SET":
+		s.values[args[0]] = args[1]
+		if len(args) == 4 && strings.ToUpper(args[2]) == "PX" {
+			s.ttls[args[0]] = args[3]
+		}
+		writer.WriteString("+OK\r\n")
+	case "DEL":
+		deleted := 0
+		for _, key := range args {
+			if _, ok := s.values[key]; ok {
+				delete(s.values, key)
+				delete(s.ttls, key)
+				deleted++
+			}
+		}
+		writer.WriteString(":" + strconv.Itoa(deleted) + "\r\n")
+	default:
+		writer.WriteString("-ERR unknown command '" + name + "'\r\n")
+	}
+}
+
+func (s *fakeServer) value(key string) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+func (s *fakeServer) ttl(key string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.ttls[key]
+}
+
+func (s *fakeServer) selectedDB() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.db
+}
+
+func (s *fakeServer) connections() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.accepted
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	reply, err := readReply(reader)
+	if err != nil {
+		return nil, err
+	}
+	args := reply.([]interface{})
+	command := make([]string, len(args))
+	for i, arg := range args {
+		command[i] = string(arg.([]byte))
+	}
+	return command, nil
+}
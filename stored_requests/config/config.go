@@ -10,17 +10,21 @@ import (
 
 	"github.com/golang/glog"
 	"github.com/julienschmidt/httprouter"
+	"github.com/lib/pq"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/backends/db_fetcher"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/stored_requests/backends/file_fetcher"
 	"github.com/prebid/prebid-server/stored_requests/backends/http_fetcher"
+	"github.com/prebid/prebid-server/stored_requests/backends/objectstore_fetcher"
 	"github.com/prebid/prebid-server/stored_requests/caches/memory"
 	"github.com/prebid/prebid-server/stored_requests/caches/nil_cache"
+	"github.com/prebid/prebid-server/stored_requests/caches/redis"
 	"github.com/prebid/prebid-server/stored_requests/events"
 	apiEvents "github.com/prebid/prebid-server/stored_requests/events/api"
 	httpEvents "github.com/prebid/prebid-server/stored_requests/events/http"
+	objectStoreEvents "github.com/prebid/prebid-server/stored_requests/events/objectstore"
 	postgresEvents "github.com/prebid/prebid-server/stored_requests/events/postgres"
 	"github.com/prebid/prebid-server/util/task"
 )
@@ -69,10 +73,27 @@ func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.Metr
 	fetcher = newFetcher(cfg, client, dbc.db)
 
 	var shutdown1 func()
+	var redisClient *redis.Client
+
+	var sharedCache *stored_requests.Cache
+	if cfg.Redis.Address != "" {
+		redisClient = newRedisClient(cfg)
+		cache := newRedisCache(cfg, redisClient)
+		fetcher = stored_requests.WithSharedCache(fetcher, cache, metricsEngine)
+		sharedCache = &cache
+	}
 
 	if cfg.InMemoryCache.Type != "" {
 		cache := newCache(cfg)
 		fetcher = stored_requests.WithCache(fetcher, cache, metricsEngine)
+		// The events update both the in-memory cache and the one shared with the other instances
+		if sharedCache != nil {
+			cache = stored_requests.Cache{
+				Requests: stored_requests.ComposedCache{cache.Requests, sharedCache.Requests},
+				Imps:     stored_requests.ComposedCache{cache.Imps, sharedCache.Imps},
+				Accounts: stored_requests.ComposedCache{cache.Accounts, sharedCache.Accounts},
+			}
+		}
 		shutdown1 = addListeners(cache, eventProducers)
 	}
 
@@ -80,6 +101,9 @@ func CreateStoredRequests(cfg *config.StoredRequests, metricsEngine metrics.Metr
 		if shutdown1 != nil {
 			shutdown1()
 		}
+		if redisClient != nil {
+			redisClient.Close()
+		}
 		if dbc.db != nil {
 			db := dbc.db
 			dbc.db = nil
@@ -165,7 +189,7 @@ func newFetcher(cfg *config.StoredRequests, client *http.Client, db *sql.DB) (fe
 	}
 	if cfg.Postgres.FetcherQueries.QueryTemplate != "" {
 		glog.Infof("Loading Stored %s data via Postgres.\nQuery: %s", cfg.DataType(), cfg.Postgres.FetcherQueries.QueryTemplate)
-		idList = append(idList, db_fetcher.NewFetcher(db, cfg.Postgres.FetcherQueries.MakeQuery))
+		idList = append(idList, db_fetcher.NewFetcher(db, cfg.Postgres.FetcherQueries.MakeQuery, cfg.Postgres.FetcherQueries.MakeResponseQuery))
 	} else if cfg.Postgres.CacheInitialization.Query != "" && cfg.Postgres.PollUpdates.Query != "" {
 		//in this case data will be loaded to cache via poll for updates event
 		idList = append(idList, empty_fetcher.EmptyFetcher{})
@@ -174,11 +198,38 @@ func newFetcher(cfg *config.StoredRequests, client *http.Client, db *sql.DB) (fe
 		glog.Infof("Loading Stored %s data via HTTP. endpoint=%s", cfg.DataType(), cfg.HTTP.Endpoint)
 		idList = append(idList, http_fetcher.NewFetcher(client, cfg.HTTP.Endpoint))
 	}
+	if cfg.ObjectStorage.Bucket != "" {
+		glog.Infof("Loading Stored %s data via object storage. bucket=%s, prefix=%s", cfg.DataType(), cfg.ObjectStorage.Bucket, cfg.ObjectStorage.Prefix)
+		idList = append(idList, objectstore_fetcher.NewFetcher(newBucket(client, cfg.ObjectStorage), cfg.ObjectStorage.Prefix))
+	}
 
 	fetcher = consolidate(cfg.DataType(), idList)
 	return
 }
 
+func newRedisClient(cfg *config.StoredRequests) *redis.Client {
+	glog.Infof("Connecting to Redis for Stored %s. address=%s, db=%d", cfg.DataType(), cfg.Redis.Address, cfg.Redis.DB)
+	return redis.NewClient(cfg.Redis.Address, cfg.Redis.Password, cfg.Redis.DB, cfg.Redis.TimeoutDuration(), cfg.Redis.PoolSize)
+}
+
+// newRedisCache returns the caches of the data type in Redis. Their keys are namespaced by the config section, since
+// the sections may share the Redis server.
+func newRedisCache(cfg *config.StoredRequests, client *redis.Client) stored_requests.Cache {
+	keyPrefix := cfg.Redis.KeyPrefix + cfg.Section() + ":"
+	cache := stored_requests.Cache{
+		Requests: &nil_cache.NilCache{},
+		Imps:     &nil_cache.NilCache{},
+		Accounts: &nil_cache.NilCache{},
+	}
+	if cfg.DataType() == config.AccountDataType {
+		cache.Accounts = redis.NewCache(client, keyPrefix+"account:", cfg.Redis.TTLDuration(), "Accounts")
+	} else {
+		cache.Requests = redis.NewCache(client, keyPrefix+"request:", cfg.Redis.TTLDuration(), "Requests")
+		cache.Imps = redis.NewCache(client, keyPrefix+"imp:", cfg.Redis.TTLDuration(), "Imps")
+	}
+	return cache
+}
+
 func newCache(cfg *config.StoredRequests) stored_requests.Cache {
 	cache := stored_requests.Cache{&nil_cache.NilCache{}, &nil_cache.NilCache{}, &nil_cache.NilCache{}}
 	switch {
@@ -200,6 +251,9 @@ func newEventProducers(cfg *config.StoredRequests, client *http.Client, db *sql.
 	if cfg.HTTPEvents.RefreshRate != 0 && cfg.HTTPEvents.Endpoint != "" {
 		eventProducers = append(eventProducers, newHttpEvents(client, cfg.HTTPEvents.TimeoutDuration(), cfg.HTTPEvents.RefreshRateDuration(), cfg.HTTPEvents.Endpoint))
 	}
+	if cfg.ObjectStorage.RefreshRate != 0 && cfg.ObjectStorage.Bucket != "" {
+		eventProducers = append(eventProducers, newObjectStoreEvents(client, cfg.ObjectStorage))
+	}
 	if cfg.Postgres.CacheInitialization.Query != "" {
 		pgEventCfg := postgresEvents.PostgresEventProducerConfig{
 			DB:                 db,
@@ -214,6 +268,10 @@ func newEventProducers(cfg *config.StoredRequests, client *http.Client, db *sql.
 		fetchInterval := time.Duration(cfg.Postgres.PollUpdates.RefreshRate) * time.Second
 		pgEventTickerTask := task.NewTickerTask(fetchInterval, pgEventProducer)
 		pgEventTickerTask.Start()
+		if cfg.Postgres.ListenUpdates.Channel != "" {
+			listener := newPostgresListener(cfg.DataType(), cfg.Postgres.ConnectionInfo, cfg.Postgres.ListenUpdates)
+			postgresEvents.NewNotificationTask(listener.NotificationChannel(), pgEventProducer).Start()
+		}
 		eventProducers = append(eventProducers, pgEventProducer)
 	}
 	return
@@ -233,6 +291,17 @@ func newHttpEvents(client *http.Client, timeout time.Duration, refreshRate time.
 	return httpEvents.NewHTTPEvents(client, endpoint, ctxProducer, refreshRate)
 }
 
+func newObjectStoreEvents(client *http.Client, cfg config.ObjectStorageConfig) events.EventProducer {
+	ctxProducer := func() (ctx context.Context, canceller func()) {
+		return context.WithTimeout(context.Background(), cfg.TimeoutDuration())
+	}
+	return objectStoreEvents.NewObjectStoreEvents(newBucket(client, cfg), cfg.Prefix, ctxProducer, cfg.RefreshRateDuration())
+}
+
+func newBucket(client *http.Client, cfg config.ObjectStorageConfig) *objectstore_fetcher.Bucket {
+	return objectstore_fetcher.NewBucket(client, cfg.BucketEndpoint(), cfg.SigningRegion(), cfg.AccessKeyID, cfg.SecretAccessKey)
+}
+
 func newFilesystem(dataType config.DataType, configPath string) stored_requests.AllFetcher {
 	glog.Infof("Loading Stored %s data from filesystem at path %s", dataType, configPath)
 	fetcher, err := file_fetcher.NewFileFetcher(configPath)
@@ -255,6 +324,23 @@ func newPostgresDB(dataType config.DataType, cfg config.PostgresConnection) *sql
 	return db
 }
 
+func newPostgresListener(dataType config.DataType, cfg config.PostgresConnection, listenCfg config.PostgresUpdateListening) *pq.Listener {
+	minReconnectInterval := time.Duration(listenCfg.MinReconnectInterval) * time.Millisecond
+	maxReconnectInterval := time.Duration(listenCfg.MaxReconnectInterval) * time.Millisecond
+	listener := pq.NewListener(cfg.ConnString(), minReconnectInterval, maxReconnectInterval, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			glog.Warningf("The %s postgres listener lost its connection: %v", dataType, err)
+		}
+	})
+
+	if err := listener.Listen(listenCfg.Channel); err != nil {
+		glog.Fatalf("Failed to listen to the %s postgres channel %s: %v", dataType, listenCfg.Channel, err)
+	}
+	glog.Infof("Listening to the postgres channel %s for the updates of Stored %s", listenCfg.Channel, dataType)
+
+	return listener
+}
+
 // consolidate returns a single Fetcher from an array of fetchers of any size.
 func consolidate(dataType config.DataType, fetchers []stored_requests.AllFetcher) stored_requests.AllFetcher {
 	if len(fetchers) == 0 {
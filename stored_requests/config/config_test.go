@@ -19,8 +19,12 @@ import (
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/backends/empty_fetcher"
 	"github.com/prebid/prebid-server/stored_requests/backends/http_fetcher"
+	"github.com/prebid/prebid-server/stored_requests/backends/objectstore_fetcher"
+	"github.com/prebid/prebid-server/stored_requests/caches/nil_cache"
+	"github.com/prebid/prebid-server/stored_requests/caches/redis"
 	"github.com/prebid/prebid-server/stored_requests/events"
 	httpEvents "github.com/prebid/prebid-server/stored_requests/events/http"
+	objectStoreEvents "github.com/prebid/prebid-server/stored_requests/events/objectstore"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -154,6 +158,40 @@ func TestNewHTTPEvents(t *testing.T) {
 	assertHttpWithURL(t, evProducers[0], server1.URL)
 }
 
+func TestNewObjectStoreFetcher(t *testing.T) {
+	fetcher := newFetcher(&config.StoredRequests{
+		ObjectStorage: config.ObjectStorageConfig{
+			Provider: "gcs",
+			Bucket:   "bucket",
+		},
+	}, nil, nil)
+	_, ok := fetcher.(*objectstore_fetcher.ObjectStoreFetcher)
+	assert.True(t, ok, "An object storage config should return an ObjectStoreFetcher. Got %v", fetcher)
+}
+
+func TestNewObjectStoreEvents(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	server1 := httptest.NewServer(http.HandlerFunc(handler))
+	defer server1.Close()
+
+	cfg := &config.StoredRequests{
+		ObjectStorage: config.ObjectStorageConfig{
+			Provider:    "s3",
+			Endpoint:    server1.URL,
+			Bucket:      "bucket",
+			RefreshRate: -1,
+			Timeout:     1000,
+		},
+	}
+
+	evProducers := newEventProducers(cfg, server1.Client(), nil, &metrics.MetricsEngineMock{}, nil)
+	assertSliceLength(t, evProducers, 1)
+	_, ok := evProducers[0].(*objectStoreEvents.ObjectStoreEvents)
+	assert.True(t, ok, "An object storage config with a refresh rate should return an ObjectStoreEvents. Got %v", evProducers[0])
+}
+
 func TestNewEmptyCache(t *testing.T) {
 	cache := newCache(&config.StoredRequests{InMemoryCache: config.InMemoryCache{Type: "none"}})
 	assert.True(t, isEmptyCacheType(cache.Requests), "The newCache method should return an empty Request cache")
@@ -186,6 +224,24 @@ func TestNewInMemoryAccountCache(t *testing.T) {
 	assert.True(t, isEmptyCacheType(cache.Imps), "The newCache method should return an empty Imp cache for Accounts config")
 }
 
+func TestNewRedisAccountCache(t *testing.T) {
+	cfg := typedConfig(config.AccountDataType, &config.StoredRequests{
+		Redis: config.RedisCacheConfig{
+			Address:   "localhost:6379",
+			KeyPrefix: "pbs:",
+			TTL:       60,
+			Timeout:   50,
+			PoolSize:  1,
+		},
+	})
+	client := newRedisClient(cfg)
+	defer client.Close()
+	cache := newRedisCache(cfg, client)
+	assert.IsType(t, &nil_cache.NilCache{}, cache.Requests, "The newRedisCache method should return an empty Request cache for Accounts config")
+	assert.IsType(t, &nil_cache.NilCache{}, cache.Imps, "The newRedisCache method should return an empty Imp cache for Accounts config")
+	assert.IsType(t, redis.NewCache(client, "", 0, "Accounts"), cache.Accounts, "The newRedisCache method should return a Redis Account cache for Accounts config")
+}
+
 func TestNewPostgresEventProducers(t *testing.T) {
 	metricsMock := &metrics.MetricsEngineMock{}
 	metricsMock.Mock.On("RecordStoredDataFetchTime", mock.Anything, mock.Anything).Return()
@@ -0,0 +1,160 @@
+package objectstore
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/prebid/prebid-server/stored_requests/backends/objectstore_fetcher"
+	"github.com/prebid/prebid-server/stored_requests/events"
+
+	"github.com/golang/glog"
+)
+
+// NewObjectStoreEvents makes an EventProducer which creates events by listing the objects of the bucket under the
+// prefix periodically. If refreshRate is negative, then the data will never be refreshed.
+//
+// The objects are expected to be laid out like for the objectstore_fetcher. All the Stored Requests, Stored Imps and
+// Accounts are saved when the EventProducer is made. From then on, the objects whose entity tag has changed in the
+// listing are saved again, and the objects which are no longer listed are invalidated.
+func NewObjectStoreEvents(bucket *objectstore_fetcher.Bucket, prefix string, ctxProducer func() (ctx context.Context, canceller func()), refreshRate time.Duration) *ObjectStoreEvents {
+	// If we're not given a function to produce Contexts, use the Background one.
+	if ctxProducer == nil {
+		ctxProducer = func() (ctx context.Context, canceller func()) {
+			return context.Background(), func() {}
+		}
+	}
+	e := &ObjectStoreEvents{
+		bucket:        bucket,
+		ctxProducer:   ctxProducer,
+		prefix:        prefix,
+		etags:         make(map[string]string),
+		saves:         make(chan events.Save, 1),
+		invalidations: make(chan events.Invalidation, 1),
+	}
+	glog.Infof("Loading object storage cache from prefix %s", prefix)
+	e.update()
+
+	if refreshRate > 0 {
+		go e.refresh(time.Tick(refreshRate))
+	}
+	return e
+}
+
+type ObjectStoreEvents struct {
+	bucket        *objectstore_fetcher.Bucket
+	ctxProducer   func() (ctx context.Context, canceller func())
+	prefix        string
+	etags         map[string]string
+	invalidations chan events.Invalidation
+	saves         chan events.Save
+}
+
+func (e *ObjectStoreEvents) refresh(ticker <-chan time.Time) {
+	for range ticker {
+		glog.Infof("Refreshing object storage cache from prefix %s", e.prefix)
+		e.update()
+	}
+}
+
+// update lists the objects under the prefix, and sends the events for the objects which have changed since the last
+// listing. The objects which fail to download are tried again with the next listing.
+func (e *ObjectStoreEvents) update() {
+	ctx, cancel := e.ctxProducer()
+	defer cancel()
+
+	listPrefix := ""
+	if prefix := strings.Trim(e.prefix, "/"); prefix != "" {
+		listPrefix = prefix + "/"
+	}
+	listedETags, err := e.bucket.List(ctx, listPrefix)
+	if err != nil {
+		glog.Errorf("Failed to list the objects for Stored Requests: %v", err)
+		return
+	}
+
+	var changedKeys []string
+	for key, etag := range listedETags {
+		if _, _, ok := e.parseKey(key); ok && e.etags[key] != etag {
+			changedKeys = append(changedKeys, key)
+		}
+	}
+
+	save := events.Save{
+		Requests: make(map[string]json.RawMessage),
+		Imps:     make(map[string]json.RawMessage),
+		Accounts: make(map[string]json.RawMessage),
+	}
+	for _, result := range e.bucket.GetAll(ctx, changedKeys, nil) {
+		if result.Err != nil {
+			glog.Errorf("Failed to get the object %s for Stored Requests: %v", result.Key, result.Err)
+			continue
+		}
+		directory, id, _ := e.parseKey(result.Key)
+		savedData(&save, directory)[id] = result.Object.Data
+		e.etags[result.Key] = listedETags[result.Key]
+	}
+
+	invalidation := events.Invalidation{}
+	for key := range e.etags {
+		if _, ok := listedETags[key]; !ok {
+			directory, id, _ := e.parseKey(key)
+			ids := invalidatedIDs(&invalidation, directory)
+			*ids = append(*ids, id)
+			delete(e.etags, key)
+		}
+	}
+
+	if len(save.Requests) > 0 || len(save.Imps) > 0 || len(save.Accounts) > 0 {
+		e.saves <- save
+	}
+	if len(invalidation.Requests) > 0 || len(invalidation.Imps) > 0 || len(invalidation.Accounts) > 0 {
+		e.invalidations <- invalidation
+	}
+}
+
+// parseKey returns the directory and the id of the object with the key, and whether it holds data which is cached
+func (e *ObjectStoreEvents) parseKey(key string) (directory string, id string, ok bool) {
+	for _, directory := range []string{objectstore_fetcher.RequestsDirectory, objectstore_fetcher.ImpsDirectory, objectstore_fetcher.AccountsDirectory} {
+		directoryPrefix := objectstore_fetcher.DirectoryKeyPrefix(e.prefix, directory)
+		if !strings.HasPrefix(key, directoryPrefix) || !strings.HasSuffix(key, ".json") {
+			continue
+		}
+		id := strings.TrimSuffix(strings.TrimPrefix(key, directoryPrefix), ".json")
+		if id != "" && !strings.Contains(id, "/") {
+			return directory, id, true
+		}
+	}
+	return "", "", false
+}
+
+func savedData(save *events.Save, directory string) map[string]json.RawMessage {
+	switch directory {
+	case objectstore_fetcher.RequestsDirectory:
+		return save.Requests
+	case objectstore_fetcher.ImpsDirectory:
+		return save.Imps
+	default:
+		return save.Accounts
+	}
+}
+
+func invalidatedIDs(invalidation *events.Invalidation, directory string) *[]string {
+	switch directory {
+	case objectstore_fetcher.RequestsDirectory:
+		return &invalidation.Requests
+	case objectstore_fetcher.ImpsDirectory:
+		return &invalidation.Imps
+	default:
+		return &invalidation.Accounts
+	}
+}
+
+func (e *ObjectStoreEvents) Saves() <-chan events.Save {
+	return e.saves
+}
+
+func (e *ObjectStoreEvents) Invalidations() <-chan events.Invalidation {
+	return e.invalidations
+}
@@ -0,0 +1,133 @@
+package objectstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/stored_requests/backends/objectstore_fetcher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartupAndRefresh(t *testing.T) {
+	handler := &mockBucketHandler{objects: map[string]string{
+		"prefix/stored_requests/req-1.json":  `{"value":1}`,
+		"prefix/stored_imps/imp-1.json":      `{"value":2}`,
+		"prefix/accounts/acc-1.json":         `{"value":3}`,
+		"prefix/stored_responses/resp.json":  `[]`,
+		"prefix/stored_imps/nested/imp.json": `{}`,
+		"other/stored_imps/imp-2.json":       `{}`,
+	}}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	bucket := objectstore_fetcher.NewBucket(server.Client(), server.URL+"/bucket", "us-east-1", "", "")
+
+	ev := NewObjectStoreEvents(bucket, "prefix", nil, -1)
+
+	assertSave(t, ev, `{"requests": {"req-1": {"value":1}}, "imps": {"imp-1": {"value":2}}, "accounts": {"acc-1": {"value":3}}}`)
+	assert.Empty(t, ev.Invalidations(), "Unexpected invalidation at startup")
+
+	// req-1 is changed, imp-1 deleted, and acc-1 left alone
+	handler.setObject("prefix/stored_requests/req-1.json", `{"value":4}`, "v2")
+	handler.setObject("prefix/stored_imps/imp-1.json", "", "")
+	refresh(ev)
+
+	assertSave(t, ev, `{"requests": {"req-1": {"value":4}}, "imps": {}, "accounts": {}}`)
+	invalidations, err := json.Marshal(<-ev.Invalidations())
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"requests": null, "imps": ["imp-1"], "accounts": null}`, string(invalidations))
+}
+
+func TestStartupError(t *testing.T) {
+	handler := &mockBucketHandler{statusCode: http.StatusForbidden}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	bucket := objectstore_fetcher.NewBucket(server.Client(), server.URL+"/bucket", "us-east-1", "", "")
+
+	ev := NewObjectStoreEvents(bucket, "", nil, -1)
+
+	assert.Empty(t, ev.Saves(), "Unexpected save after a failed listing")
+	assert.Empty(t, ev.Invalidations(), "Unexpected invalidation after a failed listing")
+}
+
+func refresh(ev *ObjectStoreEvents) {
+	timeChan := make(chan time.Time, 1)
+	timeChan <- time.Now()
+	close(timeChan)
+	ev.refresh(timeChan)
+}
+
+func assertSave(t *testing.T, ev *ObjectStoreEvents, expected string) {
+	t.Helper()
+	select {
+	case save := <-ev.Saves():
+		saveJSON, err := json.Marshal(save)
+		assert.NoError(t, err)
+		assert.JSONEq(t, expected, string(saveJSON))
+	default:
+		t.Error("Expected a save")
+	}
+}
+
+// mockBucketHandler serves the objects of a bucket named "bucket" through the S3 compatible REST API, listing all the
+// objects in a single page
+type mockBucketHandler struct {
+	mutex      sync.Mutex
+	objects    map[string]string
+	versions   map[string]string
+	statusCode int
+}
+
+func (m *mockBucketHandler) setObject(key string, data string, version string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if data == "" {
+		delete(m.objects, key)
+		return
+	}
+	m.objects[key] = data
+	if m.versions == nil {
+		m.versions = make(map[string]string)
+	}
+	m.versions[key] = version
+}
+
+func (m *mockBucketHandler) etag(key string) string {
+	return `"` + key + "-" + m.versions[key] + `"`
+}
+
+func (m *mockBucketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.statusCode != 0 {
+		w.WriteHeader(m.statusCode)
+		return
+	}
+
+	if r.URL.Path == "/bucket" {
+		var body strings.Builder
+		body.WriteString("<ListBucketResult>")
+		for key := range m.objects {
+			if strings.HasPrefix(key, r.URL.Query().Get("prefix")) {
+				fmt.Fprintf(&body, "<Contents><Key>%s</Key><ETag>%s</ETag></Contents>", key, m.etag(key))
+			}
+		}
+		body.WriteString("</ListBucketResult>")
+		w.Write([]byte(body.String()))
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+	data, ok := m.objects[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("ETag", m.etag(key))
+	w.Write([]byte(data))
+}
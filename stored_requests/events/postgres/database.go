@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/golang/glog"
@@ -38,6 +39,8 @@ type PostgresEventProducerConfig struct {
 }
 
 type PostgresEventProducer struct {
+	// mutex serializes the runs of the polling and of the notifications of the database
+	mutex         sync.Mutex
 	cfg           PostgresEventProducerConfig
 	lastUpdate    time.Time
 	invalidations chan events.Invalidation
@@ -60,6 +63,9 @@ func NewPostgresEventProducer(cfg PostgresEventProducerConfig) (eventProducer *P
 }
 
 func (e *PostgresEventProducer) Run() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
 	if e.lastUpdate.IsZero() {
 		return e.fetchAll()
 	}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"github.com/lib/pq"
+	"github.com/prebid/prebid-server/util/task"
+)
+
+// NotificationTask runs the task runner whenever the database notifies the channel of a Postgres listener. The pq
+// listener also sends a nil notification once it reconnects, so the runner catches up on the notifications lost
+// while the connection was down.
+type NotificationTask struct {
+	notifications <-chan *pq.Notification
+	runner        task.Runner
+	done          chan struct{}
+}
+
+func NewNotificationTask(notifications <-chan *pq.Notification, runner task.Runner) *NotificationTask {
+	return &NotificationTask{
+		notifications: notifications,
+		runner:        runner,
+		done:          make(chan struct{}),
+	}
+}
+
+// Start runs the task in the background on each notification
+func (t *NotificationTask) Start() {
+	go t.run()
+}
+
+// Stop stops running the task on the notifications
+func (t *NotificationTask) Stop() {
+	close(t.done)
+}
+
+func (t *NotificationTask) run() {
+	for {
+		select {
+		case _, ok := <-t.notifications:
+			if !ok {
+				return
+			}
+			// A burst of changes notifies as many times, which a single run covers
+			t.drain()
+			t.runner.Run()
+		case <-t.done:
+			return
+		}
+	}
+}
+
+func (t *NotificationTask) drain() {
+	for {
+		select {
+		case _, ok := <-t.notifications:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRunner struct {
+	runs chan struct{}
+}
+
+func (r *fakeRunner) Run() error {
+	r.runs <- struct{}{}
+	return nil
+}
+
+func TestNotificationTaskRunsOnNotification(t *testing.T) {
+	notifications := make(chan *pq.Notification, 3)
+	runner := &fakeRunner{runs: make(chan struct{}, 3)}
+	notificationTask := NewNotificationTask(notifications, runner)
+
+	// A burst of notifications is covered by a single run
+	notifications <- &pq.Notification{Channel: "stored_requests"}
+	notifications <- &pq.Notification{Channel: "stored_requests"}
+	notifications <- nil
+	notificationTask.Start()
+	defer notificationTask.Stop()
+
+	assertRuns(t, runner, 1)
+
+	notifications <- &pq.Notification{Channel: "stored_requests"}
+	assertRuns(t, runner, 1)
+}
+
+func TestNotificationTaskStop(t *testing.T) {
+	notifications := make(chan *pq.Notification, 1)
+	runner := &fakeRunner{runs: make(chan struct{}, 1)}
+	notificationTask := NewNotificationTask(notifications, runner)
+
+	notificationTask.Start()
+	notificationTask.Stop()
+	time.Sleep(10 * time.Millisecond)
+	notifications <- &pq.Notification{Channel: "stored_requests"}
+
+	assertRuns(t, runner, 0)
+}
+
+func assertRuns(t *testing.T, runner *fakeRunner, expectedRuns int) {
+	t.Helper()
+
+	runs := 0
+	timeout := time.After(50 * time.Millisecond)
+	for {
+		select {
+		case <-runner.runs:
+			runs++
+		case <-timeout:
+			assert.Equal(t, expectedRuns, runs, "runs")
+			return
+		}
+	}
+}
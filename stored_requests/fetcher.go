@@ -23,6 +23,13 @@ type Fetcher interface {
 	//
 	// The returned objects can only be read from. They may not be written to.
 	FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (requestData map[string]json.RawMessage, impData map[string]json.RawMessage, errs []error)
+
+	// FetchResponses fetches the stored responses for the given IDs. These are the seatbids which
+	// imp.ext.prebid.storedauctionresponse and imp.ext.prebid.storedbidresponse replace the live bids with.
+	//
+	// The returned map will have a key for every ID in the ids list, unless errors exist.
+	// It can only be read from. It may not be written to.
+	FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error)
 }
 
 type AccountFetcher interface {
@@ -191,6 +198,11 @@ func (f *fetcherWithCache) FetchRequests(ctx context.Context, requestIDs []strin
 	return
 }
 
+// FetchResponses is not cached, since the stored responses are only used for testing and the odd line item
+func (f *fetcherWithCache) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	return f.fetcher.FetchResponses(ctx, ids)
+}
+
 func (f *fetcherWithCache) FetchAccount(ctx context.Context, accountID string) (account json.RawMessage, errs []error) {
 	accountData := f.cache.Accounts.Get(ctx, []string{accountID})
 	// TODO: add metrics
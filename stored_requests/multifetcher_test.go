@@ -126,6 +126,35 @@ func TestOtherError(t *testing.T) {
 	assert.JSONEq(t, `{"imp_id": "imp-1"}`, string(impData["imp-1"]), "MultiFetcher should return the right imp data")
 }
 
+func TestMultiFetcherResponses(t *testing.T) {
+	f1 := &mockFetcher{}
+	f2 := &mockFetcher{}
+	fetcher := &MultiFetcher{f1, f2}
+	ctx := context.Background()
+
+	f1.On("FetchResponses", ctx, []string{"resp-1", "resp-2", "resp-3"}).Return(
+		map[string]json.RawMessage{
+			"resp-1": json.RawMessage(`[{"seat": "appnexus"}]`),
+		},
+		[]error{NotFoundError{"resp-2", "Response"}, NotFoundError{"resp-3", "Response"}},
+	)
+	f2.On("FetchResponses", ctx, []string{"resp-2", "resp-3"}).Return(
+		map[string]json.RawMessage{
+			"resp-2": json.RawMessage(`[{"seat": "rubicon"}]`),
+		},
+		[]error{NotFoundError{"resp-3", "Response"}},
+	)
+
+	respData, errs := fetcher.FetchResponses(ctx, []string{"resp-1", "resp-2", "resp-3"})
+
+	f1.AssertExpectations(t)
+	f2.AssertExpectations(t)
+	assert.Len(t, respData, 2, "MultiFetcher should return all the requested stored responses that exist")
+	assert.Equal(t, []error{NotFoundError{"resp-3", "Response"}}, errs, "MultiFetcher should return a single NotFound error for the missing response")
+	assert.JSONEq(t, `[{"seat": "appnexus"}]`, string(respData["resp-1"]), "MultiFetcher should return the right response data")
+	assert.JSONEq(t, `[{"seat": "rubicon"}]`, string(respData["resp-2"]), "MultiFetcher should return the right response data")
+}
+
 func TestMultiFetcherAccountFoundInFirstFetcher(t *testing.T) {
 	f1 := &mockFetcher{}
 	f2 := &mockFetcher{}
@@ -0,0 +1,206 @@
+package stored_requests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/prebid/prebid-server/metrics"
+)
+
+type fetcherWithSharedCache struct {
+	fetcher       AllFetcher
+	cache         Cache
+	metricsEngine metrics.MetricsEngine
+	calls         fetchCalls
+}
+
+// WithSharedCache returns a Fetcher which uses a Cache shared by the Prebid Server instances, like Redis, before
+// delegating to the original. It is meant to sit between the backing Fetcher and the in-memory Cache of WithCache.
+//
+// The ids missing from the shared Cache are fetched by a single backend call at a time: the lookups of an id which
+// is already being fetched wait for that fetch to complete, and share its data.
+func WithSharedCache(fetcher AllFetcher, cache Cache, metricsEngine metrics.MetricsEngine) AllFetcher {
+	return &fetcherWithSharedCache{
+		fetcher:       fetcher,
+		cache:         cache,
+		metricsEngine: metricsEngine,
+	}
+}
+
+func (f *fetcherWithSharedCache) FetchRequests(ctx context.Context, requestIDs []string, impIDs []string) (requestData map[string]json.RawMessage, impData map[string]json.RawMessage, errs []error) {
+	requestData = f.cache.Requests.Get(ctx, requestIDs)
+	impData = f.cache.Imps.Get(ctx, impIDs)
+
+	leftoverReqs := findLeftovers(requestIDs, requestData)
+	leftoverImps := findLeftovers(impIDs, impData)
+
+	f.metricsEngine.RecordSharedCacheResult(metrics.SharedCacheRequest, metrics.CacheHit, len(requestIDs)-len(leftoverReqs))
+	f.metricsEngine.RecordSharedCacheResult(metrics.SharedCacheImp, metrics.CacheHit, len(impIDs)-len(leftoverImps))
+	f.metricsEngine.RecordSharedCacheResult(metrics.SharedCacheRequest, metrics.CacheMiss, len(leftoverReqs))
+	f.metricsEngine.RecordSharedCacheResult(metrics.SharedCacheImp, metrics.CacheMiss, len(leftoverImps))
+
+	if len(leftoverReqs) == 0 && len(leftoverImps) == 0 {
+		return
+	}
+
+	ledReqs, waitedReqs := f.calls.join(metrics.SharedCacheRequest, leftoverReqs)
+	ledImps, waitedImps := f.calls.join(metrics.SharedCacheImp, leftoverImps)
+
+	if len(ledReqs) > 0 || len(ledImps) > 0 {
+		fetcherReqData, fetcherImpData, fetcherErrs := f.fetcher.FetchRequests(ctx, ledReqs, ledImps)
+		errs = fetcherErrs
+
+		f.cache.Requests.Save(ctx, fetcherReqData)
+		f.cache.Imps.Save(ctx, fetcherImpData)
+		f.calls.finish(metrics.SharedCacheRequest, ledReqs, fetcherReqData, fetcherErrs, "Request")
+		f.calls.finish(metrics.SharedCacheImp, ledImps, fetcherImpData, fetcherErrs, "Imp")
+
+		requestData = mergeData(requestData, fetcherReqData)
+		impData = mergeData(impData, fetcherImpData)
+	}
+
+	requestData, errs = waitForCalls(ctx, waitedReqs, requestData, errs, "Request")
+	impData, errs = waitForCalls(ctx, waitedImps, impData, errs, "Imp")
+	return
+}
+
+// FetchResponses is not cached, since the stored responses are only used for testing and the odd line item
+func (f *fetcherWithSharedCache) FetchResponses(ctx context.Context, ids []string) (data map[string]json.RawMessage, errs []error) {
+	return f.fetcher.FetchResponses(ctx, ids)
+}
+
+func (f *fetcherWithSharedCache) FetchAccount(ctx context.Context, accountID string) (account json.RawMessage, errs []error) {
+	accountData := f.cache.Accounts.Get(ctx, []string{accountID})
+	if account, ok := accountData[accountID]; ok {
+		f.metricsEngine.RecordSharedCacheResult(metrics.SharedCacheAccount, metrics.CacheHit, 1)
+		return account, nil
+	}
+	f.metricsEngine.RecordSharedCacheResult(metrics.SharedCacheAccount, metrics.CacheMiss, 1)
+
+	led, waited := f.calls.join(metrics.SharedCacheAccount, []string{accountID})
+	if len(led) == 0 {
+		return waited[accountID].wait(ctx, accountID, "Account")
+	}
+
+	account, errs = f.fetcher.FetchAccount(ctx, accountID)
+	accountData = nil
+	if len(errs) == 0 {
+		accountData = map[string]json.RawMessage{accountID: account}
+		f.cache.Accounts.Save(ctx, accountData)
+	}
+	f.calls.finish(metrics.SharedCacheAccount, led, accountData, errs, "Account")
+	return account, errs
+}
+
+func (f *fetcherWithSharedCache) FetchCategories(ctx context.Context, primaryAdServer, publisherId, iabCategory string) (string, error) {
+	return f.fetcher.FetchCategories(ctx, primaryAdServer, publisherId, iabCategory)
+}
+
+// fetchCalls tracks the ids which are being fetched from the backend, by the type of their data
+type fetchCalls struct {
+	mutex sync.Mutex
+	calls map[string]*fetchCall
+}
+
+// fetchCall is the fetch of an id, whose data or error is set once done is closed
+type fetchCall struct {
+	done chan struct{}
+	data json.RawMessage
+	err  error
+}
+
+// join returns the ids which the caller must fetch, because no fetch of theirs is in progress, and the calls of
+// the others to wait for. The caller must finish the ids it fetches before waiting for any call.
+func (c *fetchCalls) join(dataType metrics.SharedCacheDataType, ids []string) (led []string, waited map[string]*fetchCall) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.calls == nil {
+		c.calls = make(map[string]*fetchCall)
+	}
+
+	led = make([]string, 0, len(ids))
+	for _, id := range ids {
+		key := string(dataType) + ":" + id
+		if call, ok := c.calls[key]; ok {
+			if waited == nil {
+				waited = make(map[string]*fetchCall)
+			}
+			waited[id] = call
+			continue
+		}
+		c.calls[key] = &fetchCall{done: make(chan struct{})}
+		led = append(led, id)
+	}
+	return led, waited
+}
+
+// finish completes the calls of the ids with the data fetched for them. The ids which have no data get the
+// NotFoundError of their id if there is one, or else the first of the other errors.
+func (c *fetchCalls) finish(dataType metrics.SharedCacheDataType, ids []string, data map[string]json.RawMessage, errs []error, storedDataType string) {
+	if len(ids) == 0 {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, id := range ids {
+		key := string(dataType) + ":" + id
+		call := c.calls[key]
+		delete(c.calls, key)
+		if value, ok := data[id]; ok {
+			call.data = value
+		} else {
+			call.err = fetchError(id, errs, storedDataType)
+		}
+		close(call.done)
+	}
+}
+
+func fetchError(id string, errs []error, storedDataType string) error {
+	var otherErr error
+	for _, err := range errs {
+		if notFoundErr, ok := err.(NotFoundError); ok {
+			if notFoundErr.ID == id {
+				return err
+			}
+		} else if otherErr == nil {
+			otherErr = err
+		}
+	}
+	if otherErr != nil {
+		return otherErr
+	}
+	return NotFoundError{ID: id, DataType: storedDataType}
+}
+
+// wait returns the data of the call once it is done, or an error if the context is done first
+func (call *fetchCall) wait(ctx context.Context, id string, storedDataType string) (json.RawMessage, []error) {
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return nil, []error{call.err}
+		}
+		return call.data, nil
+	case <-ctx.Done():
+		return nil, []error{fmt.Errorf("Stopped waiting for the fetch of Stored %s %s: %v", storedDataType, id, ctx.Err())}
+	}
+}
+
+func waitForCalls(ctx context.Context, calls map[string]*fetchCall, data map[string]json.RawMessage, errs []error, storedDataType string) (map[string]json.RawMessage, []error) {
+	for id, call := range calls {
+		value, callErrs := call.wait(ctx, id, storedDataType)
+		if len(callErrs) > 0 {
+			errs = append(errs, callErrs...)
+			continue
+		}
+		if data == nil {
+			data = make(map[string]json.RawMessage, len(calls))
+		}
+		data[id] = value
+	}
+	return data, errs
+}
@@ -0,0 +1,166 @@
+package stored_requests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/prebid/prebid-server/metrics"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupFetcherWithSharedCacheDeps() (*mockCache, *mockCache, *mockCache, *mockFetcher, AllFetcher, *metrics.MetricsEngineMock) {
+	reqCache := &mockCache{}
+	impCache := &mockCache{}
+	accCache := &mockCache{}
+	metricsEngine := &metrics.MetricsEngineMock{}
+	fetcher := &mockFetcher{}
+	aFetcherWithSharedCache := WithSharedCache(fetcher, Cache{reqCache, impCache, accCache}, metricsEngine)
+
+	return reqCache, impCache, accCache, fetcher, aFetcherWithSharedCache, metricsEngine
+}
+
+func TestSharedCacheHit(t *testing.T) {
+	reqCache, impCache, _, fetcher, aFetcherWithSharedCache, metricsEngine := setupFetcherWithSharedCacheDeps()
+	reqIDs := []string{"req-id"}
+	impIDs := []string{"imp-id"}
+	ctx := context.Background()
+
+	reqCache.On("Get", ctx, reqIDs).Return(map[string]json.RawMessage{"req-id": json.RawMessage(`{"req":true}`)})
+	impCache.On("Get", ctx, impIDs).Return(map[string]json.RawMessage{"imp-id": json.RawMessage(`{}`)})
+	metricsEngine.On("RecordSharedCacheResult", metrics.SharedCacheRequest, metrics.CacheHit, 1)
+	metricsEngine.On("RecordSharedCacheResult", metrics.SharedCacheRequest, metrics.CacheMiss, 0)
+	metricsEngine.On("RecordSharedCacheResult", metrics.SharedCacheImp, metrics.CacheHit, 1)
+	metricsEngine.On("RecordSharedCacheResult", metrics.SharedCacheImp, metrics.CacheMiss, 0)
+
+	reqData, impData, errs := aFetcherWithSharedCache.FetchRequests(ctx, reqIDs, impIDs)
+
+	reqCache.AssertExpectations(t)
+	impCache.AssertExpectations(t)
+	fetcher.AssertExpectations(t)
+	metricsEngine.AssertExpectations(t)
+	assert.JSONEq(t, `{"req":true}`, string(reqData["req-id"]), "FetchRequests should fetch the right request data")
+	assert.JSONEq(t, `{}`, string(impData["imp-id"]), "FetchRequests should fetch the right imp data")
+	assert.Len(t, errs, 0, "FetchRequests shouldn't return any errors")
+}
+
+func TestSharedCacheMiss(t *testing.T) {
+	reqCache, impCache, _, fetcher, aFetcherWithSharedCache, metricsEngine := setupFetcherWithSharedCacheDeps()
+	impIDs := []string{"cached", "uncached", "unknown"}
+	ctx := context.Background()
+	notFoundErr := NotFoundError{ID: "unknown", DataType: "Imp"}
+
+	reqCache.On("Get", ctx, []string(nil)).Return(map[string]json.RawMessage{})
+	impCache.On("Get", ctx, impIDs).Return(map[string]json.RawMessage{"cached": json.RawMessage(`true`)})
+	fetcher.On("FetchRequests", ctx, []string(nil), []string{"uncached", "unknown"}).Return(
+		map[string]json.RawMessage{},
+		map[string]json.RawMessage{"uncached": json.RawMessage(`false`)},
+		[]error{notFoundErr},
+	)
+	reqCache.On("Save", ctx, map[string]json.RawMessage{})
+	impCache.On("Save", ctx, map[string]json.RawMessage{"uncached": json.RawMessage(`false`)})
+	metricsEngine.On("RecordSharedCacheResult", metrics.SharedCacheRequest, metrics.CacheHit, 0)
+	metricsEngine.On("RecordSharedCacheResult", metrics.SharedCacheRequest, metrics.CacheMiss, 0)
+	metricsEngine.On("RecordSharedCacheResult", metrics.SharedCacheImp, metrics.CacheHit, 1)
+	metricsEngine.On("RecordSharedCacheResult", metrics.SharedCacheImp, metrics.CacheMiss, 2)
+
+	_, impData, errs := aFetcherWithSharedCache.FetchRequests(ctx, nil, impIDs)
+
+	reqCache.AssertExpectations(t)
+	impCache.AssertExpectations(t)
+	fetcher.AssertExpectations(t)
+	metricsEngine.AssertExpectations(t)
+	assert.Equal(t, map[string]json.RawMessage{"cached": json.RawMessage(`true`), "uncached": json.RawMessage(`false`)}, impData)
+	assert.Equal(t, []error{notFoundErr}, errs, "FetchRequests should return the errors of the backend")
+}
+
+func TestSharedCacheAccountMiss(t *testing.T) {
+	_, _, accCache, fetcher, aFetcherWithSharedCache, metricsEngine := setupFetcherWithSharedCacheDeps()
+	ctx := context.Background()
+
+	accCache.On("Get", ctx, []string{"uncached"}).Return(map[string]json.RawMessage{})
+	accCache.On("Save", ctx, map[string]json.RawMessage{"uncached": json.RawMessage(`true`)})
+	fetcher.On("FetchAccount", ctx, "uncached").Return(json.RawMessage(`true`), []error(nil))
+	metricsEngine.On("RecordSharedCacheResult", metrics.SharedCacheAccount, metrics.CacheMiss, 1)
+
+	account, errs := aFetcherWithSharedCache.FetchAccount(ctx, "uncached")
+
+	accCache.AssertExpectations(t)
+	fetcher.AssertExpectations(t)
+	metricsEngine.AssertExpectations(t)
+	assert.JSONEq(t, `true`, string(account), "FetchAccount should fetch the right account data")
+	assert.Len(t, errs, 0, "FetchAccount shouldn't return any errors")
+}
+
+func TestSharedCacheAccountError(t *testing.T) {
+	_, _, accCache, fetcher, aFetcherWithSharedCache, metricsEngine := setupFetcherWithSharedCacheDeps()
+	ctx := context.Background()
+	fetchErr := errors.New("backend is down")
+
+	accCache.On("Get", ctx, []string{"account"}).Return(map[string]json.RawMessage{})
+	fetcher.On("FetchAccount", ctx, "account").Return(json.RawMessage(nil), []error{fetchErr})
+	metricsEngine.On("RecordSharedCacheResult", metrics.SharedCacheAccount, metrics.CacheMiss, 1)
+
+	_, errs := aFetcherWithSharedCache.FetchAccount(ctx, "account")
+
+	accCache.AssertExpectations(t)
+	fetcher.AssertExpectations(t)
+	assert.Equal(t, []error{fetchErr}, errs, "FetchAccount should return the errors of the backend, and not save the account")
+}
+
+func TestFetchCallsShareFetches(t *testing.T) {
+	calls := fetchCalls{}
+
+	led, waited := calls.join(metrics.SharedCacheImp, []string{"a", "b"})
+	assert.Equal(t, []string{"a", "b"}, led)
+	assert.Empty(t, waited)
+
+	led, waited = calls.join(metrics.SharedCacheImp, []string{"b", "c"})
+	assert.Equal(t, []string{"c"}, led, "Only the ids which aren't being fetched should be led")
+	assert.Contains(t, waited, "b")
+
+	led, otherWaited := calls.join(metrics.SharedCacheRequest, []string{"b"})
+	assert.Equal(t, []string{"b"}, led, "The ids of different data types should be fetched separately")
+	assert.Empty(t, otherWaited)
+
+	fetchErr := errors.New("failed")
+	calls.finish(metrics.SharedCacheImp, []string{"a", "b"}, map[string]json.RawMessage{"b": json.RawMessage(`"b"`)}, []error{fetchErr}, "Imp")
+
+	data, errs := waited["b"].wait(context.Background(), "b", "Imp")
+	assert.Equal(t, json.RawMessage(`"b"`), data)
+	assert.Empty(t, errs)
+
+	led, _ = calls.join(metrics.SharedCacheImp, []string{"a", "b"})
+	assert.Equal(t, []string{"a", "b"}, led, "The finished ids should be fetched again")
+}
+
+func TestFetchCallsErrors(t *testing.T) {
+	calls := fetchCalls{}
+	notFoundErr := NotFoundError{ID: "a", DataType: "Account"}
+	fetchErr := errors.New("failed")
+
+	calls.join(metrics.SharedCacheAccount, []string{"a", "b", "c"})
+	_, waited := calls.join(metrics.SharedCacheAccount, []string{"a", "b", "c"})
+	calls.finish(metrics.SharedCacheAccount, []string{"a", "b"}, nil, []error{notFoundErr, fetchErr}, "Account")
+	calls.finish(metrics.SharedCacheAccount, []string{"c"}, nil, nil, "Account")
+
+	_, errs := waited["a"].wait(context.Background(), "a", "Account")
+	assert.Equal(t, []error{notFoundErr}, errs, "The NotFoundError of the id should be shared")
+	_, errs = waited["b"].wait(context.Background(), "b", "Account")
+	assert.Equal(t, []error{fetchErr}, errs, "The other errors should be shared with the ids not found")
+	_, errs = waited["c"].wait(context.Background(), "c", "Account")
+	assert.Equal(t, []error{NotFoundError{ID: "c", DataType: "Account"}}, errs)
+}
+
+func TestFetchCallsWaitCancelled(t *testing.T) {
+	calls := fetchCalls{}
+	calls.join(metrics.SharedCacheRequest, []string{"a"})
+	_, waited := calls.join(metrics.SharedCacheRequest, []string{"a"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, errs := waited["a"].wait(ctx, "a", "Request")
+	assert.Len(t, errs, 1, "Waiting should stop once the context is done")
+}
@@ -0,0 +1,266 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/version"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+const scopeName = "github.com/prebid/prebid-server"
+
+// exporter posts the ended spans in batches to an OTLP/HTTP collector, encoded as OTLP JSON. It exports a batch
+// once it is full, and exports the spans batched so far at every flush interval.
+type exporter struct {
+	// dropped counts the spans dropped since the last export. It comes first, to be 64 bit aligned for atomic access.
+	dropped uint64
+
+	client        *http.Client
+	endpoint      string
+	serviceName   string
+	batchSize     int
+	flushInterval time.Duration
+	timeout       time.Duration
+	spans         chan *Span
+	done          chan struct{}
+
+	closeMutex sync.RWMutex
+	closed     bool
+}
+
+func newExporter(cfg config.Tracing, client *http.Client) *exporter {
+	e := &exporter{
+		client:        client,
+		endpoint:      cfg.Endpoint,
+		serviceName:   cfg.ServiceName,
+		batchSize:     cfg.BatchSize,
+		flushInterval: time.Duration(cfg.FlushIntervalMs) * time.Millisecond,
+		timeout:       time.Duration(cfg.TimeoutMs) * time.Millisecond,
+		spans:         make(chan *Span, cfg.BufferSize),
+		done:          make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// export queues an ended span to be exported. The span is dropped if the queue is full, and counted to be logged
+// with the next export.
+func (e *exporter) export(span *Span) {
+	e.closeMutex.RLock()
+	defer e.closeMutex.RUnlock()
+
+	if e.closed {
+		return
+	}
+	select {
+	case e.spans <- span:
+	default:
+		atomic.AddUint64(&e.dropped, 1)
+	}
+}
+
+func (e *exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, e.batchSize)
+	for {
+		select {
+		case span, ok := <-e.spans:
+			if !ok {
+				e.post(batch)
+				return
+			}
+			batch = append(batch, span)
+			if len(batch) < e.batchSize {
+				continue
+			}
+		case <-ticker.C:
+		}
+		e.post(batch)
+		batch = batch[:0]
+	}
+}
+
+func (e *exporter) post(batch []*Span) {
+	if dropped := atomic.SwapUint64(&e.dropped, 0); dropped > 0 {
+		glog.Warningf("Tracing queue is full, %d spans were dropped", dropped)
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(e.encode(batch))
+	if err != nil {
+		glog.Errorf("Failed to encode %d spans: %v", len(batch), err)
+		return
+	}
+	req, err := http.NewRequest(http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		glog.Errorf("Failed to export %d spans: %v", len(batch), err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	resp, err := ctxhttp.Do(ctx, e.client, req)
+	if err != nil {
+		glog.Errorf("Failed to export %d spans: %v", len(batch), err)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		glog.Errorf("Failed to export %d spans: the collector responded with status %d", len(batch), resp.StatusCode)
+	}
+}
+
+// shutdown exports the queued spans before stopping
+func (e *exporter) shutdown() {
+	e.closeMutex.Lock()
+	if e.closed {
+		e.closeMutex.Unlock()
+		return
+	}
+	e.closed = true
+	close(e.spans)
+	e.closeMutex.Unlock()
+
+	<-e.done
+}
+
+// The OTLP JSON encoding of the spans, as exported to the /v1/traces endpoint of the collectors. The ids are hex
+// encoded and the 64 bit integers are strings, as the OTLP JSON mapping requires.
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              SpanKind        `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+// otlpStatus has the code 2 for the failed spans, and is left unset otherwise
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+func (e *exporter) encode(batch []*Span) otlpTraces {
+	spans := make([]otlpSpan, 0, len(batch))
+	for _, span := range batch {
+		spans = append(spans, encodeSpan(span))
+	}
+
+	return otlpTraces{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpAttribute{
+					encodeAttribute("service.name", e.serviceName),
+					encodeAttribute("service.version", version.Ver),
+				},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: scopeName, Version: version.Ver},
+				Spans: spans,
+			}},
+		}},
+	}
+}
+
+func encodeSpan(span *Span) otlpSpan {
+	span.mutex.Lock()
+	defer span.mutex.Unlock()
+
+	encoded := otlpSpan{
+		TraceID:           hex.EncodeToString(span.traceID[:]),
+		SpanID:            hex.EncodeToString(span.spanID[:]),
+		Name:              span.name,
+		Kind:              span.kind,
+		StartTimeUnixNano: strconv.FormatInt(span.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.end.UnixNano(), 10),
+	}
+	if span.parentSpanID != [8]byte{} {
+		encoded.ParentSpanID = hex.EncodeToString(span.parentSpanID[:])
+	}
+	for _, attribute := range span.attributes {
+		encoded.Attributes = append(encoded.Attributes, encodeAttribute(attribute.key, attribute.value))
+	}
+	if span.failed {
+		encoded.Status = otlpStatus{Code: 2, Message: span.errMessage}
+	}
+	return encoded
+}
+
+func encodeAttribute(key string, value interface{}) otlpAttribute {
+	var encoded otlpValue
+	switch value := value.(type) {
+	case string:
+		encoded.StringValue = &value
+	case int:
+		intValue := strconv.Itoa(value)
+		encoded.IntValue = &intValue
+	case int64:
+		intValue := strconv.FormatInt(value, 10)
+		encoded.IntValue = &intValue
+	case float64:
+		encoded.DoubleValue = &value
+	case bool:
+		encoded.BoolValue = &value
+	default:
+		stringValue := fmt.Sprint(value)
+		encoded.StringValue = &stringValue
+	}
+	return otlpAttribute{Key: key, Value: encoded}
+}
@@ -0,0 +1,140 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCollector struct {
+	server *httptest.Server
+
+	mutex    sync.Mutex
+	requests []otlpTraces
+}
+
+func newFakeCollector(t *testing.T) *fakeCollector {
+	collector := &fakeCollector{}
+	collector.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var traces otlpTraces
+		require.NoError(t, json.Unmarshal(body, &traces))
+		collector.mutex.Lock()
+		collector.requests = append(collector.requests, traces)
+		collector.mutex.Unlock()
+	}))
+	t.Cleanup(collector.server.Close)
+	return collector
+}
+
+func (c *fakeCollector) exported() []otlpTraces {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.requests
+}
+
+func TestExportBatches(t *testing.T) {
+	collector := newFakeCollector(t)
+	tracer := NewTracer(config.Tracing{
+		Enabled:            true,
+		Endpoint:           collector.server.URL + "/v1/traces",
+		ServiceName:        "pbs",
+		SamplePercent:      100,
+		PropagationHeaders: []string{"traceparent"},
+		BatchSize:          2,
+		FlushIntervalMs:    60000,
+		TimeoutMs:          1000,
+		BufferSize:         10,
+	}, collector.server.Client())
+
+	ctx, root := tracer.StartServerSpan(context.Background(), http.Header{}, "/openrtb2/auction")
+	_, child := StartSpan(ctx, "bidder.request", SpanKindClient)
+	child.SetAttribute("prebid.bidder", "appnexus")
+	child.End()
+	root.End()
+	_, other := tracer.StartServerSpan(context.Background(), http.Header{}, "/openrtb2/auction")
+	other.End()
+	tracer.Shutdown()
+
+	exported := collector.exported()
+	require.Len(t, exported, 2, "The full batch and the spans left at the shutdown should be exported")
+
+	resourceSpans := exported[0].ResourceSpans
+	require.Len(t, resourceSpans, 1)
+	assert.Contains(t, resourceSpans[0].Resource.Attributes, encodeAttribute("service.name", "pbs"))
+	require.Len(t, resourceSpans[0].ScopeSpans, 1)
+	spans := resourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+	assert.Equal(t, "bidder.request", spans[0].Name)
+	assert.Equal(t, SpanKindClient, spans[0].Kind)
+	assert.Equal(t, []otlpAttribute{encodeAttribute("prebid.bidder", "appnexus")}, spans[0].Attributes)
+	assert.Equal(t, spans[1].SpanID, spans[0].ParentSpanID)
+	assert.Equal(t, spans[1].TraceID, spans[0].TraceID)
+	assert.Empty(t, spans[1].ParentSpanID)
+	assert.NotEmpty(t, spans[1].StartTimeUnixNano)
+
+	require.Len(t, exported[1].ResourceSpans[0].ScopeSpans[0].Spans, 1)
+}
+
+func TestExportDropsAfterShutdown(t *testing.T) {
+	collector := newFakeCollector(t)
+	tracer := NewTracer(config.Tracing{
+		Enabled:         true,
+		Endpoint:        collector.server.URL,
+		SamplePercent:   100,
+		BatchSize:       10,
+		FlushIntervalMs: 60000,
+		TimeoutMs:       1000,
+		BufferSize:      10,
+	}, collector.server.Client())
+
+	tracer.Shutdown()
+	tracer.Shutdown()
+	_, span := tracer.StartServerSpan(context.Background(), http.Header{}, "/openrtb2/auction")
+	span.End()
+
+	assert.Empty(t, collector.exported())
+}
+
+func TestExportDropsWhenFull(t *testing.T) {
+	e := &exporter{
+		spans: make(chan *Span, 1),
+	}
+
+	e.export(&Span{name: "/openrtb2/auction"})
+	e.export(&Span{name: "/openrtb2/auction"})
+	e.export(&Span{name: "/openrtb2/auction"})
+
+	assert.Len(t, e.spans, 1)
+	assert.Equal(t, uint64(2), e.dropped, "The spans past the size of the queue should be dropped and counted")
+
+	e.post(nil)
+	assert.Equal(t, uint64(0), e.dropped, "The dropped spans should be logged and reset with the next export")
+}
+
+func TestEncodeAttribute(t *testing.T) {
+	stringValue := "value"
+	intValue := "42"
+	int64Value := "43"
+	doubleValue := 1.5
+	boolValue := true
+	otherValue := "[a b]"
+
+	assert.Equal(t, otlpValue{StringValue: &stringValue}, encodeAttribute("key", "value").Value)
+	assert.Equal(t, otlpValue{IntValue: &intValue}, encodeAttribute("key", 42).Value)
+	assert.Equal(t, otlpValue{IntValue: &int64Value}, encodeAttribute("key", int64(43)).Value)
+	assert.Equal(t, otlpValue{DoubleValue: &doubleValue}, encodeAttribute("key", 1.5).Value)
+	assert.Equal(t, otlpValue{BoolValue: &boolValue}, encodeAttribute("key", true).Value)
+	assert.Equal(t, otlpValue{StringValue: &otherValue}, encodeAttribute("key", []string{"a", "b"}).Value)
+}
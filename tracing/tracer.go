@@ -0,0 +1,303 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	mathrand "math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/config"
+)
+
+// SpanKind is the OpenTelemetry kind of a span
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+// Tracer starts the spans of the sampled requests, and exports them to an OTLP collector once they end. A nil Tracer
+// traces no request, so that callers don't need to check if the tracing is enabled.
+type Tracer struct {
+	samplePercent       float64
+	honorCallerSampling bool
+	propagationHeaders  []string
+	exporter            *exporter
+}
+
+// NewTracer returns the tracer of the config, which is nil when the tracing is disabled
+func NewTracer(cfg config.Tracing, client *http.Client) *Tracer {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	return &Tracer{
+		samplePercent:       cfg.SamplePercent,
+		honorCallerSampling: cfg.HonorCallerSampling,
+		propagationHeaders:  cfg.PropagationHeaders,
+		exporter:            newExporter(cfg, client),
+	}
+}
+
+// Span is a timed operation of a traced request. All the methods of a nil Span do nothing, which is what the
+// requests which aren't traced get.
+type Span struct {
+	tracer       *Tracer
+	traceID      [16]byte
+	spanID       [8]byte
+	parentSpanID [8]byte
+	name         string
+	kind         SpanKind
+	start        time.Time
+
+	mutex      sync.Mutex
+	end        time.Time
+	attributes []attribute
+	errMessage string
+	failed     bool
+	ended      bool
+}
+
+type attribute struct {
+	key   string
+	value interface{}
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpan returns a copy of the context carrying the span, which the spans started from it are children of
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	if span == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+// SpanFromContext returns the span the context carries, or nil if the request isn't traced
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanContextKey{}).(*Span)
+	return span
+}
+
+// StartServerSpan starts the span of an HTTP request received by Prebid Server. The span continues the trace of the
+// caller if it sent one in a propagation header. The request is sampled at the sample percent of the tracer, unless
+// the tracer honors the sampling decision of the caller which sent a trace.
+func (t *Tracer) StartServerSpan(ctx context.Context, header http.Header, name string) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	span := &Span{
+		tracer: t,
+		spanID: newSpanID(),
+		name:   name,
+		kind:   SpanKindServer,
+		start:  time.Now(),
+	}
+	traceID, parentSpanID, sampled, ok := t.extract(header)
+	if !ok || !t.honorCallerSampling {
+		sampled = t.samplePercent > 0 && mathrand.Float64()*100 < t.samplePercent
+	}
+	if !sampled {
+		return ctx, nil
+	}
+	if ok {
+		span.traceID = traceID
+		span.parentSpanID = parentSpanID
+	} else {
+		span.traceID = newTraceID()
+	}
+	return ContextWithSpan(ctx, span), span
+}
+
+// StartSpan starts a child of the span the context carries, and returns a copy of the context carrying the child.
+// No span is started if the context doesn't carry any.
+func StartSpan(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+
+	span := &Span{
+		tracer:       parent.tracer,
+		traceID:      parent.traceID,
+		spanID:       newSpanID(),
+		parentSpanID: parent.spanID,
+		name:         name,
+		kind:         kind,
+		start:        time.Now(),
+	}
+	return ContextWithSpan(ctx, span), span
+}
+
+// InjectHeaders returns a copy of the headers with the trace context of the span the context carries set in each one
+// of the propagation headers, so that the server called continues the trace. The headers are returned as they are if
+// the context doesn't carry any span.
+func InjectHeaders(ctx context.Context, header http.Header) http.Header {
+	span := SpanFromContext(ctx)
+	if span == nil || len(span.tracer.propagationHeaders) == 0 {
+		return header
+	}
+
+	injected := header.Clone()
+	if injected == nil {
+		injected = http.Header{}
+	}
+	traceParent := span.traceParent()
+	for _, name := range span.tracer.propagationHeaders {
+		injected.Set(name, traceParent)
+	}
+	return injected
+}
+
+// Handle traces the requests of the handler with a server span of the name, which the context of the request carries
+func (t *Tracer) Handle(name string, handle httprouter.Handle) httprouter.Handle {
+	if t == nil {
+		return handle
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		ctx, span := t.StartServerSpan(r.Context(), r.Header, name)
+		if span == nil {
+			handle(w, r, params)
+			return
+		}
+
+		span.SetAttribute("http.method", r.Method)
+		span.SetAttribute("http.route", name)
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handle(recorder, r.WithContext(ctx), params)
+
+		span.SetAttribute("http.status_code", recorder.status)
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetError(fmt.Errorf("%s responded with status %d", name, recorder.status))
+		}
+		span.End()
+	}
+}
+
+// Shutdown exports the spans which have ended before stopping the exports
+func (t *Tracer) Shutdown() {
+	if t == nil {
+		return
+	}
+	t.exporter.shutdown()
+}
+
+// SetAttribute sets an attribute of the span. The strings, integers, floats and booleans are exported as such, and
+// the other values as their default format.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.ended {
+		s.attributes = append(s.attributes, attribute{key: key, value: value})
+	}
+}
+
+// SetError marks the span as failed with the error, unless it is nil
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.ended {
+		s.failed = true
+		s.errMessage = err.Error()
+	}
+}
+
+// End ends the span and queues it for the export. The span isn't changed anymore once ended.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	if s.ended {
+		s.mutex.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mutex.Unlock()
+
+	s.tracer.exporter.export(s)
+}
+
+// traceParent returns the W3C traceparent header of the span, as a sampled parent
+func (s *Span) traceParent() string {
+	return "00-" + hex.EncodeToString(s.traceID[:]) + "-" + hex.EncodeToString(s.spanID[:]) + "-01"
+}
+
+// extract returns the trace context of the first propagation header with a valid W3C traceparent
+func (t *Tracer) extract(header http.Header) (traceID [16]byte, parentSpanID [8]byte, sampled bool, ok bool) {
+	for _, name := range t.propagationHeaders {
+		if traceID, parentSpanID, sampled, ok = parseTraceParent(header.Get(name)); ok {
+			return
+		}
+	}
+	return
+}
+
+// parseTraceParent parses a W3C traceparent header, as in "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+// The fields the later versions may append are ignored.
+func parseTraceParent(value string) (traceID [16]byte, parentSpanID [8]byte, sampled bool, ok bool) {
+	fields := strings.Split(strings.TrimSpace(value), "-")
+	if len(fields) < 4 || len(fields[0]) != 2 || fields[0] == "ff" || (fields[0] == "00" && len(fields) != 4) {
+		return
+	}
+	var version [1]byte
+	var flags [1]byte
+	if !decodeHex(version[:], fields[0]) || !decodeHex(traceID[:], fields[1]) || !decodeHex(parentSpanID[:], fields[2]) || !decodeHex(flags[:], fields[3]) {
+		return
+	}
+	if traceID == [16]byte{} || parentSpanID == [8]byte{} {
+		return
+	}
+	return traceID, parentSpanID, flags[0]&0x01 == 0x01, true
+}
+
+// decodeHex decodes the lowercase hex value into dst, which it must fill exactly
+func decodeHex(dst []byte, value string) bool {
+	if len(value) != hex.EncodedLen(len(dst)) || strings.ToLower(value) != value {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(value))
+	return err == nil
+}
+
+func newTraceID() (id [16]byte) {
+	for id == [16]byte{} {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() (id [8]byte) {
+	for id == [8]byte{} {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+// statusRecorder records the status code the handler responds with
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,243 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTracer(samplePercent float64, honorCallerSampling bool) *Tracer {
+	return &Tracer{
+		samplePercent:       samplePercent,
+		honorCallerSampling: honorCallerSampling,
+		propagationHeaders:  []string{"traceparent", "x-trace"},
+		exporter: &exporter{
+			spans: make(chan *Span, 10),
+			done:  make(chan struct{}),
+		},
+	}
+}
+
+func TestNewTracerDisabled(t *testing.T) {
+	tracer := NewTracer(config.Tracing{}, http.DefaultClient)
+	assert.Nil(t, tracer)
+
+	ctx, span := tracer.StartServerSpan(context.Background(), http.Header{}, "/openrtb2/auction")
+	assert.Nil(t, span, "A nil tracer should trace no request")
+	assert.Nil(t, SpanFromContext(ctx))
+	tracer.Shutdown()
+}
+
+func TestStartServerSpan(t *testing.T) {
+	testCases := []struct {
+		description              string
+		givenSamplePercent       float64
+		givenHonorCallerSampling bool
+		givenHeader              http.Header
+		expectSpan               bool
+		expectedTraceID          string
+		expectedParentSpanID     string
+	}{
+		{
+			description:        "New trace sampled",
+			givenSamplePercent: 100,
+			givenHeader:        http.Header{},
+			expectSpan:         true,
+		},
+		{
+			description:        "New trace not sampled",
+			givenSamplePercent: 0,
+			givenHeader:        http.Header{},
+			expectSpan:         false,
+		},
+		{
+			description:          "Trace of the caller sampled",
+			givenSamplePercent:   100,
+			givenHeader:          http.Header{"Traceparent": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"}},
+			expectSpan:           true,
+			expectedTraceID:      "4bf92f3577b34da6a3ce929d0e0e4736",
+			expectedParentSpanID: "00f067aa0ba902b7",
+		},
+		{
+			description:        "Sampled trace of the caller not sampled",
+			givenSamplePercent: 0,
+			givenHeader:        http.Header{"Traceparent": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}},
+			expectSpan:         false,
+		},
+		{
+			description:              "Sampled trace of the caller honored",
+			givenSamplePercent:       0,
+			givenHonorCallerSampling: true,
+			givenHeader:              http.Header{"Traceparent": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}},
+			expectSpan:               true,
+			expectedTraceID:          "4bf92f3577b34da6a3ce929d0e0e4736",
+			expectedParentSpanID:     "00f067aa0ba902b7",
+		},
+		{
+			description:              "Sampled trace of the caller in another propagation header honored",
+			givenSamplePercent:       0,
+			givenHonorCallerSampling: true,
+			givenHeader:              http.Header{"Traceparent": []string{"invalid"}, "X-Trace": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}},
+			expectSpan:               true,
+			expectedTraceID:          "4bf92f3577b34da6a3ce929d0e0e4736",
+			expectedParentSpanID:     "00f067aa0ba902b7",
+		},
+		{
+			description:              "Unsampled trace of the caller honored",
+			givenSamplePercent:       100,
+			givenHonorCallerSampling: true,
+			givenHeader:              http.Header{"Traceparent": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"}},
+			expectSpan:               false,
+		},
+		{
+			description:              "New trace sampled while honoring the callers",
+			givenSamplePercent:       100,
+			givenHonorCallerSampling: true,
+			givenHeader:              http.Header{},
+			expectSpan:               true,
+		},
+	}
+
+	for _, test := range testCases {
+		tracer := newTestTracer(test.givenSamplePercent, test.givenHonorCallerSampling)
+
+		ctx, span := tracer.StartServerSpan(context.Background(), test.givenHeader, "/openrtb2/auction")
+
+		if !test.expectSpan {
+			assert.Nil(t, span, test.description)
+			continue
+		}
+		require.NotNil(t, span, test.description)
+		assert.Equal(t, span, SpanFromContext(ctx), test.description+":context")
+		assert.Equal(t, SpanKindServer, span.kind, test.description+":kind")
+		if test.expectedTraceID != "" {
+			encoded := encodeSpan(span)
+			assert.Equal(t, test.expectedTraceID, encoded.TraceID, test.description+":trace_id")
+			assert.Equal(t, test.expectedParentSpanID, encoded.ParentSpanID, test.description+":parent_span_id")
+		}
+	}
+}
+
+func TestParseTraceParent(t *testing.T) {
+	testCases := []struct {
+		description   string
+		givenValue    string
+		expectedOk    bool
+		expectSampled bool
+	}{
+		{description: "Sampled", givenValue: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", expectedOk: true, expectSampled: true},
+		{description: "Not sampled", givenValue: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", expectedOk: true},
+		{description: "Later version", givenValue: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", expectedOk: true, expectSampled: true},
+		{description: "Empty", givenValue: ""},
+		{description: "Invalid version", givenValue: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{description: "Extra fields in version 00", givenValue: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"},
+		{description: "Zero trace id", givenValue: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{description: "Zero parent id", givenValue: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+		{description: "Short trace id", givenValue: "00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01"},
+		{description: "Uppercase", givenValue: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"},
+	}
+
+	for _, test := range testCases {
+		_, _, sampled, ok := parseTraceParent(test.givenValue)
+		assert.Equal(t, test.expectedOk, ok, test.description)
+		assert.Equal(t, test.expectSampled, sampled, test.description+":sampled")
+	}
+}
+
+func TestStartSpan(t *testing.T) {
+	ctx, span := StartSpan(context.Background(), "stored_requests.fetch", SpanKindInternal)
+	assert.Nil(t, span, "No span should be started without a parent")
+	assert.Nil(t, SpanFromContext(ctx))
+
+	tracer := newTestTracer(100, false)
+	ctx, parent := tracer.StartServerSpan(context.Background(), http.Header{}, "/openrtb2/auction")
+	childCtx, child := StartSpan(ctx, "bidder.request", SpanKindClient)
+
+	require.NotNil(t, child)
+	assert.Equal(t, child, SpanFromContext(childCtx))
+	assert.Equal(t, parent.traceID, child.traceID, "The child should be in the trace of its parent")
+	assert.Equal(t, parent.spanID, child.parentSpanID)
+	assert.NotEqual(t, parent.spanID, child.spanID)
+	assert.Equal(t, SpanKindClient, child.kind)
+}
+
+func TestInjectHeaders(t *testing.T) {
+	header := http.Header{"Content-Type": []string{"application/json"}}
+	assert.Equal(t, header, InjectHeaders(context.Background(), header), "The headers should be left as they are without a span")
+
+	tracer := newTestTracer(100, false)
+	ctx, span := tracer.StartServerSpan(context.Background(), http.Header{}, "/openrtb2/auction")
+	injected := InjectHeaders(ctx, header)
+
+	assert.Equal(t, span.traceParent(), injected.Get("traceparent"))
+	assert.Equal(t, span.traceParent(), injected.Get("x-trace"))
+	assert.Equal(t, "application/json", injected.Get("Content-Type"))
+	assert.Empty(t, header.Get("traceparent"), "The headers given should not be modified")
+
+	traceID, parentSpanID, sampled, ok := parseTraceParent(injected.Get("traceparent"))
+	assert.True(t, ok)
+	assert.True(t, sampled)
+	assert.Equal(t, span.traceID, traceID)
+	assert.Equal(t, span.spanID, parentSpanID, "The span should be the parent of the server called")
+}
+
+func TestSpanEnd(t *testing.T) {
+	tracer := newTestTracer(100, false)
+	_, span := tracer.StartServerSpan(context.Background(), http.Header{}, "/openrtb2/auction")
+
+	span.SetAttribute("prebid.account", "acct")
+	span.SetError(errors.New("failed"))
+	span.SetError(nil)
+	span.End()
+	span.SetAttribute("ignored", true)
+	span.End()
+
+	require.Len(t, tracer.exporter.spans, 1, "The span should be exported once")
+	encoded := encodeSpan(<-tracer.exporter.spans)
+	assert.Equal(t, []otlpAttribute{encodeAttribute("prebid.account", "acct")}, encoded.Attributes, "The span shouldn't change once ended")
+	assert.Equal(t, otlpStatus{Code: 2, Message: "failed"}, encoded.Status)
+
+	var nilSpan *Span
+	nilSpan.SetAttribute("key", "value")
+	nilSpan.SetError(errors.New("failed"))
+	nilSpan.End()
+}
+
+func TestHandle(t *testing.T) {
+	tracer := newTestTracer(100, false)
+	var handledSpan *Span
+	handle := tracer.Handle("/openrtb2/auction", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		handledSpan = SpanFromContext(r.Context())
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	recorder := httptest.NewRecorder()
+	handle(recorder, httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil), nil)
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	require.NotNil(t, handledSpan, "The context of the request should carry the span")
+	require.Len(t, tracer.exporter.spans, 1)
+	encoded := encodeSpan(<-tracer.exporter.spans)
+	assert.Equal(t, "/openrtb2/auction", encoded.Name)
+	assert.Contains(t, encoded.Attributes, encodeAttribute("http.status_code", http.StatusServiceUnavailable))
+	assert.Equal(t, 2, encoded.Status.Code, "The 5xx responses should fail the span")
+}
+
+func TestHandleNilTracer(t *testing.T) {
+	var tracer *Tracer
+	handled := false
+	handle := tracer.Handle("/openrtb2/auction", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		handled = true
+		assert.Nil(t, SpanFromContext(r.Context()))
+	})
+
+	handle(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil), nil)
+	assert.True(t, handled)
+}
@@ -0,0 +1,45 @@
+package trafficshaping
+
+import (
+	"hash/fnv"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// bucketCount is the number of buckets requests are hashed into, so that percentages with two decimals are honored
+const bucketCount = 10000
+
+// Assign places the request in or out of the traffic of each shaped bidder. The groups are picked from a hash of the
+// experiment, the request id and the bidder, so that a request always lands in the same groups of an experiment while
+// the groups of different bidders are independent of each other. It returns nil if traffic shaping is disabled.
+func Assign(requestID string, cfg config.AccountTrafficShaping) openrtb_ext.ExtTrafficShaping {
+	if !cfg.Enabled || len(cfg.Bidders) == 0 {
+		return nil
+	}
+
+	assignment := make(openrtb_ext.ExtTrafficShaping, len(cfg.Bidders))
+	for bidder, percent := range cfg.Bidders {
+		if float64(bucket(cfg.Experiment, requestID, bidder)) < percent*bucketCount/100 {
+			assignment[bidder] = openrtb_ext.TrafficShapingIncluded
+		} else {
+			assignment[bidder] = openrtb_ext.TrafficShapingExcluded
+		}
+	}
+	return assignment
+}
+
+// Excludes tells if the request was kept away from the bidder. Bidders without traffic shaping get every request.
+func Excludes(assignment openrtb_ext.ExtTrafficShaping, bidder openrtb_ext.BidderName) bool {
+	return assignment[string(bidder)] == openrtb_ext.TrafficShapingExcluded
+}
+
+func bucket(experiment, requestID, bidder string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(experiment))
+	h.Write([]byte{0})
+	h.Write([]byte(requestID))
+	h.Write([]byte{0})
+	h.Write([]byte(bidder))
+	return h.Sum64() % bucketCount
+}
@@ -0,0 +1,79 @@
+package trafficshaping
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssign(t *testing.T) {
+	testCases := []struct {
+		description        string
+		givenConfig        config.AccountTrafficShaping
+		expectedAssignment openrtb_ext.ExtTrafficShaping
+	}{
+		{
+			description: "Disabled",
+			givenConfig: config.AccountTrafficShaping{Bidders: map[string]float64{"appnexus": 100}},
+		},
+		{
+			description: "No shaped bidders",
+			givenConfig: config.AccountTrafficShaping{Enabled: true},
+		},
+		{
+			description: "All or nothing",
+			givenConfig: config.AccountTrafficShaping{Enabled: true, Bidders: map[string]float64{"appnexus": 100, "rubicon": 0}},
+			expectedAssignment: openrtb_ext.ExtTrafficShaping{
+				"appnexus": openrtb_ext.TrafficShapingIncluded,
+				"rubicon":  openrtb_ext.TrafficShapingExcluded,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedAssignment, Assign("request-id", test.givenConfig), test.description)
+	}
+}
+
+func TestAssignIsDeterministic(t *testing.T) {
+	cfg := config.AccountTrafficShaping{Enabled: true, Experiment: "exp-1", Bidders: map[string]float64{"appnexus": 50, "rubicon": 50}}
+
+	for i := 0; i < 100; i++ {
+		requestID := strconv.Itoa(i)
+		assert.Equal(t, Assign(requestID, cfg), Assign(requestID, cfg), "request "+requestID)
+	}
+}
+
+func TestAssignSplitsTraffic(t *testing.T) {
+	cfg := config.AccountTrafficShaping{Enabled: true, Experiment: "exp-1", Bidders: map[string]float64{"appnexus": 30}}
+	otherCfg := config.AccountTrafficShaping{Enabled: true, Experiment: "exp-2", Bidders: map[string]float64{"appnexus": 30}}
+
+	included, moved := 0, 0
+	for i := 0; i < 10000; i++ {
+		assignment := Assign(strconv.Itoa(i), cfg)
+		if assignment["appnexus"] == openrtb_ext.TrafficShapingIncluded {
+			included++
+		}
+		if assignment["appnexus"] != Assign(strconv.Itoa(i), otherCfg)["appnexus"] {
+			moved++
+		}
+	}
+
+	assert.InDelta(t, 3000, included, 200, "share of the requests sent to the bidder")
+	assert.NotZero(t, moved, "a new experiment splits the traffic anew")
+}
+
+func TestExcludes(t *testing.T) {
+	assignment := openrtb_ext.ExtTrafficShaping{
+		"appnexus": openrtb_ext.TrafficShapingIncluded,
+		"rubicon":  openrtb_ext.TrafficShapingExcluded,
+	}
+
+	assert.False(t, Excludes(assignment, "appnexus"), "included bidder")
+	assert.True(t, Excludes(assignment, "rubicon"), "excluded bidder")
+	assert.False(t, Excludes(assignment, "openx"), "bidder without traffic shaping")
+	assert.False(t, Excludes(nil, "rubicon"), "traffic shaping disabled")
+}
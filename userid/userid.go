@@ -0,0 +1,110 @@
+package userid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// ParseCookieFromRequest returns the id of the cookie of the request. The cookies whose signature doesn't match the
+// signing key of the config are ignored.
+func ParseCookieFromRequest(r *http.Request, cfg *config.UserID) (id string, ok bool) {
+	cookie, err := r.Cookie(cfg.CookieName)
+	if err != nil {
+		return "", false
+	}
+
+	separator := strings.LastIndexByte(cookie.Value, '.')
+	if separator <= 0 {
+		return "", false
+	}
+	id = cookie.Value[:separator]
+	signature, err := base64.RawURLEncoding.DecodeString(cookie.Value[separator+1:])
+	if err != nil || !hmac.Equal(signature, sign(cfg.SigningKey, id)) {
+		return "", false
+	}
+	return id, true
+}
+
+// SetCookieOnResponse persists the signed id in the cookie of the response, which expires after the TTL of the config
+func SetCookieOnResponse(w http.ResponseWriter, cfg *config.UserID, domain string, id string) {
+	httpCookie := &http.Cookie{
+		Name:     cfg.CookieName,
+		Value:    id + "." + base64.RawURLEncoding.EncodeToString(sign(cfg.SigningKey, id)),
+		Expires:  time.Now().Add(cfg.TTLDuration()),
+		Path:     "/",
+		Domain:   domain,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	}
+	w.Header().Add("Set-Cookie", httpCookie.String())
+}
+
+// PublisherID returns the id sent for the account, which is derived from the id of the cookie so that the accounts
+// can't match their users through it
+func PublisherID(cfg *config.UserID, id string, accountID string) string {
+	return hex.EncodeToString(sign(cfg.SigningKey, accountID+"|"+id)[:16])
+}
+
+// AddEID sends the id in user.ext.eids under the source, replacing the eid the request may already have for it. The
+// eid is restricted to the bidders through ext.prebid.data.eidpermissions, unless there are none.
+func AddEID(req *openrtb_ext.RequestWrapper, source string, id string, bidders []string) error {
+	userExt, err := req.GetUserExt()
+	if err != nil {
+		return err
+	}
+	var eids []openrtb_ext.ExtUserEid
+	if userEids := userExt.GetEid(); userEids != nil {
+		for _, eid := range *userEids {
+			if eid.Source != source {
+				eids = append(eids, eid)
+			}
+		}
+	}
+	eids = append(eids, openrtb_ext.ExtUserEid{
+		Source: source,
+		Uids:   []openrtb_ext.ExtUserEidUid{{ID: id, Atype: 1}},
+	})
+	userExt.SetEid(&eids)
+
+	if len(bidders) == 0 {
+		return nil
+	}
+
+	requestExt, err := req.GetRequestExt()
+	if err != nil {
+		return err
+	}
+	prebid := requestExt.GetPrebid()
+	if prebid == nil {
+		prebid = &openrtb_ext.ExtRequestPrebid{}
+	}
+	if prebid.Data == nil {
+		prebid.Data = &openrtb_ext.ExtRequestPrebidData{}
+	}
+	var permissions []openrtb_ext.ExtRequestPrebidDataEidPermission
+	for _, permission := range prebid.Data.EidPermissions {
+		if permission.Source != source {
+			permissions = append(permissions, permission)
+		}
+	}
+	prebid.Data.EidPermissions = append(permissions, openrtb_ext.ExtRequestPrebidDataEidPermission{
+		Source:  source,
+		Bidders: bidders,
+	})
+	requestExt.SetPrebid(prebid)
+	return nil
+}
+
+func sign(key string, value string) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,118 @@
+package userid
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = config.UserID{Enabled: true, CookieName: "fpid", TTLDays: 30, SigningKey: "secret", Source: "prebid.org"}
+
+func TestCookieRoundTrip(t *testing.T) {
+	w := httptest.NewRecorder()
+	SetCookieOnResponse(w, &testConfig, "prebid.org", "7d8c21b1-0b0a-4e30-9f56-d1b3e5a7b2c4")
+
+	response := http.Response{Header: w.Header()}
+	cookies := response.Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "fpid", cookies[0].Name)
+	assert.Equal(t, "prebid.org", cookies[0].Domain)
+	assert.True(t, cookies[0].Secure)
+	assert.Equal(t, http.SameSiteNoneMode, cookies[0].SameSite)
+	assert.WithinDuration(t, time.Now().Add(30*24*time.Hour), cookies[0].Expires, time.Minute)
+
+	r := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+	r.AddCookie(cookies[0])
+	id, ok := ParseCookieFromRequest(r, &testConfig)
+	assert.True(t, ok)
+	assert.Equal(t, "7d8c21b1-0b0a-4e30-9f56-d1b3e5a7b2c4", id)
+
+	otherKeyConfig := testConfig
+	otherKeyConfig.SigningKey = "other"
+	_, ok = ParseCookieFromRequest(r, &otherKeyConfig)
+	assert.False(t, ok, "other signing key")
+}
+
+func TestParseCookieFromRequestInvalid(t *testing.T) {
+	testCases := []struct {
+		description string
+		givenValue  string
+	}{
+		{description: "Unsigned", givenValue: "7d8c21b1-0b0a-4e30-9f56-d1b3e5a7b2c4"},
+		{description: "Malformed signature", givenValue: "7d8c21b1-0b0a-4e30-9f56-d1b3e5a7b2c4.!!"},
+		{description: "Forged signature", givenValue: "7d8c21b1-0b0a-4e30-9f56-d1b3e5a7b2c4.c2lnbmF0dXJl"},
+		{description: "Signature only", givenValue: ".c2lnbmF0dXJl"},
+	}
+
+	for _, test := range testCases {
+		r := httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil)
+		r.AddCookie(&http.Cookie{Name: "fpid", Value: test.givenValue})
+
+		_, ok := ParseCookieFromRequest(r, &testConfig)
+
+		assert.False(t, ok, test.description)
+	}
+
+	_, ok := ParseCookieFromRequest(httptest.NewRequest(http.MethodPost, "/openrtb2/auction", nil), &testConfig)
+	assert.False(t, ok, "missing cookie")
+}
+
+func TestPublisherID(t *testing.T) {
+	id := PublisherID(&testConfig, "7d8c21b1-0b0a-4e30-9f56-d1b3e5a7b2c4", "1001")
+
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, PublisherID(&testConfig, "7d8c21b1-0b0a-4e30-9f56-d1b3e5a7b2c4", "1001"), "same account")
+	assert.NotEqual(t, id, PublisherID(&testConfig, "7d8c21b1-0b0a-4e30-9f56-d1b3e5a7b2c4", "1002"), "other account")
+	assert.NotEqual(t, id, PublisherID(&testConfig, "0b0a7d8c-21b1-4e30-9f56-d1b3e5a7b2c4", "1001"), "other user")
+}
+
+func TestAddEID(t *testing.T) {
+	testCases := []struct {
+		description     string
+		givenUser       *openrtb2.User
+		givenExt        json.RawMessage
+		givenBidders    []string
+		expectedUserExt json.RawMessage
+		expectedExt     json.RawMessage
+	}{
+		{
+			description:     "No user",
+			expectedUserExt: json.RawMessage(`{"eids":[{"source":"prebid.org","uids":[{"id":"fpid","atype":1}]}]}`),
+		},
+		{
+			description:     "Other eids kept and eid of the source replaced",
+			givenUser:       &openrtb2.User{Ext: json.RawMessage(`{"eids":[{"source":"prebid.org","uids":[{"id":"old"}]},{"source":"adserver.org","uids":[{"id":"tdid"}]}]}`)},
+			expectedUserExt: json.RawMessage(`{"eids":[{"source":"adserver.org","uids":[{"id":"tdid"}]},{"source":"prebid.org","uids":[{"id":"fpid","atype":1}]}]}`),
+		},
+		{
+			description:     "Eid restricted to the bidders",
+			givenExt:        json.RawMessage(`{"prebid":{"data":{"eidpermissions":[{"source":"prebid.org","bidders":["*"]},{"source":"adserver.org","bidders":["rubicon"]}]}}}`),
+			givenBidders:    []string{"appnexus"},
+			expectedUserExt: json.RawMessage(`{"eids":[{"source":"prebid.org","uids":[{"id":"fpid","atype":1}]}]}`),
+			expectedExt:     json.RawMessage(`{"prebid":{"data":{"eidpermissions":[{"source":"adserver.org","bidders":["rubicon"]},{"source":"prebid.org","bidders":["appnexus"]}]}}}`),
+		},
+	}
+
+	for _, test := range testCases {
+		req := &openrtb_ext.RequestWrapper{BidRequest: &openrtb2.BidRequest{User: test.givenUser, Ext: test.givenExt}}
+
+		err := AddEID(req, "prebid.org", "fpid", test.givenBidders)
+		require.NoError(t, err, test.description)
+		require.NoError(t, req.RebuildRequest(), test.description)
+
+		assert.JSONEq(t, string(test.expectedUserExt), string(req.User.Ext), test.description+":user.ext")
+		if test.expectedExt == nil {
+			assert.Equal(t, test.givenExt, req.Ext, test.description+":ext")
+		} else {
+			assert.JSONEq(t, string(test.expectedExt), string(req.Ext), test.description+":ext")
+		}
+	}
+}
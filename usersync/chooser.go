@@ -85,6 +85,9 @@ const (
 
 	// StatusDuplicate specifies the bidder is a duplicate or shared a syncer key with another bidder choice.
 	StatusDuplicate
+
+	// StatusBlockedByPrivacy specifies the activity controls of the account deny the bidder the syncUser activity.
+	StatusBlockedByPrivacy
 )
 
 // Privacy determines which privacy policies will be enforced for a user sync request.
@@ -92,6 +95,7 @@ type Privacy interface {
 	GDPRAllowsHostCookie() bool
 	GDPRAllowsBidderSync(bidder string) bool
 	CCPAAllowsBidderSync(bidder string) bool
+	ActivityAllowsUserSync(bidder string) bool
 }
 
 // standardChooser implements the user syncer algorithm per official Prebid specification.
@@ -139,25 +143,29 @@ func (c standardChooser) evaluate(bidder string, syncersSeen map[string]struct{}
 
 	_, seen := syncersSeen[syncer.Key()]
 	if seen {
-		return nil, BidderEvaluation{Bidder: bidder, Status: StatusDuplicate}
+		return nil, BidderEvaluation{Bidder: bidder, SyncerKey: syncer.Key(), Status: StatusDuplicate}
 	}
 	syncersSeen[syncer.Key()] = struct{}{}
 
 	if !syncer.SupportsType(syncTypeFilter.ForBidder(bidder)) {
-		return nil, BidderEvaluation{Bidder: bidder, Status: StatusTypeNotSupported}
+		return nil, BidderEvaluation{Bidder: bidder, SyncerKey: syncer.Key(), Status: StatusTypeNotSupported}
 	}
 
 	if cookie.HasLiveSync(syncer.Key()) {
-		return nil, BidderEvaluation{Bidder: bidder, Status: StatusAlreadySynced}
+		return nil, BidderEvaluation{Bidder: bidder, SyncerKey: syncer.Key(), Status: StatusAlreadySynced}
 	}
 
 	if !privacy.GDPRAllowsBidderSync(bidder) {
-		return nil, BidderEvaluation{Bidder: bidder, Status: StatusBlockedByGDPR}
+		return nil, BidderEvaluation{Bidder: bidder, SyncerKey: syncer.Key(), Status: StatusBlockedByGDPR}
 	}
 
 	if !privacy.CCPAAllowsBidderSync(bidder) {
-		return nil, BidderEvaluation{Bidder: bidder, Status: StatusBlockedByCCPA}
+		return nil, BidderEvaluation{Bidder: bidder, SyncerKey: syncer.Key(), Status: StatusBlockedByCCPA}
+	}
+
+	if !privacy.ActivityAllowsUserSync(bidder) {
+		return nil, BidderEvaluation{Bidder: bidder, SyncerKey: syncer.Key(), Status: StatusBlockedByPrivacy}
 	}
 
-	return syncer, BidderEvaluation{Bidder: bidder, Status: StatusOK}
+	return syncer, BidderEvaluation{Bidder: bidder, SyncerKey: syncer.Key(), Status: StatusOK}
 }
@@ -66,7 +66,7 @@ func TestChooserChoose(t *testing.T) {
 		{
 			description: "Cookie Opt Out",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   0,
 			},
 			givenChosenBidders: []string{"a"},
@@ -80,7 +80,7 @@ func TestChooserChoose(t *testing.T) {
 		{
 			description: "GDPR Host Cookie Not Allowed",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: false, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: false, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   0,
 			},
 			givenChosenBidders: []string{"a"},
@@ -94,7 +94,7 @@ func TestChooserChoose(t *testing.T) {
 		{
 			description: "No Bidders",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   0,
 			},
 			givenChosenBidders: []string{},
@@ -108,98 +108,98 @@ func TestChooserChoose(t *testing.T) {
 		{
 			description: "One Bidder - Sync",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   0,
 			},
 			givenChosenBidders: []string{"a"},
 			givenCookie:        Cookie{},
 			expected: Result{
 				Status:           StatusOK,
-				BiddersEvaluated: []BidderEvaluation{{Bidder: "a", Status: StatusOK}},
+				BiddersEvaluated: []BidderEvaluation{{Bidder: "a", SyncerKey: "keyA", Status: StatusOK}},
 				SyncersChosen:    []SyncerChoice{syncerChoiceA},
 			},
 		},
 		{
 			description: "One Bidder - No Sync",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   0,
 			},
 			givenChosenBidders: []string{"c"},
 			givenCookie:        Cookie{},
 			expected: Result{
 				Status:           StatusOK,
-				BiddersEvaluated: []BidderEvaluation{{Bidder: "c", Status: StatusTypeNotSupported}},
+				BiddersEvaluated: []BidderEvaluation{{Bidder: "c", SyncerKey: "keyC", Status: StatusTypeNotSupported}},
 				SyncersChosen:    []SyncerChoice{},
 			},
 		},
 		{
 			description: "Many Bidders - All Sync - Limit Disabled With 0",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   0,
 			},
 			givenChosenBidders: []string{"a", "b"},
 			givenCookie:        Cookie{},
 			expected: Result{
 				Status:           StatusOK,
-				BiddersEvaluated: []BidderEvaluation{{Bidder: "a", Status: StatusOK}, {Bidder: "b", Status: StatusOK}},
+				BiddersEvaluated: []BidderEvaluation{{Bidder: "a", SyncerKey: "keyA", Status: StatusOK}, {Bidder: "b", SyncerKey: "keyB", Status: StatusOK}},
 				SyncersChosen:    []SyncerChoice{syncerChoiceA, syncerChoiceB},
 			},
 		},
 		{
 			description: "Many Bidders - All Sync - Limit Disabled With Negative Value",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   -1,
 			},
 			givenChosenBidders: []string{"a", "b"},
 			givenCookie:        Cookie{},
 			expected: Result{
 				Status:           StatusOK,
-				BiddersEvaluated: []BidderEvaluation{{Bidder: "a", Status: StatusOK}, {Bidder: "b", Status: StatusOK}},
+				BiddersEvaluated: []BidderEvaluation{{Bidder: "a", SyncerKey: "keyA", Status: StatusOK}, {Bidder: "b", SyncerKey: "keyB", Status: StatusOK}},
 				SyncersChosen:    []SyncerChoice{syncerChoiceA, syncerChoiceB},
 			},
 		},
 		{
 			description: "Many Bidders - Limited Sync",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   1,
 			},
 			givenChosenBidders: []string{"a", "b"},
 			givenCookie:        Cookie{},
 			expected: Result{
 				Status:           StatusOK,
-				BiddersEvaluated: []BidderEvaluation{{Bidder: "a", Status: StatusOK}},
+				BiddersEvaluated: []BidderEvaluation{{Bidder: "a", SyncerKey: "keyA", Status: StatusOK}},
 				SyncersChosen:    []SyncerChoice{syncerChoiceA},
 			},
 		},
 		{
 			description: "Many Bidders - Limited Sync - Disqualified Syncers Don't Count Towards Limit",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   1,
 			},
 			givenChosenBidders: []string{"c", "a", "b"},
 			givenCookie:        Cookie{},
 			expected: Result{
 				Status:           StatusOK,
-				BiddersEvaluated: []BidderEvaluation{{Bidder: "c", Status: StatusTypeNotSupported}, {Bidder: "a", Status: StatusOK}},
+				BiddersEvaluated: []BidderEvaluation{{Bidder: "c", SyncerKey: "keyC", Status: StatusTypeNotSupported}, {Bidder: "a", SyncerKey: "keyA", Status: StatusOK}},
 				SyncersChosen:    []SyncerChoice{syncerChoiceA},
 			},
 		},
 		{
 			description: "Many Bidders - Some Sync, Some Don't",
 			givenRequest: Request{
-				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+				Privacy: fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 				Limit:   0,
 			},
 			givenChosenBidders: []string{"a", "c"},
 			givenCookie:        Cookie{},
 			expected: Result{
 				Status:           StatusOK,
-				BiddersEvaluated: []BidderEvaluation{{Bidder: "a", Status: StatusOK}, {Bidder: "c", Status: StatusTypeNotSupported}},
+				BiddersEvaluated: []BidderEvaluation{{Bidder: "a", SyncerKey: "keyA", Status: StatusOK}, {Bidder: "c", SyncerKey: "keyC", Status: StatusTypeNotSupported}},
 				SyncersChosen:    []SyncerChoice{syncerChoiceA},
 			},
 		},
@@ -255,7 +255,7 @@ func TestChooserEvaluate(t *testing.T) {
 			description:      "Valid",
 			givenBidder:      "a",
 			givenSyncersSeen: map[string]struct{}{},
-			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 			givenCookie:      cookieNeedsSync,
 			expectedSyncer:   fakeSyncerA,
 			expectedBidder:   "a",
@@ -265,7 +265,7 @@ func TestChooserEvaluate(t *testing.T) {
 			description:      "Unknown Bidder",
 			givenBidder:      "unknown",
 			givenSyncersSeen: map[string]struct{}{},
-			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 			givenCookie:      cookieNeedsSync,
 			expectedSyncer:   nil,
 			expectedBidder:   "unknown",
@@ -275,7 +275,7 @@ func TestChooserEvaluate(t *testing.T) {
 			description:      "Duplicate Syncer",
 			givenBidder:      "a",
 			givenSyncersSeen: map[string]struct{}{"keyA": {}},
-			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 			givenCookie:      cookieNeedsSync,
 			expectedSyncer:   nil,
 			expectedBidder:   "a",
@@ -285,7 +285,7 @@ func TestChooserEvaluate(t *testing.T) {
 			description:      "Incompatible Kind",
 			givenBidder:      "b",
 			givenSyncersSeen: map[string]struct{}{},
-			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 			givenCookie:      cookieNeedsSync,
 			expectedSyncer:   nil,
 			expectedBidder:   "b",
@@ -295,7 +295,7 @@ func TestChooserEvaluate(t *testing.T) {
 			description:      "Already Synced",
 			givenBidder:      "a",
 			givenSyncersSeen: map[string]struct{}{},
-			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 			givenCookie:      cookieAlreadyHasSyncForA,
 			expectedSyncer:   nil,
 			expectedBidder:   "a",
@@ -305,7 +305,7 @@ func TestChooserEvaluate(t *testing.T) {
 			description:      "Different Bidder Already Synced",
 			givenBidder:      "a",
 			givenSyncersSeen: map[string]struct{}{},
-			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 			givenCookie:      cookieAlreadyHasSyncForB,
 			expectedSyncer:   fakeSyncerA,
 			expectedBidder:   "a",
@@ -315,7 +315,7 @@ func TestChooserEvaluate(t *testing.T) {
 			description:      "Blocked By GDPR",
 			givenBidder:      "a",
 			givenSyncersSeen: map[string]struct{}{},
-			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: false, ccpaAllowsBidderSync: true},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: false, ccpaAllowsBidderSync: true, activityAllowsUserSync: true},
 			givenCookie:      cookieNeedsSync,
 			expectedSyncer:   nil,
 			expectedBidder:   "a",
@@ -325,12 +325,22 @@ func TestChooserEvaluate(t *testing.T) {
 			description:      "Blocked By CCPA",
 			givenBidder:      "a",
 			givenSyncersSeen: map[string]struct{}{},
-			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: false},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: false, activityAllowsUserSync: true},
 			givenCookie:      cookieNeedsSync,
 			expectedSyncer:   nil,
 			expectedBidder:   "a",
 			expectedStatus:   StatusBlockedByCCPA,
 		},
+		{
+			description:      "Blocked By Activity Controls",
+			givenBidder:      "a",
+			givenSyncersSeen: map[string]struct{}{},
+			givenPrivacy:     fakePrivacy{gdprAllowsHostCookie: true, gdprAllowsBidderSync: true, ccpaAllowsBidderSync: true, activityAllowsUserSync: false},
+			givenCookie:      cookieNeedsSync,
+			expectedSyncer:   nil,
+			expectedBidder:   "a",
+			expectedStatus:   StatusBlockedByPrivacy,
+		},
 	}
 
 	for _, test := range testCases {
@@ -340,6 +350,9 @@ func TestChooserEvaluate(t *testing.T) {
 		assert.Equal(t, test.expectedSyncer, sync, test.description+":syncer")
 
 		expectedEvaluation := BidderEvaluation{Bidder: test.expectedBidder, Status: test.expectedStatus}
+		if syncer, exists := bidderSyncerLookup[test.givenBidder]; exists {
+			expectedEvaluation.SyncerKey = syncer.Key()
+		}
 		assert.Equal(t, expectedEvaluation, evaluation, test.description+":evaluation")
 	}
 }
@@ -383,10 +396,15 @@ func (fakeSyncer) GetSync(syncTypes []SyncType, privacyPolicies privacy.Policies
 	return Sync{}, nil
 }
 
+func (fakeSyncer) GetChainRedirect(uid string, privacyPolicies privacy.Policies) (string, error) {
+	return "", nil
+}
+
 type fakePrivacy struct {
-	gdprAllowsHostCookie bool
-	gdprAllowsBidderSync bool
-	ccpaAllowsBidderSync bool
+	gdprAllowsHostCookie   bool
+	gdprAllowsBidderSync   bool
+	ccpaAllowsBidderSync   bool
+	activityAllowsUserSync bool
 }
 
 func (p fakePrivacy) GDPRAllowsHostCookie() bool {
@@ -400,3 +418,7 @@ func (p fakePrivacy) GDPRAllowsBidderSync(bidder string) bool {
 func (p fakePrivacy) CCPAAllowsBidderSync(bidder string) bool {
 	return p.ccpaAllowsBidderSync
 }
+
+func (p fakePrivacy) ActivityAllowsUserSync(bidder string) bool {
+	return p.activityAllowsUserSync
+}
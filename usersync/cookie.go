@@ -1,13 +1,14 @@
 package usersync
 
 import (
-	"encoding/base64"
 	"encoding/json"
 	"errors"
-	"math"
 	"net/http"
+	"sort"
+	"strconv"
 	"time"
 
+	"github.com/golang/glog"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
@@ -50,10 +51,19 @@ func ParseCookieFromRequest(r *http.Request, cookie *config.HostCookie) *Cookie
 	var parsed *Cookie
 	uidCookie, err2 := r.Cookie(uidCookieName)
 	if err2 == nil {
-		parsed = ParseCookie(uidCookie)
+		parsed = parseCookie(uidCookie, &cookie.Signing)
 	} else {
 		parsed = NewCookie()
 	}
+	for i := 1; i < cookie.MaxCookies && parsed.AllowSyncs(); i++ {
+		if shardCookie, err := r.Cookie(shardCookieName(i)); err == nil {
+			for key, uid := range parseCookie(shardCookie, &cookie.Signing).uids {
+				if _, ok := parsed.uids[key]; !ok {
+					parsed.uids[key] = uid
+				}
+			}
+		}
+	}
 	// Fixes #582
 	if uid, _, _ := parsed.GetUID(cookie.Family); uid == "" && cookie.CookieName != "" {
 		if hostCookie, err := r.Cookie(cookie.CookieName); err == nil {
@@ -65,7 +75,13 @@ func ParseCookieFromRequest(r *http.Request, cookie *config.HostCookie) *Cookie
 
 // ParseCookie parses the UserSync cookie from a raw HTTP cookie.
 func ParseCookie(httpCookie *http.Cookie) *Cookie {
-	jsonValue, err := base64.URLEncoding.DecodeString(httpCookie.Value)
+	return parseCookie(httpCookie, &config.HostCookieSigning{})
+}
+
+// parseCookie parses the UserSync cookie from a raw HTTP cookie, whose signature is verified with the signing config.
+// The tampered cookies are reset like the corrupted ones.
+func parseCookie(httpCookie *http.Cookie, signing *config.HostCookieSigning) *Cookie {
+	jsonValue, err := decodeCookieValue(httpCookie.Value, signing)
 	if err != nil {
 		// corrupted cookie; we should reset
 		return NewCookie()
@@ -104,15 +120,24 @@ func (cookie *Cookie) SetOptOut(optOut bool) {
 
 // Gets an HTTP cookie containing all the data from this UserSyncMap. This is a snapshot--not a live view.
 func (cookie *Cookie) ToHTTPCookie(ttl time.Duration) *http.Cookie {
+	httpCookie, _ := cookie.toHTTPCookie(ttl, &config.HostCookieSigning{})
+	return httpCookie
+}
+
+// toHTTPCookie is ToHTTPCookie with the value signed, and possibly encrypted, as set by the signing config
+func (cookie *Cookie) toHTTPCookie(ttl time.Duration, signing *config.HostCookieSigning) (*http.Cookie, error) {
 	j, _ := json.Marshal(cookie)
-	b64 := base64.URLEncoding.EncodeToString(j)
+	value, err := encodeCookieValue(j, signing)
+	if err != nil {
+		return nil, err
+	}
 
 	return &http.Cookie{
 		Name:    uidCookieName,
-		Value:   b64,
+		Value:   value,
 		Expires: time.Now().Add(ttl),
 		Path:    "/",
-	}
+	}, nil
 }
 
 // GetUID Gets this user's ID for the given syncer key.
@@ -142,39 +167,137 @@ func (cookie *Cookie) GetUIDs() map[string]string {
 	return uids
 }
 
-// SetCookieOnResponse is a shortcut for "ToHTTPCookie(); cookie.setDomain(domain); setCookie(w, cookie)"
+// SetCookieOnResponse writes the cookie onto the response, split across as many cookies as the host cookie config
+// allows once the uids exceed the max size of a single one. The unused cookies are expired so that the ones a
+// previous response wrote don't linger.
 func (cookie *Cookie) SetCookieOnResponse(w http.ResponseWriter, setSiteCookie bool, cfg *config.HostCookie, ttl time.Duration) {
-	httpCookie := cookie.ToHTTPCookie(ttl)
-	var domain string = cfg.Domain
+	httpCookies, err := cookie.toHTTPCookies(ttl, cfg)
+	if err != nil {
+		glog.Errorf("Failed to encode the uids cookie: %v", err)
+		return
+	}
+
+	for i := len(httpCookies); i < cfg.MaxCookies; i++ {
+		httpCookies = append(httpCookies, &http.Cookie{
+			Name:   shardCookieName(i),
+			Path:   "/",
+			Domain: cfg.Domain,
+			MaxAge: -1,
+		})
+	}
 
-	if domain != "" {
-		httpCookie.Domain = domain
+	for _, httpCookie := range httpCookies {
+		if setSiteCookie {
+			httpCookie.Secure = true
+			httpCookie.SameSite = http.SameSiteNoneMode
+		}
+		w.Header().Add("Set-Cookie", httpCookie.String())
+	}
+}
+
+// toHTTPCookies splits the uids across the cookies, which are filled in the order of the priority of the uids up to
+// the max cookie size. The first cookie carries the opt out and the birthday. The uids which don't fit, and the ones
+// beyond the max uids, are evicted along with all the uids of a lower priority.
+func (cookie *Cookie) toHTTPCookies(ttl time.Duration, cfg *config.HostCookie) ([]*http.Cookie, error) {
+	keys := cookie.prioritizedKeys(cfg.PrioritySyncers)
+	if cfg.MaxUIDs > 0 && len(keys) > cfg.MaxUIDs {
+		for _, key := range keys[cfg.MaxUIDs:] {
+			delete(cookie.uids, key)
+		}
+		keys = keys[:cfg.MaxUIDs]
+	}
+
+	shard := &Cookie{uids: make(map[string]uidWithExpiry), optOut: cookie.optOut, birthday: cookie.birthday}
+	encoded, err := shard.toShardHTTPCookie(0, ttl, cfg)
+	if err != nil {
+		return nil, err
 	}
+	var httpCookies []*http.Cookie
 
-	var currSize int = len([]byte(httpCookie.String()))
-	for cfg.MaxCookieSizeBytes > 0 && currSize > cfg.MaxCookieSizeBytes && len(cookie.uids) > 0 {
-		var oldestElem string = ""
-		var oldestDate int64 = math.MaxInt64
-		for key, value := range cookie.uids {
-			timeUntilExpiration := time.Until(value.Expires)
-			if timeUntilExpiration < time.Duration(oldestDate) {
-				oldestElem = key
-				oldestDate = int64(timeUntilExpiration)
+	for i, key := range keys {
+		shard.uids[key] = cookie.uids[key]
+		candidate, err := shard.toShardHTTPCookie(len(httpCookies), ttl, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if fitsCookieSize(candidate, cfg) {
+			encoded = candidate
+			continue
+		}
+
+		delete(shard.uids, key)
+		if len(shard.uids) > 0 && len(httpCookies)+1 < cfg.MaxCookies {
+			httpCookies = append(httpCookies, encoded)
+			shard = &Cookie{uids: map[string]uidWithExpiry{key: cookie.uids[key]}}
+			if candidate, err = shard.toShardHTTPCookie(len(httpCookies), ttl, cfg); err != nil {
+				return nil, err
 			}
+			if fitsCookieSize(candidate, cfg) {
+				encoded = candidate
+				continue
+			}
+		}
+
+		for _, evicted := range keys[i:] {
+			delete(cookie.uids, evicted)
 		}
-		delete(cookie.uids, oldestElem)
-		httpCookie = cookie.ToHTTPCookie(ttl)
-		if domain != "" {
-			httpCookie.Domain = domain
+		break
+	}
+
+	return append(httpCookies, encoded), nil
+}
+
+// prioritizedKeys returns the syncer keys of the uids, those of the priority syncers first in their order, and then
+// the others from the latest to expire
+func (cookie *Cookie) prioritizedKeys(prioritySyncers []string) []string {
+	priorities := make(map[string]int, len(prioritySyncers))
+	for i, key := range prioritySyncers {
+		if _, ok := priorities[key]; !ok {
+			priorities[key] = i
 		}
-		currSize = len([]byte(httpCookie.String()))
 	}
 
-	if setSiteCookie {
-		httpCookie.Secure = true
-		httpCookie.SameSite = http.SameSiteNoneMode
+	keys := make([]string, 0, len(cookie.uids))
+	for key := range cookie.uids {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		iPriority, iOK := priorities[keys[i]]
+		jPriority, jOK := priorities[keys[j]]
+		if iOK || jOK {
+			return iOK && (!jOK || iPriority < jPriority)
+		}
+		if iExpires, jExpires := cookie.uids[keys[i]].Expires, cookie.uids[keys[j]].Expires; !iExpires.Equal(jExpires) {
+			return iExpires.After(jExpires)
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func (cookie *Cookie) toShardHTTPCookie(index int, ttl time.Duration, cfg *config.HostCookie) (*http.Cookie, error) {
+	httpCookie, err := cookie.toHTTPCookie(ttl, &cfg.Signing)
+	if err != nil {
+		return nil, err
+	}
+	httpCookie.Name = shardCookieName(index)
+	if cfg.Domain != "" {
+		httpCookie.Domain = cfg.Domain
+	}
+	return httpCookie, nil
+}
+
+func fitsCookieSize(httpCookie *http.Cookie, cfg *config.HostCookie) bool {
+	return cfg.MaxCookieSizeBytes <= 0 || len([]byte(httpCookie.String())) <= cfg.MaxCookieSizeBytes
+}
+
+// shardCookieName returns the name of the cookie of the index, which is uids for the first one and uids2, uids3 and
+// so on for the others
+func shardCookieName(index int) string {
+	if index == 0 {
+		return uidCookieName
 	}
-	w.Header().Add("Set-Cookie", httpCookie.String())
+	return uidCookieName + strconv.Itoa(index+1)
 }
 
 // Unsync removes the user's ID for the given syncer key from this cookie.
@@ -11,6 +11,7 @@ import (
 
 	"github.com/prebid/prebid-server/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestOptOutCookie(t *testing.T) {
@@ -242,6 +243,31 @@ func TestParseCookieFromRequestOptOut(t *testing.T) {
 	}
 }
 
+func TestSignedCookieReadWrite(t *testing.T) {
+	hostCookie := &config.HostCookie{Signing: config.HostCookieSigning{Enabled: true, Keys: []string{"key1"}, Encrypt: true}}
+
+	w := httptest.NewRecorder()
+	newSampleCookie().SetCookieOnResponse(w, false, hostCookie, 24*time.Hour)
+	httpCookies := (&http.Response{Header: w.Header()}).Cookies()
+	require.Len(t, httpCookies, 1)
+
+	req := httptest.NewRequest("POST", "http://www.prebid.com", nil)
+	req.AddCookie(httpCookies[0])
+	parsed := ParseCookieFromRequest(req, hostCookie)
+	uid, _, _ := parsed.GetUID("adnxs")
+	assert.Equal(t, "123", uid, "signed cookie")
+
+	tampered := []byte(httpCookies[0].Value)
+	tampered[5] ^= 1
+	tamperedReq := httptest.NewRequest("POST", "http://www.prebid.com", nil)
+	tamperedReq.AddCookie(&http.Cookie{Name: uidCookieName, Value: string(tampered)})
+	assert.Empty(t, ParseCookieFromRequest(tamperedReq, hostCookie).uids, "tampered cookie")
+
+	legacyReq := httptest.NewRequest("POST", "http://www.prebid.com", nil)
+	legacyReq.AddCookie(newSampleCookie().ToHTTPCookie(24 * time.Hour))
+	assert.Empty(t, ParseCookieFromRequest(legacyReq, hostCookie).uids, "legacy cookie")
+}
+
 func TestCookieReadWrite(t *testing.T) {
 	cookie := newSampleCookie()
 
@@ -376,6 +402,112 @@ func TestTrimCookiesClosestExpirationDates(t *testing.T) {
 	}
 }
 
+func TestShardedCookieReadWrite(t *testing.T) {
+	newCookieToSend := func() *Cookie {
+		return &Cookie{
+			uids: map[string]uidWithExpiry{
+				"k1": newTempId("12345678901234567890123456789012345678901234567890", 7),
+				"k2": newTempId("abcdefghijklmnopqrstuvwxyz", 6),
+				"k3": newTempId("ABCDEFGHIJKLMNOPQRSTUVWXYZ", 5),
+				"k4": newTempId("12345678901234567890123456789612345678901234567890", 4),
+				"k5": newTempId("aAbBcCdDeEfFgGhHiIjJkKlLmMnNoOpPqQrRsStTuUvVwWxXyYzZ", 3),
+			},
+			birthday: timestamp(),
+		}
+	}
+
+	testCases := []struct {
+		description     string
+		givenHostCookie config.HostCookie
+		expectedCookies []string
+		expectedExpired []string
+		expectedKeys    []string
+	}{
+		{
+			description:     "Single Cookie",
+			givenHostCookie: config.HostCookie{MaxCookieSizeBytes: 400, MaxCookies: 1},
+			expectedCookies: []string{"uids"},
+			expectedKeys:    []string{"k1", "k2"},
+		},
+		{
+			description:     "Split Across Cookies",
+			givenHostCookie: config.HostCookie{MaxCookieSizeBytes: 400, MaxCookies: 3},
+			expectedCookies: []string{"uids", "uids2", "uids3"},
+			expectedKeys:    []string{"k1", "k2", "k3", "k4", "k5"},
+		},
+		{
+			description:     "Split Across Cookies - Unused Cookies Expired",
+			givenHostCookie: config.HostCookie{MaxCookieSizeBytes: 2000, MaxCookies: 3},
+			expectedCookies: []string{"uids"},
+			expectedExpired: []string{"uids2", "uids3"},
+			expectedKeys:    []string{"k1", "k2", "k3", "k4", "k5"},
+		},
+		{
+			description:     "Split Across Cookies - Oldest Evicted",
+			givenHostCookie: config.HostCookie{MaxCookieSizeBytes: 400, MaxCookies: 2},
+			expectedCookies: []string{"uids", "uids2"},
+			expectedKeys:    []string{"k1", "k2", "k3", "k4"},
+		},
+		{
+			description:     "Max UIDs",
+			givenHostCookie: config.HostCookie{MaxCookies: 1, MaxUIDs: 3},
+			expectedCookies: []string{"uids"},
+			expectedKeys:    []string{"k1", "k2", "k3"},
+		},
+		{
+			description:     "Priority Syncers Evicted Last",
+			givenHostCookie: config.HostCookie{MaxCookies: 1, MaxUIDs: 3, PrioritySyncers: []string{"k5", "k4"}},
+			expectedCookies: []string{"uids"},
+			expectedKeys:    []string{"k5", "k4", "k1"},
+		},
+	}
+
+	for _, test := range testCases {
+		w := httptest.NewRecorder()
+		newCookieToSend().SetCookieOnResponse(w, false, &test.givenHostCookie, 90*24*time.Hour)
+
+		request := httptest.NewRequest("GET", "http://www.prebid.com", nil)
+		var cookieNames, expiredNames []string
+		for _, httpCookie := range (&http.Response{Header: w.Header()}).Cookies() {
+			if httpCookie.MaxAge < 0 {
+				expiredNames = append(expiredNames, httpCookie.Name)
+				continue
+			}
+			cookieNames = append(cookieNames, httpCookie.Name)
+			if test.givenHostCookie.MaxCookieSizeBytes > 0 {
+				assert.LessOrEqual(t, len(httpCookie.String()), test.givenHostCookie.MaxCookieSizeBytes, test.description+":size")
+			}
+			request.AddCookie(httpCookie)
+		}
+		assert.Equal(t, test.expectedCookies, cookieNames, test.description+":cookies")
+		assert.Equal(t, test.expectedExpired, expiredNames, test.description+":expired")
+
+		parsed := ParseCookieFromRequest(request, &test.givenHostCookie)
+		actualKeys := make([]string, 0, len(parsed.uids))
+		for key := range parsed.uids {
+			actualKeys = append(actualKeys, key)
+		}
+		assert.ElementsMatch(t, test.expectedKeys, actualKeys, test.description+":keys")
+	}
+}
+
+func TestParseShardedCookieOptOut(t *testing.T) {
+	hostCookie := &config.HostCookie{MaxCookies: 2}
+	optedOut := NewCookie()
+	optedOut.SetOptOut(true)
+	shard := &Cookie{uids: map[string]uidWithExpiry{"k1": newTempId("123", 10)}}
+
+	request := httptest.NewRequest("GET", "http://www.prebid.com", nil)
+	request.AddCookie(optedOut.ToHTTPCookie(time.Hour))
+	shardCookie := shard.ToHTTPCookie(time.Hour)
+	shardCookie.Name = "uids2"
+	request.AddCookie(shardCookie)
+
+	parsed := ParseCookieFromRequest(request, hostCookie)
+	assert.False(t, parsed.AllowSyncs(), "opt out")
+	assert.Empty(t, parsed.uids, "uids")
+}
+
 func ensureEmptyMap(t *testing.T, cookie *Cookie) {
 	if !cookie.AllowSyncs() {
 		t.Error("Empty cookies should allow user syncs.")
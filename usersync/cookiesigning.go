@@ -0,0 +1,129 @@
+package usersync
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/prebid/prebid-server/config"
+)
+
+// The signed cookie values are made of a prefix telling the format, the base64 payload and the base64 signature of
+// both, separated by dots. The dots are not part of the base64 alphabet of the legacy values, which tells them apart.
+const (
+	signedCookiePrefix    = "s1."
+	encryptedCookiePrefix = "e1."
+)
+
+var (
+	errCookieLegacyRejected   = errors.New("the unsigned cookies are rejected")
+	errCookieSignatureInvalid = errors.New("the signature of the cookie is invalid")
+	errCookieCiphertextShort  = errors.New("the ciphertext of the cookie is too short")
+)
+
+// encodeCookieValue serializes the payload of the cookie in the format of the signing config, which is the legacy
+// base64 format when the signing is disabled
+func encodeCookieValue(payload []byte, cfg *config.HostCookieSigning) (string, error) {
+	if !cfg.Enabled {
+		return base64.URLEncoding.EncodeToString(payload), nil
+	}
+
+	key := cfg.Keys[0]
+	prefix := signedCookiePrefix
+	if cfg.Encrypt {
+		var err error
+		if payload, err = encrypt(key, payload); err != nil {
+			return "", err
+		}
+		prefix = encryptedCookiePrefix
+	}
+
+	value := prefix + base64.RawURLEncoding.EncodeToString(payload)
+	return value + "." + base64.RawURLEncoding.EncodeToString(sign(key, value)), nil
+}
+
+// decodeCookieValue returns the payload of the cookie value. The signed values are verified against each key of the
+// signing config, even when the signing is disabled so that turning it off keeps the cookies written before.
+func decodeCookieValue(value string, cfg *config.HostCookieSigning) ([]byte, error) {
+	prefix := signedCookiePrefix
+	encrypted := strings.HasPrefix(value, encryptedCookiePrefix)
+	if encrypted {
+		prefix = encryptedCookiePrefix
+	} else if !strings.HasPrefix(value, signedCookiePrefix) {
+		if cfg.Enabled && !cfg.AcceptLegacy {
+			return nil, errCookieLegacyRejected
+		}
+		return base64.URLEncoding.DecodeString(value)
+	}
+
+	separator := strings.LastIndexByte(value, '.')
+	if separator < len(prefix) {
+		return nil, errCookieSignatureInvalid
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(value[separator+1:])
+	if err != nil {
+		return nil, errCookieSignatureInvalid
+	}
+
+	for _, key := range cfg.Keys {
+		if !hmac.Equal(signature, sign(key, value[:separator])) {
+			continue
+		}
+		payload, err := base64.RawURLEncoding.DecodeString(value[len(prefix):separator])
+		if err != nil {
+			return nil, err
+		}
+		if encrypted {
+			return decrypt(key, payload)
+		}
+		return payload, nil
+	}
+	return nil, errCookieSignatureInvalid
+}
+
+func sign(key string, value string) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// encrypt seals the plaintext with AES-256-GCM under the SHA-256 of the key, prefixed with its random nonce
+func encrypt(key string, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func decrypt(key string, ciphertext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errCookieCiphertextShort
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, nil)
+}
+
+func newAEAD(key string) (cipher.AEAD, error) {
+	aesKey := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(aesKey[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
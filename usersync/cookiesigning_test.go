@@ -0,0 +1,141 @@
+package usersync
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCookieValueRoundTrip(t *testing.T) {
+	payload := []byte(`{"tempUIDs":{"adnxs":{"uid":"123"}}}`)
+
+	testCases := []struct {
+		description    string
+		givenSigning   config.HostCookieSigning
+		expectedPrefix string
+	}{
+		{
+			description:  "Legacy",
+			givenSigning: config.HostCookieSigning{Keys: []string{"key1"}},
+		},
+		{
+			description:    "Signed",
+			givenSigning:   config.HostCookieSigning{Enabled: true, Keys: []string{"key1"}},
+			expectedPrefix: "s1.",
+		},
+		{
+			description:    "Encrypted",
+			givenSigning:   config.HostCookieSigning{Enabled: true, Keys: []string{"key1"}, Encrypt: true},
+			expectedPrefix: "e1.",
+		},
+	}
+
+	for _, test := range testCases {
+		value, err := encodeCookieValue(payload, &test.givenSigning)
+		require.NoError(t, err, test.description+":encode")
+
+		if test.expectedPrefix == "" {
+			assert.Equal(t, base64.URLEncoding.EncodeToString(payload), value, test.description+":value")
+		} else {
+			assert.True(t, strings.HasPrefix(value, test.expectedPrefix), test.description+":prefix")
+		}
+		if test.givenSigning.Encrypt {
+			assert.NotContains(t, value, base64.RawURLEncoding.EncodeToString(payload), test.description+":plaintext")
+		}
+
+		decoded, err := decodeCookieValue(value, &test.givenSigning)
+		assert.NoError(t, err, test.description+":decode")
+		assert.Equal(t, payload, decoded, test.description+":payload")
+	}
+}
+
+func TestDecodeCookieValue(t *testing.T) {
+	payload := []byte(`{"tempUIDs":{"adnxs":{"uid":"123"}}}`)
+	signedKey1, _ := encodeCookieValue(payload, &config.HostCookieSigning{Enabled: true, Keys: []string{"key1"}})
+	encryptedKey1, _ := encodeCookieValue(payload, &config.HostCookieSigning{Enabled: true, Keys: []string{"key1"}, Encrypt: true})
+	legacy := base64.URLEncoding.EncodeToString(payload)
+
+	testCases := []struct {
+		description   string
+		givenValue    string
+		givenSigning  config.HostCookieSigning
+		expectedError error
+	}{
+		{
+			description:  "Signed - Rotated Key",
+			givenValue:   signedKey1,
+			givenSigning: config.HostCookieSigning{Enabled: true, Keys: []string{"key2", "key1"}},
+		},
+		{
+			description:  "Encrypted - Rotated Key",
+			givenValue:   encryptedKey1,
+			givenSigning: config.HostCookieSigning{Enabled: true, Keys: []string{"key2", "key1"}, Encrypt: true},
+		},
+		{
+			description:  "Signed - Signing Disabled",
+			givenValue:   signedKey1,
+			givenSigning: config.HostCookieSigning{Keys: []string{"key1"}},
+		},
+		{
+			description:   "Signed - Retired Key",
+			givenValue:    signedKey1,
+			givenSigning:  config.HostCookieSigning{Enabled: true, Keys: []string{"key2"}},
+			expectedError: errCookieSignatureInvalid,
+		},
+		{
+			description:   "Signed - Tampered Payload",
+			givenValue:    "s1." + base64.RawURLEncoding.EncodeToString([]byte(`{"tempUIDs":{"adnxs":{"uid":"456"}}}`)) + signedKey1[strings.LastIndexByte(signedKey1, '.'):],
+			givenSigning:  config.HostCookieSigning{Enabled: true, Keys: []string{"key1"}},
+			expectedError: errCookieSignatureInvalid,
+		},
+		{
+			description:   "Signed - Malformed Signature",
+			givenValue:    signedKey1 + "!",
+			givenSigning:  config.HostCookieSigning{Enabled: true, Keys: []string{"key1"}},
+			expectedError: errCookieSignatureInvalid,
+		},
+		{
+			description:   "Signed - Missing Signature",
+			givenValue:    "s1.",
+			givenSigning:  config.HostCookieSigning{Enabled: true, Keys: []string{"key1"}},
+			expectedError: errCookieSignatureInvalid,
+		},
+		{
+			description:   "Encrypted - Format Swapped",
+			givenValue:    "e1." + signedKey1[len("s1."):],
+			givenSigning:  config.HostCookieSigning{Enabled: true, Keys: []string{"key1"}},
+			expectedError: errCookieSignatureInvalid,
+		},
+		{
+			description:  "Legacy - Signing Disabled",
+			givenValue:   legacy,
+			givenSigning: config.HostCookieSigning{},
+		},
+		{
+			description:  "Legacy - Accepted",
+			givenValue:   legacy,
+			givenSigning: config.HostCookieSigning{Enabled: true, Keys: []string{"key1"}, AcceptLegacy: true},
+		},
+		{
+			description:   "Legacy - Rejected",
+			givenValue:    legacy,
+			givenSigning:  config.HostCookieSigning{Enabled: true, Keys: []string{"key1"}},
+			expectedError: errCookieLegacyRejected,
+		},
+	}
+
+	for _, test := range testCases {
+		decoded, err := decodeCookieValue(test.givenValue, &test.givenSigning)
+
+		if test.expectedError != nil {
+			assert.Equal(t, test.expectedError, err, test.description+":err")
+			continue
+		}
+		assert.NoError(t, err, test.description+":err")
+		assert.Equal(t, payload, decoded, test.description+":payload")
+	}
+}
@@ -36,6 +36,10 @@ type Syncer interface {
 	// GetSync returns a user sync for the user's device to perform, or an error if the none of the
 	// sync types are supported or if macro substitution fails.
 	GetSync(syncTypes []SyncType, privacyPolicies privacy.Policies) (Sync, error)
+
+	// GetChainRedirect returns the url the /setuid endpoint redirects the user's device to once the
+	// uid is stored, or an empty string if the syncer doesn't chain a redirect.
+	GetChainRedirect(uid string, privacyPolicies privacy.Policies) (string, error)
 }
 
 // Sync represents a user sync to be performed by the user's device.
@@ -50,6 +54,7 @@ type standardSyncer struct {
 	defaultSyncType SyncType
 	iframe          *template.Template
 	redirect        *template.Template
+	chainRedirect   *template.Template
 	supportCORS     bool
 }
 
@@ -106,6 +111,18 @@ func NewSyncer(hostConfig config.UserSync, syncerConfig config.Syncer) (Syncer,
 		}
 	}
 
+	if syncerConfig.ChainRedirectURL != "" {
+		var err error
+		templateName := strings.ToLower(syncerConfig.Key) + "_chain_redirect_url"
+		syncer.chainRedirect, err = template.New(templateName).Parse(syncerConfig.ChainRedirectURL)
+		if err != nil {
+			return nil, fmt.Errorf("chain redirect %v", err)
+		}
+		if err := validateChainRedirectTemplate(syncer.chainRedirect); err != nil {
+			return nil, fmt.Errorf("chain redirect %v", err)
+		}
+	}
+
 	return syncer, nil
 }
 
@@ -204,6 +221,26 @@ func validateTemplate(template *template.Template) error {
 	return nil
 }
 
+var chainRedirectTemplateTestValues = macros.SetUIDRedirectTemplateParams{
+	UID:         "anyUID",
+	GDPR:        "anyGDPR",
+	GDPRConsent: "anyGDPRConsent",
+	USPrivacy:   "anyCCPAConsent",
+}
+
+func validateChainRedirectTemplate(template *template.Template) error {
+	url, err := macros.ResolveMacros(template, chainRedirectTemplateTestValues)
+	if err != nil {
+		return err
+	}
+
+	if !validator.IsURL(url) || !validator.IsRequestURL(url) {
+		return fmt.Errorf(`composed url: "%s" is invalid`, url)
+	}
+
+	return nil
+}
+
 func (s standardSyncer) Key() string {
 	return s.key
 }
@@ -259,6 +296,19 @@ func (s standardSyncer) GetSync(syncTypes []SyncType, privacyPolicies privacy.Po
 	return sync, nil
 }
 
+func (s standardSyncer) GetChainRedirect(uid string, privacyPolicies privacy.Policies) (string, error) {
+	if s.chainRedirect == nil {
+		return "", nil
+	}
+
+	return macros.ResolveMacros(s.chainRedirect, macros.SetUIDRedirectTemplateParams{
+		UID:         url.QueryEscape(uid),
+		GDPR:        url.QueryEscape(privacyPolicies.GDPR.Signal),
+		GDPRConsent: url.QueryEscape(privacyPolicies.GDPR.Consent),
+		USPrivacy:   url.QueryEscape(privacyPolicies.CCPA.Consent),
+	})
+}
+
 func (s standardSyncer) chooseSyncType(syncTypes []SyncType) (SyncType, error) {
 	if len(syncTypes) == 0 {
 		return SyncTypeUnknown, errNoSyncTypesProvided
@@ -146,6 +146,71 @@ func TestNewSyncer(t *testing.T) {
 	}
 }
 
+func TestNewSyncerChainRedirect(t *testing.T) {
+	var (
+		hostConfig     = config.UserSync{ExternalURL: "http://host.com", RedirectURL: "{{.ExternalURL}}/host"}
+		redirectConfig = &config.SyncerEndpoint{URL: "https://bidder.com/redirect?redirect={{.RedirectURL}}"}
+		macroValues    = macros.SetUIDRedirectTemplateParams{UID: "A", GDPR: "B", GDPRConsent: "C", USPrivacy: "D"}
+	)
+
+	testCases := []struct {
+		description           string
+		givenChainRedirectURL string
+		expectedError         string
+		expectedChainRedirect string
+	}{
+		{
+			description: "None",
+		},
+		{
+			description:           "Valid",
+			givenChainRedirectURL: "https://bidder.com/chain?uid={{.UID}}&gdpr={{.GDPR}}&gdpr_consent={{.GDPRConsent}}&us_privacy={{.USPrivacy}}",
+			expectedChainRedirect: "https://bidder.com/chain?uid=A&gdpr=B&gdpr_consent=C&us_privacy=D",
+		},
+		{
+			description:           "Parse Error",
+			givenChainRedirectURL: "{{malformed}}",
+			expectedError:         "chain redirect template: a_chain_redirect_url:1: function \"malformed\" not defined",
+		},
+		{
+			description:           "Macro Error",
+			givenChainRedirectURL: "https://bidder.com/chain?uid={{.RedirectURL}}",
+			expectedError:         "chain redirect template: a_chain_redirect_url:1:31: executing \"a_chain_redirect_url\" at <.RedirectURL>: can't evaluate field RedirectURL in type macros.SetUIDRedirectTemplateParams",
+		},
+		{
+			description:           "Validation Error",
+			givenChainRedirectURL: "notAURL:{{.UID}}",
+			expectedError:         "chain redirect composed url: \"notAURL:anyUID\" is invalid",
+		},
+	}
+
+	for _, test := range testCases {
+		syncerConfig := config.Syncer{
+			Key:              "a",
+			Redirect:         redirectConfig,
+			ChainRedirectURL: test.givenChainRedirectURL,
+		}
+
+		result, err := NewSyncer(hostConfig, syncerConfig)
+
+		if test.expectedError != "" {
+			assert.EqualError(t, err, test.expectedError, test.description+":err")
+			continue
+		}
+		if assert.NoError(t, err, test.description+":err") {
+			result := result.(standardSyncer)
+			if test.expectedChainRedirect == "" {
+				assert.Nil(t, result.chainRedirect, test.description+":chain_redirect")
+			} else {
+				chainRedirectRendered, err := macros.ResolveMacros(result.chainRedirect, macroValues)
+				if assert.NoError(t, err, test.description+":chain_redirect_render") {
+					assert.Equal(t, test.expectedChainRedirect, chainRedirectRendered, test.description+":chain_redirect")
+				}
+			}
+		}
+	}
+}
+
 func TestResolveDefaultSyncType(t *testing.T) {
 	anyEndpoint := &config.SyncerEndpoint{}
 
@@ -655,6 +720,43 @@ func TestSyncerGetSync(t *testing.T) {
 	}
 }
 
+func TestSyncerGetChainRedirect(t *testing.T) {
+	chainRedirectTemplate := template.Must(template.New("test").Parse("https://bidder.com/chain?uid={{.UID}}&gdpr={{.GDPR}}&gdpr_consent={{.GDPRConsent}}&us_privacy={{.USPrivacy}}"))
+	privacyPolicies := privacy.Policies{GDPR: gdpr.Policy{Signal: "1", Consent: "B"}, CCPA: ccpa.Policy{Consent: "1YNN"}}
+
+	testCases := []struct {
+		description      string
+		givenSyncer      standardSyncer
+		givenUID         string
+		expectedRedirect string
+	}{
+		{
+			description: "None",
+			givenSyncer: standardSyncer{},
+			givenUID:    "123",
+		},
+		{
+			description:      "UID",
+			givenSyncer:      standardSyncer{chainRedirect: chainRedirectTemplate},
+			givenUID:         "123",
+			expectedRedirect: "https://bidder.com/chain?uid=123&gdpr=1&gdpr_consent=B&us_privacy=1YNN",
+		},
+		{
+			description:      "UID Escaped",
+			givenSyncer:      standardSyncer{chainRedirect: chainRedirectTemplate},
+			givenUID:         "a&b=c d",
+			expectedRedirect: "https://bidder.com/chain?uid=a%26b%3Dc+d&gdpr=1&gdpr_consent=B&us_privacy=1YNN",
+		},
+	}
+
+	for _, test := range testCases {
+		result, err := test.givenSyncer.GetChainRedirect(test.givenUID, privacyPolicies)
+
+		assert.NoError(t, err, test.description+":err")
+		assert.Equal(t, test.expectedRedirect, result, test.description+":redirect")
+	}
+}
+
 func TestSyncerChooseSyncType(t *testing.T) {
 	endpointTemplate := template.Must(template.New("test").Parse("iframe"))
 
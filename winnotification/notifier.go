@@ -0,0 +1,151 @@
+package winnotification
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coocood/freecache"
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/config"
+)
+
+// Notifier keeps the win notification urls of the bids of some bidders, and fires them server side when the win
+// events of the bids are received. A nil Notifier keeps and fires nothing, so that callers don't need to check if
+// the win notifications are enabled.
+type Notifier struct {
+	urls       *freecache.Cache
+	bidders    map[string]struct{}
+	client     *http.Client
+	ttlSeconds int
+	timeout    time.Duration
+	maxRetries int
+	retryDelay time.Duration
+}
+
+// NewNotifier returns the notifier of the config, which is nil when the win notifications are disabled
+func NewNotifier(cfg config.WinNotifications, client *http.Client) *Notifier {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	bidders := make(map[string]struct{}, len(cfg.Bidders))
+	for _, bidder := range cfg.Bidders {
+		bidders[bidder] = struct{}{}
+	}
+	return &Notifier{
+		urls:       freecache.NewCache(cfg.SizeBytes),
+		bidders:    bidders,
+		client:     client,
+		ttlSeconds: cfg.TTLSeconds,
+		timeout:    time.Duration(cfg.TimeoutMs) * time.Millisecond,
+		maxRetries: cfg.MaxRetries,
+		retryDelay: time.Duration(cfg.RetryDelayMs) * time.Millisecond,
+	}
+}
+
+// Fires tells if the win notifications of the bids of the bidder are fired server side
+func (n *Notifier) Fires(bidder string) bool {
+	if n == nil {
+		return false
+	}
+	_, ok := n.bidders[bidder]
+	return ok
+}
+
+// Save keeps the win notification url of a bid until its win event, or until it expires
+func (n *Notifier) Save(accountID string, bidder string, bidID string, url string) {
+	if n == nil {
+		return
+	}
+	if err := n.urls.Set(key(accountID, bidder, bidID), []byte(url), n.ttlSeconds); err != nil {
+		glog.Warningf("[win_notifications] Cannot keep the win notification of the bid %s of %s: %v", bidID, bidder, err)
+	}
+}
+
+// Notify fires the win notification url of a bid in the background, and tells if the bid had one. The url is fired
+// once, however many win events of the bid are received.
+func (n *Notifier) Notify(accountID string, bidder string, bidID string) bool {
+	if n == nil {
+		return false
+	}
+
+	k := key(accountID, bidder, bidID)
+	url, err := n.urls.Get(k)
+	if err != nil || !n.urls.Del(k) {
+		return false
+	}
+	go n.fire(string(url))
+	return true
+}
+
+func (n *Notifier) fire(url string) {
+	var err error
+	for attempt := 0; attempt <= n.maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(n.retryDelay)
+		}
+		var retry bool
+		if retry, err = n.get(url); !retry {
+			break
+		}
+	}
+	if err != nil {
+		glog.Warningf("[win_notifications] Failed to fire the win notification %s: %v", url, err)
+	}
+}
+
+// get fires the url once, and tells if it is worth firing it again when it fails
+func (n *Notifier) get(url string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), n.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return true, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return false, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return false, nil
+}
+
+func key(accountID string, bidder string, bidID string) []byte {
+	return []byte(accountID + "\x00" + bidder + "\x00" + bidID)
+}
+
+// Macros are the values of the OpenRTB substitution macros of the win notification urls
+type Macros struct {
+	AuctionID string
+	BidID     string
+	ImpID     string
+	SeatID    string
+	AdID      string
+	Price     float64
+	Currency  string
+}
+
+// Expand substitutes the macros of the url, as the bidders would expect the clients to
+func (m Macros) Expand(url string) string {
+	return strings.NewReplacer(
+		"${AUCTION_ID}", m.AuctionID,
+		"${AUCTION_BID_ID}", m.BidID,
+		"${AUCTION_IMP_ID}", m.ImpID,
+		"${AUCTION_SEAT_ID}", m.SeatID,
+		"${AUCTION_AD_ID}", m.AdID,
+		"${AUCTION_PRICE}", strconv.FormatFloat(m.Price, 'f', -1, 64),
+		"${AUCTION_CURRENCY}", m.Currency,
+	).Replace(url)
+}
@@ -0,0 +1,95 @@
+package winnotification
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestNotifier(maxRetries int) *Notifier {
+	return NewNotifier(config.WinNotifications{
+		Enabled:    true,
+		Bidders:    []string{"appnexus"},
+		TTLSeconds: 60,
+		SizeBytes:  512 * 1024,
+		TimeoutMs:  1000,
+		MaxRetries: maxRetries,
+	}, http.DefaultClient)
+}
+
+// newTestServer answers the given statuses in turn, and counts the requests it gets
+func newTestServer(statuses ...int) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := atomic.AddInt32(&requests, 1) - 1
+		if int(i) < len(statuses) {
+			w.WriteHeader(statuses[i])
+		}
+	}))
+	return server, &requests
+}
+
+func TestNilNotifier(t *testing.T) {
+	notifier := NewNotifier(config.WinNotifications{Bidders: []string{"appnexus"}}, http.DefaultClient)
+
+	notifier.Save("acct", "appnexus", "bid", "http://bidder.com/win")
+
+	assert.Nil(t, notifier)
+	assert.False(t, notifier.Fires("appnexus"))
+	assert.False(t, notifier.Notify("acct", "appnexus", "bid"))
+}
+
+func TestFires(t *testing.T) {
+	notifier := newTestNotifier(0)
+
+	assert.True(t, notifier.Fires("appnexus"))
+	assert.False(t, notifier.Fires("rubicon"))
+}
+
+func TestNotify(t *testing.T) {
+	testCases := []struct {
+		description      string
+		givenMaxRetries  int
+		givenStatuses    []int
+		expectedRequests int32
+	}{
+		{description: "Fired", givenMaxRetries: 2, givenStatuses: []int{http.StatusNoContent}, expectedRequests: 1},
+		{description: "Retried on server errors", givenMaxRetries: 2, givenStatuses: []int{http.StatusBadGateway, http.StatusOK}, expectedRequests: 2},
+		{description: "Retries exhausted", givenMaxRetries: 1, givenStatuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusOK}, expectedRequests: 2},
+		{description: "Not retried on client errors", givenMaxRetries: 2, givenStatuses: []int{http.StatusNotFound}, expectedRequests: 1},
+	}
+
+	for _, test := range testCases {
+		server, requests := newTestServer(test.givenStatuses...)
+		notifier := newTestNotifier(test.givenMaxRetries)
+		notifier.Save("acct", "appnexus", "bid", server.URL+"/win")
+
+		assert.True(t, notifier.Notify("acct", "appnexus", "bid"), test.description)
+		assert.False(t, notifier.Notify("acct", "appnexus", "bid"), test.description+":the url should be fired once")
+		assert.Eventually(t, func() bool { return atomic.LoadInt32(requests) == test.expectedRequests }, time.Second, time.Millisecond, test.description)
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, test.expectedRequests, atomic.LoadInt32(requests), test.description)
+		server.Close()
+	}
+}
+
+func TestNotifyUnknownBid(t *testing.T) {
+	notifier := newTestNotifier(0)
+	notifier.Save("acct", "appnexus", "bid", "http://bidder.com/win")
+
+	assert.False(t, notifier.Notify("other", "appnexus", "bid"))
+	assert.False(t, notifier.Notify("acct", "appnexus", "other"))
+}
+
+func TestExpand(t *testing.T) {
+	macros := Macros{AuctionID: "req", BidID: "bid", ImpID: "imp", SeatID: "appnexus", AdID: "ad", Price: 1.25, Currency: "USD"}
+
+	url := macros.Expand("http://bidder.com/win?a=${AUCTION_ID}&b=${AUCTION_BID_ID}&i=${AUCTION_IMP_ID}&s=${AUCTION_SEAT_ID}&ad=${AUCTION_AD_ID}&p=${AUCTION_PRICE}&c=${AUCTION_CURRENCY}&x=${AUCTION_LOSS}")
+
+	assert.Equal(t, "http://bidder.com/win?a=req&b=bid&i=imp&s=appnexus&ad=ad&p=1.25&c=USD&x=${AUCTION_LOSS}", url)
+}